@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxDiffCells bounds the line diff table so huge bodies do not exhaust memory.
+const maxDiffCells = 4_000_000
+
+// canonicalJSON formats JSON with sorted keys and indentation so bodies that
+// only differ in key order or whitespace compare equal. Other payloads are
+// returned unchanged.
+func canonicalJSON(raw []byte) string {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return strings.TrimRight(string(raw), "\n")
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return strings.TrimRight(string(raw), "\n")
+	}
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+// diffLines returns a line diff of a and b prefixed with " ", "-" and "+".
+// Unchanged runs are trimmed to contextLines around each change. It returns
+// nil when a and b are equal.
+func diffLines(a, b string, contextLines int) []string {
+	if a == b {
+		return nil
+	}
+	left := strings.Split(a, "\n")
+	right := strings.Split(b, "\n")
+	if len(left)*len(right) > maxDiffCells {
+		return []string{fmt.Sprintf("- (%d lines)", len(left)), fmt.Sprintf("+ (%d lines)", len(right))}
+	}
+
+	// lcs[i][j] is the longest common subsequence of left[i:] and right[j:].
+	lcs := make([][]int, len(left)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(right)+1)
+	}
+	for i := len(left) - 1; i >= 0; i-- {
+		for j := len(right) - 1; j >= 0; j-- {
+			if left[i] == right[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var all []string
+	i, j := 0, 0
+	for i < len(left) || j < len(right) {
+		switch {
+		case i < len(left) && j < len(right) && left[i] == right[j]:
+			all = append(all, " "+left[i])
+			i++
+			j++
+		case i < len(left) && (j == len(right) || lcs[i+1][j] >= lcs[i][j+1]):
+			all = append(all, "-"+left[i])
+			i++
+		default:
+			all = append(all, "+"+right[j])
+			j++
+		}
+	}
+	return trimDiffContext(all, contextLines)
+}
+
+func trimDiffContext(lines []string, contextLines int) []string {
+	keep := make([]bool, len(lines))
+	for index, line := range lines {
+		if strings.HasPrefix(line, " ") {
+			continue
+		}
+		for offset := max(0, index-contextLines); offset <= min(len(lines)-1, index+contextLines); offset++ {
+			keep[offset] = true
+		}
+	}
+	var out []string
+	skipped := 0
+	for index, line := range lines {
+		if keep[index] {
+			if skipped > 0 {
+				out = append(out, fmt.Sprintf("@@ %d unchanged lines @@", skipped))
+				skipped = 0
+			}
+			out = append(out, line)
+			continue
+		}
+		skipped++
+	}
+	if skipped > 0 {
+		out = append(out, fmt.Sprintf("@@ %d unchanged lines @@", skipped))
+	}
+	return out
+}
@@ -0,0 +1,309 @@
+// Command plugin-harness loads a CLIProxyAPI plugin without running the server
+// so it can be developed and tested in isolation. Host callbacks are answered
+// by in-process stubs: host.kv.* keeps state in memory, host.timer.* records
+// schedules without firing them, host.log prints to stderr and every other
+// callback fails unless a canned result is passed with --stub.
+//
+// Usage:
+//
+//	go run ./cmd/plugin-harness info   --plugin <path> [flags]
+//	go run ./cmd/plugin-harness invoke --plugin <path> --method <method> --fixture <file> [flags]
+//	go run ./cmd/plugin-harness replay --plugin <path> [--to <format>] <request-log|dir>...
+//
+// Common flags:
+//
+//	--plugin <path>          Plugin file (.so, .dylib, .dll, .plugin or .wasm)
+//	--id     <id>            Plugin ID (default: derived from the file name)
+//	--config <path>          YAML file with the plugin config block passed to plugin.register
+//	--stub   <method=file>   Canned JSON result for a host callback; repeatable
+//	--timeout <duration>     Timeout of each plugin call (default: 2m)
+//
+// invoke flags:
+//
+//	--method  <method>       Plugin method, e.g. request.translate or scheduler.pick
+//	--fixture <path>         JSON request payload; "-" reads stdin (default: {})
+//	--repeat  <n>            Call the method n times and report timings (default: 1)
+//
+// replay flags:
+//
+//	--to <format>            Upstream format for request.translate (default: inferred from the log)
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/pluginhost"
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	logging.SetupBaseLogger()
+	log.SetLevel(log.WarnLevel)
+}
+
+// stubFlags collects repeated --stub method=file flags.
+type stubFlags map[string]json.RawMessage
+
+func (s stubFlags) String() string {
+	methods := make([]string, 0, len(s))
+	for method := range s {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ",")
+}
+
+func (s stubFlags) Set(value string) error {
+	method, path, ok := strings.Cut(value, "=")
+	method = strings.TrimSpace(method)
+	path = strings.TrimSpace(path)
+	if !ok || method == "" || path == "" {
+		return fmt.Errorf("stub must be method=file, got %q", value)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if !json.Valid(data) {
+		return fmt.Errorf("stub %s: %s is not valid JSON", method, path)
+	}
+	s[method] = json.RawMessage(data)
+	return nil
+}
+
+type commonFlags struct {
+	pluginPath string
+	id         string
+	configPath string
+	stubs      stubFlags
+	timeout    time.Duration
+}
+
+func (c *commonFlags) register(fs *flag.FlagSet) {
+	c.stubs = make(stubFlags)
+	fs.StringVar(&c.pluginPath, "plugin", "", "Plugin file to load")
+	fs.StringVar(&c.id, "id", "", "Plugin ID (default: derived from the file name)")
+	fs.StringVar(&c.configPath, "config", "", "YAML file with the plugin config block")
+	fs.Var(c.stubs, "stub", "Canned host callback result as method=file.json; repeatable")
+	fs.DurationVar(&c.timeout, "timeout", 2*time.Minute, "Timeout of each plugin call")
+}
+
+func (c *commonFlags) open(stderr io.Writer) (*pluginhost.Harness, error) {
+	if strings.TrimSpace(c.pluginPath) == "" {
+		return nil, fmt.Errorf("--plugin is required")
+	}
+	var configYAML []byte
+	if strings.TrimSpace(c.configPath) != "" {
+		data, err := os.ReadFile(c.configPath)
+		if err != nil {
+			return nil, fmt.Errorf("read plugin config: %w", err)
+		}
+		configYAML = data
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	return pluginhost.OpenHarness(ctx, c.pluginPath, pluginhost.HarnessOptions{
+		ID:         c.id,
+		ConfigYAML: configYAML,
+		Stubs:      c.stubs,
+		Log: func(level, message string) {
+			fmt.Fprintf(stderr, "[plugin %s] %s\n", strings.ToLower(strings.TrimSpace(level)), message)
+		},
+	})
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return 2
+	}
+	var err error
+	switch args[0] {
+	case "info":
+		err = runInfo(args[1:], stdout, stderr)
+	case "invoke":
+		err = runInvoke(args[1:], stdin, stdout, stderr)
+	case "replay":
+		err = runReplay(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		printUsage(stdout)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "error: unknown command %q\n", args[0])
+		printUsage(stderr)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: plugin-harness <info|invoke|replay> --plugin <path> [flags]")
+	fmt.Fprintln(w, "  info    print the plugin registration and capabilities")
+	fmt.Fprintln(w, "  invoke  call one plugin method with a JSON fixture")
+	fmt.Fprintln(w, "  replay  run request logs through the plugin's interceptors and translators")
+}
+
+func runInfo(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("info", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var common commonFlags
+	common.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	harness, err := common.open(stderr)
+	if err != nil {
+		return err
+	}
+	defer harness.Close()
+
+	fmt.Fprintf(stdout, "Plugin: %s\n", harness.ID())
+	fmt.Fprintf(stdout, "Capabilities: %s\n", strings.Join(harness.Capabilities(), ", "))
+	fmt.Fprintln(stdout, "Registration:")
+	fmt.Fprintln(stdout, prettyJSON(harness.Registration()))
+	return nil
+}
+
+func runInvoke(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("invoke", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var common commonFlags
+	common.register(fs)
+	var method string
+	var fixturePath string
+	var repeat int
+	fs.StringVar(&method, "method", "", "Plugin method to call")
+	fs.StringVar(&fixturePath, "fixture", "", `JSON request payload file, "-" for stdin`)
+	fs.IntVar(&repeat, "repeat", 1, "Number of calls used for timing")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	method = strings.TrimSpace(method)
+	if method == "" {
+		return fmt.Errorf("--method is required")
+	}
+	if repeat < 1 {
+		return fmt.Errorf("--repeat must be at least 1")
+	}
+	request := []byte(`{}`)
+	switch strings.TrimSpace(fixturePath) {
+	case "":
+	case "-":
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return fmt.Errorf("read fixture: %w", err)
+		}
+		request = data
+	default:
+		data, err := os.ReadFile(fixturePath)
+		if err != nil {
+			return fmt.Errorf("read fixture: %w", err)
+		}
+		request = data
+	}
+	if !json.Valid(request) {
+		return fmt.Errorf("fixture is not valid JSON")
+	}
+
+	harness, err := common.open(stderr)
+	if err != nil {
+		return err
+	}
+	defer harness.Close()
+
+	var last pluginhost.HarnessResult
+	durations := make([]time.Duration, 0, repeat)
+	for i := 0; i < repeat; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), common.timeout)
+		last = harness.Invoke(ctx, method, request)
+		cancel()
+		durations = append(durations, last.Duration)
+		if last.Error != "" {
+			break
+		}
+	}
+	printResult(stdout, last)
+	if len(durations) > 1 {
+		fmt.Fprintf(stdout, "Timing over %d calls: %s\n", len(durations), formatTimings(durations))
+	}
+	if last.Error != "" {
+		return fmt.Errorf("%s failed", method)
+	}
+	return nil
+}
+
+func printResult(w io.Writer, result pluginhost.HarnessResult) {
+	fmt.Fprintf(w, "Method: %s\n", result.Method)
+	fmt.Fprintf(w, "Duration: %s\n", result.Duration.Round(time.Microsecond))
+	if result.Error != "" {
+		fmt.Fprintf(w, "Error: %s\n", result.Error)
+	} else if len(result.Result) > 0 {
+		fmt.Fprintln(w, "Result:")
+		fmt.Fprintln(w, prettyJSON(result.Result))
+	}
+	if len(result.Chunks) > 0 {
+		fmt.Fprintf(w, "Stream (%d chunks):\n", len(result.Chunks))
+		for index, chunk := range result.Chunks {
+			if chunk.Error != "" {
+				fmt.Fprintf(w, "  [%d] error: %s\n", index, chunk.Error)
+				continue
+			}
+			fmt.Fprintf(w, "  [%d] %s\n", index, strings.TrimRight(string(chunk.Payload), "\n"))
+		}
+	}
+	printHostCalls(w, result.HostCalls)
+}
+
+func printHostCalls(w io.Writer, calls []pluginhost.HarnessHostCall) {
+	if len(calls) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Host callbacks (%d):\n", len(calls))
+	for _, call := range calls {
+		if call.Error != "" {
+			fmt.Fprintf(w, "  %s: %s\n", call.Method, call.Error)
+			continue
+		}
+		fmt.Fprintf(w, "  %s\n", call.Method)
+	}
+}
+
+func formatTimings(durations []time.Duration) string {
+	if len(durations) == 0 {
+		return ""
+	}
+	minimum, maximum, total := durations[0], durations[0], time.Duration(0)
+	for _, duration := range durations {
+		minimum = min(minimum, duration)
+		maximum = max(maximum, duration)
+		total += duration
+	}
+	average := total / time.Duration(len(durations))
+	return fmt.Sprintf("min %s avg %s max %s", minimum.Round(time.Microsecond), average.Round(time.Microsecond), maximum.Round(time.Microsecond))
+}
+
+func prettyJSON(raw []byte) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, bytes.TrimSpace(raw), "", "  "); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormatFromPath(t *testing.T) {
+	cases := map[string]string{
+		"/v1/chat/completions":                                        "openai",
+		"/v1/messages?beta=true":                                      "claude",
+		"/v1/responses":                                               "openai-response",
+		"https://chatgpt.com/backend-api/codex/responses":             "codex",
+		"/v1beta/models/gemini-2.5-pro:streamGenerateContent?alt=sse": "gemini",
+		"https://daily-cloudcode-pa.googleapis.com/v1internal:streamGenerateContent?alt=sse": "antigravity",
+		"/v1/models": "",
+		"<unknown>":  "",
+	}
+	for path, want := range cases {
+		if got := formatFromPath(path); got != want {
+			t.Errorf("formatFromPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestDiffLinesIgnoresKeyOrderAndCollapsesContext(t *testing.T) {
+	a := canonicalJSON([]byte(`{"b":2,"a":1}`))
+	b := canonicalJSON([]byte("{\n  \"a\": 1,\n  \"b\": 2\n}\n"))
+	if lines := diffLines(a, b, diffContextLines); lines != nil {
+		t.Fatalf("diffLines() of equivalent JSON = %v", lines)
+	}
+
+	left := canonicalJSON([]byte(`{"a":1,"b":2,"c":3,"d":4,"e":5,"f":6,"g":7}`))
+	right := canonicalJSON([]byte(`{"a":1,"b":2,"c":3,"d":40,"e":5,"f":6,"g":7}`))
+	want := []string{
+		"@@ 2 unchanged lines @@",
+		`   "b": 2,`,
+		`   "c": 3,`,
+		`-  "d": 4,`,
+		`+  "d": 40,`,
+		`   "e": 5,`,
+		`   "f": 6,`,
+		"@@ 2 unchanged lines @@",
+	}
+	if got := diffLines(left, right, diffContextLines); !reflect.DeepEqual(got, want) {
+		t.Fatalf("diffLines() = %q, want %q", got, want)
+	}
+}
+
+func TestStubFlagsReadsJSONFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "http.json")
+	if errWrite := os.WriteFile(path, []byte(`{"StatusCode":200}`), 0o600); errWrite != nil {
+		t.Fatal(errWrite)
+	}
+	stubs := make(stubFlags)
+	if errSet := stubs.Set("host.http.do=" + path); errSet != nil {
+		t.Fatalf("Set() error = %v", errSet)
+	}
+	if string(stubs["host.http.do"]) != `{"StatusCode":200}` {
+		t.Fatalf("stub = %s", stubs["host.http.do"])
+	}
+	if errSet := stubs.Set("host.http.do"); errSet == nil {
+		t.Fatal("Set() accepted a stub without a file")
+	}
+}
+
+func TestCollectRequestLogsExpandsDirectories(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.log", "a.log", "notes.txt"} {
+		if errWrite := os.WriteFile(filepath.Join(dir, name), nil, 0o600); errWrite != nil {
+			t.Fatal(errWrite)
+		}
+	}
+	got, errCollect := collectRequestLogs([]string{dir})
+	if errCollect != nil {
+		t.Fatalf("collectRequestLogs() error = %v", errCollect)
+	}
+	want := []string{filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log")}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("collectRequestLogs() = %v, want %v", got, want)
+	}
+}
+
+func TestRunRejectsUnknownCommandAndMissingPlugin(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"bogus"}, nil, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), "unknown command") {
+		t.Fatalf("run(bogus) = %d, stderr %q", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := run([]string{"info"}, nil, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "--plugin is required") {
+		t.Fatalf("run(info) = %d, stderr %q", code, stderr.String())
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/pluginhost"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginabi"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v7/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change.
+const diffContextLines = 2
+
+// replayStep is one plugin call made for a request log. The plugin output is
+// compared with expected, which is either the step input or, for
+// request.translate, the upstream request CPA actually sent.
+type replayStep struct {
+	method        string
+	request       any
+	input         []byte
+	expected      []byte
+	expectedLabel string
+}
+
+type replayStats struct {
+	logs      int
+	steps     int
+	changed   int
+	failed    int
+	durations map[string][]time.Duration
+}
+
+func runReplay(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var common commonFlags
+	common.register(fs)
+	var toFormat string
+	fs.StringVar(&toFormat, "to", "", "Upstream format for request.translate (default: inferred from the log)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	paths, err := collectRequestLogs(fs.Args())
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no request logs given")
+	}
+
+	harness, err := common.open(stderr)
+	if err != nil {
+		return err
+	}
+	defer harness.Close()
+
+	stats := replayStats{durations: make(map[string][]time.Duration)}
+	for _, path := range paths {
+		if err = replayRequestLog(harness, path, strings.TrimSpace(toFormat), common.timeout, stdout, &stats); err != nil {
+			fmt.Fprintf(stdout, "== %s\n  skipped: %v\n", path, err)
+		}
+	}
+
+	fmt.Fprintf(stdout, "\nReplayed %d logs: %d steps, %d changed, %d failed\n", stats.logs, stats.steps, stats.changed, stats.failed)
+	methods := make([]string, 0, len(stats.durations))
+	for method := range stats.durations {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	for _, method := range methods {
+		fmt.Fprintf(stdout, "  %-26s %s\n", method, formatTimings(stats.durations[method]))
+	}
+	if stats.failed > 0 {
+		return fmt.Errorf("%d replay steps failed", stats.failed)
+	}
+	return nil
+}
+
+// collectRequestLogs expands directories to the .log files they contain.
+func collectRequestLogs(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+		entries, err := os.ReadDir(arg)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), ".log") {
+				paths = append(paths, filepath.Join(arg, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func replayRequestLog(harness *pluginhost.Harness, path, toFormat string, timeout time.Duration, stdout io.Writer, stats *replayStats) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	record, err := logging.ParseRequestLog(data)
+	if err != nil {
+		return err
+	}
+	if len(record.Body) == 0 {
+		return fmt.Errorf("log has no request body")
+	}
+	var upstream []byte
+	upstreamURL := ""
+	if len(record.APIRequests) > 0 {
+		upstream = record.APIRequests[0].Body
+		upstreamURL = record.APIRequests[0].URL
+	}
+	sourceFormat := formatFromPath(record.URL)
+	if toFormat == "" {
+		toFormat = formatFromPath(upstreamURL)
+	}
+	model := gjson.GetBytes(record.Body, "model").String()
+	stream := gjson.GetBytes(record.Body, "stream").Bool()
+	requestID := strings.TrimSuffix(filepath.Base(path), ".log")
+
+	stats.logs++
+	fmt.Fprintf(stdout, "== %s (%s %s, %s -> %s, model %s)\n", path, record.Method, record.URL, displayFormat(sourceFormat), displayFormat(toFormat), displayFormat(model))
+	for _, step := range replaySteps(harness, record, requestID, sourceFormat, toFormat, model, stream, upstream) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		raw, errMarshal := json.Marshal(step.request)
+		if errMarshal != nil {
+			cancel()
+			return errMarshal
+		}
+		result := harness.Invoke(ctx, step.method, raw)
+		cancel()
+		stats.steps++
+		stats.durations[step.method] = append(stats.durations[step.method], result.Duration)
+		reportReplayStep(stdout, step, result, stats)
+	}
+	return nil
+}
+
+// replaySteps builds the calls the server would make for this request, limited
+// to the capabilities the plugin registered.
+func replaySteps(harness *pluginhost.Harness, record logging.RequestLogRecord, requestID, sourceFormat, toFormat, model string, stream bool, upstream []byte) []replayStep {
+	var steps []replayStep
+	interceptor := harness.HasCapability("request_interceptor")
+	if interceptor {
+		steps = append(steps, replayStep{
+			method: pluginabi.MethodRequestInterceptBefore,
+			request: pluginapi.RequestInterceptRequest{
+				RequestID:      requestID,
+				SourceFormat:   sourceFormat,
+				Model:          model,
+				RequestedModel: model,
+				Stream:         stream,
+				Headers:        record.Headers,
+				Body:           record.Body,
+			},
+			input:         record.Body,
+			expected:      record.Body,
+			expectedLabel: "request body",
+		})
+	}
+	if harness.HasCapability("request_normalizer") {
+		steps = append(steps, replayStep{
+			method:        pluginabi.MethodRequestNormalize,
+			request:       pluginapi.RequestTransformRequest{FromFormat: sourceFormat, ToFormat: toFormat, Model: model, Stream: stream, Body: record.Body},
+			input:         record.Body,
+			expected:      record.Body,
+			expectedLabel: "request body",
+		})
+	}
+	if harness.HasCapability("request_translator") {
+		step := replayStep{
+			method:        pluginabi.MethodRequestTranslate,
+			request:       pluginapi.RequestTransformRequest{FromFormat: sourceFormat, ToFormat: toFormat, Model: model, Stream: stream, Body: record.Body},
+			input:         record.Body,
+			expected:      record.Body,
+			expectedLabel: "request body",
+		}
+		if len(upstream) > 0 {
+			step.expected = upstream
+			step.expectedLabel = "recorded upstream request"
+		}
+		steps = append(steps, step)
+	}
+	if interceptor && len(upstream) > 0 {
+		steps = append(steps, replayStep{
+			method: pluginabi.MethodRequestInterceptAfter,
+			request: pluginapi.RequestInterceptRequest{
+				RequestID:      requestID,
+				SourceFormat:   sourceFormat,
+				ToFormat:       toFormat,
+				Model:          model,
+				RequestedModel: model,
+				Stream:         stream,
+				Headers:        record.APIRequests[0].Headers,
+				Body:           upstream,
+			},
+			input:         upstream,
+			expected:      upstream,
+			expectedLabel: "recorded upstream request",
+		})
+	}
+	return steps
+}
+
+func reportReplayStep(w io.Writer, step replayStep, result pluginhost.HarnessResult, stats *replayStats) {
+	prefix := fmt.Sprintf("  %-26s %10s  ", step.method, result.Duration.Round(time.Microsecond))
+	if result.Error != "" {
+		stats.failed++
+		fmt.Fprintf(w, "%serror: %s\n", prefix, result.Error)
+		printHostCalls(w, result.HostCalls)
+		return
+	}
+	output, note, err := replayStepOutput(step, result.Result)
+	if err != nil {
+		stats.failed++
+		fmt.Fprintf(w, "%serror: %v\n", prefix, err)
+		return
+	}
+	lines := diffLines(canonicalJSON(step.expected), canonicalJSON(output), diffContextLines)
+	switch {
+	case len(lines) == 0:
+		fmt.Fprintf(w, "%smatches %s%s\n", prefix, step.expectedLabel, note)
+	default:
+		stats.changed++
+		fmt.Fprintf(w, "%sdiffers from %s%s\n", prefix, step.expectedLabel, note)
+		fmt.Fprintf(w, "    --- %s\n    +++ %s\n", step.expectedLabel, step.method)
+		for _, line := range lines {
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+	}
+	printHostCalls(w, result.HostCalls)
+}
+
+// replayStepOutput returns the request body after the step. Interceptors that
+// leave the body empty keep the input body.
+func replayStepOutput(step replayStep, result json.RawMessage) ([]byte, string, error) {
+	switch step.method {
+	case pluginabi.MethodRequestInterceptBefore, pluginabi.MethodRequestInterceptAfter:
+		var resp pluginapi.RequestInterceptResponse
+		if err := json.Unmarshal(result, &resp); err != nil {
+			return nil, "", fmt.Errorf("decode %s result: %w", step.method, err)
+		}
+		if resp.Terminate {
+			return step.input, fmt.Sprintf(" (terminated with status %d)", resp.StatusCode), nil
+		}
+		if len(resp.Body) == 0 {
+			return step.input, "", nil
+		}
+		return resp.Body, "", nil
+	default:
+		var resp pluginapi.PayloadResponse
+		if err := json.Unmarshal(result, &resp); err != nil {
+			return nil, "", fmt.Errorf("decode %s result: %w", step.method, err)
+		}
+		return resp.Body, "", nil
+	}
+}
+
+// formatFromPath infers the protocol format from a downstream path or an
+// upstream URL. It returns "" when the endpoint is not recognized.
+func formatFromPath(path string) string {
+	path = strings.ToLower(path)
+	switch {
+	case path == "":
+		return ""
+	case strings.Contains(path, "/backend-api/codex"):
+		return string(sdktranslator.FormatCodex)
+	case strings.Contains(path, "/interactions"):
+		return string(sdktranslator.FormatInteractions)
+	case strings.Contains(path, "/messages"):
+		return string(sdktranslator.FormatClaude)
+	case strings.Contains(path, "/responses"):
+		return string(sdktranslator.FormatOpenAIResponse)
+	case strings.Contains(path, "/chat/completions"), strings.HasSuffix(strings.SplitN(path, "?", 2)[0], "/completions"):
+		return string(sdktranslator.FormatOpenAI)
+	case strings.Contains(path, "v1internal:"):
+		return string(sdktranslator.FormatAntigravity)
+	case strings.Contains(path, ":generatecontent"), strings.Contains(path, ":streamgeneratecontent"), strings.Contains(path, ":counttokens"):
+		return string(sdktranslator.FormatGemini)
+	default:
+		return ""
+	}
+}
+
+func displayFormat(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "?"
+	}
+	return value
+}
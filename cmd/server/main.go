@@ -17,8 +17,11 @@ import (
 	"time"
 
 	"github.com/joho/godotenv"
+	clientcertaccess "github.com/router-for-me/CLIProxyAPI/v7/internal/access/client_cert_access"
 	configaccess "github.com/router-for-me/CLIProxyAPI/v7/internal/access/config_access"
+	jwtaccess "github.com/router-for-me/CLIProxyAPI/v7/internal/access/jwt_access"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/api"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/audit"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/buildinfo"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/cmd"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
@@ -83,6 +86,7 @@ func main() {
 	var xaiLogin bool
 	var vertexImport string
 	var vertexImportPrefix string
+	var rotateKey bool
 	var configPath string
 	var password string
 	var homeJWT string
@@ -103,6 +107,7 @@ func main() {
 	flag.StringVar(&configPath, "config", DefaultConfigPath, "Configure File Path")
 	flag.StringVar(&vertexImport, "vertex-import", "", "Import Vertex service account key JSON file")
 	flag.StringVar(&vertexImportPrefix, "vertex-import-prefix", "", "Prefix for Vertex model namespacing (use with -vertex-import)")
+	flag.BoolVar(&rotateKey, "rotate-key", false, "Re-encrypt auth files and config secrets with the current master key")
 	flag.StringVar(&password, "password", "", "")
 	flag.StringVar(&homeJWT, "home-jwt", "", "Home control plane JWT for mTLS certificate bootstrap and connection")
 	flag.BoolVar(&homeDisableClusterDiscovery, "home-disable-cluster-discovery", false, "Disable Home CLUSTER NODES discovery and keep using the configured -home-jwt address")
@@ -207,6 +212,13 @@ func main() {
 			homeJWT = v
 		}
 	}
+	// Allow the JWT to be given as a ${env:...}, ${file:...} or ${cmd:...} reference.
+	resolvedHomeJWT, errResolveHomeJWT := config.ResolveSecretValue(homeJWT)
+	if errResolveHomeJWT != nil {
+		log.Errorf("failed to resolve home JWT reference: %v", errResolveHomeJWT)
+		return
+	}
+	homeJWT = resolvedHomeJWT
 
 	if value, ok := lookupEnv("PGSTORE_DSN", "pgstore_dsn"); ok {
 		usePostgresStore = true
@@ -534,6 +546,11 @@ func main() {
 	if cfg == nil {
 		cfg = &config.Config{}
 	}
+	// Install the master key of the adopted config before any auth file is read.
+	if errEncryption := cfg.ApplyEncryption(); errEncryption != nil {
+		log.Errorf("failed to configure encryption: %v", errEncryption)
+		return
+	}
 
 	// In cloud deploy mode, check if we have a valid configuration
 	var configFileExists bool
@@ -588,7 +605,7 @@ func main() {
 		CallbackPort: oauthCallbackPort,
 	}
 
-	commandMode := vertexImport != "" || antigravityLogin || codexLogin || codexDeviceLogin || claudeLogin || kimiLogin || xaiLogin
+	commandMode := vertexImport != "" || rotateKey || antigravityLogin || codexLogin || codexDeviceLogin || claudeLogin || kimiLogin || xaiLogin
 	cloudConfigMissing := isCloudDeploy && !configFileExists
 	homeMode := configLoadedFromHome || (cfg != nil && cfg.Home.Enabled)
 	exampleAPIKeySafeMode := shouldEnableExampleAPIKeySafeMode(cfg, commandMode, tuiMode, standalone, cloudConfigMissing, homeMode)
@@ -602,6 +619,7 @@ func main() {
 	// Register the shared token store once so all components use the same persistence backend.
 	if usePostgresStore {
 		sdkAuth.RegisterTokenStore(pgStoreInst)
+		audit.SetDatabaseStore(pgStoreInst.AuditStore())
 	} else if useObjectStore {
 		sdkAuth.RegisterTokenStore(objectStoreInst)
 	} else if useGitStore {
@@ -609,9 +627,15 @@ func main() {
 	} else {
 		sdkAuth.RegisterTokenStore(sdkAuth.NewFileTokenStore())
 	}
+	if !commandMode {
+		// Seal auth files left in plaintext once encryption at rest is enabled.
+		cmd.MigratePlaintextAuthFiles(cfg)
+	}
 
 	// Register built-in access providers before constructing services.
 	configaccess.Register(&cfg.SDKConfig)
+	jwtaccess.Register(&cfg.SDKConfig)
+	clientcertaccess.Register(&cfg.SDKConfig)
 	pluginHost.ApplyConfig(context.Background(), cfg)
 	if configLoadedFromHome && homePluginStatusReady {
 		errHomePluginLoad := homeplugins.MarkLoadResults(&homePluginSyncReport, pluginHost)
@@ -646,6 +670,9 @@ func main() {
 	if vertexImport != "" {
 		// Handle Vertex service account import
 		cmd.DoVertexImport(cfg, vertexImport, vertexImportPrefix)
+	} else if rotateKey {
+		// Re-encrypt stored secrets with the current master key
+		cmd.DoRotateKey(cfg, configFilePath)
 	} else if antigravityLogin {
 		// Handle Antigravity login
 		cmd.DoAntigravityLogin(cfg, options)
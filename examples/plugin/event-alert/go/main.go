@@ -0,0 +1,288 @@
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+
+typedef struct {
+	void* ptr;
+	size_t len;
+} cliproxy_buffer;
+
+typedef int (*cliproxy_host_call_fn)(void*, const char*, const uint8_t*, size_t, cliproxy_buffer*);
+typedef void (*cliproxy_host_free_fn)(void*, size_t);
+
+typedef struct {
+	uint32_t abi_version;
+	void* host_ctx;
+	cliproxy_host_call_fn call;
+	cliproxy_host_free_fn free_buffer;
+} cliproxy_host_api;
+
+typedef int (*cliproxy_plugin_call_fn)(char*, uint8_t*, size_t, cliproxy_buffer*);
+typedef void (*cliproxy_plugin_free_fn)(void*, size_t);
+typedef void (*cliproxy_plugin_shutdown_fn)(void);
+
+typedef struct {
+	uint32_t abi_version;
+	cliproxy_plugin_call_fn call;
+	cliproxy_plugin_free_fn free_buffer;
+	cliproxy_plugin_shutdown_fn shutdown;
+} cliproxy_plugin_api;
+
+extern int cliproxyPluginCall(char*, uint8_t*, size_t, cliproxy_buffer*);
+extern void cliproxyPluginFree(void*, size_t);
+extern void cliproxyPluginShutdown(void);
+*/
+import "C"
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginabi"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
+)
+
+const (
+	pluginName    = "event-alert"
+	defaultFile   = "event-alerts.jsonl"
+	defaultTopics = "auth.status,auth.cooldown.entered,auth.refresh,config.reloaded,models.refreshed,plugin.crashed"
+)
+
+type envelope struct {
+	OK     bool            `json:"ok"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *envelopeError  `json:"error,omitempty"`
+}
+
+type envelopeError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type registration struct {
+	SchemaVersion uint32                   `json:"schema_version"`
+	Metadata      pluginapi.Metadata       `json:"metadata"`
+	Capabilities  registrationCapabilities `json:"capabilities"`
+}
+
+type registrationCapabilities struct {
+	EventSubscriber bool     `json:"event_subscriber"`
+	EventTopics     []string `json:"event_topics,omitempty"`
+	EventQueueSize  int      `json:"event_queue_size,omitempty"`
+}
+
+type lifecycleRequest struct {
+	ConfigYAML []byte `json:"config_yaml"`
+}
+
+// alertLine is one line of the alert file.
+type alertLine struct {
+	ID      uint64          `json:"id"`
+	Type    string          `json:"type"`
+	Time    time.Time       `json:"time"`
+	Dropped uint64          `json:"dropped,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+var (
+	settingsMu sync.Mutex
+	alertFile  = defaultFile
+)
+
+func main() {}
+
+//export cliproxy_plugin_init
+func cliproxy_plugin_init(host *C.cliproxy_host_api, plugin *C.cliproxy_plugin_api) C.int {
+	if plugin == nil {
+		return 1
+	}
+	_ = host
+	plugin.abi_version = C.uint32_t(pluginabi.ABIVersion)
+	plugin.call = C.cliproxy_plugin_call_fn(C.cliproxyPluginCall)
+	plugin.free_buffer = C.cliproxy_plugin_free_fn(C.cliproxyPluginFree)
+	plugin.shutdown = C.cliproxy_plugin_shutdown_fn(C.cliproxyPluginShutdown)
+	return 0
+}
+
+//export cliproxyPluginCall
+func cliproxyPluginCall(method *C.char, request *C.uint8_t, requestLen C.size_t, response *C.cliproxy_buffer) C.int {
+	if response != nil {
+		response.ptr = nil
+		response.len = 0
+	}
+	if method == nil {
+		writeResponse(response, errorEnvelope("invalid_method", "method is required"))
+		return 1
+	}
+	var requestBytes []byte
+	if request != nil && requestLen > 0 {
+		requestBytes = C.GoBytes(unsafe.Pointer(request), C.int(requestLen))
+	}
+	raw, errHandle := handleMethod(C.GoString(method), requestBytes)
+	if errHandle != nil {
+		writeResponse(response, errorEnvelope("plugin_error", errHandle.Error()))
+		return 1
+	}
+	writeResponse(response, raw)
+	return 0
+}
+
+//export cliproxyPluginFree
+func cliproxyPluginFree(ptr unsafe.Pointer, len C.size_t) {
+	if ptr != nil {
+		C.free(ptr)
+	}
+	_ = len
+}
+
+//export cliproxyPluginShutdown
+func cliproxyPluginShutdown() {}
+
+func handleMethod(method string, request []byte) ([]byte, error) {
+	switch method {
+	case pluginabi.MethodPluginRegister, pluginabi.MethodPluginReconfigure:
+		reg, errConfigure := configure(request)
+		if errConfigure != nil {
+			return nil, errConfigure
+		}
+		return okEnvelope(reg)
+	case pluginabi.MethodEventHandle:
+		return handleEvent(request)
+	default:
+		return errorEnvelope("unknown_method", "unknown method: "+method), nil
+	}
+}
+
+// configure applies the plugin config and returns the registration. The
+// returned topics replace the previous subscription on plugin.reconfigure.
+func configure(raw []byte) (registration, error) {
+	var req lifecycleRequest
+	if len(raw) > 0 {
+		if errUnmarshal := json.Unmarshal(raw, &req); errUnmarshal != nil {
+			return registration{}, fmt.Errorf("decode lifecycle request: %w", errUnmarshal)
+		}
+	}
+	file := configValue(req.ConfigYAML, "file")
+	if file == "" {
+		file = defaultFile
+	}
+	topics := configValue(req.ConfigYAML, "topics")
+	if topics == "" {
+		topics = defaultTopics
+	}
+	queueSize, _ := strconv.Atoi(configValue(req.ConfigYAML, "queue-size"))
+
+	settingsMu.Lock()
+	alertFile = file
+	settingsMu.Unlock()
+
+	return registration{
+		SchemaVersion: pluginabi.SchemaVersion,
+		Metadata: pluginapi.Metadata{
+			Name:             pluginName,
+			Version:          "0.1.0",
+			Author:           "router-for-me",
+			GitHubRepository: "https://github.com/router-for-me/CLIProxyAPI",
+			Logo:             "https://raw.githubusercontent.com/router-for-me/CLIProxyAPI/main/docs/logo.png",
+			ConfigFields: []pluginapi.ConfigField{{
+				Name:        "file",
+				Type:        pluginapi.ConfigFieldTypeString,
+				Description: "File that receives one JSON line per event.",
+			}, {
+				Name:        "topics",
+				Type:        pluginapi.ConfigFieldTypeString,
+				Description: "Comma-separated event types or prefixes, for example auth,config.reloaded.",
+			}, {
+				Name:        "queue-size",
+				Type:        pluginapi.ConfigFieldTypeInteger,
+				Description: "Events buffered by the host before new ones are dropped.",
+			}},
+		},
+		Capabilities: registrationCapabilities{
+			EventSubscriber: true,
+			EventTopics:     strings.Split(topics, ","),
+			EventQueueSize:  queueSize,
+		},
+	}, nil
+}
+
+// configValue reads a top-level scalar from the plugin config block. The
+// example avoids a YAML dependency and only understands "key: value" lines.
+func configValue(configYAML []byte, key string) string {
+	for _, line := range strings.Split(string(configYAML), "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) != key || strings.HasPrefix(line, " ") {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return ""
+}
+
+func handleEvent(raw []byte) ([]byte, error) {
+	var event pluginapi.HostEvent
+	if errUnmarshal := json.Unmarshal(raw, &event); errUnmarshal != nil {
+		return nil, fmt.Errorf("decode event.handle request: %w", errUnmarshal)
+	}
+	line, errMarshal := json.Marshal(alertLine{
+		ID:      event.ID,
+		Type:    event.Type,
+		Time:    event.Time,
+		Dropped: event.Dropped,
+		Data:    event.Data,
+	})
+	if errMarshal != nil {
+		return nil, errMarshal
+	}
+
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	if dir := filepath.Dir(alertFile); dir != "." {
+		if errMkdir := os.MkdirAll(dir, 0o755); errMkdir != nil {
+			return nil, errMkdir
+		}
+	}
+	file, errOpen := os.OpenFile(alertFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if errOpen != nil {
+		return nil, errOpen
+	}
+	defer func() { _ = file.Close() }()
+	if _, errWrite := file.Write(append(line, '\n')); errWrite != nil {
+		return nil, errWrite
+	}
+	return okEnvelope(map[string]any{})
+}
+
+func okEnvelope(v any) ([]byte, error) {
+	raw, errMarshal := json.Marshal(v)
+	if errMarshal != nil {
+		return nil, errMarshal
+	}
+	return json.Marshal(envelope{OK: true, Result: raw})
+}
+
+func errorEnvelope(code, message string) []byte {
+	raw, _ := json.Marshal(envelope{OK: false, Error: &envelopeError{Code: code, Message: message}})
+	return raw
+}
+
+func writeResponse(response *C.cliproxy_buffer, raw []byte) {
+	if response == nil || len(raw) == 0 {
+		return
+	}
+	ptr := C.CBytes(raw)
+	if ptr == nil {
+		return
+	}
+	response.ptr = ptr
+	response.len = C.size_t(len(raw))
+}
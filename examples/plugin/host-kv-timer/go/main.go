@@ -0,0 +1,438 @@
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+
+typedef struct {
+	void* ptr;
+	size_t len;
+} cliproxy_buffer;
+
+typedef int (*cliproxy_host_call_fn)(void*, const char*, const uint8_t*, size_t, cliproxy_buffer*);
+typedef void (*cliproxy_host_free_fn)(void*, size_t);
+
+typedef struct {
+	uint32_t abi_version;
+	void* host_ctx;
+	cliproxy_host_call_fn call;
+	cliproxy_host_free_fn free_buffer;
+} cliproxy_host_api;
+
+typedef int (*cliproxy_plugin_call_fn)(char*, uint8_t*, size_t, cliproxy_buffer*);
+typedef void (*cliproxy_plugin_free_fn)(void*, size_t);
+typedef void (*cliproxy_plugin_shutdown_fn)(void);
+
+typedef struct {
+	uint32_t abi_version;
+	cliproxy_plugin_call_fn call;
+	cliproxy_plugin_free_fn free_buffer;
+	cliproxy_plugin_shutdown_fn shutdown;
+} cliproxy_plugin_api;
+
+extern int cliproxyPluginCall(char*, uint8_t*, size_t, cliproxy_buffer*);
+extern void cliproxyPluginFree(void*, size_t);
+extern void cliproxyPluginShutdown(void);
+
+static const cliproxy_host_api* stored_host;
+
+static void store_host_api(const cliproxy_host_api* host) {
+	stored_host = host;
+}
+
+static int call_host_api(const char* method, const uint8_t* request, size_t request_len, cliproxy_buffer* response) {
+	if (stored_host == NULL || stored_host->call == NULL) {
+		return 1;
+	}
+	return stored_host->call(stored_host->host_ctx, method, request, request_len, response);
+}
+
+static void free_host_buffer(void* ptr, size_t len) {
+	if (stored_host != NULL && stored_host->free_buffer != NULL && ptr != NULL) {
+		stored_host->free_buffer(ptr, len);
+	}
+}
+*/
+import "C"
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginabi"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
+)
+
+const (
+	pluginName          = "host-kv-timer"
+	resourcePath        = "/status"
+	resourceContentType = "text/html; charset=utf-8"
+	timerName           = "heartbeat"
+	defaultInterval     = "1m"
+	fireCountKey        = "heartbeat/count"
+	lastFiredKey        = "heartbeat/last"
+)
+
+type envelope struct {
+	OK     bool            `json:"ok"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *envelopeError  `json:"error,omitempty"`
+}
+
+type envelopeError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type registration struct {
+	SchemaVersion uint32                   `json:"schema_version"`
+	Metadata      pluginapi.Metadata       `json:"metadata"`
+	Capabilities  registrationCapabilities `json:"capabilities"`
+}
+
+type registrationCapabilities struct {
+	ManagementAPI bool `json:"management_api"`
+}
+
+type lifecycleRequest struct {
+	ConfigYAML []byte `json:"config_yaml"`
+}
+
+type managementRegistration struct {
+	Resources []managementResource `json:"resources,omitempty"`
+}
+
+type managementResource struct {
+	Path        string `json:"Path"`
+	Menu        string `json:"Menu"`
+	Description string `json:"Description"`
+}
+
+type managementResponse struct {
+	StatusCode int         `json:"StatusCode"`
+	Headers    http.Header `json:"Headers"`
+	Body       []byte      `json:"Body"`
+}
+
+type kvEntry struct {
+	Key   string
+	Value string
+}
+
+func main() {}
+
+//export cliproxy_plugin_init
+func cliproxy_plugin_init(host *C.cliproxy_host_api, plugin *C.cliproxy_plugin_api) C.int {
+	if plugin == nil {
+		return 1
+	}
+	C.store_host_api(host)
+	plugin.abi_version = C.uint32_t(pluginabi.ABIVersion)
+	plugin.call = C.cliproxy_plugin_call_fn(C.cliproxyPluginCall)
+	plugin.free_buffer = C.cliproxy_plugin_free_fn(C.cliproxyPluginFree)
+	plugin.shutdown = C.cliproxy_plugin_shutdown_fn(C.cliproxyPluginShutdown)
+	return 0
+}
+
+//export cliproxyPluginCall
+func cliproxyPluginCall(method *C.char, request *C.uint8_t, requestLen C.size_t, response *C.cliproxy_buffer) C.int {
+	if response != nil {
+		response.ptr = nil
+		response.len = 0
+	}
+	if method == nil {
+		writeResponse(response, errorEnvelope("invalid_method", "method is required"))
+		return 1
+	}
+	var requestBytes []byte
+	if request != nil && requestLen > 0 {
+		requestBytes = C.GoBytes(unsafe.Pointer(request), C.int(requestLen))
+	}
+	raw, errHandle := handleMethod(C.GoString(method), requestBytes)
+	if errHandle != nil {
+		writeResponse(response, errorEnvelope("plugin_error", errHandle.Error()))
+		return 1
+	}
+	writeResponse(response, raw)
+	return 0
+}
+
+//export cliproxyPluginFree
+func cliproxyPluginFree(ptr unsafe.Pointer, len C.size_t) {
+	if ptr != nil {
+		C.free(ptr)
+	}
+	_ = len
+}
+
+//export cliproxyPluginShutdown
+func cliproxyPluginShutdown() {}
+
+func handleMethod(method string, request []byte) ([]byte, error) {
+	switch method {
+	case pluginabi.MethodPluginRegister, pluginabi.MethodPluginReconfigure:
+		if errSchedule := scheduleHeartbeat(request); errSchedule != nil {
+			return nil, errSchedule
+		}
+		return okEnvelope(pluginRegistration())
+	case pluginabi.MethodTimerFire:
+		return handleTimerFire(request)
+	case pluginabi.MethodManagementRegister:
+		return okEnvelope(managementRegistration{
+			Resources: []managementResource{{
+				Path:        resourcePath,
+				Menu:        "Host KV Timer",
+				Description: "Shows the state a host.timer heartbeat keeps in host.kv storage.",
+			}},
+		})
+	case pluginabi.MethodManagementHandle:
+		return handleManagement()
+	default:
+		return errorEnvelope("unknown_method", "unknown method: "+method), nil
+	}
+}
+
+func pluginRegistration() registration {
+	return registration{
+		SchemaVersion: pluginabi.SchemaVersion,
+		Metadata: pluginapi.Metadata{
+			Name:             pluginName,
+			Version:          "0.1.0",
+			Author:           "router-for-me",
+			GitHubRepository: "https://github.com/router-for-me/CLIProxyAPI",
+			Logo:             "https://raw.githubusercontent.com/router-for-me/CLIProxyAPI/main/docs/logo.png",
+			ConfigFields: []pluginapi.ConfigField{{
+				Name:        "interval",
+				Type:        pluginapi.ConfigFieldTypeString,
+				Description: "Go duration between heartbeats, at least 1s. Ignored when cron is set.",
+			}, {
+				Name:        "cron",
+				Type:        pluginapi.ConfigFieldTypeString,
+				Description: "Optional five-field cron expression evaluated in UTC.",
+			}},
+		},
+		Capabilities: registrationCapabilities{
+			ManagementAPI: true,
+		},
+	}
+}
+
+// scheduleHeartbeat (re)schedules the heartbeat timer from the plugin config.
+// Scheduling a timer with an existing name replaces it.
+func scheduleHeartbeat(raw []byte) error {
+	var req lifecycleRequest
+	if len(raw) > 0 {
+		if errUnmarshal := json.Unmarshal(raw, &req); errUnmarshal != nil {
+			return fmt.Errorf("decode lifecycle request: %w", errUnmarshal)
+		}
+	}
+	schedule := pluginapi.HostTimerScheduleRequest{
+		Name:    timerName,
+		Method:  pluginabi.MethodTimerFire,
+		Payload: json.RawMessage(`{"source":"` + pluginName + `"}`),
+	}
+	interval, cron := configValue(req.ConfigYAML, "interval"), configValue(req.ConfigYAML, "cron")
+	switch {
+	case cron != "":
+		schedule.Cron = cron
+	case interval != "":
+		schedule.Interval = interval
+	default:
+		schedule.Interval = defaultInterval
+	}
+	_, errCall := callHost(pluginabi.MethodHostTimerSchedule, schedule)
+	return errCall
+}
+
+// configValue reads a top-level scalar from the plugin config block. The
+// example avoids a YAML dependency and only understands "key: value" lines.
+func configValue(configYAML []byte, key string) string {
+	for _, line := range strings.Split(string(configYAML), "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) != key || strings.HasPrefix(line, " ") {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return ""
+}
+
+func handleTimerFire(raw []byte) ([]byte, error) {
+	var req pluginapi.TimerFireRequest
+	if errUnmarshal := json.Unmarshal(raw, &req); errUnmarshal != nil {
+		return nil, fmt.Errorf("decode timer.fire request: %w", errUnmarshal)
+	}
+	count := 0
+	if value, found, errGet := kvGet(fireCountKey); errGet != nil {
+		return nil, errGet
+	} else if found {
+		count, _ = strconv.Atoi(string(value))
+	}
+	if errSet := kvSet(fireCountKey, []byte(strconv.Itoa(count+1)), 0); errSet != nil {
+		return nil, errSet
+	}
+	// The last run expires after a day to show TTL handling.
+	if errSet := kvSet(lastFiredKey, []byte(req.ScheduledAt.UTC().Format(time.RFC3339)), 24*60*60); errSet != nil {
+		return nil, errSet
+	}
+	return okEnvelope(map[string]any{})
+}
+
+func kvGet(key string) ([]byte, bool, error) {
+	result, errCall := callHost(pluginabi.MethodHostKVGet, pluginapi.HostKVGetRequest{Key: key})
+	if errCall != nil {
+		return nil, false, errCall
+	}
+	var resp pluginapi.HostKVGetResponse
+	if errUnmarshal := json.Unmarshal(result, &resp); errUnmarshal != nil {
+		return nil, false, fmt.Errorf("decode host.kv.get result: %w", errUnmarshal)
+	}
+	return resp.Value, resp.Found, nil
+}
+
+func kvSet(key string, value []byte, ttlSeconds int64) error {
+	_, errCall := callHost(pluginabi.MethodHostKVSet, pluginapi.HostKVSetRequest{Key: key, Value: value, TTLSeconds: ttlSeconds})
+	return errCall
+}
+
+func kvEntries() ([]kvEntry, error) {
+	result, errCall := callHost(pluginabi.MethodHostKVList, pluginapi.HostKVListRequest{})
+	if errCall != nil {
+		return nil, errCall
+	}
+	var list pluginapi.HostKVListResponse
+	if errUnmarshal := json.Unmarshal(result, &list); errUnmarshal != nil {
+		return nil, fmt.Errorf("decode host.kv.list result: %w", errUnmarshal)
+	}
+	entries := make([]kvEntry, 0, len(list.Keys))
+	for _, key := range list.Keys {
+		value, found, errGet := kvGet(key)
+		if errGet != nil {
+			return nil, errGet
+		}
+		if found {
+			entries = append(entries, kvEntry{Key: key, Value: string(value)})
+		}
+	}
+	return entries, nil
+}
+
+func handleManagement() ([]byte, error) {
+	entries, errEntries := kvEntries()
+	errText := ""
+	if errEntries != nil {
+		errText = errEntries.Error()
+	}
+	return okEnvelope(managementResponse{
+		StatusCode: http.StatusOK,
+		Headers: http.Header{
+			"content-type": []string{resourceContentType},
+		},
+		Body: renderPage(entries, errText),
+	})
+}
+
+func renderPage(entries []kvEntry, errText string) []byte {
+	var out bytes.Buffer
+	out.WriteString("<!doctype html><html><head><meta charset=\"utf-8\"><title>Host KV Timer</title>")
+	out.WriteString("<style>body{font-family:-apple-system,BlinkMacSystemFont,\"Segoe UI\",sans-serif;margin:2rem;line-height:1.45;color:#1f2933}code{background:#f3f4f6;border-radius:6px;padding:.1rem .3rem}dl{display:grid;grid-template-columns:max-content 1fr;gap:.35rem 1rem}dt{font-weight:600}dd{margin:0}.error{color:#b42318}</style>")
+	out.WriteString("</head><body><main>")
+	out.WriteString("<h1>Host KV Timer</h1>")
+	if errText != "" {
+		out.WriteString("<p class=\"error\">")
+		out.WriteString(html.EscapeString(errText))
+		out.WriteString("</p>")
+	}
+	if len(entries) == 0 && errText == "" {
+		out.WriteString("<p>The heartbeat timer has not fired yet.</p>")
+	}
+	out.WriteString("<dl>")
+	for _, entry := range entries {
+		out.WriteString("<dt>")
+		out.WriteString(html.EscapeString(entry.Key))
+		out.WriteString("</dt><dd><code>")
+		out.WriteString(html.EscapeString(entry.Value))
+		out.WriteString("</code></dd>")
+	}
+	out.WriteString("</dl>")
+	out.WriteString("</main></body></html>")
+	return out.Bytes()
+}
+
+func callHost(method string, payload any) (json.RawMessage, error) {
+	rawPayload, errMarshal := json.Marshal(payload)
+	if errMarshal != nil {
+		return nil, fmt.Errorf("marshal host callback payload %s: %w", method, errMarshal)
+	}
+	cMethod := C.CString(method)
+	defer C.free(unsafe.Pointer(cMethod))
+
+	var response C.cliproxy_buffer
+	var requestPtr *C.uint8_t
+	if len(rawPayload) > 0 {
+		cPayload := C.CBytes(rawPayload)
+		if cPayload == nil {
+			return nil, fmt.Errorf("allocate host callback payload %s", method)
+		}
+		defer C.free(cPayload)
+		requestPtr = (*C.uint8_t)(cPayload)
+	}
+	callCode := C.call_host_api(cMethod, requestPtr, C.size_t(len(rawPayload)), &response)
+	var rawResponse []byte
+	if response.ptr != nil && response.len > 0 {
+		rawResponse = C.GoBytes(response.ptr, C.int(response.len))
+	}
+	if response.ptr != nil {
+		C.free_host_buffer(response.ptr, response.len)
+	}
+	if len(rawResponse) == 0 {
+		return nil, fmt.Errorf("host callback %s returned no response, code=%d", method, int(callCode))
+	}
+
+	var env envelope
+	if errUnmarshal := json.Unmarshal(rawResponse, &env); errUnmarshal != nil {
+		return nil, fmt.Errorf("decode host callback envelope %s: %w", method, errUnmarshal)
+	}
+	if !env.OK {
+		if env.Error != nil {
+			return nil, fmt.Errorf("%s: %s", env.Error.Code, env.Error.Message)
+		}
+		return nil, fmt.Errorf("host callback %s failed", method)
+	}
+	if callCode != 0 {
+		return nil, fmt.Errorf("host callback %s returned code=%d", method, int(callCode))
+	}
+	return append(json.RawMessage(nil), env.Result...), nil
+}
+
+func okEnvelope(v any) ([]byte, error) {
+	raw, errMarshal := json.Marshal(v)
+	if errMarshal != nil {
+		return nil, errMarshal
+	}
+	return json.Marshal(envelope{OK: true, Result: raw})
+}
+
+func errorEnvelope(code, message string) []byte {
+	raw, _ := json.Marshal(envelope{OK: false, Error: &envelopeError{Code: code, Message: message}})
+	return raw
+}
+
+func writeResponse(response *C.cliproxy_buffer, raw []byte) {
+	if response == nil || len(raw) == 0 {
+		return
+	}
+	ptr := C.CBytes(raw)
+	if ptr == nil {
+		return
+	}
+	response.ptr = ptr
+	response.len = C.size_t(len(raw))
+}
@@ -0,0 +1,172 @@
+// Package clientcertaccess implements the built-in "client-cert" access
+// provider. It authenticates requests by the client certificate verified
+// during the TLS handshake against tls.client-ca, so mTLS callers need no
+// bearer key.
+package clientcertaccess
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v7/sdk/access"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v7/sdk/config"
+)
+
+// ProviderName identifies the client-certificate provider in access results.
+const ProviderName = "client-cert"
+
+type connectionStateKey struct{}
+
+// WithConnectionState stores the TLS state of a connection that net/http does
+// not expose as Request.TLS (for example after protocol sniffing).
+func WithConnectionState(ctx context.Context, state *tls.ConnectionState) context.Context {
+	if state == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, connectionStateKey{}, state)
+}
+
+func connectionState(r *http.Request) *tls.ConnectionState {
+	if r.TLS != nil {
+		return r.TLS
+	}
+	state, _ := r.Context().Value(connectionStateKey{}).(*tls.ConnectionState)
+	return state
+}
+
+// Register installs the client-certificate provider when client-cert-auth is
+// enabled and removes it otherwise.
+func Register(cfg *sdkconfig.SDKConfig) {
+	if cfg == nil || !cfg.ClientCertAuth.Enabled {
+		sdkaccess.UnregisterProvider(sdkaccess.AccessProviderTypeClientCert)
+		return
+	}
+	sdkaccess.RegisterProvider(sdkaccess.AccessProviderTypeClientCert, newProvider(cfg.ClientCertAuth))
+}
+
+type provider struct {
+	principalFrom string
+	identities    []config.ClientCertIdentity
+}
+
+func newProvider(cfg config.ClientCertAuthConfig) *provider {
+	return &provider{
+		principalFrom: cfg.PrincipalFromOrDefault(),
+		identities:    append([]config.ClientCertIdentity(nil), cfg.Identities...),
+	}
+}
+
+func (p *provider) Identifier() string {
+	return ProviderName
+}
+
+func (p *provider) Authenticate(_ context.Context, r *http.Request) (*sdkaccess.Result, *sdkaccess.AuthError) {
+	if p == nil || r == nil {
+		return nil, sdkaccess.NewNotHandledError()
+	}
+	state := connectionState(r)
+	// Only trust certificates the TLS stack verified against tls.client-ca.
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.PeerCertificates) == 0 {
+		return nil, sdkaccess.NewNoCredentialsError()
+	}
+	leaf := state.PeerCertificates[0]
+	names := certificateNames(leaf, p.principalFrom)
+
+	principal := ""
+	var models []string
+	if len(p.identities) == 0 {
+		if len(names) > 0 {
+			principal = names[0]
+		}
+	} else {
+	identities:
+		for _, identity := range p.identities {
+			for _, name := range names {
+				if !matchName(identity.Match, name) {
+					continue
+				}
+				principal = name
+				if identity.Principal != "" {
+					principal = identity.Principal
+				}
+				models = identity.Models
+				break identities
+			}
+		}
+	}
+	if principal == "" {
+		return nil, sdkaccess.NewInvalidCredentialError()
+	}
+
+	fingerprint := sha256.Sum256(leaf.Raw)
+	metadata := map[string]string{
+		"source":      "client-certificate",
+		"subject":     leaf.Subject.String(),
+		"fingerprint": hex.EncodeToString(fingerprint[:]),
+	}
+	if len(models) > 0 {
+		metadata[sdkaccess.MetadataKeyAllowedModels] = strings.Join(models, ",")
+	}
+	return &sdkaccess.Result{
+		Provider:          p.Identifier(),
+		Principal:         principal,
+		Metadata:          metadata,
+		PrincipalLoggable: true,
+	}, nil
+}
+
+// certificateNames returns the certificate names selected by principalFrom.
+func certificateNames(cert *x509.Certificate, principalFrom string) []string {
+	var names []string
+	switch principalFrom {
+	case config.ClientCertPrincipalSANDNS:
+		names = append(names, cert.DNSNames...)
+	case config.ClientCertPrincipalSANURI:
+		for _, uri := range cert.URIs {
+			if uri != nil {
+				names = append(names, uri.String())
+			}
+		}
+	case config.ClientCertPrincipalSANEmail:
+		names = append(names, cert.EmailAddresses...)
+	default:
+		names = append(names, cert.Subject.CommonName)
+	}
+	out := names[:0]
+	for _, name := range names {
+		if trimmed := strings.TrimSpace(name); trimmed != "" {
+			out = append(out, trimmed)
+		}
+	}
+	return out
+}
+
+// matchName reports whether name matches pattern, where '*' matches any run
+// of characters. DNS names and e-mail addresses compare case-insensitively.
+func matchName(pattern, name string) bool {
+	pattern = strings.ToLower(pattern)
+	name = strings.ToLower(name)
+	if !strings.Contains(pattern, "*") {
+		return pattern == name
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(name, parts[0]) {
+		return false
+	}
+	name = name[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, segment := range parts[1 : len(parts)-1] {
+		index := strings.Index(name, segment)
+		if index < 0 {
+			return false
+		}
+		name = name[index+len(segment):]
+	}
+	return strings.HasSuffix(name, last)
+}
@@ -0,0 +1,136 @@
+package clientcertaccess
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v7/sdk/access"
+)
+
+func newClientCert(t *testing.T, commonName string, uris ...string) *x509.Certificate {
+	t.Helper()
+	key, errKey := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if errKey != nil {
+		t.Fatalf("GenerateKey() error = %v", errKey)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	for _, raw := range uris {
+		parsed, errParse := url.Parse(raw)
+		if errParse != nil {
+			t.Fatalf("url.Parse(%q) error = %v", raw, errParse)
+		}
+		template.URIs = append(template.URIs, parsed)
+	}
+	der, errCreate := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if errCreate != nil {
+		t.Fatalf("CreateCertificate() error = %v", errCreate)
+	}
+	cert, errParse := x509.ParseCertificate(der)
+	if errParse != nil {
+		t.Fatalf("ParseCertificate() error = %v", errParse)
+	}
+	return cert
+}
+
+func certRequest(cert *x509.Certificate, verified bool) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	state := &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	if verified {
+		state.VerifiedChains = [][]*x509.Certificate{{cert}}
+	}
+	req.TLS = state
+	return req
+}
+
+func TestAuthenticateUsesCommonNameByDefault(t *testing.T) {
+	p := newProvider(config.ClientCertAuthConfig{Enabled: true})
+
+	result, authErr := p.Authenticate(context.Background(), certRequest(newClientCert(t, "billing-service"), true))
+	if authErr != nil {
+		t.Fatalf("Authenticate() error = %v", authErr)
+	}
+	if result.Provider != ProviderName || result.Principal != "billing-service" || !result.PrincipalLoggable {
+		t.Fatalf("Authenticate() result = %+v", result)
+	}
+	if result.Metadata["source"] != "client-certificate" || result.Metadata["fingerprint"] == "" {
+		t.Fatalf("Authenticate() metadata = %v", result.Metadata)
+	}
+	if _, ok := result.Metadata[sdkaccess.MetadataKeyAllowedModels]; ok {
+		t.Fatalf("Authenticate() metadata has allow-list without identity policy: %v", result.Metadata)
+	}
+}
+
+func TestAuthenticateMapsSANIdentityToPrincipalAndPolicy(t *testing.T) {
+	p := newProvider(config.ClientCertAuthConfig{
+		Enabled:       true,
+		PrincipalFrom: config.ClientCertPrincipalSANURI,
+		Identities: []config.ClientCertIdentity{
+			{Match: "spiffe://corp/ns/search/*", Principal: "search", Models: []string{"gpt-5*"}},
+		},
+	})
+
+	cert := newClientCert(t, "ignored", "spiffe://corp/ns/search/sa/indexer")
+	result, authErr := p.Authenticate(context.Background(), certRequest(cert, true))
+	if authErr != nil {
+		t.Fatalf("Authenticate() error = %v", authErr)
+	}
+	if result.Principal != "search" {
+		t.Fatalf("Principal = %q, want search", result.Principal)
+	}
+	if got := result.Metadata[sdkaccess.MetadataKeyAllowedModels]; got != "gpt-5*" {
+		t.Fatalf("allowed models = %q, want gpt-5*", got)
+	}
+
+	other := newClientCert(t, "ignored", "spiffe://corp/ns/billing/sa/api")
+	_, authErr = p.Authenticate(context.Background(), certRequest(other, true))
+	if !sdkaccess.IsAuthErrorCode(authErr, sdkaccess.AuthErrorCodeInvalidCredential) {
+		t.Fatalf("Authenticate(unlisted) error = %v, want invalid credential", authErr)
+	}
+}
+
+func TestAuthenticateIgnoresUnverifiedCertificates(t *testing.T) {
+	p := newProvider(config.ClientCertAuthConfig{Enabled: true})
+
+	_, authErr := p.Authenticate(context.Background(), certRequest(newClientCert(t, "svc"), false))
+	if !sdkaccess.IsAuthErrorCode(authErr, sdkaccess.AuthErrorCodeNoCredentials) {
+		t.Fatalf("Authenticate(unverified) error = %v, want no credentials", authErr)
+	}
+	_, authErr = p.Authenticate(context.Background(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !sdkaccess.IsAuthErrorCode(authErr, sdkaccess.AuthErrorCodeNoCredentials) {
+		t.Fatalf("Authenticate(plain http) error = %v, want no credentials", authErr)
+	}
+}
+
+func TestAuthenticateReadsConnectionStateFromContext(t *testing.T) {
+	p := newProvider(config.ClientCertAuthConfig{Enabled: true})
+	cert := newClientCert(t, "sniffed")
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	state := &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}
+	req = req.WithContext(WithConnectionState(req.Context(), state))
+
+	result, authErr := p.Authenticate(context.Background(), req)
+	if authErr != nil {
+		t.Fatalf("Authenticate() error = %v", authErr)
+	}
+	if result.Principal != "sniffed" {
+		t.Fatalf("Principal = %q, want sniffed", result.Principal)
+	}
+}
@@ -0,0 +1,319 @@
+package jwtaccess
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// minForcedRefresh rate-limits reloads triggered by unknown key IDs.
+	minForcedRefresh = 30 * time.Second
+	jwksFetchTimeout = 10 * time.Second
+	// jwksRetryBackoff is how long a failed reload suppresses further reloads.
+	jwksRetryBackoff = 30 * time.Second
+	maxJWKSBodySize  = 1 << 20
+)
+
+// signingKey is a verification key parsed from a JWKS document.
+type signingKey struct {
+	kid string
+	alg string
+	key crypto.PublicKey
+}
+
+type jwksDocument struct {
+	Keys []jwkEntry `json:"keys"`
+}
+
+type jwkEntry struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// keySet caches the signing keys of one JWKS source. Keys are reloaded when the
+// refresh interval elapses, when a local file changes, or (rate-limited) when a
+// token names an unknown key ID. A failed reload keeps the previous keys, and
+// no reload is attempted for jwksRetryBackoff after a failure.
+//
+// Reloads run outside mu and are shared through a singleflight group, so
+// requests never queue behind a fetch. Once keys are cached, a stale URL
+// source is refreshed in the background while the cached keys keep serving.
+type keySet struct {
+	url     string
+	file    string
+	refresh time.Duration
+	client  *http.Client
+	now     func() time.Time
+
+	loads singleflight.Group
+
+	mu          sync.Mutex
+	keys        []signingKey
+	loadedAt    time.Time
+	fileModTime time.Time
+	lastForced  time.Time
+	failedAt    time.Time
+	lastErr     error
+}
+
+func newKeySet(url, file string, refresh time.Duration) *keySet {
+	return &keySet{
+		url:     url,
+		file:    file,
+		refresh: refresh,
+		client:  &http.Client{Timeout: jwksFetchTimeout},
+		now:     time.Now,
+	}
+}
+
+// Keys returns the cached keys, reloading them when stale. When force is set
+// the source is reloaded unless a forced reload happened recently.
+func (s *keySet) Keys(ctx context.Context, force bool) ([]signingKey, error) {
+	var fileModTime time.Time
+	fileChanged := false
+	if s.url == "" && s.file != "" {
+		if info, errStat := os.Stat(s.file); errStat == nil {
+			fileModTime = info.ModTime()
+			fileChanged = true
+		}
+	}
+
+	s.mu.Lock()
+	now := s.now()
+	keys := s.keys
+	stale := s.loadedAt.IsZero() || now.Sub(s.loadedAt) >= s.refresh
+	if force && !stale {
+		if now.Sub(s.lastForced) < minForcedRefresh {
+			s.mu.Unlock()
+			return keys, nil
+		}
+		s.lastForced = now
+		stale = true
+	}
+	if fileChanged && fileModTime.Equal(s.fileModTime) {
+		fileChanged = false
+	}
+	stale = stale || fileChanged
+	if stale && !s.failedAt.IsZero() && now.Sub(s.failedAt) < jwksRetryBackoff {
+		// The source failed recently; do not hammer it on every request.
+		stale = false
+	}
+	lastErr := s.lastErr
+	s.mu.Unlock()
+
+	if !stale {
+		if len(keys) == 0 {
+			if lastErr == nil {
+				lastErr = errors.New("jwks signing keys unavailable")
+			}
+			return nil, lastErr
+		}
+		return keys, nil
+	}
+
+	result := s.loads.DoChan("load", s.reload)
+	if len(keys) > 0 && !force && s.url != "" {
+		// Serve the stale keys while the refresh completes in the background.
+		return keys, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	select {
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.([]signingKey), nil
+	case <-ctx.Done():
+		if len(keys) > 0 {
+			return keys, nil
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// reload loads the source once for every caller waiting on it and stores the
+// outcome. It returns the keys callers should use, which are the previous
+// keys when the load failed but some were cached.
+func (s *keySet) reload() (any, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+	keys, modTime, errLoad := s.load(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if errLoad != nil {
+		s.failedAt = now
+		s.lastErr = errLoad
+		if len(s.keys) > 0 {
+			log.Warnf("jwt access: reloading signing keys failed, keeping cached keys: %v", errLoad)
+			s.loadedAt = now
+			return s.keys, nil
+		}
+		return nil, errLoad
+	}
+	s.keys = keys
+	s.loadedAt = now
+	s.fileModTime = modTime
+	s.failedAt = time.Time{}
+	s.lastErr = nil
+	return keys, nil
+}
+
+func (s *keySet) load(ctx context.Context) ([]signingKey, time.Time, error) {
+	if s.url != "" {
+		body, errFetch := s.fetch(ctx)
+		if errFetch != nil {
+			return nil, time.Time{}, errFetch
+		}
+		keys, errParse := parseJWKS(body)
+		return keys, time.Time{}, errParse
+	}
+	info, errStat := os.Stat(s.file)
+	if errStat != nil {
+		return nil, time.Time{}, fmt.Errorf("stat jwks file: %w", errStat)
+	}
+	body, errRead := os.ReadFile(s.file)
+	if errRead != nil {
+		return nil, time.Time{}, fmt.Errorf("read jwks file: %w", errRead)
+	}
+	keys, errParse := parseJWKS(body)
+	return keys, info.ModTime(), errParse
+}
+
+func (s *keySet) fetch(ctx context.Context) ([]byte, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if errReq != nil {
+		return nil, fmt.Errorf("build jwks request: %w", errReq)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, errDo := s.client.Do(req)
+	if errDo != nil {
+		return nil, fmt.Errorf("fetch jwks: %w", errDo)
+	}
+	defer func() {
+		if errClose := resp.Body.Close(); errClose != nil {
+			log.Debugf("jwt access: close jwks response body: %v", errClose)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch jwks: unexpected status %d", resp.StatusCode)
+	}
+	body, errRead := io.ReadAll(io.LimitReader(resp.Body, maxJWKSBodySize))
+	if errRead != nil {
+		return nil, fmt.Errorf("read jwks response: %w", errRead)
+	}
+	return body, nil
+}
+
+// parseJWKS extracts the RSA and P-256 signature keys from a JWKS document.
+// Unsupported entries are skipped.
+func parseJWKS(body []byte) ([]signingKey, error) {
+	var doc jwksDocument
+	if errUnmarshal := json.Unmarshal(body, &doc); errUnmarshal != nil {
+		return nil, fmt.Errorf("parse jwks: %w", errUnmarshal)
+	}
+	keys := make([]signingKey, 0, len(doc.Keys))
+	for _, entry := range doc.Keys {
+		if entry.Use != "" && entry.Use != "sig" {
+			continue
+		}
+		key, alg, errKey := entry.publicKey()
+		if errKey != nil {
+			log.Debugf("jwt access: skipping jwks key %q: %v", entry.Kid, errKey)
+			continue
+		}
+		if entry.Alg != "" && entry.Alg != alg {
+			continue
+		}
+		keys = append(keys, signingKey{kid: entry.Kid, alg: alg, key: key})
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("parse jwks: no usable RS256 or ES256 keys")
+	}
+	return keys, nil
+}
+
+func (e jwkEntry) publicKey() (crypto.PublicKey, string, error) {
+	switch e.Kty {
+	case "RSA":
+		n, errN := decodeBigInt(e.N)
+		if errN != nil {
+			return nil, "", fmt.Errorf("modulus: %w", errN)
+		}
+		exponent, errE := decodeBigInt(e.E)
+		if errE != nil {
+			return nil, "", fmt.Errorf("exponent: %w", errE)
+		}
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 || exponent.Int64() < 3 {
+			return nil, "", errors.New("exponent out of range")
+		}
+		return &rsa.PublicKey{N: n, E: int(exponent.Int64())}, algRS256, nil
+	case "EC":
+		if e.Crv != "P-256" {
+			return nil, "", fmt.Errorf("unsupported curve %q", e.Crv)
+		}
+		x, errX := decodeBigInt(e.X)
+		if errX != nil {
+			return nil, "", fmt.Errorf("x: %w", errX)
+		}
+		y, errY := decodeBigInt(e.Y)
+		if errY != nil {
+			return nil, "", fmt.Errorf("y: %w", errY)
+		}
+		if x.BitLen() > 256 || y.BitLen() > 256 {
+			return nil, "", errors.New("coordinate out of range")
+		}
+		point := make([]byte, 65)
+		point[0] = 4
+		x.FillBytes(point[1:33])
+		y.FillBytes(point[33:])
+		if _, errPoint := ecdh.P256().NewPublicKey(point); errPoint != nil {
+			return nil, "", fmt.Errorf("invalid point: %w", errPoint)
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, algES256, nil
+	default:
+		return nil, "", fmt.Errorf("unsupported key type %q", e.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, errDecode := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if errDecode != nil {
+		return nil, errDecode
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
@@ -0,0 +1,215 @@
+// Package jwtaccess implements the built-in "jwt" access provider. It accepts
+// RS256/ES256 bearer tokens signed by keys published in a JWKS document and
+// maps their claims to the access result used for model allow-lists and usage
+// attribution.
+package jwtaccess
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v7/sdk/access"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v7/sdk/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// ProviderName identifies the JWT provider in access results.
+const ProviderName = "jwt"
+
+// reservedMetadataKeys are set by the provider itself. metadata-claims entries
+// with these names are ignored so a token cannot widen its own model allow-list
+// or spoof the credential source.
+var reservedMetadataKeys = map[string]struct{}{
+	sdkaccess.MetadataKeyAllowedModels: {},
+	"source":                           {},
+	"subject":                          {},
+	"issuer":                           {},
+}
+
+var (
+	registeredMu  sync.Mutex
+	registeredKey string
+	registered    *provider
+)
+
+// Register installs the JWT provider when jwt-auth is enabled and removes it
+// otherwise. The registered instance, and with it the cached signing keys, is
+// reused while the configuration is unchanged.
+func Register(cfg *sdkconfig.SDKConfig) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+
+	if cfg == nil || !cfg.JWTAuth.Enabled || (cfg.JWTAuth.JWKSURL == "" && cfg.JWTAuth.JWKSFile == "") {
+		registered = nil
+		registeredKey = ""
+		sdkaccess.UnregisterProvider(sdkaccess.AccessProviderTypeJWT)
+		return
+	}
+	key, errKey := json.Marshal(cfg.JWTAuth)
+	if errKey != nil {
+		log.Errorf("jwt access: encode configuration: %v", errKey)
+		return
+	}
+	if registered == nil || registeredKey != string(key) {
+		registered = newProvider(cfg.JWTAuth)
+		registeredKey = string(key)
+	}
+	sdkaccess.RegisterProvider(sdkaccess.AccessProviderTypeJWT, registered)
+}
+
+type provider struct {
+	cfg            config.JWTAuthConfig
+	principalClaim string
+	metadataClaims []string
+	keys           *keySet
+	now            func() time.Time
+}
+
+func newProvider(cfg config.JWTAuthConfig) *provider {
+	metadataClaims := make([]string, 0, len(cfg.MetadataClaims))
+	for _, name := range cfg.MetadataClaims {
+		if _, reserved := reservedMetadataKeys[name]; reserved {
+			log.Warnf("jwt access: ignoring metadata claim %q, the name is reserved", name)
+			continue
+		}
+		metadataClaims = append(metadataClaims, name)
+	}
+	return &provider{
+		cfg:            cfg,
+		principalClaim: cfg.PrincipalClaimOrDefault(),
+		metadataClaims: metadataClaims,
+		keys:           newKeySet(cfg.JWKSURL, cfg.JWKSFile, cfg.RefreshIntervalOrDefault()),
+		now:            time.Now,
+	}
+}
+
+func (p *provider) Identifier() string {
+	return ProviderName
+}
+
+func (p *provider) Authenticate(ctx context.Context, r *http.Request) (*sdkaccess.Result, *sdkaccess.AuthError) {
+	if p == nil || r == nil {
+		return nil, sdkaccess.NewNotHandledError()
+	}
+	token := bearerToken(r.Header.Get("Authorization"))
+	if token == "" {
+		return nil, sdkaccess.NewNoCredentialsError()
+	}
+	if !looksLikeJWT(token) {
+		// Leave opaque bearer values to the API key providers.
+		return nil, sdkaccess.NewNotHandledError()
+	}
+
+	parsed, errParse := parseToken(token)
+	if errParse != nil {
+		log.Debugf("jwt access: rejecting token: %v", errParse)
+		return nil, sdkaccess.NewInvalidCredentialError()
+	}
+	keys, errKeys := p.keys.Keys(ctx, false)
+	if errKeys != nil {
+		return nil, sdkaccess.NewInternalAuthError("JWT signing keys unavailable", errKeys)
+	}
+	errVerify := parsed.verify(keys)
+	if errors.Is(errVerify, errUnknownKey) {
+		// The issuer may have rotated keys since the last load.
+		if keys, errKeys = p.keys.Keys(ctx, true); errKeys == nil {
+			errVerify = parsed.verify(keys)
+		}
+	}
+	if errVerify != nil {
+		log.Debugf("jwt access: rejecting token: %v", errVerify)
+		return nil, sdkaccess.NewInvalidCredentialError()
+	}
+	if errClaims := parsed.validateClaims(p.cfg.Issuer, p.cfg.Audience, p.now()); errClaims != nil {
+		log.Debugf("jwt access: rejecting token: %v", errClaims)
+		return nil, sdkaccess.NewInvalidCredentialError()
+	}
+
+	principal := ""
+	if raw, ok := claimValue(parsed.claims, p.principalClaim); ok {
+		principal = claimText(raw)
+	}
+	if principal == "" {
+		log.Debugf("jwt access: rejecting token without %q claim", p.principalClaim)
+		return nil, sdkaccess.NewInvalidCredentialError()
+	}
+
+	metadata, okModels := p.metadata(parsed.claims)
+	if !okModels {
+		// A configured allow-list claim must be present; without it the token
+		// would be allowed every model.
+		log.Debugf("jwt access: rejecting token without %q claim", p.cfg.ModelsClaim)
+		return nil, sdkaccess.NewInvalidCredentialError()
+	}
+
+	return &sdkaccess.Result{
+		Provider:          p.Identifier(),
+		Principal:         principal,
+		Metadata:          metadata,
+		PrincipalLoggable: true,
+	}, nil
+}
+
+// metadata maps token claims to access metadata. ok is false when models-claim
+// is configured but the token carries no models under it.
+func (p *provider) metadata(claims map[string]any) (metadata map[string]string, ok bool) {
+	metadata = map[string]string{
+		"source": "authorization",
+	}
+	if subject := claimText(claims["sub"]); subject != "" {
+		metadata["subject"] = subject
+	}
+	if issuer := claimText(claims["iss"]); issuer != "" {
+		metadata["issuer"] = issuer
+	}
+	for _, name := range p.metadataClaims {
+		if raw, ok := claimValue(claims, name); ok {
+			if value := claimText(raw); value != "" {
+				metadata[name] = value
+			}
+		}
+	}
+	if p.cfg.ModelsClaim == "" {
+		return metadata, true
+	}
+	raw, found := claimValue(claims, p.cfg.ModelsClaim)
+	if !found {
+		return nil, false
+	}
+	models := claimStrings(raw)
+	if len(models) == 0 {
+		return nil, false
+	}
+	metadata[sdkaccess.MetadataKeyAllowedModels] = strings.Join(models, ",")
+	return metadata, true
+}
+
+// claimText renders a scalar claim as-is and joins array claims with commas.
+func claimText(raw any) string {
+	switch v := raw.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case []any:
+		return strings.Join(claimStrings(v), ",")
+	default:
+		values := claimStrings(v)
+		if len(values) == 0 {
+			return ""
+		}
+		return values[0]
+	}
+}
+
+func bearerToken(header string) string {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
@@ -0,0 +1,363 @@
+package jwtaccess
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v7/sdk/access"
+)
+
+type testKey struct {
+	kid string
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+func newRSATestKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	key, errKey := rsa.GenerateKey(rand.Reader, 2048)
+	if errKey != nil {
+		t.Fatalf("GenerateKey() error = %v", errKey)
+	}
+	return testKey{kid: kid, rsa: key}
+}
+
+func newECTestKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	key, errKey := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if errKey != nil {
+		t.Fatalf("GenerateKey() error = %v", errKey)
+	}
+	return testKey{kid: kid, ec: key}
+}
+
+func (k testKey) jwk() map[string]string {
+	enc := base64.RawURLEncoding
+	if k.rsa != nil {
+		return map[string]string{
+			"kty": "RSA",
+			"kid": k.kid,
+			"use": "sig",
+			"n":   enc.EncodeToString(k.rsa.N.Bytes()),
+			"e":   enc.EncodeToString(big.NewInt(int64(k.rsa.E)).Bytes()),
+		}
+	}
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	k.ec.X.FillBytes(x)
+	k.ec.Y.FillBytes(y)
+	return map[string]string{
+		"kty": "EC",
+		"kid": k.kid,
+		"crv": "P-256",
+		"x":   enc.EncodeToString(x),
+		"y":   enc.EncodeToString(y),
+	}
+}
+
+func (k testKey) sign(t *testing.T, claims map[string]any) string {
+	t.Helper()
+	alg := algRS256
+	if k.ec != nil {
+		alg = algES256
+	}
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": k.kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	enc := base64.RawURLEncoding
+	input := enc.EncodeToString(header) + "." + enc.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+	var signature []byte
+	if k.rsa != nil {
+		sig, errSign := rsa.SignPKCS1v15(rand.Reader, k.rsa, crypto.SHA256, digest[:])
+		if errSign != nil {
+			t.Fatalf("SignPKCS1v15() error = %v", errSign)
+		}
+		signature = sig
+	} else {
+		r, s, errSign := ecdsa.Sign(rand.Reader, k.ec, digest[:])
+		if errSign != nil {
+			t.Fatalf("ecdsa.Sign() error = %v", errSign)
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return input + "." + enc.EncodeToString(signature)
+}
+
+func writeJWKS(t *testing.T, path string, keys ...testKey) {
+	t.Helper()
+	entries := make([]map[string]string, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, key.jwk())
+	}
+	body, _ := json.Marshal(map[string]any{"keys": entries})
+	if errWrite := os.WriteFile(path, body, 0o600); errWrite != nil {
+		t.Fatalf("WriteFile() error = %v", errWrite)
+	}
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"iss":    "https://sso.example.com",
+		"aud":    []string{"cliproxy"},
+		"sub":    "user-1",
+		"email":  "user@example.com",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"models": []string{"gpt-5*", "claude-sonnet-4"},
+	}
+}
+
+func bearerRequest(token string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func newFileProvider(t *testing.T, keys ...testKey) (*provider, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, keys...)
+	p := newProvider(config.JWTAuthConfig{
+		Enabled:        true,
+		JWKSFile:       path,
+		Issuer:         "https://sso.example.com",
+		Audience:       []string{"cliproxy"},
+		ModelsClaim:    "models",
+		MetadataClaims: []string{"email"},
+	})
+	return p, path
+}
+
+func TestAuthenticateAcceptsRS256AndES256Tokens(t *testing.T) {
+	rsaKey := newRSATestKey(t, "rsa-1")
+	ecKey := newECTestKey(t, "ec-1")
+	p, _ := newFileProvider(t, rsaKey, ecKey)
+
+	for _, key := range []testKey{rsaKey, ecKey} {
+		result, authErr := p.Authenticate(context.Background(), bearerRequest(key.sign(t, validClaims())))
+		if authErr != nil {
+			t.Fatalf("Authenticate(%s) error = %v", key.kid, authErr)
+		}
+		if result.Provider != ProviderName || result.Principal != "user-1" {
+			t.Fatalf("Authenticate(%s) result = %+v", key.kid, result)
+		}
+		if got := result.Metadata["email"]; got != "user@example.com" {
+			t.Fatalf("metadata email = %q, want user@example.com", got)
+		}
+		if got := result.Metadata[sdkaccess.MetadataKeyAllowedModels]; got != "gpt-5*,claude-sonnet-4" {
+			t.Fatalf("metadata allowed-models = %q", got)
+		}
+	}
+}
+
+func TestAuthenticateRejectsInvalidTokens(t *testing.T) {
+	key := newRSATestKey(t, "rsa-1")
+	other := newRSATestKey(t, "rsa-1")
+	p, _ := newFileProvider(t, key)
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	wrongIssuer := validClaims()
+	wrongIssuer["iss"] = "https://evil.example.com"
+	wrongAudience := validClaims()
+	wrongAudience["aud"] = "someone-else"
+	noExpiry := validClaims()
+	delete(noExpiry, "exp")
+	noModels := validClaims()
+	delete(noModels, "models")
+	emptyModels := validClaims()
+	emptyModels["models"] = []string{}
+
+	cases := map[string]string{
+		"expired":         key.sign(t, expired),
+		"wrong issuer":    key.sign(t, wrongIssuer),
+		"wrong audience":  key.sign(t, wrongAudience),
+		"no expiry":       key.sign(t, noExpiry),
+		"wrong signature": other.sign(t, validClaims()),
+		"no models":       key.sign(t, noModels),
+		"empty models":    key.sign(t, emptyModels),
+	}
+	for name, token := range cases {
+		_, authErr := p.Authenticate(context.Background(), bearerRequest(token))
+		if !sdkaccess.IsAuthErrorCode(authErr, sdkaccess.AuthErrorCodeInvalidCredential) {
+			t.Fatalf("%s: Authenticate() error = %v, want invalid credential", name, authErr)
+		}
+	}
+}
+
+func TestAuthenticateIgnoresReservedMetadataClaims(t *testing.T) {
+	key := newRSATestKey(t, "rsa-1")
+	_, path := newFileProvider(t, key)
+	p := newProvider(config.JWTAuthConfig{
+		Enabled:        true,
+		JWKSFile:       path,
+		Issuer:         "https://sso.example.com",
+		Audience:       []string{"cliproxy"},
+		MetadataClaims: []string{sdkaccess.MetadataKeyAllowedModels, "source", "email"},
+	})
+
+	claims := validClaims()
+	claims[sdkaccess.MetadataKeyAllowedModels] = "*"
+	claims["source"] = "api-key"
+	result, authErr := p.Authenticate(context.Background(), bearerRequest(key.sign(t, claims)))
+	if authErr != nil {
+		t.Fatalf("Authenticate() error = %v", authErr)
+	}
+	if _, ok := result.Metadata[sdkaccess.MetadataKeyAllowedModels]; ok || result.Metadata["source"] != "authorization" {
+		t.Fatalf("metadata = %v, want reserved keys set only by the provider", result.Metadata)
+	}
+	if result.Metadata["email"] != "user@example.com" {
+		t.Fatalf("metadata email = %q, want user@example.com", result.Metadata["email"])
+	}
+}
+
+func TestAuthenticateLeavesOpaqueBearerValuesUnhandled(t *testing.T) {
+	p, _ := newFileProvider(t, newRSATestKey(t, "rsa-1"))
+
+	_, authErr := p.Authenticate(context.Background(), bearerRequest("sk-plain-api-key"))
+	if !sdkaccess.IsAuthErrorCode(authErr, sdkaccess.AuthErrorCodeNotHandled) {
+		t.Fatalf("Authenticate() error = %v, want not handled", authErr)
+	}
+	_, authErr = p.Authenticate(context.Background(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !sdkaccess.IsAuthErrorCode(authErr, sdkaccess.AuthErrorCodeNoCredentials) {
+		t.Fatalf("Authenticate() error = %v, want no credentials", authErr)
+	}
+}
+
+func TestAuthenticateReloadsRotatedKeysFile(t *testing.T) {
+	oldKey := newRSATestKey(t, "old")
+	newKey := newECTestKey(t, "new")
+	p, path := newFileProvider(t, oldKey)
+
+	if _, authErr := p.Authenticate(context.Background(), bearerRequest(oldKey.sign(t, validClaims()))); authErr != nil {
+		t.Fatalf("Authenticate(old) error = %v", authErr)
+	}
+
+	writeJWKS(t, path, newKey)
+	future := time.Now().Add(time.Minute)
+	if errTimes := os.Chtimes(path, future, future); errTimes != nil {
+		t.Fatalf("Chtimes() error = %v", errTimes)
+	}
+
+	if _, authErr := p.Authenticate(context.Background(), bearerRequest(newKey.sign(t, validClaims()))); authErr != nil {
+		t.Fatalf("Authenticate(new) error = %v", authErr)
+	}
+	_, authErr := p.Authenticate(context.Background(), bearerRequest(oldKey.sign(t, validClaims())))
+	if !sdkaccess.IsAuthErrorCode(authErr, sdkaccess.AuthErrorCodeInvalidCredential) {
+		t.Fatalf("Authenticate(old after rotation) error = %v, want invalid credential", authErr)
+	}
+}
+
+func TestAuthenticateRefetchesURLOnUnknownKeyID(t *testing.T) {
+	first := newRSATestKey(t, "first")
+	second := newRSATestKey(t, "second")
+	current := []testKey{first}
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches++
+		entries := make([]map[string]string, 0, len(current))
+		for _, key := range current {
+			entries = append(entries, key.jwk())
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": entries})
+	}))
+	defer server.Close()
+
+	p := newProvider(config.JWTAuthConfig{Enabled: true, JWKSURL: server.URL})
+	if _, authErr := p.Authenticate(context.Background(), bearerRequest(first.sign(t, validClaims()))); authErr != nil {
+		t.Fatalf("Authenticate(first) error = %v", authErr)
+	}
+	if _, authErr := p.Authenticate(context.Background(), bearerRequest(first.sign(t, validClaims()))); authErr != nil {
+		t.Fatalf("Authenticate(first, cached) error = %v", authErr)
+	}
+	if fetches != 1 {
+		t.Fatalf("fetches = %d, want 1 while keys are cached", fetches)
+	}
+
+	current = []testKey{first, second}
+	if _, authErr := p.Authenticate(context.Background(), bearerRequest(second.sign(t, validClaims()))); authErr != nil {
+		t.Fatalf("Authenticate(second) error = %v", authErr)
+	}
+	if fetches != 2 {
+		t.Fatalf("fetches = %d, want 2 after unknown key ID", fetches)
+	}
+}
+
+func TestKeysBacksOffWhileJWKSEndpointIsDown(t *testing.T) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	set := newKeySet(server.URL, "", time.Hour)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, errKeys := set.Keys(context.Background(), false); errKeys == nil {
+				t.Error("Keys() error = nil while the endpoint is down")
+			}
+		}()
+	}
+	wg.Wait()
+	if _, errKeys := set.Keys(context.Background(), true); errKeys == nil {
+		t.Fatal("Keys() after failure error = nil, want the last fetch error")
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Fatalf("fetches = %d, want one shared fetch and no retry inside the backoff", got)
+	}
+}
+
+func TestKeysServesCachedKeysDuringSlowRefresh(t *testing.T) {
+	key := newRSATestKey(t, "cached")
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if fetches.Add(1) > 1 {
+			<-release
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{key.jwk()}})
+	}))
+	defer server.Close()
+	defer close(release)
+
+	set := newKeySet(server.URL, "", time.Minute)
+	if _, errKeys := set.Keys(context.Background(), false); errKeys != nil {
+		t.Fatalf("Keys() error = %v", errKeys)
+	}
+	later := time.Now().Add(2 * time.Minute)
+	set.mu.Lock()
+	set.now = func() time.Time { return later }
+	set.mu.Unlock()
+
+	started := time.Now()
+	keys, errKeys := set.Keys(context.Background(), false)
+	if errKeys != nil || len(keys) != 1 {
+		t.Fatalf("Keys() = %d keys, %v; want the cached key", len(keys), errKeys)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Fatalf("Keys() waited %s for the refresh", elapsed)
+	}
+}
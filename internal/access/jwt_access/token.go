@@ -0,0 +1,236 @@
+package jwtaccess
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	algRS256 = "RS256"
+	algES256 = "ES256"
+
+	// clockLeeway tolerates small clock differences with the token issuer.
+	clockLeeway = time.Minute
+)
+
+var (
+	errUnknownKey       = errors.New("no signing key matches token")
+	errInvalidSignature = errors.New("invalid token signature")
+)
+
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// parsedToken is a structurally valid compact JWS whose signature is not yet verified.
+type parsedToken struct {
+	header       tokenHeader
+	claims       map[string]any
+	signingInput []byte
+	signature    []byte
+}
+
+// looksLikeJWT reports whether token has the three-segment compact shape.
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2 && !strings.ContainsAny(token, " \t")
+}
+
+func parseToken(token string) (*parsedToken, error) {
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	headerRaw, errHeader := base64.RawURLEncoding.DecodeString(segments[0])
+	if errHeader != nil {
+		return nil, fmt.Errorf("decode token header: %w", errHeader)
+	}
+	var header tokenHeader
+	if errUnmarshal := json.Unmarshal(headerRaw, &header); errUnmarshal != nil {
+		return nil, fmt.Errorf("parse token header: %w", errUnmarshal)
+	}
+	if header.Alg != algRS256 && header.Alg != algES256 {
+		return nil, fmt.Errorf("unsupported token algorithm %q", header.Alg)
+	}
+	payloadRaw, errPayload := base64.RawURLEncoding.DecodeString(segments[1])
+	if errPayload != nil {
+		return nil, fmt.Errorf("decode token claims: %w", errPayload)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(payloadRaw))
+	decoder.UseNumber()
+	var claims map[string]any
+	if errDecode := decoder.Decode(&claims); errDecode != nil || claims == nil {
+		return nil, errors.New("parse token claims: claims must be a JSON object")
+	}
+	signature, errSignature := base64.RawURLEncoding.DecodeString(segments[2])
+	if errSignature != nil {
+		return nil, fmt.Errorf("decode token signature: %w", errSignature)
+	}
+	return &parsedToken{
+		header:       header,
+		claims:       claims,
+		signingInput: []byte(segments[0] + "." + segments[1]),
+		signature:    signature,
+	}, nil
+}
+
+// verify checks the signature against the candidate keys. It returns
+// errUnknownKey when no key has a matching ID and algorithm.
+func (t *parsedToken) verify(keys []signingKey) error {
+	digest := sha256.Sum256(t.signingInput)
+	matched := false
+	for _, key := range keys {
+		if key.alg != t.header.Alg {
+			continue
+		}
+		if t.header.Kid != "" && key.kid != t.header.Kid {
+			continue
+		}
+		matched = true
+		if verifySignature(key, digest[:], t.signature) {
+			return nil
+		}
+	}
+	if !matched {
+		return errUnknownKey
+	}
+	return errInvalidSignature
+}
+
+func verifySignature(key signingKey, digest, signature []byte) bool {
+	switch pub := key.key.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		// JWS encodes ES256 signatures as the fixed-width concatenation r || s.
+		if len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(pub, digest, r, s)
+	default:
+		return false
+	}
+}
+
+// validateClaims checks the registered time, issuer and audience claims.
+func (t *parsedToken) validateClaims(issuer string, audience []string, now time.Time) error {
+	exp, hasExp, errExp := t.numericDate("exp")
+	if errExp != nil {
+		return errExp
+	}
+	if !hasExp {
+		return errors.New("token has no expiry")
+	}
+	if now.After(exp.Add(clockLeeway)) {
+		return errors.New("token expired")
+	}
+	if nbf, hasNbf, errNbf := t.numericDate("nbf"); errNbf != nil {
+		return errNbf
+	} else if hasNbf && now.Add(clockLeeway).Before(nbf) {
+		return errors.New("token not yet valid")
+	}
+	if issuer != "" {
+		if iss, _ := t.claims["iss"].(string); iss != issuer {
+			return errors.New("token issuer mismatch")
+		}
+	}
+	if len(audience) > 0 && !audienceMatches(t.claims["aud"], audience) {
+		return errors.New("token audience mismatch")
+	}
+	return nil
+}
+
+func (t *parsedToken) numericDate(name string) (time.Time, bool, error) {
+	raw, ok := t.claims[name]
+	if !ok || raw == nil {
+		return time.Time{}, false, nil
+	}
+	number, ok := raw.(json.Number)
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("claim %q is not a number", name)
+	}
+	seconds, errParse := number.Float64()
+	if errParse != nil {
+		return time.Time{}, false, fmt.Errorf("claim %q: %w", name, errParse)
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), true, nil
+}
+
+func audienceMatches(raw any, audience []string) bool {
+	values := []string{}
+	switch v := raw.(type) {
+	case string:
+		values = append(values, v)
+	case []any:
+		for _, item := range v {
+			if value, ok := item.(string); ok {
+				values = append(values, value)
+			}
+		}
+	}
+	for _, value := range values {
+		for _, want := range audience {
+			if value == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// claimStrings flattens a string, number or array claim into strings. A
+// string containing spaces or commas is split, matching the common "scope" style.
+func claimStrings(raw any) []string {
+	switch v := raw.(type) {
+	case string:
+		return strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	case json.Number:
+		return []string{v.String()}
+	case bool:
+		if v {
+			return []string{"true"}
+		}
+		return []string{"false"}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			out = append(out, claimStrings(item)...)
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// claimValue resolves a claim name, allowing dotted paths into nested objects
+// (for example "realm_access.roles").
+func claimValue(claims map[string]any, name string) (any, bool) {
+	if value, ok := claims[name]; ok {
+		return value, true
+	}
+	var current any = claims
+	for _, segment := range strings.Split(name, ".") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = object[segment]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
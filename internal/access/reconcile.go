@@ -6,7 +6,9 @@ import (
 	"sort"
 	"strings"
 
+	clientcertaccess "github.com/router-for-me/CLIProxyAPI/v7/internal/access/client_cert_access"
 	configaccess "github.com/router-for-me/CLIProxyAPI/v7/internal/access/config_access"
+	jwtaccess "github.com/router-for-me/CLIProxyAPI/v7/internal/access/jwt_access"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v7/sdk/access"
 	log "github.com/sirupsen/logrus"
@@ -86,6 +88,8 @@ func ApplyAccessProviders(manager *sdkaccess.Manager, oldCfg, newCfg *config.Con
 
 	existing := manager.Providers()
 	configaccess.Register(&newCfg.SDKConfig)
+	jwtaccess.Register(&newCfg.SDKConfig)
+	clientcertaccess.Register(&newCfg.SDKConfig)
 	providers, added, updated, removed, err := ReconcileProviders(oldCfg, newCfg, existing)
 	if err != nil {
 		log.Errorf("failed to reconcile request auth providers: %v", err)
@@ -2,8 +2,11 @@ package api
 
 import (
 	"bufio"
+	"context"
 	"crypto/tls"
 	"net"
+
+	clientcertaccess "github.com/router-for-me/CLIProxyAPI/v7/internal/access/client_cert_access"
 )
 
 type bufferedConn struct {
@@ -30,3 +33,17 @@ func (c *bufferedConn) ConnectionState() tls.ConnectionState {
 	}
 	return tls.ConnectionState{}
 }
+
+// muxConnContext exposes the TLS state of sniffed connections, which net/http
+// only reports as Request.TLS for bare *tls.Conn values.
+func muxConnContext(ctx context.Context, conn net.Conn) context.Context {
+	buffered, ok := conn.(*bufferedConn)
+	if !ok {
+		return ctx
+	}
+	if _, isTLS := buffered.Conn.(*tls.Conn); !isTLS {
+		return ctx
+	}
+	state := buffered.ConnectionState()
+	return clientcertaccess.WithConnectionState(ctx, &state)
+}
@@ -0,0 +1,244 @@
+package management
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/audit"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/watcher/diff"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	auditConfigChangesContextKey = "managementAuditConfigChanges"
+	auditDefaultFileName         = "audit.jsonl"
+	// auditMaxSummaryBodyBytes bounds how much of a JSON body is read to list its fields.
+	auditMaxSummaryBodyBytes = 64 << 10
+	auditRedactedValue       = "[redacted]"
+)
+
+// auditRecorder returns the recorder for the current config, or nil when auditing is disabled.
+func (h *Handler) auditRecorder() *audit.Recorder {
+	if h == nil || h.cfg == nil || !h.cfg.RemoteManagement.AuditLog.Enabled {
+		return nil
+	}
+	auditCfg := h.cfg.RemoteManagement.AuditLog
+	return audit.NewRecorder(h.auditStore(auditCfg), auditCfg.ForwardToHome && h.cfg.Home.Enabled)
+}
+
+// auditStore resolves the configured backend. The postgres backend falls back to
+// the JSONL file when the Postgres store is not in use.
+func (h *Handler) auditStore(auditCfg config.AuditLogConfig) audit.Store {
+	if auditCfg.Backend == config.AuditLogBackendPostgres {
+		if store := audit.DatabaseStore(); store != nil {
+			return store
+		}
+		h.auditFallbackOnce.Do(func() {
+			log.Warn("remote-management.audit-log.backend is postgres but the Postgres store is not enabled; using the audit file")
+		})
+	}
+	path := auditCfg.File
+	if path == "" {
+		path = filepath.Join(h.logDirectory(), auditDefaultFileName)
+	}
+	h.auditMu.Lock()
+	defer h.auditMu.Unlock()
+	if h.auditFile == nil || h.auditFile.Path() != path {
+		h.auditFile = audit.NewFileStore(path)
+	}
+	return h.auditFile
+}
+
+// auditCall records a mutating management call. Read-only calls are skipped.
+func (h *Handler) auditCall(c *gin.Context, identity ManagementIdentity, summary *audit.RequestSummary) {
+	if !isMutatingMethod(c.Request.Method) {
+		return
+	}
+	recorder := h.auditRecorder()
+	if recorder == nil {
+		return
+	}
+	entry := audit.Entry{
+		Timestamp: time.Now(),
+		ClientIP:  c.ClientIP(),
+		Key:       identity.Name,
+		Role:      identity.Role,
+		Method:    c.Request.Method,
+		Route:     c.FullPath(),
+		Path:      c.Request.URL.Path,
+		Status:    c.Writer.Status(),
+		Request:   summary,
+	}
+	if raw, ok := c.Get(auditConfigChangesContextKey); ok {
+		if lines, okLines := raw.([]string); okLines {
+			entry.ConfigChanges = audit.ParseConfigChanges(lines)
+		}
+	}
+	recorder.Record(c.Request.Context(), entry)
+}
+
+// summarizeAuditRequest describes the request for the audit trail. Query values
+// that look like secrets are redacted and only the top-level field names of a
+// JSON body are kept.
+func (h *Handler) summarizeAuditRequest(c *gin.Context) *audit.RequestSummary {
+	if !isMutatingMethod(c.Request.Method) || h.auditRecorder() == nil {
+		return nil
+	}
+	req := c.Request
+	summary := &audit.RequestSummary{
+		ContentType:   req.Header.Get("Content-Type"),
+		ContentLength: req.ContentLength,
+	}
+	if values := req.URL.Query(); len(values) > 0 {
+		summary.Query = make(map[string]string, len(values))
+		for name, list := range values {
+			if isSensitiveAuditField(name) {
+				summary.Query[name] = auditRedactedValue
+				continue
+			}
+			summary.Query[name] = strings.Join(list, ",")
+		}
+	}
+	mediaType, _, _ := mime.ParseMediaType(summary.ContentType)
+	if req.Body == nil || req.Body == http.NoBody || mediaType != "application/json" {
+		return summary
+	}
+	head, errRead := io.ReadAll(io.LimitReader(req.Body, auditMaxSummaryBodyBytes+1))
+	req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(head), req.Body), Closer: req.Body}
+	if errRead != nil || len(head) > auditMaxSummaryBodyBytes {
+		return summary
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(head, &fields) == nil {
+		summary.Fields = make([]string, 0, len(fields))
+		for name := range fields {
+			summary.Fields = append(summary.Fields, name)
+		}
+		sort.Strings(summary.Fields)
+	}
+	return summary
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
+func isSensitiveAuditField(name string) bool {
+	lower := strings.ToLower(name)
+	for _, marker := range []string{"key", "secret", "token", "password", "credential"} {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordConfigChanges stores the diff between the config file on disk and the
+// in-memory config for the audit entry of the current request. It must be called
+// before the new config is written. Callers must hold h.mu.
+func (h *Handler) recordConfigChanges(c *gin.Context) func() {
+	if c == nil || h.auditRecorder() == nil {
+		return func() {}
+	}
+	data, errRead := os.ReadFile(h.configFilePath)
+	if errRead != nil {
+		return func() {}
+	}
+	previous, errParse := config.ParseConfigBytes(data)
+	if errParse != nil {
+		return func() {}
+	}
+	// Compare against the values the running config resolved its secret
+	// references to instead of resolving them again.
+	previous.MapSecretRefs(h.cfg.SecretRefs)
+	return func() {
+		setAuditConfigChanges(c, previous, h.cfg)
+	}
+}
+
+func setAuditConfigChanges(c *gin.Context, previous, current *config.Config) {
+	if c == nil || previous == nil || current == nil {
+		return
+	}
+	if changes := diff.BuildConfigChangeDetails(previous, current); len(changes) > 0 {
+		c.Set(auditConfigChangesContextKey, changes)
+	}
+}
+
+// saveConfigLocked writes h.cfg to disk and records the resulting config diff
+// for the audit trail. Secret references echoed back by clients are mapped to
+// the values they resolved to so only the references reach the file. Callers
+// must hold h.mu.
+func (h *Handler) saveConfigLocked(c *gin.Context) error {
+	if errRefs := h.cfg.ApplySecretRefs(); errRefs != nil {
+		return errRefs
+	}
+	recordChanges := h.recordConfigChanges(c)
+	if errSave := config.SaveConfigPreserveComments(h.configFilePath, h.cfg); errSave != nil {
+		return errSave
+	}
+	recordChanges()
+	return nil
+}
+
+// GetAudit returns audit entries, newest first.
+// Query parameters: since, until (RFC3339), key, role, method, path (prefix), limit.
+func (h *Handler) GetAudit(c *gin.Context) {
+	if h == nil || h.cfg == nil || !h.cfg.RemoteManagement.AuditLog.Enabled {
+		c.JSON(http.StatusNotFound, gin.H{"error": "audit log disabled"})
+		return
+	}
+	filter := audit.Filter{
+		Key:        strings.TrimSpace(c.Query("key")),
+		Role:       strings.TrimSpace(c.Query("role")),
+		Method:     strings.TrimSpace(c.Query("method")),
+		PathPrefix: strings.TrimSpace(c.Query("path")),
+	}
+	for name, target := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		raw := strings.TrimSpace(c.Query(name))
+		if raw == "" {
+			continue
+		}
+		parsed, errParse := time.Parse(time.RFC3339, raw)
+		if errParse != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name + ", expected RFC3339"})
+			return
+		}
+		*target = parsed
+	}
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		limit, errLimit := strconv.Atoi(raw)
+		if errLimit != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		filter.Limit = limit
+	}
+	entries, errQuery := h.auditStore(h.cfg.RemoteManagement.AuditLog).Query(c.Request.Context(), filter)
+	if errQuery != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errQuery.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
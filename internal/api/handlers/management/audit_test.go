@@ -0,0 +1,113 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/audit"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+func newAuditHandler(t *testing.T) (*Handler, string) {
+	t.Helper()
+	auditPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	cfg := &config.Config{}
+	cfg.RemoteManagement.AuditLog = config.AuditLogConfig{Enabled: true, Backend: config.AuditLogBackendFile, File: auditPath}
+	return &Handler{cfg: cfg, failedAttempts: make(map[string]*attemptInfo), envSecret: "test-secret"}, auditPath
+}
+
+func TestMiddlewareRecordsAuditEntryForMutations(t *testing.T) {
+	h, auditPath := newAuditHandler(t)
+	engine := gin.New()
+	var body string
+	engine.PATCH("/v0/management/auth-files/status", h.Middleware(), func(c *gin.Context) {
+		raw, _ := io.ReadAll(c.Request.Body)
+		body = string(raw)
+		c.Status(http.StatusOK)
+	})
+	engine.GET("/v0/management/audit", h.Middleware(), h.GetAudit)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPatch, "/v0/management/auth-files/status?name=a.json&api_key=sk-secret", strings.NewReader(`{"name":"a.json","disabled":true}`))
+	req.RemoteAddr = "127.0.0.1:12345"
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Management-Key", "test-secret")
+	engine.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PATCH status = %d, want 200", rec.Code)
+	}
+	if body != `{"name":"a.json","disabled":true}` {
+		t.Fatalf("handler body = %q, want original body", body)
+	}
+
+	entries, errQuery := audit.NewFileStore(auditPath).Query(context.Background(), audit.Filter{})
+	if errQuery != nil {
+		t.Fatalf("Query() error = %v", errQuery)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("entries = %+v, want 1", entries)
+	}
+	entry := entries[0]
+	if entry.Key != managementKeyNameEnv || entry.Role != config.ManagementRoleAdmin || entry.Route != "/v0/management/auth-files/status" || entry.Status != http.StatusOK {
+		t.Fatalf("entry = %+v", entry)
+	}
+	if entry.Request == nil || entry.Request.Query["api_key"] != auditRedactedValue || entry.Request.Query["name"] != "a.json" {
+		t.Fatalf("entry request = %+v", entry.Request)
+	}
+	if strings.Join(entry.Request.Fields, ",") != "disabled,name" {
+		t.Fatalf("entry fields = %v", entry.Request.Fields)
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/v0/management/audit?method=patch&limit=10", nil)
+	req.RemoteAddr = "127.0.0.1:12345"
+	req.Header.Set("X-Management-Key", "test-secret")
+	engine.ServeHTTP(rec, req)
+	var payload struct {
+		Entries []audit.Entry `json:"entries"`
+	}
+	if errDecode := json.Unmarshal(rec.Body.Bytes(), &payload); errDecode != nil {
+		t.Fatalf("decode audit response: %v", errDecode)
+	}
+	if rec.Code != http.StatusOK || len(payload.Entries) != 1 {
+		t.Fatalf("GET /audit = %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestSaveConfigLockedRecordsConfigDiff(t *testing.T) {
+	h, _ := newAuditHandler(t)
+	h.configFilePath = filepath.Join(t.TempDir(), "config.yaml")
+	if errWrite := os.WriteFile(h.configFilePath, []byte("debug: false\nrequest-retry: 1\n"), 0o600); errWrite != nil {
+		t.Fatalf("write config: %v", errWrite)
+	}
+	h.cfg.Debug = true
+	h.cfg.RequestRetry = 1
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPut, "/v0/management/debug", nil)
+	if errSave := h.saveConfigLocked(c); errSave != nil {
+		t.Fatalf("saveConfigLocked() error = %v", errSave)
+	}
+	raw, ok := c.Get(auditConfigChangesContextKey)
+	if !ok {
+		t.Fatalf("config changes not recorded")
+	}
+	changes := audit.ParseConfigChanges(raw.([]string))
+	found := false
+	for _, change := range changes {
+		if change.Field == "debug" && change.Before == "false" && change.After == "true" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("changes = %+v, want debug false -> true", changes)
+	}
+}
@@ -0,0 +1,56 @@
+package management
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+)
+
+func patchAuthFileStatus(h *Handler, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/v0/management/auth-files/status", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	h.PatchAuthFileStatus(c)
+	return rec
+}
+
+func TestPatchAuthFileStatusDrainsAndReactivates(t *testing.T) {
+	manager := coreauth.NewManager(nil, nil, nil)
+	if _, errRegister := manager.Register(context.Background(), &coreauth.Auth{ID: "drain-auth", FileName: "drain.json", Provider: "claude", Status: coreauth.StatusActive}); errRegister != nil {
+		t.Fatalf("Register() error = %v", errRegister)
+	}
+	h := NewHandlerWithoutConfigFilePath(&config.Config{AuthDir: t.TempDir()}, manager)
+
+	rec := patchAuthFileStatus(h, `{"name":"drain.json","status":"draining","drain_timeout":"bogus"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid drain_timeout status = %d, want 400", rec.Code)
+	}
+
+	rec = patchAuthFileStatus(h, `{"name":"drain.json","status":"draining","drain_timeout":"30m"}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "draining_until") {
+		t.Fatalf("drain status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	if current, _ := manager.GetByID("drain-auth"); current.Status != coreauth.StatusDraining || !current.IsDraining() {
+		t.Fatalf("auth after drain = %+v", current)
+	}
+
+	rec = patchAuthFileStatus(h, `{"name":"drain.json","status":"active"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("reactivate status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	if current, _ := manager.GetByID("drain-auth"); current.Status != coreauth.StatusActive || current.IsDraining() {
+		t.Fatalf("auth after reactivation = %+v, want the drain cancelled", current)
+	}
+
+	rec = patchAuthFileStatus(h, `{"name":"drain.json","status":"paused"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown status = %d, want 400", rec.Code)
+	}
+}
@@ -15,12 +15,20 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/credentialschedule"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/credentialweight"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/envelope"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v7/sdk/auth"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
 )
 
-// PatchAuthFileStatus toggles the disabled state of an auth file
+// PatchAuthFileStatus toggles the disabled state of an auth file, or starts
+// draining it.
+//
+// Body: {"name": "...", "disabled": true} or {"name": "...", "status":
+// "active" | "disabled" | "draining", "drain_timeout": "30m"}. A draining
+// credential serves only the sessions already bound to it and is disabled once
+// they idle out or drain_timeout (default 1h) passes.
 func (h *Handler) PatchAuthFileStatus(c *gin.Context) {
 	if h.authManager == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
@@ -28,9 +36,11 @@ func (h *Handler) PatchAuthFileStatus(c *gin.Context) {
 	}
 
 	var req struct {
-		Name      string `json:"name"`
-		AuthIndex string `json:"auth_index"`
-		Disabled  *bool  `json:"disabled"`
+		Name         string `json:"name"`
+		AuthIndex    string `json:"auth_index"`
+		Disabled     *bool  `json:"disabled"`
+		Status       string `json:"status"`
+		DrainTimeout string `json:"drain_timeout"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
@@ -43,7 +53,19 @@ func (h *Handler) PatchAuthFileStatus(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
 		return
 	}
-	if req.Disabled == nil {
+	draining := false
+	switch coreauth.Status(strings.ToLower(strings.TrimSpace(req.Status))) {
+	case "":
+	case coreauth.StatusActive, coreauth.StatusDisabled:
+		disabled := strings.EqualFold(strings.TrimSpace(req.Status), string(coreauth.StatusDisabled))
+		req.Disabled = &disabled
+	case coreauth.StatusDraining:
+		draining = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be active, disabled or draining"})
+		return
+	}
+	if req.Disabled == nil && !draining {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "disabled is required"})
 		return
 	}
@@ -55,6 +77,10 @@ func (h *Handler) PatchAuthFileStatus(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "auth file not found"})
 		return
 	}
+	if draining {
+		h.drainAuthFile(c, targetAuth, req.DrainTimeout)
+		return
+	}
 	if coreauth.IsPluginVirtualAuth(targetAuth) {
 		// Allow status changes only when targeting the source auth file name, matching delete semantics.
 		// Expanded virtual project auths still cannot be modified independently.
@@ -105,15 +131,51 @@ func (h *Handler) PatchAuthFileStatus(c *gin.Context) {
 		return
 	}
 
+	wasDraining := targetAuth.IsDraining()
 	applyAuthDisabledState(targetAuth, *req.Disabled)
 	if _, err := h.authManager.Update(ctx, targetAuth); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to update auth: %v", err)})
 		return
 	}
+	if wasDraining && !*req.Disabled {
+		// Re-enabling a draining credential cancels the drain.
+		if _, err := h.authManager.SetDraining(ctx, targetAuth.ID, time.Time{}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to cancel drain: %v", err)})
+			return
+		}
+	}
 
 	c.JSON(http.StatusOK, gin.H{"status": "ok", "disabled": *req.Disabled})
 }
 
+// drainAuthFile starts draining targetAuth for timeout, a Go duration string.
+func (h *Handler) drainAuthFile(c *gin.Context, targetAuth *coreauth.Auth, timeout string) {
+	if coreauth.IsPluginVirtualAuth(targetAuth) || coreauth.IsConfigAPIKeyAuth(targetAuth) {
+		c.JSON(http.StatusConflict, gin.H{"error": "draining is only supported for auth files"})
+		return
+	}
+	drainTimeout := coreauth.DefaultDrainTimeout
+	if timeout = strings.TrimSpace(timeout); timeout != "" {
+		parsed, errParse := time.ParseDuration(timeout)
+		if errParse != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "drain_timeout must be a positive duration"})
+			return
+		}
+		drainTimeout = parsed
+	}
+	updated, errDrain := h.authManager.SetDraining(c.Request.Context(), targetAuth.ID, time.Now().Add(drainTimeout))
+	if errDrain != nil {
+		status := http.StatusInternalServerError
+		var authErr *coreauth.Error
+		if errors.As(errDrain, &authErr) && authErr.HTTPStatus != 0 {
+			status = authErr.HTTPStatus
+		}
+		c.JSON(status, gin.H{"error": errDrain.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "disabled": false, "draining_until": updated.DrainingUntil})
+}
+
 // patchPluginVirtualSourceStatus toggles disabled on a plugin multi-auth source file and all
 // runtime auths expanded from it. Virtual project children cannot be toggled independently.
 func (h *Handler) patchPluginVirtualSourceStatus(ctx context.Context, targetAuth *coreauth.Auth, disabled bool) error {
@@ -156,7 +218,7 @@ func setSourceAuthFileDisabled(path string, disabled bool) error {
 	if path == "" {
 		return fmt.Errorf("source auth path is empty")
 	}
-	data, errRead := os.ReadFile(path)
+	data, errRead := envelope.ReadFile(path)
 	if errRead != nil {
 		return errRead
 	}
@@ -175,7 +237,7 @@ func setSourceAuthFileDisabled(path string, disabled bool) error {
 	if errMarshal != nil {
 		return fmt.Errorf("marshal auth file: %w", errMarshal)
 	}
-	if errWrite := os.WriteFile(path, raw, 0o600); errWrite != nil {
+	if errWrite := envelope.WriteFile(path, raw, 0o600); errWrite != nil {
 		return errWrite
 	}
 	return nil
@@ -309,6 +371,20 @@ func (h *Handler) PatchAuthFileFields(c *gin.Context) {
 		} else if rootAuthFileField(fieldPath) == coreauth.AttributeWeight {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "weight does not support nested fields"})
 			return
+		} else if fieldPath == coreauth.AttributeSchedule {
+			schedule, errSchedule := credentialschedule.ParseValue(value)
+			if errSchedule != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": errSchedule.Error()})
+				return
+			}
+			if schedule.IsZero() {
+				delete(targetAuth.Metadata, coreauth.AttributeSchedule)
+			} else {
+				targetAuth.Metadata[coreauth.AttributeSchedule] = schedule
+			}
+		} else if rootAuthFileField(fieldPath) == coreauth.AttributeSchedule {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "schedule does not support nested fields"})
+			return
 		} else if fieldPath == "headers" {
 			applyAuthFileHeadersPatch(targetAuth, value)
 		} else if errSet := setAuthFileMetadataValue(targetAuth.Metadata, fieldPath, value); errSet != nil {
@@ -558,6 +634,9 @@ func syncAuthFileMetadataFields(auth *coreauth.Auth, touchedRoots map[string]str
 	if _, ok := touchedRoots[coreauth.AttributeWeight]; ok {
 		syncAuthFileWeightAttribute(auth)
 	}
+	if _, ok := touchedRoots[coreauth.AttributeSchedule]; ok {
+		syncAuthFileScheduleAttribute(auth)
+	}
 	if _, ok := touchedRoots["note"]; ok {
 		syncAuthFileNoteAttribute(auth)
 	}
@@ -620,6 +699,19 @@ func syncAuthFileWeightAttribute(auth *coreauth.Auth) {
 	auth.Attributes[coreauth.AttributeWeight] = strconv.FormatInt(weight, 10)
 }
 
+func syncAuthFileScheduleAttribute(auth *coreauth.Auth) {
+	if auth == nil {
+		return
+	}
+	if auth.Attributes == nil {
+		auth.Attributes = make(map[string]string)
+	}
+	delete(auth.Attributes, coreauth.AttributeSchedule)
+	if errSchedule := coreauth.ApplyAuthScheduleMetadata(auth, auth.Metadata); errSchedule != nil {
+		delete(auth.Attributes, coreauth.AttributeSchedule)
+	}
+}
+
 func authFileIntValue(value any) (int, bool) {
 	switch typed := value.(type) {
 	case int:
@@ -815,7 +907,7 @@ func (h *Handler) mergeExistingAuthFileMetadata(record *coreauth.Auth) {
 		}
 		if targetFile != "" {
 			fullPath := filepath.Join(h.cfg.AuthDir, targetFile)
-			if raw, errRead := os.ReadFile(fullPath); errRead == nil && len(raw) > 0 {
+			if raw, errRead := envelope.ReadFile(fullPath); errRead == nil && len(raw) > 0 {
 				_ = json.Unmarshal(raw, &existingMap)
 			}
 		}
@@ -0,0 +1,161 @@
+package management
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+)
+
+// maxAuthFileCheckTargets bounds one on-demand check request.
+const maxAuthFileCheckTargets = 100
+
+// CheckAuthFiles probes the selected credentials through their executors and
+// returns the results. Failures update credential status and cooldowns the same
+// way a failed user request would.
+//
+// Body: {"names": [...], "auth_indexes": [...], "models": [...]}. models is
+// optional and overrides the configured health-check models.
+func (h *Handler) CheckAuthFiles(c *gin.Context) {
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	var req struct {
+		Names       []string `json:"names"`
+		AuthIndexes []string `json:"auth_indexes"`
+		Models      []string `json:"models"`
+	}
+	if errBind := c.ShouldBindJSON(&req); errBind != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if len(req.Names)+len(req.AuthIndexes) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "names or auth_indexes is required"})
+		return
+	}
+	if len(req.Names)+len(req.AuthIndexes) > maxAuthFileCheckTargets {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "too many credentials in one check"})
+		return
+	}
+
+	targets := make(map[string]*coreauth.Auth)
+	notFound := make([]string, 0)
+	for _, name := range req.Names {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if auth, ok := h.lookupAuthFile(name, ""); ok && auth != nil {
+			targets[auth.ID] = auth
+		} else {
+			notFound = append(notFound, name)
+		}
+	}
+	for _, authIndex := range req.AuthIndexes {
+		if authIndex = strings.TrimSpace(authIndex); authIndex == "" {
+			continue
+		}
+		if auth := h.authByIndex(authIndex); auth != nil {
+			targets[auth.ID] = auth
+		} else {
+			notFound = append(notFound, authIndex)
+		}
+	}
+	if len(targets) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "auth file not found", "not_found": notFound})
+		return
+	}
+
+	ids := make([]string, 0, len(targets))
+	for id := range targets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	models := make([]string, 0, len(req.Models))
+	for _, model := range req.Models {
+		if model = strings.TrimSpace(model); model != "" {
+			models = append(models, model)
+		}
+	}
+
+	checked := h.authManager.CheckAuthsHealth(c.Request.Context(), ids, models)
+	results := make([]gin.H, 0, len(ids))
+	for _, id := range ids {
+		auth := targets[id]
+		name := strings.TrimSpace(auth.FileName)
+		if name == "" {
+			name = auth.ID
+		}
+		entry := gin.H{
+			"id":         auth.ID,
+			"name":       name,
+			"auth_index": lockedAuthIndex(auth),
+			"provider":   strings.TrimSpace(auth.Provider),
+		}
+		for key, value := range authHealthSummary(checked[id]) {
+			entry[key] = value
+		}
+		results = append(results, entry)
+	}
+	c.JSON(http.StatusOK, gin.H{"results": results, "not_found": notFound})
+}
+
+// GetAuthFilesHealth returns prober counters and how many credentials are
+// currently healthy, unhealthy, or not yet checked.
+func (h *Handler) GetAuthFilesHealth(c *gin.Context) {
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	healthy, unhealthy, unchecked := 0, 0, 0
+	for _, auth := range h.authManager.List() {
+		if auth == nil {
+			continue
+		}
+		summary := authHealthSummary(h.authManager.HealthCheckResults(auth.ID))
+		switch ok, checked := summary["healthy"].(bool); {
+		case !checked:
+			unchecked++
+		case ok:
+			healthy++
+		default:
+			unhealthy++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"stats": h.authManager.HealthCheckStats(),
+		"credentials": gin.H{
+			"healthy":   healthy,
+			"unhealthy": unhealthy,
+			"unchecked": unchecked,
+		},
+	})
+}
+
+// authHealthSummary folds the latest probe results of one credential. healthy
+// is omitted when every probe was skipped.
+func authHealthSummary(results []coreauth.HealthCheckResult) gin.H {
+	if len(results) == 0 {
+		return nil
+	}
+	var checkedAt time.Time
+	probed, healthy := false, true
+	for _, result := range results {
+		if result.CheckedAt.After(checkedAt) {
+			checkedAt = result.CheckedAt
+		}
+		if result.Skipped {
+			continue
+		}
+		probed = true
+		healthy = healthy && result.Healthy
+	}
+	summary := gin.H{"checked_at": checkedAt, "checks": results}
+	if probed {
+		summary["healthy"] = healthy
+	}
+	return summary
+}
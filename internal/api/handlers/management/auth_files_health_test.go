@@ -0,0 +1,81 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+)
+
+func TestCheckAuthFilesReportsResultsAndUnknownTargets(t *testing.T) {
+	manager := coreauth.NewManager(nil, nil, nil)
+	auth := &coreauth.Auth{ID: "check-auth-id", FileName: "check-auth.json", Provider: "claude", Disabled: true, Status: coreauth.StatusDisabled}
+	if _, errRegister := manager.Register(context.Background(), auth); errRegister != nil {
+		t.Fatalf("Register() error = %v", errRegister)
+	}
+	h := NewHandlerWithoutConfigFilePath(&config.Config{AuthDir: t.TempDir()}, manager)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v0/management/auth-files/check", strings.NewReader(`{"names":["check-auth.json","missing.json"]}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	h.CheckAuthFiles(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s, want 200", rec.Code, rec.Body.String())
+	}
+
+	var payload struct {
+		Results []struct {
+			ID      string                       `json:"id"`
+			Name    string                       `json:"name"`
+			Healthy *bool                        `json:"healthy"`
+			Checks  []coreauth.HealthCheckResult `json:"checks"`
+		} `json:"results"`
+		NotFound []string `json:"not_found"`
+	}
+	if errUnmarshal := json.Unmarshal(rec.Body.Bytes(), &payload); errUnmarshal != nil {
+		t.Fatalf("decode response: %v", errUnmarshal)
+	}
+	if len(payload.Results) != 1 || payload.Results[0].ID != auth.ID || payload.Results[0].Name != "check-auth.json" {
+		t.Fatalf("results = %+v, want the registered credential", payload.Results)
+	}
+	if result := payload.Results[0]; result.Healthy != nil || len(result.Checks) != 1 || !result.Checks[0].Skipped {
+		t.Fatalf("result = %+v, want one skipped check without a verdict", result)
+	}
+	if len(payload.NotFound) != 1 || payload.NotFound[0] != "missing.json" {
+		t.Fatalf("not_found = %v, want [missing.json]", payload.NotFound)
+	}
+
+	rec = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v0/management/auth-files/health", nil)
+	h.GetAuthFilesHealth(c)
+	var health struct {
+		Stats       coreauth.HealthCheckStats `json:"stats"`
+		Credentials map[string]int            `json:"credentials"`
+	}
+	if errUnmarshal := json.Unmarshal(rec.Body.Bytes(), &health); errUnmarshal != nil {
+		t.Fatalf("decode health: %v", errUnmarshal)
+	}
+	if health.Stats.Probes != 1 || health.Stats.Skipped != 1 || health.Credentials["unchecked"] != 1 {
+		t.Fatalf("health = %+v, want one skipped probe and one unchecked credential", health)
+	}
+}
+
+func TestCheckAuthFilesRequiresTargets(t *testing.T) {
+	h := NewHandlerWithoutConfigFilePath(&config.Config{AuthDir: t.TempDir()}, coreauth.NewManager(nil, nil, nil))
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v0/management/auth-files/check", strings.NewReader(`{}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	h.CheckAuthFiles(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+}
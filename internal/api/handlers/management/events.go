@@ -0,0 +1,211 @@
+package management
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/events"
+)
+
+const (
+	eventsHeartbeatInterval = 15 * time.Second
+	eventsWriteTimeout      = 10 * time.Second
+
+	// Stream control messages are sent alongside regular events.
+	eventsTypeGap    = "stream.gap"
+	eventsTypeLagged = "stream.lagged"
+)
+
+var eventsWebsocketUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// eventsStreamNotice is the payload of stream control messages.
+type eventsStreamNotice struct {
+	LastEventID uint64 `json:"last_event_id"`
+	Message     string `json:"message"`
+}
+
+// GetEvents streams management events as Server-Sent Events, or over a
+// WebSocket when the request asks for an upgrade.
+//
+// The types query parameter takes a comma-separated list of event types or
+// type prefixes ("auth" matches every auth.* event). Clients resume with the
+// Last-Event-ID header or the last_event_id query parameter; a stream.gap
+// message is sent first when some events after that ID were already dropped.
+func (h *Handler) GetEvents(c *gin.Context) {
+	filter := events.ParseFilter(c.Query("types"))
+	afterID, resume, errID := parseLastEventID(c)
+	if errID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errID.Error()})
+		return
+	}
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		streamEventsWebsocket(c, filter, afterID, resume)
+		return
+	}
+	streamEventsSSE(c, filter, afterID, resume)
+}
+
+// parseLastEventID reports the event ID to resume after. Without one the
+// stream starts with live events; "0" replays everything still retained.
+func parseLastEventID(c *gin.Context) (uint64, bool, error) {
+	raw := strings.TrimSpace(c.GetHeader("Last-Event-ID"))
+	if raw == "" {
+		raw = strings.TrimSpace(c.Query("last_event_id"))
+	}
+	if raw == "" {
+		return 0, false, nil
+	}
+	id, errParse := strconv.ParseUint(raw, 10, 64)
+	if errParse != nil {
+		return 0, false, fmt.Errorf("invalid last event id %q", raw)
+	}
+	return id, true, nil
+}
+
+func streamEventsSSE(c *gin.Context, filter events.Filter, afterID uint64, resume bool) {
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "streaming unsupported"})
+		return
+	}
+	sub, backlog, gap := events.Default().Subscribe(filter, afterID, resume)
+	defer sub.Close()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	lastID := afterID
+	if gap {
+		writeSSENotice(c, eventsTypeGap, lastID, "some events after the last event id are no longer retained")
+	}
+	for _, event := range backlog {
+		writeSSEEvent(c, event)
+		lastID = event.ID
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+	ctx := c.Request.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, open := <-sub.C:
+			if !open {
+				if sub.Lagged() {
+					writeSSENotice(c, eventsTypeLagged, lastID, "subscriber fell behind; reconnect with the last event id")
+					flusher.Flush()
+				}
+				return
+			}
+			writeSSEEvent(c, event)
+			lastID = event.ID
+			flusher.Flush()
+		case <-heartbeat.C:
+			_, _ = c.Writer.WriteString(": ping\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func writeSSEEvent(c *gin.Context, event events.Event) {
+	payload, errMarshal := json.Marshal(event)
+	if errMarshal != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, payload)
+}
+
+func writeSSENotice(c *gin.Context, noticeType string, lastID uint64, message string) {
+	payload, errMarshal := json.Marshal(eventsStreamNotice{LastEventID: lastID, Message: message})
+	if errMarshal != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", noticeType, payload)
+}
+
+func streamEventsWebsocket(c *gin.Context, filter events.Filter, afterID uint64, resume bool) {
+	conn, errUpgrade := eventsWebsocketUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if errUpgrade != nil {
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	sub, backlog, gap := events.Default().Subscribe(filter, afterID, resume)
+	defer sub.Close()
+
+	// The feed is one-way; reading only detects the client going away.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, errRead := conn.ReadMessage(); errRead != nil {
+				return
+			}
+		}
+	}()
+
+	write := func(value any) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(eventsWriteTimeout))
+		return conn.WriteJSON(value) == nil
+	}
+	notice := func(noticeType string, lastID uint64, message string) bool {
+		data, _ := json.Marshal(eventsStreamNotice{LastEventID: lastID, Message: message})
+		return write(events.Event{Type: noticeType, Time: time.Now().UTC(), Data: data})
+	}
+
+	lastID := afterID
+	if gap && !notice(eventsTypeGap, lastID, "some events after the last event id are no longer retained") {
+		return
+	}
+	for _, event := range backlog {
+		if !write(event) {
+			return
+		}
+		lastID = event.ID
+	}
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+	ctx := c.Request.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-closed:
+			return
+		case event, open := <-sub.C:
+			if !open {
+				if sub.Lagged() {
+					_ = notice(eventsTypeLagged, lastID, "subscriber fell behind; reconnect with the last event id")
+				}
+				return
+			}
+			if !write(event) {
+				return
+			}
+			lastID = event.ID
+		case <-heartbeat.C:
+			if conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(eventsWriteTimeout)) != nil {
+				return
+			}
+		}
+	}
+}
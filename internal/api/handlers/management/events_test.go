@@ -0,0 +1,94 @@
+package management
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/events"
+)
+
+func TestGetEventsSSEResumesFromLastEventID(t *testing.T) {
+	h := &Handler{}
+	start := events.Default().LastID()
+	events.Publish(events.TypeAuthStatus, map[string]string{"auth_id": "a"})
+	events.Publish(events.TypeConfigReloaded, map[string]any{"changes": []string{"debug: false -> true"}})
+	events.Publish(events.TypeAuthStatus, map[string]string{"auth_id": "b"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v0/management/events?types=auth", nil).WithContext(ctx)
+	c.Request.Header.Set("Last-Event-ID", strconv.FormatUint(start, 10))
+	h.GetEvents(c)
+
+	if got := rec.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", got)
+	}
+	body := rec.Body.String()
+	if strings.Count(body, "event: auth.status\n") != 2 {
+		t.Fatalf("body = %q, want two auth.status events", body)
+	}
+	if strings.Contains(body, events.TypeConfigReloaded) {
+		t.Fatalf("body = %q, want config events filtered out", body)
+	}
+	if !strings.Contains(body, "id: "+strconv.FormatUint(start+1, 10)+"\n") {
+		t.Fatalf("body = %q, want event ids", body)
+	}
+}
+
+func TestGetEventsRejectsInvalidLastEventID(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v0/management/events?last_event_id=abc", nil)
+	(&Handler{}).GetEvents(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestGetEventsWebsocketStreamsLiveEvents(t *testing.T) {
+	engine := gin.New()
+	engine.GET("/v0/management/events", (&Handler{}).GetEvents)
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/v0/management/events?types=plugin.loaded"
+	conn, _, errDial := websocket.DefaultDialer.Dial(url, nil)
+	if errDial != nil {
+		t.Fatalf("Dial() error = %v", errDial)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// The subscription is registered after the upgrade; publish until it lands.
+	deadline := time.Now().Add(2 * time.Second)
+	received := make(chan events.Event, 1)
+	go func() {
+		var event events.Event
+		if conn.ReadJSON(&event) == nil {
+			received <- event
+		}
+	}()
+	for {
+		events.Publish(events.TypeAuthStatus, map[string]string{"auth_id": "ignored"})
+		events.Publish(events.TypePluginLoaded, map[string]string{"id": "demo"})
+		select {
+		case event := <-received:
+			if event.Type != events.TypePluginLoaded || event.ID == 0 {
+				t.Fatalf("event = %+v, want plugin.loaded", event)
+			}
+			return
+		case <-time.After(20 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for websocket event")
+		}
+	}
+}
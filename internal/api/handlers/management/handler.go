@@ -4,7 +4,9 @@ package management
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/subtle"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -14,6 +16,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/audit"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/buildinfo"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/pluginhost"
@@ -21,7 +24,6 @@ import (
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v7/sdk/auth"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
 	log "github.com/sirupsen/logrus"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type attemptInfo struct {
@@ -46,6 +48,11 @@ type Handler struct {
 	appliedReloadGeneration uint64
 	attemptsMu              sync.Mutex
 	failedAttempts          map[string]*attemptInfo // keyed by client IP
+	verifiedKeys            map[[sha256.Size]byte]string
+	rejectedKeys            map[[sha256.Size]byte][sha256.Size]byte
+	auditMu                 sync.Mutex
+	auditFile               *audit.FileStore
+	auditFallbackOnce       sync.Once
 	authManager             *coreauth.Manager
 	tokenStore              coreauth.Store
 	localPassword           string
@@ -176,8 +183,8 @@ func (h *Handler) reloadSnapshotConfigLocked() configReloadSnapshot {
 // saveConfigAndSnapshotLocked saves h.cfg and returns a full runtime config snapshot.
 // Callers must hold h.mu.
 func (h *Handler) saveConfigAndSnapshotLocked(c *gin.Context) (configReloadSnapshot, bool) {
-	if errSave := config.SaveConfigPreserveComments(h.configFilePath, h.cfg); errSave != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to save config: %v", errSave)})
+	if errSave := h.saveConfigLocked(c); errSave != nil {
+		writeSaveConfigError(c, errSave)
 		return configReloadSnapshot{}, false
 	}
 	return h.reloadSnapshotConfigLocked(), true
@@ -286,33 +293,55 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 			provided = c.GetHeader("X-Management-Key")
 		}
 
-		allowed, statusCode, errMsg := h.AuthenticateManagementKey(clientIP, localClient, provided)
+		identity, allowed, statusCode, errMsg := h.AuthenticateManagementIdentity(clientIP, localClient, provided)
 		if !allowed {
 			c.AbortWithStatusJSON(statusCode, gin.H{"error": errMsg})
 			return
 		}
+		summary := h.summarizeAuditRequest(c)
+		if !identity.Permits(c.Request.Method, c.Request.URL.Path) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("management key %q (role %s) is not allowed to call this endpoint", identity.Name, identity.Role)})
+			recordManagementCall(c, identity)
+			h.auditCall(c, identity, summary)
+			return
+		}
+		c.Set(ManagementKeyNameContextKey, identity.Name)
+		c.Set(ManagementRoleContextKey, identity.Role)
 		c.Next()
+		recordManagementCall(c, identity)
+		h.auditCall(c, identity, summary)
 	}
 }
 
 // AuthenticateManagementKey verifies the provided management key for the given client.
 // It mirrors the behaviour of Middleware() so non-HTTP callers can reuse the same logic.
+// Any valid key is accepted regardless of its role.
 func (h *Handler) AuthenticateManagementKey(clientIP string, localClient bool, provided string) (bool, int, string) {
+	_, allowed, statusCode, errMsg := h.AuthenticateManagementIdentity(clientIP, localClient, provided)
+	return allowed, statusCode, errMsg
+}
+
+// AuthenticateManagementIdentity verifies the provided management key and returns the
+// identity it resolves to. The legacy secret-key, MANAGEMENT_PASSWORD and local
+// password resolve to the admin role.
+func (h *Handler) AuthenticateManagementIdentity(clientIP string, localClient bool, provided string) (ManagementIdentity, bool, int, string) {
 	const maxFailures = 5
 	const banDuration = 30 * time.Minute
 
 	if h == nil {
-		return false, http.StatusForbidden, "remote management disabled"
+		return ManagementIdentity{}, false, http.StatusForbidden, "remote management disabled"
 	}
 
 	cfg := h.cfg
 	var (
 		allowRemote bool
 		secretHash  string
+		namedKeys   []config.ManagementKey
 	)
 	if cfg != nil {
 		allowRemote = cfg.RemoteManagement.AllowRemote
 		secretHash = cfg.RemoteManagement.SecretKey
+		namedKeys = cfg.RemoteManagement.Keys
 	}
 	if h.allowRemoteOverride {
 		allowRemote = true
@@ -326,7 +355,7 @@ func (h *Handler) AuthenticateManagementKey(clientIP string, localClient bool, p
 		if now.Before(ai.blockedUntil) {
 			remaining := ai.blockedUntil.Sub(now).Round(time.Second)
 			h.attemptsMu.Unlock()
-			return false, http.StatusForbidden, fmt.Sprintf("IP banned due to too many failed attempts. Try again in %s", remaining)
+			return ManagementIdentity{}, false, http.StatusForbidden, fmt.Sprintf("IP banned due to too many failed attempts. Try again in %s", remaining)
 		}
 		// Ban expired, reset state
 		ai.blockedUntil = time.Time{}
@@ -335,7 +364,7 @@ func (h *Handler) AuthenticateManagementKey(clientIP string, localClient bool, p
 	h.attemptsMu.Unlock()
 
 	if !localClient && !allowRemote {
-		return false, http.StatusForbidden, "remote management disabled"
+		return ManagementIdentity{}, false, http.StatusForbidden, "remote management disabled"
 	}
 
 	fail := func() {
@@ -363,37 +392,41 @@ func (h *Handler) AuthenticateManagementKey(clientIP string, localClient bool, p
 		h.attemptsMu.Unlock()
 	}
 
-	if secretHash == "" && envSecret == "" {
-		return false, http.StatusForbidden, "remote management key not set"
+	if secretHash == "" && envSecret == "" && len(namedKeys) == 0 {
+		return ManagementIdentity{}, false, http.StatusForbidden, "remote management key not set"
 	}
 
 	if provided == "" {
 		fail()
-		return false, http.StatusUnauthorized, "missing management key"
+		return ManagementIdentity{}, false, http.StatusUnauthorized, "missing management key"
 	}
 
 	if localClient {
 		if lp := h.localPassword; lp != "" {
 			if subtle.ConstantTimeCompare([]byte(provided), []byte(lp)) == 1 {
 				reset()
-				return true, 0, ""
+				return ManagementIdentity{Name: managementKeyNameLocal, Role: config.ManagementRoleAdmin}, true, 0, ""
 			}
 		}
 	}
 
 	if envSecret != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(envSecret)) == 1 {
 		reset()
-		return true, 0, ""
+		return ManagementIdentity{Name: managementKeyNameEnv, Role: config.ManagementRoleAdmin}, true, 0, ""
 	}
 
-	if secretHash == "" || bcrypt.CompareHashAndPassword([]byte(secretHash), []byte(provided)) != nil {
+	key, legacy, ok := h.matchManagementKey(secretHash, namedKeys, provided)
+	if !ok {
 		fail()
-		return false, http.StatusUnauthorized, "invalid management key"
+		return ManagementIdentity{}, false, http.StatusUnauthorized, "invalid management key"
 	}
 
 	reset()
 
-	return true, 0, ""
+	if legacy {
+		return ManagementIdentity{Name: managementKeyNameSecret, Role: config.ManagementRoleAdmin}, true, 0, ""
+	}
+	return ManagementIdentity{Name: key.Name, Role: key.Role, Routes: key.Routes}, true, 0, ""
 }
 
 // persist saves the current in-memory config to disk.
@@ -407,8 +440,8 @@ func (h *Handler) persist(c *gin.Context) bool {
 // It expects the caller to hold h.mu.
 func (h *Handler) persistLocked(c *gin.Context) bool {
 	// Preserve comments when writing
-	if err := config.SaveConfigPreserveComments(h.configFilePath, h.cfg); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to save config: %v", err)})
+	if err := h.saveConfigLocked(c); err != nil {
+		writeSaveConfigError(c, err)
 		return false
 	}
 	snapshot := h.reloadSnapshotConfigLocked()
@@ -421,6 +454,16 @@ func (h *Handler) persistLocked(c *gin.Context) bool {
 	return true
 }
 
+// writeSaveConfigError reports a failed config save. Unknown secret references
+// are client errors; everything else is a server error.
+func writeSaveConfigError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, config.ErrUnknownSecretRef) {
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, gin.H{"error": fmt.Sprintf("failed to save config: %v", err)})
+}
+
 // Helper methods for simple types
 func (h *Handler) updateBoolField(c *gin.Context, set func(bool)) {
 	var body struct {
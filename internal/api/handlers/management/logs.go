@@ -28,6 +28,8 @@ const (
 	logScannerMaxBuffer     = 8 * 1024 * 1024
 	logCursorVersion        = 1
 	logCursorFingerprintMax = 4 * 1024
+	defaultRequestLogsLimit = 100
+	maxRequestLogsLimit     = 1000
 )
 
 // GetLogs returns log lines with optional incremental loading.
@@ -256,6 +258,105 @@ func (h *Handler) GetRequestErrorLogs(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"files": files})
 }
 
+// GetRequestLogs lists request log files, newest first, with a summary parsed from
+// each file. Query parameters: after (modification time in unix milliseconds,
+// exclusive) and limit (default 100, max 1000).
+//
+// Without after the newest files are listed. With after the oldest files past
+// the cursor are listed instead and has_more reports whether more remain, so a
+// poller that advances after to latest pages forward without skipping files.
+func (h *Handler) GetRequestLogs(c *gin.Context) {
+	if h == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "handler unavailable"})
+		return
+	}
+	if h.cfg == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "configuration unavailable"})
+		return
+	}
+
+	dir := h.logDirectory()
+	if strings.TrimSpace(dir) == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "log directory not configured"})
+		return
+	}
+
+	limit := defaultRequestLogsLimit
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		parsed, errLimit := strconv.Atoi(raw)
+		if errLimit != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		limit = min(parsed, maxRequestLogsLimit)
+	}
+	after := parseCutoff(c.Query("after"))
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			c.JSON(http.StatusOK, gin.H{"logs": []any{}, "latest": after, "has_more": false})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to list request logs: %v", err)})
+		return
+	}
+
+	type candidate struct {
+		name     string
+		modified int64
+	}
+	candidates := make([]candidate, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		if _, ok := logging.ParseRequestLogFilename(name); !ok {
+			continue
+		}
+		info, errInfo := entry.Info()
+		if errInfo != nil {
+			continue
+		}
+		modified := info.ModTime().UnixMilli()
+		if modified <= after {
+			continue
+		}
+		candidates = append(candidates, candidate{name: name, modified: modified})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].modified > candidates[j].modified })
+	hasMore := false
+	if len(candidates) > limit {
+		if after > 0 {
+			// Keep the oldest files past the cursor. Files sharing the
+			// boundary timestamp stay together, since the cursor cannot
+			// split them.
+			cut := len(candidates) - limit
+			for cut > 0 && candidates[cut-1].modified == candidates[cut].modified {
+				cut--
+			}
+			hasMore = cut > 0
+			candidates = candidates[cut:]
+		} else {
+			candidates = candidates[:limit]
+		}
+	}
+
+	latest := after
+	logs := make([]logging.RequestLogSummary, 0, len(candidates))
+	for _, item := range candidates {
+		summary, errSummary := logging.SummarizeRequestLog(filepath.Join(dir, item.name))
+		if errSummary != nil {
+			continue
+		}
+		logs = append(logs, summary)
+		latest = max(latest, item.modified)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"logs": logs, "latest": latest, "has_more": hasMore})
+}
+
 // GetRequestLogByID finds and downloads a request log file by its request ID.
 // The ID is matched against the suffix of log file names (format: *-{requestID}.log).
 func (h *Handler) GetRequestLogByID(c *gin.Context) {
@@ -3,6 +3,7 @@ package management
 import (
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -734,3 +735,119 @@ func appendMainLog(t *testing.T, dir, content string) {
 		t.Fatalf("close main log: %v", errClose)
 	}
 }
+
+func TestGetRequestLogsListsNewestFirstAndHonorsAfter(t *testing.T) {
+	dir := t.TempDir()
+	writeMainLog(t, dir, "[2026-06-15 10:00:00] ignored\n")
+	older := filepath.Join(dir, "v1-chat-completions-2026-06-15T100000-old.log")
+	newer := filepath.Join(dir, "error-v1-responses-2026-06-15T100100-new.log")
+	writeRequestLogFile(t, older, "POST", "/v1/chat/completions", 200, time.Date(2026, 6, 15, 10, 0, 0, 0, time.UTC))
+	writeRequestLogFile(t, newer, "POST", "/v1/responses", 500, time.Date(2026, 6, 15, 10, 1, 0, 0, time.UTC))
+
+	h := newLogsTestHandler(dir, false)
+	status, body := performGetRequestLogs(t, h, "/v0/management/request-logs")
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", status, body)
+	}
+	var resp struct {
+		Logs []struct {
+			ID     string `json:"id"`
+			Status int    `json:"status"`
+			Error  bool   `json:"error"`
+		} `json:"logs"`
+		Latest int64 `json:"latest"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Logs) != 2 || resp.Logs[0].ID != "new" || resp.Logs[1].ID != "old" {
+		t.Fatalf("logs = %+v", resp.Logs)
+	}
+	if resp.Logs[0].Status != 500 || !resp.Logs[0].Error {
+		t.Fatalf("newest log = %+v", resp.Logs[0])
+	}
+	wantLatest := time.Date(2026, 6, 15, 10, 1, 0, 0, time.UTC).UnixMilli()
+	if resp.Latest != wantLatest {
+		t.Fatalf("latest = %d, want %d", resp.Latest, wantLatest)
+	}
+
+	status, body = performGetRequestLogs(t, h, "/v0/management/request-logs?after="+strconv.FormatInt(wantLatest, 10))
+	if status != http.StatusOK || !strings.Contains(body, `"logs":[]`) {
+		t.Fatalf("after latest: status = %d, body = %s", status, body)
+	}
+
+	status, _ = performGetRequestLogs(t, h, "/v0/management/request-logs?limit=0")
+	if status != http.StatusBadRequest {
+		t.Fatalf("limit=0 status = %d, want %d", status, http.StatusBadRequest)
+	}
+}
+
+func TestGetRequestLogsPagesForwardPastTheCursor(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 6, 15, 10, 0, 0, 0, time.UTC)
+	writeRequestLogFile(t, filepath.Join(dir, "v1-chat-completions-2026-06-15T100000-seed.log"), "POST", "/v1/chat/completions", 200, base)
+	for i := 1; i <= 7; i++ {
+		name := fmt.Sprintf("v1-chat-completions-2026-06-15T1000%02d-req%d.log", i, i)
+		writeRequestLogFile(t, filepath.Join(dir, name), "POST", "/v1/chat/completions", 200, base.Add(time.Duration(i)*time.Second))
+	}
+
+	h := newLogsTestHandler(dir, false)
+	type page struct {
+		Logs []struct {
+			ID string `json:"id"`
+		} `json:"logs"`
+		Latest  int64 `json:"latest"`
+		HasMore bool  `json:"has_more"`
+	}
+	after := base.UnixMilli()
+	seen := make(map[string]int)
+	for round := 0; ; round++ {
+		if round > 5 {
+			t.Fatal("paging did not drain")
+		}
+		status, body := performGetRequestLogs(t, h, "/v0/management/request-logs?limit=3&after="+strconv.FormatInt(after, 10))
+		if status != http.StatusOK {
+			t.Fatalf("status = %d, body = %s", status, body)
+		}
+		var resp page
+		if err := json.Unmarshal([]byte(body), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		for _, entry := range resp.Logs {
+			seen[entry.ID]++
+		}
+		after = resp.Latest
+		if !resp.HasMore {
+			break
+		}
+	}
+	if len(seen) != 7 {
+		t.Fatalf("seen = %v, want all 7 requests past the cursor", seen)
+	}
+	for id, count := range seen {
+		if count != 1 || id == "seed" {
+			t.Fatalf("seen = %v, want each request past the cursor exactly once", seen)
+		}
+	}
+}
+
+func performGetRequestLogs(t *testing.T, h *Handler, target string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	h.GetRequestLogs(c)
+	return rec.Code, rec.Body.String()
+}
+
+func writeRequestLogFile(t *testing.T, path, method, requestURL string, status int, modified time.Time) {
+	t.Helper()
+	content := "=== REQUEST INFO ===\nURL: " + requestURL + "\nMethod: " + method + "\nTimestamp: " +
+		modified.Add(-time.Second).Format(time.RFC3339Nano) + "\n\n=== RESPONSE ===\nStatus: " + strconv.Itoa(status) + "\n\n{}\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write request log: %v", err)
+	}
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatalf("chtimes request log: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/notify"
+)
+
+// GetNotifications reports the webhook targets, their delivery counters and
+// the number of queued deliveries.
+func (h *Handler) GetNotifications(c *gin.Context) {
+	c.JSON(http.StatusOK, notify.Default().Status())
+}
+
+// TestNotifications queues a test notification.
+//
+// Body: {"target": "..."}. An empty body or target sends to every target.
+func (h *Handler) TestNotifications(c *gin.Context) {
+	var req struct {
+		Target string `json:"target"`
+	}
+	if c.Request.ContentLength != 0 {
+		if errBind := c.ShouldBindJSON(&req); errBind != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+	}
+	targets, errSend := notify.Default().SendTest(strings.TrimSpace(req.Target))
+	if errSend != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": errSend.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"status": "queued", "targets": targets})
+}
@@ -46,9 +46,21 @@ type pluginStoreListResponse struct {
 }
 
 type pluginStoreSource struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
-	URL  string `json:"url"`
+	ID    string                  `json:"id"`
+	Name  string                  `json:"name"`
+	URL   string                  `json:"url"`
+	Trust *pluginStoreSourceTrust `json:"trust,omitempty"`
+}
+
+type pluginStoreSourceTrust struct {
+	Publishers     []pluginStorePublisher `json:"publishers"`
+	AllowUnsigned  bool                   `json:"allow_unsigned"`
+	AllowUntrusted bool                   `json:"allow_untrusted"`
+}
+
+type pluginStorePublisher struct {
+	Name  string `json:"name"`
+	KeyID string `json:"key_id"`
 }
 
 type pluginStoreSourceErr struct {
@@ -81,6 +93,9 @@ type pluginStoreListEntry struct {
 	InstalledVersion    string                `json:"installed_version"`
 	InstalledSourceID   string                `json:"installed_source_id,omitempty"`
 	InstallSourceStatus string                `json:"install_source_status,omitempty"`
+	InstalledSignature  string                `json:"installed_signature,omitempty"`
+	InstalledSigner     string                `json:"installed_signer,omitempty"`
+	InstalledSignerKey  string                `json:"installed_signer_key_id,omitempty"`
 	Path                string                `json:"path"`
 	Configured          bool                  `json:"configured"`
 	Registered          bool                  `json:"registered"`
@@ -95,14 +110,20 @@ type pluginStorePlatform struct {
 }
 
 type pluginInstallResponse struct {
-	Status          string `json:"status"`
-	SourceID        string `json:"source_id"`
-	SourceName      string `json:"source_name"`
-	SourceURL       string `json:"source_url"`
-	ID              string `json:"id"`
-	Version         string `json:"version"`
-	InstallType     string `json:"install_type"`
-	Path            string `json:"path"`
+	Status      string `json:"status"`
+	SourceID    string `json:"source_id"`
+	SourceName  string `json:"source_name"`
+	SourceURL   string `json:"source_url"`
+	ID          string `json:"id"`
+	Version     string `json:"version"`
+	InstallType string `json:"install_type"`
+	Path        string `json:"path"`
+	Signature   string `json:"signature"`
+	Signer      string `json:"signer,omitempty"`
+	SignerKeyID string `json:"signer_key_id,omitempty"`
+	// PreviousVersion is the running version the install upgrades. It keeps
+	// serving until the new version loads, and stays active if that fails.
+	PreviousVersion string `json:"previous_version,omitempty"`
 	PluginsEnabled  bool   `json:"plugins_enabled"`
 	RestartRequired bool   `json:"restart_required"`
 }
@@ -117,6 +138,9 @@ type pluginLocalStatus struct {
 	StoreManaged       bool
 	InstalledSourceID  string
 	InstalledSourceURL string
+	Signature          string
+	Signer             string
+	SignerKeyID        string
 	Path               string
 	Configured         bool
 	Registered         bool
@@ -130,7 +154,7 @@ type sourcedPlugin struct {
 }
 
 func (h *Handler) ListPluginStore(c *gin.Context) {
-	pluginsEnabled, pluginsDir, proxyURL, sourceConfigs, storeAuth, configs, host := h.pluginStoreSnapshot()
+	pluginsEnabled, pluginsDir, proxyURL, sourceConfigs, storeAuth, storeTrust, configs, host := h.pluginStoreSnapshot()
 	resolvedPluginsDir, errResolvePluginsDir := config.ResolvePluginsDir(pluginsDir)
 	if errResolvePluginsDir != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "plugin_directory_invalid", "message": errResolvePluginsDir.Error()})
@@ -203,6 +227,9 @@ func (h *Handler) ListPluginStore(c *gin.Context) {
 			InstalledVersion:    htmlsanitize.String(installedVersion),
 			InstalledSourceID:   htmlsanitize.String(installedSourceID),
 			InstallSourceStatus: htmlsanitize.String(installSourceStatus),
+			InstalledSignature:  htmlsanitize.String(status.Signature),
+			InstalledSigner:     htmlsanitize.String(status.Signer),
+			InstalledSignerKey:  htmlsanitize.String(status.SignerKeyID),
 			Path:                htmlsanitize.String(status.Path),
 			Configured:          status.Configured,
 			Registered:          status.Registered,
@@ -215,7 +242,7 @@ func (h *Handler) ListPluginStore(c *gin.Context) {
 	c.JSON(http.StatusOK, pluginStoreListResponse{
 		PluginsEnabled: pluginsEnabled,
 		PluginsDir:     htmlsanitize.String(pluginsDir),
-		Sources:        sanitizePluginStoreSources(sources),
+		Sources:        sanitizePluginStoreSourcesWithTrust(sources, storeTrust),
 		SourceErrors:   sanitizePluginStoreSourceErrors(sourceErrors),
 		Plugins:        entries,
 	})
@@ -236,7 +263,7 @@ func (h *Handler) installPluginFromStore(c *gin.Context, goos, goarch string) {
 		return
 	}
 	installCtx := c.Request.Context()
-	pluginsEnabled, pluginsDir, proxyURL, sourceConfigs, storeAuth, configs, host := h.pluginStoreSnapshot()
+	pluginsEnabled, pluginsDir, proxyURL, sourceConfigs, storeAuth, storeTrust, configs, host := h.pluginStoreSnapshot()
 	resolvedPluginsDir, errResolvePluginsDir := config.ResolvePluginsDir(pluginsDir)
 	if errResolvePluginsDir != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "plugin_directory_invalid", "message": errResolvePluginsDir.Error()})
@@ -255,6 +282,8 @@ func (h *Handler) installPluginFromStore(c *gin.Context, goos, goarch string) {
 	if !validatePluginStoreInstallSource(c, configs, sources, id, source.ID) {
 		return
 	}
+	trust := pluginstore.TrustPolicyForSource(storeTrust, source)
+	client.Trust = &trust
 	pluginIsBusy := func() bool { return pluginBusy(host, id) }
 	installOptions := pluginstore.InstallOptions{
 		PluginsDir:   pluginsDir,
@@ -304,7 +333,16 @@ func (h *Handler) installPluginFromStore(c *gin.Context, goos, goarch string) {
 			return
 		}
 	}
+	manifest.Signature = result.Signature
+	manifest.Signer = result.Signer
+	manifest.SignerKeyID = result.SignerKeyID
 	restartRequired := false
+	previousVersion := ""
+	if host != nil {
+		if activeVersion, okActive := host.PluginRegisteredVersion(id); okActive && activeVersion != result.Version {
+			previousVersion = activeVersion
+		}
+	}
 
 	h.mu.Lock()
 	if h.cfg == nil {
@@ -325,7 +363,7 @@ func (h *Handler) installPluginFromStore(c *gin.Context, goos, goarch string) {
 		})
 		return
 	}
-	if errSave := config.SaveConfigPreserveComments(h.configFilePath, h.cfg); errSave != nil {
+	if errSave := h.saveConfigLocked(c); errSave != nil {
 		h.mu.Unlock()
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "config_save_failed",
@@ -346,6 +384,9 @@ func (h *Handler) installPluginFromStore(c *gin.Context, goos, goarch string) {
 		"install_type": result.InstallType,
 		"path":         result.Path,
 		"overwritten":  result.Overwritten,
+		"signature":    result.Signature,
+		"signer":       result.Signer,
+		"previous":     previousVersion,
 	}).Info("pluginstore: plugin installed")
 
 	c.JSON(http.StatusOK, pluginInstallResponse{
@@ -357,6 +398,10 @@ func (h *Handler) installPluginFromStore(c *gin.Context, goos, goarch string) {
 		Version:         htmlsanitize.String(result.Version),
 		InstallType:     htmlsanitize.String(result.InstallType),
 		Path:            htmlsanitize.String(result.Path),
+		Signature:       htmlsanitize.String(result.Signature),
+		Signer:          htmlsanitize.String(result.Signer),
+		SignerKeyID:     htmlsanitize.String(result.SignerKeyID),
+		PreviousVersion: htmlsanitize.String(previousVersion),
 		PluginsEnabled:  pluginsEnabled,
 		RestartRequired: restartRequired,
 	})
@@ -495,25 +540,26 @@ func pluginStoreManifestYAMLNode(manifest pluginstore.Manifest) (*yaml.Node, err
 	return &node, nil
 }
 
-func (h *Handler) pluginStoreSnapshot() (bool, string, string, []string, []pluginstore.AuthConfig, map[string]config.PluginInstanceConfig, *pluginhost.Host) {
+func (h *Handler) pluginStoreSnapshot() (bool, string, string, []string, []pluginstore.AuthConfig, []pluginstore.TrustConfig, map[string]config.PluginInstanceConfig, *pluginhost.Host) {
 	if h == nil {
-		return false, "plugins", "", nil, nil, map[string]config.PluginInstanceConfig{}, nil
+		return false, "plugins", "", nil, nil, nil, map[string]config.PluginInstanceConfig{}, nil
 	}
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	if h.cfg == nil {
-		return false, "plugins", "", nil, nil, map[string]config.PluginInstanceConfig{}, nil
+		return false, "plugins", "", nil, nil, nil, map[string]config.PluginInstanceConfig{}, nil
 	}
 	pluginsEnabled := h.cfg.Plugins.Enabled
 	pluginsDir := normalizedPluginsDir(h.cfg.Plugins.Dir)
 	proxyURL := strings.TrimSpace(h.cfg.ProxyURL)
 	sourceConfigs := append([]string(nil), h.cfg.Plugins.StoreSources...)
 	storeAuth := append([]pluginstore.AuthConfig(nil), h.cfg.Plugins.StoreAuth...)
+	storeTrust := append([]pluginstore.TrustConfig(nil), h.cfg.Plugins.StoreTrust...)
 	configs := make(map[string]config.PluginInstanceConfig, len(h.cfg.Plugins.Configs))
 	for id, item := range h.cfg.Plugins.Configs {
 		configs[id] = item
 	}
-	return pluginsEnabled, pluginsDir, proxyURL, sourceConfigs, storeAuth, configs, h.pluginHost
+	return pluginsEnabled, pluginsDir, proxyURL, sourceConfigs, storeAuth, storeTrust, configs, h.pluginHost
 }
 
 func (h *Handler) pluginStoreSources(sourceConfigs []string) ([]pluginstore.Source, error) {
@@ -637,6 +683,32 @@ func sanitizePluginStoreSources(sources []pluginstore.Source) []pluginStoreSourc
 	return out
 }
 
+// sanitizePluginStoreSourcesWithTrust adds the publisher trust policy that
+// store installs from each source are checked against.
+func sanitizePluginStoreSourcesWithTrust(sources []pluginstore.Source, trust []pluginstore.TrustConfig) []pluginStoreSource {
+	out := sanitizePluginStoreSources(sources)
+	for index, source := range sources {
+		policy := pluginstore.TrustPolicyForSource(trust, source)
+		publishers := make([]pluginStorePublisher, 0, len(policy.Publishers))
+		for _, publisher := range policy.Publishers {
+			keyID := ""
+			if key, errKey := pluginstore.ParseMinisignPublicKey(publisher.Key); errKey == nil {
+				keyID = pluginstore.MinisignKeyIDString(key.KeyID)
+			}
+			publishers = append(publishers, pluginStorePublisher{
+				Name:  htmlsanitize.String(publisher.Name),
+				KeyID: keyID,
+			})
+		}
+		out[index].Trust = &pluginStoreSourceTrust{
+			Publishers:     publishers,
+			AllowUnsigned:  policy.AllowUnsigned,
+			AllowUntrusted: policy.AllowUntrusted,
+		}
+	}
+	return out
+}
+
 func sanitizePluginStoreSourceErrors(sourceErrors []pluginStoreSourceErr) []pluginStoreSourceErr {
 	if len(sourceErrors) == 0 {
 		return nil
@@ -749,7 +821,13 @@ func pluginLocalStatuses(pluginsEnabled bool, pluginsDir string, configs map[str
 		status := statuses[id]
 		status.Configured = true
 		status.Enabled = pluginInstanceEnabled(item)
-		status.InstalledSourceID, status.InstalledSourceURL, status.StoreManaged = pluginStoreConfiguredSource(item)
+		manifest, managed := pluginStoreConfiguredManifest(item)
+		status.StoreManaged = managed
+		status.InstalledSourceID = strings.TrimSpace(manifest.SourceID)
+		status.InstalledSourceURL = strings.TrimSpace(manifest.SourceURL)
+		status.Signature = strings.TrimSpace(manifest.Signature)
+		status.Signer = strings.TrimSpace(manifest.Signer)
+		status.SignerKeyID = strings.TrimSpace(manifest.SignerKeyID)
 		statuses[id] = status
 	}
 	if host != nil {
@@ -772,15 +850,22 @@ func pluginLocalStatuses(pluginsEnabled bool, pluginsDir string, configs map[str
 }
 
 func pluginStoreConfiguredSource(item config.PluginInstanceConfig) (sourceID string, sourceURL string, managed bool) {
+	manifest, managed := pluginStoreConfiguredManifest(item)
+	return strings.TrimSpace(manifest.SourceID), strings.TrimSpace(manifest.SourceURL), managed
+}
+
+// pluginStoreConfiguredManifest decodes plugins.configs.<id>.store. A store
+// block that fails to decode still marks the plugin as store managed.
+func pluginStoreConfiguredManifest(item config.PluginInstanceConfig) (pluginstore.Manifest, bool) {
 	storeNode := pluginStoreConfigNode(item)
 	if storeNode == nil {
-		return "", "", false
+		return pluginstore.Manifest{}, false
 	}
 	var manifest pluginstore.Manifest
 	if errDecode := storeNode.Decode(&manifest); errDecode != nil {
-		return "", "", true
+		return pluginstore.Manifest{}, true
 	}
-	return strings.TrimSpace(manifest.SourceID), strings.TrimSpace(manifest.SourceURL), true
+	return manifest, true
 }
 
 func pluginStoreResolveInstalledSource(status pluginLocalStatus, sources []pluginstore.Source) (string, bool) {
@@ -3,7 +3,9 @@ package management
 import (
 	"archive/zip"
 	"bytes"
+	"crypto/ed25519"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"html"
@@ -771,6 +773,9 @@ func TestInstallPluginFromStoreWritesFileAndEnablesConfig(t *testing.T) {
 plugins:
   enabled: false
   dir: "~/.cli-proxy-api/plugins"
+  store-trust:
+    - source: "*"
+      allow-unsigned: true
 `))
 	if errParse != nil {
 		t.Fatalf("ParseConfigBytes() error = %v", errParse)
@@ -872,8 +877,9 @@ func TestInstallPluginFromStoreInstallsDirectArtifact(t *testing.T) {
 	h := &Handler{
 		cfg: &config.Config{
 			Plugins: config.PluginsConfig{
-				Enabled: false,
-				Dir:     pluginsDir,
+				Enabled:    false,
+				Dir:        pluginsDir,
+				StoreTrust: allowUnsignedPluginStoreTrust(),
 			},
 		},
 		configFilePath:         writeTestConfigFile(t),
@@ -924,6 +930,127 @@ func TestInstallPluginFromStoreInstallsDirectArtifact(t *testing.T) {
 	}
 }
 
+func TestInstallPluginFromStoreRecordsVerifiedSigner(t *testing.T) {
+	t.Parallel()
+
+	pluginsDir := t.TempDir()
+	archiveData := makeManagementPluginStoreZip(t, "sample-provider"+managementPluginExtension(runtime.GOOS), "signed-library-data")
+	checksum := sha256.Sum256(archiveData)
+	artifactURL := "https://downloads.example/sample-provider.zip"
+	signatureURL := artifactURL + pluginstore.SignatureExtension
+	publicKey, keyID, signature := signManagementPluginStoreArtifact(t, archiveData)
+	registry := strings.Replace(string(directRegistryJSON(artifactURL, hex.EncodeToString(checksum[:]))),
+		`"url": "`+artifactURL+`",`, `"url": "`+artifactURL+`", "signature_url": "`+signatureURL+`",`, 1)
+	h := &Handler{
+		cfg: &config.Config{
+			Plugins: config.PluginsConfig{
+				Dir: pluginsDir,
+				StoreTrust: []pluginstore.TrustConfig{{
+					Source:     pluginstore.DefaultSourceID,
+					Publishers: []pluginstore.PublisherKey{{Name: "Sample Publisher", Key: publicKey}},
+				}},
+			},
+		},
+		configFilePath:         writeTestConfigFile(t),
+		pluginStoreRegistryURL: "https://registry.example/registry.json",
+		pluginStoreHTTPClient: fakePluginStoreHTTPClient{
+			"https://registry.example/registry.json": []byte(registry),
+			artifactURL:                              archiveData,
+			signatureURL:                             signature,
+		},
+	}
+	reloads, reloadDone := captureConfigReload(h)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Params = gin.Params{{Key: "id", Value: "sample-provider"}}
+	c.Request = httptest.NewRequest(http.MethodPost, "/v0/management/plugin-store/sample-provider/install", nil)
+	h.InstallPluginFromStore(c)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	waitForAsyncReload(t, reloads)
+	waitForReloadDone(t, reloadDone)
+	var body pluginInstallResponse
+	if errDecode := json.Unmarshal(rec.Body.Bytes(), &body); errDecode != nil {
+		t.Fatalf("Unmarshal() error = %v; body=%s", errDecode, rec.Body.String())
+	}
+	if body.Signature != pluginstore.SignatureStatusVerified || body.Signer != "Sample Publisher" || body.SignerKeyID != keyID {
+		t.Fatalf("install response = %#v, want verified signer", body)
+	}
+	manifest := pluginStoreManifestFromConfig(t, h.cfg.Plugins.Configs["sample-provider"])
+	if manifest.Signature != pluginstore.SignatureStatusVerified || manifest.Signer != "Sample Publisher" || manifest.SignerKeyID != keyID {
+		t.Fatalf("store manifest = %#v, want the signer recorded", manifest)
+	}
+
+	listRec := httptest.NewRecorder()
+	listCtx, _ := gin.CreateTestContext(listRec)
+	listCtx.Request = httptest.NewRequest(http.MethodGet, "/v0/management/plugin-store", nil)
+	h.ListPluginStore(listCtx)
+	var list pluginStoreListResponse
+	if errDecode := json.Unmarshal(listRec.Body.Bytes(), &list); errDecode != nil {
+		t.Fatalf("Unmarshal() error = %v; body=%s", errDecode, listRec.Body.String())
+	}
+	if len(list.Plugins) != 1 || list.Plugins[0].InstalledSigner != "Sample Publisher" || list.Plugins[0].InstalledSignature != pluginstore.SignatureStatusVerified {
+		t.Fatalf("plugin store entries = %#v, want the installed signer", list.Plugins)
+	}
+	if len(list.Sources) != 1 || list.Sources[0].Trust == nil || len(list.Sources[0].Trust.Publishers) != 1 || list.Sources[0].Trust.Publishers[0].KeyID != keyID {
+		t.Fatalf("plugin store sources = %#v, want the trusted publisher key", list.Sources)
+	}
+
+	pluginsRec := httptest.NewRecorder()
+	pluginsCtx, _ := gin.CreateTestContext(pluginsRec)
+	pluginsCtx.Request = httptest.NewRequest(http.MethodGet, "/v0/management/plugins", nil)
+	h.ListPlugins(pluginsCtx)
+	var plugins pluginListResponse
+	if errDecode := json.Unmarshal(pluginsRec.Body.Bytes(), &plugins); errDecode != nil {
+		t.Fatalf("Unmarshal() error = %v; body=%s", errDecode, pluginsRec.Body.String())
+	}
+	if len(plugins.Plugins) != 1 || plugins.Plugins[0].Signer != "Sample Publisher" || plugins.Plugins[0].SignerKeyID != keyID {
+		t.Fatalf("plugins = %#v, want the signer in the plugin list", plugins.Plugins)
+	}
+}
+
+func TestInstallPluginFromStoreRefusesUnsignedArtifact(t *testing.T) {
+	t.Parallel()
+
+	pluginsDir := t.TempDir()
+	archiveData := makeManagementPluginStoreZip(t, "sample-provider"+managementPluginExtension(runtime.GOOS), "unsigned-library-data")
+	checksum := sha256.Sum256(archiveData)
+	artifactURL := "https://downloads.example/sample-provider.zip"
+	h := &Handler{
+		cfg: &config.Config{
+			Plugins: config.PluginsConfig{
+				Dir: pluginsDir,
+			},
+		},
+		configFilePath:         writeTestConfigFile(t),
+		pluginStoreRegistryURL: "https://registry.example/registry.json",
+		pluginStoreHTTPClient: fakePluginStoreHTTPClient{
+			"https://registry.example/registry.json": directRegistryJSON(artifactURL, hex.EncodeToString(checksum[:])),
+			artifactURL:                              archiveData,
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Params = gin.Params{{Key: "id", Value: "sample-provider"}}
+	c.Request = httptest.NewRequest(http.MethodPost, "/v0/management/plugin-store/sample-provider/install", nil)
+	h.InstallPluginFromStore(c)
+
+	if rec.Code != http.StatusBadGateway || !strings.Contains(rec.Body.String(), "not signed") {
+		t.Fatalf("status = %d, body=%s; want unsigned artifact refused", rec.Code, rec.Body.String())
+	}
+	if _, configured := h.cfg.Plugins.Configs["sample-provider"]; configured {
+		t.Fatal("refused install enabled the plugin")
+	}
+	targetPath := filepath.Join(pluginsDir, runtime.GOOS, runtime.GOARCH, "sample-provider-v0.4.0"+managementPluginExtension(runtime.GOOS))
+	if _, errStat := os.Stat(targetPath); !os.IsNotExist(errStat) {
+		t.Fatalf("refused install wrote %s: %v", targetPath, errStat)
+	}
+}
+
 func TestInstallPluginFromStoreHonorsDirectQueryVersion(t *testing.T) {
 	t.Parallel()
 
@@ -935,8 +1062,9 @@ func TestInstallPluginFromStoreHonorsDirectQueryVersion(t *testing.T) {
 	h := &Handler{
 		cfg: &config.Config{
 			Plugins: config.PluginsConfig{
-				Enabled: false,
-				Dir:     pluginsDir,
+				Enabled:    false,
+				Dir:        pluginsDir,
+				StoreTrust: allowUnsignedPluginStoreTrust(),
 			},
 		},
 		configFilePath:         writeTestConfigFile(t),
@@ -997,6 +1125,7 @@ func TestInstallPluginFromStoreUsesRequestedThirdPartySource(t *testing.T) {
 				Enabled:      false,
 				Dir:          pluginsDir,
 				StoreSources: []string{"https://community.example/registry.json"},
+				StoreTrust:   allowUnsignedPluginStoreTrust(),
 			},
 		},
 		configFilePath: writeTestConfigFile(t),
@@ -1103,8 +1232,9 @@ func TestInstallPluginFromStoreOverwritesFilePreservesConfigAndReloads(t *testin
 	h := &Handler{
 		cfg: &config.Config{
 			Plugins: config.PluginsConfig{
-				Enabled: true,
-				Dir:     pluginsDir,
+				Enabled:    true,
+				Dir:        pluginsDir,
+				StoreTrust: allowUnsignedPluginStoreTrust(),
 				Configs: map[string]config.PluginInstanceConfig{
 					"sample-provider": pluginConfigFromYAML(t, "enabled: false\npriority: 5\nmode: fast\nextra: keep\n"),
 				},
@@ -1417,6 +1547,27 @@ func pluginStorePlatformsContain(platforms []pluginStorePlatform, goos string, g
 	return false
 }
 
+func allowUnsignedPluginStoreTrust() []pluginstore.TrustConfig {
+	return []pluginstore.TrustConfig{{Source: pluginstore.TrustSourceAny, AllowUnsigned: true}}
+}
+
+// signManagementPluginStoreArtifact signs data with a fixed minisign key and
+// returns the public key, its key ID, and the signature file.
+func signManagementPluginStoreArtifact(t *testing.T, data []byte) (string, string, []byte) {
+	t.Helper()
+	private := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{9}, ed25519.SeedSize))
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	publicKey := base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), private.Public().(ed25519.PublicKey)...))
+	signature := ed25519.Sign(private, data)
+	trustedComment := "sample-provider 0.4.0"
+	global := ed25519.Sign(private, append(append([]byte(nil), signature...), trustedComment...))
+	signatureFile := "untrusted comment: signature from minisign secret key\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), signature...)) + "\n" +
+		"trusted comment: " + trustedComment + "\n" +
+		base64.StdEncoding.EncodeToString(global) + "\n"
+	return publicKey, "0807060504030201", []byte(signatureFile)
+}
+
 func makeManagementPluginStoreZip(t *testing.T, name string, content string) []byte {
 	t.Helper()
 
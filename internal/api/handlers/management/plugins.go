@@ -37,6 +37,10 @@ type pluginListEntry struct {
 	ConfigFields     []pluginConfigFieldInfo `json:"config_fields"`
 	Menus            []pluginMenuInfo        `json:"menus"`
 	Metadata         *pluginMetadataInfo     `json:"metadata"`
+	Events           *pluginEventsInfo       `json:"events,omitempty"`
+	Signature        string                  `json:"signature,omitempty"`
+	Signer           string                  `json:"signer,omitempty"`
+	SignerKeyID      string                  `json:"signer_key_id,omitempty"`
 }
 
 type pluginMetadataInfo struct {
@@ -48,6 +52,16 @@ type pluginMetadataInfo struct {
 	ConfigFields     []pluginConfigFieldInfo `json:"config_fields"`
 }
 
+// pluginEventsInfo reports host event delivery for plugins that subscribe to events.
+type pluginEventsInfo struct {
+	Topics    []string `json:"topics"`
+	QueueSize int      `json:"queue_size"`
+	Queued    int      `json:"queued"`
+	Delivered uint64   `json:"delivered"`
+	Failed    uint64   `json:"failed"`
+	Dropped   uint64   `json:"dropped"`
+}
+
 type pluginConfigFieldInfo struct {
 	Name        string   `json:"name"`
 	Type        string   `json:"type"`
@@ -107,6 +121,11 @@ func (h *Handler) ListPlugins(c *gin.Context) {
 		entry.ID = htmlsanitize.String(id)
 		entry.Configured = true
 		entry.Enabled = pluginInstanceEnabled(item)
+		if manifest, managed := pluginStoreConfiguredManifest(item); managed {
+			entry.Signature = htmlsanitize.String(strings.TrimSpace(manifest.Signature))
+			entry.Signer = htmlsanitize.String(strings.TrimSpace(manifest.Signer))
+			entry.SignerKeyID = htmlsanitize.String(strings.TrimSpace(manifest.SignerKeyID))
+		}
 		if entry.ConfigFields == nil {
 			entry.ConfigFields = []pluginConfigFieldInfo{}
 		}
@@ -126,6 +145,7 @@ func (h *Handler) ListPlugins(c *gin.Context) {
 			entry.ConfigFields = pluginConfigFields(info.Metadata.ConfigFields)
 			entry.Menus = pluginMenus(info.Menus)
 			entry.Metadata = pluginMetadata(info.Metadata)
+			entry.Events = pluginEvents(info.Events)
 			entries[info.ID] = entry
 		}
 	}
@@ -381,7 +401,7 @@ func (h *Handler) DeletePlugin(c *gin.Context) {
 	h.mu.Lock()
 	delete(h.cfg.Plugins.Configs, id)
 	if configured {
-		if errSave := config.SaveConfigPreserveComments(h.configFilePath, h.cfg); errSave != nil {
+		if errSave := h.saveConfigLocked(c); errSave != nil {
 			h.mu.Unlock()
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error":        "config_save_failed",
@@ -472,6 +492,24 @@ func pluginConfigFields(fields []pluginapi.ConfigField) []pluginConfigFieldInfo
 	return out
 }
 
+func pluginEvents(stats *pluginhost.PluginEventStats) *pluginEventsInfo {
+	if stats == nil {
+		return nil
+	}
+	topics := make([]string, 0, len(stats.Topics))
+	for _, topic := range stats.Topics {
+		topics = append(topics, htmlsanitize.String(topic))
+	}
+	return &pluginEventsInfo{
+		Topics:    topics,
+		QueueSize: stats.QueueSize,
+		Queued:    stats.Queued,
+		Delivered: stats.Delivered,
+		Failed:    stats.Failed,
+		Dropped:   stats.Dropped,
+	}
+}
+
 func pluginMenus(menus []pluginhost.RegisteredPluginMenu) []pluginMenuInfo {
 	out := make([]pluginMenuInfo, 0, len(menus))
 	for _, menu := range menus {
@@ -0,0 +1,152 @@
+package management
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+)
+
+type quotaWindowCredential struct {
+	AuthIndex     string                 `json:"auth_index"`
+	ID            string                 `json:"id"`
+	Name          string                 `json:"name,omitempty"`
+	Provider      string                 `json:"provider"`
+	Label         string                 `json:"label,omitempty"`
+	Email         string                 `json:"email,omitempty"`
+	Status        coreauth.Status        `json:"status"`
+	StatusMessage string                 `json:"status_message,omitempty"`
+	Unavailable   bool                   `json:"unavailable"`
+	NextRetryAt   *time.Time             `json:"next_retry_at,omitempty"`
+	UsageEndpoint bool                   `json:"usage_endpoint"`
+	ObservedAt    *time.Time             `json:"observed_at,omitempty"`
+	Windows       []coreauth.QuotaWindow `json:"windows"`
+	// ExhaustsAt is the earliest predicted exhaustion before a reset across windows.
+	ExhaustsAt *time.Time `json:"exhausts_at,omitempty"`
+}
+
+// GetQuotaWindows lists the rate-limit windows observed for each credential,
+// with burn rates and predicted exhaustion. Credentials whose provider exposes a
+// usage endpoint are listed even before any window was observed.
+//
+// Query: provider, auth_index (both optional filters).
+func (h *Handler) GetQuotaWindows(c *gin.Context) {
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	providerFilter := strings.ToLower(strings.TrimSpace(c.Query("provider")))
+	indexFilter := strings.TrimSpace(c.Query("auth_index"))
+	now := time.Now()
+
+	credentials := make([]quotaWindowCredential, 0)
+	for _, auth := range h.authManager.List() {
+		if auth == nil {
+			continue
+		}
+		auth.EnsureIndex()
+		if providerFilter != "" && !strings.EqualFold(auth.Provider, providerFilter) {
+			continue
+		}
+		if indexFilter != "" && auth.Index != indexFilter {
+			continue
+		}
+		supported := coreauth.SupportsQuotaUsageEndpoint(auth)
+		if len(auth.Quota.Limits.Windows) == 0 && !supported {
+			continue
+		}
+		entry := quotaWindowCredential{
+			AuthIndex:     auth.Index,
+			ID:            auth.ID,
+			Name:          auth.FileName,
+			Provider:      auth.Provider,
+			Label:         auth.Label,
+			Email:         authEmail(auth),
+			Status:        auth.Status,
+			StatusMessage: auth.StatusMessage,
+			Unavailable:   auth.Unavailable,
+			UsageEndpoint: supported,
+			Windows:       make([]coreauth.QuotaWindow, 0, len(auth.Quota.Limits.Windows)),
+		}
+		if auth.NextRetryAfter.After(now) {
+			next := auth.NextRetryAfter
+			entry.NextRetryAt = &next
+		}
+		if observed := auth.Quota.Limits.ObservedAt; !observed.IsZero() {
+			entry.ObservedAt = &observed
+		}
+		for _, window := range auth.Quota.Limits.Windows {
+			if !window.ResetAt.IsZero() && !window.ResetAt.After(now) {
+				continue
+			}
+			entry.Windows = append(entry.Windows, window)
+			if !window.ExhaustsAt.IsZero() && (entry.ExhaustsAt == nil || window.ExhaustsAt.Before(*entry.ExhaustsAt)) {
+				exhaustsAt := window.ExhaustsAt
+				entry.ExhaustsAt = &exhaustsAt
+			}
+		}
+		credentials = append(credentials, entry)
+	}
+	sort.Slice(credentials, func(i, j int) bool {
+		if credentials[i].Provider != credentials[j].Provider {
+			return credentials[i].Provider < credentials[j].Provider
+		}
+		return credentials[i].ID < credentials[j].ID
+	})
+	c.JSON(http.StatusOK, gin.H{"credentials": credentials})
+}
+
+// RefreshQuotaWindows queries provider usage endpoints and records the reported
+// windows. Body: {"auth_index": "..."}; without auth_index every enabled
+// credential with a usage endpoint is refreshed.
+func (h *Handler) RefreshQuotaWindows(c *gin.Context) {
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	var req struct {
+		AuthIndex string `json:"auth_index"`
+	}
+	if c.Request.ContentLength != 0 {
+		if errBind := c.ShouldBindJSON(&req); errBind != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+	}
+
+	var targets []*coreauth.Auth
+	if authIndex := strings.TrimSpace(req.AuthIndex); authIndex != "" {
+		auth := h.authByIndex(authIndex)
+		if auth == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "auth not found"})
+			return
+		}
+		if !coreauth.SupportsQuotaUsageEndpoint(auth) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "no usage endpoint for provider " + auth.Provider})
+			return
+		}
+		targets = append(targets, auth)
+	} else {
+		for _, auth := range h.authManager.List() {
+			if auth == nil || auth.Disabled || !coreauth.SupportsQuotaUsageEndpoint(auth) {
+				continue
+			}
+			auth.EnsureIndex()
+			targets = append(targets, auth)
+		}
+	}
+
+	refreshed := make([]string, 0, len(targets))
+	failures := make(map[string]string)
+	for _, auth := range targets {
+		if _, errRefresh := h.authManager.RefreshQuotaWindows(c.Request.Context(), auth.ID); errRefresh != nil {
+			failures[auth.Index] = errRefresh.Error()
+			continue
+		}
+		refreshed = append(refreshed, auth.Index)
+	}
+	c.JSON(http.StatusOK, gin.H{"refreshed": refreshed, "errors": failures})
+}
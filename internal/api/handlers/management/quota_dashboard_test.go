@@ -0,0 +1,65 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+)
+
+func TestGetQuotaWindowsListsObservedAndQueryableCredentials(t *testing.T) {
+	manager := coreauth.NewManager(nil, nil, nil)
+	now := time.Now()
+	for _, auth := range []*coreauth.Auth{
+		{ID: "codex-oauth", Provider: "codex", Status: coreauth.StatusActive, Metadata: map[string]any{"email": "dev@example.com"}},
+		{ID: "claude-oauth", Provider: "claude", Status: coreauth.StatusActive},
+		{ID: "gemini-key", Provider: "gemini", Status: coreauth.StatusActive, Attributes: map[string]string{"api_key": "secret"}},
+	} {
+		if _, errRegister := manager.Register(context.Background(), auth); errRegister != nil {
+			t.Fatalf("Register(%s) error = %v", auth.ID, errRegister)
+		}
+	}
+	manager.ObserveQuotaWindows(context.Background(), "codex-oauth", coreauth.QuotaSourceHeaders, []coreauth.QuotaWindow{
+		{Name: "codex-primary", RemainingFraction: 0.4, ResetAt: now.Add(time.Hour)},
+		{Name: "codex-secondary", RemainingFraction: 0.9, ResetAt: now.Add(72 * time.Hour)},
+	})
+	h := NewHandlerWithoutConfigFilePath(&config.Config{AuthDir: t.TempDir()}, manager)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v0/management/quota-windows", nil)
+	h.GetQuotaWindows(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Credentials []quotaWindowCredential `json:"credentials"`
+	}
+	if errUnmarshal := json.Unmarshal(rec.Body.Bytes(), &body); errUnmarshal != nil {
+		t.Fatalf("unmarshal: %v", errUnmarshal)
+	}
+	if len(body.Credentials) != 2 || body.Credentials[0].ID != "claude-oauth" || body.Credentials[1].ID != "codex-oauth" {
+		t.Fatalf("credentials = %+v, want claude-oauth and codex-oauth", body.Credentials)
+	}
+	if claude := body.Credentials[0]; !claude.UsageEndpoint || len(claude.Windows) != 0 {
+		t.Fatalf("claude entry = %+v, want a queryable credential without windows", claude)
+	}
+	if codex := body.Credentials[1]; len(codex.Windows) != 2 || codex.ObservedAt == nil || codex.AuthIndex == "" {
+		t.Fatalf("codex entry = %+v, want both observed windows", codex)
+	}
+
+	rec = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v0/management/quota-windows?provider=claude", nil)
+	h.GetQuotaWindows(c)
+	body.Credentials = nil
+	if errUnmarshal := json.Unmarshal(rec.Body.Bytes(), &body); errUnmarshal != nil || len(body.Credentials) != 1 || body.Credentials[0].Provider != "claude" {
+		t.Fatalf("filtered body = %s (%v)", rec.Body.String(), errUnmarshal)
+	}
+}
@@ -0,0 +1,239 @@
+package management
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// ManagementKeyNameContextKey holds the name of the management key that authenticated the request.
+	ManagementKeyNameContextKey = "managementKeyName"
+	// ManagementRoleContextKey holds the role granted to the request.
+	ManagementRoleContextKey = "managementRole"
+
+	managementKeyNameSecret   = "secret-key"
+	managementKeyNameEnv      = "MANAGEMENT_PASSWORD"
+	managementKeyNameLocal    = "local-password"
+	managementRoutePathPrefix = "/v0/management"
+)
+
+// ManagementIdentity describes the key that authenticated a management request.
+type ManagementIdentity struct {
+	Name   string
+	Role   string
+	Routes []string
+}
+
+// managementRolePolicy lists the routes, relative to /v0/management, that each
+// non-admin role may call. Routes not listed here require the admin role.
+var managementRolePolicy = map[string][]string{
+	config.ManagementRoleViewer: {
+		"GET /api-key-usage",
+		"GET /usage-queue",
+		"GET /usage-statistics-enabled",
+		"GET /logs",
+		"GET /request-error-logs",
+		"GET /request-error-logs/*",
+		"GET /request-logs",
+		"GET /request-log-by-id/*",
+		"GET /events",
+		"GET /notifications",
+		"GET /auth-files",
+		"GET /auth-files/models",
+		"GET /auth-files/health",
+		"GET /quota-windows",
+		"POST /routing/explain",
+		"GET /session-affinity/bindings",
+		"GET /session-affinity/binding",
+		"GET /model-definitions/*",
+		"GET /latest-version",
+	},
+	config.ManagementRoleOperator: {
+		"PATCH /auth-files/status",
+		"POST /auth-files/check",
+		"POST /notifications/test",
+		"POST /reset-quota",
+		"POST /quota-windows/refresh",
+		"PUT /session-affinity/binding",
+		"POST /session-affinity/invalidate",
+	},
+}
+
+// managementRoleIncludes lists the roles whose routes a role inherits.
+var managementRoleIncludes = map[string][]string{
+	config.ManagementRoleOperator: {config.ManagementRoleViewer},
+}
+
+// Permits reports whether the identity may call method on path.
+func (id ManagementIdentity) Permits(method, path string) bool {
+	if len(id.Routes) > 0 && !matchManagementRoutes(id.Routes, method, path, "") {
+		return false
+	}
+	return managementRolePermits(id.Role, method, path)
+}
+
+func managementRolePermits(role, method, path string) bool {
+	if role == config.ManagementRoleAdmin {
+		return true
+	}
+	if matchManagementRoutes(managementRolePolicy[role], method, path, managementRoutePathPrefix) {
+		return true
+	}
+	for _, included := range managementRoleIncludes[role] {
+		if managementRolePermits(included, method, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchManagementRoutes matches "METHOD pattern" or bare "pattern" entries.
+// HEAD requests match GET entries.
+func matchManagementRoutes(routes []string, method, path, prefix string) bool {
+	method = strings.ToUpper(method)
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	for _, route := range routes {
+		routeMethod, pattern := "", strings.TrimSpace(route)
+		if before, after, found := strings.Cut(pattern, " "); found {
+			routeMethod, pattern = strings.ToUpper(strings.TrimSpace(before)), strings.TrimSpace(after)
+		}
+		if routeMethod != "" && routeMethod != "*" && routeMethod != method {
+			continue
+		}
+		if matchRoutePattern(prefix+pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchRoutePattern(pattern, path string) bool {
+	if !strings.Contains(pattern, "*") {
+		return pattern == path
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	path = path[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, segment := range parts[1 : len(parts)-1] {
+		index := strings.Index(path, segment)
+		if index < 0 {
+			return false
+		}
+		path = path[index+len(segment):]
+	}
+	return strings.HasSuffix(path, last)
+}
+
+// maxRejectedManagementKeys bounds the cache of rejected management keys.
+const maxRejectedManagementKeys = 1024
+
+// matchManagementKey checks provided against the named keys and the legacy
+// secret-key hash; legacy reports a secret-key match. Verified and rejected
+// keys are cached by digest so a repeated key skips bcrypt however many named
+// keys are configured. A rejection only holds for the key set it was checked
+// against.
+func (h *Handler) matchManagementKey(secretHash string, keys []config.ManagementKey, provided string) (key config.ManagementKey, legacy, ok bool) {
+	digest := sha256.Sum256([]byte(provided))
+	keySet := managementKeySetDigest(secretHash, keys)
+	h.attemptsMu.Lock()
+	cachedHash, verified := h.verifiedKeys[digest]
+	rejectedFor, rejected := h.rejectedKeys[digest]
+	h.attemptsMu.Unlock()
+	if verified {
+		if secretHash != "" && cachedHash == secretHash {
+			return config.ManagementKey{}, true, true
+		}
+		for _, key := range keys {
+			if key.Key == cachedHash {
+				return key, false, true
+			}
+		}
+	}
+	if rejected && rejectedFor == keySet {
+		return config.ManagementKey{}, false, false
+	}
+
+	for _, key := range keys {
+		if bcrypt.CompareHashAndPassword([]byte(key.Key), []byte(provided)) == nil {
+			h.rememberManagementKey(digest, key.Key)
+			return key, false, true
+		}
+	}
+	if secretHash != "" && bcrypt.CompareHashAndPassword([]byte(secretHash), []byte(provided)) == nil {
+		h.rememberManagementKey(digest, secretHash)
+		return config.ManagementKey{}, true, true
+	}
+
+	h.attemptsMu.Lock()
+	if h.rejectedKeys == nil || len(h.rejectedKeys) >= maxRejectedManagementKeys {
+		h.rejectedKeys = make(map[[sha256.Size]byte][sha256.Size]byte)
+	}
+	h.rejectedKeys[digest] = keySet
+	h.attemptsMu.Unlock()
+	return config.ManagementKey{}, false, false
+}
+
+func (h *Handler) rememberManagementKey(digest [sha256.Size]byte, hash string) {
+	h.attemptsMu.Lock()
+	if h.verifiedKeys == nil {
+		h.verifiedKeys = make(map[[sha256.Size]byte]string)
+	}
+	h.verifiedKeys[digest] = hash
+	delete(h.rejectedKeys, digest)
+	h.attemptsMu.Unlock()
+}
+
+// managementKeySetDigest identifies the configured key hashes.
+func managementKeySetDigest(secretHash string, keys []config.ManagementKey) [sha256.Size]byte {
+	sum := sha256.New()
+	sum.Write([]byte(secretHash))
+	for _, key := range keys {
+		sum.Write([]byte{0})
+		sum.Write([]byte(key.Key))
+	}
+	var digest [sha256.Size]byte
+	sum.Sum(digest[:0])
+	return digest
+}
+
+// ManagementIdentityFromContext returns the identity stored by Middleware.
+func ManagementIdentityFromContext(c *gin.Context) (ManagementIdentity, bool) {
+	if c == nil {
+		return ManagementIdentity{}, false
+	}
+	name, okName := c.Get(ManagementKeyNameContextKey)
+	role, okRole := c.Get(ManagementRoleContextKey)
+	if !okName || !okRole {
+		return ManagementIdentity{}, false
+	}
+	nameValue, _ := name.(string)
+	roleValue, _ := role.(string)
+	return ManagementIdentity{Name: nameValue, Role: roleValue}, true
+}
+
+// recordManagementCall logs the key and role behind a mutating management call.
+func recordManagementCall(c *gin.Context, identity ManagementIdentity) {
+	method := c.Request.Method
+	if !isMutatingMethod(method) {
+		return
+	}
+	log.WithFields(log.Fields{
+		"key":    identity.Name,
+		"role":   identity.Role,
+		"method": method,
+		"path":   c.Request.URL.Path,
+		"status": c.Writer.Status(),
+		"client": c.ClientIP(),
+	}).Info("management call")
+}
@@ -0,0 +1,170 @@
+package management
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newNamedKeyHandler(t *testing.T, keys ...config.ManagementKey) *Handler {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.RemoteManagement.Keys = keys
+	if errSanitize := cfg.SanitizeManagementKeys(); errSanitize != nil {
+		t.Fatalf("sanitize management keys: %v", errSanitize)
+	}
+	return &Handler{cfg: cfg, failedAttempts: make(map[string]*attemptInfo)}
+}
+
+func TestManagementRolePermits(t *testing.T) {
+	tests := []struct {
+		role   string
+		method string
+		path   string
+		want   bool
+	}{
+		{config.ManagementRoleViewer, http.MethodGet, "/v0/management/api-key-usage", true},
+		{config.ManagementRoleViewer, http.MethodGet, "/v0/management/request-error-logs/error-1.log", true},
+		{config.ManagementRoleViewer, http.MethodGet, "/v0/management/auth-files", true},
+		{config.ManagementRoleViewer, http.MethodGet, "/v0/management/auth-files/download", false},
+		{config.ManagementRoleViewer, http.MethodDelete, "/v0/management/logs", false},
+		{config.ManagementRoleViewer, http.MethodPatch, "/v0/management/auth-files/status", false},
+		{config.ManagementRoleViewer, http.MethodGet, "/v0/management/config.yaml", false},
+		{config.ManagementRoleOperator, http.MethodGet, "/v0/management/logs", true},
+		{config.ManagementRoleOperator, http.MethodPatch, "/v0/management/auth-files/status", true},
+		{config.ManagementRoleOperator, http.MethodPost, "/v0/management/reset-quota", true},
+		{config.ManagementRoleOperator, http.MethodPut, "/v0/management/config.yaml", false},
+		{config.ManagementRoleOperator, http.MethodPost, "/v0/management/plugins/install", false},
+		{config.ManagementRoleAdmin, http.MethodPut, "/v0/management/config.yaml", true},
+		{config.ManagementRoleAdmin, http.MethodPost, "/v0/management/plugins/install", true},
+	}
+	for _, tt := range tests {
+		if got := managementRolePermits(tt.role, tt.method, tt.path); got != tt.want {
+			t.Errorf("managementRolePermits(%s, %s %s) = %v, want %v", tt.role, tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestManagementIdentityRoutesNarrowRole(t *testing.T) {
+	identity := ManagementIdentity{Name: "ci", Role: config.ManagementRoleOperator, Routes: []string{"POST /v0/management/reset-quota", "/v0/management/auth-files*"}}
+
+	if !identity.Permits(http.MethodPost, "/v0/management/reset-quota") {
+		t.Fatalf("expected reset-quota to be allowed")
+	}
+	if !identity.Permits(http.MethodPatch, "/v0/management/auth-files/status") {
+		t.Fatalf("expected auth-files status to be allowed")
+	}
+	if identity.Permits(http.MethodGet, "/v0/management/logs") {
+		t.Fatalf("expected logs to be outside the route allow-list")
+	}
+	if identity.Permits(http.MethodDelete, "/v0/management/auth-files") {
+		t.Fatalf("expected route allow-list not to widen the operator role")
+	}
+}
+
+func TestMiddlewareEnforcesNamedKeyRoles(t *testing.T) {
+	h := newNamedKeyHandler(t,
+		config.ManagementKey{Name: "dashboard", Key: "viewer-secret", Role: "viewer"},
+		config.ManagementKey{Name: "oncall", Key: "operator-secret", Role: "operator"},
+		config.ManagementKey{Name: "root", Key: "admin-secret", Role: "admin"},
+	)
+	engine := gin.New()
+	var gotName, gotRole string
+	handler := func(c *gin.Context) {
+		identity, _ := ManagementIdentityFromContext(c)
+		gotName, gotRole = identity.Name, identity.Role
+		c.Status(http.StatusOK)
+	}
+	engine.GET("/v0/management/logs", h.Middleware(), handler)
+	engine.POST("/v0/management/reset-quota", h.Middleware(), handler)
+	engine.PUT("/v0/management/config.yaml", h.Middleware(), handler)
+
+	tests := []struct {
+		key    string
+		method string
+		path   string
+		want   int
+		name   string
+	}{
+		{"viewer-secret", http.MethodGet, "/v0/management/logs", http.StatusOK, "dashboard"},
+		{"viewer-secret", http.MethodPost, "/v0/management/reset-quota", http.StatusForbidden, ""},
+		{"operator-secret", http.MethodPost, "/v0/management/reset-quota", http.StatusOK, "oncall"},
+		{"operator-secret", http.MethodPut, "/v0/management/config.yaml", http.StatusForbidden, ""},
+		{"admin-secret", http.MethodPut, "/v0/management/config.yaml", http.StatusOK, "root"},
+		{"unknown", http.MethodGet, "/v0/management/logs", http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		gotName, gotRole = "", ""
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.RemoteAddr = "127.0.0.1:12345"
+		req.Header.Set("Authorization", "Bearer "+tt.key)
+		engine.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Fatalf("%s %s with %q: status = %d, want %d", tt.method, tt.path, tt.key, rec.Code, tt.want)
+		}
+		if gotName != tt.name {
+			t.Fatalf("%s %s with %q: key name = %q, want %q", tt.method, tt.path, tt.key, gotName, tt.name)
+		}
+		if tt.name != "" && gotRole == "" {
+			t.Fatalf("%s %s with %q: role not set", tt.method, tt.path, tt.key)
+		}
+	}
+}
+
+func TestAuthenticateManagementIdentityLegacySecretIsAdmin(t *testing.T) {
+	h := &Handler{cfg: &config.Config{}, failedAttempts: make(map[string]*attemptInfo), envSecret: "env-secret"}
+
+	identity, allowed, _, _ := h.AuthenticateManagementIdentity("127.0.0.1", true, "env-secret")
+	if !allowed {
+		t.Fatalf("expected env secret to authenticate")
+	}
+	if identity.Name != managementKeyNameEnv || identity.Role != config.ManagementRoleAdmin {
+		t.Fatalf("identity = %+v, want admin %s", identity, managementKeyNameEnv)
+	}
+}
+
+func TestAuthenticateManagementIdentityCachesLegacyAndRejectedKeys(t *testing.T) {
+	h := newNamedKeyHandler(t,
+		config.ManagementKey{Name: "dashboard", Key: "viewer-secret", Role: "viewer"},
+		config.ManagementKey{Name: "oncall", Key: "operator-secret", Role: "operator"},
+	)
+	legacyHash, errHash := bcrypt.GenerateFromPassword([]byte("legacy-secret"), bcrypt.MinCost)
+	if errHash != nil {
+		t.Fatalf("hash legacy key: %v", errHash)
+	}
+	h.cfg.RemoteManagement.SecretKey = string(legacyHash)
+
+	for i := 0; i < 2; i++ {
+		identity, allowed, _, _ := h.AuthenticateManagementIdentity("127.0.0.1", true, "legacy-secret")
+		if !allowed || identity.Name != managementKeyNameSecret {
+			t.Fatalf("legacy key attempt %d = %+v, %v", i, identity, allowed)
+		}
+	}
+	if got := h.verifiedKeys[sha256.Sum256([]byte("legacy-secret"))]; got != string(legacyHash) {
+		t.Fatalf("verified cache for legacy key = %q, want the secret-key hash", got)
+	}
+
+	if _, allowed, _, _ := h.AuthenticateManagementIdentity("127.0.0.1", true, "wrong-secret"); allowed {
+		t.Fatal("wrong key authenticated")
+	}
+	if _, rejected := h.rejectedKeys[sha256.Sum256([]byte("wrong-secret"))]; !rejected {
+		t.Fatal("wrong key not remembered as rejected")
+	}
+
+	// A rotation that makes the rejected key valid must not be hidden by the cache.
+	h.cfg.RemoteManagement.Keys = append(h.cfg.RemoteManagement.Keys, config.ManagementKey{Name: "new", Key: "wrong-secret", Role: "admin"})
+	if errSanitize := h.cfg.SanitizeManagementKeys(); errSanitize != nil {
+		t.Fatalf("sanitize management keys: %v", errSanitize)
+	}
+	identity, allowed, _, _ := h.AuthenticateManagementIdentity("127.0.0.1", true, "wrong-secret")
+	if !allowed || identity.Name != "new" {
+		t.Fatalf("rotated-in key = %+v, %v; want the new named key", identity, allowed)
+	}
+}
@@ -0,0 +1,99 @@
+package management
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	coresession "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/session"
+)
+
+// ExplainRouting dry-runs credential selection for a model and reports every
+// candidate with the reasons it would or would not be picked. Nothing is
+// executed and no rotation or session binding changes.
+//
+// Body: {"model": "...", "client_key": "...", "headers": {...},
+// "session_id": "...", "providers": [...], "retry_round": 0, "websocket": false}.
+// providers is optional and defaults to the providers serving the model.
+func (h *Handler) ExplainRouting(c *gin.Context) {
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	var req struct {
+		Model      string            `json:"model"`
+		ClientKey  string            `json:"client_key"`
+		Headers    map[string]string `json:"headers"`
+		SessionID  string            `json:"session_id"`
+		Providers  []string          `json:"providers"`
+		RetryRound int               `json:"retry_round"`
+		Websocket  bool              `json:"websocket"`
+	}
+	if errBind := c.ShouldBindJSON(&req); errBind != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	model := strings.TrimSpace(req.Model)
+	if model == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "model is required"})
+		return
+	}
+	if req.RetryRound < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "retry_round must not be negative"})
+		return
+	}
+
+	model = resolveExplainModel(model)
+	providers := req.Providers
+	if len(providers) == 0 {
+		providers = util.GetProviderName(strings.TrimSpace(thinking.ParseSuffix(model).ModelName))
+		if len(providers) == 0 {
+			providers = util.GetProviderName(model)
+		}
+	}
+	if len(providers) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown provider for model " + model})
+		return
+	}
+
+	headers := make(http.Header, len(req.Headers))
+	for key, value := range req.Headers {
+		if key = strings.TrimSpace(key); key != "" {
+			headers.Set(key, value)
+		}
+	}
+	explanation, errExplain := h.authManager.ExplainRouting(c.Request.Context(), coreauth.RoutingExplainRequest{
+		Providers:   providers,
+		Model:       model,
+		Headers:     headers,
+		SessionID:   req.SessionID,
+		CallerScope: coresession.CallerScope(req.ClientKey),
+		RetryRound:  req.RetryRound,
+		Websocket:   req.Websocket,
+	})
+	if errExplain != nil {
+		status := http.StatusInternalServerError
+		var authErr *coreauth.Error
+		if errors.As(errExplain, &authErr) && authErr.HTTPStatus != 0 {
+			status = authErr.HTTPStatus
+		}
+		c.JSON(status, gin.H{"error": errExplain.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, explanation)
+}
+
+// resolveExplainModel maps "auto" to a concrete model the way request
+// handlers do, keeping any thinking suffix.
+func resolveExplainModel(model string) string {
+	parsed := thinking.ParseSuffix(model)
+	resolved := util.ResolveAutoModel(parsed.ModelName)
+	if parsed.HasSuffix {
+		return resolved + "(" + parsed.RawSuffix + ")"
+	}
+	return resolved
+}
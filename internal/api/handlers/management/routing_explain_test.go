@@ -0,0 +1,61 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+)
+
+func TestExplainRoutingReportsCandidateReasons(t *testing.T) {
+	manager := coreauth.NewManager(nil, nil, nil)
+	auth := &coreauth.Auth{ID: "explain-auth-id", FileName: "explain-auth.json", Provider: "claude", Disabled: true, Status: coreauth.StatusDisabled}
+	if _, errRegister := manager.Register(context.Background(), auth); errRegister != nil {
+		t.Fatalf("Register() error = %v", errRegister)
+	}
+	h := NewHandlerWithoutConfigFilePath(&config.Config{AuthDir: t.TempDir()}, manager)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v0/management/routing/explain", strings.NewReader(`{"model":"explain-model","providers":["claude"],"client_key":"sk-test","session_id":"s-1"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	h.ExplainRouting(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s, want 200", rec.Code, rec.Body.String())
+	}
+
+	var payload coreauth.RoutingExplanation
+	if errUnmarshal := json.Unmarshal(rec.Body.Bytes(), &payload); errUnmarshal != nil {
+		t.Fatalf("decode response: %v", errUnmarshal)
+	}
+	if payload.Selected != nil || payload.Error == "" {
+		t.Fatalf("selected = %v error = %q, want no pick with an error", payload.Selected, payload.Error)
+	}
+	if len(payload.Candidates) != 1 || payload.Candidates[0].File != "explain-auth.json" {
+		t.Fatalf("candidates = %+v, want the registered credential", payload.Candidates)
+	}
+	reasons := payload.Candidates[0].Reasons
+	if !slices.Contains(reasons, coreauth.RoutingReasonExecutorMissing) || !slices.Contains(reasons, coreauth.RoutingReasonDisabled) {
+		t.Fatalf("reasons = %v, want executor_missing and disabled", reasons)
+	}
+}
+
+func TestExplainRoutingRequiresModel(t *testing.T) {
+	h := NewHandlerWithoutConfigFilePath(&config.Config{AuthDir: t.TempDir()}, coreauth.NewManager(nil, nil, nil))
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v0/management/routing/explain", strings.NewReader(`{"client_key":"sk-test"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	h.ExplainRouting(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+}
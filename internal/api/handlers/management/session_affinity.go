@@ -0,0 +1,166 @@
+package management
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+)
+
+// GetSessionBindings lists the live session-affinity bindings of this node.
+// An optional auth_id query parameter limits the list to one credential.
+func (h *Handler) GetSessionBindings(c *gin.Context) {
+	if !h.sessionBindingsAvailable(c) {
+		return
+	}
+	bindings, errBindings := h.authManager.SessionBindings()
+	if errBindings != nil {
+		writeSessionAffinityError(c, errBindings)
+		return
+	}
+	if authID := strings.TrimSpace(c.Query("auth_id")); authID != "" {
+		filtered := bindings[:0]
+		for _, binding := range bindings {
+			if binding.AuthID == authID {
+				filtered = append(filtered, binding)
+			}
+		}
+		bindings = filtered
+	}
+	c.JSON(http.StatusOK, gin.H{"bindings": bindings})
+}
+
+// GetSessionBinding looks up the bindings of one session. The session query
+// parameter accepts the session identifier, its hash, or the raw client value;
+// model optionally narrows the result.
+func (h *Handler) GetSessionBinding(c *gin.Context) {
+	if !h.sessionBindingsAvailable(c) {
+		return
+	}
+	session := strings.TrimSpace(c.Query("session"))
+	if session == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "session is required"})
+		return
+	}
+	bindings, errLookup := h.authManager.LookupSessionBindings(session, c.Query("model"))
+	if errLookup != nil {
+		writeSessionAffinityError(c, errLookup)
+		return
+	}
+	if len(bindings) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session binding not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"bindings": bindings})
+}
+
+// PutSessionBinding forces a session onto a specific credential.
+//
+// Body: {"session": "...", "model": "...", "auth_id": "..."}; the credential
+// may also be given as auth_index or name. model is required only when the
+// session has no binding yet.
+func (h *Handler) PutSessionBinding(c *gin.Context) {
+	if !h.sessionBindingsAvailable(c) {
+		return
+	}
+	var req struct {
+		Session string `json:"session"`
+		Model   string `json:"model"`
+		sessionAffinityAuthTarget
+	}
+	if errBind := c.ShouldBindJSON(&req); errBind != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	session := strings.TrimSpace(req.Session)
+	if session == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "session is required"})
+		return
+	}
+	auth, ok := h.resolveSessionAffinityAuth(c, req.sessionAffinityAuthTarget)
+	if !ok {
+		return
+	}
+	bindings, errRebind := h.authManager.RebindSession(session, strings.TrimSpace(req.Model), auth.ID)
+	if errRebind != nil {
+		writeSessionAffinityError(c, errRebind)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"bindings": bindings})
+}
+
+// InvalidateSessionBindings drops every binding of a credential, typically
+// before taking it down for maintenance.
+//
+// Body: {"auth_id": "..."}, or auth_index or name instead of auth_id.
+func (h *Handler) InvalidateSessionBindings(c *gin.Context) {
+	if !h.sessionBindingsAvailable(c) {
+		return
+	}
+	var req sessionAffinityAuthTarget
+	if errBind := c.ShouldBindJSON(&req); errBind != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	auth, ok := h.resolveSessionAffinityAuth(c, req)
+	if !ok {
+		return
+	}
+	removed, errInvalidate := h.authManager.InvalidateSessionBindings(auth.ID)
+	if errInvalidate != nil {
+		writeSessionAffinityError(c, errInvalidate)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "auth_id": auth.ID, "removed": removed})
+}
+
+// sessionBindingsAvailable writes the error response and returns false when
+// session bindings cannot be managed, including while Home selects credentials.
+func (h *Handler) sessionBindingsAvailable(c *gin.Context) bool {
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return false
+	}
+	if errAvailable := h.authManager.SessionBindingsAvailable(); errAvailable != nil {
+		writeSessionAffinityError(c, errAvailable)
+		return false
+	}
+	return true
+}
+
+type sessionAffinityAuthTarget struct {
+	AuthID    string `json:"auth_id"`
+	AuthIndex string `json:"auth_index"`
+	Name      string `json:"name"`
+}
+
+func (h *Handler) resolveSessionAffinityAuth(c *gin.Context, target sessionAffinityAuthTarget) (*coreauth.Auth, bool) {
+	var auth *coreauth.Auth
+	switch {
+	case strings.TrimSpace(target.AuthID) != "":
+		auth, _ = h.authManager.GetByID(strings.TrimSpace(target.AuthID))
+	case strings.TrimSpace(target.AuthIndex) != "":
+		auth = h.authByIndex(target.AuthIndex)
+	case strings.TrimSpace(target.Name) != "":
+		auth, _ = h.lookupAuthFile(target.Name, "")
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "auth_id, auth_index or name is required"})
+		return nil, false
+	}
+	if auth == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "auth not found"})
+		return nil, false
+	}
+	return auth, true
+}
+
+func writeSessionAffinityError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	var authErr *coreauth.Error
+	if errors.As(err, &authErr) && authErr.HTTPStatus != 0 {
+		status = authErr.HTTPStatus
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
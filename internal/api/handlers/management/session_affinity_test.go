@@ -0,0 +1,122 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+)
+
+func newSessionAffinityTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	selector := coreauth.NewSessionAffinitySelector(&coreauth.RoundRobinSelector{})
+	t.Cleanup(selector.Stop)
+	manager := coreauth.NewManager(nil, selector, nil)
+	for _, auth := range []*coreauth.Auth{
+		{ID: "affinity-a", FileName: "affinity-a.json", Provider: "claude", Status: coreauth.StatusActive},
+		{ID: "affinity-b", FileName: "affinity-b.json", Provider: "claude", Status: coreauth.StatusActive},
+	} {
+		if _, errRegister := manager.Register(context.Background(), auth); errRegister != nil {
+			t.Fatalf("Register() error = %v", errRegister)
+		}
+	}
+	return NewHandlerWithoutConfigFilePath(&config.Config{AuthDir: t.TempDir()}, manager)
+}
+
+func serveSessionAffinity(handler gin.HandlerFunc, method, target, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(method, target, strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handler(c)
+	return rec
+}
+
+func TestSessionAffinityRebindLookupAndInvalidate(t *testing.T) {
+	h := newSessionAffinityTestHandler(t)
+
+	rec := serveSessionAffinity(h.PutSessionBinding, http.MethodPut, "/v0/management/session-affinity/binding", `{"session":"header:abc","model":"claude-test","name":"affinity-a.json"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("rebind status = %d body = %s", rec.Code, rec.Body.String())
+	}
+
+	rec = serveSessionAffinity(h.GetSessionBinding, http.MethodGet, "/v0/management/session-affinity/binding?session=abc", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("lookup status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	var lookup struct {
+		Bindings []coreauth.SessionBinding `json:"bindings"`
+	}
+	if errUnmarshal := json.Unmarshal(rec.Body.Bytes(), &lookup); errUnmarshal != nil {
+		t.Fatalf("decode lookup: %v", errUnmarshal)
+	}
+	if len(lookup.Bindings) != 1 || lookup.Bindings[0].AuthID != "affinity-a" || lookup.Bindings[0].SessionHash != coreauth.SessionBindingHash("header:abc") {
+		t.Fatalf("lookup bindings = %+v", lookup.Bindings)
+	}
+
+	rec = serveSessionAffinity(h.GetSessionBindings, http.MethodGet, "/v0/management/session-affinity/bindings?auth_id=affinity-b", "")
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "affinity-a") {
+		t.Fatalf("filtered list status = %d body = %s, want no affinity-a binding", rec.Code, rec.Body.String())
+	}
+
+	rec = serveSessionAffinity(h.InvalidateSessionBindings, http.MethodPost, "/v0/management/session-affinity/invalidate", `{"auth_id":"affinity-a"}`)
+	var invalidate struct {
+		Removed int `json:"removed"`
+	}
+	if errUnmarshal := json.Unmarshal(rec.Body.Bytes(), &invalidate); errUnmarshal != nil || rec.Code != http.StatusOK || invalidate.Removed != 1 {
+		t.Fatalf("invalidate status = %d body = %s, want one removed", rec.Code, rec.Body.String())
+	}
+
+	rec = serveSessionAffinity(h.GetSessionBinding, http.MethodGet, "/v0/management/session-affinity/binding?session=abc", "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("lookup after invalidate status = %d, want 404", rec.Code)
+	}
+}
+
+func TestSessionAffinityEndpointsRequireSessionAffinity(t *testing.T) {
+	h := NewHandlerWithoutConfigFilePath(&config.Config{AuthDir: t.TempDir()}, coreauth.NewManager(nil, &coreauth.RoundRobinSelector{}, nil))
+
+	rec := serveSessionAffinity(h.GetSessionBindings, http.MethodGet, "/v0/management/session-affinity/bindings", "")
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409", rec.Code)
+	}
+}
+
+func TestSessionAffinityRebindUnknownAuth(t *testing.T) {
+	h := newSessionAffinityTestHandler(t)
+
+	rec := serveSessionAffinity(h.PutSessionBinding, http.MethodPut, "/v0/management/session-affinity/binding", `{"session":"header:abc","model":"claude-test","auth_id":"missing"}`)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", rec.Code)
+	}
+}
+
+func TestSessionAffinityEndpointsUnavailableUnderHome(t *testing.T) {
+	h := newSessionAffinityTestHandler(t)
+	h.authManager.SetConfig(&config.Config{Home: config.HomeConfig{Enabled: true}})
+
+	cases := []struct {
+		name    string
+		handler gin.HandlerFunc
+		method  string
+		target  string
+		body    string
+	}{
+		{"list", h.GetSessionBindings, http.MethodGet, "/v0/management/session-affinity/bindings", ""},
+		{"lookup", h.GetSessionBinding, http.MethodGet, "/v0/management/session-affinity/binding?session=abc", ""},
+		{"rebind", h.PutSessionBinding, http.MethodPut, "/v0/management/session-affinity/binding", `{"session":"header:abc","model":"claude-test","auth_id":"affinity-a"}`},
+		{"invalidate", h.InvalidateSessionBindings, http.MethodPost, "/v0/management/session-affinity/invalidate", `{"auth_id":"affinity-a"}`},
+	}
+	for _, tc := range cases {
+		rec := serveSessionAffinity(tc.handler, tc.method, tc.target, tc.body)
+		if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "home_unavailable") {
+			t.Fatalf("%s status = %d body = %s, want 503 home_unavailable", tc.name, rec.Code, rec.Body.String())
+		}
+	}
+}
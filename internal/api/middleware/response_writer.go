@@ -22,6 +22,10 @@ const requestBodyOverrideContextKey = "REQUEST_BODY_OVERRIDE"
 const responseBodyOverrideContextKey = "RESPONSE_BODY_OVERRIDE"
 const websocketTimelineOverrideContextKey = "WEBSOCKET_TIMELINE_OVERRIDE"
 
+// ClientPrincipalLogHeader is the pseudo request header under which request logs
+// record the authenticated client identity.
+const ClientPrincipalLogHeader = "X-Cliproxy-Client-Principal"
+
 // RequestInfo holds essential details of an incoming HTTP request for logging purposes.
 type RequestInfo struct {
 	URL                 string                      // URL is the request URL.
@@ -150,6 +154,18 @@ func (w *ResponseWriterWrapper) WriteString(data string) (int, error) {
 	return n, err
 }
 
+// SetClientPrincipal records the authenticated client identity in the logged
+// request headers. Only identities that are not secrets should be passed.
+func (w *ResponseWriterWrapper) SetClientPrincipal(principal string) {
+	if w == nil || w.requestInfo == nil || principal == "" {
+		return
+	}
+	if w.requestInfo.Headers == nil {
+		w.requestInfo.Headers = make(map[string][]string)
+	}
+	w.requestInfo.Headers[ClientPrincipalLogHeader] = []string{principal}
+}
+
 // WriteHeader wraps the underlying ResponseWriter's WriteHeader method.
 // It captures the status code, detects if the response is streaming based on the Content-Type header,
 // and initializes the appropriate logging mechanism (standard or streaming).
@@ -378,3 +378,19 @@ func TestFinalizeIncludes500InForceLog(t *testing.T) {
 		t.Fatalf("expected 1 logged call for 500 status, got: %v", logger.loggedCalls)
 	}
 }
+
+func TestSetClientPrincipalAddsLoggedRequestHeader(t *testing.T) {
+	original := http.Header{"Content-Type": []string{"application/json"}}
+	wrapper := &ResponseWriterWrapper{
+		requestInfo: &RequestInfo{Headers: map[string][]string{"Content-Type": original["Content-Type"]}},
+	}
+
+	wrapper.SetClientPrincipal("billing-service")
+
+	if got := wrapper.requestInfo.Headers[ClientPrincipalLogHeader]; len(got) != 1 || got[0] != "billing-service" {
+		t.Fatalf("logged principal header = %v, want [billing-service]", got)
+	}
+	if _, leaked := original[ClientPrincipalLogHeader]; leaked {
+		t.Fatal("SetClientPrincipal modified the live request headers")
+	}
+}
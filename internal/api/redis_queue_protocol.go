@@ -131,7 +131,10 @@ func (s *Server) handleRedisConnection(conn net.Conn, reader *bufio.Reader) {
 				}
 				continue
 			}
-			allowed, _, errMsg := s.mgmt.AuthenticateManagementKey(clientIP, localClient, password)
+			identity, allowed, _, errMsg := s.mgmt.AuthenticateManagementIdentity(clientIP, localClient, password)
+			if allowed && !identity.Permits(http.MethodGet, "/v0/management/usage-queue") {
+				allowed, errMsg = false, "management key is not allowed to read the usage queue"
+			}
 			if !allowed {
 				_ = writeRedisError(writer, "ERR "+errMsg)
 				if !flush() {
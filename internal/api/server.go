@@ -232,7 +232,7 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 
 	// Register management routes when configuration or environment secrets are available,
 	// or when a local management password is provided (e.g. TUI mode).
-	hasManagementSecret := cfg.RemoteManagement.SecretKey != "" || envManagementSecret || s.localPassword != ""
+	hasManagementSecret := cfg.RemoteManagement.HasManagementKeys() || envManagementSecret || s.localPassword != ""
 	s.managementRoutesEnabled.Store(hasManagementSecret)
 	redisqueue.SetEnabled(hasManagementSecret || (cfg != nil && cfg.Home.Enabled))
 	if hasManagementSecret {
@@ -247,8 +247,9 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 
 	// Create HTTP server
 	s.server = &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
-		Handler: engine,
+		Addr:        fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Handler:     engine,
+		ConnContext: muxConnContext,
 	}
 
 	return s
@@ -288,9 +289,19 @@ func (s *Server) Start() error {
 			return fmt.Errorf("failed to start HTTPS server: %v", errLoad)
 		}
 
+		clientCAs, errClientCAs := s.cfg.TLS.LoadClientCAs()
+		if errClientCAs != nil {
+			if errClose := listener.Close(); errClose != nil {
+				log.Errorf("failed to close listener after client CA load failure: %v", errClose)
+			}
+			return fmt.Errorf("failed to start HTTPS server: %v", errClientCAs)
+		}
+
 		tlsConfig := &tls.Config{
 			Certificates: []tls.Certificate{certPair},
 			NextProtos:   []string{"h2", "http/1.1"},
+			ClientCAs:    clientCAs,
+			ClientAuth:   s.cfg.TLS.ClientAuthType(),
 		}
 		s.server.TLSConfig = tlsConfig
 		if errHTTP2 := http2.ConfigureServer(s.server, &http2.Server{}); errHTTP2 != nil {
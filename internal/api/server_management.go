@@ -75,6 +75,8 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.PUT("/quota-exceeded/switch-preview-model", s.mgmt.PutSwitchPreviewModel)
 		mgmt.PATCH("/quota-exceeded/switch-preview-model", s.mgmt.PutSwitchPreviewModel)
 		mgmt.POST("/reset-quota", s.mgmt.ResetQuota)
+		mgmt.GET("/quota-windows", s.mgmt.GetQuotaWindows)
+		mgmt.POST("/quota-windows/refresh", s.mgmt.RefreshQuotaWindows)
 
 		mgmt.GET("/api-keys", s.mgmt.GetAPIKeys)
 		mgmt.PUT("/api-keys", s.mgmt.PutAPIKeys)
@@ -97,7 +99,12 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.DELETE("/logs", s.mgmt.DeleteLogs)
 		mgmt.GET("/request-error-logs", s.mgmt.GetRequestErrorLogs)
 		mgmt.GET("/request-error-logs/:name", s.mgmt.DownloadRequestErrorLog)
+		mgmt.GET("/request-logs", s.mgmt.GetRequestLogs)
 		mgmt.GET("/request-log-by-id/:id", s.mgmt.GetRequestLogByID)
+		mgmt.GET("/audit", s.mgmt.GetAudit)
+		mgmt.GET("/events", s.mgmt.GetEvents)
+		mgmt.GET("/notifications", s.mgmt.GetNotifications)
+		mgmt.POST("/notifications/test", s.mgmt.TestNotifications)
 		mgmt.GET("/request-log", s.mgmt.GetRequestLog)
 		mgmt.PUT("/request-log", s.mgmt.PutRequestLog)
 		mgmt.PATCH("/request-log", s.mgmt.PutRequestLog)
@@ -122,6 +129,12 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/routing/strategy", s.mgmt.GetRoutingStrategy)
 		mgmt.PUT("/routing/strategy", s.mgmt.PutRoutingStrategy)
 		mgmt.PATCH("/routing/strategy", s.mgmt.PutRoutingStrategy)
+		mgmt.POST("/routing/explain", s.mgmt.ExplainRouting)
+
+		mgmt.GET("/session-affinity/bindings", s.mgmt.GetSessionBindings)
+		mgmt.GET("/session-affinity/binding", s.mgmt.GetSessionBinding)
+		mgmt.PUT("/session-affinity/binding", s.mgmt.PutSessionBinding)
+		mgmt.POST("/session-affinity/invalidate", s.mgmt.InvalidateSessionBindings)
 
 		mgmt.GET("/claude-api-key", s.mgmt.GetClaudeKeys)
 		mgmt.PUT("/claude-api-key", s.mgmt.PutClaudeKeys)
@@ -165,6 +178,8 @@ func (s *Server) registerManagementRoutes() {
 
 		mgmt.GET("/auth-files", s.mgmt.ListAuthFiles)
 		mgmt.GET("/auth-files/models", s.mgmt.GetAuthFileModels)
+		mgmt.GET("/auth-files/health", s.mgmt.GetAuthFilesHealth)
+		mgmt.POST("/auth-files/check", s.mgmt.CheckAuthFiles)
 		mgmt.GET("/model-definitions/:channel", s.mgmt.GetStaticModelDefinitions)
 		mgmt.GET("/auth-files/download", s.mgmt.DownloadAuthFile)
 		mgmt.POST("/auth-files", s.mgmt.UploadAuthFile)
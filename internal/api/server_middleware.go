@@ -171,6 +171,11 @@ func accessAuthMiddleware(manager *sdkaccess.Manager, realtimeError bool) gin.Ha
 				if len(result.Metadata) > 0 {
 					c.Set("accessMetadata", result.Metadata)
 				}
+				if result.PrincipalLoggable {
+					if writer, ok := c.Writer.(interface{ SetClientPrincipal(string) }); ok {
+						writer.SetClientPrincipal(result.Principal)
+					}
+				}
 			}
 			c.Next()
 			return
@@ -0,0 +1,184 @@
+// Package audit records an append-only trail of management API mutations.
+// Entries are written to a JSONL file or a database table and can optionally
+// be forwarded to Home.
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultQueryLimit is the number of entries returned when a filter has no limit.
+	DefaultQueryLimit = 100
+	// MaxQueryLimit caps the number of entries returned by a single query.
+	MaxQueryLimit = 1000
+)
+
+// Entry is a single audit record.
+type Entry struct {
+	ID            string          `json:"id"`
+	Timestamp     time.Time       `json:"timestamp"`
+	ClientIP      string          `json:"client_ip,omitempty"`
+	Key           string          `json:"key,omitempty"`
+	Role          string          `json:"role,omitempty"`
+	Method        string          `json:"method"`
+	Route         string          `json:"route,omitempty"`
+	Path          string          `json:"path"`
+	Status        int             `json:"status"`
+	Request       *RequestSummary `json:"request,omitempty"`
+	ConfigChanges []ConfigChange  `json:"config_changes,omitempty"`
+}
+
+// RequestSummary describes a request without recording secret values.
+type RequestSummary struct {
+	Query         map[string]string `json:"query,omitempty"`
+	ContentType   string            `json:"content_type,omitempty"`
+	ContentLength int64             `json:"content_length,omitempty"`
+	Fields        []string          `json:"fields,omitempty"`
+}
+
+// ConfigChange is one line of a config diff split into its parts.
+// Detail keeps the original text when the line has no before/after form.
+type ConfigChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Filter selects entries returned by Store.Query.
+type Filter struct {
+	Since      time.Time
+	Until      time.Time
+	Key        string
+	Role       string
+	Method     string
+	PathPrefix string
+	Limit      int
+}
+
+// Store persists and queries audit entries.
+type Store interface {
+	Append(ctx context.Context, entry Entry) error
+	// Query returns matching entries, newest first.
+	Query(ctx context.Context, filter Filter) ([]Entry, error)
+}
+
+var databaseStore atomic.Pointer[Store]
+
+// SetDatabaseStore registers the database-backed store used when the audit
+// backend is "postgres". Passing nil clears it.
+func SetDatabaseStore(store Store) {
+	if store == nil {
+		databaseStore.Store(nil)
+		return
+	}
+	databaseStore.Store(&store)
+}
+
+// DatabaseStore returns the registered database-backed store, if any.
+func DatabaseStore() Store {
+	if store := databaseStore.Load(); store != nil {
+		return *store
+	}
+	return nil
+}
+
+// NewID returns a random identifier for an entry.
+func NewID() string {
+	var buf [12]byte
+	if _, errRead := rand.Read(buf[:]); errRead != nil {
+		return time.Now().UTC().Format("20060102T150405.000000000")
+	}
+	return hex.EncodeToString(buf[:])
+}
+
+// Normalize fills the ID and timestamp of an entry when missing.
+func (e *Entry) Normalize() {
+	if e.ID == "" {
+		e.ID = NewID()
+	}
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+	e.Timestamp = e.Timestamp.UTC()
+	e.Method = strings.ToUpper(e.Method)
+}
+
+// NormalizedLimit returns the limit clamped to [1, MaxQueryLimit].
+func (f Filter) NormalizedLimit() int {
+	switch {
+	case f.Limit <= 0:
+		return DefaultQueryLimit
+	case f.Limit > MaxQueryLimit:
+		return MaxQueryLimit
+	default:
+		return f.Limit
+	}
+}
+
+// Match reports whether the entry satisfies the filter, ignoring the limit.
+func (f Filter) Match(entry Entry) bool {
+	if !f.Since.IsZero() && entry.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.Timestamp.After(f.Until) {
+		return false
+	}
+	if f.Key != "" && entry.Key != f.Key {
+		return false
+	}
+	if f.Role != "" && !strings.EqualFold(entry.Role, f.Role) {
+		return false
+	}
+	if f.Method != "" && !strings.EqualFold(entry.Method, f.Method) {
+		return false
+	}
+	if f.PathPrefix != "" && !strings.HasPrefix(entry.Path, f.PathPrefix) {
+		return false
+	}
+	return true
+}
+
+// ParseConfigChanges converts the text lines produced by the config diff
+// ("field: before -> after" or "field: detail") into structured changes.
+// Indented lines that follow a bare "section:" header are prefixed with the section.
+func ParseConfigChanges(lines []string) []ConfigChange {
+	if len(lines) == 0 {
+		return nil
+	}
+	changes := make([]ConfigChange, 0, len(lines))
+	section := ""
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if strings.HasSuffix(trimmed, ":") && !strings.Contains(trimmed, ": ") {
+			section = strings.TrimSuffix(trimmed, ":")
+			continue
+		}
+		field, rest, found := strings.Cut(trimmed, ": ")
+		if !found {
+			field, rest = "", trimmed
+		}
+		if strings.HasPrefix(line, " ") && section != "" {
+			field = strings.Trim(section+"."+field, ".")
+		} else {
+			section = ""
+		}
+		change := ConfigChange{Field: field}
+		if before, after, isDiff := strings.Cut(rest, " -> "); isDiff {
+			change.Before, change.After = strings.TrimSpace(before), strings.TrimSpace(after)
+		} else {
+			change.Detail = strings.TrimSpace(rest)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
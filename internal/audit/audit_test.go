@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseConfigChanges(t *testing.T) {
+	changes := ParseConfigChanges([]string{
+		"debug: false -> true",
+		"api-keys: values updated (count unchanged, redacted)",
+		"openai-compatibility:",
+		"  provider added: acme",
+		"remote-management.keys: 1 -> 2",
+	})
+	want := []ConfigChange{
+		{Field: "debug", Before: "false", After: "true"},
+		{Field: "api-keys", Detail: "values updated (count unchanged, redacted)"},
+		{Field: "openai-compatibility.provider added", Detail: "acme"},
+		{Field: "remote-management.keys", Before: "1", After: "2"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("ParseConfigChanges() = %+v, want %+v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("change[%d] = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+}
+
+func TestFileStoreAppendAndQuery(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "nested", "audit.jsonl"))
+	ctx := context.Background()
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	entries := []Entry{
+		{Timestamp: base, Key: "root", Role: "admin", Method: "put", Path: "/v0/management/config.yaml", Status: 200},
+		{Timestamp: base.Add(time.Minute), Key: "oncall", Role: "operator", Method: "POST", Path: "/v0/management/reset-quota", Status: 200},
+		{Timestamp: base.Add(2 * time.Minute), Key: "root", Role: "admin", Method: "DELETE", Path: "/v0/management/auth-files", Status: 200},
+	}
+	for _, entry := range entries {
+		if errAppend := store.Append(ctx, entry); errAppend != nil {
+			t.Fatalf("Append() error = %v", errAppend)
+		}
+	}
+
+	all, errQuery := store.Query(ctx, Filter{})
+	if errQuery != nil {
+		t.Fatalf("Query() error = %v", errQuery)
+	}
+	if len(all) != 3 || all[0].Method != "DELETE" || all[2].Method != "PUT" || all[0].ID == "" {
+		t.Fatalf("Query() = %+v, want newest first with IDs", all)
+	}
+
+	filtered, errQuery := store.Query(ctx, Filter{Key: "root", Since: base.Add(30 * time.Second)})
+	if errQuery != nil {
+		t.Fatalf("Query(filter) error = %v", errQuery)
+	}
+	if len(filtered) != 1 || filtered[0].Path != "/v0/management/auth-files" {
+		t.Fatalf("Query(filter) = %+v", filtered)
+	}
+
+	limited, errQuery := store.Query(ctx, Filter{Limit: 2})
+	if errQuery != nil {
+		t.Fatalf("Query(limit) error = %v", errQuery)
+	}
+	if len(limited) != 2 || limited[1].Method != "POST" {
+		t.Fatalf("Query(limit) = %+v", limited)
+	}
+}
+
+func TestFileStoreQueryMissingFile(t *testing.T) {
+	entries, errQuery := NewFileStore(filepath.Join(t.TempDir(), "audit.jsonl")).Query(context.Background(), Filter{})
+	if errQuery != nil || len(entries) != 0 {
+		t.Fatalf("Query() = %v, %v, want empty", entries, errQuery)
+	}
+}
@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// maxFileLineBytes bounds a single JSONL record when reading the audit file.
+const maxFileLineBytes = 4 << 20
+
+// FileStore appends entries to a JSONL file.
+type FileStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileStore returns a store writing to path. The file and its directory are
+// created on first append.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Path returns the file backing the store.
+func (s *FileStore) Path() string {
+	if s == nil {
+		return ""
+	}
+	return s.path
+}
+
+// Append writes entry as one JSON line.
+func (s *FileStore) Append(_ context.Context, entry Entry) error {
+	if s == nil || s.path == "" {
+		return fmt.Errorf("audit file store: path is empty")
+	}
+	entry.Normalize()
+	raw, errMarshal := json.Marshal(entry)
+	if errMarshal != nil {
+		return fmt.Errorf("audit file store: encode entry: %w", errMarshal)
+	}
+	raw = append(raw, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if errMkdir := os.MkdirAll(filepath.Dir(s.path), 0o700); errMkdir != nil {
+		return fmt.Errorf("audit file store: create directory: %w", errMkdir)
+	}
+	file, errOpen := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if errOpen != nil {
+		return fmt.Errorf("audit file store: open: %w", errOpen)
+	}
+	if _, errWrite := file.Write(raw); errWrite != nil {
+		_ = file.Close()
+		return fmt.Errorf("audit file store: write: %w", errWrite)
+	}
+	if errClose := file.Close(); errClose != nil {
+		return fmt.Errorf("audit file store: close: %w", errClose)
+	}
+	return nil
+}
+
+// Query scans the file and returns matching entries, newest first.
+func (s *FileStore) Query(ctx context.Context, filter Filter) ([]Entry, error) {
+	if s == nil || s.path == "" {
+		return nil, fmt.Errorf("audit file store: path is empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, errOpen := os.Open(s.path)
+	if errOpen != nil {
+		if os.IsNotExist(errOpen) {
+			return []Entry{}, nil
+		}
+		return nil, fmt.Errorf("audit file store: open: %w", errOpen)
+	}
+	defer func() { _ = file.Close() }()
+
+	limit := filter.NormalizedLimit()
+	entries := make([]Entry, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFileLineBytes)
+	for scanner.Scan() {
+		if ctx != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry Entry
+		if errUnmarshal := json.Unmarshal(line, &entry); errUnmarshal != nil {
+			continue
+		}
+		if !filter.Match(entry) {
+			continue
+		}
+		entries = append(entries, entry)
+		// Keep memory bounded: the file is in append order, so only the tail matters.
+		if len(entries) > 2*limit {
+			entries = append(entries[:0], entries[len(entries)-limit:]...)
+		}
+	}
+	if errScan := scanner.Err(); errScan != nil {
+		return nil, fmt.Errorf("audit file store: read: %w", errScan)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.After(entries[j].Timestamp)
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/home"
+	log "github.com/sirupsen/logrus"
+)
+
+const homeForwardTimeout = 5 * time.Second
+
+// HomeClient is the subset of the Home client used to forward audit entries.
+type HomeClient interface {
+	HeartbeatOK() bool
+	RPushAuditLog(ctx context.Context, payload []byte) error
+}
+
+var currentHomeClient = func() HomeClient {
+	if client := home.Current(); client != nil {
+		return client
+	}
+	return nil
+}
+
+// Recorder writes entries to a store and optionally forwards them to Home.
+type Recorder struct {
+	store       Store
+	forwardHome bool
+}
+
+// NewRecorder returns a recorder for store. When forwardHome is true, entries
+// are also pushed to Home while its heartbeat is healthy.
+func NewRecorder(store Store, forwardHome bool) *Recorder {
+	return &Recorder{store: store, forwardHome: forwardHome}
+}
+
+// Store returns the store backing the recorder.
+func (r *Recorder) Store() Store {
+	if r == nil {
+		return nil
+	}
+	return r.store
+}
+
+// Record persists entry. Failures are logged and never block the caller's response.
+func (r *Recorder) Record(ctx context.Context, entry Entry) {
+	if r == nil {
+		return
+	}
+	entry.Normalize()
+	if r.store != nil {
+		if errAppend := r.store.Append(ctx, entry); errAppend != nil {
+			log.WithError(errAppend).Warn("audit: failed to append entry")
+		}
+	}
+	if r.forwardHome {
+		go forwardToHome(entry)
+	}
+}
+
+func forwardToHome(entry Entry) {
+	client := currentHomeClient()
+	if client == nil || !client.HeartbeatOK() {
+		return
+	}
+	raw, errMarshal := json.Marshal(entry)
+	if errMarshal != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), homeForwardTimeout)
+	defer cancel()
+	if errPush := client.RPushAuditLog(ctx, raw); errPush != nil {
+		log.WithError(errPush).Debug("audit: failed to forward entry to home")
+	}
+}
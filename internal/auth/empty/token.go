@@ -24,3 +24,10 @@ func (ts *EmptyStorage) SaveTokenToFile(_ string) error {
 	ts.Type = "empty"
 	return nil
 }
+
+// MarshalToken implements the in-memory rendering used by encrypted saves.
+// Empty storage has no content, so nothing is written.
+func (ts *EmptyStorage) MarshalToken() ([]byte, error) {
+	ts.Type = "empty"
+	return nil, nil
+}
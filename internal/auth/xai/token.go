@@ -1,6 +1,7 @@
 package xai
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -9,7 +10,6 @@ import (
 	"time"
 
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/misc"
-	log "github.com/sirupsen/logrus"
 )
 
 // TokenStorage stores xAI OAuth credentials on disk.
@@ -40,33 +40,38 @@ func (ts *TokenStorage) SetMetadata(meta map[string]any) {
 // SaveTokenToFile writes xAI credentials to a JSON auth file.
 func (ts *TokenStorage) SaveTokenToFile(authFilePath string) error {
 	misc.LogSavingCredentials(authFilePath)
-	ts.Type = "xai"
-	ts.AuthKind = "oauth"
 	if errMkdirAll := os.MkdirAll(filepath.Dir(authFilePath), 0o700); errMkdirAll != nil {
 		return fmt.Errorf("xai token storage: create directory: %w", errMkdirAll)
 	}
 
-	data, errMerge := misc.MergeMetadata(ts, ts.Metadata)
-	if errMerge != nil {
-		return fmt.Errorf("xai token storage: merge metadata: %w", errMerge)
+	payload, errMarshal := ts.MarshalToken()
+	if errMarshal != nil {
+		return errMarshal
 	}
+	if errWrite := os.WriteFile(authFilePath, payload, 0o600); errWrite != nil {
+		return fmt.Errorf("xai token storage: write token file: %w", errWrite)
+	}
+	return nil
+}
 
-	file, err := os.Create(authFilePath)
-	if err != nil {
-		return fmt.Errorf("xai token storage: create token file: %w", err)
+// MarshalToken renders the token file content in memory so it can be sealed
+// before it reaches disk.
+func (ts *TokenStorage) MarshalToken() ([]byte, error) {
+	ts.Type = "xai"
+	ts.AuthKind = "oauth"
+
+	data, errMerge := misc.MergeMetadata(ts, ts.Metadata)
+	if errMerge != nil {
+		return nil, fmt.Errorf("xai token storage: merge metadata: %w", errMerge)
 	}
-	defer func() {
-		if errClose := file.Close(); errClose != nil {
-			log.Errorf("xai token storage: close token file error: %v", errClose)
-		}
-	}()
 
-	encoder := json.NewEncoder(file)
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
 	encoder.SetIndent("", "  ")
-	if err = encoder.Encode(data); err != nil {
-		return fmt.Errorf("xai token storage: write token file: %w", err)
+	if errEncode := encoder.Encode(data); errEncode != nil {
+		return nil, fmt.Errorf("xai token storage: encode token: %w", errEncode)
 	}
-	return nil
+	return buf.Bytes(), nil
 }
 
 // CredentialFileName returns the filename used for xAI credentials.
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/envelope"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v7/sdk/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// authFilePersister is implemented by token stores that mirror the auth
+// directory to a remote backend (git, PostgreSQL, object storage).
+type authFilePersister interface {
+	PersistAuthFiles(ctx context.Context, message string, paths ...string) error
+}
+
+// configPersister is implemented by token stores that mirror config.yaml.
+type configPersister interface {
+	PersistConfig(ctx context.Context) error
+}
+
+// MigratePlaintextAuthFiles seals auth files that are still stored in
+// plaintext, or under a retired key, once encryption at rest is enabled. The
+// rewritten files are pushed to the registered token store backend.
+func MigratePlaintextAuthFiles(cfg *config.Config) {
+	if cfg == nil || !envelope.Enabled() {
+		return
+	}
+	changed, errSeal := sealAuthDir(context.Background(), cfg, "Encrypt auth files at rest")
+	if errSeal != nil {
+		log.Errorf("encryption: failed to migrate auth files: %v", errSeal)
+		return
+	}
+	if len(changed) > 0 {
+		log.Infof("encryption: sealed %d auth file(s)", len(changed))
+	}
+}
+
+// DoRotateKey re-seals every auth file and config secret with the current
+// primary key. Run it after installing a new master key and listing the old
+// one under encryption.previous-key-files (or the KMS keyring); once it
+// completes the old key can be retired.
+func DoRotateKey(cfg *config.Config, configFilePath string) {
+	if cfg == nil || !cfg.Encryption.Enabled || !envelope.Enabled() {
+		log.Error("rotate-key: encryption is not enabled in the config")
+		return
+	}
+	ctx := context.Background()
+	keyID := envelope.Current().KeyID()
+
+	changed, errSeal := sealAuthDir(ctx, cfg, "Rotate auth file encryption key")
+	if errSeal != nil {
+		log.Errorf("rotate-key: %v", errSeal)
+		return
+	}
+	fmt.Printf("Re-sealed %d auth file(s) with key %s\n", len(changed), keyID)
+
+	// Save a freshly loaded copy so runtime-only adjustments such as the
+	// resolved auth directory are not written back.
+	fresh, errLoad := config.LoadConfig(configFilePath)
+	if errLoad != nil {
+		log.Errorf("rotate-key: failed to reload config: %v", errLoad)
+		return
+	}
+	if errSave := config.SaveConfigPreserveComments(configFilePath, fresh); errSave != nil {
+		log.Errorf("rotate-key: failed to re-seal config secrets: %v", errSave)
+		return
+	}
+	if persister, ok := sdkAuth.GetTokenStore().(configPersister); ok {
+		if errPersist := persister.PersistConfig(ctx); errPersist != nil {
+			log.Errorf("rotate-key: failed to persist config: %v", errPersist)
+			return
+		}
+	}
+	fmt.Printf("Re-sealed config secrets in %s\n", configFilePath)
+}
+
+// sealAuthDir seals stale files under the auth directory and persists them
+// through the registered token store.
+func sealAuthDir(ctx context.Context, cfg *config.Config, message string) ([]string, error) {
+	authDir, errResolve := util.ResolveAuthDir(cfg.AuthDir)
+	if errResolve != nil {
+		return nil, fmt.Errorf("resolve auth directory: %w", errResolve)
+	}
+	changed, errSeal := envelope.SealDir(authDir)
+	if errSeal != nil {
+		return changed, errSeal
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	if persister, ok := sdkAuth.GetTokenStore().(authFilePersister); ok {
+		if errPersist := persister.PersistAuthFiles(ctx, message, changed...); errPersist != nil {
+			return changed, fmt.Errorf("persist auth files: %w", errPersist)
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// AuditLogBackendFile stores audit entries in a JSONL file.
+	AuditLogBackendFile = "file"
+	// AuditLogBackendPostgres stores audit entries in the Postgres store's audit table.
+	AuditLogBackendPostgres = "postgres"
+)
+
+// AuditLogConfig configures the management audit trail under 'remote-management.audit-log'.
+type AuditLogConfig struct {
+	// Enabled turns on recording of mutating management calls.
+	Enabled bool `yaml:"enabled"`
+	// Backend selects where entries are stored: "file" (default) or "postgres".
+	// The postgres backend requires the Postgres-backed store (PGSTORE_DSN).
+	Backend string `yaml:"backend,omitempty"`
+	// File overrides the JSONL path. Defaults to audit.jsonl in the logs directory.
+	File string `yaml:"file,omitempty"`
+	// ForwardToHome also pushes entries to Home when it is enabled.
+	ForwardToHome bool `yaml:"forward-to-home,omitempty"`
+}
+
+// SanitizeAuditLog normalizes the audit backend and path.
+func (cfg *Config) SanitizeAuditLog() {
+	if cfg == nil {
+		return
+	}
+	audit := &cfg.RemoteManagement.AuditLog
+	audit.File = strings.TrimSpace(audit.File)
+	switch backend := strings.ToLower(strings.TrimSpace(audit.Backend)); backend {
+	case "", AuditLogBackendFile:
+		audit.Backend = AuditLogBackendFile
+	case AuditLogBackendPostgres:
+		audit.Backend = backend
+	default:
+		log.Warnf("remote-management.audit-log.backend %q is not supported, using %q", audit.Backend, AuditLogBackendFile)
+		audit.Backend = AuditLogBackendFile
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// TLSClientAuthOptional verifies client certificates only when presented.
+	TLSClientAuthOptional = "optional"
+	// TLSClientAuthRequire rejects TLS handshakes without a valid client certificate.
+	TLSClientAuthRequire = "require"
+)
+
+const (
+	// ClientCertPrincipalCN uses the certificate subject common name as the principal.
+	ClientCertPrincipalCN = "cn"
+	// ClientCertPrincipalSANDNS uses the DNS subject alternative names.
+	ClientCertPrincipalSANDNS = "san-dns"
+	// ClientCertPrincipalSANURI uses the URI subject alternative names (e.g. SPIFFE IDs).
+	ClientCertPrincipalSANURI = "san-uri"
+	// ClientCertPrincipalSANEmail uses the email subject alternative names.
+	ClientCertPrincipalSANEmail = "san-email"
+)
+
+// ClientAuthType maps ClientAuth to the crypto/tls verification mode. It
+// returns tls.NoClientCert when no client CA is configured.
+func (t TLSConfig) ClientAuthType() tls.ClientAuthType {
+	if strings.TrimSpace(t.ClientCA) == "" {
+		return tls.NoClientCert
+	}
+	if strings.EqualFold(strings.TrimSpace(t.ClientAuth), TLSClientAuthRequire) {
+		return tls.RequireAndVerifyClientCert
+	}
+	return tls.VerifyClientCertIfGiven
+}
+
+// LoadClientCAs reads the client CA bundle. It returns nil when none is configured.
+func (t TLSConfig) LoadClientCAs() (*x509.CertPool, error) {
+	path := strings.TrimSpace(t.ClientCA)
+	if path == "" {
+		return nil, nil
+	}
+	data, errRead := os.ReadFile(path)
+	if errRead != nil {
+		return nil, fmt.Errorf("read tls.client-ca: %w", errRead)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("tls.client-ca %s contains no PEM certificates", path)
+	}
+	return pool, nil
+}
+
+// ClientCertAuthConfig configures the built-in "client-cert" access provider,
+// which authenticates clients by the certificate verified during the TLS
+// handshake (see tls.client-ca).
+type ClientCertAuthConfig struct {
+	// Enabled toggles client-certificate authentication.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// PrincipalFrom selects the certificate field used as the principal:
+	// "cn" (default), "san-dns", "san-uri" or "san-email".
+	PrincipalFrom string `yaml:"principal-from,omitempty" json:"principal-from,omitempty"`
+
+	// Identities restricts which certificates are accepted and assigns their policy.
+	// When empty, every certificate verified by tls.client-ca is accepted.
+	Identities []ClientCertIdentity `yaml:"identities,omitempty" json:"identities,omitempty"`
+}
+
+// ClientCertIdentity maps matching certificate names to a principal and policy.
+type ClientCertIdentity struct {
+	// Match is compared with the certificate names selected by principal-from.
+	// '*' matches any run of characters.
+	Match string `yaml:"match" json:"match"`
+
+	// Principal overrides the certificate name used for usage attribution.
+	Principal string `yaml:"principal,omitempty" json:"principal,omitempty"`
+
+	// Models lists the models the identity may call. Entries may use '*'.
+	// Empty allows all models.
+	Models []string `yaml:"models,omitempty" json:"models,omitempty"`
+}
+
+// PrincipalFromOrDefault returns the effective principal source.
+func (c ClientCertAuthConfig) PrincipalFromOrDefault() string {
+	switch value := strings.ToLower(strings.TrimSpace(c.PrincipalFrom)); value {
+	case ClientCertPrincipalSANDNS, ClientCertPrincipalSANURI, ClientCertPrincipalSANEmail:
+		return value
+	default:
+		return ClientCertPrincipalCN
+	}
+}
+
+// SanitizeClientCertAuth normalizes TLS client-auth and client-certificate settings.
+func (cfg *Config) SanitizeClientCertAuth() {
+	if cfg == nil {
+		return
+	}
+	cfg.TLS.ClientCA = strings.TrimSpace(cfg.TLS.ClientCA)
+	mode := strings.ToLower(strings.TrimSpace(cfg.TLS.ClientAuth))
+	switch mode {
+	case "", TLSClientAuthOptional, TLSClientAuthRequire:
+	default:
+		log.Warnf("unknown tls.client-auth %q, using %q", cfg.TLS.ClientAuth, TLSClientAuthOptional)
+		mode = TLSClientAuthOptional
+	}
+	cfg.TLS.ClientAuth = mode
+
+	certAuth := &cfg.ClientCertAuth
+	if from := strings.ToLower(strings.TrimSpace(certAuth.PrincipalFrom)); from != "" && from != certAuth.PrincipalFromOrDefault() {
+		log.Warnf("unknown client-cert-auth.principal-from %q, using %q", certAuth.PrincipalFrom, ClientCertPrincipalCN)
+	}
+	certAuth.PrincipalFrom = certAuth.PrincipalFromOrDefault()
+	identities := make([]ClientCertIdentity, 0, len(certAuth.Identities))
+	for _, identity := range certAuth.Identities {
+		identity.Match = strings.TrimSpace(identity.Match)
+		if identity.Match == "" {
+			continue
+		}
+		identity.Principal = strings.TrimSpace(identity.Principal)
+		identity.Models = normalizeStringList(identity.Models)
+		identities = append(identities, identity)
+	}
+	if len(identities) == 0 {
+		identities = nil
+	}
+	certAuth.Identities = identities
+	if certAuth.Enabled && (!cfg.TLS.Enable || cfg.TLS.ClientCA == "") {
+		log.Warn("client-cert-auth is enabled but tls.enable or tls.client-ca is not set; no client certificates will be presented")
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestParseConfigBytesClientCertAuth(t *testing.T) {
+	yaml := `tls:
+  enable: true
+  client-ca: " /etc/cliproxy/clients.pem "
+  client-auth: REQUIRE
+client-cert-auth:
+  enabled: true
+  principal-from: SAN-URI
+  identities:
+    - match: "spiffe://corp/*"
+      models: [" gpt-5* ", ""]
+    - match: "  "
+`
+	cfg, errParse := ParseConfigBytes([]byte(yaml))
+	if errParse != nil {
+		t.Fatalf("ParseConfigBytes() error = %v", errParse)
+	}
+	if cfg.TLS.ClientCA != "/etc/cliproxy/clients.pem" || cfg.TLS.ClientAuth != TLSClientAuthRequire {
+		t.Fatalf("TLS = %+v", cfg.TLS)
+	}
+	if got := cfg.TLS.ClientAuthType(); got != tls.RequireAndVerifyClientCert {
+		t.Fatalf("ClientAuthType() = %v, want RequireAndVerifyClientCert", got)
+	}
+	if cfg.ClientCertAuth.PrincipalFrom != ClientCertPrincipalSANURI {
+		t.Fatalf("PrincipalFrom = %q, want %q", cfg.ClientCertAuth.PrincipalFrom, ClientCertPrincipalSANURI)
+	}
+	if len(cfg.ClientCertAuth.Identities) != 1 || len(cfg.ClientCertAuth.Identities[0].Models) != 1 || cfg.ClientCertAuth.Identities[0].Models[0] != "gpt-5*" {
+		t.Fatalf("Identities = %+v", cfg.ClientCertAuth.Identities)
+	}
+}
+
+func TestTLSClientAuthTypeDefaults(t *testing.T) {
+	if got := (TLSConfig{}).ClientAuthType(); got != tls.NoClientCert {
+		t.Fatalf("ClientAuthType() without CA = %v, want NoClientCert", got)
+	}
+	if got := (TLSConfig{ClientCA: "ca.pem"}).ClientAuthType(); got != tls.VerifyClientCertIfGiven {
+		t.Fatalf("ClientAuthType() default = %v, want VerifyClientCertIfGiven", got)
+	}
+	if pool, errLoad := (TLSConfig{}).LoadClientCAs(); pool != nil || errLoad != nil {
+		t.Fatalf("LoadClientCAs() without CA = %v, %v", pool, errLoad)
+	}
+}
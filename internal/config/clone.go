@@ -41,13 +41,14 @@ func cloneRuntimeValue(v reflect.Value) reflect.Value {
 		}
 		return cloneRuntimeValue(v.Elem())
 	case reflect.Struct:
+		// Start from a shallow copy so unexported fields carry over, then deep
+		// copy the exported ones.
 		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
 		for i := 0; i < v.NumField(); i++ {
-			dst := out.Field(i)
-			if !dst.CanSet() {
-				return v
+			if dst := out.Field(i); dst.CanSet() {
+				dst.Set(cloneRuntimeValue(v.Field(i)))
 			}
-			dst.Set(cloneRuntimeValue(v.Field(i)))
 		}
 		return out
 	case reflect.Slice:
@@ -169,6 +169,19 @@ type Config struct {
 	// NOTE: This applies only to OAuth credentials and does not affect per-credential request-scoped-errors under *-api-key.
 	OAuthRequestScopedErrors map[string][]RequestScopedErrorRule `yaml:"oauth-request-scoped-errors,omitempty" json:"oauth-request-scoped-errors,omitempty"`
 
+	// HealthCheck configures scheduled credential probing.
+	HealthCheck HealthCheckConfig `yaml:"health-check" json:"health-check"`
+
+	// Notifications configures outbound webhook alerts for operational events.
+	Notifications NotificationsConfig `yaml:"notifications" json:"notifications"`
+
+	// Encryption configures encryption at rest for auth records and config secrets.
+	Encryption EncryptionConfig `yaml:"encryption" json:"encryption"`
+
+	// SecretRefs maps secrets resolved from ${env:...}, ${file:...} or ${cmd:...}
+	// references to the reference they came from. It is runtime-only.
+	SecretRefs map[string]string `yaml:"-" json:"-"`
+
 	// Payload defines default and override rules for provider payload parameters.
 	Payload PayloadConfig `yaml:"payload" json:"payload"`
 }
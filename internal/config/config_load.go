@@ -30,6 +30,20 @@ func LoadConfig(configFile string) (*Config, error) {
 // If optional is true and the file is missing, it returns an empty Config.
 // If optional is true and the file is empty or invalid, it returns an empty Config.
 func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
+	return loadConfigFile(configFile, optional, true)
+}
+
+// ValidateConfigFile parses configFile through the same pipeline as LoadConfig
+// without side effects: secret references are left unresolved and nothing is
+// written back. It is used to check config that has not been adopted yet.
+func ValidateConfigFile(configFile string) error {
+	_, err := loadConfigFile(configFile, false, false)
+	return err
+}
+
+// loadConfigFile implements LoadConfigOptional. adopt is false when the config
+// is only being validated.
+func loadConfigFile(configFile string, optional, adopt bool) (*Config, error) {
 	// Read the entire configuration file into memory.
 	data, err := os.ReadFile(configFile)
 	if err != nil {
@@ -88,6 +102,17 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	// Decrypt secrets sealed at rest. The key provider is installed only when
+	// the config is adopted; see ApplyEncryption.
+	if errSecrets := cfg.OpenSecrets(); errSecrets != nil {
+		return nil, errSecrets
+	}
+	if adopt {
+		if errRefs := cfg.ResolveSecretRefs(); errRefs != nil {
+			return nil, errRefs
+		}
+	}
+
 	cfg.CredentialConcurrency = cfg.CredentialConcurrency.WithDefaults()
 	if errValidate := cfg.CredentialInFlight.Validate(); errValidate != nil {
 		return nil, errValidate
@@ -98,19 +123,32 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	if errValidate := cfg.ValidateCredentialWeights(); errValidate != nil {
 		return nil, errValidate
 	}
+	if errValidate := cfg.ValidateCredentialSchedules(); errValidate != nil {
+		return nil, errValidate
+	}
 
 	// Hash remote management key if plaintext is detected (nested)
 	// We consider a value to be already hashed if it looks like a bcrypt hash ($2a$, $2b$, or $2y$ prefix).
-	if cfg.RemoteManagement.SecretKey != "" && !looksLikeBcrypt(cfg.RemoteManagement.SecretKey) {
+	if cfg.RemoteManagement.SecretKey != "" && !looksLikeBcrypt(cfg.RemoteManagement.SecretKey) && !IsSecretRef(cfg.RemoteManagement.SecretKey) {
 		hashed, errHash := hashSecret(cfg.RemoteManagement.SecretKey)
 		if errHash != nil {
 			return nil, fmt.Errorf("failed to hash remote management key: %w", errHash)
 		}
+		_, fromRef := cfg.SecretRefs[cfg.RemoteManagement.SecretKey]
+		cfg.rebindSecretRef(cfg.RemoteManagement.SecretKey, hashed)
 		cfg.RemoteManagement.SecretKey = hashed
 
 		// Persist the hashed value back to the config file to avoid re-hashing on next startup.
-		// Preserve YAML comments and ordering; update only the nested key.
-		_ = SaveConfigPreserveCommentsUpdateNestedScalar(configFile, []string{"remote-management", "secret-key"}, hashed)
+		// Preserve YAML comments and ordering; update only the nested key. Keys read from a
+		// secret reference keep the reference in the file.
+		if adopt && !fromRef {
+			_ = SaveConfigPreserveCommentsUpdateNestedScalar(configFile, []string{"remote-management", "secret-key"}, hashed)
+		}
+	}
+
+	// Normalize named management keys and hash plaintext secrets in memory.
+	if errKeys := cfg.SanitizeManagementKeys(); errKeys != nil {
+		return nil, errKeys
 	}
 
 	cfg.RemoteManagement.PanelGitHubRepository = strings.TrimSpace(cfg.RemoteManagement.PanelGitHubRepository)
@@ -118,6 +156,15 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 		cfg.RemoteManagement.PanelGitHubRepository = DefaultPanelGitHubRepository
 	}
 
+	// Normalize the management audit log backend.
+	cfg.SanitizeAuditLog()
+
+	// Apply credential health-check defaults.
+	cfg.SanitizeHealthCheck()
+
+	// Apply webhook notification defaults and drop unusable targets.
+	cfg.SanitizeNotifications()
+
 	cfg.Pprof.Addr = strings.TrimSpace(cfg.Pprof.Addr)
 	if cfg.Pprof.Addr == "" {
 		cfg.Pprof.Addr = DefaultPprofAddr
@@ -186,6 +233,15 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	// Validate raw payload rules and drop invalid entries.
 	cfg.SanitizePayloadRules()
 
+	// Validate output filter rules and drop invalid patterns.
+	cfg.SanitizeOutputFilter()
+
+	// Normalize JWT client authentication settings.
+	cfg.SanitizeJWTAuth()
+
+	// Normalize TLS client-auth and client-certificate access settings.
+	cfg.SanitizeClientCertAuth()
+
 	// Return the populated configuration struct.
 	return &cfg, nil
 }
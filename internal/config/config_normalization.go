@@ -28,6 +28,7 @@ func (cfg *Config) NormalizePluginsConfig() {
 		cfg.Plugins.StoreSources = sources
 	}
 	cfg.Plugins.StoreAuth = sdkpluginstore.NormalizeAuthConfigs(cfg.Plugins.StoreAuth)
+	cfg.Plugins.StoreTrust = sdkpluginstore.NormalizeTrustConfigs(cfg.Plugins.StoreTrust)
 	if cfg.Plugins.Configs == nil {
 		cfg.Plugins.Configs = map[string]PluginInstanceConfig{}
 	}
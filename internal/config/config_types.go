@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/credentialschedule"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
 	sdkpluginstore "github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginstore"
 	"gopkg.in/yaml.v3"
@@ -30,6 +31,9 @@ type PluginsConfig struct {
 	StoreSources []string `yaml:"store-sources,omitempty" json:"store-sources,omitempty"`
 	// StoreAuth defines optional auth rules for plugin store registry, metadata, and artifact requests.
 	StoreAuth []sdkpluginstore.AuthConfig `yaml:"store-auth,omitempty" json:"store-auth,omitempty"`
+	// StoreTrust lists trusted publisher signing keys per plugin store source.
+	// Store installs without a trusted signature are refused unless allowed here.
+	StoreTrust []sdkpluginstore.TrustConfig `yaml:"store-trust,omitempty" json:"store-trust,omitempty"`
 	// AuthRevision changes when Home-managed plugin credentials change.
 	AuthRevision int64 `yaml:"auth-revision,omitempty" json:"auth-revision,omitempty"`
 	// Configs stores per-plugin instance configuration by plugin ID.
@@ -188,6 +192,12 @@ type TLSConfig struct {
 	Cert string `yaml:"cert" json:"cert"`
 	// Key is the path to the TLS private key file.
 	Key string `yaml:"key" json:"key"`
+	// ClientCA is the path to a PEM bundle of CAs trusted to sign client certificates.
+	// When set, the server asks clients for a certificate.
+	ClientCA string `yaml:"client-ca,omitempty" json:"client-ca,omitempty"`
+	// ClientAuth selects how client certificates are enforced: "optional" (default)
+	// verifies a certificate when one is presented, "require" rejects handshakes without one.
+	ClientAuth string `yaml:"client-auth,omitempty" json:"client-auth,omitempty"`
 }
 
 // PprofConfig holds pprof HTTP server settings.
@@ -204,6 +214,8 @@ type RemoteManagement struct {
 	AllowRemote bool `yaml:"allow-remote"`
 	// SecretKey is the management key (plaintext or bcrypt hashed). YAML key intentionally 'secret-key'.
 	SecretKey string `yaml:"secret-key"`
+	// Keys lists additional named management keys with roles and optional route allow-lists.
+	Keys []ManagementKey `yaml:"keys,omitempty"`
 	// DisableControlPanel skips serving and syncing the bundled management UI when true.
 	DisableControlPanel bool `yaml:"disable-control-panel"`
 	// DisableAutoUpdatePanel disables automatic periodic background updates of the management panel asset from GitHub.
@@ -212,6 +224,8 @@ type RemoteManagement struct {
 	// PanelGitHubRepository overrides the GitHub repository used to fetch the management panel asset.
 	// Accepts either a repository URL (https://github.com/org/repo) or an API releases endpoint.
 	PanelGitHubRepository string `yaml:"panel-github-repository"`
+	// AuditLog configures the append-only record of management mutations.
+	AuditLog AuditLogConfig `yaml:"audit-log,omitempty"`
 }
 
 // QuotaExceeded defines the behavior when API quota limits are exceeded.
@@ -232,9 +246,20 @@ type QuotaExceeded struct {
 // RoutingConfig configures how credentials are selected for requests.
 type RoutingConfig struct {
 	// Strategy selects the credential selection strategy.
-	// Supported values: "round-robin" (default), "weighted-round-robin", "fill-first".
+	// Supported values: "round-robin" (default), "weighted-round-robin", "fill-first",
+	// "quota-aware".
 	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
 
+	// QuotaReservePercent cools a credential down once any upstream rate-limit
+	// window reports this share or less remaining, before the upstream returns 429.
+	// Default: 0 (cool down only when a window is fully spent).
+	QuotaReservePercent int `yaml:"quota-reserve-percent,omitempty" json:"quota-reserve-percent,omitempty"`
+
+	// QuotaExhaustionLead cools a credential down when a window is predicted to run
+	// out within this duration at its current burn rate.
+	// Default: 1m. Set to "0" to disable predictive cooldowns.
+	QuotaExhaustionLead string `yaml:"quota-exhaustion-lead,omitempty" json:"quota-exhaustion-lead,omitempty"`
+
 	// SessionAffinity enables universal session-sticky routing for all clients.
 	// Explicit Claude Code, Codex, OpenCode, and pi session headers are preferred,
 	// followed by prompt_cache_key, Responses conversation IDs, legacy body IDs,
@@ -351,6 +376,10 @@ type ClaudeKey struct {
 	// An omitted value defaults to 1; non-positive values exclude this credential; maximum 1,000,000.
 	Weight *int `yaml:"weight,omitempty" json:"weight,omitempty"`
 
+	// Schedule optionally limits this credential to weekly time windows and
+	// overrides its priority by time of day.
+	Schedule *credentialschedule.Schedule `yaml:"schedule,omitempty" json:"schedule,omitempty"`
+
 	// Prefix optionally namespaces models for this credential (e.g., "teamA/claude-sonnet-4").
 	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
 
@@ -466,6 +495,10 @@ type CodexKey struct {
 	// An omitted value defaults to 1; non-positive values exclude this credential; maximum 1,000,000.
 	Weight *int `yaml:"weight,omitempty" json:"weight,omitempty"`
 
+	// Schedule optionally limits this credential to weekly time windows and
+	// overrides its priority by time of day.
+	Schedule *credentialschedule.Schedule `yaml:"schedule,omitempty" json:"schedule,omitempty"`
+
 	// Prefix optionally namespaces models for this credential (e.g., "teamA/gpt-5-codex").
 	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
 
@@ -570,6 +603,10 @@ type GeminiKey struct {
 	// An omitted value defaults to 1; non-positive values exclude this credential; maximum 1,000,000.
 	Weight *int `yaml:"weight,omitempty" json:"weight,omitempty"`
 
+	// Schedule optionally limits this credential to weekly time windows and
+	// overrides its priority by time of day.
+	Schedule *credentialschedule.Schedule `yaml:"schedule,omitempty" json:"schedule,omitempty"`
+
 	// Prefix optionally namespaces models for this credential (e.g., "teamA/gemini-3-pro-preview").
 	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
 
@@ -696,6 +733,10 @@ type OpenAICompatibilityAPIKey struct {
 	// An omitted value defaults to 1; non-positive values exclude this credential; maximum 1,000,000.
 	Weight *int `yaml:"weight,omitempty" json:"weight,omitempty"`
 
+	// Schedule optionally limits this credential to weekly time windows and
+	// overrides its priority by time of day.
+	Schedule *credentialschedule.Schedule `yaml:"schedule,omitempty" json:"schedule,omitempty"`
+
 	// ProxyURL overrides the global proxy setting for this API key if provided.
 	ProxyURL string `yaml:"proxy-url,omitempty" json:"proxy-url,omitempty"`
 }
@@ -45,6 +45,12 @@ func SaveConfigPreserveComments(configFile string, cfg *Config) error {
 	if generated.Content[0].Kind != yaml.MappingNode {
 		return fmt.Errorf("expected generated root mapping node")
 	}
+	// Write secret references back instead of the values they resolved to.
+	cfg.restoreSecretRefNodes(generated.Content[0])
+	// Keep secrets sealed at rest when encryption is enabled.
+	if err = sealSecretNodes(generated.Content[0]); err != nil {
+		return err
+	}
 
 	// Remove deprecated sections before merging back the sanitized config.
 	removeLegacyAuthBlock(original.Content[0])
@@ -0,0 +1,270 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/envelope"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultMasterKeyEnv is the environment variable read for the master key when
+// neither a KMS nor a key file is configured.
+const DefaultMasterKeyEnv = "CLIPROXY_MASTER_KEY"
+
+// EncryptionConfig configures encryption at rest under 'encryption'.
+type EncryptionConfig struct {
+	// Enabled seals auth records and config secrets with envelope encryption.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// KeyEnv names the environment variable holding the master key.
+	// Defaults to CLIPROXY_MASTER_KEY.
+	KeyEnv string `yaml:"key-env,omitempty" json:"key-env,omitempty"`
+	// KeyFile is a file holding the master key as base64 or hex. It takes
+	// precedence over KeyEnv.
+	KeyFile string `yaml:"key-file,omitempty" json:"key-file,omitempty"`
+	// PreviousKeyFiles hold retired master keys that are still accepted for
+	// reading data sealed before a rotation.
+	PreviousKeyFiles []string `yaml:"previous-key-files,omitempty" json:"previous-key-files,omitempty"`
+	// KMS names a registered key management service. It takes precedence over
+	// KeyFile and KeyEnv. "local" reads a keyring file named by kms-options.keyring.
+	KMS string `yaml:"kms,omitempty" json:"kms,omitempty"`
+	// KMSOptions are passed to the KMS factory.
+	KMSOptions map[string]string `yaml:"kms-options,omitempty" json:"kms-options,omitempty"`
+}
+
+// KeyProvider builds the key provider described by the config. It returns nil
+// when encryption is disabled.
+func (c EncryptionConfig) KeyProvider() (envelope.KeyProvider, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+	if kms := strings.TrimSpace(c.KMS); kms != "" {
+		return envelope.NewKMS(kms, c.KMSOptions)
+	}
+	var primary []byte
+	if keyFile := strings.TrimSpace(c.KeyFile); keyFile != "" {
+		key, errKey := envelope.LoadKeyFile(keyFile)
+		if errKey != nil {
+			return nil, errKey
+		}
+		primary = key
+	} else {
+		keyEnv := strings.TrimSpace(c.KeyEnv)
+		if keyEnv == "" {
+			keyEnv = DefaultMasterKeyEnv
+		}
+		raw, ok := os.LookupEnv(keyEnv)
+		if !ok || strings.TrimSpace(raw) == "" {
+			return nil, fmt.Errorf("encryption is enabled but no master key is configured (set %s, encryption.key-file or encryption.kms)", keyEnv)
+		}
+		key, errKey := envelope.ParseKey(raw)
+		if errKey != nil {
+			return nil, fmt.Errorf("%s: %w", keyEnv, errKey)
+		}
+		primary = key
+	}
+	previous := make([][]byte, 0, len(c.PreviousKeyFiles))
+	for _, path := range c.PreviousKeyFiles {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		key, errKey := envelope.LoadKeyFile(path)
+		if errKey != nil {
+			return nil, errKey
+		}
+		previous = append(previous, key)
+	}
+	return envelope.NewLocalKeyProvider(primary, previous...)
+}
+
+// ApplyEncryption installs the configured key provider process-wide. It is
+// called when a config is adopted as the running config, never while a config
+// is only parsed or validated.
+func (cfg *Config) ApplyEncryption() error {
+	provider, errProvider := cfg.Encryption.KeyProvider()
+	if errProvider != nil {
+		return fmt.Errorf("failed to load encryption key: %w", errProvider)
+	}
+	envelope.Configure(provider)
+	return nil
+}
+
+// OpenSecrets replaces sealed "enc:v1:" secret values with their plaintext,
+// using the key provider the config itself describes without installing it.
+// When the config does not enable encryption the installed provider is used,
+// so secrets can still be read while encryption is being turned off.
+func (cfg *Config) OpenSecrets() error {
+	provider, errProvider := cfg.Encryption.KeyProvider()
+	if errProvider != nil {
+		return fmt.Errorf("failed to load encryption key: %w", errProvider)
+	}
+	if provider == nil {
+		provider = envelope.Current()
+	}
+	var errOpen error
+	cfg.visitSecrets(func(field string, value *string) {
+		if errOpen != nil || !envelope.IsSealedString(*value) {
+			return
+		}
+		plaintext, errValue := envelope.OpenStringWith(provider, secretRecordName(field), *value)
+		if errValue != nil {
+			errOpen = fmt.Errorf("failed to decrypt %s: %w", field, errValue)
+			return
+		}
+		*value = plaintext
+	})
+	return errOpen
+}
+
+// visitSecrets calls fn for every config field that is sealed at rest.
+func (cfg *Config) visitSecrets(fn func(field string, value *string)) {
+	for i := range cfg.APIKeys {
+		fn("api-keys", &cfg.APIKeys[i])
+	}
+	for i := range cfg.GeminiKey {
+		fn("gemini-api-key", &cfg.GeminiKey[i].APIKey)
+	}
+	for i := range cfg.InteractionsKey {
+		fn("interactions-api-key", &cfg.InteractionsKey[i].APIKey)
+	}
+	for i := range cfg.CodexKey {
+		fn("codex-api-key", &cfg.CodexKey[i].APIKey)
+	}
+	for i := range cfg.XAIKey {
+		fn("xai-api-key", &cfg.XAIKey[i].APIKey)
+	}
+	for i := range cfg.ClaudeKey {
+		fn("claude-api-key", &cfg.ClaudeKey[i].APIKey)
+	}
+	for i := range cfg.VertexCompatAPIKey {
+		fn("vertex-api-key", &cfg.VertexCompatAPIKey[i].APIKey)
+	}
+	for i := range cfg.OpenAICompatibility {
+		for j := range cfg.OpenAICompatibility[i].APIKeyEntries {
+			fn("openai-compatibility.api-key-entries", &cfg.OpenAICompatibility[i].APIKeyEntries[j].APIKey)
+		}
+	}
+	for i := range cfg.Notifications.Targets {
+		target := &cfg.Notifications.Targets[i]
+		fn("notifications.targets.secret", &target.Secret)
+		for name, value := range target.Headers {
+			fn("notifications.targets.headers", &value)
+			target.Headers[name] = value
+		}
+	}
+	for i := range cfg.Notifications.ClientKeyBudgets {
+		fn("notifications.client-key-budgets", &cfg.Notifications.ClientKeyBudgets[i].APIKey)
+	}
+}
+
+// secretRecordName is the envelope record name config secrets under field are
+// bound to.
+func secretRecordName(field string) string {
+	return "config/" + field
+}
+
+// secretKeyLists lists the config sections, as dotted paths from the root,
+// whose entries carry an "api-key" sealed at rest.
+var secretKeyLists = []string{
+	"gemini-api-key",
+	"interactions-api-key",
+	"codex-api-key",
+	"xai-api-key",
+	"claude-api-key",
+	"vertex-api-key",
+	"notifications.client-key-budgets",
+}
+
+// sealSecretNodes seals the secret scalars of a generated YAML document root
+// in place. It is a no-op while encryption is disabled. Secret references are
+// left readable.
+func sealSecretNodes(root *yaml.Node) error {
+	if !envelope.Enabled() || root == nil || root.Kind != yaml.MappingNode {
+		return nil
+	}
+	return visitSecretNodes(root, func(field string, node *yaml.Node) error {
+		if node == nil || node.Kind != yaml.ScalarNode || node.Value == "" || IsSecretRef(node.Value) {
+			return nil
+		}
+		sealed, errSeal := envelope.SealString(secretRecordName(field), node.Value)
+		if errSeal != nil {
+			return errSeal
+		}
+		node.Value = sealed
+		node.Style = 0
+		node.Tag = "!!str"
+		return nil
+	})
+}
+
+// visitSecretNodes calls fn for the value node of every secret scalar that
+// visitSecrets covers in a generated YAML document root, with the same field
+// names. Missing nodes are passed as nil.
+func visitSecretNodes(root *yaml.Node, fn func(field string, node *yaml.Node) error) error {
+	visitEntries := func(field string, list *yaml.Node) error {
+		if list == nil || list.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for _, entry := range list.Content {
+			if errVisit := fn(field, mappingValue(entry, "api-key")); errVisit != nil {
+				return errVisit
+			}
+		}
+		return nil
+	}
+
+	if keys := mappingValue(root, "api-keys"); keys != nil && keys.Kind == yaml.SequenceNode {
+		for _, key := range keys.Content {
+			if errVisit := fn("api-keys", key); errVisit != nil {
+				return errVisit
+			}
+		}
+	}
+	for _, section := range secretKeyLists {
+		if errVisit := visitEntries(section, mappingPathValue(root, section)); errVisit != nil {
+			return errVisit
+		}
+	}
+	if providers := mappingValue(root, "openai-compatibility"); providers != nil && providers.Kind == yaml.SequenceNode {
+		for _, provider := range providers.Content {
+			if errVisit := visitEntries("openai-compatibility.api-key-entries", mappingValue(provider, "api-key-entries")); errVisit != nil {
+				return errVisit
+			}
+		}
+	}
+	if targets := mappingPathValue(root, "notifications.targets"); targets != nil && targets.Kind == yaml.SequenceNode {
+		for _, target := range targets.Content {
+			if errVisit := fn("notifications.targets.secret", mappingValue(target, "secret")); errVisit != nil {
+				return errVisit
+			}
+			headers := mappingValue(target, "headers")
+			if headers == nil || headers.Kind != yaml.MappingNode {
+				continue
+			}
+			for i := 1; i < len(headers.Content); i += 2 {
+				if errVisit := fn("notifications.targets.headers", headers.Content[i]); errVisit != nil {
+					return errVisit
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// mappingPathValue returns the value node stored under a dotted key path.
+func mappingPathValue(node *yaml.Node, path string) *yaml.Node {
+	for _, key := range strings.Split(path, ".") {
+		node = mappingValue(node, key)
+	}
+	return node
+}
+
+// mappingValue returns the value node stored under key in a mapping node.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	idx := findMapKeyIndex(node, key)
+	if idx < 0 {
+		return nil
+	}
+	return node.Content[idx+1]
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/envelope"
+)
+
+func TestEncryptedConfigSecretsRoundTrip(t *testing.T) {
+	t.Setenv(DefaultMasterKeyEnv, base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+	envelope.Configure(nil)
+	t.Cleanup(func() { envelope.Configure(nil) })
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if errWrite := os.WriteFile(configPath, []byte(`# keep this comment
+encryption:
+  enabled: true
+api-keys:
+  - "client-key"
+claude-api-key:
+  - api-key: "sk-ant-secret"
+openai-compatibility:
+  - name: "compat"
+    base-url: "https://compat.example.com/v1"
+    api-key-entries:
+      - api-key: "sk-compat-secret"
+`), 0o600); errWrite != nil {
+		t.Fatalf("WriteFile() error = %v", errWrite)
+	}
+
+	// Validating a config must not install its key.
+	if errValidate := ValidateConfigFile(configPath); errValidate != nil || envelope.Enabled() {
+		t.Fatalf("ValidateConfigFile() error = %v, enabled = %v", errValidate, envelope.Enabled())
+	}
+	cfg, errLoad := LoadConfig(configPath)
+	if errLoad != nil {
+		t.Fatalf("LoadConfig() error = %v", errLoad)
+	}
+	if envelope.Enabled() {
+		t.Fatal("LoadConfig() installed the key before the config was adopted")
+	}
+	if errApply := cfg.ApplyEncryption(); errApply != nil {
+		t.Fatalf("ApplyEncryption() error = %v", errApply)
+	}
+	if errSave := SaveConfigPreserveComments(configPath, cfg); errSave != nil {
+		t.Fatalf("SaveConfigPreserveComments() error = %v", errSave)
+	}
+	saved, _ := os.ReadFile(configPath)
+	for _, secret := range []string{"client-key", "sk-ant-secret", "sk-compat-secret"} {
+		if strings.Contains(string(saved), secret) {
+			t.Fatalf("saved config leaks %q:\n%s", secret, saved)
+		}
+	}
+	if !strings.Contains(string(saved), "# keep this comment") || !strings.Contains(string(saved), envelope.StringPrefix) {
+		t.Fatalf("saved config:\n%s", saved)
+	}
+
+	reloaded, errReload := LoadConfig(configPath)
+	if errReload != nil {
+		t.Fatalf("LoadConfig() after save error = %v", errReload)
+	}
+	if reloaded.APIKeys[0] != "client-key" || reloaded.ClaudeKey[0].APIKey != "sk-ant-secret" ||
+		reloaded.OpenAICompatibility[0].APIKeyEntries[0].APIKey != "sk-compat-secret" {
+		t.Fatalf("secrets not decrypted: %+v", reloaded.APIKeys)
+	}
+
+	// Saving unchanged secrets again keeps the file byte-identical.
+	if errSave := SaveConfigPreserveComments(configPath, reloaded); errSave != nil {
+		t.Fatalf("second save error = %v", errSave)
+	}
+	if again, _ := os.ReadFile(configPath); string(again) != string(saved) {
+		t.Fatalf("second save changed the file:\n%s\n---\n%s", saved, again)
+	}
+}
+
+func TestEncryptedNotificationSecretsRoundTrip(t *testing.T) {
+	t.Setenv(DefaultMasterKeyEnv, base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+	envelope.Configure(nil)
+	t.Cleanup(func() { envelope.Configure(nil) })
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if errWrite := os.WriteFile(configPath, []byte(`encryption:
+  enabled: true
+api-keys:
+  - "sk-client-secret"
+notifications:
+  targets:
+    - name: "ops"
+      url: "https://hooks.example.com/alert"
+      secret: "hmac-secret"
+      headers:
+        Authorization: "Bearer hook-token"
+  client-key-budgets:
+    - api-key: "sk-client-secret"
+      max-requests: 10
+`), 0o600); errWrite != nil {
+		t.Fatalf("WriteFile() error = %v", errWrite)
+	}
+
+	cfg, errLoad := LoadConfig(configPath)
+	if errLoad != nil {
+		t.Fatalf("LoadConfig() error = %v", errLoad)
+	}
+	if errApply := cfg.ApplyEncryption(); errApply != nil {
+		t.Fatalf("ApplyEncryption() error = %v", errApply)
+	}
+	if errSave := SaveConfigPreserveComments(configPath, cfg); errSave != nil {
+		t.Fatalf("SaveConfigPreserveComments() error = %v", errSave)
+	}
+	saved, _ := os.ReadFile(configPath)
+	for _, secret := range []string{"sk-client-secret", "hmac-secret", "hook-token"} {
+		if strings.Contains(string(saved), secret) {
+			t.Fatalf("saved config leaks %q:\n%s", secret, saved)
+		}
+	}
+
+	reloaded, errReload := LoadConfig(configPath)
+	if errReload != nil {
+		t.Fatalf("LoadConfig() after save error = %v", errReload)
+	}
+	target := reloaded.Notifications.Targets[0]
+	if target.Secret != "hmac-secret" || target.Headers["Authorization"] != "Bearer hook-token" {
+		t.Fatalf("target secrets not decrypted: secret %q, headers %v", target.Secret, target.Headers)
+	}
+	if budget := reloaded.Notifications.ClientKeyBudgets[0]; budget.APIKey != "sk-client-secret" {
+		t.Fatalf("budget api-key = %q, want the decrypted client key", budget.APIKey)
+	}
+}
+
+func TestEncryptionRequiresMasterKey(t *testing.T) {
+	t.Setenv(DefaultMasterKeyEnv, "")
+	t.Cleanup(func() { envelope.Configure(nil) })
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if errWrite := os.WriteFile(configPath, []byte("encryption:\n  enabled: true\n"), 0o600); errWrite != nil {
+		t.Fatalf("WriteFile() error = %v", errWrite)
+	}
+	if _, errLoad := LoadConfig(configPath); errLoad == nil || !strings.Contains(errLoad.Error(), DefaultMasterKeyEnv) {
+		t.Fatalf("LoadConfig() error = %v, want missing key error", errLoad)
+	}
+}
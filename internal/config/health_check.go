@@ -0,0 +1,61 @@
+package config
+
+import "strings"
+
+const (
+	// DefaultHealthCheckIntervalSeconds is the time between probes of one credential.
+	DefaultHealthCheckIntervalSeconds = 1800
+	// DefaultHealthCheckTimeoutSeconds bounds a single probe.
+	DefaultHealthCheckTimeoutSeconds = 60
+	// DefaultHealthCheckConcurrency is the number of probes run at once.
+	DefaultHealthCheckConcurrency = 2
+)
+
+// HealthCheckConfig configures the scheduled credential prober under 'health-check'.
+type HealthCheckConfig struct {
+	// Enabled turns on scheduled probing. On-demand checks work either way.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// IntervalSeconds is the time between probes of one credential.
+	IntervalSeconds int `yaml:"interval-seconds,omitempty" json:"interval-seconds,omitempty"`
+	// TimeoutSeconds bounds a single probe.
+	TimeoutSeconds int `yaml:"timeout-seconds,omitempty" json:"timeout-seconds,omitempty"`
+	// Concurrency is the number of probes run at once.
+	Concurrency int `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+	// Models lists the models to probe per provider. Providers without an entry
+	// probe the first model registered for the credential.
+	Models map[string][]string `yaml:"models,omitempty" json:"models,omitempty"`
+}
+
+// SanitizeHealthCheck applies defaults and normalizes the per-provider model lists.
+func (cfg *Config) SanitizeHealthCheck() {
+	if cfg == nil {
+		return
+	}
+	hc := &cfg.HealthCheck
+	if hc.IntervalSeconds <= 0 {
+		hc.IntervalSeconds = DefaultHealthCheckIntervalSeconds
+	}
+	if hc.TimeoutSeconds <= 0 {
+		hc.TimeoutSeconds = DefaultHealthCheckTimeoutSeconds
+	}
+	if hc.Concurrency <= 0 {
+		hc.Concurrency = DefaultHealthCheckConcurrency
+	}
+	if len(hc.Models) == 0 {
+		hc.Models = nil
+		return
+	}
+	models := make(map[string][]string, len(hc.Models))
+	for provider, list := range hc.Models {
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		if provider == "" {
+			continue
+		}
+		for _, model := range list {
+			if model = strings.TrimSpace(model); model != "" {
+				models[provider] = append(models[provider], model)
+			}
+		}
+	}
+	hc.Models = models
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultJWTPrincipalClaim names the claim used as the client principal.
+	DefaultJWTPrincipalClaim = "sub"
+	// DefaultJWTKeyRefreshInterval is how long a fetched JWKS is trusted before it is reloaded.
+	DefaultJWTKeyRefreshInterval = time.Hour
+)
+
+// JWTAuthConfig configures the built-in "jwt" access provider, which accepts
+// RS256/ES256 bearer tokens issued by an external identity provider.
+type JWTAuthConfig struct {
+	// Enabled toggles JWT client authentication.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// JWKSURL is fetched to obtain the signing keys. Takes precedence over JWKSFile.
+	JWKSURL string `yaml:"jwks-url,omitempty" json:"jwks-url,omitempty"`
+
+	// JWKSFile reads the signing keys from a local JWKS document instead of a URL.
+	JWKSFile string `yaml:"jwks-file,omitempty" json:"jwks-file,omitempty"`
+
+	// Issuer, when set, must equal the token's "iss" claim.
+	Issuer string `yaml:"issuer,omitempty" json:"issuer,omitempty"`
+
+	// Audience, when set, requires the token's "aud" claim to contain at least one entry.
+	Audience []string `yaml:"audience,omitempty" json:"audience,omitempty"`
+
+	// PrincipalClaim names the claim used as the client identity for usage
+	// attribution. Empty uses "sub".
+	PrincipalClaim string `yaml:"principal-claim,omitempty" json:"principal-claim,omitempty"`
+
+	// ModelsClaim names a claim listing the models the client may call. Entries
+	// may use '*' wildcards. When set, tokens without the claim or with an empty
+	// list are rejected. When empty, all models are allowed.
+	ModelsClaim string `yaml:"models-claim,omitempty" json:"models-claim,omitempty"`
+
+	// MetadataClaims lists additional claims copied into the access metadata.
+	// The reserved names allowed-models, source, subject and issuer are ignored.
+	MetadataClaims []string `yaml:"metadata-claims,omitempty" json:"metadata-claims,omitempty"`
+
+	// RefreshInterval controls how often signing keys are reloaded, e.g. "1h".
+	// Unknown key IDs also trigger a rate-limited reload. Empty or invalid uses 1h.
+	RefreshInterval string `yaml:"refresh-interval,omitempty" json:"refresh-interval,omitempty"`
+}
+
+// PrincipalClaimOrDefault returns the effective principal claim name.
+func (c JWTAuthConfig) PrincipalClaimOrDefault() string {
+	if claim := strings.TrimSpace(c.PrincipalClaim); claim != "" {
+		return claim
+	}
+	return DefaultJWTPrincipalClaim
+}
+
+// RefreshIntervalOrDefault parses RefreshInterval, falling back to the default.
+func (c JWTAuthConfig) RefreshIntervalOrDefault() time.Duration {
+	raw := strings.TrimSpace(c.RefreshInterval)
+	if raw == "" {
+		return DefaultJWTKeyRefreshInterval
+	}
+	interval, errParse := time.ParseDuration(raw)
+	if errParse != nil || interval <= 0 {
+		return DefaultJWTKeyRefreshInterval
+	}
+	return interval
+}
+
+// SanitizeJWTAuth trims JWT settings and disables the provider when no key source is configured.
+func (cfg *Config) SanitizeJWTAuth() {
+	if cfg == nil {
+		return
+	}
+	jwt := &cfg.JWTAuth
+	jwt.JWKSURL = strings.TrimSpace(jwt.JWKSURL)
+	jwt.JWKSFile = strings.TrimSpace(jwt.JWKSFile)
+	jwt.Issuer = strings.TrimSpace(jwt.Issuer)
+	jwt.PrincipalClaim = strings.TrimSpace(jwt.PrincipalClaim)
+	jwt.ModelsClaim = strings.TrimSpace(jwt.ModelsClaim)
+	jwt.Audience = normalizeStringList(jwt.Audience)
+	jwt.MetadataClaims = normalizeStringList(jwt.MetadataClaims)
+	if jwt.Enabled && jwt.JWKSURL == "" && jwt.JWKSFile == "" {
+		log.Warn("jwt-auth disabled: neither jwks-url nor jwks-file is set")
+		jwt.Enabled = false
+	}
+}
+
+func normalizeStringList(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(values))
+	seen := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		trimmed := strings.TrimSpace(value)
+		if trimmed == "" {
+			continue
+		}
+		if _, exists := seen[trimmed]; exists {
+			continue
+		}
+		seen[trimmed] = struct{}{}
+		out = append(out, trimmed)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
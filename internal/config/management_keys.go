@@ -0,0 +1,111 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// ManagementRoleViewer may read usage, logs and auth-file listings.
+	ManagementRoleViewer = "viewer"
+	// ManagementRoleOperator may additionally toggle auth files and reset quota.
+	ManagementRoleOperator = "operator"
+	// ManagementRoleAdmin has full management access.
+	ManagementRoleAdmin = "admin"
+)
+
+// ManagementKey is a named management API key with a role.
+type ManagementKey struct {
+	// Name identifies the key in logs and audit records.
+	Name string `yaml:"name"`
+	// Key is the secret (plaintext or bcrypt hashed). Plaintext values are hashed in memory on load.
+	Key string `yaml:"key"`
+	// Role is one of viewer, operator or admin. Empty or unknown values fall back to viewer.
+	Role string `yaml:"role"`
+	// Routes optionally narrows the role to matching routes. Each entry is either a
+	// path pattern or "METHOD path-pattern"; '*' matches any run of characters.
+	Routes []string `yaml:"routes,omitempty"`
+
+	// sourceDigest is a SHA-256 digest of the key as written in the config. It
+	// is kept because plaintext keys get a fresh bcrypt salt on every load, and
+	// stays valid while Key still holds hashedKey.
+	sourceDigest string
+	hashedKey    string
+}
+
+// KeyDigest returns a SHA-256 digest identifying the key material without
+// exposing it. Keys hashed on load report the digest of the configured value,
+// so reloading an unchanged file yields the same digest.
+func (k ManagementKey) KeyDigest() string {
+	if k.sourceDigest != "" && k.hashedKey == k.Key {
+		return k.sourceDigest
+	}
+	return secretDigest(k.Key)
+}
+
+func secretDigest(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeManagementRole returns a known role name, defaulting to viewer.
+func NormalizeManagementRole(role string) string {
+	switch value := strings.ToLower(strings.TrimSpace(role)); value {
+	case ManagementRoleOperator, ManagementRoleAdmin:
+		return value
+	default:
+		return ManagementRoleViewer
+	}
+}
+
+// HasManagementKeys reports whether any management key is configured in the file.
+func (r RemoteManagement) HasManagementKeys() bool {
+	return r.SecretKey != "" || len(r.Keys) > 0
+}
+
+// SanitizeManagementKeys trims named keys, drops incomplete or duplicate entries
+// and hashes plaintext secrets.
+func (cfg *Config) SanitizeManagementKeys() error {
+	if cfg == nil || len(cfg.RemoteManagement.Keys) == 0 {
+		return nil
+	}
+	keys := make([]ManagementKey, 0, len(cfg.RemoteManagement.Keys))
+	seen := make(map[string]struct{}, len(cfg.RemoteManagement.Keys))
+	for i, entry := range cfg.RemoteManagement.Keys {
+		entry.Name = strings.TrimSpace(entry.Name)
+		entry.Key = strings.TrimSpace(entry.Key)
+		if entry.Name == "" || entry.Key == "" {
+			log.Warnf("remote-management.keys[%d] dropped: name and key are required", i)
+			continue
+		}
+		if _, exists := seen[entry.Name]; exists {
+			log.Warnf("remote-management.keys[%d] dropped: duplicate name %q", i, entry.Name)
+			continue
+		}
+		seen[entry.Name] = struct{}{}
+		if raw := strings.ToLower(strings.TrimSpace(entry.Role)); raw != "" && raw != NormalizeManagementRole(raw) {
+			log.Warnf("remote-management key %q has unknown role %q, using %q", entry.Name, entry.Role, ManagementRoleViewer)
+		}
+		entry.Role = NormalizeManagementRole(entry.Role)
+		entry.Routes = normalizeStringList(entry.Routes)
+		if !looksLikeBcrypt(entry.Key) && !IsSecretRef(entry.Key) {
+			hashed, errHash := hashSecret(entry.Key)
+			if errHash != nil {
+				return fmt.Errorf("failed to hash management key %q: %w", entry.Name, errHash)
+			}
+			cfg.rebindSecretRef(entry.Key, hashed)
+			entry.sourceDigest, entry.hashedKey = secretDigest(entry.Key), hashed
+			entry.Key = hashed
+		}
+		keys = append(keys, entry)
+	}
+	if len(keys) == 0 {
+		keys = nil
+	}
+	cfg.RemoteManagement.Keys = keys
+	return nil
+}
@@ -0,0 +1,36 @@
+package config
+
+import "testing"
+
+func TestParseConfigBytesManagementKeys(t *testing.T) {
+	yaml := `remote-management:
+  keys:
+    - name: " dashboard "
+      key: "viewer-secret"
+    - name: "oncall"
+      key: "operator-secret"
+      role: OPERATOR
+      routes: [" POST /v0/management/reset-quota ", ""]
+    - name: "oncall"
+      key: "duplicate"
+      role: admin
+    - name: "missing-key"
+`
+	cfg, errParse := ParseConfigBytes([]byte(yaml))
+	if errParse != nil {
+		t.Fatalf("ParseConfigBytes() error = %v", errParse)
+	}
+	keys := cfg.RemoteManagement.Keys
+	if len(keys) != 2 {
+		t.Fatalf("Keys = %+v, want 2 entries", keys)
+	}
+	if keys[0].Name != "dashboard" || keys[0].Role != ManagementRoleViewer || !looksLikeBcrypt(keys[0].Key) {
+		t.Fatalf("Keys[0] = %+v", keys[0])
+	}
+	if keys[1].Role != ManagementRoleOperator || len(keys[1].Routes) != 1 || keys[1].Routes[0] != "POST /v0/management/reset-quota" {
+		t.Fatalf("Keys[1] = %+v", keys[1])
+	}
+	if !cfg.RemoteManagement.HasManagementKeys() {
+		t.Fatalf("HasManagementKeys() = false, want true")
+	}
+}
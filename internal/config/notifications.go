@@ -0,0 +1,157 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultNotificationMaxAttempts bounds delivery attempts per notification and target.
+	DefaultNotificationMaxAttempts = 8
+	// DefaultNotificationDedupWindowSeconds suppresses repeats of one alert within the window.
+	DefaultNotificationDedupWindowSeconds = 300
+	// DefaultNotificationRateLimitPerMinute caps the alerts queued per target per minute.
+	DefaultNotificationRateLimitPerMinute = 30
+	// DefaultNotificationTimeoutSeconds bounds a single webhook request.
+	DefaultNotificationTimeoutSeconds = 10
+	// DefaultClientKeyBudgetWindow is the accounting window of a client-key budget.
+	DefaultClientKeyBudgetWindow = 24 * time.Hour
+)
+
+// NotificationsConfig configures outbound webhook alerts under 'notifications'.
+type NotificationsConfig struct {
+	// Enabled turns on webhook delivery.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// QueueDir holds pending deliveries so they survive restarts.
+	// Defaults to "notifications" in the logs directory.
+	QueueDir string `yaml:"queue-dir,omitempty" json:"queue-dir,omitempty"`
+	// MaxAttempts bounds delivery attempts per notification and target.
+	MaxAttempts int `yaml:"max-attempts,omitempty" json:"max-attempts,omitempty"`
+	// DedupWindowSeconds suppresses repeats of the same alert within the window.
+	// A negative value disables deduplication.
+	DedupWindowSeconds int `yaml:"dedup-window-seconds,omitempty" json:"dedup-window-seconds,omitempty"`
+	// RateLimitPerMinute caps the alerts queued per target per minute.
+	// A negative value disables the limit.
+	RateLimitPerMinute int `yaml:"rate-limit-per-minute,omitempty" json:"rate-limit-per-minute,omitempty"`
+	// Targets lists the webhook endpoints.
+	Targets []NotificationTarget `yaml:"targets,omitempty" json:"targets,omitempty"`
+	// ClientKeyBudgets raises client.budget.exceeded when a client API key
+	// crosses its token or request budget.
+	ClientKeyBudgets []ClientKeyBudget `yaml:"client-key-budgets,omitempty" json:"client-key-budgets,omitempty"`
+}
+
+// NotificationTarget is one webhook endpoint.
+type NotificationTarget struct {
+	// Name identifies the target in logs and queue entries. Defaults to the URL host.
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// URL receives a POST per notification.
+	URL string `yaml:"url" json:"url"`
+	// Headers are added to every request, e.g. an Authorization header.
+	Headers map[string]string `yaml:"headers,omitempty" json:"-"`
+	// Template is a Go text/template rendering the request body from the
+	// notification. Empty sends the notification as JSON.
+	Template string `yaml:"template,omitempty" json:"template,omitempty"`
+	// ContentType overrides the Content-Type header. Defaults to application/json.
+	ContentType string `yaml:"content-type,omitempty" json:"content-type,omitempty"`
+	// Secret signs the body with HMAC-SHA256; the signature is sent in
+	// X-CLIProxy-Signature as "sha256=<hex>".
+	Secret string `yaml:"secret,omitempty" json:"-"`
+	// Events filters the notification types sent to the target. Entries match
+	// their exact type and every type below it. Empty sends everything.
+	Events []string `yaml:"events,omitempty" json:"events,omitempty"`
+	// TimeoutSeconds bounds a single request.
+	TimeoutSeconds int `yaml:"timeout-seconds,omitempty" json:"timeout-seconds,omitempty"`
+}
+
+// ClientKeyBudget is a usage threshold for one client API key.
+type ClientKeyBudget struct {
+	// APIKey is the client key the budget applies to.
+	APIKey string `yaml:"api-key" json:"-"`
+	// Name labels the key in alerts instead of the key itself.
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// MaxTokens is the total token budget per window. Zero disables the check.
+	MaxTokens int64 `yaml:"max-tokens,omitempty" json:"max-tokens,omitempty"`
+	// MaxRequests is the request budget per window. Zero disables the check.
+	MaxRequests int64 `yaml:"max-requests,omitempty" json:"max-requests,omitempty"`
+	// Window is the accounting window, e.g. "1h" or "24h". Defaults to 24h.
+	Window string `yaml:"window,omitempty" json:"window,omitempty"`
+}
+
+// WindowOrDefault parses Window, falling back to the default.
+func (b ClientKeyBudget) WindowOrDefault() time.Duration {
+	raw := strings.TrimSpace(b.Window)
+	if raw == "" {
+		return DefaultClientKeyBudgetWindow
+	}
+	window, errParse := time.ParseDuration(raw)
+	if errParse != nil || window <= 0 {
+		return DefaultClientKeyBudgetWindow
+	}
+	return window
+}
+
+// SanitizeNotifications applies defaults and drops targets and budgets that
+// cannot be used.
+func (cfg *Config) SanitizeNotifications() {
+	if cfg == nil {
+		return
+	}
+	n := &cfg.Notifications
+	n.QueueDir = strings.TrimSpace(n.QueueDir)
+	if n.MaxAttempts <= 0 {
+		n.MaxAttempts = DefaultNotificationMaxAttempts
+	}
+	if n.DedupWindowSeconds == 0 {
+		n.DedupWindowSeconds = DefaultNotificationDedupWindowSeconds
+	}
+	if n.RateLimitPerMinute == 0 {
+		n.RateLimitPerMinute = DefaultNotificationRateLimitPerMinute
+	}
+
+	targets := make([]NotificationTarget, 0, len(n.Targets))
+	seen := make(map[string]struct{}, len(n.Targets))
+	for _, target := range n.Targets {
+		target.URL = strings.TrimSpace(target.URL)
+		parsed, errParse := url.Parse(target.URL)
+		if errParse != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			log.Warnf("notifications: dropping target %q with invalid url", target.Name)
+			continue
+		}
+		target.Name = strings.TrimSpace(target.Name)
+		if target.Name == "" {
+			target.Name = parsed.Host
+		}
+		if _, dup := seen[target.Name]; dup {
+			log.Warnf("notifications: dropping duplicate target %q", target.Name)
+			continue
+		}
+		seen[target.Name] = struct{}{}
+		target.ContentType = strings.TrimSpace(target.ContentType)
+		if target.TimeoutSeconds <= 0 {
+			target.TimeoutSeconds = DefaultNotificationTimeoutSeconds
+		}
+		events := target.Events[:0]
+		for _, event := range target.Events {
+			if event = strings.TrimSuffix(strings.TrimSpace(event), ".*"); event != "" && event != "*" {
+				events = append(events, event)
+			}
+		}
+		target.Events = events
+		targets = append(targets, target)
+	}
+	n.Targets = targets
+
+	budgets := make([]ClientKeyBudget, 0, len(n.ClientKeyBudgets))
+	for _, budget := range n.ClientKeyBudgets {
+		budget.APIKey = strings.TrimSpace(budget.APIKey)
+		budget.Name = strings.TrimSpace(budget.Name)
+		if budget.APIKey == "" || (budget.MaxTokens <= 0 && budget.MaxRequests <= 0) {
+			continue
+		}
+		budgets = append(budgets, budget)
+	}
+	n.ClientKeyBudgets = budgets
+}
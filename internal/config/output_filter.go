@@ -0,0 +1,87 @@
+package config
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultOutputFilterReplacement replaces matched output when a rule omits replacement.
+	DefaultOutputFilterReplacement = "[REDACTED]"
+	// DefaultOutputFilterWindow is the default rolling buffer size, in bytes of streamed text.
+	DefaultOutputFilterWindow = 256
+	maxOutputFilterWindow     = 64 * 1024
+)
+
+// OutputFilterConfig configures response-side redaction applied after translation.
+type OutputFilterConfig struct {
+	// Enabled toggles output filtering for client-facing responses.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Window bounds the longest match that can be caught across streamed chunks.
+	// Streamed text is held back by up to this many bytes. <= 0 uses the default (256).
+	Window int `yaml:"window,omitempty" json:"window,omitempty"`
+
+	// Rules lists the patterns to redact, applied in order.
+	Rules []OutputFilterRule `yaml:"rules,omitempty" json:"rules,omitempty"`
+}
+
+// OutputFilterRule describes a single redaction pattern.
+type OutputFilterRule struct {
+	// Name identifies the rule in request logs and usage records.
+	Name string `yaml:"name" json:"name"`
+
+	// Pattern is an RE2 regular expression matched against response text.
+	Pattern string `yaml:"pattern" json:"pattern"`
+
+	// Replacement substitutes each match. Empty uses "[REDACTED]".
+	Replacement string `yaml:"replacement,omitempty" json:"replacement,omitempty"`
+}
+
+// WindowOrDefault returns the effective rolling buffer size.
+func (c OutputFilterConfig) WindowOrDefault() int {
+	if c.Window <= 0 {
+		return DefaultOutputFilterWindow
+	}
+	if c.Window > maxOutputFilterWindow {
+		return maxOutputFilterWindow
+	}
+	return c.Window
+}
+
+// SanitizeOutputFilter trims rule fields and drops rules with invalid patterns.
+func (cfg *Config) SanitizeOutputFilter() {
+	if cfg == nil {
+		return
+	}
+	cfg.OutputFilter.Rules = sanitizeOutputFilterRules(cfg.OutputFilter.Rules)
+}
+
+func sanitizeOutputFilterRules(rules []OutputFilterRule) []OutputFilterRule {
+	if len(rules) == 0 {
+		return rules
+	}
+	out := make([]OutputFilterRule, 0, len(rules))
+	for i := range rules {
+		rule := rules[i]
+		rule.Name = strings.TrimSpace(rule.Name)
+		if rule.Pattern == "" {
+			continue
+		}
+		if _, errCompile := regexp.Compile(rule.Pattern); errCompile != nil {
+			log.WithFields(log.Fields{
+				"rule_index": i + 1,
+				"rule":       rule.Name,
+			}).Warnf("output filter rule dropped: invalid pattern: %v", errCompile)
+			continue
+		}
+		if rule.Name == "" {
+			rule.Name = "rule-" + strconv.Itoa(i+1)
+		}
+		out = append(out, rule)
+	}
+	return out
+}
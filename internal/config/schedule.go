@@ -0,0 +1,49 @@
+package config
+
+import "fmt"
+
+// ValidateCredentialSchedules validates schedules for every API-key family.
+func (cfg *Config) ValidateCredentialSchedules() error {
+	if cfg == nil {
+		return nil
+	}
+	for index := range cfg.GeminiKey {
+		if errValidate := cfg.GeminiKey[index].Schedule.Validate(); errValidate != nil {
+			return fmt.Errorf("gemini-api-key[%d].schedule: %w", index, errValidate)
+		}
+	}
+	for index := range cfg.InteractionsKey {
+		if errValidate := cfg.InteractionsKey[index].Schedule.Validate(); errValidate != nil {
+			return fmt.Errorf("interactions-api-key[%d].schedule: %w", index, errValidate)
+		}
+	}
+	for index := range cfg.ClaudeKey {
+		if errValidate := cfg.ClaudeKey[index].Schedule.Validate(); errValidate != nil {
+			return fmt.Errorf("claude-api-key[%d].schedule: %w", index, errValidate)
+		}
+	}
+	for index := range cfg.VertexCompatAPIKey {
+		if errValidate := cfg.VertexCompatAPIKey[index].Schedule.Validate(); errValidate != nil {
+			return fmt.Errorf("vertex-api-key[%d].schedule: %w", index, errValidate)
+		}
+	}
+	for index := range cfg.CodexKey {
+		if errValidate := cfg.CodexKey[index].Schedule.Validate(); errValidate != nil {
+			return fmt.Errorf("codex-api-key[%d].schedule: %w", index, errValidate)
+		}
+	}
+	for index := range cfg.XAIKey {
+		if errValidate := cfg.XAIKey[index].Schedule.Validate(); errValidate != nil {
+			return fmt.Errorf("xai-api-key[%d].schedule: %w", index, errValidate)
+		}
+	}
+	for providerIndex := range cfg.OpenAICompatibility {
+		for keyIndex := range cfg.OpenAICompatibility[providerIndex].APIKeyEntries {
+			schedule := cfg.OpenAICompatibility[providerIndex].APIKeyEntries[keyIndex].Schedule
+			if errValidate := schedule.Validate(); errValidate != nil {
+				return fmt.Errorf("openai-compatibility[%d].api-key-entries[%d].schedule: %w", providerIndex, keyIndex, errValidate)
+			}
+		}
+	}
+	return nil
+}
@@ -51,6 +51,12 @@ type SDKConfig struct {
 	// APIKeys is a list of keys for authenticating clients to this proxy server.
 	APIKeys []string `yaml:"api-keys" json:"api-keys"`
 
+	// JWTAuth configures client authentication with bearer JWTs validated against a JWKS.
+	JWTAuth JWTAuthConfig `yaml:"jwt-auth,omitempty" json:"jwt-auth,omitempty"`
+
+	// ClientCertAuth configures client authentication with TLS client certificates.
+	ClientCertAuth ClientCertAuthConfig `yaml:"client-cert-auth,omitempty" json:"client-cert-auth,omitempty"`
+
 	// PassthroughHeaders controls whether upstream response headers are forwarded to downstream clients.
 	// Default is false (disabled).
 	PassthroughHeaders bool `yaml:"passthrough-headers" json:"passthrough-headers"`
@@ -61,6 +67,9 @@ type SDKConfig struct {
 	// NonStreamKeepAliveInterval controls how often blank lines are emitted for non-streaming responses.
 	// <= 0 disables keep-alives. Value is in seconds.
 	NonStreamKeepAliveInterval int `yaml:"nonstream-keepalive-interval,omitempty" json:"nonstream-keepalive-interval,omitempty"`
+
+	// OutputFilter configures redaction of model output after translation.
+	OutputFilter OutputFilterConfig `yaml:"output-filter,omitempty" json:"output-filter,omitempty"`
 }
 
 // ClaudeCodeConfig configures Claude Code compatibility behavior.
@@ -0,0 +1,290 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// secretRefPattern matches a whole-value secret reference such as
+// "${env:NAME}", "${file:/run/secrets/x}" or "${cmd:vault read ...}".
+var secretRefPattern = regexp.MustCompile(`^\$\{(env|file|cmd):(.+)\}$`)
+
+// secretRefCommandTimeout bounds how long a "${cmd:...}" reference may run.
+const secretRefCommandTimeout = 10 * time.Second
+
+// AllowSecretCommandsEnv must be set to a true value in the process
+// environment before "${cmd:...}" references are run. It is deliberately not
+// a config option so config written through the management API cannot enable
+// command execution.
+const AllowSecretCommandsEnv = "CLIPROXY_ALLOW_SECRET_COMMANDS"
+
+// ErrUnknownSecretRef is returned when a secret reference that is not present
+// in the loaded config is written through the management API. New references
+// must be added to config.yaml directly.
+var ErrUnknownSecretRef = errors.New("unknown secret reference")
+
+// ParseSecretRef splits a secret reference into its provider and argument. ok
+// is false when value is not a reference.
+func ParseSecretRef(value string) (provider, arg string, ok bool) {
+	match := secretRefPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return "", "", false
+	}
+	arg = strings.TrimSpace(match[2])
+	if arg == "" {
+		return "", "", false
+	}
+	return match[1], arg, true
+}
+
+// IsSecretRef reports whether value is a secret reference.
+func IsSecretRef(value string) bool {
+	_, _, ok := ParseSecretRef(value)
+	return ok
+}
+
+// ResolveSecretValue returns the secret a reference points at. Values that are
+// not references are returned unchanged.
+func ResolveSecretValue(value string) (string, error) {
+	provider, arg, ok := ParseSecretRef(value)
+	if !ok {
+		return value, nil
+	}
+	switch provider {
+	case "env":
+		resolved, found := os.LookupEnv(arg)
+		if !found {
+			return "", fmt.Errorf("environment variable %s is not set", arg)
+		}
+		return strings.TrimSpace(resolved), nil
+	case "file":
+		data, errRead := os.ReadFile(arg)
+		if errRead != nil {
+			return "", fmt.Errorf("read secret file: %w", errRead)
+		}
+		return strings.TrimSpace(string(data)), nil
+	case "cmd":
+		if allowed, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv(AllowSecretCommandsEnv))); !allowed {
+			return "", fmt.Errorf("command references are disabled; set %s=true to enable them", AllowSecretCommandsEnv)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), secretRefCommandTimeout)
+		defer cancel()
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.CommandContext(ctx, "cmd", "/C", arg)
+		} else {
+			cmd = exec.CommandContext(ctx, "sh", "-c", arg)
+		}
+		out, errRun := cmd.Output()
+		if errRun != nil {
+			return "", fmt.Errorf("run secret command: %w", errRun)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+	return "", fmt.Errorf("unsupported secret reference provider %q", provider)
+}
+
+// ResolveSecretRefs replaces secret references in API keys and management keys
+// with the values they point at and remembers each reference in SecretRefs so
+// it can be written back on save and shown by the management API.
+func (cfg *Config) ResolveSecretRefs() error {
+	if cfg == nil {
+		return nil
+	}
+	var errResolve error
+	cfg.visitSecretRefFields(func(field string, value *string) {
+		if errResolve != nil || !IsSecretRef(*value) {
+			return
+		}
+		ref := strings.TrimSpace(*value)
+		resolved, errValue := ResolveSecretValue(ref)
+		if errValue != nil {
+			errResolve = fmt.Errorf("failed to resolve %s reference %s: %w", field, ref, errValue)
+			return
+		}
+		if resolved == "" {
+			errResolve = fmt.Errorf("failed to resolve %s reference %s: value is empty", field, ref)
+			return
+		}
+		cfg.bindSecretRef(ref, resolved)
+		*value = resolved
+	})
+	return errResolve
+}
+
+// ApplySecretRefs replaces references written back through the management API
+// with the values they were resolved to at load time. References unknown to the
+// loaded config are rejected with ErrUnknownSecretRef.
+func (cfg *Config) ApplySecretRefs() error {
+	if cfg == nil {
+		return nil
+	}
+	return cfg.applySecretRefs(cfg.SecretRefs, true)
+}
+
+// ApplyKnownSecretRefs is ApplySecretRefs against known, the SecretRefs of
+// another config, for a config parsed from client input such as a raw
+// config.yaml upload. References known does not cover are rejected with
+// ErrUnknownSecretRef so they are never resolved on the next load.
+func (cfg *Config) ApplyKnownSecretRefs(known map[string]string) error {
+	if cfg == nil {
+		return nil
+	}
+	return cfg.applySecretRefs(known, true)
+}
+
+// MapSecretRefs replaces references in a config parsed by ParseConfigBytes
+// with the values known resolves them to, typically the SecretRefs of the
+// loaded config. Nothing is read or run; unknown references are left as is.
+// It lets a re-parsed file be compared with the running config.
+func (cfg *Config) MapSecretRefs(known map[string]string) {
+	if cfg == nil || len(known) == 0 {
+		return
+	}
+	_ = cfg.applySecretRefs(known, false)
+	cfg.SecretRefs = make(map[string]string, len(known))
+	for resolved, ref := range known {
+		cfg.SecretRefs[resolved] = ref
+	}
+}
+
+// applySecretRefs replaces references found in known, a resolved-to-reference
+// map. With strict set an unknown reference stops it with ErrUnknownSecretRef.
+func (cfg *Config) applySecretRefs(known map[string]string, strict bool) error {
+	resolvedByRef := make(map[string]string, len(known))
+	for resolved, ref := range known {
+		resolvedByRef[ref] = resolved
+	}
+	var errApply error
+	cfg.visitSecretRefFields(func(field string, value *string) {
+		if errApply != nil || !IsSecretRef(*value) {
+			return
+		}
+		ref := strings.TrimSpace(*value)
+		resolved, ok := resolvedByRef[ref]
+		if !ok {
+			if strict {
+				errApply = fmt.Errorf("%w %s in %s; add it to config.yaml instead", ErrUnknownSecretRef, ref, field)
+			}
+			return
+		}
+		*value = resolved
+	})
+	return errApply
+}
+
+// SecretRef returns the reference value was resolved from, or value itself
+// when it did not come from a reference.
+func (cfg *Config) SecretRef(value string) string {
+	if cfg == nil || value == "" {
+		return value
+	}
+	if ref, ok := cfg.SecretRefs[value]; ok {
+		return ref
+	}
+	return value
+}
+
+// SecretValue returns the value a known reference resolved to, or value itself
+// when it is not a reference recorded at load time. Management handlers use it
+// to match entries selected by the reference shown to clients.
+func (cfg *Config) SecretValue(value string) string {
+	if cfg == nil || !IsSecretRef(value) {
+		return value
+	}
+	ref := strings.TrimSpace(value)
+	for resolved, known := range cfg.SecretRefs {
+		if known == ref {
+			return resolved
+		}
+	}
+	return value
+}
+
+// WithSecretRefs returns a copy of cfg whose referenced secrets are replaced by
+// their references, for display. It returns cfg itself when nothing was
+// resolved from a reference.
+func (cfg *Config) WithSecretRefs() *Config {
+	if cfg == nil || len(cfg.SecretRefs) == 0 {
+		return cfg
+	}
+	out := cfg.CloneForRuntime()
+	out.visitSecretRefFields(func(_ string, value *string) {
+		*value = cfg.SecretRef(*value)
+	})
+	return out
+}
+
+// bindSecretRef records that resolved was read from ref.
+func (cfg *Config) bindSecretRef(ref, resolved string) {
+	if cfg.SecretRefs == nil {
+		cfg.SecretRefs = make(map[string]string)
+	}
+	if existing, ok := cfg.SecretRefs[resolved]; ok && existing <= ref {
+		// Keep the pick deterministic when two references share a value.
+		return
+	}
+	cfg.SecretRefs[resolved] = ref
+}
+
+// rebindSecretRef moves the reference recorded for previous to current. It is
+// used when a resolved management key is replaced by its hash.
+func (cfg *Config) rebindSecretRef(previous, current string) {
+	ref, ok := cfg.SecretRefs[previous]
+	if !ok {
+		return
+	}
+	delete(cfg.SecretRefs, previous)
+	cfg.SecretRefs[current] = ref
+}
+
+// visitSecretRefFields calls fn for every config field that may hold a secret
+// reference: the fields sealed at rest plus the management keys.
+func (cfg *Config) visitSecretRefFields(fn func(field string, value *string)) {
+	cfg.visitSecrets(fn)
+	fn("remote-management.secret-key", &cfg.RemoteManagement.SecretKey)
+	for i := range cfg.RemoteManagement.Keys {
+		fn("remote-management.keys", &cfg.RemoteManagement.Keys[i].Key)
+	}
+}
+
+// restoreSecretRefNodes writes the recorded references back over resolved
+// values in a generated YAML document root so secrets read from the
+// environment, files or commands never land in config.yaml.
+func (cfg *Config) restoreSecretRefNodes(root *yaml.Node) {
+	if cfg == nil || len(cfg.SecretRefs) == 0 || root == nil || root.Kind != yaml.MappingNode {
+		return
+	}
+	restore := func(_ string, node *yaml.Node) error {
+		if node == nil || node.Kind != yaml.ScalarNode {
+			return nil
+		}
+		if ref, ok := cfg.SecretRefs[node.Value]; ok {
+			node.Value = ref
+			node.Style = yaml.DoubleQuotedStyle
+			node.Tag = "!!str"
+		}
+		return nil
+	}
+	_ = visitSecretNodes(root, restore)
+	management := mappingValue(root, "remote-management")
+	if management == nil || management.Kind != yaml.MappingNode {
+		return
+	}
+	_ = restore("remote-management.secret-key", mappingValue(management, "secret-key"))
+	if keys := mappingValue(management, "keys"); keys != nil && keys.Kind == yaml.SequenceNode {
+		for _, entry := range keys.Content {
+			_ = restore("remote-management.keys", mappingValue(entry, "key"))
+		}
+	}
+}
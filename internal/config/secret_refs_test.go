@@ -0,0 +1,222 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveSecretValue(t *testing.T) {
+	t.Setenv("CLIPROXY_TEST_SECRET", " env-secret\n")
+	t.Setenv(AllowSecretCommandsEnv, "true")
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	if errWrite := os.WriteFile(secretFile, []byte("file-secret\n"), 0o600); errWrite != nil {
+		t.Fatalf("WriteFile() error = %v", errWrite)
+	}
+
+	cases := map[string]string{
+		"${env:CLIPROXY_TEST_SECRET}": "env-secret",
+		"${file:" + secretFile + "}":  "file-secret",
+		"${cmd:echo cmd-secret}":      "cmd-secret",
+		"plain-secret":                "plain-secret",
+		"${vault:kv/x}":               "${vault:kv/x}",
+	}
+	for value, want := range cases {
+		got, errResolve := ResolveSecretValue(value)
+		if errResolve != nil || got != want {
+			t.Fatalf("ResolveSecretValue(%q) = %q, %v; want %q", value, got, errResolve, want)
+		}
+	}
+	if _, errResolve := ResolveSecretValue("${env:CLIPROXY_TEST_SECRET_MISSING}"); errResolve == nil {
+		t.Fatal("unset environment variable resolved")
+	}
+}
+
+func TestSecretRefsResolvedOnLoadAndKeptOnSave(t *testing.T) {
+	t.Setenv(AllowSecretCommandsEnv, "true")
+	t.Setenv("CLIPROXY_TEST_CLAUDE_KEY", "sk-ant-from-env")
+	t.Setenv("CLIPROXY_TEST_MANAGEMENT_KEY", "management-from-env")
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "gemini")
+	if errWrite := os.WriteFile(secretFile, []byte("gemini-from-file\n"), 0o600); errWrite != nil {
+		t.Fatalf("WriteFile() error = %v", errWrite)
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	if errWrite := os.WriteFile(configPath, []byte(`remote-management:
+  secret-key: "${env:CLIPROXY_TEST_MANAGEMENT_KEY}"
+api-keys:
+  - "plain-client-key"
+gemini-api-key:
+  - api-key: "${file:`+secretFile+`}"
+claude-api-key:
+  - api-key: "${env:CLIPROXY_TEST_CLAUDE_KEY}"
+openai-compatibility:
+  - name: "compat"
+    base-url: "https://compat.example.com/v1"
+    api-key-entries:
+      - api-key: "${cmd:echo sk-compat-from-cmd | tr a-z A-Z}"
+`), 0o600); errWrite != nil {
+		t.Fatalf("WriteFile() error = %v", errWrite)
+	}
+
+	cfg, errLoad := LoadConfig(configPath)
+	if errLoad != nil {
+		t.Fatalf("LoadConfig() error = %v", errLoad)
+	}
+	if cfg.GeminiKey[0].APIKey != "gemini-from-file" || cfg.ClaudeKey[0].APIKey != "sk-ant-from-env" ||
+		cfg.OpenAICompatibility[0].APIKeyEntries[0].APIKey != "SK-COMPAT-FROM-CMD" {
+		t.Fatalf("references not resolved: gemini=%q claude=%q compat=%q", cfg.GeminiKey[0].APIKey,
+			cfg.ClaudeKey[0].APIKey, cfg.OpenAICompatibility[0].APIKeyEntries[0].APIKey)
+	}
+	if !looksLikeBcrypt(cfg.RemoteManagement.SecretKey) {
+		t.Fatalf("management key not hashed: %q", cfg.RemoteManagement.SecretKey)
+	}
+	if got := cfg.SecretRef(cfg.RemoteManagement.SecretKey); got != "${env:CLIPROXY_TEST_MANAGEMENT_KEY}" {
+		t.Fatalf("SecretRef(management hash) = %q", got)
+	}
+
+	onDisk, _ := os.ReadFile(configPath)
+	if !strings.Contains(string(onDisk), "${env:CLIPROXY_TEST_MANAGEMENT_KEY}") {
+		t.Fatalf("management key reference replaced on load:\n%s", onDisk)
+	}
+
+	cfg.APIKeys = append(cfg.APIKeys, "second-client-key")
+	if errSave := SaveConfigPreserveComments(configPath, cfg); errSave != nil {
+		t.Fatalf("SaveConfigPreserveComments() error = %v", errSave)
+	}
+	saved, _ := os.ReadFile(configPath)
+	for _, secret := range []string{"gemini-from-file", "sk-ant-from-env", "SK-COMPAT-FROM-CMD", "$2a$"} {
+		if strings.Contains(string(saved), secret) {
+			t.Fatalf("saved config contains resolved secret %q:\n%s", secret, saved)
+		}
+	}
+	for _, ref := range []string{"${file:" + secretFile + "}", "${env:CLIPROXY_TEST_CLAUDE_KEY}", "${cmd:echo sk-compat-from-cmd | tr a-z A-Z}", "second-client-key"} {
+		if !strings.Contains(string(saved), ref) {
+			t.Fatalf("saved config lost %q:\n%s", ref, saved)
+		}
+	}
+
+	display := cfg.WithSecretRefs()
+	if display.ClaudeKey[0].APIKey != "${env:CLIPROXY_TEST_CLAUDE_KEY}" || cfg.ClaudeKey[0].APIKey != "sk-ant-from-env" {
+		t.Fatalf("WithSecretRefs() = %q, original = %q", display.ClaudeKey[0].APIKey, cfg.ClaudeKey[0].APIKey)
+	}
+}
+
+func TestSecretRefsResolvedInNotificationFields(t *testing.T) {
+	t.Setenv("CLIPROXY_TEST_WEBHOOK_HMAC", "hmac-from-env")
+	t.Setenv("CLIPROXY_TEST_WEBHOOK_TOKEN", "Bearer token-from-env")
+	t.Setenv("CLIPROXY_TEST_CLIENT_KEY", "client-from-env")
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if errWrite := os.WriteFile(configPath, []byte(`api-keys:
+  - "${env:CLIPROXY_TEST_CLIENT_KEY}"
+notifications:
+  targets:
+    - name: "ops"
+      url: "https://hooks.example.com/alert"
+      secret: "${env:CLIPROXY_TEST_WEBHOOK_HMAC}"
+      headers:
+        Authorization: "${env:CLIPROXY_TEST_WEBHOOK_TOKEN}"
+  client-key-budgets:
+    - api-key: "${env:CLIPROXY_TEST_CLIENT_KEY}"
+      max-requests: 10
+`), 0o600); errWrite != nil {
+		t.Fatalf("WriteFile() error = %v", errWrite)
+	}
+
+	cfg, errLoad := LoadConfig(configPath)
+	if errLoad != nil {
+		t.Fatalf("LoadConfig() error = %v", errLoad)
+	}
+	target := cfg.Notifications.Targets[0]
+	if target.Secret != "hmac-from-env" || target.Headers["Authorization"] != "Bearer token-from-env" {
+		t.Fatalf("target references not resolved: secret %q, headers %v", target.Secret, target.Headers)
+	}
+	if budget := cfg.Notifications.ClientKeyBudgets[0]; budget.APIKey != "client-from-env" {
+		t.Fatalf("budget api-key = %q, want the resolved client key", budget.APIKey)
+	}
+
+	if errSave := SaveConfigPreserveComments(configPath, cfg); errSave != nil {
+		t.Fatalf("SaveConfigPreserveComments() error = %v", errSave)
+	}
+	saved, _ := os.ReadFile(configPath)
+	for _, secret := range []string{"hmac-from-env", "token-from-env", "client-from-env"} {
+		if strings.Contains(string(saved), secret) {
+			t.Fatalf("saved config contains resolved secret %q:\n%s", secret, saved)
+		}
+	}
+	for _, ref := range []string{"${env:CLIPROXY_TEST_WEBHOOK_HMAC}", "${env:CLIPROXY_TEST_WEBHOOK_TOKEN}", "api-key: \"${env:CLIPROXY_TEST_CLIENT_KEY}\""} {
+		if !strings.Contains(string(saved), ref) {
+			t.Fatalf("saved config lost %q:\n%s", ref, saved)
+		}
+	}
+}
+
+func TestApplySecretRefsRejectsUnknownReferences(t *testing.T) {
+	t.Setenv("CLIPROXY_TEST_CLAUDE_KEY", "sk-ant-from-env")
+	cfg := &Config{ClaudeKey: []ClaudeKey{{APIKey: "${env:CLIPROXY_TEST_CLAUDE_KEY}"}}}
+	if errResolve := cfg.ResolveSecretRefs(); errResolve != nil {
+		t.Fatalf("ResolveSecretRefs() error = %v", errResolve)
+	}
+
+	// A management client echoes the displayed reference back.
+	cfg.ClaudeKey[0].APIKey = "${env:CLIPROXY_TEST_CLAUDE_KEY}"
+	if errApply := cfg.ApplySecretRefs(); errApply != nil {
+		t.Fatalf("ApplySecretRefs() error = %v", errApply)
+	}
+	if cfg.ClaudeKey[0].APIKey != "sk-ant-from-env" {
+		t.Fatalf("reference not restored to its value: %q", cfg.ClaudeKey[0].APIKey)
+	}
+
+	cfg.APIKeys = []string{"${cmd:cat /etc/shadow}"}
+	if errApply := cfg.ApplySecretRefs(); !errors.Is(errApply, ErrUnknownSecretRef) {
+		t.Fatalf("ApplySecretRefs() error = %v, want ErrUnknownSecretRef", errApply)
+	}
+}
+
+func TestSecretCommandsRequireOptIn(t *testing.T) {
+	t.Setenv(AllowSecretCommandsEnv, "")
+	if _, errResolve := ResolveSecretValue("${cmd:echo cmd-secret}"); errResolve == nil || !strings.Contains(errResolve.Error(), AllowSecretCommandsEnv) {
+		t.Fatalf("ResolveSecretValue() error = %v, want opt-in error", errResolve)
+	}
+}
+
+// Config that is only parsed or validated, such as a Home payload, a
+// management upload or the audit re-parse, must not read files or run commands.
+func TestSecretRefsNotResolvedWhenParsingOrValidating(t *testing.T) {
+	t.Setenv(AllowSecretCommandsEnv, "true")
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "ran")
+	payload := []byte(`remote-management:
+  secret-key: "${env:CLIPROXY_TEST_MANAGEMENT_KEY}"
+claude-api-key:
+  - api-key: "${cmd:touch ` + marker + `}"
+gemini-api-key:
+  - api-key: "${file:/etc/hostname}"
+`)
+
+	parsed, errParse := ParseConfigBytes(payload)
+	if errParse != nil {
+		t.Fatalf("ParseConfigBytes() error = %v", errParse)
+	}
+	if parsed.GeminiKey[0].APIKey != "${file:/etc/hostname}" || parsed.RemoteManagement.SecretKey != "${env:CLIPROXY_TEST_MANAGEMENT_KEY}" {
+		t.Fatalf("references resolved or hashed: gemini=%q secret=%q", parsed.GeminiKey[0].APIKey, parsed.RemoteManagement.SecretKey)
+	}
+
+	configPath := filepath.Join(dir, "config.yaml")
+	if errWrite := os.WriteFile(configPath, payload, 0o600); errWrite != nil {
+		t.Fatalf("WriteFile() error = %v", errWrite)
+	}
+	if errValidate := ValidateConfigFile(configPath); errValidate != nil {
+		t.Fatalf("ValidateConfigFile() error = %v", errValidate)
+	}
+	if _, errStat := os.Stat(marker); !os.IsNotExist(errStat) {
+		t.Fatal("command reference ran while parsing")
+	}
+
+	parsed.MapSecretRefs(map[string]string{"sk-ant-resolved": "${cmd:touch " + marker + "}"})
+	if parsed.ClaudeKey[0].APIKey != "sk-ant-resolved" || parsed.GeminiKey[0].APIKey != "${file:/etc/hostname}" {
+		t.Fatalf("MapSecretRefs() claude=%q gemini=%q", parsed.ClaudeKey[0].APIKey, parsed.GeminiKey[0].APIKey)
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/credentialschedule"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
 )
 
@@ -25,6 +26,10 @@ type VertexCompatKey struct {
 	// An omitted value defaults to 1; non-positive values exclude this credential; maximum 1,000,000.
 	Weight *int `yaml:"weight,omitempty" json:"weight,omitempty"`
 
+	// Schedule optionally limits this credential to weekly time windows and
+	// overrides its priority by time of day.
+	Schedule *credentialschedule.Schedule `yaml:"schedule,omitempty" json:"schedule,omitempty"`
+
 	// Prefix optionally namespaces model aliases for this credential (e.g., "teamA/vertex-pro").
 	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
 
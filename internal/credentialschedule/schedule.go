@@ -0,0 +1,356 @@
+// Package credentialschedule parses and evaluates weekly credential availability
+// windows and time-of-day priority overrides.
+//
+// A window is written as "[days] HH:MM-HH:MM", for example "mon-fri 19:00-07:00"
+// or "sat,sun 00:00-24:00". Days are comma-separated names or ranges (mon..sun);
+// "*", "daily" or no days at all mean every day. A window whose end is not after
+// its start runs overnight into the next day.
+package credentialschedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+)
+
+// Schedule restricts when a credential is used and optionally overrides its
+// priority during parts of the week.
+type Schedule struct {
+	// Timezone is an IANA zone name; empty means the server's local time.
+	Timezone string `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+	// Windows lists when the credential may be used; empty means always.
+	Windows []string `yaml:"windows,omitempty" json:"windows,omitempty"`
+	// Priorities override the credential priority inside their windows. The
+	// first matching entry wins.
+	Priorities []PriorityWindow `yaml:"priorities,omitempty" json:"priorities,omitempty"`
+}
+
+// PriorityWindow overrides the credential priority inside its windows.
+type PriorityWindow struct {
+	Windows  []string `yaml:"windows" json:"windows"`
+	Priority int      `yaml:"priority" json:"priority"`
+}
+
+// State is a schedule evaluated at one instant.
+type State struct {
+	// Active reports whether the credential is inside an availability window.
+	Active bool `json:"active"`
+	// Priority is the override in effect, if any.
+	Priority *int `json:"priority,omitempty"`
+	// NextTransition is when Active or Priority next changes; zero when the
+	// schedule never changes.
+	NextTransition time.Time `json:"next_transition,omitzero"`
+}
+
+// interval is a half-open range of minutes since Monday 00:00.
+type interval struct {
+	start, end int
+}
+
+type priorityIntervals struct {
+	intervals []interval
+	priority  int
+}
+
+// Compiled is a validated schedule ready for evaluation.
+type Compiled struct {
+	location   *time.Location
+	windows    []interval
+	priorities []priorityIntervals
+	boundaries []int
+}
+
+// IsZero reports whether the schedule sets nothing.
+func (s *Schedule) IsZero() bool {
+	return s == nil || (len(s.Windows) == 0 && len(s.Priorities) == 0)
+}
+
+// Validate reports the first problem in the schedule.
+func (s *Schedule) Validate() error {
+	_, errCompile := Compile(s)
+	return errCompile
+}
+
+// Compile validates a schedule. A nil or empty schedule compiles to nil.
+func Compile(s *Schedule) (*Compiled, error) {
+	if s.IsZero() {
+		return nil, nil
+	}
+	compiled := &Compiled{location: time.Local}
+	if zone := strings.TrimSpace(s.Timezone); zone != "" {
+		location, errZone := time.LoadLocation(zone)
+		if errZone != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", zone, errZone)
+		}
+		compiled.location = location
+	}
+	windows, errWindows := parseWindows(s.Windows)
+	if errWindows != nil {
+		return nil, errWindows
+	}
+	compiled.windows = windows
+	for index, override := range s.Priorities {
+		if len(override.Windows) == 0 {
+			return nil, fmt.Errorf("priorities[%d]: windows are required", index)
+		}
+		intervals, errParse := parseWindows(override.Windows)
+		if errParse != nil {
+			return nil, fmt.Errorf("priorities[%d]: %w", index, errParse)
+		}
+		compiled.priorities = append(compiled.priorities, priorityIntervals{intervals: intervals, priority: override.Priority})
+	}
+	compiled.boundaries = collectBoundaries(compiled)
+	return compiled, nil
+}
+
+// ParseValue parses a JSON-compatible auth-file metadata value.
+func ParseValue(value any) (*Schedule, error) {
+	if value == nil {
+		return nil, nil
+	}
+	raw, errMarshal := json.Marshal(value)
+	if errMarshal != nil {
+		return nil, fmt.Errorf("schedule must be an object: %w", errMarshal)
+	}
+	return ParseString(string(raw))
+}
+
+// ParseString parses a schedule encoded by Encode.
+func ParseString(raw string) (*Schedule, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	var schedule Schedule
+	if errDecode := decoder.Decode(&schedule); errDecode != nil {
+		return nil, fmt.Errorf("schedule must be an object with timezone, windows and priorities: %w", errDecode)
+	}
+	if errValidate := schedule.Validate(); errValidate != nil {
+		return nil, errValidate
+	}
+	return &schedule, nil
+}
+
+// Encode serializes a schedule for a scheduler attribute.
+func Encode(s *Schedule) string {
+	if s.IsZero() {
+		return ""
+	}
+	raw, _ := json.Marshal(s)
+	return string(raw)
+}
+
+// At evaluates the schedule at now.
+func (c *Compiled) At(now time.Time) State {
+	if c == nil {
+		return State{Active: true}
+	}
+	local := now.In(c.location)
+	minute := minuteOfWeek(local)
+	state := c.stateAt(minute)
+	state.NextTransition = c.nextTransition(local, minute, state)
+	return state
+}
+
+// nextTransition returns the earliest boundary after minute at which the state
+// differs from current.
+func (c *Compiled) nextTransition(local time.Time, minute int, current State) time.Time {
+	best := -1
+	for _, boundary := range c.boundaries {
+		offset := boundary - minute
+		if offset <= 0 {
+			offset += minutesPerWeek
+		}
+		if best >= 0 && offset >= best {
+			continue
+		}
+		if !sameState(current, c.stateAt(boundary)) {
+			best = offset
+		}
+	}
+	if best < 0 {
+		return time.Time{}
+	}
+	return addMinutes(local, best)
+}
+
+func (c *Compiled) stateAt(minute int) State {
+	state := State{Active: len(c.windows) == 0 || containsMinute(c.windows, minute)}
+	for _, override := range c.priorities {
+		if containsMinute(override.intervals, minute) {
+			priority := override.priority
+			state.Priority = &priority
+			break
+		}
+	}
+	return state
+}
+
+func sameState(a, b State) bool {
+	if a.Active != b.Active || (a.Priority == nil) != (b.Priority == nil) {
+		return false
+	}
+	return a.Priority == nil || *a.Priority == *b.Priority
+}
+
+func containsMinute(intervals []interval, minute int) bool {
+	for _, span := range intervals {
+		if minute >= span.start && minute < span.end {
+			return true
+		}
+	}
+	return false
+}
+
+func collectBoundaries(c *Compiled) []int {
+	seen := make(map[int]struct{})
+	add := func(intervals []interval) {
+		for _, span := range intervals {
+			seen[span.start%minutesPerWeek] = struct{}{}
+			seen[span.end%minutesPerWeek] = struct{}{}
+		}
+	}
+	add(c.windows)
+	for _, override := range c.priorities {
+		add(override.intervals)
+	}
+	boundaries := make([]int, 0, len(seen))
+	for boundary := range seen {
+		boundaries = append(boundaries, boundary)
+	}
+	sort.Ints(boundaries)
+	return boundaries
+}
+
+// minuteOfWeek counts minutes since Monday 00:00 of the week containing t.
+func minuteOfWeek(t time.Time) int {
+	day := (int(t.Weekday()) + 6) % 7
+	return day*minutesPerDay + t.Hour()*60 + t.Minute()
+}
+
+// addMinutes moves local forward to the start of the minute offset minutes
+// ahead, using calendar arithmetic so DST shifts land on wall-clock times.
+func addMinutes(local time.Time, offset int) time.Time {
+	start := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), 0, 0, local.Location())
+	return time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute()+offset, 0, 0, start.Location())
+}
+
+var dayIndex = map[string]int{"mon": 0, "tue": 1, "wed": 2, "thu": 3, "fri": 4, "sat": 5, "sun": 6}
+
+func parseWindows(specs []string) ([]interval, error) {
+	intervals := make([]interval, 0, len(specs))
+	for _, spec := range specs {
+		parsed, errParse := parseWindow(spec)
+		if errParse != nil {
+			return nil, fmt.Errorf("invalid window %q: %w", spec, errParse)
+		}
+		intervals = append(intervals, parsed...)
+	}
+	return intervals, nil
+}
+
+func parseWindow(spec string) ([]interval, error) {
+	fields := strings.Fields(strings.ToLower(spec))
+	var daysSpec, timeSpec string
+	switch len(fields) {
+	case 1:
+		daysSpec, timeSpec = "*", fields[0]
+	case 2:
+		daysSpec, timeSpec = fields[0], fields[1]
+	default:
+		return nil, fmt.Errorf("expected \"[days] HH:MM-HH:MM\"")
+	}
+	days, errDays := parseDays(daysSpec)
+	if errDays != nil {
+		return nil, errDays
+	}
+	startSpec, endSpec, ok := strings.Cut(timeSpec, "-")
+	if !ok {
+		return nil, fmt.Errorf("expected a HH:MM-HH:MM time range")
+	}
+	start, errStart := parseClock(startSpec, false)
+	if errStart != nil {
+		return nil, errStart
+	}
+	end, errEnd := parseClock(endSpec, true)
+	if errEnd != nil {
+		return nil, errEnd
+	}
+	if start == end {
+		return nil, fmt.Errorf("start and end must differ")
+	}
+	if end < start {
+		end += minutesPerDay
+	}
+	intervals := make([]interval, 0, len(days)+1)
+	for _, day := range days {
+		from, to := day*minutesPerDay+start, day*minutesPerDay+end
+		if to <= minutesPerWeek {
+			intervals = append(intervals, interval{start: from, end: to})
+			continue
+		}
+		// Sunday night windows continue into Monday morning.
+		intervals = append(intervals, interval{start: from, end: minutesPerWeek}, interval{start: 0, end: to - minutesPerWeek})
+	}
+	return intervals, nil
+}
+
+func parseDays(spec string) ([]int, error) {
+	if spec == "*" || spec == "daily" {
+		return []int{0, 1, 2, 3, 4, 5, 6}, nil
+	}
+	selected := make(map[int]struct{})
+	for _, part := range strings.Split(spec, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		first, ok := dayIndex[from]
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q", from)
+		}
+		last := first
+		if isRange {
+			if last, ok = dayIndex[to]; !ok {
+				return nil, fmt.Errorf("unknown day %q", to)
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			selected[day] = struct{}{}
+			if day == last {
+				break
+			}
+		}
+	}
+	days := make([]int, 0, len(selected))
+	for day := range selected {
+		days = append(days, day)
+	}
+	sort.Ints(days)
+	return days, nil
+}
+
+func parseClock(spec string, allowMidnightEnd bool) (int, error) {
+	hourSpec, minuteSpec, ok := strings.Cut(spec, ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time %q", spec)
+	}
+	hour, errHour := strconv.Atoi(hourSpec)
+	minute, errMinute := strconv.Atoi(minuteSpec)
+	if errHour != nil || errMinute != nil || minute < 0 || minute > 59 || hour < 0 || hour > 24 {
+		return 0, fmt.Errorf("invalid time %q", spec)
+	}
+	if hour == 24 {
+		if !allowMidnightEnd || minute != 0 {
+			return 0, fmt.Errorf("invalid time %q", spec)
+		}
+	}
+	return hour*60 + minute, nil
+}
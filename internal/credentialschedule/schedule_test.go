@@ -0,0 +1,98 @@
+package credentialschedule
+
+import (
+	"testing"
+	"time"
+)
+
+func mustCompile(t *testing.T, s *Schedule) *Compiled {
+	t.Helper()
+	compiled, errCompile := Compile(s)
+	if errCompile != nil {
+		t.Fatalf("Compile() error = %v", errCompile)
+	}
+	return compiled
+}
+
+func TestScheduleOvernightWeekdayWindow(t *testing.T) {
+	compiled := mustCompile(t, &Schedule{Timezone: "UTC", Windows: []string{"mon-fri 19:00-07:00"}})
+
+	// 2026-10-19 is a Monday.
+	tests := []struct {
+		at     time.Time
+		active bool
+		next   time.Time
+	}{
+		{time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC), false, time.Date(2026, 10, 19, 19, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 10, 19, 23, 30, 0, 0, time.UTC), true, time.Date(2026, 10, 20, 7, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 10, 24, 6, 59, 0, 0, time.UTC), true, time.Date(2026, 10, 24, 7, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 10, 24, 12, 0, 0, 0, time.UTC), false, time.Date(2026, 10, 26, 19, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		state := compiled.At(tt.at)
+		if state.Active != tt.active || !state.NextTransition.Equal(tt.next) {
+			t.Fatalf("At(%s) = %+v, want active=%v next=%s", tt.at, state, tt.active, tt.next)
+		}
+	}
+}
+
+func TestScheduleSundayNightWrapsIntoMonday(t *testing.T) {
+	compiled := mustCompile(t, &Schedule{Timezone: "UTC", Windows: []string{"sun 22:00-02:00"}})
+	if state := compiled.At(time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC)); !state.Active {
+		t.Fatalf("Monday 01:00 = %+v, want active", state)
+	}
+	if state := compiled.At(time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)); state.Active {
+		t.Fatalf("Monday 03:00 = %+v, want inactive", state)
+	}
+}
+
+func TestSchedulePriorityOverrides(t *testing.T) {
+	compiled := mustCompile(t, &Schedule{
+		Timezone:   "UTC",
+		Priorities: []PriorityWindow{{Windows: []string{"daily 00:00-06:00"}, Priority: 50}},
+	})
+	night := compiled.At(time.Date(2026, 10, 20, 3, 0, 0, 0, time.UTC))
+	if !night.Active || night.Priority == nil || *night.Priority != 50 || !night.NextTransition.Equal(time.Date(2026, 10, 20, 6, 0, 0, 0, time.UTC)) {
+		t.Fatalf("night state = %+v", night)
+	}
+	day := compiled.At(time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC))
+	if !day.Active || day.Priority != nil || !day.NextTransition.Equal(time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("day state = %+v", day)
+	}
+}
+
+func TestScheduleAlwaysActiveHasNoTransition(t *testing.T) {
+	compiled := mustCompile(t, &Schedule{Windows: []string{"00:00-24:00"}})
+	if state := compiled.At(time.Now()); !state.Active || !state.NextTransition.IsZero() {
+		t.Fatalf("state = %+v, want always active without transitions", state)
+	}
+	if compiled, _ := Compile(nil); compiled.At(time.Now()).Active != true {
+		t.Fatal("nil schedule must be active")
+	}
+}
+
+func TestScheduleRejectsInvalidSpecs(t *testing.T) {
+	for _, schedule := range []*Schedule{
+		{Windows: []string{"funday 10:00-11:00"}},
+		{Windows: []string{"mon 25:00-26:00"}},
+		{Windows: []string{"mon 10:00-10:00"}},
+		{Windows: []string{"mon 10:00"}},
+		{Timezone: "Mars/Olympus", Windows: []string{"mon 10:00-11:00"}},
+		{Priorities: []PriorityWindow{{Priority: 3}}},
+	} {
+		if errValidate := schedule.Validate(); errValidate == nil {
+			t.Fatalf("Validate(%+v) succeeded, want an error", schedule)
+		}
+	}
+	if _, errParse := ParseValue(map[string]any{"windows": []any{"mon 10:00-11:00"}, "bogus": true}); errParse == nil {
+		t.Fatal("ParseValue accepted an unknown field")
+	}
+}
+
+func TestScheduleEncodeRoundTrip(t *testing.T) {
+	schedule := &Schedule{Timezone: "Europe/Berlin", Windows: []string{"sat,sun 00:00-24:00"}}
+	parsed, errParse := ParseString(Encode(schedule))
+	if errParse != nil || parsed.Timezone != schedule.Timezone || len(parsed.Windows) != 1 {
+		t.Fatalf("ParseString(Encode()) = %+v, %v", parsed, errParse)
+	}
+}
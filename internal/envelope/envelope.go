@@ -0,0 +1,275 @@
+// Package envelope implements envelope encryption for data at rest.
+//
+// Each sealed payload is encrypted with a fresh AES-256-GCM data key, and the
+// data key is wrapped by a KeyProvider (a local master key or a KMS). Sealed
+// payloads are themselves JSON objects, so auth files remain valid JSON for
+// every store backend and can be told apart from legacy plaintext records.
+//
+// Every record is bound to a name, passed to GCM as additional authenticated
+// data: the base name of an auth file, or the config field of a secret. A
+// sealed record copied over another record therefore fails to open.
+package envelope
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// formatVersion is the version stored in sealed records.
+	formatVersion = 1
+	// versionField is the JSON field that marks a sealed record.
+	versionField = "cliproxy_envelope"
+	// StringPrefix marks sealed string values such as config secrets.
+	StringPrefix = "enc:v1:"
+
+	dataKeySize = 32
+)
+
+var (
+	// ErrNoKey is returned when sealed data is read but no key provider is configured.
+	ErrNoKey = errors.New("envelope: data is encrypted but no master key is configured")
+	// ErrUnknownKey is returned when the key that wrapped a record is not available.
+	ErrUnknownKey = errors.New("envelope: unknown key id")
+)
+
+// sealedRecord is the on-disk form of a sealed payload.
+type sealedRecord struct {
+	Version int    `json:"cliproxy_envelope"`
+	KeyID   string `json:"kid"`
+	DataKey string `json:"dek"`
+	Nonce   string `json:"nonce"`
+	Data    string `json:"data"`
+}
+
+type providerHolder struct {
+	provider KeyProvider
+}
+
+var current atomic.Value
+
+// sealedStrings maps "<key id>\x00<name>\x00<plaintext>" to a sealed string, so
+// rewriting a config whose secrets did not change keeps their ciphertext and
+// the file stays byte-identical.
+var sealedStrings sync.Map
+
+// Configure installs the process-wide key provider. A nil provider disables
+// sealing; reading data that is already sealed then fails with ErrNoKey.
+func Configure(provider KeyProvider) {
+	current.Store(providerHolder{provider: provider})
+}
+
+// Current returns the process-wide key provider, or nil when encryption is off.
+func Current() KeyProvider {
+	holder, _ := current.Load().(providerHolder)
+	return holder.provider
+}
+
+// Enabled reports whether new writes are sealed.
+func Enabled() bool {
+	return Current() != nil
+}
+
+// IsSealed reports whether data is a sealed record.
+func IsSealed(data []byte) bool {
+	if !bytes.Contains(data, []byte(versionField)) {
+		return false
+	}
+	var probe struct {
+		Version int `json:"cliproxy_envelope"`
+	}
+	if errUnmarshal := json.Unmarshal(data, &probe); errUnmarshal != nil {
+		return false
+	}
+	return probe.Version > 0
+}
+
+// RecordName returns the name an auth file at path is bound to. The base name
+// is used so records stay readable when the auth directory moves or is
+// mirrored by a store backend.
+func RecordName(path string) string {
+	return filepath.Base(path)
+}
+
+// Seal encrypts plaintext, bound to name, with the process-wide provider. When
+// encryption is off the plaintext is returned unchanged.
+func Seal(name string, plaintext []byte) ([]byte, error) {
+	provider := Current()
+	if provider == nil {
+		return plaintext, nil
+	}
+	return SealWith(provider, name, plaintext)
+}
+
+// SealWith encrypts plaintext with a fresh data key wrapped by provider and
+// binds it to name.
+func SealWith(provider KeyProvider, name string, plaintext []byte) ([]byte, error) {
+	if provider == nil {
+		return nil, ErrNoKey
+	}
+	dataKey := make([]byte, dataKeySize)
+	if _, errRand := rand.Read(dataKey); errRand != nil {
+		return nil, fmt.Errorf("envelope: generate data key: %w", errRand)
+	}
+	nonce, ciphertext, errEncrypt := encrypt(dataKey, plaintext, []byte(name))
+	if errEncrypt != nil {
+		return nil, errEncrypt
+	}
+	wrapped, errWrap := provider.WrapKey(dataKey)
+	if errWrap != nil {
+		return nil, fmt.Errorf("envelope: wrap data key: %w", errWrap)
+	}
+	return json.Marshal(sealedRecord{
+		Version: formatVersion,
+		KeyID:   provider.KeyID(),
+		DataKey: base64.StdEncoding.EncodeToString(wrapped),
+		Nonce:   base64.StdEncoding.EncodeToString(nonce),
+		Data:    base64.StdEncoding.EncodeToString(ciphertext),
+	})
+}
+
+// Open decrypts data sealed by Seal under name. Plaintext input is returned
+// unchanged so callers can read legacy files transparently.
+func Open(name string, data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	return OpenWith(Current(), name, data)
+}
+
+// OpenWith decrypts a record sealed under name using provider.
+func OpenWith(provider KeyProvider, name string, data []byte) ([]byte, error) {
+	if provider == nil {
+		return nil, ErrNoKey
+	}
+	var record sealedRecord
+	if errUnmarshal := json.Unmarshal(data, &record); errUnmarshal != nil {
+		return nil, fmt.Errorf("envelope: decode record: %w", errUnmarshal)
+	}
+	if record.Version != formatVersion {
+		return nil, fmt.Errorf("envelope: unsupported version %d", record.Version)
+	}
+	wrapped, errWrapped := base64.StdEncoding.DecodeString(record.DataKey)
+	nonce, errNonce := base64.StdEncoding.DecodeString(record.Nonce)
+	ciphertext, errData := base64.StdEncoding.DecodeString(record.Data)
+	if errDecode := errors.Join(errWrapped, errNonce, errData); errDecode != nil {
+		return nil, fmt.Errorf("envelope: decode record: %w", errDecode)
+	}
+	dataKey, errUnwrap := provider.UnwrapKey(record.KeyID, wrapped)
+	if errUnwrap != nil {
+		return nil, fmt.Errorf("envelope: unwrap data key: %w", errUnwrap)
+	}
+	return decrypt(dataKey, nonce, ciphertext, []byte(name))
+}
+
+// KeyIDOf returns the key id a sealed record was wrapped with, or "" for plaintext.
+func KeyIDOf(data []byte) string {
+	if !IsSealed(data) {
+		return ""
+	}
+	var record sealedRecord
+	_ = json.Unmarshal(data, &record)
+	return record.KeyID
+}
+
+// IsSealedString reports whether value is a sealed string.
+func IsSealedString(value string) bool {
+	return strings.HasPrefix(value, StringPrefix)
+}
+
+// SealString seals a secret string value bound to name as "enc:v1:<base64>".
+// Empty values and values that are already sealed are returned unchanged, as
+// is every value when encryption is off.
+func SealString(name, value string) (string, error) {
+	provider := Current()
+	if value == "" || IsSealedString(value) || provider == nil {
+		return value, nil
+	}
+	cacheKey := stringCacheKey(provider.KeyID(), name, value)
+	if cached, ok := sealedStrings.Load(cacheKey); ok {
+		return cached.(string), nil
+	}
+	sealed, errSeal := SealWith(provider, name, []byte(value))
+	if errSeal != nil {
+		return "", errSeal
+	}
+	result := StringPrefix + base64.RawURLEncoding.EncodeToString(sealed)
+	sealedStrings.Store(cacheKey, result)
+	return result, nil
+}
+
+// OpenString reverses SealString with the process-wide provider. Values
+// without the prefix are returned unchanged.
+func OpenString(name, value string) (string, error) {
+	return OpenStringWith(Current(), name, value)
+}
+
+// OpenStringWith reverses SealString using provider.
+func OpenStringWith(provider KeyProvider, name, value string) (string, error) {
+	if !IsSealedString(value) {
+		return value, nil
+	}
+	sealed, errDecode := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(value, StringPrefix))
+	if errDecode != nil {
+		return "", fmt.Errorf("envelope: decode sealed string: %w", errDecode)
+	}
+	plaintext, errOpen := OpenWith(provider, name, sealed)
+	if errOpen != nil {
+		return "", errOpen
+	}
+	sealedStrings.Store(stringCacheKey(KeyIDOf(sealed), name, string(plaintext)), value)
+	return string(plaintext), nil
+}
+
+func stringCacheKey(keyID, name, value string) string {
+	return keyID + "\x00" + name + "\x00" + value
+}
+
+func encrypt(key, plaintext, additionalData []byte) (nonce, ciphertext []byte, err error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce = make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, nil, fmt.Errorf("envelope: generate nonce: %w", err)
+	}
+	return nonce, aead.Seal(nil, nonce, plaintext, additionalData), nil
+}
+
+func decrypt(key, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("envelope: invalid nonce length %d", len(nonce))
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("envelope: decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("envelope: init cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("envelope: init gcm: %w", err)
+	}
+	return aead, nil
+}
@@ -0,0 +1,247 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func testKey(fill byte) []byte {
+	return bytes.Repeat([]byte{fill}, dataKeySize)
+}
+
+func configureForTest(t *testing.T, provider KeyProvider) {
+	t.Helper()
+	Configure(provider)
+	t.Cleanup(func() { Configure(nil) })
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	provider, errProvider := NewLocalKeyProvider(testKey(1))
+	if errProvider != nil {
+		t.Fatalf("NewLocalKeyProvider() error = %v", errProvider)
+	}
+	plaintext := []byte(`{"type":"codex","refresh_token":"rt-secret"}`)
+	sealed, errSeal := SealWith(provider, "codex.json", plaintext)
+	if errSeal != nil {
+		t.Fatalf("SealWith() error = %v", errSeal)
+	}
+	if bytes.Contains(sealed, []byte("rt-secret")) || !IsSealed(sealed) || !json.Valid(sealed) {
+		t.Fatalf("sealed = %s", sealed)
+	}
+	if KeyIDOf(sealed) != provider.KeyID() {
+		t.Fatalf("KeyIDOf() = %q, want %q", KeyIDOf(sealed), provider.KeyID())
+	}
+	opened, errOpen := OpenWith(provider, "codex.json", sealed)
+	if errOpen != nil || !bytes.Equal(opened, plaintext) {
+		t.Fatalf("OpenWith() = %s, %v", opened, errOpen)
+	}
+	// A record copied over another auth file must not open there.
+	if _, errOpen = OpenWith(provider, "claude.json", sealed); errOpen == nil {
+		t.Fatal("record opened under a different name")
+	}
+	if IsSealed(plaintext) {
+		t.Fatal("plaintext reported as sealed")
+	}
+}
+
+func TestOpenRequiresKey(t *testing.T) {
+	provider, _ := NewLocalKeyProvider(testKey(1))
+	sealed, _ := SealWith(provider, "a.json", []byte(`{}`))
+	configureForTest(t, nil)
+	if _, errOpen := Open("a.json", sealed); !errors.Is(errOpen, ErrNoKey) {
+		t.Fatalf("Open() error = %v, want ErrNoKey", errOpen)
+	}
+	other, _ := NewLocalKeyProvider(testKey(2))
+	if _, errOpen := OpenWith(other, "a.json", sealed); !errors.Is(errOpen, ErrUnknownKey) {
+		t.Fatalf("OpenWith(other) error = %v, want ErrUnknownKey", errOpen)
+	}
+}
+
+func TestSealDirMigratesAndRotates(t *testing.T) {
+	dir := t.TempDir()
+	plainPath := filepath.Join(dir, "codex.json")
+	if errWrite := os.WriteFile(plainPath, []byte(`{"type":"codex"}`), 0o600); errWrite != nil {
+		t.Fatal(errWrite)
+	}
+	if errWrite := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep"), 0o600); errWrite != nil {
+		t.Fatal(errWrite)
+	}
+
+	oldKey, _ := NewLocalKeyProvider(testKey(1))
+	configureForTest(t, oldKey)
+	changed, errSeal := SealDir(dir)
+	if errSeal != nil || len(changed) != 1 {
+		t.Fatalf("SealDir() = %v, %v", changed, errSeal)
+	}
+	raw, _ := os.ReadFile(plainPath)
+	if KeyIDOf(raw) != oldKey.KeyID() {
+		t.Fatalf("file not sealed with the old key: %s", raw)
+	}
+	if changed, _ = SealDir(dir); len(changed) != 0 {
+		t.Fatalf("second SealDir() rewrote %v", changed)
+	}
+
+	rotated, _ := NewLocalKeyProvider(testKey(2), testKey(1))
+	Configure(rotated)
+	if changed, errSeal = SealDir(dir); errSeal != nil || len(changed) != 1 {
+		t.Fatalf("rotation SealDir() = %v, %v", changed, errSeal)
+	}
+	raw, _ = os.ReadFile(plainPath)
+	if KeyIDOf(raw) != rotated.KeyID() {
+		t.Fatalf("file not re-sealed with the new key: %s", raw)
+	}
+	opened, errRead := ReadFile(plainPath)
+	if errRead != nil || string(opened) != `{"type":"codex"}` {
+		t.Fatalf("ReadFile() = %s, %v", opened, errRead)
+	}
+	if notes, _ := os.ReadFile(filepath.Join(dir, "notes.txt")); string(notes) != "keep" {
+		t.Fatalf("non-json file was touched: %q", notes)
+	}
+}
+
+type memoryTokenStorage struct {
+	t       *testing.T
+	payload []byte
+}
+
+func (s *memoryTokenStorage) SaveTokenToFile(string) error {
+	s.t.Fatal("plaintext SaveTokenToFile used while encryption is enabled")
+	return nil
+}
+
+func (s *memoryTokenStorage) MarshalToken() ([]byte, error) { return s.payload, nil }
+
+type fileOnlyTokenStorage struct{}
+
+func (fileOnlyTokenStorage) SaveTokenToFile(string) error { return nil }
+
+func TestSaveTokenSealsInMemory(t *testing.T) {
+	provider, _ := NewLocalKeyProvider(testKey(3))
+	configureForTest(t, provider)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "claude.json")
+	storage := &memoryTokenStorage{t: t, payload: []byte(`{"access_token":"at"}`)}
+	if errSave := SaveToken(path, storage); errSave != nil {
+		t.Fatalf("SaveToken() error = %v", errSave)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("temporary files left behind: %v", entries)
+	}
+	raw, _ := os.ReadFile(path)
+	if !IsSealed(raw) || bytes.Contains(raw, []byte(`"at"`)) {
+		t.Fatalf("file not sealed: %s", raw)
+	}
+	if errSave := SaveToken(filepath.Join(dir, "other.json"), fileOnlyTokenStorage{}); errSave == nil {
+		t.Fatal("storage without MarshalToken saved while encryption is enabled")
+	}
+}
+
+func TestWriteFileConcurrentWritersUseOwnTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "auth.json")
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- WriteFile(path, []byte(strings.Repeat(string(rune('a'+i)), 4096)), 0o640)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for errWrite := range errs {
+		if errWrite != nil {
+			t.Fatalf("WriteFile() error = %v", errWrite)
+		}
+	}
+	data, errRead := os.ReadFile(path)
+	if errRead != nil {
+		t.Fatalf("ReadFile() error = %v", errRead)
+	}
+	if len(data) != 4096 || strings.Count(string(data), string(data[:1])) != 4096 {
+		t.Fatalf("file holds a mix of writes: %q...", data[:16])
+	}
+	info, errStat := os.Stat(path)
+	if errStat != nil {
+		t.Fatalf("Stat() error = %v", errStat)
+	}
+	if perm := info.Mode().Perm(); perm != 0o640 {
+		t.Fatalf("perm = %o, want 640", perm)
+	}
+	entries, errDir := os.ReadDir(dir)
+	if errDir != nil {
+		t.Fatalf("ReadDir() error = %v", errDir)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("dir entries = %d, want only auth.json", len(entries))
+	}
+}
+
+func TestSealStringIsStable(t *testing.T) {
+	provider, _ := NewLocalKeyProvider(testKey(4))
+	configureForTest(t, provider)
+	sealed, errSeal := SealString("config/claude-api-key", "sk-secret")
+	if errSeal != nil || !IsSealedString(sealed) {
+		t.Fatalf("SealString() = %q, %v", sealed, errSeal)
+	}
+	again, _ := SealString("config/claude-api-key", "sk-secret")
+	if again != sealed {
+		t.Fatal("sealing an unchanged value produced new ciphertext")
+	}
+	opened, errOpen := OpenString("config/claude-api-key", sealed)
+	if errOpen != nil || opened != "sk-secret" {
+		t.Fatalf("OpenString() = %q, %v", opened, errOpen)
+	}
+	if _, errOpen = OpenString("config/api-keys", sealed); errOpen == nil {
+		t.Fatal("secret opened under a different field")
+	}
+}
+
+func TestLocalKMSKeyring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	keyring := map[string]any{
+		"primary": "k2",
+		"keys": map[string]string{
+			"k1": base64.StdEncoding.EncodeToString(testKey(1)),
+			"k2": base64.StdEncoding.EncodeToString(testKey(2)),
+		},
+	}
+	raw, _ := json.Marshal(keyring)
+	if errWrite := os.WriteFile(path, raw, 0o600); errWrite != nil {
+		t.Fatal(errWrite)
+	}
+	provider, errKMS := NewKMS(LocalKMS, map[string]string{"keyring": path})
+	if errKMS != nil || provider.KeyID() != "k2" {
+		t.Fatalf("NewKMS() = %v, %v", provider, errKMS)
+	}
+	if _, errKMS = NewKMS("missing", nil); errKMS == nil {
+		t.Fatal("unknown kms accepted")
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	key := testKey(7)
+	for _, encoded := range []string{
+		base64.StdEncoding.EncodeToString(key),
+		base64.RawURLEncoding.EncodeToString(key),
+		"  " + strings.ToUpper(hex.EncodeToString(key)) + "\n",
+	} {
+		parsed, errParse := ParseKey(encoded)
+		if errParse != nil || !bytes.Equal(parsed, key) {
+			t.Fatalf("ParseKey(%q) = %x, %v", encoded, parsed, errParse)
+		}
+	}
+	if _, errParse := ParseKey("c2hvcnQ="); errParse == nil {
+		t.Fatal("short key accepted")
+	}
+}
@@ -0,0 +1,170 @@
+package envelope
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadFile reads path and opens it when it is sealed.
+func ReadFile(path string) ([]byte, error) {
+	data, errRead := os.ReadFile(path)
+	if errRead != nil {
+		return nil, errRead
+	}
+	plaintext, errOpen := Open(RecordName(path), data)
+	if errOpen != nil {
+		return nil, fmt.Errorf("%s: %w", path, errOpen)
+	}
+	return plaintext, nil
+}
+
+// WriteFile seals data, bound to the base name of path, when encryption is on
+// and writes it to path through a temporary file and rename.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	sealed, errSeal := Seal(RecordName(path), data)
+	if errSeal != nil {
+		return errSeal
+	}
+	return writeAtomic(path, sealed, perm)
+}
+
+// NeedsReseal reports whether stored data differs from what a write would
+// produce under the current settings: plaintext while encryption is on, or
+// sealed with a key other than the current primary key.
+func NeedsReseal(data []byte) bool {
+	provider := Current()
+	if provider == nil {
+		return false
+	}
+	return KeyIDOf(data) != provider.KeyID()
+}
+
+// TokenStorage is the subset of the auth TokenStorage interface SaveToken
+// needs.
+type TokenStorage interface {
+	SaveTokenToFile(path string) error
+}
+
+// TokenMarshaler is implemented by token storages that can render their file
+// content in memory.
+type TokenMarshaler interface {
+	MarshalToken() ([]byte, error)
+}
+
+// SaveToken persists storage at path. When encryption is off the storage
+// writes the file itself. When it is on the content is rendered with
+// MarshalToken and sealed in memory, so plaintext never touches disk; storages
+// that cannot render in memory are refused. An empty rendering writes nothing.
+func SaveToken(path string, storage TokenStorage) error {
+	if !Enabled() {
+		return storage.SaveTokenToFile(path)
+	}
+	marshaler, ok := storage.(TokenMarshaler)
+	if !ok {
+		return fmt.Errorf("envelope: %T cannot be saved encrypted", storage)
+	}
+	plaintext, errMarshal := marshaler.MarshalToken()
+	if errMarshal != nil {
+		return errMarshal
+	}
+	if len(plaintext) == 0 {
+		return nil
+	}
+	if errMkdir := os.MkdirAll(filepath.Dir(path), 0o700); errMkdir != nil {
+		return fmt.Errorf("envelope: create directory: %w", errMkdir)
+	}
+	return WriteFile(path, plaintext, 0o600)
+}
+
+// SealFile rewrites path under the current key when NeedsReseal reports it is
+// stale. It reports whether the file changed.
+func SealFile(path string) (bool, error) {
+	data, errRead := os.ReadFile(path)
+	if errRead != nil {
+		return false, errRead
+	}
+	if len(data) == 0 || !NeedsReseal(data) {
+		return false, nil
+	}
+	plaintext, errOpen := Open(RecordName(path), data)
+	if errOpen != nil {
+		return false, fmt.Errorf("%s: %w", path, errOpen)
+	}
+	info, errStat := os.Stat(path)
+	if errStat != nil {
+		return false, errStat
+	}
+	if errWrite := WriteFile(path, plaintext, info.Mode().Perm()); errWrite != nil {
+		return false, errWrite
+	}
+	return true, nil
+}
+
+// SealDir applies SealFile to every .json file under dir. It migrates legacy
+// plaintext files and re-seals files wrapped by an older key, returning the
+// paths it rewrote.
+func SealDir(dir string) ([]string, error) {
+	if !Enabled() {
+		return nil, nil
+	}
+	var changed []string
+	errWalk := filepath.WalkDir(dir, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(strings.ToLower(d.Name()), ".json") {
+			return nil
+		}
+		rewritten, errSeal := SealFile(path)
+		if errSeal != nil {
+			return errSeal
+		}
+		if rewritten {
+			changed = append(changed, path)
+		}
+		return nil
+	})
+	if errWalk != nil && !os.IsNotExist(errWalk) {
+		return changed, errWalk
+	}
+	return changed, nil
+}
+
+// writeAtomic writes data to a temporary file of its own in the directory of
+// path, syncs it and renames it over path, so concurrent writers never share a
+// temporary file.
+func writeAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, errCreate := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if errCreate != nil {
+		return fmt.Errorf("envelope: create temp file: %w", errCreate)
+	}
+	tmpPath := tmp.Name()
+	errWrite := tmp.Chmod(perm)
+	if errWrite == nil {
+		_, errWrite = tmp.Write(data)
+	}
+	if errWrite == nil {
+		errWrite = tmp.Sync()
+	}
+	if errClose := tmp.Close(); errWrite == nil {
+		errWrite = errClose
+	}
+	if errWrite != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("envelope: write temp file: %w", errWrite)
+	}
+	if errRename := os.Rename(tmpPath, path); errRename != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("envelope: rename temp file: %w", errRename)
+	}
+	return nil
+}
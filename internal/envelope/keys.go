@@ -0,0 +1,211 @@
+package envelope
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// KeyProvider wraps and unwraps data keys. It is the extension point for key
+// management services: a KMS-backed provider keeps the master key remote and
+// only ever sees data keys.
+type KeyProvider interface {
+	// KeyID names the key new data keys are wrapped with.
+	KeyID() string
+	// WrapKey encrypts a data key with the current key.
+	WrapKey(dataKey []byte) ([]byte, error)
+	// UnwrapKey decrypts a data key wrapped by the key named keyID.
+	UnwrapKey(keyID string, wrapped []byte) ([]byte, error)
+}
+
+// LocalKeyProvider wraps data keys with AES-256-GCM master keys held in
+// memory. Previous keys are kept for unwrapping so data sealed before a
+// rotation can still be read.
+type LocalKeyProvider struct {
+	primary string
+	keys    map[string][]byte
+}
+
+// NewLocalKeyProvider builds a provider whose primary key is primary. Key ids
+// are derived from the key material.
+func NewLocalKeyProvider(primary []byte, previous ...[]byte) (*LocalKeyProvider, error) {
+	keys := make(map[string][]byte, len(previous)+1)
+	for _, key := range previous {
+		keys[LocalKeyID(key)] = key
+	}
+	primaryID := LocalKeyID(primary)
+	keys[primaryID] = primary
+	return NewLocalKeyring(primaryID, keys)
+}
+
+// NewLocalKeyring builds a provider from named keys. primaryID selects the key
+// used for new data.
+func NewLocalKeyring(primaryID string, keys map[string][]byte) (*LocalKeyProvider, error) {
+	if _, ok := keys[primaryID]; !ok {
+		return nil, fmt.Errorf("envelope: primary key %q is not in the keyring", primaryID)
+	}
+	copied := make(map[string][]byte, len(keys))
+	for id, key := range keys {
+		if len(key) != dataKeySize {
+			return nil, fmt.Errorf("envelope: key %q must be %d bytes, got %d", id, dataKeySize, len(key))
+		}
+		copied[id] = append([]byte(nil), key...)
+	}
+	return &LocalKeyProvider{primary: primaryID, keys: copied}, nil
+}
+
+// LocalKeyID derives a stable, non-secret id for a master key.
+func LocalKeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return "local-" + hex.EncodeToString(sum[:6])
+}
+
+// KeyID implements KeyProvider.
+func (p *LocalKeyProvider) KeyID() string { return p.primary }
+
+// KeyIDs returns every key id the provider can unwrap, sorted.
+func (p *LocalKeyProvider) KeyIDs() []string {
+	ids := make([]string, 0, len(p.keys))
+	for id := range p.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// WrapKey implements KeyProvider. The result is nonce || ciphertext.
+func (p *LocalKeyProvider) WrapKey(dataKey []byte) ([]byte, error) {
+	nonce, ciphertext, errEncrypt := encrypt(p.keys[p.primary], dataKey, []byte(p.primary))
+	if errEncrypt != nil {
+		return nil, errEncrypt
+	}
+	return append(nonce, ciphertext...), nil
+}
+
+// UnwrapKey implements KeyProvider.
+func (p *LocalKeyProvider) UnwrapKey(keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+	aead, errAEAD := newAEAD(key)
+	if errAEAD != nil {
+		return nil, errAEAD
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, fmt.Errorf("envelope: wrapped key too short")
+	}
+	return decrypt(key, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], []byte(keyID))
+}
+
+// ParseKey decodes a 32-byte master key given as base64 (standard or URL
+// alphabet) or hex.
+func ParseKey(raw string) ([]byte, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("envelope: master key is empty")
+	}
+	decoders := []func(string) ([]byte, error){
+		hex.DecodeString,
+		base64.StdEncoding.DecodeString,
+		base64.RawStdEncoding.DecodeString,
+		base64.URLEncoding.DecodeString,
+		base64.RawURLEncoding.DecodeString,
+	}
+	for _, decode := range decoders {
+		if key, errDecode := decode(raw); errDecode == nil && len(key) == dataKeySize {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("envelope: master key must be %d bytes encoded as base64 or hex", dataKeySize)
+}
+
+// LoadKeyFile reads a master key from path.
+func LoadKeyFile(path string) ([]byte, error) {
+	data, errRead := os.ReadFile(path)
+	if errRead != nil {
+		return nil, fmt.Errorf("envelope: read key file: %w", errRead)
+	}
+	key, errParse := ParseKey(string(data))
+	if errParse != nil {
+		return nil, fmt.Errorf("%w (%s)", errParse, path)
+	}
+	return key, nil
+}
+
+// KMSFactory builds a KeyProvider from the kms-options of the config.
+type KMSFactory func(options map[string]string) (KeyProvider, error)
+
+var (
+	kmsMu        sync.RWMutex
+	kmsFactories = map[string]KMSFactory{}
+)
+
+// LocalKMS is the name of the built-in KMS stand-in backed by a keyring file.
+const LocalKMS = "local"
+
+func init() {
+	RegisterKMS(LocalKMS, newLocalKMS)
+}
+
+// RegisterKMS registers a KMS factory under name, replacing any previous one.
+func RegisterKMS(name string, factory KMSFactory) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || factory == nil {
+		return
+	}
+	kmsMu.Lock()
+	kmsFactories[name] = factory
+	kmsMu.Unlock()
+}
+
+// NewKMS builds the KeyProvider registered under name.
+func NewKMS(name string, options map[string]string) (KeyProvider, error) {
+	kmsMu.RLock()
+	factory, ok := kmsFactories[strings.ToLower(strings.TrimSpace(name))]
+	kmsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("envelope: unknown kms %q", name)
+	}
+	return factory(options)
+}
+
+// localKeyringFile is the format read by the "local" KMS:
+//
+//	{"primary": "2026-10", "keys": {"2026-10": "<base64>", "2026-04": "<base64>"}}
+type localKeyringFile struct {
+	Primary string            `json:"primary"`
+	Keys    map[string]string `json:"keys"`
+}
+
+// newLocalKMS loads the keyring named by the "keyring" option. It stands in
+// for a remote KMS in development and single-host deployments.
+func newLocalKMS(options map[string]string) (KeyProvider, error) {
+	path := strings.TrimSpace(options["keyring"])
+	if path == "" {
+		return nil, fmt.Errorf("envelope: local kms requires the keyring option")
+	}
+	data, errRead := os.ReadFile(path)
+	if errRead != nil {
+		return nil, fmt.Errorf("envelope: read keyring: %w", errRead)
+	}
+	var file localKeyringFile
+	if errUnmarshal := json.Unmarshal(data, &file); errUnmarshal != nil {
+		return nil, fmt.Errorf("envelope: parse keyring: %w", errUnmarshal)
+	}
+	keys := make(map[string][]byte, len(file.Keys))
+	for id, encoded := range file.Keys {
+		key, errParse := ParseKey(encoded)
+		if errParse != nil {
+			return nil, fmt.Errorf("envelope: keyring key %q: %w", id, errParse)
+		}
+		keys[id] = key
+	}
+	return NewLocalKeyring(file.Primary, keys)
+}
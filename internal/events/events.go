@@ -0,0 +1,241 @@
+// Package events fans out management events (credential state, config reloads,
+// model catalog refreshes and cooldowns, plugin lifecycle, request completions
+// and client budgets) to live subscribers and keeps a bounded backlog so
+// clients can resume from the last event they saw.
+package events
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event types published on the management event stream.
+const (
+	TypeAuthStatus           = "auth.status"
+	TypeCooldownEntered      = "auth.cooldown.entered"
+	TypeCooldownExited       = "auth.cooldown.exited"
+	TypeAuthRefresh          = "auth.refresh"
+	TypeAuthHealth           = "auth.health"
+	TypeConfigReloaded       = "config.reloaded"
+	TypeConfigReloadFailed   = "config.reload_failed"
+	TypeModelsRefreshed      = "models.refreshed"
+	TypeModelsCooling        = "models.cooling"
+	TypePluginLoaded         = "plugin.loaded"
+	TypePluginUnloaded       = "plugin.unloaded"
+	TypePluginCrashed        = "plugin.crashed"
+	TypePluginRolledBack     = "plugin.rolled_back"
+	TypeRequestCompleted     = "request.completed"
+	TypeClientBudgetExceeded = "client.budget.exceeded"
+)
+
+const (
+	defaultBacklog     = 1024
+	subscriberBuffer   = 256
+	maxFilterTypeCount = 32
+)
+
+// Event is one entry on the management event stream. Data is marshaled when the
+// event is published so later changes to the source value are not observed.
+type Event struct {
+	ID   uint64          `json:"id"`
+	Type string          `json:"type"`
+	Time time.Time       `json:"time"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// Filter selects event types. An entry matches its exact type and every type
+// below it, so "auth" matches "auth.status" and "auth.cooldown.entered".
+// An empty filter matches everything.
+type Filter struct {
+	Types []string
+}
+
+// ParseFilter reads a comma-separated list of event types. A trailing ".*" is
+// accepted and treated like the bare prefix.
+func ParseFilter(raw string) Filter {
+	var filter Filter
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSuffix(strings.TrimSpace(part), ".*")
+		if part == "" || part == "*" {
+			continue
+		}
+		filter.Types = append(filter.Types, part)
+		if len(filter.Types) >= maxFilterTypeCount {
+			break
+		}
+	}
+	return filter
+}
+
+// Match reports whether eventType passes the filter.
+func (f Filter) Match(eventType string) bool {
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, want := range f.Types {
+		if eventType == want || strings.HasPrefix(eventType, want+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription receives live events. C is closed when the subscription is
+// closed or when the subscriber falls too far behind; Lagged reports the latter
+// so the client can reconnect and resume from its last event ID.
+type Subscription struct {
+	C <-chan Event
+
+	bus    *Bus
+	ch     chan Event
+	filter Filter
+	lagged bool
+	closed bool
+}
+
+// Lagged reports whether the subscription was dropped for falling behind.
+func (s *Subscription) Lagged() bool {
+	if s == nil || s.bus == nil {
+		return false
+	}
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	return s.lagged
+}
+
+// Close detaches the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	if s == nil || s.bus == nil {
+		return
+	}
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.removeLocked(s)
+}
+
+// Bus keeps the event backlog and the live subscribers.
+type Bus struct {
+	mu          sync.Mutex
+	nextID      uint64
+	backlog     []Event
+	head        int
+	size        int
+	subscribers map[*Subscription]struct{}
+}
+
+// NewBus returns a bus that retains the last capacity events for resumption.
+func NewBus(capacity int) *Bus {
+	if capacity <= 0 {
+		capacity = defaultBacklog
+	}
+	return &Bus{
+		backlog:     make([]Event, capacity),
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish marshals data and delivers the event to matching subscribers.
+// Events whose data cannot be marshaled are dropped.
+func (b *Bus) Publish(eventType string, data any) (Event, bool) {
+	if b == nil || strings.TrimSpace(eventType) == "" {
+		return Event{}, false
+	}
+	var payload json.RawMessage
+	if data != nil {
+		raw, errMarshal := json.Marshal(data)
+		if errMarshal != nil {
+			return Event{}, false
+		}
+		payload = raw
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	event := Event{ID: b.nextID, Type: eventType, Time: time.Now().UTC(), Data: payload}
+	index := (b.head + b.size) % len(b.backlog)
+	if b.size == len(b.backlog) {
+		b.head = (b.head + 1) % len(b.backlog)
+	} else {
+		b.size++
+	}
+	b.backlog[index] = event
+
+	for sub := range b.subscribers {
+		if !sub.filter.Match(eventType) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			sub.lagged = true
+			b.removeLocked(sub)
+		}
+	}
+	return event, true
+}
+
+// Subscribe registers a subscriber. When resume is set it also returns the
+// retained events after afterID that match filter, and gap reports that some
+// of those events are no longer retained. Without resume only live events are
+// delivered.
+func (b *Bus) Subscribe(filter Filter, afterID uint64, resume bool) (sub *Subscription, backlog []Event, gap bool) {
+	if b == nil {
+		return nil, nil, false
+	}
+	ch := make(chan Event, subscriberBuffer)
+	sub = &Subscription{C: ch, bus: b, ch: ch, filter: filter}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if resume {
+		// An ID beyond the last published event comes from before a restart;
+		// replay everything retained and report the gap.
+		if afterID > b.nextID {
+			afterID, gap = 0, true
+		}
+		oldest := b.nextID - uint64(b.size) + 1
+		gap = gap || afterID+1 < oldest
+		for i := 0; i < b.size; i++ {
+			event := b.backlog[(b.head+i)%len(b.backlog)]
+			if event.ID > afterID && filter.Match(event.Type) {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+	b.subscribers[sub] = struct{}{}
+	return sub, backlog, gap
+}
+
+// LastID returns the ID of the most recent event.
+func (b *Bus) LastID() uint64 {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.nextID
+}
+
+func (b *Bus) removeLocked(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(b.subscribers, sub)
+	close(sub.ch)
+}
+
+var defaultBus = NewBus(defaultBacklog)
+
+// Default returns the process-wide event bus.
+func Default() *Bus {
+	return defaultBus
+}
+
+// Publish publishes an event on the process-wide bus.
+func Publish(eventType string, data any) {
+	defaultBus.Publish(eventType, data)
+}
@@ -0,0 +1,102 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFilterMatchesTypesAndPrefixes(t *testing.T) {
+	filter := ParseFilter(" auth.cooldown.* , config.reloaded,,")
+	cases := map[string]bool{
+		TypeCooldownEntered:  true,
+		TypeCooldownExited:   true,
+		TypeConfigReloaded:   true,
+		TypeAuthStatus:       false,
+		TypeRequestCompleted: false,
+		"auth.cooldownx":     false,
+	}
+	for eventType, want := range cases {
+		if got := filter.Match(eventType); got != want {
+			t.Fatalf("Match(%q) = %v, want %v", eventType, got, want)
+		}
+	}
+	if !ParseFilter("").Match(TypePluginLoaded) || !ParseFilter("*").Match(TypePluginLoaded) {
+		t.Fatal("empty filter should match every event type")
+	}
+}
+
+func TestBusDeliversMatchingEventsToSubscribers(t *testing.T) {
+	bus := NewBus(8)
+	sub, backlog, gap := bus.Subscribe(ParseFilter("plugin"), 0, false)
+	defer sub.Close()
+	if len(backlog) != 0 || gap {
+		t.Fatalf("Subscribe(0) backlog = %v gap = %v, want none without resume", backlog, gap)
+	}
+
+	bus.Publish(TypeAuthStatus, map[string]string{"auth_id": "a"})
+	published, ok := bus.Publish(TypePluginLoaded, map[string]string{"id": "p"})
+	if !ok {
+		t.Fatal("Publish() failed")
+	}
+
+	event := <-sub.C
+	if event.ID != published.ID || event.Type != TypePluginLoaded {
+		t.Fatalf("event = %+v, want %+v", event, published)
+	}
+	var data map[string]string
+	if err := json.Unmarshal(event.Data, &data); err != nil || data["id"] != "p" {
+		t.Fatalf("event data = %s, err = %v", event.Data, err)
+	}
+	select {
+	case extra := <-sub.C:
+		t.Fatalf("unexpected event %+v", extra)
+	default:
+	}
+}
+
+func TestBusResumesFromEventID(t *testing.T) {
+	bus := NewBus(3)
+	for i := 0; i < 5; i++ {
+		bus.Publish(TypeAuthRefresh, i)
+	}
+
+	sub, backlog, gap := bus.Subscribe(Filter{}, 3, true)
+	sub.Close()
+	if gap {
+		t.Fatal("gap = true, want false for a retained event id")
+	}
+	if len(backlog) != 2 || backlog[0].ID != 4 || backlog[1].ID != 5 {
+		t.Fatalf("backlog = %+v, want events 4 and 5", backlog)
+	}
+
+	sub, backlog, gap = bus.Subscribe(Filter{}, 1, true)
+	sub.Close()
+	if !gap || len(backlog) != 3 || backlog[0].ID != 3 {
+		t.Fatalf("backlog = %+v gap = %v, want events 3-5 with a gap", backlog, gap)
+	}
+
+	sub, backlog, gap = bus.Subscribe(Filter{}, 99, true)
+	sub.Close()
+	if !gap || len(backlog) != 3 {
+		t.Fatalf("backlog = %+v gap = %v, want full replay with a gap after a restart", backlog, gap)
+	}
+}
+
+func TestBusDropsLaggingSubscriber(t *testing.T) {
+	bus := NewBus(4)
+	sub, _, _ := bus.Subscribe(Filter{}, 0, false)
+	for i := 0; i < subscriberBuffer+1; i++ {
+		bus.Publish(TypeRequestCompleted, i)
+	}
+	drained := 0
+	for range sub.C {
+		drained++
+	}
+	if drained != subscriberBuffer {
+		t.Fatalf("drained %d events, want %d", drained, subscriberBuffer)
+	}
+	if !sub.Lagged() {
+		t.Fatal("Lagged() = false, want true")
+	}
+	sub.Close()
+}
@@ -0,0 +1,62 @@
+package events
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	internallogging "github.com/router-for-me/CLIProxyAPI/v7/internal/logging"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/usage"
+)
+
+func init() {
+	coreusage.RegisterPlugin(&requestCompletionPlugin{})
+}
+
+// RequestCompletion summarizes one finished upstream request.
+type RequestCompletion struct {
+	RequestID    string    `json:"request_id,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+	Provider     string    `json:"provider,omitempty"`
+	Model        string    `json:"model,omitempty"`
+	Alias        string    `json:"alias,omitempty"`
+	AuthID       string    `json:"auth_id,omitempty"`
+	AuthIndex    string    `json:"auth_index,omitempty"`
+	Failed       bool      `json:"failed"`
+	StatusCode   int       `json:"status_code,omitempty"`
+	LatencyMs    int64     `json:"latency_ms"`
+	TTFTMs       int64     `json:"ttft_ms,omitempty"`
+	InputTokens  int64     `json:"input_tokens"`
+	OutputTokens int64     `json:"output_tokens"`
+	TotalTokens  int64     `json:"total_tokens"`
+}
+
+// requestCompletionPlugin publishes request.completed events from usage records.
+type requestCompletionPlugin struct{}
+
+func (p *requestCompletionPlugin) HandleUsage(ctx context.Context, record coreusage.Record) {
+	if p == nil {
+		return
+	}
+	timestamp := record.RequestedAt
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	detail := coreusage.EnsureTokenBreakdownForProvider(record.Detail, record.Provider, record.ExecutorType)
+	Publish(TypeRequestCompleted, RequestCompletion{
+		RequestID:    strings.TrimSpace(internallogging.GetRequestID(ctx)),
+		Timestamp:    timestamp.UTC(),
+		Provider:     strings.TrimSpace(record.Provider),
+		Model:        strings.TrimSpace(record.Model),
+		Alias:        strings.TrimSpace(record.Alias),
+		AuthID:       strings.TrimSpace(record.AuthID),
+		AuthIndex:    strings.TrimSpace(record.AuthIndex),
+		Failed:       record.Failed,
+		StatusCode:   record.Fail.StatusCode,
+		LatencyMs:    record.Latency.Milliseconds(),
+		TTFTMs:       record.TTFT.Milliseconds(),
+		InputTokens:  detail.InputTokens,
+		OutputTokens: detail.OutputTokens,
+		TotalTokens:  detail.TotalTokens,
+	})
+}
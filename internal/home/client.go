@@ -34,6 +34,7 @@ const (
 	redisKeyConcurrencyRelease = "concurrency-release"
 	redisKeyRequestLog         = "request-log"
 	redisKeyAppLog             = "app-log"
+	redisKeyAuditLog           = "audit-log"
 	redisKeyPluginStatus       = "plugin-status"
 	redisKeyPluginTasks        = "plugin-tasks"
 	redisKeyPluginSync         = "plugin-sync"
@@ -1514,6 +1515,17 @@ func (c *Client) RPushAppLog(ctx context.Context, payload []byte) error {
 	return cmd.RPush(ctx, redisKeyAppLog, payload).Err()
 }
 
+func (c *Client) RPushAuditLog(ctx context.Context, payload []byte) error {
+	cmd, errClient := c.commandClient()
+	if errClient != nil {
+		return errClient
+	}
+	if len(payload) == 0 {
+		return nil
+	}
+	return cmd.RPush(ctx, redisKeyAuditLog, payload).Err()
+}
+
 func (c *Client) RPushPluginStatus(ctx context.Context, payload []byte) error {
 	cmd, errClient := c.commandClient()
 	if errClient != nil {
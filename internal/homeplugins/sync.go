@@ -33,6 +33,12 @@ type PluginLoadInspector interface {
 	PluginRegistered(id string) bool
 }
 
+// pluginVersionInspector is implemented by runtimes that can report which
+// version is active, so an upgrade that rolled back is not reported as loaded.
+type pluginVersionInspector interface {
+	PluginRegisteredVersion(id string) (string, bool)
+}
+
 type contextualPluginUnloader interface {
 	UnloadPluginContext(ctx context.Context, id string) bool
 }
@@ -83,6 +89,7 @@ const (
 	pluginInstallStatusMissing   = "missing"
 	pluginLoadStatusLoaded       = "loaded"
 	pluginLoadStatusFailed       = "failed"
+	pluginLoadStatusRolledBack   = "rolled_back"
 )
 
 // CurrentPlatform reports the platform used by pluginhost discovery.
@@ -173,7 +180,7 @@ func SyncPlatformWithReport(ctx context.Context, cfg *config.Config, pluginRunti
 			continue
 		}
 		status := pluginStatusFromManifest(manifest)
-		result, errSync := installManifest(ctx, client, manifest, root, platform, pluginRuntime)
+		result, errSync := installManifest(ctx, cfg, client, manifest, root, platform, pluginRuntime)
 		if errSync != nil {
 			status.InstallStatus = pluginInstallStatusFailed
 			status.Error = errSync.Error()
@@ -319,7 +326,7 @@ func upsertPluginInstallStatus(report *SyncReport, status PluginInstallStatus) {
 func installResolvedManifest(ctx context.Context, cfg *config.Config, manifest sdkpluginstore.Manifest, auth []sdkpluginstore.ResolvedAuthConfig, expiresAt time.Time, root string, platform Platform, pluginRuntime PluginRuntime) (sdkpluginstore.InstallResult, error) {
 	client := newResolvedPluginStoreClient(cfg, auth, expiresAt)
 	defer client.ClearAuth()
-	return installManifest(ctx, client, manifest, root, platform, pluginRuntime)
+	return installManifest(ctx, cfg, client, manifest, root, platform, pluginRuntime)
 }
 
 func InstalledVersions(cfg *config.Config) (map[string]string, error) {
@@ -347,11 +354,18 @@ func InstalledVersions(cfg *config.Config) (map[string]string, error) {
 	return versions, nil
 }
 
-func installManifest(ctx context.Context, client sdkpluginstore.Client, manifest sdkpluginstore.Manifest, root string, platform Platform, pluginRuntime PluginRuntime) (sdkpluginstore.InstallResult, error) {
+func installManifest(ctx context.Context, cfg *config.Config, client sdkpluginstore.Client, manifest sdkpluginstore.Manifest, root string, platform Platform, pluginRuntime PluginRuntime) (sdkpluginstore.InstallResult, error) {
 	id := strings.TrimSpace(manifest.ID)
 	if id == "" {
 		return sdkpluginstore.InstallResult{}, fmt.Errorf("home plugins: manifest plugin id is empty")
 	}
+	// Plugins synced from Home go through the same publisher checks as store
+	// installs; without a matching store-trust entry unsigned artifacts are refused.
+	var storeTrust []sdkpluginstore.TrustConfig
+	if cfg != nil {
+		storeTrust = cfg.Plugins.StoreTrust
+	}
+	client.SetTrust(sdkpluginstore.TrustPolicyForSource(storeTrust, manifestSource(manifest)))
 	pluginIsBusy := func() bool {
 		return pluginRuntime != nil && pluginRuntime.PluginBusy(id)
 	}
@@ -367,6 +381,15 @@ func installManifest(ctx context.Context, client sdkpluginstore.Client, manifest
 	return result, nil
 }
 
+// manifestSource returns the store source a manifest was resolved from.
+func manifestSource(manifest sdkpluginstore.Manifest) sdkpluginstore.Source {
+	return sdkpluginstore.Source{
+		ID:   strings.TrimSpace(manifest.SourceID),
+		Name: strings.TrimSpace(manifest.SourceName),
+		URL:  strings.TrimSpace(manifest.SourceURL),
+	}
+}
+
 func DeleteWithReport(ctx context.Context, cfg *config.Config, pluginRuntime PluginRuntime, taskID uint, pluginID string) SyncReport {
 	if ctx == nil {
 		ctx = context.Background()
@@ -700,7 +723,17 @@ func MarkLoadResults(report *SyncReport, inspector PluginLoadInspector) error {
 			continue
 		}
 		if inspector != nil && inspector.PluginRegistered(status.ID) {
-			status.LoadStatus = pluginLoadStatusLoaded
+			activeVersion, rolledBack := rolledBackPluginVersion(inspector, *status)
+			if !rolledBack {
+				status.LoadStatus = pluginLoadStatusLoaded
+				continue
+			}
+			status.LoadStatus = pluginLoadStatusRolledBack
+			errRollback := fmt.Errorf("home plugins: plugin %s %s failed to load, %s remains active", status.ID, status.Version, activeVersion)
+			if strings.TrimSpace(status.Error) == "" {
+				status.Error = errRollback.Error()
+			}
+			loadErrors = append(loadErrors, errRollback)
 			continue
 		}
 		status.LoadStatus = pluginLoadStatusFailed
@@ -715,6 +748,25 @@ func MarkLoadResults(report *SyncReport, inspector PluginLoadInspector) error {
 	return errLoad
 }
 
+// rolledBackPluginVersion reports the active version when it is not the one the
+// sync installed, which happens when the runtime rolled an upgrade back.
+func rolledBackPluginVersion(inspector PluginLoadInspector, status PluginInstallStatus) (string, bool) {
+	versions, ok := inspector.(pluginVersionInspector)
+	if !ok {
+		return "", false
+	}
+	installed := strings.TrimPrefix(strings.TrimSpace(status.Version), "v")
+	if installed == "" {
+		return "", false
+	}
+	active, okActive := versions.PluginRegisteredVersion(status.ID)
+	active = strings.TrimPrefix(strings.TrimSpace(active), "v")
+	if !okActive || active == "" || active == installed {
+		return "", false
+	}
+	return active, true
+}
+
 func newSyncReport(platform Platform) SyncReport {
 	now := time.Now().UTC()
 	return SyncReport{
@@ -85,6 +85,36 @@ func TestSyncPlatformInstallsManifestArtifact(t *testing.T) {
 	}
 }
 
+func TestSyncPlatformRefusesUnsignedArtifactWithoutTrust(t *testing.T) {
+	root := t.TempDir()
+	archiveData := makeZip(t, map[string]string{"sample.dll": "library-data"})
+	archiveName := "sample_0.2.0_windows_amd64.zip"
+	checksum := sha256.Sum256(archiveData)
+	httpClient := mapHTTPDoer{
+		"https://api.github.com/repos/owner/sample-plugin/releases/tags/v0.2.0": []byte(`{
+			"tag_name": "v0.2.0",
+			"assets": [
+				{"name": "` + archiveName + `", "browser_download_url": "https://downloads.example/` + archiveName + `"},
+				{"name": "checksums.txt", "browser_download_url": "https://downloads.example/checksums.txt"}
+			]
+		}`),
+		"https://downloads.example/" + archiveName: archiveData,
+		"https://downloads.example/checksums.txt":  []byte(hex.EncodeToString(checksum[:]) + "  " + archiveName + "\n"),
+	}
+	restore := replacePluginStoreClientForTest(httpClient)
+	defer restore()
+	cfg := syncTestConfig(t, root)
+	cfg.Plugins.StoreTrust = nil
+
+	errSync := SyncPlatform(context.Background(), cfg, nil, Platform{GOOS: "windows", GOARCH: "amd64"})
+	if errSync == nil || !strings.Contains(errSync.Error(), "not signed") {
+		t.Fatalf("SyncPlatform() error = %v, want unsigned artifact refused", errSync)
+	}
+	if _, errStat := os.Stat(pluginTestPath(root, "windows", "amd64", "sample", "0.2.0")); !os.IsNotExist(errStat) {
+		t.Fatalf("unsigned plugin was installed: stat error = %v", errStat)
+	}
+}
+
 func TestSyncResolvedWithReportUsesTemporaryAuthAndClearsIt(t *testing.T) {
 	root := t.TempDir()
 	libraryName := "sample" + pluginExtension(runtime.GOOS)
@@ -133,7 +163,7 @@ func TestSyncResolvedWithReportUsesTemporaryAuthAndClearsIt(t *testing.T) {
 	enabled := true
 	cfg := &config.Config{
 		Home:    config.HomeConfig{Enabled: true},
-		Plugins: config.PluginsConfig{Enabled: true, Dir: root, Configs: map[string]config.PluginInstanceConfig{"sample": {Enabled: &enabled}}},
+		Plugins: config.PluginsConfig{Enabled: true, Dir: root, Configs: map[string]config.PluginInstanceConfig{"sample": {Enabled: &enabled}}, StoreTrust: allowUnsignedStoreTrust()},
 	}
 
 	report, errSync := SyncResolvedWithReport(context.Background(), cfg, items, time.Now().UTC().Add(time.Minute), map[string]string{"sample": "0.9.0"}, nil)
@@ -496,6 +526,45 @@ func TestMarkLoadResultsFailsWhenInstalledPluginDidNotLoad(t *testing.T) {
 	}
 }
 
+type versionedPluginLoadInspector map[string]string
+
+func (i versionedPluginLoadInspector) PluginRegistered(id string) bool {
+	_, ok := i[id]
+	return ok
+}
+
+func (i versionedPluginLoadInspector) PluginRegisteredVersion(id string) (string, bool) {
+	version, ok := i[id]
+	return version, ok
+}
+
+func TestMarkLoadResultsReportsRolledBackUpgrade(t *testing.T) {
+	report := SyncReport{
+		Status: pluginTaskStatusOK,
+		OK:     true,
+		Phase:  pluginTaskPhaseInstall,
+		Plugins: []PluginInstallStatus{
+			{ID: "sample", Version: "0.2.0", InstallStatus: pluginInstallStatusInstalled},
+			{ID: "other", Version: "1.0.0", InstallStatus: pluginInstallStatusSkipped},
+		},
+	}
+
+	errLoad := MarkLoadResults(&report, versionedPluginLoadInspector{"sample": "0.1.0", "other": "1.0.0"})
+	if errLoad == nil {
+		t.Fatal("MarkLoadResults() error = nil, want rollback failure")
+	}
+	if report.OK || report.Status != pluginTaskStatusError {
+		t.Fatalf("report = %+v, want failed status", report)
+	}
+	sample := report.Plugins[0]
+	if sample.LoadStatus != pluginLoadStatusRolledBack || !strings.Contains(sample.Error, "0.1.0 remains active") {
+		t.Fatalf("sample report = %+v, want rolled back status", sample)
+	}
+	if report.Plugins[1].LoadStatus != pluginLoadStatusLoaded {
+		t.Fatalf("other report = %+v, want loaded", report.Plugins[1])
+	}
+}
+
 func TestMarkLoadResultsPreservesInstallFailure(t *testing.T) {
 	report := SyncReport{
 		Status:  pluginTaskStatusError,
@@ -726,8 +795,9 @@ func syncTestConfig(t *testing.T, root string) *config.Config {
 	return &config.Config{
 		Home: config.HomeConfig{Enabled: true},
 		Plugins: config.PluginsConfig{
-			Enabled: true,
-			Dir:     root,
+			Enabled:    true,
+			Dir:        root,
+			StoreTrust: allowUnsignedStoreTrust(),
 			Configs: map[string]config.PluginInstanceConfig{
 				"sample": pluginConfigFromYAML(t, `
 enabled: true
@@ -745,6 +815,12 @@ store:
 	}
 }
 
+// allowUnsignedStoreTrust lets tests that are not about signatures install
+// the unsigned fixtures.
+func allowUnsignedStoreTrust() []sdkpluginstore.TrustConfig {
+	return []sdkpluginstore.TrustConfig{{Source: "*", AllowUnsigned: true}}
+}
+
 func pluginTestPath(root string, goos string, goarch string, id string, version string) string {
 	name := strings.TrimSpace(id)
 	version = strings.TrimSpace(version)
@@ -0,0 +1,156 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var requestLogSectionPattern = regexp.MustCompile(`^=== (.+) ===$`)
+
+// RequestLogRecord is the replayable content of a request log file written by
+// FileRequestLogger: the downstream request, the upstream requests CPA sent for
+// it and the downstream response.
+type RequestLogRecord struct {
+	URL     string
+	Method  string
+	Headers http.Header
+	Body    []byte
+	// APIRequests holds the upstream requests in attempt order.
+	APIRequests []RequestLogAPIRequest
+	Status      int
+	Response    []byte
+}
+
+// RequestLogAPIRequest is one upstream request recorded in a request log.
+type RequestLogAPIRequest struct {
+	URL     string
+	Method  string
+	Headers http.Header
+	Body    []byte
+}
+
+// RequestLogSection is one "=== NAME ===" block of a request log.
+type RequestLogSection struct {
+	Name string
+	// Lines holds the section content without the heading and line terminators.
+	Lines []string
+}
+
+// ParseRequestLog extracts the request, upstream requests and response from a
+// request log file. Header values keep the masking applied when the log was written.
+func ParseRequestLog(data []byte) (RequestLogRecord, error) {
+	var record RequestLogRecord
+	sections := SplitRequestLogSections(data)
+	if len(sections) == 0 || sections[0].Name != "REQUEST INFO" {
+		return record, fmt.Errorf("not a request log: missing REQUEST INFO section")
+	}
+	for _, section := range sections {
+		switch {
+		case section.Name == "REQUEST INFO":
+			for _, line := range section.Lines {
+				key, value, found := strings.Cut(line, ": ")
+				if !found {
+					continue
+				}
+				switch key {
+				case "URL":
+					record.URL = strings.TrimSpace(value)
+				case "Method":
+					record.Method = strings.TrimSpace(value)
+				}
+			}
+		case section.Name == "HEADERS":
+			record.Headers = parseRequestLogHeaderLines(section.Lines)
+		case section.Name == "REQUEST BODY":
+			record.Body = joinRequestLogLines(section.Lines)
+		case strings.HasPrefix(section.Name, "API REQUEST"):
+			if apiRequest, ok := parseRequestLogAPIRequest(section.Lines); ok {
+				record.APIRequests = append(record.APIRequests, apiRequest)
+			}
+		case section.Name == "RESPONSE":
+			record.Status, record.Response = parseRequestLogResponse(section.Lines)
+		}
+	}
+	return record, nil
+}
+
+// SplitRequestLogSections splits a request log written by FileRequestLogger
+// into its sections. Text before the first heading is dropped.
+func SplitRequestLogSections(data []byte) []RequestLogSection {
+	var sections []RequestLogSection
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if match := requestLogSectionPattern.FindStringSubmatch(line); match != nil {
+			sections = append(sections, RequestLogSection{Name: match[1]})
+			continue
+		}
+		if len(sections) == 0 {
+			continue
+		}
+		last := &sections[len(sections)-1]
+		last.Lines = append(last.Lines, line)
+	}
+	return sections
+}
+
+func joinRequestLogLines(lines []string) []byte {
+	return bytes.TrimRight([]byte(strings.Join(lines, "\n")), "\n")
+}
+
+func parseRequestLogHeaderLines(lines []string) http.Header {
+	headers := make(http.Header)
+	for _, line := range lines {
+		key, value, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(key) == "" {
+			continue
+		}
+		headers.Add(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+	return headers
+}
+
+func parseRequestLogAPIRequest(lines []string) (RequestLogAPIRequest, bool) {
+	var request RequestLogAPIRequest
+	found := false
+	for index := 0; index < len(lines); index++ {
+		line := lines[index]
+		switch {
+		case strings.HasPrefix(line, "Upstream URL: "):
+			request.URL = strings.TrimSpace(strings.TrimPrefix(line, "Upstream URL: "))
+			if request.URL == "<unknown>" {
+				request.URL = ""
+			}
+			found = true
+		case strings.HasPrefix(line, "HTTP Method: "):
+			request.Method = strings.TrimSpace(strings.TrimPrefix(line, "HTTP Method: "))
+		case line == "Headers:":
+			end := index + 1
+			for end < len(lines) && strings.TrimSpace(lines[end]) != "" {
+				end++
+			}
+			request.Headers = parseRequestLogHeaderLines(lines[index+1 : end])
+			index = end
+		case line == "Body:":
+			request.Body = joinRequestLogLines(lines[index+1:])
+			return request, true
+		}
+	}
+	return request, found
+}
+
+func parseRequestLogResponse(lines []string) (int, []byte) {
+	status := 0
+	for index, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			return status, joinRequestLogLines(lines[index+1:])
+		}
+		if value, found := strings.CutPrefix(line, "Status: "); found {
+			status, _ = strconv.Atoi(strings.TrimSpace(value))
+		}
+	}
+	return status, nil
+}
@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseRequestLogExtractsRequestAndUpstreamAttempts(t *testing.T) {
+	logger := &FileRequestLogger{}
+	apiRequest := []byte("=== API REQUEST 1 ===\n" +
+		"Timestamp: 2026-03-14T10:00:00Z\n" +
+		"Upstream URL: https://api.example.com/v1/messages\n" +
+		"HTTP Method: POST\n" +
+		"\nHeaders:\nContent-Type: application/json\n" +
+		"\nBody:\n{\"model\":\"claude-x\",\"max_tokens\":16}\n\n" +
+		"=== API REQUEST 2 ===\n" +
+		"Timestamp: 2026-03-14T10:00:01Z\n" +
+		"Upstream URL: https://backup.example.com/v1/messages\n" +
+		"\nHeaders:\n" +
+		"\nBody:\n{\"model\":\"claude-y\"}\n")
+	content := logger.formatLogContent(
+		"/v1/chat/completions",
+		http.MethodPost,
+		map[string][]string{"Content-Type": {"application/json"}},
+		[]byte(`{"model":"gpt-x","stream":false}`),
+		nil,
+		apiRequest,
+		nil,
+		nil,
+		[]byte(`{"id":"resp-1"}`),
+		http.StatusOK,
+		map[string][]string{"Content-Type": {"application/json"}},
+		nil,
+	)
+
+	record, errParse := ParseRequestLog([]byte(content))
+	if errParse != nil {
+		t.Fatalf("ParseRequestLog() error = %v", errParse)
+	}
+	if record.URL != "/v1/chat/completions" || record.Method != http.MethodPost {
+		t.Fatalf("request line = %s %s", record.Method, record.URL)
+	}
+	if got := record.Headers.Get("Content-Type"); got != "application/json" {
+		t.Fatalf("Content-Type = %q", got)
+	}
+	if string(record.Body) != `{"model":"gpt-x","stream":false}` {
+		t.Fatalf("body = %q", record.Body)
+	}
+	if len(record.APIRequests) != 2 {
+		t.Fatalf("api requests = %d, want 2", len(record.APIRequests))
+	}
+	first := record.APIRequests[0]
+	if first.URL != "https://api.example.com/v1/messages" || first.Method != http.MethodPost || string(first.Body) != `{"model":"claude-x","max_tokens":16}` {
+		t.Fatalf("first api request = %#v", first)
+	}
+	if string(record.APIRequests[1].Body) != `{"model":"claude-y"}` {
+		t.Fatalf("second api request body = %q", record.APIRequests[1].Body)
+	}
+	if record.Status != http.StatusOK || string(record.Response) != `{"id":"resp-1"}` {
+		t.Fatalf("response = %d %q", record.Status, record.Response)
+	}
+}
+
+func TestParseRequestLogRejectsOtherFiles(t *testing.T) {
+	if _, errParse := ParseRequestLog([]byte("hello\n")); errParse == nil {
+		t.Fatal("ParseRequestLog() accepted a file without request sections")
+	}
+}
+
+func TestSplitRequestLogSections(t *testing.T) {
+	sections := SplitRequestLogSections([]byte("preamble\n" +
+		"=== REQUEST INFO ===\r\nURL: /v1/responses\r\n\n" +
+		"=== REQUEST BODY ===\n{\"model\":\"gpt-5\"}\n\n" +
+		"=== API REQUEST ===\n=== API REQUEST 1 ===\nBody:\n{}\n"))
+	var names []string
+	for _, section := range sections {
+		names = append(names, section.Name)
+	}
+	if got, want := strings.Join(names, ","), "REQUEST INFO,REQUEST BODY,API REQUEST,API REQUEST 1"; got != want {
+		t.Fatalf("sections = %s, want %s", got, want)
+	}
+	if got := strings.Join(sections[0].Lines, "|"); got != "URL: /v1/responses|" {
+		t.Fatalf("request info lines = %q", got)
+	}
+	if got := sections[1].Lines[0]; got != `{"model":"gpt-5"}` {
+		t.Fatalf("request body = %q", got)
+	}
+	if len(sections[2].Lines) != 0 {
+		t.Fatalf("empty section lines = %q", sections[2].Lines)
+	}
+}
@@ -0,0 +1,190 @@
+package logging
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// requestLogSummaryChunk bounds how much of the head and tail of a request log
+// is read to build its summary.
+const requestLogSummaryChunk = 64 << 10
+
+var (
+	requestLogFilenamePattern = regexp.MustCompile(`-(\d{4}-\d{2}-\d{2}T\d{6})-([^/\\]+)\.log$`)
+	requestLogModelPattern    = regexp.MustCompile(`"model"\s*:\s*"([^"]+)"`)
+	requestLogAuthPattern     = regexp.MustCompile(`(?m)^Auth: (.*)$`)
+	requestLogStatusPattern   = regexp.MustCompile(`(?m)^(?:HTTP )?Status: (\d{3})`)
+)
+
+// RequestLogSummary describes one request log file written by FileRequestLogger.
+type RequestLogSummary struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	URL        string    `json:"url,omitempty"`
+	Method     string    `json:"method,omitempty"`
+	Status     int       `json:"status,omitempty"`
+	Model      string    `json:"model,omitempty"`
+	Provider   string    `json:"provider,omitempty"`
+	Credential string    `json:"credential,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+	LatencyMs  int64     `json:"latency_ms"`
+	Size       int64     `json:"size"`
+	Modified   time.Time `json:"modified"`
+	Error      bool      `json:"error"`
+}
+
+// ParseRequestLogFilename extracts the request ID from a request log file name
+// (format: <path>-<2006-01-02T150405>-<id>.log). Forced error logs carry an
+// "error-" prefix.
+func ParseRequestLogFilename(name string) (string, bool) {
+	match := requestLogFilenamePattern.FindStringSubmatch(name)
+	if match == nil {
+		return "", false
+	}
+	return match[2], true
+}
+
+// SummarizeRequestLog reads the head and tail of a request log file and extracts
+// the request line, status, model, credential and latency.
+func SummarizeRequestLog(path string) (RequestLogSummary, error) {
+	file, errOpen := os.Open(path)
+	if errOpen != nil {
+		return RequestLogSummary{}, errOpen
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	info, errStat := file.Stat()
+	if errStat != nil {
+		return RequestLogSummary{}, errStat
+	}
+
+	head, errHead := readRequestLogChunk(file, 0, requestLogSummaryChunk)
+	if errHead != nil {
+		return RequestLogSummary{}, errHead
+	}
+	var tail []byte
+	if info.Size() > requestLogSummaryChunk {
+		offset := max(info.Size()-requestLogSummaryChunk, requestLogSummaryChunk)
+		rest, errTail := readRequestLogChunk(file, offset, requestLogSummaryChunk)
+		if errTail != nil {
+			return RequestLogSummary{}, errTail
+		}
+		tail = rest
+	}
+
+	name := info.Name()
+	id, _ := ParseRequestLogFilename(name)
+	summary := parseRequestLogSummary(head, tail)
+	summary.ID = id
+	summary.Name = name
+	summary.Size = info.Size()
+	summary.Modified = info.ModTime()
+	summary.Error = strings.HasPrefix(name, "error-")
+	if !summary.Timestamp.IsZero() {
+		summary.LatencyMs = max(summary.Modified.Sub(summary.Timestamp).Milliseconds(), 0)
+	}
+	return summary, nil
+}
+
+func readRequestLogChunk(file *os.File, offset, size int64) ([]byte, error) {
+	buf := make([]byte, size)
+	n, errRead := file.ReadAt(buf, offset)
+	if errRead != nil && errRead != io.EOF {
+		return nil, errRead
+	}
+	return buf[:n], nil
+}
+
+// parseRequestLogSummary extracts summary fields from the start of a log and,
+// for large files, its last chunk. tail is nil when head covers the whole file.
+func parseRequestLogSummary(head, tail []byte) RequestLogSummary {
+	var summary RequestLogSummary
+	info := head
+	if end := bytes.Index(info, []byte("=== HEADERS ===")); end >= 0 {
+		info = info[:end]
+	}
+	for _, line := range strings.Split(string(info), "\n") {
+		key, value, found := strings.Cut(line, ": ")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "URL":
+			summary.URL = value
+		case "Method":
+			summary.Method = value
+		case "Timestamp":
+			if parsed, errParse := time.Parse(time.RFC3339Nano, value); errParse == nil {
+				summary.Timestamp = parsed
+			}
+		}
+	}
+
+	if match := requestLogModelPattern.FindSubmatch(head); match != nil {
+		summary.Model = string(match[1])
+	}
+
+	authLine := requestLogAuthPattern.FindSubmatch(head)
+	if authLine == nil && tail != nil {
+		authLine = requestLogAuthPattern.FindSubmatch(tail)
+	}
+	if authLine != nil {
+		summary.Provider, summary.Credential = parseRequestLogAuth(string(authLine[1]))
+	}
+
+	if tail != nil {
+		summary.Status = parseRequestLogStatus(tail)
+	}
+	if summary.Status == 0 {
+		summary.Status = parseRequestLogStatus(head)
+	}
+	return summary
+}
+
+// parseRequestLogAuth reads the "provider=... auth_id=... label=..." fields
+// written for upstream attempts. The label wins over the auth ID.
+func parseRequestLogAuth(line string) (string, string) {
+	var provider, authID, label string
+	for _, field := range strings.Fields(line) {
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "provider":
+			provider = value
+		case "auth_id":
+			authID = value
+		case "label":
+			label = value
+		}
+	}
+	if label != "" {
+		return provider, label
+	}
+	return provider, authID
+}
+
+// parseRequestLogStatus prefers the downstream RESPONSE status and falls back to
+// the last upstream status in data.
+func parseRequestLogStatus(data []byte) int {
+	if index := bytes.LastIndex(data, []byte("=== RESPONSE ===\n")); index >= 0 {
+		if match := requestLogStatusPattern.FindSubmatch(data[index:]); match != nil {
+			status, _ := strconv.Atoi(string(match[1]))
+			return status
+		}
+	}
+	matches := requestLogStatusPattern.FindAllSubmatch(data, -1)
+	if len(matches) == 0 {
+		return 0
+	}
+	status, _ := strconv.Atoi(string(matches[len(matches)-1][1]))
+	return status
+}
@@ -0,0 +1,120 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleRequestLog = `=== REQUEST INFO ===
+Version: dev
+URL: /v1/chat/completions
+Method: POST
+Downstream Transport: http
+Upstream Transport: http
+Timestamp: 2026-06-15T10:00:00Z
+
+=== HEADERS ===
+Content-Type: application/json
+
+=== REQUEST BODY ===
+{"model":"gpt-5","messages":[]}
+
+=== API REQUEST ===
+=== API REQUEST 1 ===
+Timestamp: 2026-06-15T10:00:00.1Z
+Upstream URL: https://example.com/v1/responses
+HTTP Method: POST
+Auth: provider=codex auth_id=codex-1.json label=user@example.com type=oauth
+
+Headers:
+
+Body:
+{"model":"gpt-5"}
+
+=== API RESPONSE ===
+=== API RESPONSE 1 ===
+Timestamp: 2026-06-15T10:00:01Z
+Status: 429
+
+{"error":"rate limited"}
+
+=== RESPONSE ===
+Status: 502
+Content-Type: application/json
+
+{"error":"upstream failed"}
+`
+
+func TestParseRequestLogFilename(t *testing.T) {
+	cases := map[string]string{
+		"v1-chat-completions-2026-06-15T100000-abc123.log":    "abc123",
+		"error-v1-responses-2026-06-15T100000-req-7f.log":     "req-7f",
+		"v1beta-models-gemini-2026-06-15T100000-0a1b2c3d.log": "0a1b2c3d",
+		"main.log":                           "",
+		"main-2026-06-15T10-00-00.log":       "",
+		"v1-chat-completions-2026-06-15.log": "",
+	}
+	for name, want := range cases {
+		got, ok := ParseRequestLogFilename(name)
+		if ok != (want != "") || got != want {
+			t.Fatalf("ParseRequestLogFilename(%q) = %q, %v; want %q", name, got, ok, want)
+		}
+	}
+}
+
+func TestSummarizeRequestLog(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "error-v1-chat-completions-2026-06-15T100000-abc123.log")
+	if err := os.WriteFile(path, []byte(sampleRequestLog), 0o644); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+	modified := time.Date(2026, 6, 15, 10, 0, 1, 500_000_000, time.UTC)
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	summary, err := SummarizeRequestLog(path)
+	if err != nil {
+		t.Fatalf("SummarizeRequestLog() error = %v", err)
+	}
+	if summary.ID != "abc123" || !summary.Error {
+		t.Fatalf("id/error = %q/%v", summary.ID, summary.Error)
+	}
+	if summary.URL != "/v1/chat/completions" || summary.Method != "POST" {
+		t.Fatalf("request line = %s %s", summary.Method, summary.URL)
+	}
+	if summary.Status != 502 {
+		t.Fatalf("status = %d, want downstream 502", summary.Status)
+	}
+	if summary.Model != "gpt-5" {
+		t.Fatalf("model = %q", summary.Model)
+	}
+	if summary.Provider != "codex" || summary.Credential != "user@example.com" {
+		t.Fatalf("provider/credential = %q/%q", summary.Provider, summary.Credential)
+	}
+	if summary.LatencyMs != 1500 {
+		t.Fatalf("latency = %d, want 1500", summary.LatencyMs)
+	}
+}
+
+func TestSummarizeRequestLogReadsStatusFromTailOfLargeFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "v1-responses-2026-06-15T100000-big.log")
+	padding := strings.Repeat("x", 3*requestLogSummaryChunk)
+	content := strings.Replace(sampleRequestLog, `{"model":"gpt-5","messages":[]}`, `{"model":"gpt-5","input":"`+padding+`"}`, 1)
+	content = strings.Replace(content, "Status: 502", "Status: 200", 1)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+
+	summary, err := SummarizeRequestLog(path)
+	if err != nil {
+		t.Fatalf("SummarizeRequestLog() error = %v", err)
+	}
+	if summary.Status != 200 || summary.Model != "gpt-5" || summary.Credential != "user@example.com" {
+		t.Fatalf("summary = %+v", summary)
+	}
+}
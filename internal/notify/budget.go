@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/events"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/usage"
+)
+
+// Budget metrics reported on client.budget.exceeded events.
+const (
+	budgetMetricTokens   = "tokens"
+	budgetMetricRequests = "requests"
+)
+
+func init() {
+	coreusage.RegisterPlugin(budgets)
+}
+
+// clientBudgetEvent is the payload of client.budget.exceeded events.
+type clientBudgetEvent struct {
+	Key         string    `json:"key"`
+	Metric      string    `json:"metric"`
+	Used        int64     `json:"used"`
+	Limit       int64     `json:"limit"`
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+}
+
+// budgetUsage is the usage of one client key in the current window.
+type budgetUsage struct {
+	windowStart     time.Time
+	tokens          int64
+	requests        int64
+	tokensReported  bool
+	requestReported bool
+}
+
+// budgetTracker counts usage per client key and publishes
+// client.budget.exceeded once per window and metric. Counters are kept in
+// memory and restart from zero with the process.
+type budgetTracker struct {
+	mu      sync.Mutex
+	budgets map[string]config.ClientKeyBudget
+	usage   map[string]*budgetUsage
+	bus     *events.Bus
+	now     func() time.Time
+}
+
+var budgets = newBudgetTracker(events.Default())
+
+func newBudgetTracker(bus *events.Bus) *budgetTracker {
+	return &budgetTracker{
+		budgets: make(map[string]config.ClientKeyBudget),
+		usage:   make(map[string]*budgetUsage),
+		bus:     bus,
+		now:     time.Now,
+	}
+}
+
+// apply replaces the configured budgets. Usage of keys that keep a budget
+// is preserved.
+func (t *budgetTracker) apply(list []config.ClientKeyBudget) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.budgets = make(map[string]config.ClientKeyBudget, len(list))
+	for _, budget := range list {
+		t.budgets[budget.APIKey] = budget
+	}
+	for key := range t.usage {
+		if _, ok := t.budgets[key]; !ok {
+			delete(t.usage, key)
+		}
+	}
+}
+
+// HandleUsage implements coreusage.Plugin.
+func (t *budgetTracker) HandleUsage(_ context.Context, record coreusage.Record) {
+	if t == nil || record.APIKey == "" {
+		return
+	}
+	detail := coreusage.EnsureTokenBreakdownForProvider(record.Detail, record.Provider, record.ExecutorType)
+	now := t.now()
+
+	t.mu.Lock()
+	budget, ok := t.budgets[record.APIKey]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	window := budget.WindowOrDefault()
+	windowStart := now.Truncate(window)
+	usage := t.usage[record.APIKey]
+	if usage == nil || !usage.windowStart.Equal(windowStart) {
+		usage = &budgetUsage{windowStart: windowStart}
+		t.usage[record.APIKey] = usage
+	}
+	usage.tokens += detail.TotalTokens
+	usage.requests++
+
+	label := budget.Name
+	if label == "" {
+		label = util.HideAPIKey(budget.APIKey)
+	}
+	var exceeded []clientBudgetEvent
+	if budget.MaxTokens > 0 && usage.tokens >= budget.MaxTokens && !usage.tokensReported {
+		usage.tokensReported = true
+		exceeded = append(exceeded, clientBudgetEvent{Key: label, Metric: budgetMetricTokens, Used: usage.tokens, Limit: budget.MaxTokens})
+	}
+	if budget.MaxRequests > 0 && usage.requests >= budget.MaxRequests && !usage.requestReported {
+		usage.requestReported = true
+		exceeded = append(exceeded, clientBudgetEvent{Key: label, Metric: budgetMetricRequests, Used: usage.requests, Limit: budget.MaxRequests})
+	}
+	t.mu.Unlock()
+
+	for _, event := range exceeded {
+		event.WindowStart = windowStart.UTC()
+		event.WindowEnd = windowStart.Add(window).UTC()
+		t.bus.Publish(events.TypeClientBudgetExceeded, event)
+	}
+}
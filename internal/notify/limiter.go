@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"sync"
+	"time"
+)
+
+// limiter suppresses repeats of one alert within the dedup window and caps the
+// alerts accepted per target per minute.
+type limiter struct {
+	mu          sync.Mutex
+	dedupWindow time.Duration
+	perMinute   int
+	lastSeen    map[string]time.Time
+	windows     map[string]*rateWindow
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func newLimiter() *limiter {
+	return &limiter{
+		lastSeen: make(map[string]time.Time),
+		windows:  make(map[string]*rateWindow),
+	}
+}
+
+// configure sets the dedup window and per-minute cap. Non-positive values
+// disable the respective check.
+func (l *limiter) configure(dedupWindow time.Duration, perMinute int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.dedupWindow = dedupWindow
+	l.perMinute = perMinute
+}
+
+// allow reports whether an alert identified by key may be sent to target.
+func (l *limiter) allow(target, key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.dedupWindow > 0 {
+		dedupKey := target + "\x00" + key
+		if last, seen := l.lastSeen[dedupKey]; seen && now.Sub(last) < l.dedupWindow {
+			return false
+		}
+		l.pruneLocked(now)
+		l.lastSeen[dedupKey] = now
+	}
+	if l.perMinute > 0 {
+		window := l.windows[target]
+		if window == nil || now.Sub(window.start) >= time.Minute {
+			window = &rateWindow{start: now}
+			l.windows[target] = window
+		}
+		if window.count >= l.perMinute {
+			return false
+		}
+		window.count++
+	}
+	return true
+}
+
+// pruneLocked drops dedup entries older than the window so the map stays
+// bounded by the alerts seen within one window.
+func (l *limiter) pruneLocked(now time.Time) {
+	for key, last := range l.lastSeen {
+		if now.Sub(last) >= l.dedupWindow {
+			delete(l.lastSeen, key)
+		}
+	}
+}
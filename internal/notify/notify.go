@@ -0,0 +1,596 @@
+// Package notify delivers operational alerts to webhook targets. It turns
+// selected management events (credential refresh failures, models with every
+// credential cooling down, client budgets and failed config reloads) into
+// notifications, deduplicates and rate limits them per target, and delivers
+// them from a file-backed retry queue that survives restarts.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/events"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// Notification types sent to webhook targets.
+const (
+	TypeAuthRefreshFailed    = "auth.refresh.failed"
+	TypeModelsCooling        = events.TypeModelsCooling
+	TypeClientBudgetExceeded = events.TypeClientBudgetExceeded
+	TypeConfigReloadFailed   = events.TypeConfigReloadFailed
+	TypeTest                 = "notification.test"
+)
+
+const (
+	defaultQueueDirName = "notifications"
+	minRetryDelay       = 5 * time.Second
+	maxRetryDelay       = 30 * time.Minute
+)
+
+// sourceFilter selects the bus events that can become notifications.
+var sourceFilter = events.Filter{Types: []string{
+	events.TypeAuthRefresh,
+	events.TypeModelsCooling,
+	events.TypeClientBudgetExceeded,
+	events.TypeConfigReloadFailed,
+}}
+
+// Notification is one alert. It is the JSON body sent to targets without a
+// template and the value templates are rendered with.
+type Notification struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Time    time.Time       `json:"time"`
+	Summary string          `json:"summary"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	// Key identifies repeats of the same alert for deduplication.
+	Key string `json:"-"`
+}
+
+// Status describes the dispatcher for the management API.
+type Status struct {
+	Enabled    bool           `json:"enabled"`
+	QueueDir   string         `json:"queue_dir,omitempty"`
+	Pending    int            `json:"pending"`
+	Targets    []TargetStatus `json:"targets"`
+	Suppressed uint64         `json:"suppressed"`
+}
+
+// TargetStatus reports delivery counters for one target.
+type TargetStatus struct {
+	Name      string    `json:"name"`
+	Events    []string  `json:"events,omitempty"`
+	Pending   int       `json:"pending"`
+	Delivered uint64    `json:"delivered"`
+	Failed    uint64    `json:"failed"`
+	Dropped   uint64    `json:"dropped"`
+	LastError string    `json:"last_error,omitempty"`
+	LastSent  time.Time `json:"last_sent,omitempty"`
+}
+
+type targetCounters struct {
+	delivered uint64
+	failed    uint64
+	dropped   uint64
+	lastError string
+	lastSent  time.Time
+}
+
+// Dispatcher subscribes to the event bus and delivers notifications.
+type Dispatcher struct {
+	bus    *events.Bus
+	client *http.Client
+
+	mu         sync.Mutex
+	cfg        config.NotificationsConfig
+	targets    map[string]config.NotificationTarget
+	queue      *fileQueue
+	limiter    *limiter
+	counters   map[string]*targetCounters
+	suppressed uint64
+	cancel     context.CancelFunc
+	ctx        context.Context
+	done       chan struct{}
+	workers    map[string]*targetWorker
+	now        func() time.Time
+}
+
+// targetWorker delivers the queued notifications of one target so a slow or
+// unreachable target neither delays the others nor the event subscription.
+type targetWorker struct {
+	wake   chan struct{}
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewDispatcher returns a stopped dispatcher reading events from bus.
+func NewDispatcher(bus *events.Bus) *Dispatcher {
+	return &Dispatcher{
+		bus:      bus,
+		client:   &http.Client{},
+		targets:  make(map[string]config.NotificationTarget),
+		counters: make(map[string]*targetCounters),
+		workers:  make(map[string]*targetWorker),
+		now:      time.Now,
+	}
+}
+
+var defaultDispatcher = NewDispatcher(events.Default())
+
+// Default returns the process-wide dispatcher.
+func Default() *Dispatcher {
+	return defaultDispatcher
+}
+
+// Apply starts, reconfigures or stops the dispatcher for cfg. Pending
+// deliveries are kept across calls; deliveries to removed targets are dropped
+// when the dispatcher next runs with the new targets.
+func (d *Dispatcher) Apply(cfg *config.Config) {
+	if d == nil || cfg == nil {
+		return
+	}
+	budgets.apply(cfg.Notifications.ClientKeyBudgets)
+	ncfg := cfg.Notifications
+	if !ncfg.Enabled || len(ncfg.Targets) == 0 {
+		d.Stop()
+		d.mu.Lock()
+		d.cfg = ncfg
+		d.mu.Unlock()
+		return
+	}
+	queueDir := ncfg.QueueDir
+	if queueDir == "" {
+		queueDir = filepath.Join(logging.ResolveLogDirectory(cfg), defaultQueueDirName)
+	}
+
+	d.mu.Lock()
+	d.cfg = ncfg
+	d.targets = make(map[string]config.NotificationTarget, len(ncfg.Targets))
+	for _, target := range ncfg.Targets {
+		d.targets[target.Name] = target
+	}
+	if d.limiter == nil {
+		d.limiter = newLimiter()
+	}
+	d.limiter.configure(time.Duration(ncfg.DedupWindowSeconds)*time.Second, ncfg.RateLimitPerMinute)
+	queueChanged := d.queue == nil || d.queue.dir != queueDir
+	running := d.cancel != nil
+	var removed []*targetWorker
+	if running && !queueChanged {
+		removed = d.syncWorkersLocked()
+	}
+	d.mu.Unlock()
+	waitWorkers(removed)
+
+	if queueChanged {
+		d.Stop()
+		queue, errQueue := openFileQueue(queueDir)
+		if errQueue != nil {
+			log.Errorf("notifications: failed to open queue %s: %v", queueDir, errQueue)
+			return
+		}
+		d.mu.Lock()
+		d.queue = queue
+		d.mu.Unlock()
+		running = false
+	}
+	if !running {
+		d.start()
+	}
+	d.signal()
+}
+
+// Stop stops delivery. Pending deliveries stay in the queue directory.
+func (d *Dispatcher) Stop() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	cancel, done := d.cancel, d.done
+	d.cancel, d.ctx, d.done = nil, nil, nil
+	workers := make([]*targetWorker, 0, len(d.workers))
+	for name, worker := range d.workers {
+		workers = append(workers, worker)
+		delete(d.workers, name)
+	}
+	d.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+	waitWorkers(workers)
+}
+
+// SendTest queues a test notification for the named target, or for every
+// target when name is empty, bypassing filters, deduplication and rate limits.
+func (d *Dispatcher) SendTest(name string) ([]string, error) {
+	if d == nil {
+		return nil, fmt.Errorf("notifications are not available")
+	}
+	d.mu.Lock()
+	running := d.cancel != nil
+	targets := make([]string, 0, len(d.targets))
+	for targetName := range d.targets {
+		if name == "" || targetName == name {
+			targets = append(targets, targetName)
+		}
+	}
+	d.mu.Unlock()
+	sort.Strings(targets)
+	if !running {
+		return nil, fmt.Errorf("notifications are disabled")
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("notification target %q not found", name)
+	}
+	notification := Notification{
+		ID:      newID(),
+		Type:    TypeTest,
+		Time:    d.now().UTC(),
+		Summary: "Test notification from CLIProxyAPI",
+	}
+	for _, target := range targets {
+		if errEnqueue := d.enqueue(target, notification); errEnqueue != nil {
+			return nil, errEnqueue
+		}
+	}
+	d.signal(targets...)
+	return targets, nil
+}
+
+// Status reports the configured targets and queue state.
+func (d *Dispatcher) Status() Status {
+	if d == nil {
+		return Status{}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	status := Status{
+		Enabled:    d.cancel != nil,
+		Targets:    make([]TargetStatus, 0, len(d.cfg.Targets)),
+		Suppressed: d.suppressed,
+	}
+	var pendingByTarget map[string]int
+	if d.queue != nil {
+		status.QueueDir = d.queue.dir
+		pendingByTarget = d.queue.pendingByTarget()
+		for _, pending := range pendingByTarget {
+			status.Pending += pending
+		}
+	}
+	for _, target := range d.cfg.Targets {
+		entry := TargetStatus{Name: target.Name, Events: target.Events, Pending: pendingByTarget[target.Name]}
+		if counters := d.counters[target.Name]; counters != nil {
+			entry.Delivered = counters.delivered
+			entry.Failed = counters.failed
+			entry.Dropped = counters.dropped
+			entry.LastError = counters.lastError
+			entry.LastSent = counters.lastSent
+		}
+		status.Targets = append(status.Targets, entry)
+	}
+	return status
+}
+
+func (d *Dispatcher) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	sub, _, _ := d.bus.Subscribe(sourceFilter, 0, false)
+	d.mu.Lock()
+	d.cancel, d.ctx, d.done = cancel, ctx, done
+	d.syncWorkersLocked()
+	d.mu.Unlock()
+	go d.run(ctx, sub, done)
+}
+
+// syncWorkersLocked starts a worker for every configured target, stops the
+// workers of removed targets and drops their pending deliveries. It returns
+// the stopped workers for the caller to wait on after releasing d.mu.
+func (d *Dispatcher) syncWorkersLocked() []*targetWorker {
+	var removed []*targetWorker
+	for name, worker := range d.workers {
+		if _, ok := d.targets[name]; ok {
+			continue
+		}
+		worker.cancel()
+		removed = append(removed, worker)
+		delete(d.workers, name)
+	}
+	if d.queue != nil {
+		d.queue.removeTargets(func(target string) bool {
+			_, ok := d.targets[target]
+			return !ok
+		})
+	}
+	for name := range d.targets {
+		if _, ok := d.workers[name]; ok {
+			continue
+		}
+		ctx, cancel := context.WithCancel(d.ctx)
+		worker := &targetWorker{wake: make(chan struct{}, 1), cancel: cancel, done: make(chan struct{})}
+		d.workers[name] = worker
+		go d.work(ctx, name, worker)
+	}
+	return removed
+}
+
+func waitWorkers(workers []*targetWorker) {
+	for _, worker := range workers {
+		worker.cancel()
+		<-worker.done
+	}
+}
+
+// run reads the event bus and queues notifications; delivery happens on the
+// target workers. After the bus dropped a lagging subscription it resumes
+// from the last handled event so retained events are not lost.
+func (d *Dispatcher) run(ctx context.Context, sub *events.Subscription, done chan struct{}) {
+	defer close(done)
+	defer func() { sub.Close() }()
+	var lastID uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				log.Warn("notifications: event subscription lagged; resubscribing")
+				var backlog []events.Event
+				var gap bool
+				sub, backlog, gap = d.bus.Subscribe(sourceFilter, lastID, true)
+				if gap {
+					log.Warn("notifications: event subscription lost events while resubscribing")
+				}
+				for _, missed := range backlog {
+					lastID = missed.ID
+					d.handleEvent(missed)
+				}
+				continue
+			}
+			lastID = event.ID
+			d.handleEvent(event)
+		}
+	}
+}
+
+// work delivers the deliveries queued for target until ctx is canceled.
+func (d *Dispatcher) work(ctx context.Context, target string, worker *targetWorker) {
+	defer close(worker.done)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-worker.wake:
+		case <-timer.C:
+		}
+		next := d.deliverDue(ctx, target)
+		timer.Reset(next)
+	}
+}
+
+// handleEvent converts a bus event and queues it for the matching targets.
+func (d *Dispatcher) handleEvent(event events.Event) {
+	notification, ok := notificationFromEvent(event)
+	if !ok {
+		return
+	}
+	d.mu.Lock()
+	targets := make([]string, 0, len(d.targets))
+	for name, target := range d.targets {
+		if !(events.Filter{Types: target.Events}).Match(notification.Type) {
+			continue
+		}
+		if !d.limiter.allow(name, notification.Type+"\x00"+notification.Key, d.now()) {
+			d.suppressed++
+			continue
+		}
+		targets = append(targets, name)
+	}
+	d.mu.Unlock()
+	for _, target := range targets {
+		if errEnqueue := d.enqueue(target, notification); errEnqueue != nil {
+			log.Warnf("notifications: failed to queue %s for %s: %v", notification.Type, target, errEnqueue)
+		}
+	}
+	if len(targets) > 0 {
+		d.signal(targets...)
+	}
+}
+
+func (d *Dispatcher) enqueue(target string, notification Notification) error {
+	d.mu.Lock()
+	queue := d.queue
+	d.mu.Unlock()
+	if queue == nil {
+		return fmt.Errorf("queue is not open")
+	}
+	return queue.put(&delivery{
+		ID:           newID(),
+		Target:       target,
+		Notification: notification,
+		NextAttempt:  d.now().UTC(),
+	})
+}
+
+// deliverDue sends every delivery for target that is due and returns the wait
+// until its next one.
+func (d *Dispatcher) deliverDue(ctx context.Context, target string) time.Duration {
+	d.mu.Lock()
+	queue := d.queue
+	d.mu.Unlock()
+	if queue == nil {
+		return maxRetryDelay
+	}
+	for _, item := range queue.due(target, d.now()) {
+		if ctx.Err() != nil {
+			return maxRetryDelay
+		}
+		d.attempt(ctx, queue, item)
+	}
+	next := queue.nextAttempt(target)
+	if next.IsZero() {
+		return maxRetryDelay
+	}
+	wait := next.Sub(d.now())
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, queue *fileQueue, item *delivery) {
+	d.mu.Lock()
+	target, ok := d.targets[item.Target]
+	maxAttempts := d.cfg.MaxAttempts
+	d.mu.Unlock()
+	if !ok {
+		log.Debugf("notifications: dropping %s for removed target %s", item.Notification.Type, item.Target)
+		queue.remove(item)
+		return
+	}
+
+	errSend := sendWebhook(ctx, d.client, target, item.Notification)
+	if errSend != nil && ctx.Err() != nil {
+		// Shutdown interrupted the request; it is retried on the next start.
+		return
+	}
+	d.mu.Lock()
+	counters := d.counters[target.Name]
+	if counters == nil {
+		counters = &targetCounters{}
+		d.counters[target.Name] = counters
+	}
+	if errSend == nil {
+		counters.delivered++
+		counters.lastSent = d.now().UTC()
+		d.mu.Unlock()
+		queue.remove(item)
+		return
+	}
+	counters.failed++
+	counters.lastError = errSend.Error()
+	item.Attempts++
+	if maxAttempts > 0 && item.Attempts >= maxAttempts {
+		counters.dropped++
+		d.mu.Unlock()
+		log.Warnf("notifications: giving up on %s for %s after %d attempts: %v", item.Notification.Type, target.Name, item.Attempts, errSend)
+		queue.remove(item)
+		return
+	}
+	d.mu.Unlock()
+	item.LastError = errSend.Error()
+	item.NextAttempt = d.now().Add(retryDelay(item.Attempts)).UTC()
+	log.Debugf("notifications: delivery of %s to %s failed (attempt %d): %v", item.Notification.Type, target.Name, item.Attempts, errSend)
+	if errPut := queue.put(item); errPut != nil {
+		log.Warnf("notifications: failed to persist retry for %s: %v", target.Name, errPut)
+	}
+}
+
+// signal wakes the workers of targets, or every worker when none are named.
+func (d *Dispatcher) signal(targets ...string) {
+	d.mu.Lock()
+	wake := make([]chan struct{}, 0, len(d.workers))
+	if len(targets) == 0 {
+		for _, worker := range d.workers {
+			wake = append(wake, worker.wake)
+		}
+	}
+	for _, target := range targets {
+		if worker := d.workers[target]; worker != nil {
+			wake = append(wake, worker.wake)
+		}
+	}
+	d.mu.Unlock()
+	for _, ch := range wake {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// retryDelay doubles from minRetryDelay per failed attempt up to maxRetryDelay.
+func retryDelay(attempts int) time.Duration {
+	delay := minRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// notificationFromEvent maps a bus event to a notification. Events that are
+// not alerts, such as successful refreshes, are skipped.
+func notificationFromEvent(event events.Event) (Notification, bool) {
+	var fields map[string]any
+	if len(event.Data) > 0 {
+		if errUnmarshal := json.Unmarshal(event.Data, &fields); errUnmarshal != nil {
+			return Notification{}, false
+		}
+	}
+	notification := Notification{
+		ID:   newID(),
+		Type: event.Type,
+		Time: event.Time,
+		Data: event.Data,
+	}
+	switch event.Type {
+	case events.TypeAuthRefresh:
+		if success, _ := fields["success"].(bool); success {
+			return Notification{}, false
+		}
+		notification.Type = TypeAuthRefreshFailed
+		notification.Key = stringField(fields, "auth_id")
+		notification.Summary = fmt.Sprintf("Credential %s failed to refresh: %s", authLabel(fields), stringField(fields, "error"))
+	case events.TypeModelsCooling:
+		model := stringField(fields, "model")
+		notification.Key = stringField(fields, "provider") + "\x00" + model
+		notification.Summary = fmt.Sprintf("All credentials for model %s are cooling down", model)
+		if provider := stringField(fields, "provider"); provider != "" {
+			notification.Summary += " via provider " + provider
+		}
+		if until := stringField(fields, "until"); until != "" {
+			notification.Summary += " until " + until
+		}
+	case events.TypeClientBudgetExceeded:
+		notification.Key = stringField(fields, "key") + "\x00" + stringField(fields, "metric") + "\x00" + stringField(fields, "window_start")
+		notification.Summary = fmt.Sprintf("Client key %s crossed its %s budget (%v of %v)", stringField(fields, "key"), stringField(fields, "metric"), fields["used"], fields["limit"])
+	case events.TypeConfigReloadFailed:
+		notification.Key = stringField(fields, "error")
+		notification.Summary = fmt.Sprintf("Config reload of %s failed: %s", stringField(fields, "file"), stringField(fields, "error"))
+	default:
+		return Notification{}, false
+	}
+	return notification, true
+}
+
+func authLabel(fields map[string]any) string {
+	for _, key := range []string{"label", "file", "auth_id"} {
+		if value := stringField(fields, key); value != "" {
+			if provider := stringField(fields, "provider"); provider != "" {
+				return provider + "/" + value
+			}
+			return value
+		}
+	}
+	return "unknown"
+}
+
+func stringField(fields map[string]any, key string) string {
+	value, _ := fields[key].(string)
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,227 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/events"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/usage"
+)
+
+type sinkRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newSink(t *testing.T, status *atomic.Int32) (*httptest.Server, chan sinkRequest) {
+	t.Helper()
+	received := make(chan sinkRequest, 16)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		code := http.StatusOK
+		if status != nil && status.Load() != 0 {
+			code = int(status.Load())
+		}
+		w.WriteHeader(code)
+		received <- sinkRequest{header: r.Header.Clone(), body: body}
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func waitRequest(t *testing.T, received chan sinkRequest) sinkRequest {
+	t.Helper()
+	select {
+	case req := <-received:
+		return req
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+		return sinkRequest{}
+	}
+}
+
+func notificationsConfig(queueDir string, targets ...config.NotificationTarget) *config.Config {
+	cfg := &config.Config{}
+	cfg.Notifications = config.NotificationsConfig{Enabled: true, QueueDir: queueDir, Targets: targets}
+	cfg.SanitizeNotifications()
+	return cfg
+}
+
+func TestDispatcherDeliversSignedTemplatedAlert(t *testing.T) {
+	server, received := newSink(t, nil)
+	bus := events.NewBus(16)
+	d := NewDispatcher(bus)
+	d.Apply(notificationsConfig(t.TempDir(), config.NotificationTarget{
+		Name:     "sink",
+		URL:      server.URL,
+		Headers:  map[string]string{"X-Token": "abc"},
+		Template: `{"text": {{ json .Summary }}, "auth": {{ json .Fields.auth_id }}}`,
+		Secret:   "s3cret",
+		Events:   []string{"auth"},
+	}))
+	defer d.Stop()
+
+	bus.Publish(events.TypeAuthRefresh, map[string]any{"auth_id": "ok", "success": true})
+	bus.Publish(events.TypeConfigReloadFailed, map[string]any{"file": "config.yaml", "error": "bad yaml"})
+	bus.Publish(events.TypeAuthRefresh, map[string]any{"auth_id": "a1", "provider": "codex", "label": "me@example.com", "success": false, "error": "invalid_grant"})
+
+	req := waitRequest(t, received)
+	var body map[string]string
+	if errUnmarshal := json.Unmarshal(req.body, &body); errUnmarshal != nil {
+		t.Fatalf("body %q: %v", req.body, errUnmarshal)
+	}
+	if body["auth"] != "a1" || body["text"] != "Credential codex/me@example.com failed to refresh: invalid_grant" {
+		t.Fatalf("body = %v", body)
+	}
+	if req.header.Get(EventHeader) != TypeAuthRefreshFailed || req.header.Get("X-Token") != "abc" {
+		t.Fatalf("headers = %v", req.header)
+	}
+	timestamp, _ := strconv.ParseInt(req.header.Get(TimestampHeader), 10, 64)
+	if got, want := req.header.Get(SignatureHeader), Sign("s3cret", timestamp, req.body); got != want {
+		t.Fatalf("signature = %q, want %q", got, want)
+	}
+	select {
+	case extra := <-received:
+		t.Fatalf("unexpected delivery %s", extra.body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestDispatcherRetriesFromQueueAfterRestart(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusBadGateway)
+	server, received := newSink(t, &status)
+	queueDir := t.TempDir()
+	cfg := notificationsConfig(queueDir, config.NotificationTarget{Name: "sink", URL: server.URL})
+
+	bus := events.NewBus(16)
+	first := NewDispatcher(bus)
+	first.Apply(cfg)
+	bus.Publish(events.TypeModelsCooling, map[string]any{"model": "gpt-5", "provider": "codex"})
+	waitRequest(t, received)
+	deadline := time.Now().Add(5 * time.Second)
+	for first.Status().Targets[0].Failed == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	first.Stop()
+
+	queue, errQueue := openFileQueue(queueDir)
+	if errQueue != nil {
+		t.Fatalf("openFileQueue() error = %v", errQueue)
+	}
+	pending := queue.due("sink", time.Now().Add(time.Hour))
+	if len(pending) != 1 || pending[0].Attempts != 1 || pending[0].Notification.Type != TypeModelsCooling {
+		t.Fatalf("queued deliveries = %+v", pending)
+	}
+
+	status.Store(http.StatusOK)
+	second := NewDispatcher(events.NewBus(16))
+	second.now = func() time.Time { return time.Now().Add(time.Minute) }
+	second.Apply(cfg)
+	defer second.Stop()
+	req := waitRequest(t, received)
+	var notification Notification
+	if errUnmarshal := json.Unmarshal(req.body, &notification); errUnmarshal != nil || notification.Type != TypeModelsCooling {
+		t.Fatalf("redelivered body = %s (%v)", req.body, errUnmarshal)
+	}
+	deadline = time.Now().Add(5 * time.Second)
+	for second.Status().Pending != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if pendingStatus := second.Status(); pendingStatus.Pending != 0 || pendingStatus.Targets[0].Delivered != 1 {
+		t.Fatalf("status after redelivery = %+v", pendingStatus)
+	}
+}
+
+func TestDispatcherSlowTargetDoesNotBlockOthers(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(func() { close(release) })
+	fast, received := newSink(t, nil)
+
+	bus := events.NewBus(16)
+	d := NewDispatcher(bus)
+	d.Apply(notificationsConfig(t.TempDir(),
+		config.NotificationTarget{Name: "slow", URL: slow.URL},
+		config.NotificationTarget{Name: "fast", URL: fast.URL},
+	))
+	defer d.Stop()
+
+	bus.Publish(events.TypeConfigReloadFailed, map[string]any{"file": "config.yaml", "error": "first"})
+	waitRequest(t, received)
+	bus.Publish(events.TypeConfigReloadFailed, map[string]any{"file": "config.yaml", "error": "second"})
+	req := waitRequest(t, received)
+	var notification Notification
+	if errUnmarshal := json.Unmarshal(req.body, &notification); errUnmarshal != nil || notification.Summary != "Config reload of config.yaml failed: second" {
+		t.Fatalf("second delivery = %s (%v)", req.body, errUnmarshal)
+	}
+}
+
+func TestLimiterDeduplicatesAndRateLimits(t *testing.T) {
+	l := newLimiter()
+	l.configure(time.Minute, 2)
+	now := time.Unix(1_700_000_000, 0)
+	if !l.allow("t", "a", now) || l.allow("t", "a", now.Add(30*time.Second)) {
+		t.Fatal("repeat within dedup window was not suppressed")
+	}
+	if !l.allow("other", "a", now) {
+		t.Fatal("dedup leaked across targets")
+	}
+	if !l.allow("t", "b", now) || l.allow("t", "c", now) {
+		t.Fatal("per-minute limit was not applied")
+	}
+	if !l.allow("t", "a", now.Add(2*time.Minute)) {
+		t.Fatal("alert was not allowed after the dedup window")
+	}
+}
+
+func TestBudgetTrackerPublishesOncePerWindow(t *testing.T) {
+	bus := events.NewBus(16)
+	sub, _, _ := bus.Subscribe(events.Filter{}, 0, false)
+	defer sub.Close()
+	tracker := newBudgetTracker(bus)
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+	tracker.apply([]config.ClientKeyBudget{{APIKey: "key-1", Name: "team-a", MaxTokens: 100, Window: "1h"}})
+
+	record := coreusage.Record{APIKey: "key-1", Detail: coreusage.Detail{TotalTokens: 60}}
+	tracker.HandleUsage(context.Background(), record)
+	tracker.HandleUsage(context.Background(), record)
+	tracker.HandleUsage(context.Background(), record)
+	tracker.HandleUsage(context.Background(), coreusage.Record{APIKey: "other", Detail: coreusage.Detail{TotalTokens: 1000}})
+
+	select {
+	case event := <-sub.C:
+		var payload clientBudgetEvent
+		_ = json.Unmarshal(event.Data, &payload)
+		if event.Type != events.TypeClientBudgetExceeded || payload.Key != "team-a" || payload.Used != 120 || payload.Limit != 100 {
+			t.Fatalf("event = %s %s", event.Type, event.Data)
+		}
+	default:
+		t.Fatal("budget event was not published")
+	}
+	select {
+	case event := <-sub.C:
+		t.Fatalf("unexpected second event %s", event.Data)
+	default:
+	}
+
+	now = now.Add(time.Hour)
+	tracker.HandleUsage(context.Background(), record)
+	tracker.HandleUsage(context.Background(), record)
+	if _, ok := <-sub.C; !ok {
+		t.Fatal("budget event was not published for the next window")
+	}
+}
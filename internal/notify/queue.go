@@ -0,0 +1,176 @@
+package notify
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const queueFileSuffix = ".json"
+
+// delivery is one notification pending for one target. Each delivery is kept
+// in its own file so a crash loses at most the delivery being written.
+type delivery struct {
+	ID           string       `json:"id"`
+	Target       string       `json:"target"`
+	Notification Notification `json:"notification"`
+	Attempts     int          `json:"attempts"`
+	NextAttempt  time.Time    `json:"next_attempt"`
+	LastError    string       `json:"last_error,omitempty"`
+}
+
+// fileQueue keeps pending deliveries in memory and mirrors them to a directory.
+type fileQueue struct {
+	dir   string
+	mu    sync.Mutex
+	items map[string]*delivery
+}
+
+// openFileQueue creates dir when missing and loads the deliveries left by a
+// previous run. Unreadable files are removed.
+func openFileQueue(dir string) (*fileQueue, error) {
+	if errMkdir := os.MkdirAll(dir, 0o700); errMkdir != nil {
+		return nil, fmt.Errorf("create queue directory: %w", errMkdir)
+	}
+	entries, errRead := os.ReadDir(dir)
+	if errRead != nil {
+		return nil, fmt.Errorf("read queue directory: %w", errRead)
+	}
+	queue := &fileQueue{dir: dir, items: make(map[string]*delivery)}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), queueFileSuffix) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		raw, errFile := os.ReadFile(path)
+		if errFile != nil {
+			log.Warnf("notifications: failed to read queued delivery %s: %v", entry.Name(), errFile)
+			continue
+		}
+		var item delivery
+		if errUnmarshal := json.Unmarshal(raw, &item); errUnmarshal != nil || item.ID+queueFileSuffix != entry.Name() {
+			log.Warnf("notifications: removing corrupt queued delivery %s", entry.Name())
+			_ = os.Remove(path)
+			continue
+		}
+		queue.items[item.ID] = &item
+	}
+	return queue, nil
+}
+
+// put stores or updates a delivery.
+func (q *fileQueue) put(item *delivery) error {
+	raw, errMarshal := json.Marshal(item)
+	if errMarshal != nil {
+		return fmt.Errorf("encode delivery: %w", errMarshal)
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	path := q.path(item.ID)
+	tmp := path + ".tmp"
+	if errWrite := os.WriteFile(tmp, raw, 0o600); errWrite != nil {
+		return fmt.Errorf("write delivery: %w", errWrite)
+	}
+	if errRename := os.Rename(tmp, path); errRename != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("write delivery: %w", errRename)
+	}
+	copied := *item
+	q.items[item.ID] = &copied
+	return nil
+}
+
+// remove deletes a delivery.
+func (q *fileQueue) remove(item *delivery) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.items, item.ID)
+	if errRemove := os.Remove(q.path(item.ID)); errRemove != nil && !os.IsNotExist(errRemove) {
+		log.Warnf("notifications: failed to remove delivered notification %s: %v", item.ID, errRemove)
+	}
+}
+
+// due returns copies of the deliveries for target whose next attempt is not
+// after now, oldest notification first.
+func (q *fileQueue) due(target string, now time.Time) []*delivery {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := make([]*delivery, 0)
+	for _, item := range q.items {
+		if item.Target == target && !item.NextAttempt.After(now) {
+			copied := *item
+			items = append(items, &copied)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].Notification.Time.Equal(items[j].Notification.Time) {
+			return items[i].Notification.Time.Before(items[j].Notification.Time)
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items
+}
+
+// nextAttempt returns the earliest pending attempt for target, or the zero
+// time when nothing is pending for it.
+func (q *fileQueue) nextAttempt(target string) time.Time {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var next time.Time
+	for _, item := range q.items {
+		if item.Target != target {
+			continue
+		}
+		if next.IsZero() || item.NextAttempt.Before(next) {
+			next = item.NextAttempt
+		}
+	}
+	return next
+}
+
+// removeTargets deletes the deliveries whose target drop reports true.
+func (q *fileQueue) removeTargets(drop func(target string) bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for id, item := range q.items {
+		if !drop(item.Target) {
+			continue
+		}
+		delete(q.items, id)
+		if errRemove := os.Remove(q.path(id)); errRemove != nil && !os.IsNotExist(errRemove) {
+			log.Warnf("notifications: failed to remove notification %s for removed target %s: %v", id, item.Target, errRemove)
+		}
+	}
+}
+
+func (q *fileQueue) pendingByTarget() map[string]int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	counts := make(map[string]int)
+	for _, item := range q.items {
+		counts[item.Target]++
+	}
+	return counts
+}
+
+func (q *fileQueue) path(id string) string {
+	return filepath.Join(q.dir, id+queueFileSuffix)
+}
+
+// newID returns a random identifier that sorts roughly by creation time.
+func newID() string {
+	var buf [8]byte
+	if _, errRead := rand.Read(buf[:]); errRead != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return fmt.Sprintf("%d-%s", time.Now().UnixNano(), hex.EncodeToString(buf[:]))
+}
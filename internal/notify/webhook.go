@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+const (
+	// SignatureHeader carries "sha256=<hex>" of the HMAC over "<timestamp>.<body>".
+	SignatureHeader = "X-CLIProxy-Signature"
+	// TimestampHeader carries the Unix time the signature was computed at.
+	TimestampHeader = "X-CLIProxy-Timestamp"
+	// EventHeader carries the notification type.
+	EventHeader = "X-CLIProxy-Event"
+
+	maxErrorBodyBytes = 512
+)
+
+// templateData is the value a target template is executed with. Fields holds
+// the decoded event data so templates can reach individual values.
+type templateData struct {
+	Notification
+	Fields map[string]any
+}
+
+var templateFuncs = template.FuncMap{
+	// json encodes a value, so templates can embed strings in JSON bodies safely.
+	"json": func(value any) (string, error) {
+		raw, errMarshal := json.Marshal(value)
+		return string(raw), errMarshal
+	},
+}
+
+// renderBody produces the request body for a target.
+func renderBody(target config.NotificationTarget, notification Notification) ([]byte, error) {
+	if strings.TrimSpace(target.Template) == "" {
+		return json.Marshal(notification)
+	}
+	tmpl, errParse := template.New(target.Name).Funcs(templateFuncs).Option("missingkey=zero").Parse(target.Template)
+	if errParse != nil {
+		return nil, fmt.Errorf("parse template: %w", errParse)
+	}
+	data := templateData{Notification: notification}
+	if len(notification.Data) > 0 {
+		_ = json.Unmarshal(notification.Data, &data.Fields)
+	}
+	var buf bytes.Buffer
+	if errExecute := tmpl.Execute(&buf, data); errExecute != nil {
+		return nil, fmt.Errorf("render template: %w", errExecute)
+	}
+	return buf.Bytes(), nil
+}
+
+// Sign returns the signature header value for body signed at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// sendWebhook posts notification to target. Any 2xx response is a success.
+func sendWebhook(ctx context.Context, client *http.Client, target config.NotificationTarget, notification Notification) error {
+	body, errRender := renderBody(target, notification)
+	if errRender != nil {
+		return errRender
+	}
+	timeout := time.Duration(target.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = config.DefaultNotificationTimeoutSeconds * time.Second
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, errRequest := http.NewRequestWithContext(reqCtx, http.MethodPost, target.URL, bytes.NewReader(body))
+	if errRequest != nil {
+		return fmt.Errorf("build request: %w", errRequest)
+	}
+	contentType := target.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "CLIProxyAPI-Notifier")
+	req.Header.Set(EventHeader, notification.Type)
+	for name, value := range target.Headers {
+		req.Header.Set(name, value)
+	}
+	if target.Secret != "" {
+		timestamp := time.Now().Unix()
+		req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(SignatureHeader, Sign(target.Secret, timestamp, body))
+	}
+
+	resp, errDo := client.Do(req)
+	if errDo != nil {
+		return errDo
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	if text := strings.TrimSpace(string(snippet)); text != "" {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, text)
+	}
+	return fmt.Errorf("unexpected status %d", resp.StatusCode)
+}
@@ -0,0 +1,195 @@
+// Package outputfilter redacts configured patterns from model output after
+// translation. Complete response bodies are filtered in one pass; streamed
+// responses go through a rolling buffer so matches split across chunks are
+// still caught.
+package outputfilter
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+type rule struct {
+	name        string
+	re          *regexp.Regexp
+	replacement string
+}
+
+// Filter applies a compiled set of redaction rules.
+type Filter struct {
+	rules  []rule
+	window int
+}
+
+// New compiles cfg into a Filter. It returns nil when filtering is disabled
+// or no rules are configured.
+func New(cfg config.OutputFilterConfig) (*Filter, error) {
+	if !cfg.Enabled || len(cfg.Rules) == 0 {
+		return nil, nil
+	}
+	f := &Filter{window: cfg.WindowOrDefault(), rules: make([]rule, 0, len(cfg.Rules))}
+	for i, item := range cfg.Rules {
+		if item.Pattern == "" {
+			continue
+		}
+		re, errCompile := regexp.Compile(item.Pattern)
+		if errCompile != nil {
+			return nil, fmt.Errorf("output filter rule %d: %w", i+1, errCompile)
+		}
+		name := strings.TrimSpace(item.Name)
+		if name == "" {
+			name = "rule-" + strconv.Itoa(i+1)
+		}
+		replacement := item.Replacement
+		if replacement == "" {
+			replacement = config.DefaultOutputFilterReplacement
+		}
+		f.rules = append(f.rules, rule{name: name, re: re, replacement: replacement})
+	}
+	if len(f.rules) == 0 {
+		return nil, nil
+	}
+	return f, nil
+}
+
+var (
+	cacheMu  sync.Mutex
+	cacheKey string
+	cacheVal *Filter
+)
+
+// FromConfig returns the Filter for the current SDK configuration, reusing the
+// last compiled filter while the rules are unchanged. Invalid configurations
+// yield nil; LoadConfig already drops rules that do not compile.
+func FromConfig(cfg *config.SDKConfig) *Filter {
+	if cfg == nil || !cfg.OutputFilter.Enabled || len(cfg.OutputFilter.Rules) == 0 {
+		return nil
+	}
+	key, errKey := json.Marshal(cfg.OutputFilter)
+	if errKey != nil {
+		return nil
+	}
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if cacheVal != nil && cacheKey == string(key) {
+		return cacheVal
+	}
+	f, errNew := New(cfg.OutputFilter)
+	if errNew != nil {
+		return nil
+	}
+	cacheKey = string(key)
+	cacheVal = f
+	return f
+}
+
+// Window returns the rolling buffer size used for streams.
+func (f *Filter) Window() int {
+	if f == nil {
+		return 0
+	}
+	return f.window
+}
+
+// RedactString applies every rule to text and records matches in stats.
+func (f *Filter) RedactString(text string, stats *Stats) string {
+	if f == nil || text == "" {
+		return text
+	}
+	for i := range f.rules {
+		r := &f.rules[i]
+		matches := r.re.FindAllStringIndex(text, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, m := range matches {
+			if m[0] == m[1] {
+				continue
+			}
+			b.WriteString(text[last:m[0]])
+			b.WriteString(r.replacement)
+			last = m[1]
+			stats.add(r.name, 1)
+		}
+		b.WriteString(text[last:])
+		text = b.String()
+	}
+	return text
+}
+
+// ApplyBody redacts a complete response body. JSON string values are
+// filtered individually so the document stays valid; other payloads are
+// filtered as plain text.
+func (f *Filter) ApplyBody(body []byte, stats *Stats) []byte {
+	if f == nil || len(body) == 0 {
+		return body
+	}
+	if out, ok := f.redactJSON(body, stats); ok {
+		return out
+	}
+	if sse, ok := f.redactSSE(body, stats); ok {
+		return sse
+	}
+	return []byte(f.RedactString(string(body), stats))
+}
+
+func (f *Filter) redactJSON(payload []byte, stats *Stats) ([]byte, bool) {
+	trimmed := trimJSONSpace(payload)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') || !json.Valid(trimmed) {
+		return nil, false
+	}
+	tokens := scanJSONStrings(payload)
+	changed := false
+	for i := range tokens {
+		if tokens[i].isKey {
+			continue
+		}
+		redacted := f.RedactString(tokens[i].value, stats)
+		if redacted != tokens[i].value {
+			tokens[i].value = redacted
+			tokens[i].dirty = true
+			changed = true
+		}
+	}
+	if !changed {
+		return payload, true
+	}
+	return rewriteJSONStrings(payload, tokens), true
+}
+
+// redactSSE filters each data line of an SSE body that was buffered into a
+// single non-streaming response.
+func (f *Filter) redactSSE(body []byte, stats *Stats) ([]byte, bool) {
+	parts := splitChunk(body)
+	if len(parts) == 0 {
+		return nil, false
+	}
+	hasJSON := false
+	for _, part := range parts {
+		if part.json {
+			hasJSON = true
+			break
+		}
+	}
+	if !hasJSON {
+		return nil, false
+	}
+	out := make([]byte, 0, len(body))
+	for _, part := range parts {
+		if part.json {
+			redacted, _ := f.redactJSON(part.data, stats)
+			out = append(out, redacted...)
+			continue
+		}
+		out = append(out, f.RedactString(string(part.data), stats)...)
+	}
+	return out, true
+}
@@ -0,0 +1,127 @@
+package outputfilter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/tidwall/gjson"
+)
+
+func newTestFilter(t *testing.T, window int) *Filter {
+	t.Helper()
+	f, errNew := New(config.OutputFilterConfig{
+		Enabled: true,
+		Window:  window,
+		Rules: []config.OutputFilterRule{
+			{Name: "api-key", Pattern: `sk-[A-Za-z0-9]{8,}`},
+			{Name: "host", Pattern: `[a-z0-9-]+\.corp\.internal`, Replacement: "[host]"},
+		},
+	})
+	if errNew != nil {
+		t.Fatalf("New() error = %v", errNew)
+	}
+	return f
+}
+
+func TestNewReturnsNilWhenDisabled(t *testing.T) {
+	f, errNew := New(config.OutputFilterConfig{Rules: []config.OutputFilterRule{{Pattern: "x"}}})
+	if errNew != nil || f != nil {
+		t.Fatalf("New(disabled) = (%v, %v), want (nil, nil)", f, errNew)
+	}
+}
+
+func TestApplyBodyRedactsJSONStringValues(t *testing.T) {
+	f := newTestFilter(t, 0)
+	stats := &Stats{}
+	body := []byte(`{"id":"resp_1","choices":[{"message":{"content":"key sk-ABCDEFGH123 on db1.corp.internal"}}]}`)
+
+	got := f.ApplyBody(body, stats)
+
+	if !json.Valid(got) {
+		t.Fatalf("ApplyBody() produced invalid JSON: %s", got)
+	}
+	content := gjson.GetBytes(got, "choices.0.message.content").String()
+	if content != "key [REDACTED] on [host]" {
+		t.Fatalf("content = %q, want redacted", content)
+	}
+	if gjson.GetBytes(got, "id").String() != "resp_1" {
+		t.Fatalf("id changed: %s", got)
+	}
+	counts := stats.Counts()
+	if counts["api-key"] != 1 || counts["host"] != 1 {
+		t.Fatalf("counts = %v, want one match per rule", counts)
+	}
+}
+
+func TestApplyBodyKeepsUnmatchedBodyUnchanged(t *testing.T) {
+	f := newTestFilter(t, 0)
+	body := []byte(`{"a": "plain text",  "b": [1, 2]}`)
+	if got := f.ApplyBody(body, nil); string(got) != string(body) {
+		t.Fatalf("ApplyBody() = %s, want unchanged", got)
+	}
+}
+
+func TestStreamRedactsMatchSplitAcrossChunks(t *testing.T) {
+	f := newTestFilter(t, 16)
+	stats := &Stats{}
+	stream := f.NewStream(stats)
+	chunks := []string{
+		`data: {"choices":[{"delta":{"content":"token is sk-ABC"}}]}` + "\n\n",
+		`data: {"choices":[{"delta":{"content":"DEF12345 done"}}]}` + "\n\n",
+		`data: {"choices":[{"delta":{"content":" and more trailing text here"}}]}` + "\n\n",
+		"data: [DONE]\n\n",
+	}
+	var out [][]byte
+	for _, chunk := range chunks {
+		out = append(out, stream.Push([]byte(chunk))...)
+	}
+	out = append(out, stream.Flush()...)
+
+	if len(out) != len(chunks) {
+		t.Fatalf("emitted %d chunks, want %d", len(out), len(chunks))
+	}
+	var text strings.Builder
+	for _, chunk := range out[:3] {
+		payload := strings.TrimSuffix(strings.TrimPrefix(string(chunk), "data: "), "\n\n")
+		text.WriteString(gjson.Get(payload, "choices.0.delta.content").String())
+	}
+	if got, want := text.String(), "token is [REDACTED] done and more trailing text here"; got != want {
+		t.Fatalf("joined text = %q, want %q", got, want)
+	}
+	if string(out[3]) != "data: [DONE]\n\n" {
+		t.Fatalf("terminal chunk = %q", out[3])
+	}
+	if stats.Total() != 1 {
+		t.Fatalf("Total() = %d, want 1", stats.Total())
+	}
+}
+
+func TestStreamHoldsTextUntilWindowFilled(t *testing.T) {
+	f := newTestFilter(t, 32)
+	stream := f.NewStream(nil)
+	if got := stream.Push([]byte(`{"type":"response.output_text.delta","delta":"short"}`)); len(got) != 0 {
+		t.Fatalf("Push() released %d chunks before window filled", len(got))
+	}
+	got := stream.Push([]byte(`{"type":"response.output_text.delta","delta":"` + strings.Repeat("x", 40) + `"}`))
+	if len(got) != 1 || gjson.GetBytes(got[0], "delta").String() != "short" {
+		t.Fatalf("Push() = %q, want first chunk released", got)
+	}
+	if rest := stream.Flush(); len(rest) != 1 {
+		t.Fatalf("Flush() released %d chunks, want 1", len(rest))
+	}
+}
+
+func TestStreamFiltersNonTextFieldsWithinChunk(t *testing.T) {
+	f := newTestFilter(t, 8)
+	stream := f.NewStream(nil)
+	out := stream.Push([]byte(`{"type":"response.completed","response":{"instructions":"use api.corp.internal"}}`))
+	out = append(out, stream.Flush()...)
+	if len(out) != 1 {
+		t.Fatalf("emitted %d chunks, want 1", len(out))
+	}
+	if got := gjson.GetBytes(out[0], "response.instructions").String(); got != "use [host]" {
+		t.Fatalf("instructions = %q, want redacted", got)
+	}
+}
@@ -0,0 +1,128 @@
+package outputfilter
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// jsonString is a string token located in a JSON document.
+type jsonString struct {
+	start int // offset of the opening quote
+	end   int // offset just past the closing quote
+	isKey bool
+	// field is the name of the object member holding the value. Strings inside
+	// arrays inherit the member name of the array.
+	field string
+	value string
+	dirty bool
+}
+
+type jsonFrame struct {
+	array bool
+	field string // member name of this container in its parent
+	key   string // last member name seen inside an object
+}
+
+// scanJSONStrings returns every string token in a valid JSON document in
+// document order, tagged with the member name it belongs to.
+func scanJSONStrings(payload []byte) []jsonString {
+	var tokens []jsonString
+	var stack []jsonFrame
+	currentField := func() string {
+		if len(stack) == 0 {
+			return ""
+		}
+		top := stack[len(stack)-1]
+		if top.array {
+			return top.field
+		}
+		return top.key
+	}
+	for i := 0; i < len(payload); i++ {
+		switch payload[i] {
+		case '{', '[':
+			stack = append(stack, jsonFrame{array: payload[i] == '[', field: currentField()})
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case '"':
+			end := stringTokenEnd(payload, i)
+			if end < 0 {
+				return tokens
+			}
+			var value string
+			if errUnmarshal := json.Unmarshal(payload[i:end], &value); errUnmarshal != nil {
+				return tokens
+			}
+			isKey := false
+			if len(stack) > 0 && !stack[len(stack)-1].array {
+				next := end
+				for next < len(payload) && isJSONSpace(payload[next]) {
+					next++
+				}
+				isKey = next < len(payload) && payload[next] == ':'
+			}
+			token := jsonString{start: i, end: end, isKey: isKey, value: value}
+			if isKey {
+				stack[len(stack)-1].key = value
+			} else {
+				token.field = currentField()
+			}
+			tokens = append(tokens, token)
+			i = end - 1
+		}
+	}
+	return tokens
+}
+
+// stringTokenEnd returns the offset just past the closing quote of the string
+// token starting at start, or -1 when the token is unterminated.
+func stringTokenEnd(payload []byte, start int) int {
+	for i := start + 1; i < len(payload); i++ {
+		switch payload[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// rewriteJSONStrings re-encodes dirty tokens and copies everything else verbatim.
+func rewriteJSONStrings(payload []byte, tokens []jsonString) []byte {
+	var out bytes.Buffer
+	out.Grow(len(payload))
+	last := 0
+	for _, token := range tokens {
+		if !token.dirty {
+			continue
+		}
+		out.Write(payload[last:token.start])
+		out.Write(encodeJSONString(token.value))
+		last = token.end
+	}
+	out.Write(payload[last:])
+	return out.Bytes()
+}
+
+func encodeJSONString(value string) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if errEncode := enc.Encode(value); errEncode != nil {
+		return []byte(`""`)
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n")
+}
+
+func isJSONSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+func trimJSONSpace(payload []byte) []byte {
+	return bytes.TrimFunc(payload, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+}
@@ -0,0 +1,95 @@
+package outputfilter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type statsContextKey struct{}
+
+// Stats counts redactions per rule for a single request.
+type Stats struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+// WithStats returns ctx carrying a redaction counter, reusing an existing one.
+func WithStats(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if StatsFromContext(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, statsContextKey{}, &Stats{})
+}
+
+// StatsFromContext returns the redaction counter stored in ctx, if any.
+func StatsFromContext(ctx context.Context) *Stats {
+	if ctx == nil {
+		return nil
+	}
+	stats, _ := ctx.Value(statsContextKey{}).(*Stats)
+	return stats
+}
+
+func (s *Stats) add(rule string, n int64) {
+	if s == nil || n <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.counts == nil {
+		s.counts = make(map[string]int64)
+	}
+	s.counts[rule] += n
+}
+
+// Counts returns a snapshot of redactions keyed by rule name, or nil when none occurred.
+func (s *Stats) Counts() map[string]int64 {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.counts) == 0 {
+		return nil
+	}
+	out := make(map[string]int64, len(s.counts))
+	for rule, count := range s.counts {
+		out[rule] = count
+	}
+	return out
+}
+
+// Total returns the number of redactions across all rules.
+func (s *Stats) Total() int64 {
+	var total int64
+	for _, count := range s.Counts() {
+		total += count
+	}
+	return total
+}
+
+// LogSection formats the counters as a request log section.
+func (s *Stats) LogSection() []byte {
+	counts := s.Counts()
+	if len(counts) == 0 {
+		return nil
+	}
+	rules := make([]string, 0, len(counts))
+	for rule := range counts {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	var b strings.Builder
+	b.WriteString("=== OUTPUT REDACTIONS ===\n")
+	for _, rule := range rules {
+		fmt.Fprintf(&b, "%s: %d\n", rule, counts[rule])
+	}
+	b.WriteString("\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,254 @@
+package outputfilter
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// streamTextFields names the JSON members that carry incremental model text
+// in the translated stream formats (OpenAI chat and Responses, Claude, Gemini).
+// Their values are joined across chunks before matching; every other string
+// value is filtered within its own chunk.
+var streamTextFields = map[string]struct{}{
+	"content":           {},
+	"text":              {},
+	"delta":             {},
+	"arguments":         {},
+	"partial_json":      {},
+	"thinking":          {},
+	"reasoning_content": {},
+	"refusal":           {},
+}
+
+// chunkPart is a slice of a stream chunk: either a JSON payload or raw framing.
+type chunkPart struct {
+	data   []byte
+	json   bool
+	tokens []jsonString
+}
+
+type streamChunk struct {
+	parts   []chunkPart
+	textLen int
+}
+
+// Stream redacts a sequence of output chunks. It holds back the most recent
+// Window bytes of streamed text so a match split across chunks is replaced
+// before any part of it reaches the client. Stream is not safe for
+// concurrent use.
+type Stream struct {
+	filter  *Filter
+	stats   *Stats
+	pending []*streamChunk
+}
+
+// NewStream returns a rolling redactor that records matches in stats.
+func (f *Filter) NewStream(stats *Stats) *Stream {
+	return &Stream{filter: f, stats: stats}
+}
+
+// Push adds a chunk and returns the chunks that are now safe to emit, in order.
+func (s *Stream) Push(chunk []byte) [][]byte {
+	if s == nil || s.filter == nil {
+		return [][]byte{chunk}
+	}
+	parts := splitChunk(chunk)
+	pending := &streamChunk{parts: parts}
+	for i := range pending.parts {
+		for _, token := range pending.parts[i].tokens {
+			if isStreamText(token) {
+				pending.textLen += len(token.value)
+			}
+		}
+	}
+	s.pending = append(s.pending, pending)
+	return s.drain(false)
+}
+
+// Flush releases every held chunk. Call it once the upstream stream ends.
+func (s *Stream) Flush() [][]byte {
+	if s == nil || s.filter == nil {
+		return nil
+	}
+	return s.drain(true)
+}
+
+func (s *Stream) drain(final bool) [][]byte {
+	if len(s.pending) == 0 {
+		return nil
+	}
+	after := make([]int, len(s.pending))
+	total := 0
+	for i := len(s.pending) - 1; i >= 0; i-- {
+		after[i] = total
+		total += s.pending[i].textLen
+	}
+	release := 0
+	boundary := 0
+	for i, chunk := range s.pending {
+		if !final && chunk.textLen > 0 && after[i] < s.filter.window {
+			break
+		}
+		release++
+		boundary += chunk.textLen
+	}
+	if release == 0 {
+		return nil
+	}
+	s.redactPending(boundary, final)
+
+	out := make([][]byte, 0, release)
+	for _, chunk := range s.pending[:release] {
+		out = append(out, s.render(chunk))
+	}
+	for i := 0; i < release; i++ {
+		s.pending[i] = nil
+	}
+	s.pending = s.pending[release:]
+	return out
+}
+
+// redactPending applies each rule across the joined text of every held chunk.
+// Only matches starting before boundary are applied; later ones are found
+// again once more text arrives or the stream ends.
+func (s *Stream) redactPending(boundary int, final bool) {
+	var slots []*jsonString
+	for _, chunk := range s.pending {
+		for i := range chunk.parts {
+			for j := range chunk.parts[i].tokens {
+				if isStreamText(chunk.parts[i].tokens[j]) {
+					slots = append(slots, &chunk.parts[i].tokens[j])
+				}
+			}
+		}
+	}
+	if len(slots) == 0 {
+		return
+	}
+	for i := range s.filter.rules {
+		r := &s.filter.rules[i]
+		var joined bytes.Buffer
+		starts := make([]int, len(slots))
+		for k, slot := range slots {
+			starts[k] = joined.Len()
+			joined.WriteString(slot.value)
+		}
+		matches := r.re.FindAllStringIndex(joined.String(), -1)
+		// Apply right to left so earlier offsets stay valid.
+		for m := len(matches) - 1; m >= 0; m-- {
+			a, b := matches[m][0], matches[m][1]
+			if a == b || (!final && a >= boundary) {
+				continue
+			}
+			inserted := false
+			for k, slot := range slots {
+				start, end := starts[k], starts[k]+len(slot.value)
+				if start >= b || end <= a {
+					continue
+				}
+				lo := max(a, start) - start
+				hi := min(b, end) - start
+				if !inserted {
+					slot.value = slot.value[:lo] + r.replacement + slot.value[hi:]
+					inserted = true
+				} else {
+					slot.value = slot.value[:lo] + slot.value[hi:]
+				}
+				slot.dirty = true
+			}
+			s.stats.add(r.name, 1)
+		}
+	}
+}
+
+func (s *Stream) render(chunk *streamChunk) []byte {
+	var out bytes.Buffer
+	for i := range chunk.parts {
+		part := &chunk.parts[i]
+		if !part.json {
+			out.WriteString(s.filter.RedactString(string(part.data), s.stats))
+			continue
+		}
+		changed := false
+		for j := range part.tokens {
+			token := &part.tokens[j]
+			if token.isKey || isStreamText(*token) {
+				changed = changed || token.dirty
+				continue
+			}
+			redacted := s.filter.RedactString(token.value, s.stats)
+			if redacted != token.value {
+				token.value = redacted
+				token.dirty = true
+				changed = true
+			}
+		}
+		if changed {
+			out.Write(rewriteJSONStrings(part.data, part.tokens))
+		} else {
+			out.Write(part.data)
+		}
+	}
+	return out.Bytes()
+}
+
+func isStreamText(token jsonString) bool {
+	if token.isKey {
+		return false
+	}
+	_, ok := streamTextFields[token.field]
+	return ok
+}
+
+// splitChunk separates JSON payloads from SSE framing. A chunk is either a bare
+// JSON document or a sequence of SSE lines whose data fields may hold JSON.
+func splitChunk(chunk []byte) []chunkPart {
+	if len(chunk) == 0 {
+		return nil
+	}
+	trimmed := trimJSONSpace(chunk)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+		start := bytes.Index(chunk, trimmed)
+		parts := make([]chunkPart, 0, 3)
+		parts = appendRawPart(parts, chunk[:start])
+		parts = append(parts, newJSONPart(trimmed))
+		return appendRawPart(parts, chunk[start+len(trimmed):])
+	}
+	var parts []chunkPart
+	for _, line := range bytes.SplitAfter(chunk, []byte("\n")) {
+		if !bytes.HasPrefix(line, []byte("data:")) {
+			parts = appendRawPart(parts, line)
+			continue
+		}
+		prefix := len("data:")
+		if prefix < len(line) && line[prefix] == ' ' {
+			prefix++
+		}
+		body := bytes.TrimRight(line[prefix:], "\r\n")
+		if len(body) == 0 || (body[0] != '{' && body[0] != '[') || !json.Valid(body) {
+			parts = appendRawPart(parts, line)
+			continue
+		}
+		parts = appendRawPart(parts, line[:prefix])
+		parts = append(parts, newJSONPart(body))
+		parts = appendRawPart(parts, line[prefix+len(body):])
+	}
+	return parts
+}
+
+func newJSONPart(payload []byte) chunkPart {
+	return chunkPart{data: payload, json: true, tokens: scanJSONStrings(payload)}
+}
+
+func appendRawPart(parts []chunkPart, data []byte) []chunkPart {
+	if len(data) == 0 {
+		return parts
+	}
+	if n := len(parts); n > 0 && !parts[n-1].json {
+		merged := make([]byte, 0, len(parts[n-1].data)+len(data))
+		merged = append(merged, parts[n-1].data...)
+		parts[n-1].data = append(merged, data...)
+		return parts
+	}
+	return append(parts, chunkPart{data: data})
+}
@@ -436,22 +436,32 @@ func (s *pluginTokenStorage) RawJSON() []byte {
 }
 
 func (s *pluginTokenStorage) SaveTokenToFile(path string) error {
-	if s == nil {
-		return fmt.Errorf("plugin token storage is nil")
-	}
-	payload, errPayload := mergedStorageJSON(s.rawJSON, s.meta, s.provider)
+	payload, errPayload := s.MarshalToken()
 	if errPayload != nil {
 		return errPayload
 	}
-	if len(bytes.TrimSpace(payload)) == 0 {
-		return fmt.Errorf("plugin token storage payload is empty")
-	}
 	if pluginTokenStorageFileCurrent(path, payload) {
 		return nil
 	}
 	return atomicWriteFile(path, payload)
 }
 
+// MarshalToken renders the merged storage JSON in memory so it can be sealed
+// before it reaches disk.
+func (s *pluginTokenStorage) MarshalToken() ([]byte, error) {
+	if s == nil {
+		return nil, fmt.Errorf("plugin token storage is nil")
+	}
+	payload, errPayload := mergedStorageJSON(s.rawJSON, s.meta, s.provider)
+	if errPayload != nil {
+		return nil, errPayload
+	}
+	if len(bytes.TrimSpace(payload)) == 0 {
+		return nil, fmt.Errorf("plugin token storage payload is empty")
+	}
+	return payload, nil
+}
+
 func pluginTokenStorageFileCurrent(path string, payload []byte) bool {
 	if strings.TrimSpace(path) == "" || len(bytes.TrimSpace(payload)) == 0 {
 		return false
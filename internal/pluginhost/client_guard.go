@@ -126,3 +126,20 @@ func (c *guardedPluginClient) ShutdownContext(ctx context.Context) {
 	case <-ctx.Done():
 	}
 }
+
+// holdPluginClient keeps client from shutting down until the returned release
+// runs. It covers work that outlives a single call, such as async streams that
+// must finish on a plugin instance already replaced by an upgrade.
+func holdPluginClient(client pluginClient) func() {
+	guarded, ok := client.(*guardedPluginClient)
+	if !ok {
+		return func() {}
+	}
+	if _, errAcquire := guarded.acquire(); errAcquire != nil {
+		return func() {}
+	}
+	var once sync.Once
+	return func() {
+		once.Do(guarded.release)
+	}
+}
@@ -0,0 +1,142 @@
+package pluginhost
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed five-field cron expression evaluated in UTC:
+// minute hour day-of-month month day-of-week. Fields accept *, lists, ranges
+// and steps such as */15 or 1-5/2. Day-of-week 0 and 7 both mean Sunday.
+type cronSchedule struct {
+	minutes  [60]bool
+	hours    [24]bool
+	days     [32]bool
+	months   [13]bool
+	weekdays [7]bool
+	// anyDay and anyWeekday record a bare *; when both day fields are
+	// restricted a time matches either of them, as in classic cron.
+	anyDay     bool
+	anyWeekday bool
+}
+
+// maxCronSearchSteps bounds the search for the next run so impossible dates
+// such as 30 February are reported instead of looping.
+const maxCronSearchSteps = 20000
+
+func parseCronSchedule(expression string) (*cronSchedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields", expression)
+	}
+	schedule := &cronSchedule{}
+	var weekdays [8]bool
+	for index, target := range []struct {
+		name     string
+		min, max int
+		out      []bool
+	}{
+		{name: "minute", min: 0, max: 59, out: schedule.minutes[:]},
+		{name: "hour", min: 0, max: 23, out: schedule.hours[:]},
+		{name: "day-of-month", min: 1, max: 31, out: schedule.days[:]},
+		{name: "month", min: 1, max: 12, out: schedule.months[:]},
+		{name: "day-of-week", min: 0, max: 7, out: weekdays[:]},
+	} {
+		if errField := parseCronField(fields[index], target.min, target.max, target.out); errField != nil {
+			return nil, fmt.Errorf("cron %s field: %w", target.name, errField)
+		}
+	}
+	for day := 0; day < 7; day++ {
+		schedule.weekdays[day] = weekdays[day]
+	}
+	if weekdays[7] {
+		schedule.weekdays[0] = true
+	}
+	schedule.anyDay = fields[2] == "*"
+	schedule.anyWeekday = fields[4] == "*"
+	if _, errNext := schedule.next(time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)); errNext != nil {
+		return nil, errNext
+	}
+	return schedule, nil
+}
+
+func parseCronField(field string, min, max int, out []bool) error {
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			parsed, errStep := strconv.Atoi(stepPart)
+			if errStep != nil || parsed <= 0 {
+				return fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = parsed
+		}
+		low, high := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			lowText, highText, _ := strings.Cut(rangePart, "-")
+			var errLow, errHigh error
+			low, errLow = strconv.Atoi(lowText)
+			high, errHigh = strconv.Atoi(highText)
+			if errLow != nil || errHigh != nil {
+				return fmt.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			value, errValue := strconv.Atoi(rangePart)
+			if errValue != nil {
+				return fmt.Errorf("invalid value %q", rangePart)
+			}
+			low = value
+			if hasStep {
+				high = max
+			} else {
+				high = value
+			}
+		}
+		if low < min || high > max || low > high {
+			return fmt.Errorf("value %q is outside %d-%d", part, min, max)
+		}
+		for value := low; value <= high; value += step {
+			out[value] = true
+		}
+	}
+	return nil
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	day := s.days[t.Day()]
+	weekday := s.weekdays[int(t.Weekday())]
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+// next returns the first matching minute strictly after after.
+func (s *cronSchedule) next(after time.Time) (time.Time, error) {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	for step := 0; step < maxCronSearchSteps; step++ {
+		switch {
+		case !s.months[int(t.Month())]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !s.hours[t.Hour()]:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case !s.minutes[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cron expression never fires")
+}
@@ -0,0 +1,275 @@
+package pluginhost
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/events"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultPluginEventQueueSize = 256
+	maxPluginEventQueueSize     = 4096
+	pluginEventCallTimeout      = 30 * time.Second
+)
+
+// PluginEventStats reports event delivery counters of an EventSubscriber plugin.
+type PluginEventStats struct {
+	Topics    []string
+	QueueSize int
+	Queued    int
+	Delivered uint64
+	Failed    uint64
+	Dropped   uint64
+}
+
+// pluginEventQueue buffers the events of one subscribed plugin. A full queue
+// drops new events instead of blocking the bus or other plugins.
+type pluginEventQueue struct {
+	pluginID string
+	topics   []string
+	filter   events.Filter
+	ch       chan events.Event
+	done     chan struct{}
+
+	delivered atomic.Uint64
+	failed    atomic.Uint64
+	dropped   atomic.Uint64
+	// pendingDrops counts drops not yet reported to the plugin on HostEvent.Dropped.
+	pendingDrops atomic.Uint64
+}
+
+func (q *pluginEventQueue) offer(event events.Event) {
+	if !q.filter.Match(event.Type) {
+		return
+	}
+	select {
+	case q.ch <- event:
+	default:
+		q.dropped.Add(1)
+		q.pendingDrops.Add(1)
+	}
+}
+
+// pluginEventDispatcher forwards host bus events to subscribed plugins. It
+// holds one bus subscription while at least one plugin subscribes.
+type pluginEventDispatcher struct {
+	mu     sync.Mutex
+	bus    *events.Bus
+	sub    *events.Subscription
+	lastID uint64
+	queues map[string]*pluginEventQueue
+}
+
+func newPluginEventDispatcher(bus *events.Bus) *pluginEventDispatcher {
+	return &pluginEventDispatcher{bus: bus, queues: make(map[string]*pluginEventQueue)}
+}
+
+// retainEventSubscribers starts queues for active subscribers, restarts queues
+// whose topics or size changed, and stops the queues of plugins that no longer
+// subscribe.
+func (h *Host) retainEventSubscribers(records []capabilityRecord) {
+	d := h.eventDispatcher
+	if d == nil {
+		return
+	}
+	wanted := make(map[string]pluginapi.Capabilities)
+	for _, record := range records {
+		if record.plugin.Capabilities.EventSubscriber != nil && eventSubscriptionsSupported(record.plugin.SchemaVersion) {
+			wanted[record.id] = record.plugin.Capabilities
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for id, queue := range d.queues {
+		caps, ok := wanted[id]
+		if ok && slices.Equal(queue.topics, normalizedEventTopics(caps.EventTopics)) && cap(queue.ch) == pluginEventQueueSize(caps.EventQueueSize) {
+			continue
+		}
+		close(queue.done)
+		delete(d.queues, id)
+	}
+	for id, caps := range wanted {
+		if _, ok := d.queues[id]; ok {
+			continue
+		}
+		topics := normalizedEventTopics(caps.EventTopics)
+		queue := &pluginEventQueue{
+			pluginID: id,
+			topics:   topics,
+			filter:   events.Filter{Types: topics},
+			ch:       make(chan events.Event, pluginEventQueueSize(caps.EventQueueSize)),
+			done:     make(chan struct{}),
+		}
+		d.queues[id] = queue
+		go h.runPluginEventQueue(queue)
+	}
+
+	switch {
+	case len(d.queues) > 0 && d.sub == nil:
+		d.subscribeLocked(false)
+	case len(d.queues) == 0 && d.sub != nil:
+		d.sub.Close()
+		d.sub = nil
+	}
+}
+
+// subscribeLocked attaches to the bus. After the bus dropped a lagging
+// subscription it resumes from the last forwarded event so retained events
+// are not lost.
+func (d *pluginEventDispatcher) subscribeLocked(resume bool) {
+	sub, backlog, gap := d.bus.Subscribe(events.Filter{}, d.lastID, resume)
+	if sub == nil {
+		return
+	}
+	if gap {
+		log.Warn("pluginhost: plugin event subscription lost events while resubscribing")
+	}
+	d.sub = sub
+	for _, event := range backlog {
+		d.forwardLocked(event)
+	}
+	go d.pump(sub)
+}
+
+func (d *pluginEventDispatcher) pump(sub *events.Subscription) {
+	for event := range sub.C {
+		d.mu.Lock()
+		if d.sub == sub {
+			d.forwardLocked(event)
+		}
+		d.mu.Unlock()
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.sub == sub && sub.Lagged() {
+		d.subscribeLocked(true)
+	}
+}
+
+func (d *pluginEventDispatcher) forwardLocked(event events.Event) {
+	d.lastID = event.ID
+	for _, queue := range d.queues {
+		queue.offer(event)
+	}
+}
+
+func (d *pluginEventDispatcher) stats(pluginID string) *PluginEventStats {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	queue := d.queues[pluginID]
+	d.mu.Unlock()
+	if queue == nil {
+		return nil
+	}
+	return &PluginEventStats{
+		Topics:    append([]string(nil), queue.topics...),
+		QueueSize: cap(queue.ch),
+		Queued:    len(queue.ch),
+		Delivered: queue.delivered.Load(),
+		Failed:    queue.failed.Load(),
+		Dropped:   queue.dropped.Load(),
+	}
+}
+
+// PluginEventStats returns event delivery counters for a subscribed plugin, or
+// nil when the plugin does not subscribe to host events.
+func (h *Host) PluginEventStats(id string) *PluginEventStats {
+	if h == nil {
+		return nil
+	}
+	return h.eventDispatcher.stats(strings.TrimSpace(id))
+}
+
+func (h *Host) runPluginEventQueue(queue *pluginEventQueue) {
+	for {
+		select {
+		case <-queue.done:
+			return
+		case event := <-queue.ch:
+			if errDeliver := h.deliverPluginEvent(queue, event); errDeliver != nil {
+				queue.failed.Add(1)
+				log.WithFields(pluginLogFields(queue.pluginID, "", "", "")).Warnf("pluginhost: event %s delivery failed: %v", event.Type, errDeliver)
+				continue
+			}
+			queue.delivered.Add(1)
+		}
+	}
+}
+
+func (h *Host) deliverPluginEvent(queue *pluginEventQueue, event events.Event) (errDeliver error) {
+	var subscriber pluginapi.EventSubscriber
+	for _, record := range h.activeRecords() {
+		if record.id == queue.pluginID {
+			subscriber = record.plugin.Capabilities.EventSubscriber
+			break
+		}
+	}
+	if subscriber == nil || h.isPluginFused(queue.pluginID) {
+		return fmt.Errorf("plugin is not active")
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			h.fusePlugin(queue.pluginID, "EventSubscriber.HandleEvent", recovered)
+			errDeliver = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	ctx, cancel := context.WithTimeout(withHostCallbackPluginID(context.Background(), queue.pluginID), pluginEventCallTimeout)
+	defer cancel()
+	return subscriber.HandleEvent(ctx, pluginapi.HostEvent{
+		ID:      event.ID,
+		Type:    event.Type,
+		Time:    event.Time,
+		Data:    append([]byte(nil), event.Data...),
+		Dropped: queue.pendingDrops.Swap(0),
+	})
+}
+
+func pluginEventQueueSize(size int) int {
+	switch {
+	case size <= 0:
+		return defaultPluginEventQueueSize
+	case size > maxPluginEventQueueSize:
+		return maxPluginEventQueueSize
+	default:
+		return size
+	}
+}
+
+// normalizedEventTopics trims topics, strips a trailing ".*" like the
+// management event stream filter, and sorts them. An empty result, or any
+// "*" topic, subscribes to everything.
+func normalizedEventTopics(topics []string) []string {
+	seen := make(map[string]struct{}, len(topics))
+	out := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		topic = strings.TrimSuffix(strings.TrimSpace(topic), ".*")
+		if topic == "*" {
+			return nil
+		}
+		if topic == "" {
+			continue
+		}
+		if _, ok := seen[topic]; ok {
+			continue
+		}
+		seen[topic] = struct{}{}
+		out = append(out, topic)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	sort.Strings(out)
+	return out
+}
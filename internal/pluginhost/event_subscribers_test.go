@@ -0,0 +1,192 @@
+package pluginhost
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/events"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginabi"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
+)
+
+type eventSubscriberFunc func(context.Context, pluginapi.HostEvent) error
+
+func (f eventSubscriberFunc) HandleEvent(ctx context.Context, event pluginapi.HostEvent) error {
+	return f(ctx, event)
+}
+
+func newEventTestHost(t *testing.T, records ...capabilityRecord) (*Host, *events.Bus) {
+	t.Helper()
+	bus := events.NewBus(16)
+	host := newHostWithRecords(records...)
+	host.eventDispatcher = newPluginEventDispatcher(bus)
+	host.retainEventSubscribers(host.activeRecords())
+	t.Cleanup(func() { host.retainEventSubscribers(nil) })
+	return host, bus
+}
+
+func receiveHostEvent(t *testing.T, ch <-chan pluginapi.HostEvent) pluginapi.HostEvent {
+	t.Helper()
+	select {
+	case event := <-ch:
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a host event")
+		return pluginapi.HostEvent{}
+	}
+}
+
+func waitForEventStats(t *testing.T, host *Host, pluginID string, done func(*PluginEventStats) bool) *PluginEventStats {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		stats := host.PluginEventStats(pluginID)
+		if stats != nil && done(stats) {
+			return stats
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("event stats = %#v", stats)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestEventSubscriberReceivesMatchingTopics(t *testing.T) {
+	received := make(chan pluginapi.HostEvent, 8)
+	host, bus := newEventTestHost(t, capabilityRecord{
+		id: "alerts",
+		plugin: pluginapi.Plugin{SchemaVersion: pluginabi.SchemaVersionEvents, Capabilities: pluginapi.Capabilities{
+			EventSubscriber: eventSubscriberFunc(func(ctx context.Context, event pluginapi.HostEvent) error {
+				if pluginID := hostCallbackPluginIDFromContext(ctx); pluginID != "alerts" {
+					t.Errorf("callback plugin ID = %q, want alerts", pluginID)
+				}
+				received <- event
+				return nil
+			}),
+			EventTopics: []string{"auth.cooldown.*", " models.refreshed "},
+		}},
+	})
+
+	bus.Publish(events.TypeConfigReloaded, map[string]any{"file": "config.yaml"})
+	bus.Publish(events.TypeCooldownEntered, map[string]any{"auth_id": "a1"})
+	bus.Publish(events.TypeModelsRefreshed, map[string]any{"providers": []string{"claude"}})
+
+	first := receiveHostEvent(t, received)
+	second := receiveHostEvent(t, received)
+	if first.Type != pluginapi.HostEventAuthCooldownEntered || string(first.Data) != `{"auth_id":"a1"}` {
+		t.Fatalf("first event = %s %s", first.Type, first.Data)
+	}
+	if second.Type != pluginapi.HostEventModelsRefreshed || second.ID <= first.ID {
+		t.Fatalf("second event = %#v", second)
+	}
+	select {
+	case extra := <-received:
+		t.Fatalf("unexpected event %s", extra.Type)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	stats := waitForEventStats(t, host, "alerts", func(stats *PluginEventStats) bool { return stats.Delivered == 2 })
+	if stats.Dropped != 0 || !reflect.DeepEqual(stats.Topics, []string{"auth.cooldown", "models.refreshed"}) {
+		t.Fatalf("stats = %#v", stats)
+	}
+	if infos := host.RegisteredPlugins(); len(infos) != 1 || infos[0].Events == nil {
+		t.Fatalf("RegisteredPlugins() = %#v", infos)
+	}
+}
+
+func TestEventSubscriberQueueDropsWhenFull(t *testing.T) {
+	release := make(chan struct{})
+	received := make(chan pluginapi.HostEvent, 8)
+	host, bus := newEventTestHost(t, capabilityRecord{
+		id: "slow",
+		plugin: pluginapi.Plugin{SchemaVersion: pluginabi.SchemaVersionEvents, Capabilities: pluginapi.Capabilities{
+			EventSubscriber: eventSubscriberFunc(func(_ context.Context, event pluginapi.HostEvent) error {
+				received <- event
+				<-release
+				return nil
+			}),
+			EventQueueSize: 1,
+		}},
+	})
+
+	bus.Publish(events.TypeAuthStatus, nil)
+	receiveHostEvent(t, received)
+	// The worker is blocked on the first event: one more fits in the queue and
+	// the remaining two are dropped.
+	for i := 0; i < 3; i++ {
+		bus.Publish(events.TypeAuthStatus, nil)
+	}
+	waitForEventStats(t, host, "slow", func(stats *PluginEventStats) bool { return stats.Dropped == 2 })
+	close(release)
+
+	if next := receiveHostEvent(t, received); next.Dropped != 2 {
+		t.Fatalf("next event Dropped = %d, want 2", next.Dropped)
+	}
+}
+
+func TestRetainEventSubscribersStopsRemovedPlugins(t *testing.T) {
+	record := capabilityRecord{
+		id: "alerts",
+		plugin: pluginapi.Plugin{SchemaVersion: pluginabi.SchemaVersionEvents, Capabilities: pluginapi.Capabilities{
+			EventSubscriber: eventSubscriberFunc(func(context.Context, pluginapi.HostEvent) error { return nil }),
+		}},
+	}
+	host, _ := newEventTestHost(t, record)
+	if host.PluginEventStats("alerts") == nil {
+		t.Fatal("subscriber queue was not started")
+	}
+	host.retainEventSubscribers(nil)
+	if host.PluginEventStats("alerts") != nil {
+		t.Fatal("subscriber queue survived removal")
+	}
+	if host.eventDispatcher.sub != nil {
+		t.Fatal("bus subscription kept without subscribers")
+	}
+}
+
+func TestEventSubscriberRequiresEventsSchemaVersion(t *testing.T) {
+	host, _ := newEventTestHost(t, capabilityRecord{
+		id: "legacy",
+		plugin: pluginapi.Plugin{SchemaVersion: pluginabi.SchemaVersionEvents - 1, Capabilities: pluginapi.Capabilities{
+			EventSubscriber: eventSubscriberFunc(func(context.Context, pluginapi.HostEvent) error { return nil }),
+		}},
+	})
+	if host.PluginEventStats("legacy") != nil {
+		t.Fatal("subscriber queue started for a plugin registered before schema version 5")
+	}
+}
+
+func TestNormalizedEventTopics(t *testing.T) {
+	if got := normalizedEventTopics([]string{"config.reloaded", "auth.*", "auth", ""}); !reflect.DeepEqual(got, []string{"auth", "config.reloaded"}) {
+		t.Fatalf("normalizedEventTopics() = %v", got)
+	}
+	if got := normalizedEventTopics([]string{"auth", "*"}); got != nil {
+		t.Fatalf("normalizedEventTopics(*) = %v, want nil", got)
+	}
+	if pluginEventQueueSize(0) != defaultPluginEventQueueSize || pluginEventQueueSize(1<<20) != maxPluginEventQueueSize {
+		t.Fatal("pluginEventQueueSize() does not clamp")
+	}
+}
+
+func TestHostEventTypesMatchBus(t *testing.T) {
+	for got, want := range map[string]string{
+		pluginapi.HostEventAuthStatus:          events.TypeAuthStatus,
+		pluginapi.HostEventAuthCooldownEntered: events.TypeCooldownEntered,
+		pluginapi.HostEventAuthCooldownExited:  events.TypeCooldownExited,
+		pluginapi.HostEventAuthRefresh:         events.TypeAuthRefresh,
+		pluginapi.HostEventAuthHealth:          events.TypeAuthHealth,
+		pluginapi.HostEventConfigReloaded:      events.TypeConfigReloaded,
+		pluginapi.HostEventModelsRefreshed:     events.TypeModelsRefreshed,
+		pluginapi.HostEventPluginLoaded:        events.TypePluginLoaded,
+		pluginapi.HostEventPluginUnloaded:      events.TypePluginUnloaded,
+		pluginapi.HostEventPluginCrashed:       events.TypePluginCrashed,
+		pluginapi.HostEventPluginRolledBack:    events.TypePluginRolledBack,
+		pluginapi.HostEventRequestCompleted:    events.TypeRequestCompleted,
+	} {
+		if got != want {
+			t.Fatalf("host event type %q, want %q", got, want)
+		}
+	}
+}
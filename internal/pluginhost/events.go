@@ -0,0 +1,70 @@
+package pluginhost
+
+import (
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/events"
+)
+
+// pluginEvent is the payload of plugin.loaded and plugin.unloaded management events.
+type pluginEvent struct {
+	ID      string `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+	Path    string `json:"path,omitempty"`
+	// HotReload marks a load that replaced an already running version.
+	HotReload bool `json:"hot_reload,omitempty"`
+}
+
+func publishPluginEvent(eventType, id, name, version, path string, hotReload bool) {
+	events.Publish(eventType, pluginEvent{
+		ID:        strings.TrimSpace(id),
+		Name:      strings.TrimSpace(name),
+		Version:   strings.TrimSpace(version),
+		Path:      strings.TrimSpace(path),
+		HotReload: hotReload,
+	})
+}
+
+// pluginCrashEvent is the payload of plugin.crashed management events.
+type pluginCrashEvent struct {
+	ID       string `json:"id"`
+	Path     string `json:"path,omitempty"`
+	Reason   string `json:"reason"`
+	Restarts int    `json:"restarts"`
+	// RestartIn is the backoff before the next start attempt, in milliseconds.
+	RestartIn int64 `json:"restart_in_ms"`
+}
+
+func publishPluginCrashEvent(id, path, reason string, restarts int, restartIn time.Duration) {
+	events.Publish(events.TypePluginCrashed, pluginCrashEvent{
+		ID:        strings.TrimSpace(id),
+		Path:      strings.TrimSpace(path),
+		Reason:    reason,
+		Restarts:  restarts,
+		RestartIn: restartIn.Milliseconds(),
+	})
+}
+
+// pluginRollbackEvent is the payload of plugin.rolled_back management events.
+type pluginRollbackEvent struct {
+	ID string `json:"id"`
+	// Version and Path identify the instance that stays active.
+	Version       string `json:"version,omitempty"`
+	Path          string `json:"path,omitempty"`
+	FailedVersion string `json:"failed_version,omitempty"`
+	FailedPath    string `json:"failed_path,omitempty"`
+	Reason        string `json:"reason"`
+}
+
+func publishPluginRollbackEvent(id, version, path, failedVersion, failedPath, reason string) {
+	events.Publish(events.TypePluginRolledBack, pluginRollbackEvent{
+		ID:            strings.TrimSpace(id),
+		Version:       strings.TrimSpace(version),
+		Path:          strings.TrimSpace(path),
+		FailedVersion: strings.TrimSpace(failedVersion),
+		FailedPath:    strings.TrimSpace(failedPath),
+		Reason:        reason,
+	})
+}
@@ -0,0 +1,423 @@
+package pluginhost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginabi"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
+	"github.com/tidwall/sjson"
+)
+
+// harnessStreamTimeout bounds how long Invoke waits for an asynchronous
+// executor stream to be closed by the plugin.
+const harnessStreamTimeout = 2 * time.Minute
+
+// Harness loads a single plugin outside the server so development tools can
+// inspect its registration and call its methods with fixtures. Host callbacks
+// are answered by in-process stubs instead of the live server.
+type Harness struct {
+	host         *Host
+	id           string
+	client       pluginClient
+	registration json.RawMessage
+	capabilities []string
+	stubs        *harnessStubs
+}
+
+// HarnessOptions configures OpenHarness.
+type HarnessOptions struct {
+	// ID overrides the plugin ID derived from the file name.
+	ID string
+	// ConfigYAML is passed to plugin.register as the plugin config block.
+	ConfigYAML []byte
+	// Stubs maps host callback methods to canned results. They take precedence
+	// over the built-in host.log, host.kv.* and host.timer.* stubs.
+	Stubs map[string]json.RawMessage
+	// Log receives host.log messages. Nil discards them.
+	Log func(level, message string)
+}
+
+// HarnessHostCall records one host callback issued by the plugin.
+type HarnessHostCall struct {
+	Method  string
+	Request json.RawMessage
+	Error   string
+}
+
+// HarnessChunk is one chunk emitted on an executor stream.
+type HarnessChunk struct {
+	Payload []byte
+	Error   string
+}
+
+// HarnessResult is the outcome of one plugin method call.
+type HarnessResult struct {
+	Method string
+	// Result is the envelope result of a successful call.
+	Result json.RawMessage
+	// Error describes a failed call or an error envelope.
+	Error string
+	// Chunks holds the stream of executor.execute_stream calls.
+	Chunks    []HarnessChunk
+	Duration  time.Duration
+	HostCalls []HarnessHostCall
+}
+
+// OpenHarness loads the plugin at path through the same loaders as the server
+// and registers it with opts.ConfigYAML.
+func OpenHarness(ctx context.Context, path string, opts HarnessOptions) (*Harness, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("plugin path is required")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	id := strings.TrimSpace(opts.ID)
+	if id == "" {
+		id = pluginIDFromPath(path)
+	}
+	if !validPluginID(id) {
+		return nil, fmt.Errorf("invalid plugin id %q", id)
+	}
+	return openHarness(ctx, New(), id, path, opts)
+}
+
+func openHarness(ctx context.Context, h *Host, id, path string, opts HarnessOptions) (*Harness, error) {
+	stubs := newHarnessStubs(id, opts)
+	h.callbackStub = stubs.call
+	client, errOpen := h.loaderFor(path).Open(pluginFile{ID: id, Path: path}, h)
+	if errOpen != nil {
+		return nil, errOpen
+	}
+	if client == nil {
+		return nil, fmt.Errorf("plugin loader returned nil client")
+	}
+	hs := &Harness{host: h, id: id, client: newGuardedPluginClient(client), stubs: stubs}
+
+	configYAML := opts.ConfigYAML
+	if len(bytes.TrimSpace(configYAML)) == 0 {
+		configYAML = defaultRuntimeConfigYAML
+	}
+	request, errMarshal := json.Marshal(rpcLifecycleRequest{ConfigYAML: configYAML, SchemaVersion: pluginabi.SchemaVersion})
+	if errMarshal != nil {
+		hs.Close()
+		return nil, errMarshal
+	}
+	result := hs.Invoke(ctx, pluginabi.MethodPluginRegister, request)
+	if result.Error != "" {
+		hs.Close()
+		return nil, fmt.Errorf("%s: %s", pluginabi.MethodPluginRegister, result.Error)
+	}
+	var registration rpcRegistration
+	if errUnmarshal := json.Unmarshal(result.Result, &registration); errUnmarshal != nil {
+		hs.Close()
+		return nil, fmt.Errorf("decode plugin registration: %w", errUnmarshal)
+	}
+	if registration.SchemaVersion > pluginabi.SchemaVersion {
+		hs.Close()
+		return nil, fmt.Errorf("plugin schema version %d is not supported", registration.SchemaVersion)
+	}
+	hs.registration = result.Result
+	hs.capabilities = harnessCapabilityNames(registration.Capabilities)
+	return hs, nil
+}
+
+// harnessCapabilityNames lists the enabled boolean capability flags by their
+// wire names.
+func harnessCapabilityNames(capabilities rpcCapabilities) []string {
+	raw, errMarshal := json.Marshal(capabilities)
+	if errMarshal != nil {
+		return nil
+	}
+	var fields map[string]any
+	if errUnmarshal := json.Unmarshal(raw, &fields); errUnmarshal != nil {
+		return nil
+	}
+	names := make([]string, 0, len(fields))
+	for name, value := range fields {
+		if enabled, ok := value.(bool); ok && enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ID returns the plugin ID used for host callbacks.
+func (hs *Harness) ID() string {
+	return hs.id
+}
+
+// Registration returns the raw plugin.register result.
+func (hs *Harness) Registration() json.RawMessage {
+	return append(json.RawMessage(nil), hs.registration...)
+}
+
+// Capabilities returns the capability flags the plugin registered.
+func (hs *Harness) Capabilities() []string {
+	return append([]string(nil), hs.capabilities...)
+}
+
+// HasCapability reports whether the plugin registered capability name.
+func (hs *Harness) HasCapability(name string) bool {
+	for _, capability := range hs.capabilities {
+		if capability == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Invoke calls method with a JSON request and reports the result, its timing
+// and the host callbacks made meanwhile. executor.execute_stream requests get
+// a stream_id and the emitted chunks are collected until the plugin closes the
+// stream.
+func (hs *Harness) Invoke(ctx context.Context, method string, request []byte) HarnessResult {
+	result := HarnessResult{Method: method}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	hs.stubs.takeCalls()
+
+	var collected chan []HarnessChunk
+	if method == pluginabi.MethodExecutorExecuteStream {
+		streamCtx, cancel := context.WithTimeout(ctx, harnessStreamTimeout)
+		defer cancel()
+		streamID, chunks, cleanup := hs.host.streams.open(streamCtx)
+		defer cleanup()
+		withStream, errSet := sjson.SetBytes(bytes.TrimSpace(request), "stream_id", streamID)
+		if errSet != nil {
+			result.Error = fmt.Sprintf("set stream_id: %v", errSet)
+			return result
+		}
+		request = withStream
+		collected = make(chan []HarnessChunk, 1)
+		go func() {
+			var out []HarnessChunk
+			for chunk := range chunks {
+				out = append(out, harnessChunk(chunk))
+			}
+			collected <- out
+		}()
+		ctx = streamCtx
+	}
+
+	started := time.Now()
+	raw, errCall := hs.client.Call(ctx, method, request)
+	result.Duration = time.Since(started)
+	if errCall != nil {
+		result.Error = errCall.Error()
+	} else {
+		var envelope pluginabi.Envelope
+		if errUnmarshal := json.Unmarshal(raw, &envelope); errUnmarshal != nil {
+			result.Error = fmt.Sprintf("decode plugin envelope: %v", errUnmarshal)
+		} else if !envelope.OK {
+			result.Error = harnessEnvelopeError(envelope.Error)
+		} else {
+			result.Result = envelope.Result
+		}
+	}
+
+	if collected != nil && result.Error == "" {
+		var streamResp rpcExecutorStreamResponse
+		if len(result.Result) > 0 {
+			_ = json.Unmarshal(result.Result, &streamResp)
+		}
+		if len(streamResp.Chunks) > 0 {
+			for _, chunk := range streamResp.Chunks {
+				result.Chunks = append(result.Chunks, harnessChunk(chunk))
+			}
+		} else {
+			// Asynchronous plugins keep emitting after the call returns.
+			result.Chunks = <-collected
+			result.Duration = time.Since(started)
+			if errContext := ctx.Err(); errContext != nil {
+				result.Error = fmt.Sprintf("stream was not closed: %v", errContext)
+			}
+		}
+	}
+	result.HostCalls = hs.stubs.takeCalls()
+	return result
+}
+
+func harnessChunk(chunk pluginapi.ExecutorStreamChunk) HarnessChunk {
+	out := HarnessChunk{Payload: append([]byte(nil), chunk.Payload...)}
+	if chunk.Err != nil {
+		out.Error = chunk.Err.Error()
+	}
+	return out
+}
+
+func harnessEnvelopeError(envelopeError *pluginabi.Error) string {
+	if envelopeError == nil {
+		return "plugin call failed"
+	}
+	code := strings.TrimSpace(envelopeError.Code)
+	message := strings.TrimSpace(envelopeError.Message)
+	switch {
+	case code == "":
+		return message
+	case message == "":
+		return code
+	default:
+		return code + ": " + message
+	}
+}
+
+// Close shuts the plugin down.
+func (hs *Harness) Close() {
+	if hs == nil {
+		return
+	}
+	shutdownPluginClient(context.Background(), hs.client)
+	hs.host.timers.retain(nil)
+}
+
+// harnessStubs answers host callbacks for the harness. host.kv.* keeps state
+// in memory, host.timer.* records schedules without firing them and host.log
+// is forwarded to HarnessOptions.Log. Executor stream callbacks still use the
+// real bridge so Invoke can collect chunks.
+type harnessStubs struct {
+	pluginID string
+	canned   map[string]json.RawMessage
+	log      func(level, message string)
+
+	mu     sync.Mutex
+	kv     map[string][]byte
+	timers map[string]pluginapi.HostTimerScheduleRequest
+	calls  []HarnessHostCall
+}
+
+func newHarnessStubs(pluginID string, opts HarnessOptions) *harnessStubs {
+	canned := make(map[string]json.RawMessage, len(opts.Stubs))
+	for method, result := range opts.Stubs {
+		canned[strings.TrimSpace(method)] = append(json.RawMessage(nil), result...)
+	}
+	return &harnessStubs{
+		pluginID: pluginID,
+		canned:   canned,
+		log:      opts.Log,
+		kv:       make(map[string][]byte),
+		timers:   make(map[string]pluginapi.HostTimerScheduleRequest),
+	}
+}
+
+func (s *harnessStubs) call(_ context.Context, method string, request []byte) ([]byte, bool, error) {
+	switch method {
+	case pluginabi.MethodHostStreamEmit, pluginabi.MethodHostStreamClose:
+		return nil, false, nil
+	}
+	response, errAnswer := s.answer(method, request)
+	call := HarnessHostCall{Method: method}
+	if json.Valid(request) {
+		call.Request = append(json.RawMessage(nil), request...)
+	}
+	if errAnswer != nil {
+		call.Error = errAnswer.Error()
+	}
+	s.mu.Lock()
+	s.calls = append(s.calls, call)
+	s.mu.Unlock()
+	return response, true, errAnswer
+}
+
+func (s *harnessStubs) takeCalls() []HarnessHostCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	calls := s.calls
+	s.calls = nil
+	return calls
+}
+
+func (s *harnessStubs) answer(method string, request []byte) ([]byte, error) {
+	if canned, ok := s.canned[method]; ok {
+		return json.Marshal(pluginabi.Envelope{OK: true, Result: canned})
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch method {
+	case pluginabi.MethodHostLog:
+		var req rpcHostLogRequest
+		if errUnmarshal := json.Unmarshal(request, &req); errUnmarshal != nil {
+			return nil, fmt.Errorf("decode host log request: %w", errUnmarshal)
+		}
+		if s.log != nil {
+			s.log(req.Level, req.Message)
+		}
+		return marshalRPCResult(rpcEmptyResponse{})
+	case pluginabi.MethodHostKVGet:
+		var req pluginapi.HostKVGetRequest
+		if errUnmarshal := json.Unmarshal(request, &req); errUnmarshal != nil {
+			return nil, fmt.Errorf("decode host kv get request: %w", errUnmarshal)
+		}
+		value, found := s.kv[req.Key]
+		return marshalRPCResult(pluginapi.HostKVGetResponse{Value: value, Found: found})
+	case pluginabi.MethodHostKVSet:
+		var req pluginapi.HostKVSetRequest
+		if errUnmarshal := json.Unmarshal(request, &req); errUnmarshal != nil {
+			return nil, fmt.Errorf("decode host kv set request: %w", errUnmarshal)
+		}
+		if errKey := validatePluginKVKey(req.Key); errKey != nil {
+			return nil, errKey
+		}
+		s.kv[req.Key] = append([]byte(nil), req.Value...)
+		return marshalRPCResult(rpcEmptyResponse{})
+	case pluginabi.MethodHostKVDelete:
+		var req pluginapi.HostKVDeleteRequest
+		if errUnmarshal := json.Unmarshal(request, &req); errUnmarshal != nil {
+			return nil, fmt.Errorf("decode host kv delete request: %w", errUnmarshal)
+		}
+		_, deleted := s.kv[req.Key]
+		delete(s.kv, req.Key)
+		return marshalRPCResult(pluginapi.HostKVDeleteResponse{Deleted: deleted})
+	case pluginabi.MethodHostKVList:
+		var req pluginapi.HostKVListRequest
+		if len(bytes.TrimSpace(request)) > 0 {
+			if errUnmarshal := json.Unmarshal(request, &req); errUnmarshal != nil {
+				return nil, fmt.Errorf("decode host kv list request: %w", errUnmarshal)
+			}
+		}
+		keys := make([]string, 0, len(s.kv))
+		for key := range s.kv {
+			if strings.HasPrefix(key, req.Prefix) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		return marshalRPCResult(pluginapi.HostKVListResponse{Keys: keys})
+	case pluginabi.MethodHostTimerSchedule:
+		var req pluginapi.HostTimerScheduleRequest
+		if errUnmarshal := json.Unmarshal(request, &req); errUnmarshal != nil {
+			return nil, fmt.Errorf("decode host timer schedule request: %w", errUnmarshal)
+		}
+		timer, errTimer := newPluginTimer(s.pluginID, req)
+		if errTimer != nil {
+			return nil, errTimer
+		}
+		nextRun, errNext := timer.next(time.Now())
+		if errNext != nil {
+			return nil, errNext
+		}
+		s.timers[timer.name] = req
+		return marshalRPCResult(pluginapi.HostTimerScheduleResponse{Name: timer.name, NextRun: nextRun})
+	case pluginabi.MethodHostTimerCancel:
+		var req pluginapi.HostTimerCancelRequest
+		if errUnmarshal := json.Unmarshal(request, &req); errUnmarshal != nil {
+			return nil, fmt.Errorf("decode host timer cancel request: %w", errUnmarshal)
+		}
+		name := strings.TrimSpace(req.Name)
+		_, canceled := s.timers[name]
+		delete(s.timers, name)
+		return marshalRPCResult(pluginapi.HostTimerCancelResponse{Canceled: canceled})
+	default:
+		return nil, fmt.Errorf("host callback %s is not stubbed in the harness", method)
+	}
+}
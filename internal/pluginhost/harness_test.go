@@ -0,0 +1,127 @@
+package pluginhost
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginabi"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
+)
+
+// harnessStreamingClient registers an executor and emits two chunks through the
+// host stream callbacks, like an asynchronous native plugin.
+type harnessStreamingClient struct {
+	host *Host
+}
+
+func (c *harnessStreamingClient) Call(ctx context.Context, method string, request []byte) ([]byte, error) {
+	switch method {
+	case pluginabi.MethodPluginRegister:
+		return marshalRPCResult(rpcRegistration{
+			SchemaVersion: pluginabi.SchemaVersion,
+			Metadata:      pluginapi.Metadata{Name: "alpha", Version: "1.0.0"},
+			Capabilities:  rpcCapabilities{Executor: true, RequestInterceptor: true},
+		})
+	case pluginabi.MethodExecutorExecuteStream:
+		var req rpcExecutorRequest
+		if errUnmarshal := json.Unmarshal(request, &req); errUnmarshal != nil {
+			return nil, errUnmarshal
+		}
+		go func() {
+			for _, payload := range []string{"data: one", "data: two"} {
+				emit, _ := json.Marshal(rpcStreamEmitRequest{StreamID: req.StreamID, Payload: []byte(payload)})
+				_, _ = c.host.callFromPlugin(ctx, pluginabi.MethodHostStreamEmit, emit)
+			}
+			closeRequest, _ := json.Marshal(rpcStreamCloseRequest{StreamID: req.StreamID})
+			_, _ = c.host.callFromPlugin(ctx, pluginabi.MethodHostStreamClose, closeRequest)
+		}()
+		return marshalRPCResult(rpcExecutorStreamResponse{})
+	case pluginabi.MethodRequestInterceptBefore:
+		if _, errCall := c.host.callFromPlugin(ctx, pluginabi.MethodHostKVSet, []byte(`{"key":"seen","value":"eQ=="}`)); errCall != nil {
+			return nil, errCall
+		}
+		if _, errCall := c.host.callFromPlugin(ctx, pluginabi.MethodHostHTTPDo, []byte(`{"Method":"GET","URL":"https://example.com"}`)); errCall != nil {
+			return marshalRPCError("upstream", errCall.Error()), nil
+		}
+		return marshalRPCResult(pluginapi.RequestInterceptResponse{Body: []byte(`{"rewritten":true}`)})
+	default:
+		return marshalRPCError("unknown_method", "unknown method: "+method), nil
+	}
+}
+
+func (c *harnessStreamingClient) Shutdown() {}
+
+type harnessLoader struct {
+	client *harnessStreamingClient
+}
+
+func (l harnessLoader) Open(pluginFile, *Host) (pluginClient, error) {
+	return l.client, nil
+}
+
+func openTestHarness(t *testing.T, opts HarnessOptions) *Harness {
+	t.Helper()
+	client := &harnessStreamingClient{}
+	h := NewForTest(harnessLoader{client: client})
+	client.host = h
+	hs, errOpen := openHarness(context.Background(), h, "alpha", "alpha.so", opts)
+	if errOpen != nil {
+		t.Fatalf("openHarness() error = %v", errOpen)
+	}
+	t.Cleanup(hs.Close)
+	return hs
+}
+
+func TestHarnessReportsRegistrationCapabilities(t *testing.T) {
+	hs := openTestHarness(t, HarnessOptions{})
+	if got := hs.Capabilities(); !reflect.DeepEqual(got, []string{"executor", "request_interceptor"}) {
+		t.Fatalf("Capabilities() = %v", got)
+	}
+	if !hs.HasCapability("executor") || hs.HasCapability("scheduler") {
+		t.Fatal("HasCapability() does not match the registration")
+	}
+	if !strings.Contains(string(hs.Registration()), `"Name":"alpha"`) {
+		t.Fatalf("Registration() = %s", hs.Registration())
+	}
+}
+
+func TestHarnessInvokeCollectsExecutorStream(t *testing.T) {
+	hs := openTestHarness(t, HarnessOptions{})
+	result := hs.Invoke(context.Background(), pluginabi.MethodExecutorExecuteStream, []byte(`{"Model":"m"}`))
+	if result.Error != "" {
+		t.Fatalf("Invoke() error = %s", result.Error)
+	}
+	if len(result.Chunks) != 2 || string(result.Chunks[0].Payload) != "data: one" || string(result.Chunks[1].Payload) != "data: two" {
+		t.Fatalf("chunks = %#v", result.Chunks)
+	}
+	if len(result.HostCalls) != 0 {
+		t.Fatalf("stream callbacks were recorded as stubbed host calls: %#v", result.HostCalls)
+	}
+}
+
+func TestHarnessStubsHostCallbacks(t *testing.T) {
+	hs := openTestHarness(t, HarnessOptions{})
+	result := hs.Invoke(context.Background(), pluginabi.MethodRequestInterceptBefore, []byte(`{}`))
+	if !strings.Contains(result.Error, "not stubbed") {
+		t.Fatalf("Invoke() error = %q, want an unstubbed host.http.do", result.Error)
+	}
+	if len(result.HostCalls) != 2 || result.HostCalls[0].Method != pluginabi.MethodHostKVSet || result.HostCalls[1].Error == "" {
+		t.Fatalf("host calls = %#v", result.HostCalls)
+	}
+	raw, _ := hs.host.callFromPlugin(context.Background(), pluginabi.MethodHostKVGet, []byte(`{"key":"seen"}`))
+	got, errDecode := decodeRPCEnvelope[pluginapi.HostKVGetResponse](raw)
+	if errDecode != nil || !got.Found || string(got.Value) != "y" {
+		t.Fatalf("stubbed kv get = %#v, %v", got, errDecode)
+	}
+
+	hs = openTestHarness(t, HarnessOptions{Stubs: map[string]json.RawMessage{
+		pluginabi.MethodHostHTTPDo: json.RawMessage(`{"StatusCode":200}`),
+	}})
+	result = hs.Invoke(context.Background(), pluginabi.MethodRequestInterceptBefore, []byte(`{}`))
+	if result.Error != "" || !strings.Contains(string(result.Result), "Body") {
+		t.Fatalf("Invoke() with a canned host.http.do = %#v", result)
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/events"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/interfaces"
 	"github.com/router-for-me/CLIProxyAPI/v7/sdk/api/handlers"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
@@ -56,10 +58,14 @@ type Host struct {
 	applyMu                chan struct{}
 	mu                     sync.Mutex
 	loader                 pluginLoader
+	processLoader          pluginLoader
+	wasmLoader             pluginLoader
 	loaded                 map[string]*loadedPlugin
 	retired                map[string][]*loadedPlugin
 	loading                map[string]*pluginLoadRequest
 	fused                  map[string]string
+	rejectedUpgrades       map[string]rejectedPluginUpgrade
+	retiredPluginGrace     time.Duration
 	pluginFileVersions     map[string]string
 	activePluginVersions   map[string]string
 	activePluginPaths      map[string]string
@@ -82,6 +88,10 @@ type Host struct {
 	httpStreams            *hostHTTPStreamBridge
 	modelStreams           *modelStreamBridge
 	callbackContexts       *callbackContextRegistry
+	timers                 *pluginTimerRegistry
+	eventDispatcher        *pluginEventDispatcher
+	callbackStub           hostCallbackStub
+	kvMu                   sync.Mutex
 	snapshot               atomic.Value
 }
 
@@ -89,10 +99,14 @@ func New() *Host {
 	h := &Host{
 		applyMu:                make(chan struct{}, 1),
 		loader:                 defaultPluginLoader(),
+		processLoader:          processPluginLoader{},
+		wasmLoader:             wasmPluginLoader{},
 		loaded:                 make(map[string]*loadedPlugin),
 		retired:                make(map[string][]*loadedPlugin),
 		loading:                make(map[string]*pluginLoadRequest),
 		fused:                  make(map[string]string),
+		rejectedUpgrades:       make(map[string]rejectedPluginUpgrade),
+		retiredPluginGrace:     defaultRetiredPluginGrace,
 		pluginFileVersions:     make(map[string]string),
 		activePluginVersions:   make(map[string]string),
 		activePluginPaths:      make(map[string]string),
@@ -112,6 +126,8 @@ func New() *Host {
 		httpStreams:            newHostHTTPStreamBridge(),
 		modelStreams:           newModelStreamBridge(),
 		callbackContexts:       newCallbackContextRegistry(),
+		timers:                 newPluginTimerRegistry(),
+		eventDispatcher:        newPluginEventDispatcher(events.Default()),
 	}
 	h.snapshot.Store(emptySnapshot())
 	return h
@@ -221,6 +237,8 @@ func (h *Host) ApplyConfig(ctx context.Context, cfg *config.Config) {
 		h.snapshot.Store(emptySnapshot())
 		h.mu.Unlock()
 		h.refreshThinkingProviders(nil)
+		h.timers.retain(nil)
+		h.retainEventSubscribers(nil)
 		return
 	}
 
@@ -235,6 +253,8 @@ func (h *Host) ApplyConfig(ctx context.Context, cfg *config.Config) {
 		h.snapshot.Store(emptySnapshot())
 		h.mu.Unlock()
 		h.refreshThinkingProviders(nil)
+		h.timers.retain(nil)
+		h.retainEventSubscribers(nil)
 		return
 	}
 	files = h.withLoadedPluginFallbacks(files, rc.Items, desiredVersions)
@@ -242,6 +262,7 @@ func (h *Host) ApplyConfig(ctx context.Context, cfg *config.Config) {
 	records := make([]capabilityRecord, 0, len(files))
 	loadedFiles := make([]pluginFile, 0, len(files))
 	hotReloadLogs := make([]log.Fields, 0)
+	retiredNow := make([]*loadedPlugin, 0)
 	for _, file := range files {
 		item, ok := rc.Items[file.ID]
 		if !ok {
@@ -254,8 +275,12 @@ func (h *Host) ApplyConfig(ctx context.Context, cfg *config.Config) {
 		lp := h.loaded[file.ID]
 		var replaced *loadedPlugin
 		if lp != nil && cleanPluginPath(lp.path) != cleanPluginPath(file.Path) {
-			replaced = lp
-			lp = nil
+			if h.upgradeRejectedLocked(file) {
+				file = pluginFile{ID: file.ID, Path: lp.path, Version: strings.TrimSpace(lp.version)}
+			} else {
+				replaced = lp
+				lp = nil
+			}
 		}
 		_, disabled := h.fused[file.ID]
 		h.mu.Unlock()
@@ -282,37 +307,55 @@ func (h *Host) ApplyConfig(ctx context.Context, cfg *config.Config) {
 			if !completed {
 				return
 			}
-			if loadResult.err != nil {
-				h.cleanupPluginLoad(file.ID, request, loadResult.loaded)
+			errUpgrade := loadResult.err
+			if errUpgrade != nil {
 				log.Warnf("pluginhost: failed to load plugin %s from %s: %v", file.ID, file.Path, loadResult.err)
-				continue
+			} else if replaced != nil {
+				// Blue/green upgrade: the running version keeps serving until the new one
+				// has registered and passed its health probe.
+				errUpgrade = verifyPluginUpgrade(ctx, loadResult)
 			}
-
-			h.mu.Lock()
-			if h.loading[file.ID] != request {
-				h.mu.Unlock()
-				h.discardLoadedPlugin(loadResult.loaded)
-				return
-			}
-			if errContext := ctx.Err(); errContext != nil {
-				h.mu.Unlock()
+			if errUpgrade != nil {
 				h.cleanupPluginLoad(file.ID, request, loadResult.loaded)
-				return
-			}
-			delete(h.loading, file.ID)
-			lp = loadResult.loaded
-			if replaced != nil {
-				hotReloadFields = pluginHotReloadLogFields(file.ID, file.Version, file.Path, replaced.version, replaced.path)
-				h.retireLoadedPluginLocked(replaced)
-				delete(h.fused, file.ID)
-				h.removePluginRuntimeStateLocked(file.ID)
+				if replaced == nil {
+					continue
+				}
+				h.rollbackPluginUpgrade(replaced, file, disabled, errUpgrade)
+				if disabled {
+					continue
+				}
+				file = pluginFile{ID: file.ID, Path: replaced.path, Version: strings.TrimSpace(replaced.version)}
+				lp = replaced
+				replaced = nil
+			} else {
+				h.mu.Lock()
+				if h.loading[file.ID] != request {
+					h.mu.Unlock()
+					h.discardLoadedPlugin(loadResult.loaded)
+					return
+				}
+				if errContext := ctx.Err(); errContext != nil {
+					h.mu.Unlock()
+					h.cleanupPluginLoad(file.ID, request, loadResult.loaded)
+					return
+				}
+				delete(h.loading, file.ID)
+				lp = loadResult.loaded
+				if replaced != nil {
+					hotReloadFields = pluginHotReloadLogFields(file.ID, file.Version, file.Path, replaced.version, replaced.path)
+					h.retireLoadedPluginLocked(replaced)
+					retiredNow = append(retiredNow, replaced)
+					delete(h.fused, file.ID)
+					delete(h.rejectedUpgrades, file.ID)
+					h.removePluginRuntimeStateLocked(file.ID)
+				}
+				h.loaded[file.ID] = lp
+				loadedNow = true
+				plugin = loadResult.plugin
+				registeredNow = loadResult.initialized
+				h.mu.Unlock()
+				log.WithFields(pluginLogFields(file.ID, "", file.Version, file.Path)).Info("pluginhost: plugin loaded")
 			}
-			h.loaded[file.ID] = lp
-			loadedNow = true
-			plugin = loadResult.plugin
-			registeredNow = loadResult.initialized
-			h.mu.Unlock()
-			log.WithFields(pluginLogFields(file.ID, "", file.Version, file.Path)).Info("pluginhost: plugin loaded")
 		}
 
 		if !registeredNow {
@@ -336,6 +379,7 @@ func (h *Host) ApplyConfig(ctx context.Context, cfg *config.Config) {
 		h.mu.Unlock()
 		if loadedNow {
 			log.WithFields(pluginLogFieldsFromMetadata(file.ID, plugin.Metadata, file.Path)).Info("pluginhost: plugin registered")
+			publishPluginEvent(events.TypePluginLoaded, file.ID, plugin.Metadata.Name, plugin.Metadata.Version, file.Path, replaced != nil)
 		}
 		if hotReloadFields != nil {
 			hotReloadLogs = append(hotReloadLogs, hotReloadFields)
@@ -359,11 +403,27 @@ func (h *Host) ApplyConfig(ctx context.Context, cfg *config.Config) {
 	}
 	h.rebuildActivePluginMapsLocked(records)
 	h.snapshot.Store(&Snapshot{enabled: true, records: records})
+	grace := h.retiredPluginGrace
 	h.mu.Unlock()
 	h.refreshThinkingProviders(records)
+	// Timers scheduled during registration by a plugin that then registered
+	// an older schema version are dropped with those of inactive plugins.
+	timerIDs := make(map[string]struct{}, len(records))
+	for _, record := range records {
+		if hostStateCallbacksSupported(record.plugin.SchemaVersion) {
+			timerIDs[record.id] = struct{}{}
+		}
+	}
+	h.timers.retain(timerIDs)
+	h.retainEventSubscribers(records)
 	for _, fields := range hotReloadLogs {
 		log.WithFields(fields).Info("pluginhost: plugin hot reloaded")
 	}
+	// New requests now route to the replacements; retired instances unload once
+	// their in-flight calls and streams finish.
+	for _, retired := range retiredNow {
+		go h.drainRetiredPlugin(retired, grace)
+	}
 	if cleanupFiles && len(loadedFiles) > 0 {
 		if errCleanup := cleanupUnselectedPluginFiles(rc.Dir, loadedFiles); errCleanup != nil {
 			log.Warnf("pluginhost: failed to clean old plugin files: %v", errCleanup)
@@ -371,6 +431,18 @@ func (h *Host) ApplyConfig(ctx context.Context, cfg *config.Config) {
 	}
 }
 
+// loaderFor picks the transport for a plugin file by its extension.
+func (h *Host) loaderFor(path string) pluginLoader {
+	switch {
+	case isProcessPluginFile(path):
+		return h.processLoader
+	case isWASMPluginFile(path):
+		return h.wasmLoader
+	default:
+		return h.loader
+	}
+}
+
 func (h *Host) startPluginLoad(ctx context.Context, file pluginFile, item runtimeItemConfig, request *pluginLoadRequest) {
 	if h == nil || request == nil || request.result == nil {
 		return
@@ -378,8 +450,9 @@ func (h *Host) startPluginLoad(ctx context.Context, file pluginFile, item runtim
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	loader := h.loaderFor(file.Path)
 	go func() {
-		client, errOpen := h.loader.Open(file, h)
+		client, errOpen := loader.Open(file, h)
 		if errOpen != nil {
 			request.result <- pluginLoadResult{err: errOpen}
 			return
@@ -551,6 +624,7 @@ func (h *Host) UnloadPluginContext(ctx context.Context, id string) bool {
 	delete(h.loaded, id)
 	delete(h.retired, id)
 	delete(h.fused, id)
+	delete(h.rejectedUpgrades, id)
 	delete(h.activePluginVersions, id)
 	delete(h.activePluginPaths, id)
 	for _, target := range targets {
@@ -563,11 +637,14 @@ func (h *Host) UnloadPluginContext(ctx context.Context, id string) bool {
 
 	h.refreshThinkingProviders(records)
 	h.RegisterFrontendAuthProviders()
+	h.timers.cancelPlugin(id)
+	h.retainEventSubscribers(records)
 	for _, target := range targets {
 		if target.client != nil {
 			shutdownPluginClient(ctx, target.client)
 		}
 		log.WithFields(pluginLogFields(target.id, target.name, target.version, target.path)).Info("pluginhost: plugin unloaded")
+		publishPluginEvent(events.TypePluginUnloaded, target.id, target.name, target.version, target.path, false)
 	}
 	return true
 }
@@ -638,12 +715,15 @@ func (h *Host) ShutdownAllContext(ctx context.Context) {
 
 	h.refreshThinkingProviders(nil)
 	h.RegisterFrontendAuthProviders()
+	h.timers.retain(nil)
+	h.retainEventSubscribers(nil)
 	for id, request := range loading {
 		h.cleanupCanceledPluginLoad(id, request)
 	}
 	for _, target := range targets {
 		shutdownPluginClient(ctx, target.client)
 		log.WithFields(pluginLogFields(target.id, target.name, target.version, target.path)).Info("pluginhost: plugin unloaded")
+		publishPluginEvent(events.TypePluginUnloaded, target.id, target.name, target.version, target.path, false)
 	}
 }
 
@@ -864,6 +944,7 @@ func validPlugin(plugin pluginapi.Plugin) bool {
 		caps.StreamChunkInterceptor != nil ||
 		caps.ThinkingApplier != nil ||
 		caps.UsagePlugin != nil ||
+		caps.EventSubscriber != nil ||
 		caps.CommandLinePlugin != nil ||
 		caps.ManagementAPI != nil
 }
@@ -95,7 +95,16 @@ func hostCallbackPluginIDFromContext(ctx context.Context) string {
 	return strings.TrimSpace(pluginID)
 }
 
+// hostCallbackStub answers host callbacks in place of the live server, for
+// example in the plugin harness. handled reports whether the stub took the call.
+type hostCallbackStub func(ctx context.Context, method string, request []byte) (response []byte, handled bool, err error)
+
 func (h *Host) callFromPlugin(ctx context.Context, method string, request []byte) ([]byte, error) {
+	if h.callbackStub != nil {
+		if response, handled, errStub := h.callbackStub(ctx, method, request); handled {
+			return response, errStub
+		}
+	}
 	switch method {
 	case pluginabi.MethodHostModelExecute:
 		return h.callHostModelExecute(ctx, request)
@@ -127,6 +136,18 @@ func (h *Host) callFromPlugin(ctx context.Context, method string, request []byte
 		return h.callHostAuthGetRuntime(ctx, request)
 	case pluginabi.MethodHostAuthSave:
 		return h.callHostAuthSave(ctx, request)
+	case pluginabi.MethodHostKVGet:
+		return h.callHostKVGet(ctx, request)
+	case pluginabi.MethodHostKVSet:
+		return h.callHostKVSet(ctx, request)
+	case pluginabi.MethodHostKVDelete:
+		return h.callHostKVDelete(ctx, request)
+	case pluginabi.MethodHostKVList:
+		return h.callHostKVList(ctx, request)
+	case pluginabi.MethodHostTimerSchedule:
+		return h.callHostTimerSchedule(ctx, request)
+	case pluginabi.MethodHostTimerCancel:
+		return h.callHostTimerCancel(ctx, request)
 	default:
 		return nil, fmt.Errorf("unsupported host callback %s", method)
 	}
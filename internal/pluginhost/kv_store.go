@@ -0,0 +1,342 @@
+package pluginhost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/home"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginabi"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
+)
+
+const (
+	// pluginStateDirName holds per-plugin state below the plugins directory.
+	// Plugin discovery only reads regular files, so the directory is never loaded.
+	pluginStateDirName       = "state"
+	pluginKVFileName         = "kv.json"
+	pluginKVHomeKeyPrefix    = "plugin-kv:"
+	maxPluginKVKeyLength     = 256
+	maxPluginKVValueSize     = 256 << 10
+	maxPluginKVNamespaceSize = 4 << 20
+	maxPluginKVSwapAttempts  = 8
+)
+
+type pluginKVEntry struct {
+	Value     []byte     `json:"value"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// pluginKVNamespace is the whole key space of one plugin. It is stored as a
+// single JSON document so local disk and Home behave the same way.
+type pluginKVNamespace map[string]pluginKVEntry
+
+// pluginKVBackend persists one namespace document per plugin. swap replaces the
+// document only while it still holds previous, so concurrent writers on other
+// nodes retry instead of losing updates.
+type pluginKVBackend interface {
+	load(ctx context.Context, pluginID string) ([]byte, bool, error)
+	swap(ctx context.Context, pluginID string, previous []byte, previousFound bool, next []byte) (bool, error)
+}
+
+type localPluginKVBackend struct {
+	dir string
+	mu  *sync.Mutex
+}
+
+func (b localPluginKVBackend) path(pluginID string) string {
+	return filepath.Join(b.dir, pluginID, pluginKVFileName)
+}
+
+func (b localPluginKVBackend) load(_ context.Context, pluginID string) ([]byte, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.readLocked(pluginID)
+}
+
+func (b localPluginKVBackend) readLocked(pluginID string) ([]byte, bool, error) {
+	raw, errRead := os.ReadFile(b.path(pluginID))
+	if errors.Is(errRead, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if errRead != nil {
+		return nil, false, errRead
+	}
+	return raw, true, nil
+}
+
+func (b localPluginKVBackend) swap(_ context.Context, pluginID string, previous []byte, previousFound bool, next []byte) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	current, found, errRead := b.readLocked(pluginID)
+	if errRead != nil {
+		return false, errRead
+	}
+	if found != previousFound || !bytes.Equal(current, previous) {
+		return false, nil
+	}
+	path := b.path(pluginID)
+	if errMkdir := os.MkdirAll(filepath.Dir(path), 0o700); errMkdir != nil {
+		return false, errMkdir
+	}
+	tmp, errCreate := os.CreateTemp(filepath.Dir(path), pluginKVFileName+".*.tmp")
+	if errCreate != nil {
+		return false, errCreate
+	}
+	tmpPath := tmp.Name()
+	_, errWrite := tmp.Write(next)
+	errClose := tmp.Close()
+	if errWrite == nil {
+		errWrite = errClose
+	}
+	if errWrite == nil {
+		errWrite = os.Rename(tmpPath, path)
+	}
+	if errWrite != nil {
+		_ = os.Remove(tmpPath)
+		return false, errWrite
+	}
+	return true, nil
+}
+
+type homePluginKVBackend struct {
+	client *home.Client
+}
+
+func (b homePluginKVBackend) load(ctx context.Context, pluginID string) ([]byte, bool, error) {
+	return b.client.KVGet(ctx, pluginKVHomeKeyPrefix+pluginID)
+}
+
+func (b homePluginKVBackend) swap(ctx context.Context, pluginID string, previous []byte, previousFound bool, next []byte) (bool, error) {
+	key := pluginKVHomeKeyPrefix + pluginID
+	swapped, errSwap := b.client.KVCompareAndSwap(ctx, key, previous, previousFound, next, 0)
+	if errors.Is(errSwap, home.ErrCompareAndSwapUnsupported) {
+		// Older Home deployments fall back to last-writer-wins.
+		return b.client.KVSet(ctx, key, next, home.KVSetOptions{})
+	}
+	return swapped, errSwap
+}
+
+// pluginKVBackend returns Home storage in cluster mode so every node sees the
+// same state, and the local plugins directory otherwise.
+func (h *Host) pluginKVBackend() (pluginKVBackend, error) {
+	client, homeMode, errClient := home.CurrentKVClient()
+	if homeMode {
+		if errClient != nil {
+			return nil, errClient
+		}
+		return homePluginKVBackend{client: client}, nil
+	}
+	h.mu.Lock()
+	cfg := h.runtimeConfig
+	h.mu.Unlock()
+	rc, errConfig := runtimeConfigFromConfig(cfg)
+	if errConfig != nil {
+		return nil, errConfig
+	}
+	return localPluginKVBackend{dir: filepath.Join(rc.Dir, pluginStateDirName), mu: &h.kvMu}, nil
+}
+
+func decodePluginKVNamespace(raw []byte, now time.Time) (pluginKVNamespace, error) {
+	namespace := make(pluginKVNamespace)
+	if len(bytesTrimSpace(raw)) > 0 {
+		if errUnmarshal := json.Unmarshal(raw, &namespace); errUnmarshal != nil {
+			return nil, fmt.Errorf("decode plugin kv namespace: %w", errUnmarshal)
+		}
+	}
+	for key, entry := range namespace {
+		if entry.ExpiresAt != nil && !entry.ExpiresAt.After(now) {
+			delete(namespace, key)
+		}
+	}
+	return namespace, nil
+}
+
+func (h *Host) readPluginKV(ctx context.Context, pluginID string) (pluginKVNamespace, error) {
+	backend, errBackend := h.pluginKVBackend()
+	if errBackend != nil {
+		return nil, errBackend
+	}
+	raw, _, errLoad := backend.load(ctx, pluginID)
+	if errLoad != nil {
+		return nil, errLoad
+	}
+	return decodePluginKVNamespace(raw, time.Now())
+}
+
+// updatePluginKV applies mutate to the plugin's namespace and stores the result.
+// mutate reports whether it changed anything; unchanged namespaces are not written.
+func (h *Host) updatePluginKV(ctx context.Context, pluginID string, mutate func(pluginKVNamespace) bool) error {
+	backend, errBackend := h.pluginKVBackend()
+	if errBackend != nil {
+		return errBackend
+	}
+	for attempt := 0; attempt < maxPluginKVSwapAttempts; attempt++ {
+		raw, found, errLoad := backend.load(ctx, pluginID)
+		if errLoad != nil {
+			return errLoad
+		}
+		namespace, errDecode := decodePluginKVNamespace(raw, time.Now())
+		if errDecode != nil {
+			return errDecode
+		}
+		if !mutate(namespace) {
+			return nil
+		}
+		next, errMarshal := json.Marshal(namespace)
+		if errMarshal != nil {
+			return errMarshal
+		}
+		if len(next) > maxPluginKVNamespaceSize {
+			return fmt.Errorf("plugin kv namespace for %s exceeds %d bytes", pluginID, maxPluginKVNamespaceSize)
+		}
+		swapped, errSwap := backend.swap(ctx, pluginID, raw, found, next)
+		if errSwap != nil {
+			return errSwap
+		}
+		if swapped {
+			return nil
+		}
+	}
+	return fmt.Errorf("plugin kv namespace for %s is updated concurrently, retry later", pluginID)
+}
+
+func validatePluginKVKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("key is required")
+	}
+	if len(key) > maxPluginKVKeyLength {
+		return fmt.Errorf("key exceeds %d bytes", maxPluginKVKeyLength)
+	}
+	for _, r := range key {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("key must not contain control characters")
+		}
+	}
+	return nil
+}
+
+// hostStateCallerPluginID returns the plugin that issued a host.kv or
+// host.timer callback. State callbacks are always scoped to that plugin, and
+// an active plugin registered before SchemaVersionHostState may not use them.
+func (h *Host) hostStateCallerPluginID(ctx context.Context, method string) (string, error) {
+	pluginID := hostCallbackPluginIDFromContext(ctx)
+	if pluginID == "" || !validPluginID(pluginID) {
+		return "", fmt.Errorf("%s requires a calling plugin", method)
+	}
+	if version, active := h.pluginSchemaVersion(pluginID); active && !hostStateCallbacksSupported(version) {
+		return "", fmt.Errorf("%s requires plugin schema version %d or later", method, pluginabi.SchemaVersionHostState)
+	}
+	return pluginID, nil
+}
+
+func (h *Host) callHostKVGet(ctx context.Context, request []byte) ([]byte, error) {
+	pluginID, errCaller := h.hostStateCallerPluginID(ctx, "host.kv.get")
+	if errCaller != nil {
+		return nil, errCaller
+	}
+	var req pluginapi.HostKVGetRequest
+	if errUnmarshal := json.Unmarshal(request, &req); errUnmarshal != nil {
+		return nil, fmt.Errorf("decode host kv get request: %w", errUnmarshal)
+	}
+	if errKey := validatePluginKVKey(req.Key); errKey != nil {
+		return nil, errKey
+	}
+	namespace, errRead := h.readPluginKV(ctx, pluginID)
+	if errRead != nil {
+		return nil, errRead
+	}
+	entry, found := namespace[req.Key]
+	return marshalRPCResult(pluginapi.HostKVGetResponse{Value: entry.Value, Found: found})
+}
+
+func (h *Host) callHostKVSet(ctx context.Context, request []byte) ([]byte, error) {
+	pluginID, errCaller := h.hostStateCallerPluginID(ctx, "host.kv.set")
+	if errCaller != nil {
+		return nil, errCaller
+	}
+	var req pluginapi.HostKVSetRequest
+	if errUnmarshal := json.Unmarshal(request, &req); errUnmarshal != nil {
+		return nil, fmt.Errorf("decode host kv set request: %w", errUnmarshal)
+	}
+	if errKey := validatePluginKVKey(req.Key); errKey != nil {
+		return nil, errKey
+	}
+	if len(req.Value) > maxPluginKVValueSize {
+		return nil, fmt.Errorf("value exceeds %d bytes", maxPluginKVValueSize)
+	}
+	if req.TTLSeconds < 0 {
+		return nil, fmt.Errorf("ttl_seconds must not be negative")
+	}
+	entry := pluginKVEntry{Value: append([]byte(nil), req.Value...)}
+	if req.TTLSeconds > 0 {
+		expiresAt := time.Now().UTC().Add(time.Duration(req.TTLSeconds) * time.Second)
+		entry.ExpiresAt = &expiresAt
+	}
+	errUpdate := h.updatePluginKV(ctx, pluginID, func(namespace pluginKVNamespace) bool {
+		namespace[req.Key] = entry
+		return true
+	})
+	if errUpdate != nil {
+		return nil, errUpdate
+	}
+	return marshalRPCResult(rpcEmptyResponse{})
+}
+
+func (h *Host) callHostKVDelete(ctx context.Context, request []byte) ([]byte, error) {
+	pluginID, errCaller := h.hostStateCallerPluginID(ctx, "host.kv.delete")
+	if errCaller != nil {
+		return nil, errCaller
+	}
+	var req pluginapi.HostKVDeleteRequest
+	if errUnmarshal := json.Unmarshal(request, &req); errUnmarshal != nil {
+		return nil, fmt.Errorf("decode host kv delete request: %w", errUnmarshal)
+	}
+	if errKey := validatePluginKVKey(req.Key); errKey != nil {
+		return nil, errKey
+	}
+	deleted := false
+	errUpdate := h.updatePluginKV(ctx, pluginID, func(namespace pluginKVNamespace) bool {
+		_, deleted = namespace[req.Key]
+		delete(namespace, req.Key)
+		return deleted
+	})
+	if errUpdate != nil {
+		return nil, errUpdate
+	}
+	return marshalRPCResult(pluginapi.HostKVDeleteResponse{Deleted: deleted})
+}
+
+func (h *Host) callHostKVList(ctx context.Context, request []byte) ([]byte, error) {
+	pluginID, errCaller := h.hostStateCallerPluginID(ctx, "host.kv.list")
+	if errCaller != nil {
+		return nil, errCaller
+	}
+	var req pluginapi.HostKVListRequest
+	if len(bytesTrimSpace(request)) > 0 {
+		if errUnmarshal := json.Unmarshal(request, &req); errUnmarshal != nil {
+			return nil, fmt.Errorf("decode host kv list request: %w", errUnmarshal)
+		}
+	}
+	namespace, errRead := h.readPluginKV(ctx, pluginID)
+	if errRead != nil {
+		return nil, errRead
+	}
+	keys := make([]string, 0, len(namespace))
+	for key := range namespace {
+		if strings.HasPrefix(key, req.Prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return marshalRPCResult(pluginapi.HostKVListResponse{Keys: keys})
+}
@@ -0,0 +1,109 @@
+package pluginhost
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginabi"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
+)
+
+func newKVTestHost(t *testing.T) (*Host, string) {
+	t.Helper()
+	pluginsDir := t.TempDir()
+	host := New()
+	host.runtimeConfig = &config.Config{Plugins: config.PluginsConfig{Enabled: true, Dir: pluginsDir}}
+	return host, pluginsDir
+}
+
+func callHostKV[T any](t *testing.T, host *Host, pluginID, method string, request any) T {
+	t.Helper()
+	raw, errMarshal := json.Marshal(request)
+	if errMarshal != nil {
+		t.Fatalf("marshal %s request: %v", method, errMarshal)
+	}
+	rawResp, errCall := host.callFromPlugin(withHostCallbackPluginID(context.Background(), pluginID), method, raw)
+	if errCall != nil {
+		t.Fatalf("callFromPlugin(%s) error = %v", method, errCall)
+	}
+	resp, errDecode := decodeRPCEnvelope[T](rawResp)
+	if errDecode != nil {
+		t.Fatalf("decode %s response: %v", method, errDecode)
+	}
+	return resp
+}
+
+func TestHostKVCallbacksStorePerPluginState(t *testing.T) {
+	host, pluginsDir := newKVTestHost(t)
+
+	callHostKV[rpcEmptyResponse](t, host, "alpha", pluginabi.MethodHostKVSet, pluginapi.HostKVSetRequest{Key: "cursor/a", Value: []byte("1")})
+	callHostKV[rpcEmptyResponse](t, host, "alpha", pluginabi.MethodHostKVSet, pluginapi.HostKVSetRequest{Key: "cursor/b", Value: []byte("2")})
+	callHostKV[rpcEmptyResponse](t, host, "alpha", pluginabi.MethodHostKVSet, pluginapi.HostKVSetRequest{Key: "other", Value: []byte("3")})
+	callHostKV[rpcEmptyResponse](t, host, "beta", pluginabi.MethodHostKVSet, pluginapi.HostKVSetRequest{Key: "cursor/a", Value: []byte("beta")})
+
+	got := callHostKV[pluginapi.HostKVGetResponse](t, host, "alpha", pluginabi.MethodHostKVGet, pluginapi.HostKVGetRequest{Key: "cursor/a"})
+	if !got.Found || string(got.Value) != "1" {
+		t.Fatalf("alpha get = %#v, want value 1", got)
+	}
+	got = callHostKV[pluginapi.HostKVGetResponse](t, host, "beta", pluginabi.MethodHostKVGet, pluginapi.HostKVGetRequest{Key: "cursor/a"})
+	if !got.Found || string(got.Value) != "beta" {
+		t.Fatalf("beta get = %#v, want its own value", got)
+	}
+
+	list := callHostKV[pluginapi.HostKVListResponse](t, host, "alpha", pluginabi.MethodHostKVList, pluginapi.HostKVListRequest{Prefix: "cursor/"})
+	if !reflect.DeepEqual(list.Keys, []string{"cursor/a", "cursor/b"}) {
+		t.Fatalf("list keys = %v, want cursor/a cursor/b", list.Keys)
+	}
+
+	deleted := callHostKV[pluginapi.HostKVDeleteResponse](t, host, "alpha", pluginabi.MethodHostKVDelete, pluginapi.HostKVDeleteRequest{Key: "cursor/a"})
+	if !deleted.Deleted {
+		t.Fatal("delete of an existing key reported false")
+	}
+	deleted = callHostKV[pluginapi.HostKVDeleteResponse](t, host, "alpha", pluginabi.MethodHostKVDelete, pluginapi.HostKVDeleteRequest{Key: "cursor/a"})
+	if deleted.Deleted {
+		t.Fatal("delete of a missing key reported true")
+	}
+
+	if _, errStat := os.Stat(filepath.Join(pluginsDir, pluginStateDirName, "alpha", pluginKVFileName)); errStat != nil {
+		t.Fatalf("stat alpha kv file: %v", errStat)
+	}
+}
+
+func TestHostKVCallbacksExpireEntries(t *testing.T) {
+	host, pluginsDir := newKVTestHost(t)
+	callHostKV[rpcEmptyResponse](t, host, "alpha", pluginabi.MethodHostKVSet, pluginapi.HostKVSetRequest{Key: "token", Value: []byte("x"), TTLSeconds: 60})
+
+	path := filepath.Join(pluginsDir, pluginStateDirName, "alpha", pluginKVFileName)
+	raw, errRead := os.ReadFile(path)
+	if errRead != nil {
+		t.Fatalf("read kv file: %v", errRead)
+	}
+	namespace, errDecode := decodePluginKVNamespace(raw, time.Now())
+	if errDecode != nil || namespace["token"].ExpiresAt == nil {
+		t.Fatalf("namespace = %#v, %v, want an expiry on token", namespace, errDecode)
+	}
+	if expired, _ := decodePluginKVNamespace(raw, time.Now().Add(2*time.Minute)); len(expired) != 0 {
+		t.Fatalf("namespace after expiry = %#v, want empty", expired)
+	}
+}
+
+func TestHostKVCallbacksRequireCallingPlugin(t *testing.T) {
+	host, _ := newKVTestHost(t)
+	if _, errCall := host.callFromPlugin(context.Background(), pluginabi.MethodHostKVGet, []byte(`{"key":"a"}`)); errCall == nil {
+		t.Fatal("host.kv.get without a calling plugin succeeded")
+	}
+	ctx := withHostCallbackPluginID(context.Background(), "alpha")
+	if _, errCall := host.callFromPlugin(ctx, pluginabi.MethodHostKVSet, []byte(`{"key":""}`)); errCall == nil {
+		t.Fatal("host.kv.set with an empty key succeeded")
+	}
+	setHostSnapshotForTest(host, true, capabilityRecord{id: "alpha", plugin: pluginapi.Plugin{SchemaVersion: pluginabi.SchemaVersionHostState - 1}})
+	if _, errCall := host.callFromPlugin(ctx, pluginabi.MethodHostKVSet, []byte(`{"key":"a","value":"MQ=="}`)); errCall == nil {
+		t.Fatal("host.kv.set from a plugin registered before schema version 4 succeeded")
+	}
+}
@@ -51,7 +51,7 @@ func pluginIDFromPath(path string) string {
 	}
 	base := filepath.Base(path)
 	lowerBase := strings.ToLower(base)
-	for _, extension := range []string{".so", ".dylib", ".dll"} {
+	for _, extension := range []string{".so", ".dylib", ".dll", processPluginExtension, wasmPluginExtension} {
 		if strings.HasSuffix(lowerBase, extension) {
 			return base[:len(base)-len(extension)]
 		}
@@ -68,7 +68,7 @@ func pluginFileFromPath(filePath string, requiredExtension string) (pluginFile,
 			return pluginFile{}, false
 		}
 	} else {
-		for _, candidateExtension := range []string{".so", ".dylib", ".dll"} {
+		for _, candidateExtension := range []string{".so", ".dylib", ".dll", processPluginExtension, wasmPluginExtension} {
 			if strings.HasSuffix(lowerBase, candidateExtension) {
 				extension = candidateExtension
 				break
@@ -124,7 +124,7 @@ func selectPluginFilesWithCandidates(root string, desiredVersions ...map[string]
 	desired := normalizeDesiredPluginVersions(desiredVersions...)
 
 	candidates := candidateDirs(root, runtime.GOOS, runtime.GOARCH)
-	extension := pluginExtension(runtime.GOOS)
+	extensions := []string{pluginExtension(runtime.GOOS), processPluginExtension, wasmPluginExtension}
 	selectedByID := make(map[string]pluginFile)
 	order := make([]string, 0)
 	all := make([]pluginFile, 0)
@@ -141,13 +141,13 @@ func selectPluginFilesWithCandidates(root string, desiredVersions ...map[string]
 			if entry == nil || !entry.Type().IsRegular() {
 				continue
 			}
-			if strings.HasSuffix(strings.ToLower(entry.Name()), extension) {
+			if pluginFileExtension(entry.Name(), extensions) != "" {
 				files = append(files, filepath.Join(dir, entry.Name()))
 			}
 		}
 		sort.Strings(files)
 		for _, path := range files {
-			file, okFile := pluginFileFromPath(path, extension)
+			file, okFile := pluginFileFromPath(path, pluginFileExtension(path, extensions))
 			if !okFile {
 				continue
 			}
@@ -174,6 +174,17 @@ func selectPluginFilesWithCandidates(root string, desiredVersions ...map[string]
 	return selected, all, nil
 }
 
+// pluginFileExtension returns the entry of extensions that name ends with, if any.
+func pluginFileExtension(name string, extensions []string) string {
+	lowerName := strings.ToLower(name)
+	for _, extension := range extensions {
+		if strings.HasSuffix(lowerName, extension) {
+			return extension
+		}
+	}
+	return ""
+}
+
 func normalizeDesiredPluginVersions(sources ...map[string]string) map[string]string {
 	out := make(map[string]string)
 	for _, source := range sources {
@@ -0,0 +1,520 @@
+package pluginhost
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginabi"
+	log "github.com/sirupsen/logrus"
+)
+
+// processPluginExtension marks plugin executables that the host runs as child
+// processes instead of loading them into its own address space.
+const processPluginExtension = ".plugin"
+
+const (
+	defaultProcessRestartBackoff    = time.Second
+	defaultProcessMaxRestartBackoff = time.Minute
+	// processStableRun resets the restart backoff once a process stayed up this long.
+	processStableRun = time.Minute
+	// processConnectTimeout bounds how long a Unix socket plugin may take to dial the host.
+	processConnectTimeout = 10 * time.Second
+	// processRegisterTimeout bounds replaying plugin.register after a restart.
+	processRegisterTimeout = 30 * time.Second
+	// processShutdownGrace is how long a plugin may take to exit after its connection closes.
+	processShutdownGrace = 5 * time.Second
+	// processExitProbe is how long a closed connection waits for the process exit status.
+	processExitProbe = 200 * time.Millisecond
+	// processStderrLineLimit flushes unterminated stderr output as one log line.
+	processStderrLineLimit = 64 << 10
+)
+
+// processOptions configures an out-of-process plugin from the plugins.configs.<id>.process block.
+type processOptions struct {
+	Transport         string
+	Args              []string
+	MemoryLimitBytes  uint64
+	CPULimitPercent   float64
+	RestartBackoff    time.Duration
+	MaxRestartBackoff time.Duration
+}
+
+type processOptionsYAML struct {
+	Transport         string   `yaml:"transport"`
+	Args              []string `yaml:"args"`
+	MemoryLimitMB     int      `yaml:"memory-limit-mb"`
+	CPULimitPercent   float64  `yaml:"cpu-limit-percent"`
+	RestartBackoff    string   `yaml:"restart-backoff"`
+	MaxRestartBackoff string   `yaml:"max-restart-backoff"`
+}
+
+func defaultProcessOptions() processOptions {
+	return processOptions{
+		Transport:         pluginabi.ProcessTransportStdio,
+		RestartBackoff:    defaultProcessRestartBackoff,
+		MaxRestartBackoff: defaultProcessMaxRestartBackoff,
+	}
+}
+
+// pluginProcessOptions reads the host-owned process block of a plugin config.
+// Invalid values fall back to the defaults with a warning.
+func pluginProcessOptions(id string, item config.PluginInstanceConfig) processOptions {
+	opts := defaultProcessOptions()
+	node := yamlMappingValue(&item.Raw, "process")
+	if node == nil || node.Kind == 0 {
+		return opts
+	}
+	var raw processOptionsYAML
+	if errDecode := node.Decode(&raw); errDecode != nil {
+		log.Warnf("pluginhost: invalid process config for plugin %s: %v", id, errDecode)
+		return opts
+	}
+	switch transport := strings.ToLower(strings.TrimSpace(raw.Transport)); transport {
+	case "", pluginabi.ProcessTransportStdio:
+	case pluginabi.ProcessTransportUnix:
+		opts.Transport = transport
+	default:
+		log.Warnf("pluginhost: unknown process transport %q for plugin %s, using stdio", raw.Transport, id)
+	}
+	opts.Args = append([]string(nil), raw.Args...)
+	if raw.MemoryLimitMB > 0 {
+		opts.MemoryLimitBytes = uint64(raw.MemoryLimitMB) << 20
+	}
+	if raw.CPULimitPercent > 0 {
+		opts.CPULimitPercent = raw.CPULimitPercent
+	}
+	if backoff, ok := parseProcessDuration(id, "restart-backoff", raw.RestartBackoff); ok {
+		opts.RestartBackoff = backoff
+	}
+	if backoff, ok := parseProcessDuration(id, "max-restart-backoff", raw.MaxRestartBackoff); ok {
+		opts.MaxRestartBackoff = backoff
+	}
+	opts.MaxRestartBackoff = max(opts.MaxRestartBackoff, opts.RestartBackoff)
+	return opts
+}
+
+func parseProcessDuration(id, key, raw string) (time.Duration, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, false
+	}
+	value, errParse := time.ParseDuration(raw)
+	if errParse != nil || value <= 0 {
+		log.Warnf("pluginhost: invalid process %s %q for plugin %s", key, raw, id)
+		return 0, false
+	}
+	return value, true
+}
+
+func isProcessPluginFile(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), processPluginExtension)
+}
+
+// processOptionsFor returns the process settings configured for plugin id.
+func (h *Host) processOptionsFor(id string) processOptions {
+	item, ok := h.pluginInstanceConfig(id)
+	if !ok {
+		return defaultProcessOptions()
+	}
+	return pluginProcessOptions(id, item)
+}
+
+// pluginInstanceConfig returns the plugins.configs entry for plugin id.
+func (h *Host) pluginInstanceConfig(id string) (config.PluginInstanceConfig, bool) {
+	if h == nil {
+		return config.PluginInstanceConfig{}, false
+	}
+	h.mu.Lock()
+	cfg := h.runtimeConfig
+	h.mu.Unlock()
+	if cfg == nil {
+		return config.PluginInstanceConfig{}, false
+	}
+	item, ok := cfg.Plugins.Configs[id]
+	return item, ok
+}
+
+type processPluginLoader struct{}
+
+func (processPluginLoader) Open(file pluginFile, host *Host) (pluginClient, error) {
+	return startProcessPluginClient(file, host, host.processOptionsFor(file.ID))
+}
+
+// processPluginClient runs a plugin executable as a supervised child process and
+// speaks the plugin method set with it over framed JSON. A process that exits,
+// drops its connection or exceeds its limits is restarted with exponential
+// backoff and registered again with the last configuration it accepted.
+type processPluginClient struct {
+	id   string
+	path string
+	host *Host
+	opts processOptions
+
+	mu       sync.Mutex
+	instance *pluginProcess
+	register []byte
+	restarts int
+	closed   bool
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+type pluginProcess struct {
+	cmd     *exec.Cmd
+	conn    *pluginabi.Conn
+	started time.Time
+	exited  chan struct{}
+	waitErr error
+}
+
+func startProcessPluginClient(file pluginFile, host *Host, opts processOptions) (*processPluginClient, error) {
+	client := &processPluginClient{
+		id:   file.ID,
+		path: file.Path,
+		host: host,
+		opts: opts,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	proc, errStart := client.start()
+	if errStart != nil {
+		return nil, errStart
+	}
+	client.instance = proc
+	go client.supervise(proc)
+	return client, nil
+}
+
+func (c *processPluginClient) Call(ctx context.Context, method string, request []byte) ([]byte, error) {
+	if c == nil {
+		return nil, fmt.Errorf("plugin client is closed")
+	}
+	c.mu.Lock()
+	proc, closed := c.instance, c.closed
+	c.mu.Unlock()
+	if closed {
+		return nil, fmt.Errorf("plugin client is closed")
+	}
+	if proc == nil {
+		return nil, fmt.Errorf("plugin process %s is restarting", c.id)
+	}
+	response, errCall := proc.conn.Call(ctx, method, request)
+	if errCall != nil {
+		if errors.Is(errCall, pluginabi.ErrConnClosed) {
+			return nil, fmt.Errorf("plugin process %s stopped during %s: %w", c.id, method, errCall)
+		}
+		return nil, fmt.Errorf("plugin call %s: %w", method, errCall)
+	}
+	if (method == pluginabi.MethodPluginRegister || method == pluginabi.MethodPluginReconfigure) && !isPluginErrorEnvelope(response) {
+		c.mu.Lock()
+		c.register = append([]byte(nil), request...)
+		c.mu.Unlock()
+	}
+	return response, nil
+}
+
+func (c *processPluginClient) Shutdown() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		<-c.done
+		return
+	}
+	c.closed = true
+	proc := c.instance
+	c.instance = nil
+	c.mu.Unlock()
+
+	if proc != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), processShutdownGrace)
+		_, _ = proc.conn.Call(ctx, pluginabi.MethodPluginShutdown, nil)
+		cancel()
+	}
+	close(c.stop)
+	<-c.done
+}
+
+// start launches the plugin executable and, after a restart, registers it again.
+func (c *processPluginClient) start() (*pluginProcess, error) {
+	proc, errSpawn := c.spawn()
+	if errSpawn != nil {
+		return nil, errSpawn
+	}
+	c.mu.Lock()
+	register := c.register
+	c.mu.Unlock()
+	if register == nil {
+		return proc, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), processRegisterTimeout)
+	defer cancel()
+	response, errRegister := proc.conn.Call(ctx, pluginabi.MethodPluginRegister, register)
+	if errRegister == nil && isPluginErrorEnvelope(response) {
+		errRegister = fmt.Errorf("plugin rejected its previous configuration")
+	}
+	if errRegister != nil {
+		proc.terminate(0)
+		return nil, fmt.Errorf("register restarted plugin: %w", errRegister)
+	}
+	return proc, nil
+}
+
+func (c *processPluginClient) spawn() (*pluginProcess, error) {
+	cmd := processCommand(c.id, c.path, c.opts.Args, append(os.Environ(),
+		pluginabi.EnvPluginID+"="+c.id,
+		pluginabi.EnvProcessTransport+"="+c.opts.Transport,
+	), c.opts)
+	cmd.Dir = filepath.Dir(c.path)
+	cmd.Stderr = &pluginStderrWriter{id: c.id}
+	proc := &pluginProcess{cmd: cmd, exited: make(chan struct{})}
+
+	if c.opts.Transport == pluginabi.ProcessTransportUnix {
+		if errConnect := c.spawnUnix(proc); errConnect != nil {
+			return nil, errConnect
+		}
+	} else {
+		stdin, errStdin := cmd.StdinPipe()
+		if errStdin != nil {
+			return nil, fmt.Errorf("plugin stdin: %w", errStdin)
+		}
+		stdout, errStdout := cmd.StdoutPipe()
+		if errStdout != nil {
+			return nil, fmt.Errorf("plugin stdout: %w", errStdout)
+		}
+		if errStart := cmd.Start(); errStart != nil {
+			return nil, fmt.Errorf("start plugin process %s: %w", c.path, errStart)
+		}
+		proc.started = time.Now()
+		go proc.wait()
+		proc.conn = pluginabi.NewConn(stdout, stdin, stdin)
+	}
+	go func() {
+		_ = proc.conn.Serve(context.Background(), c.handleHostCall)
+	}()
+	return proc, nil
+}
+
+// spawnUnix starts the process with a private socket and waits for it to dial in.
+func (c *processPluginClient) spawnUnix(proc *pluginProcess) error {
+	dir, errDir := os.MkdirTemp("", "cliproxy-plugin-")
+	if errDir != nil {
+		return fmt.Errorf("create plugin socket directory: %w", errDir)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	socket := filepath.Join(dir, "plugin.sock")
+	listener, errListen := net.Listen("unix", socket)
+	if errListen != nil {
+		return fmt.Errorf("listen on plugin socket: %w", errListen)
+	}
+	defer func() { _ = listener.Close() }()
+
+	proc.cmd.Env = append(proc.cmd.Env, pluginabi.EnvProcessSocket+"="+socket)
+	if errStart := proc.cmd.Start(); errStart != nil {
+		return fmt.Errorf("start plugin process %s: %w", c.path, errStart)
+	}
+	proc.started = time.Now()
+	go proc.wait()
+
+	type acceptResult struct {
+		conn net.Conn
+		err  error
+	}
+	accepted := make(chan acceptResult, 1)
+	go func() {
+		conn, errAccept := listener.Accept()
+		accepted <- acceptResult{conn: conn, err: errAccept}
+	}()
+	timer := time.NewTimer(processConnectTimeout)
+	defer timer.Stop()
+	select {
+	case result := <-accepted:
+		if result.err != nil {
+			proc.kill()
+			return fmt.Errorf("accept plugin connection: %w", result.err)
+		}
+		proc.conn = pluginabi.NewConn(result.conn, result.conn, result.conn)
+		return nil
+	case <-proc.exited:
+		return fmt.Errorf("plugin process exited before connecting: %v", proc.waitErr)
+	case <-timer.C:
+		proc.kill()
+		return fmt.Errorf("plugin process did not connect within %s", processConnectTimeout)
+	}
+}
+
+func (c *processPluginClient) handleHostCall(ctx context.Context, method string, payload []byte) ([]byte, error) {
+	if c.host == nil {
+		return marshalRPCError("host_unavailable", "plugin host is unavailable"), nil
+	}
+	response, errCall := c.host.callFromPlugin(withHostCallbackPluginID(ctx, c.id), method, payload)
+	if errCall != nil {
+		response = marshalRPCError("host_call_failed", errCall.Error())
+	}
+	return response, nil
+}
+
+// supervise restarts the plugin process whenever it stops until Shutdown is called.
+func (c *processPluginClient) supervise(proc *pluginProcess) {
+	defer close(c.done)
+	backoff := c.opts.RestartBackoff
+	for {
+		reason, stopping := c.watch(proc)
+		c.mu.Lock()
+		if c.instance == proc {
+			c.instance = nil
+		}
+		c.mu.Unlock()
+		if stopping {
+			proc.terminate(processShutdownGrace)
+			return
+		}
+		proc.terminate(0)
+		if time.Since(proc.started) >= processStableRun {
+			backoff = c.opts.RestartBackoff
+		}
+		c.mu.Lock()
+		c.restarts++
+		restarts := c.restarts
+		c.mu.Unlock()
+		log.WithFields(pluginLogFields(c.id, "", "", c.path)).Warnf("pluginhost: plugin process stopped (%s), restarting in %s", reason, backoff)
+		publishPluginCrashEvent(c.id, c.path, reason, restarts, backoff)
+
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, c.opts.MaxRestartBackoff)
+			next, errStart := c.start()
+			if errStart != nil {
+				log.WithFields(pluginLogFields(c.id, "", "", c.path)).Warnf("pluginhost: plugin process restart failed, retrying in %s: %v", backoff, errStart)
+				continue
+			}
+			c.mu.Lock()
+			if c.closed {
+				c.mu.Unlock()
+				next.terminate(processShutdownGrace)
+				return
+			}
+			c.instance = next
+			c.mu.Unlock()
+			log.WithFields(pluginLogFields(c.id, "", "", c.path)).Info("pluginhost: plugin process restarted")
+			proc = next
+			break
+		}
+	}
+}
+
+// watch blocks until proc stops or Shutdown is called and describes why.
+func (c *processPluginClient) watch(proc *pluginProcess) (string, bool) {
+	limitStop := make(chan struct{})
+	defer close(limitStop)
+	limits := watchProcessLimits(c.id, proc.cmd.Process.Pid, c.opts, limitStop)
+	select {
+	case <-c.stop:
+		return "", true
+	case <-proc.exited:
+		return proc.exitReason(), false
+	case <-proc.conn.Done():
+		// A crashing process usually closes its connection just before it exits;
+		// prefer reporting the exit status when it follows shortly.
+		timer := time.NewTimer(processExitProbe)
+		defer timer.Stop()
+		select {
+		case <-proc.exited:
+			return proc.exitReason(), false
+		case <-timer.C:
+		}
+		if errConn := proc.conn.Err(); errConn != nil {
+			return "connection failed: " + errConn.Error(), false
+		}
+		return "connection closed", false
+	case reason := <-limits:
+		return reason, false
+	}
+}
+
+func (p *pluginProcess) wait() {
+	p.waitErr = p.cmd.Wait()
+	close(p.exited)
+}
+
+func (p *pluginProcess) exitReason() string {
+	if p.waitErr != nil {
+		return p.waitErr.Error()
+	}
+	return "exited"
+}
+
+func (p *pluginProcess) kill() {
+	if p.cmd.Process != nil {
+		_ = p.cmd.Process.Kill()
+	}
+}
+
+// terminate closes the connection, which asks the plugin to exit, and kills
+// the process if it is still running after grace.
+func (p *pluginProcess) terminate(grace time.Duration) {
+	if p.conn != nil {
+		_ = p.conn.Close()
+	}
+	if grace > 0 {
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+		select {
+		case <-p.exited:
+			return
+		case <-timer.C:
+		}
+	}
+	p.kill()
+	<-p.exited
+}
+
+// pluginStderrWriter forwards plugin stderr to the host log one line at a time.
+type pluginStderrWriter struct {
+	id  string
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *pluginStderrWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		index := bytes.IndexByte(w.buf, '\n')
+		if index < 0 {
+			break
+		}
+		w.emit(w.buf[:index])
+		w.buf = w.buf[index+1:]
+	}
+	if len(w.buf) >= processStderrLineLimit {
+		w.emit(w.buf)
+		w.buf = nil
+	}
+	return len(p), nil
+}
+
+func (w *pluginStderrWriter) emit(line []byte) {
+	text := strings.TrimRight(string(line), "\r")
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	log.WithField("plugin_id", w.id).Info(text)
+}
@@ -0,0 +1,190 @@
+package pluginhost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginabi"
+	"gopkg.in/yaml.v3"
+)
+
+const processHelperEnv = "CLIPROXY_PROCESS_PLUGIN_HELPER"
+
+// TestProcessPluginHelper is not a real test: the process client tests run the
+// test binary with this test selected so it acts as a plugin executable.
+func TestProcessPluginHelper(t *testing.T) {
+	if os.Getenv(processHelperEnv) != "1" {
+		t.Skip("helper process for out-of-process plugin tests")
+	}
+	conn, errDial := pluginabi.DialHost()
+	if errDial != nil {
+		fmt.Fprintln(os.Stderr, errDial)
+		os.Exit(2)
+	}
+	var mu sync.Mutex
+	var registered string
+	errServe := conn.Serve(context.Background(), func(ctx context.Context, method string, payload []byte) ([]byte, error) {
+		switch method {
+		case pluginabi.MethodPluginRegister:
+			mu.Lock()
+			registered = string(payload)
+			mu.Unlock()
+			return []byte(`{"ok":true}`), nil
+		case "test.state":
+			mu.Lock()
+			defer mu.Unlock()
+			return json.Marshal(map[string]any{"pid": os.Getpid(), "registered": registered, "plugin_id": os.Getenv(pluginabi.EnvPluginID)})
+		case "test.host_log":
+			return conn.Call(ctx, pluginabi.MethodHostLog, []byte(`{"level":"debug","message":"from process plugin"}`))
+		case "test.crash":
+			fmt.Fprintln(os.Stderr, "crashing on request")
+			os.Exit(3)
+		case pluginabi.MethodPluginShutdown:
+			return []byte(`{"ok":true}`), nil
+		}
+		return nil, fmt.Errorf("unknown method %s", method)
+	})
+	if errServe != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+type processHelperState struct {
+	PID        int    `json:"pid"`
+	Registered string `json:"registered"`
+	PluginID   string `json:"plugin_id"`
+}
+
+func processHelperClient(t *testing.T, transport string) *processPluginClient {
+	t.Helper()
+	t.Setenv(processHelperEnv, "1")
+	opts := defaultProcessOptions()
+	opts.Transport = transport
+	opts.Args = []string{"-test.run=^TestProcessPluginHelper$"}
+	opts.RestartBackoff = 10 * time.Millisecond
+	client, errStart := startProcessPluginClient(pluginFile{ID: "process-test", Path: os.Args[0]}, New(), opts)
+	if errStart != nil {
+		t.Fatalf("startProcessPluginClient() error = %v", errStart)
+	}
+	t.Cleanup(client.Shutdown)
+	return client
+}
+
+func processHelperStateOf(t *testing.T, client *processPluginClient) (processHelperState, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	raw, errCall := client.Call(ctx, "test.state", nil)
+	if errCall != nil {
+		return processHelperState{}, errCall
+	}
+	var state processHelperState
+	if errUnmarshal := json.Unmarshal(raw, &state); errUnmarshal != nil {
+		t.Fatalf("decode state %s: %v", raw, errUnmarshal)
+	}
+	return state, nil
+}
+
+func TestProcessPluginClientRestartsCrashedProcess(t *testing.T) {
+	for _, transport := range []string{pluginabi.ProcessTransportStdio, pluginabi.ProcessTransportUnix} {
+		t.Run(transport, func(t *testing.T) {
+			client := processHelperClient(t, transport)
+			ctx := context.Background()
+
+			if _, errRegister := client.Call(ctx, pluginabi.MethodPluginRegister, []byte(`{"config_yaml":"ZW5hYmxlZDogdHJ1ZQo="}`)); errRegister != nil {
+				t.Fatalf("register error = %v", errRegister)
+			}
+			first, errState := processHelperStateOf(t, client)
+			if errState != nil || first.PluginID != "process-test" {
+				t.Fatalf("state = %+v, %v", first, errState)
+			}
+			if raw, errLog := client.Call(ctx, "test.host_log", nil); errLog != nil || !strings.Contains(string(raw), `"ok":true`) {
+				t.Fatalf("host callback = %s, %v", raw, errLog)
+			}
+
+			if _, errCrash := client.Call(ctx, "test.crash", nil); errCrash == nil {
+				t.Fatal("call into a crashing process succeeded")
+			}
+			deadline := time.Now().Add(10 * time.Second)
+			var restarted processHelperState
+			for {
+				restarted, errState = processHelperStateOf(t, client)
+				if errState == nil && restarted.PID != first.PID {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("process was not restarted: state %+v, error %v", restarted, errState)
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+			if restarted.Registered != `{"config_yaml":"ZW5hYmxlZDogdHJ1ZQo="}` {
+				t.Fatalf("restarted process registered with %q, want the previous config replayed", restarted.Registered)
+			}
+
+			client.Shutdown()
+			if _, errCall := client.Call(ctx, "test.state", nil); errCall == nil {
+				t.Fatal("call after Shutdown succeeded")
+			}
+			if process, errFind := os.FindProcess(restarted.PID); errFind == nil && processAlive(process) {
+				t.Fatalf("plugin process %d still running after Shutdown", restarted.PID)
+			}
+		})
+	}
+}
+
+func TestPluginProcessOptionsFromConfig(t *testing.T) {
+	var item config.PluginInstanceConfig
+	if errUnmarshal := yaml.Unmarshal([]byte(`
+enabled: true
+process:
+  transport: unix
+  args: ["--verbose"]
+  memory-limit-mb: 256
+  cpu-limit-percent: 50
+  restart-backoff: 2s
+  max-restart-backoff: 1s
+`), &item); errUnmarshal != nil {
+		t.Fatalf("unmarshal: %v", errUnmarshal)
+	}
+	opts := pluginProcessOptions("sample", item)
+	if opts.Transport != pluginabi.ProcessTransportUnix || len(opts.Args) != 1 || opts.MemoryLimitBytes != 256<<20 || opts.CPULimitPercent != 50 {
+		t.Fatalf("opts = %+v", opts)
+	}
+	if opts.RestartBackoff != 2*time.Second || opts.MaxRestartBackoff != 2*time.Second {
+		t.Fatalf("backoff = %s/%s, want the maximum raised to the initial backoff", opts.RestartBackoff, opts.MaxRestartBackoff)
+	}
+
+	if opts := pluginProcessOptions("sample", config.PluginInstanceConfig{}); opts.Transport != pluginabi.ProcessTransportStdio || opts.RestartBackoff != defaultProcessRestartBackoff {
+		t.Fatalf("default opts = %+v", opts)
+	}
+}
+
+func TestSelectPluginFilesIncludesProcessPlugins(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"alpha-v1.0.0.plugin", "alpha-v1.2.0.plugin", "notes.txt"} {
+		if errWrite := os.WriteFile(filepath.Join(root, name), []byte("x"), 0o755); errWrite != nil {
+			t.Fatalf("write %s: %v", name, errWrite)
+		}
+	}
+	files, errSelect := selectPluginFiles(root)
+	if errSelect != nil {
+		t.Fatalf("selectPluginFiles() error = %v", errSelect)
+	}
+	if len(files) != 1 || files[0].ID != "alpha" || files[0].Version != "1.2.0" || !isProcessPluginFile(files[0].Path) {
+		t.Fatalf("files = %+v, want the newest alpha process plugin", files)
+	}
+}
+
+func processAlive(process *os.Process) bool {
+	return process.Signal(syscall.Signal(0)) == nil
+}
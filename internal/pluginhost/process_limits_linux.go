@@ -0,0 +1,160 @@
+//go:build linux
+
+package pluginhost
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// processLimitInterval is how often a limited plugin process is sampled.
+	processLimitInterval = 2 * time.Second
+	// processCPULimitSamples is how many consecutive samples may exceed the CPU
+	// limit before the process is restarted, so short bursts are tolerated.
+	processCPULimitSamples = 3
+	// processClockTicks is the USER_HZ unit of /proc/<pid>/stat CPU times.
+	processClockTicks = 100
+	// processDataLimitHeadroom is added to the memory limit for the hard
+	// RLIMIT_DATA ceiling. Runtimes such as Go's reserve heap address space
+	// well beyond what is resident, so the ceiling only stops runaway growth;
+	// the poller restarts the process at the resident limit itself.
+	processDataLimitHeadroom = 256 << 20
+	// processLimitExecEnv marks a re-executed host process that sets the hard
+	// limits it carries and then execs the plugin binary.
+	processLimitExecEnv = "CLIPROXY_PLUGIN_DATA_LIMIT"
+)
+
+func init() {
+	if raw, ok := os.LookupEnv(processLimitExecEnv); ok {
+		execWithProcessLimits(raw)
+	}
+}
+
+// processCommand builds the command for a plugin process. With a memory limit
+// the host binary re-executes itself, applies RLIMIT_DATA and execs the plugin
+// in place, so no plugin code runs before the limit is in force and the
+// process keeps the PID the poller samples. RLIMIT_AS is not used because Go
+// plugins reserve more address space at start than any useful limit allows.
+func processCommand(id, path string, args, env []string, opts processOptions) *exec.Cmd {
+	if opts.MemoryLimitBytes == 0 {
+		cmd := exec.Command(path, args...)
+		cmd.Env = env
+		return cmd
+	}
+	self, errSelf := os.Executable()
+	if errSelf != nil {
+		log.WithField("plugin_id", id).Warnf("pluginhost: cannot apply hard memory limit, relying on sampling: %v", errSelf)
+		cmd := exec.Command(path, args...)
+		cmd.Env = env
+		return cmd
+	}
+	cmd := exec.Command(self, append([]string{path}, args...)...)
+	cmd.Env = append(slices.Clone(env), processLimitExecEnv+"="+strconv.FormatUint(opts.MemoryLimitBytes+processDataLimitHeadroom, 10))
+	return cmd
+}
+
+// execWithProcessLimits runs in the re-executed host: it applies the data
+// limit and replaces itself with the plugin binary. It never returns.
+func execWithProcessLimits(raw string) {
+	fail := func(errLimit error) {
+		_, _ = fmt.Fprintf(os.Stderr, "pluginhost: start plugin with limits: %v\n", errLimit)
+		os.Exit(126)
+	}
+	if len(os.Args) < 2 {
+		fail(fmt.Errorf("missing plugin path"))
+	}
+	limit, errParse := strconv.ParseUint(raw, 10, 64)
+	if errParse != nil {
+		fail(fmt.Errorf("invalid data limit %q", raw))
+	}
+	if errLimit := syscall.Setrlimit(syscall.RLIMIT_DATA, &syscall.Rlimit{Cur: limit, Max: limit}); errLimit != nil {
+		fail(fmt.Errorf("set RLIMIT_DATA: %w", errLimit))
+	}
+	env := slices.DeleteFunc(os.Environ(), func(entry string) bool {
+		return strings.HasPrefix(entry, processLimitExecEnv+"=")
+	})
+	fail(syscall.Exec(os.Args[1], os.Args[1:], env))
+}
+
+// watchProcessLimits samples /proc for the plugin process and reports a reason
+// once its resident memory exceeds the limit or its CPU usage stays above the
+// limit. CPU use is only limited here, since RLIMIT_CPU caps total CPU time
+// rather than a share of a core; memory is also capped by processCommand. It
+// returns nil when no limit is configured.
+func watchProcessLimits(id string, pid int, opts processOptions, stop <-chan struct{}) <-chan string {
+	if opts.MemoryLimitBytes == 0 && opts.CPULimitPercent <= 0 {
+		return nil
+	}
+	exceeded := make(chan string, 1)
+	go func() {
+		ticker := time.NewTicker(processLimitInterval)
+		defer ticker.Stop()
+		lastTicks, lastAt := uint64(0), time.Time{}
+		overCPU := 0
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			ticks, rss, errSample := sampleProcessStat(pid)
+			if errSample != nil {
+				return
+			}
+			now := time.Now()
+			if opts.MemoryLimitBytes > 0 && rss > opts.MemoryLimitBytes {
+				exceeded <- fmt.Sprintf("memory limit exceeded: %d MiB resident, limit %d MiB", rss>>20, opts.MemoryLimitBytes>>20)
+				return
+			}
+			if opts.CPULimitPercent > 0 && !lastAt.IsZero() && ticks >= lastTicks {
+				percent := float64(ticks-lastTicks) / processClockTicks / now.Sub(lastAt).Seconds() * 100
+				if percent > opts.CPULimitPercent {
+					overCPU++
+				} else {
+					overCPU = 0
+				}
+				if overCPU >= processCPULimitSamples {
+					exceeded <- fmt.Sprintf("cpu limit exceeded: %.0f%% of a core, limit %.0f%%", percent, opts.CPULimitPercent)
+					return
+				}
+			}
+			lastTicks, lastAt = ticks, now
+		}
+	}()
+	return exceeded
+}
+
+// sampleProcessStat returns the total CPU ticks and resident bytes of pid.
+func sampleProcessStat(pid int) (uint64, uint64, error) {
+	raw, errRead := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if errRead != nil {
+		return 0, 0, errRead
+	}
+	// The command name may contain spaces, so fields are counted after its closing parenthesis.
+	text := string(raw)
+	end := strings.LastIndexByte(text, ')')
+	if end < 0 {
+		return 0, 0, fmt.Errorf("unexpected stat format")
+	}
+	fields := strings.Fields(text[end+1:])
+	// Fields start at "state" (3); utime is 14, stime 15 and rss 24.
+	if len(fields) < 22 {
+		return 0, 0, fmt.Errorf("unexpected stat format")
+	}
+	utime, errUser := strconv.ParseUint(fields[11], 10, 64)
+	stime, errSystem := strconv.ParseUint(fields[12], 10, 64)
+	rssPages, errRSS := strconv.ParseUint(fields[21], 10, 64)
+	if errUser != nil || errSystem != nil || errRSS != nil {
+		return 0, 0, fmt.Errorf("unexpected stat format")
+	}
+	return utime + stime, rssPages * uint64(os.Getpagesize()), nil
+}
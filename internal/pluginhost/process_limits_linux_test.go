@@ -0,0 +1,28 @@
+//go:build linux
+
+package pluginhost
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestProcessCommandAppliesDataLimitBeforeExec(t *testing.T) {
+	opts := processOptions{MemoryLimitBytes: 64 << 20}
+	cmd := processCommand("limited", "/bin/sh", []string{"-c", "ulimit -d; echo ${" + processLimitExecEnv + ":-unset}"}, os.Environ(), opts)
+	out, errRun := cmd.Output()
+	if errRun != nil {
+		t.Fatalf("run limited command: %v", errRun)
+	}
+	lines := strings.Fields(string(out))
+	want := strconv.FormatUint((opts.MemoryLimitBytes+processDataLimitHeadroom)>>10, 10)
+	if len(lines) != 2 || lines[0] != want || lines[1] != "unset" {
+		t.Fatalf("output = %q, want data limit %s KiB and the marker removed", out, want)
+	}
+
+	if cmd := processCommand("unlimited", "/bin/sh", nil, nil, processOptions{}); cmd.Path != "/bin/sh" {
+		t.Fatalf("unlimited command path = %q, want the plugin binary", cmd.Path)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !linux
+
+package pluginhost
+
+import (
+	"os/exec"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// processCommand builds the command for a plugin process. Hard limits are only
+// applied on Linux.
+func processCommand(_, path string, args, env []string, _ processOptions) *exec.Cmd {
+	cmd := exec.Command(path, args...)
+	cmd.Env = env
+	return cmd
+}
+
+// watchProcessLimits is only implemented on Linux; elsewhere configured limits
+// are reported once per process start and not enforced.
+func watchProcessLimits(id string, pid int, opts processOptions, stop <-chan struct{}) <-chan string {
+	if opts.MemoryLimitBytes > 0 || opts.CPULimitPercent > 0 {
+		log.WithField("plugin_id", id).Warn("pluginhost: plugin process limits are only enforced on linux")
+	}
+	return nil
+}
@@ -83,6 +83,11 @@ func registerRPCPlugin(ctx context.Context, host *Host, id string, client plugin
 			ExecutorOutputFormats:         append([]string(nil), resp.Capabilities.ExecutorOutputFormats...),
 		},
 	}
+	if resp.Capabilities.EventSubscriber {
+		plugin.Capabilities.EventSubscriber = adapter
+		plugin.Capabilities.EventTopics = append([]string(nil), resp.Capabilities.EventTopics...)
+		plugin.Capabilities.EventQueueSize = resp.Capabilities.EventQueueSize
+	}
 	if resp.Capabilities.ModelRegistrar {
 		plugin.Capabilities.ModelRegistrar = adapter
 	}
@@ -540,6 +545,16 @@ func (a *rpcPluginAdapter) HandleUsage(ctx context.Context, record pluginapi.Usa
 	_, _ = callPlugin[rpcEmptyResponse](ctx, a.client, pluginabi.MethodUsageHandle, record)
 }
 
+func (a *rpcPluginAdapter) HandleEvent(ctx context.Context, event pluginapi.HostEvent) error {
+	callbackID, closeCallback := a.openHostCallbackContext(ctx)
+	defer closeCallback()
+	_, errCall := callPlugin[rpcEmptyResponse](ctx, a.client, pluginabi.MethodEventHandle, rpcHostEvent{
+		HostEvent:      event,
+		HostCallbackID: callbackID,
+	})
+	return errCall
+}
+
 func (a *rpcPluginAdapter) RegisterCommandLine(ctx context.Context, req pluginapi.CommandLineRegistrationRequest) (pluginapi.CommandLineRegistrationResponse, error) {
 	return callPlugin[pluginapi.CommandLineRegistrationResponse](ctx, a.client, pluginabi.MethodCommandLineRegister, req)
 }
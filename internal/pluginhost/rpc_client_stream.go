@@ -15,7 +15,9 @@ func (a *rpcPluginAdapter) ExecuteStream(ctx context.Context, req pluginapi.Exec
 	}
 	streamID, chunks, cleanupStream := a.host.streams.open(ctx)
 	callbackID, closeCallback := a.openHostCallbackContext(ctx)
-	cleanup := combinedCleanup(cleanupStream, closeCallback)
+	// The lease keeps a replaced plugin instance alive until its stream ends.
+	releaseClient := holdPluginClient(a.client)
+	cleanup := combinedCleanup(cleanupStream, closeCallback, releaseClient)
 	rpcReq := rpcExecutorRequest{
 		ExecutorRequest: req,
 		StreamID:        streamID,
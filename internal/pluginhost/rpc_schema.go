@@ -4,9 +4,22 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginabi"
 	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
 )
 
+// hostStateCallbacksSupported reports whether a plugin registered with
+// schemaVersion may call host.kv.* and host.timer.*.
+func hostStateCallbacksSupported(schemaVersion uint32) bool {
+	return schemaVersion >= pluginabi.SchemaVersionHostState
+}
+
+// eventSubscriptionsSupported reports whether a plugin registered with
+// schemaVersion receives event.handle calls.
+func eventSubscriptionsSupported(schemaVersion uint32) bool {
+	return schemaVersion >= pluginabi.SchemaVersionEvents
+}
+
 type rpcLifecycleRequest struct {
 	ConfigYAML    []byte `json:"config_yaml"`
 	SchemaVersion uint32 `json:"schema_version"`
@@ -41,6 +54,9 @@ type rpcCapabilities struct {
 	StreamChunkInterceptor        bool                         `json:"response_stream_interceptor"`
 	ThinkingApplier               bool                         `json:"thinking_applier"`
 	UsagePlugin                   bool                         `json:"usage_plugin"`
+	EventSubscriber               bool                         `json:"event_subscriber"`
+	EventTopics                   []string                     `json:"event_topics,omitempty"`
+	EventQueueSize                int                          `json:"event_queue_size,omitempty"`
 	CommandLinePlugin             bool                         `json:"command_line_plugin"`
 	ManagementAPI                 bool                         `json:"management_api"`
 }
@@ -100,6 +116,11 @@ type rpcRequestCompletion struct {
 	HostCallbackID string `json:"host_callback_id,omitempty"`
 }
 
+type rpcHostEvent struct {
+	pluginapi.HostEvent
+	HostCallbackID string `json:"host_callback_id,omitempty"`
+}
+
 type rpcResponseInterceptRequest struct {
 	pluginapi.ResponseInterceptRequest
 	HostCallbackID string `json:"host_callback_id,omitempty"`
@@ -152,6 +173,9 @@ func rpcCapabilitiesFromPlugin(plugin pluginapi.Plugin) rpcCapabilities {
 		StreamChunkInterceptor:        caps.StreamChunkInterceptor != nil,
 		ThinkingApplier:               caps.ThinkingApplier != nil,
 		UsagePlugin:                   caps.UsagePlugin != nil,
+		EventSubscriber:               caps.EventSubscriber != nil,
+		EventTopics:                   append([]string(nil), caps.EventTopics...),
+		EventQueueSize:                caps.EventQueueSize,
 		CommandLinePlugin:             caps.CommandLinePlugin != nil,
 		ManagementAPI:                 caps.ManagementAPI != nil,
 	}
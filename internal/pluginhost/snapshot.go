@@ -29,6 +29,8 @@ type RegisteredPluginInfo struct {
 	SupportsOAuth bool
 	OAuthProvider string
 	Menus         []RegisteredPluginMenu
+	// Events is set when the plugin subscribes to host events.
+	Events *PluginEventStats
 }
 
 // RegisteredPluginMenu describes a plugin-owned resource menu entry.
@@ -59,6 +61,18 @@ func (h *Host) activeRecordsFromSnapshot(snap *Snapshot) []capabilityRecord {
 	return out
 }
 
+// pluginSchemaVersion returns the schema version an active plugin registered
+// with. active is false while the plugin is not in the snapshot, for example
+// during its first plugin.register call.
+func (h *Host) pluginSchemaVersion(id string) (version uint32, active bool) {
+	for _, record := range h.activeRecords() {
+		if record.id == id {
+			return record.plugin.SchemaVersion, true
+		}
+	}
+	return 0, false
+}
+
 // RegisteredPlugins returns a stable copy of plugin metadata in the current runtime snapshot.
 func (h *Host) RegisteredPlugins() []RegisteredPluginInfo {
 	records := h.activeRecords()
@@ -82,6 +96,7 @@ func (h *Host) RegisteredPlugins() []RegisteredPluginInfo {
 			SupportsOAuth: authProvider != nil,
 			OAuthProvider: oauthProvider,
 			Menus:         menusByPlugin[record.id],
+			Events:        h.eventDispatcher.stats(record.id),
 		})
 	}
 	return out
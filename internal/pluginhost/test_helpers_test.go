@@ -41,6 +41,7 @@ type testSymbolLookup struct {
 	reconfigureOverride func([]byte) pluginapi.Plugin
 	schemaVersion       uint32
 	lastLifecycle       rpcLifecycleRequest
+	healthError         *pluginabi.Error
 }
 
 func newTestSymbolLookup(plugin *testPlugin) *testSymbolLookup {
@@ -53,6 +54,11 @@ func (l *testSymbolLookup) Call(ctx context.Context, method string, request []by
 		return l.callLifecycle(request, false)
 	case pluginabi.MethodPluginReconfigure:
 		return l.callLifecycle(request, true)
+	case pluginabi.MethodPluginHealth:
+		if l.healthError != nil {
+			return json.Marshal(pluginabi.Envelope{Error: l.healthError})
+		}
+		return marshalRPCResult(rpcEmptyResponse{})
 	case pluginabi.MethodThinkingIdentifier:
 		if l.active.Capabilities.ThinkingApplier == nil {
 			return nil, fmt.Errorf("missing thinking applier")
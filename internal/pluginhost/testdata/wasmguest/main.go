@@ -0,0 +1,69 @@
+//go:build wasip1
+
+// Command wasmguest is the WASM plugin used by the pluginhost tests.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginabi"
+)
+
+var (
+	registered string
+	calls      int
+	busy       int
+	hoard      [][]byte
+)
+
+func init() {
+	pluginabi.ServeWASM(handle)
+}
+
+func handle(ctx context.Context, method string, payload []byte) ([]byte, error) {
+	calls++
+	switch method {
+	case pluginabi.MethodPluginRegister, pluginabi.MethodPluginReconfigure:
+		registered = string(payload)
+		return []byte(`{"ok":true}`), nil
+	case pluginabi.MethodPluginShutdown:
+		return []byte(`{"ok":true}`), nil
+	case "test.state":
+		return json.Marshal(map[string]any{"registered": registered, "calls": calls, "plugin_id": os.Getenv(pluginabi.EnvPluginID)})
+	case "test.echo":
+		return payload, nil
+	case "test.host_call":
+		var request struct {
+			Method  string          `json:"method"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if errUnmarshal := json.Unmarshal(payload, &request); errUnmarshal != nil {
+			return nil, errUnmarshal
+		}
+		return pluginabi.CallHost(ctx, request.Method, request.Payload)
+	case "test.spin":
+		for {
+			spin()
+		}
+	case "test.busy":
+		// Spins without calling anything, so no guest calls are counted.
+		for {
+			busy++
+		}
+	case "test.hoard":
+		for {
+			hoard = append(hoard, make([]byte, 1<<20))
+		}
+	case "test.panic":
+		panic("boom")
+	}
+	return nil, fmt.Errorf("unknown method %s", method)
+}
+
+//go:noinline
+func spin() {}
+
+func main() {}
@@ -0,0 +1,293 @@
+package pluginhost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/home"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginabi"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	pluginTimerMethodPrefix  = "timer."
+	pluginTimerHomeKeyPrefix = "plugin-timer:"
+	minPluginTimerInterval   = time.Second
+	maxPluginTimerNameLength = 128
+	maxPluginTimersPerPlugin = 32
+	pluginTimerCallTimeout   = 5 * time.Minute
+)
+
+// pluginTimer is one host.timer.schedule registration. Due times are aligned
+// to the interval or cron grid so every node of a Home cluster computes the
+// same slots and only the node that claims a slot runs it.
+type pluginTimer struct {
+	pluginID string
+	name     string
+	method   string
+	payload  json.RawMessage
+	interval time.Duration
+	cron     *cronSchedule
+
+	timer   *time.Timer
+	running bool
+	stopped bool
+}
+
+func (t *pluginTimer) next(after time.Time) (time.Time, error) {
+	if t.cron != nil {
+		return t.cron.next(after)
+	}
+	return after.UTC().Truncate(t.interval).Add(t.interval), nil
+}
+
+type pluginTimerRegistry struct {
+	mu       sync.Mutex
+	byPlugin map[string]map[string]*pluginTimer
+}
+
+func newPluginTimerRegistry() *pluginTimerRegistry {
+	return &pluginTimerRegistry{byPlugin: make(map[string]map[string]*pluginTimer)}
+}
+
+func (r *pluginTimerRegistry) stopLocked(timer *pluginTimer) {
+	timer.stopped = true
+	if timer.timer != nil {
+		timer.timer.Stop()
+	}
+}
+
+// cancel stops one timer of a plugin and reports whether it existed.
+func (r *pluginTimerRegistry) cancel(pluginID, name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	timer := r.byPlugin[pluginID][name]
+	if timer == nil {
+		return false
+	}
+	r.stopLocked(timer)
+	delete(r.byPlugin[pluginID], name)
+	if len(r.byPlugin[pluginID]) == 0 {
+		delete(r.byPlugin, pluginID)
+	}
+	return true
+}
+
+// cancelPlugin stops every timer of a plugin.
+func (r *pluginTimerRegistry) cancelPlugin(pluginID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, timer := range r.byPlugin[pluginID] {
+		r.stopLocked(timer)
+	}
+	delete(r.byPlugin, pluginID)
+}
+
+// retain stops the timers of plugins that are no longer active.
+func (r *pluginTimerRegistry) retain(active map[string]struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for pluginID, timers := range r.byPlugin {
+		if _, ok := active[pluginID]; ok {
+			continue
+		}
+		for _, timer := range timers {
+			r.stopLocked(timer)
+		}
+		delete(r.byPlugin, pluginID)
+	}
+}
+
+func newPluginTimer(pluginID string, req pluginapi.HostTimerScheduleRequest) (*pluginTimer, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("timer name is required")
+	}
+	if len(name) > maxPluginTimerNameLength {
+		return nil, fmt.Errorf("timer name exceeds %d bytes", maxPluginTimerNameLength)
+	}
+	method := strings.TrimSpace(req.Method)
+	if method == "" {
+		method = pluginabi.MethodTimerFire
+	}
+	if !strings.HasPrefix(method, pluginTimerMethodPrefix) || method == pluginTimerMethodPrefix {
+		return nil, fmt.Errorf("timer method %q must start with %q", method, pluginTimerMethodPrefix)
+	}
+	timer := &pluginTimer{
+		pluginID: pluginID,
+		name:     name,
+		method:   method,
+		payload:  append(json.RawMessage(nil), req.Payload...),
+	}
+	intervalText := strings.TrimSpace(req.Interval)
+	cronText := strings.TrimSpace(req.Cron)
+	switch {
+	case intervalText != "" && cronText != "":
+		return nil, fmt.Errorf("timer %s sets both interval and cron", name)
+	case intervalText != "":
+		interval, errParse := time.ParseDuration(intervalText)
+		if errParse != nil {
+			return nil, fmt.Errorf("timer %s interval: %w", name, errParse)
+		}
+		if interval < minPluginTimerInterval {
+			return nil, fmt.Errorf("timer %s interval must be at least %s", name, minPluginTimerInterval)
+		}
+		timer.interval = interval
+	case cronText != "":
+		schedule, errParse := parseCronSchedule(cronText)
+		if errParse != nil {
+			return nil, fmt.Errorf("timer %s: %w", name, errParse)
+		}
+		timer.cron = schedule
+	default:
+		return nil, fmt.Errorf("timer %s needs an interval or a cron expression", name)
+	}
+	return timer, nil
+}
+
+// schedulePluginTimer registers timer, replacing a timer of the same name, and
+// arms it for its next slot.
+func (h *Host) schedulePluginTimer(timer *pluginTimer) (time.Time, error) {
+	nextRun, errNext := timer.next(time.Now())
+	if errNext != nil {
+		return time.Time{}, errNext
+	}
+	registry := h.timers
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	timers := registry.byPlugin[timer.pluginID]
+	if timers == nil {
+		timers = make(map[string]*pluginTimer)
+		registry.byPlugin[timer.pluginID] = timers
+	}
+	if existing := timers[timer.name]; existing != nil {
+		registry.stopLocked(existing)
+	} else if len(timers) >= maxPluginTimersPerPlugin {
+		return time.Time{}, fmt.Errorf("plugin %s already has %d timers", timer.pluginID, maxPluginTimersPerPlugin)
+	}
+	timers[timer.name] = timer
+	h.armPluginTimerLocked(timer, nextRun)
+	return nextRun, nil
+}
+
+func (h *Host) armPluginTimerLocked(timer *pluginTimer, due time.Time) {
+	timer.timer = time.AfterFunc(time.Until(due), func() {
+		h.firePluginTimer(timer, due)
+	})
+}
+
+// firePluginTimer re-arms timer for its next slot and then runs this one. A run
+// that is still in progress when the next slot arrives causes that slot to be
+// skipped rather than overlapping it.
+func (h *Host) firePluginTimer(timer *pluginTimer, due time.Time) {
+	registry := h.timers
+	registry.mu.Lock()
+	if timer.stopped {
+		registry.mu.Unlock()
+		return
+	}
+	if nextRun, errNext := timer.next(due); errNext == nil {
+		h.armPluginTimerLocked(timer, nextRun)
+	}
+	if timer.running {
+		registry.mu.Unlock()
+		log.WithFields(pluginLogFields(timer.pluginID, "", "", "")).Warnf("pluginhost: timer %s skipped, previous run still in progress", timer.name)
+		return
+	}
+	timer.running = true
+	registry.mu.Unlock()
+	defer func() {
+		registry.mu.Lock()
+		timer.running = false
+		registry.mu.Unlock()
+	}()
+
+	if !h.claimPluginTimerSlot(timer, due) {
+		return
+	}
+	h.mu.Lock()
+	lp := h.loaded[timer.pluginID]
+	_, fused := h.fused[timer.pluginID]
+	h.mu.Unlock()
+	if lp == nil || lp.client == nil || fused {
+		return
+	}
+	if errRun := runPluginTimer(lp.client, timer, due); errRun != nil {
+		log.WithFields(pluginLogFields(timer.pluginID, lp.name, lp.version, lp.path)).Warnf("pluginhost: timer %s failed: %v", timer.name, errRun)
+	}
+}
+
+// claimPluginTimerSlot makes sure only one Home cluster node runs a slot.
+// Without Home every node runs its own timers.
+func (h *Host) claimPluginTimerSlot(timer *pluginTimer, due time.Time) bool {
+	client, homeMode, errClient := home.CurrentKVClient()
+	if !homeMode {
+		return true
+	}
+	if errClient != nil {
+		log.WithFields(pluginLogFields(timer.pluginID, "", "", "")).Warnf("pluginhost: timer %s skipped: %v", timer.name, errClient)
+		return false
+	}
+	key := fmt.Sprintf("%s%s:%s:%d", pluginTimerHomeKeyPrefix, timer.pluginID, timer.name, due.Unix())
+	ttl := max(timer.interval, time.Minute)
+	claimed, errClaim := client.KVSetNX(context.Background(), key, []byte(client.MembershipInstanceID()), ttl)
+	if errClaim != nil {
+		log.WithFields(pluginLogFields(timer.pluginID, "", "", "")).Warnf("pluginhost: timer %s skipped: %v", timer.name, errClaim)
+		return false
+	}
+	return claimed
+}
+
+func runPluginTimer(client pluginClient, timer *pluginTimer, due time.Time) (errRun error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			errRun = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	ctx, cancel := context.WithTimeout(withHostCallbackPluginID(context.Background(), timer.pluginID), pluginTimerCallTimeout)
+	defer cancel()
+	_, errCall := callPlugin[rpcEmptyResponse](ctx, client, timer.method, pluginapi.TimerFireRequest{
+		Name:        timer.name,
+		ScheduledAt: due,
+		Payload:     timer.payload,
+	})
+	return errCall
+}
+
+func (h *Host) callHostTimerSchedule(ctx context.Context, request []byte) ([]byte, error) {
+	pluginID, errCaller := h.hostStateCallerPluginID(ctx, "host.timer.schedule")
+	if errCaller != nil {
+		return nil, errCaller
+	}
+	var req pluginapi.HostTimerScheduleRequest
+	if errUnmarshal := json.Unmarshal(request, &req); errUnmarshal != nil {
+		return nil, fmt.Errorf("decode host timer schedule request: %w", errUnmarshal)
+	}
+	timer, errTimer := newPluginTimer(pluginID, req)
+	if errTimer != nil {
+		return nil, errTimer
+	}
+	nextRun, errSchedule := h.schedulePluginTimer(timer)
+	if errSchedule != nil {
+		return nil, errSchedule
+	}
+	return marshalRPCResult(pluginapi.HostTimerScheduleResponse{Name: timer.name, NextRun: nextRun})
+}
+
+func (h *Host) callHostTimerCancel(ctx context.Context, request []byte) ([]byte, error) {
+	pluginID, errCaller := h.hostStateCallerPluginID(ctx, "host.timer.cancel")
+	if errCaller != nil {
+		return nil, errCaller
+	}
+	var req pluginapi.HostTimerCancelRequest
+	if errUnmarshal := json.Unmarshal(request, &req); errUnmarshal != nil {
+		return nil, fmt.Errorf("decode host timer cancel request: %w", errUnmarshal)
+	}
+	canceled := h.timers.cancel(pluginID, strings.TrimSpace(req.Name))
+	return marshalRPCResult(pluginapi.HostTimerCancelResponse{Canceled: canceled})
+}
@@ -0,0 +1,160 @@
+package pluginhost
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginabi"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
+)
+
+type timerRecordingClient struct {
+	mu    sync.Mutex
+	fired []pluginapi.TimerFireRequest
+}
+
+func (c *timerRecordingClient) Call(_ context.Context, method string, request []byte) ([]byte, error) {
+	if method == pluginabi.MethodTimerFire {
+		var req pluginapi.TimerFireRequest
+		if errUnmarshal := json.Unmarshal(request, &req); errUnmarshal != nil {
+			return nil, errUnmarshal
+		}
+		c.mu.Lock()
+		c.fired = append(c.fired, req)
+		c.mu.Unlock()
+	}
+	return marshalRPCResult(rpcEmptyResponse{})
+}
+
+func (c *timerRecordingClient) Shutdown() {}
+
+func (c *timerRecordingClient) firedCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.fired)
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	base := time.Date(2026, time.March, 14, 10, 7, 30, 0, time.UTC) // Saturday
+	for _, tc := range []struct {
+		expression string
+		want       time.Time
+	}{
+		{expression: "* * * * *", want: time.Date(2026, time.March, 14, 10, 8, 0, 0, time.UTC)},
+		{expression: "*/15 * * * *", want: time.Date(2026, time.March, 14, 10, 15, 0, 0, time.UTC)},
+		{expression: "0 9 * * 1-5", want: time.Date(2026, time.March, 16, 9, 0, 0, 0, time.UTC)},
+		{expression: "30 2 1 * *", want: time.Date(2026, time.April, 1, 2, 30, 0, 0, time.UTC)},
+		{expression: "0 0 * * 7", want: time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		{expression: "0 0 20 * 0", want: time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)},
+	} {
+		schedule, errParse := parseCronSchedule(tc.expression)
+		if errParse != nil {
+			t.Fatalf("parseCronSchedule(%q) error = %v", tc.expression, errParse)
+		}
+		got, errNext := schedule.next(base)
+		if errNext != nil {
+			t.Fatalf("next(%q) error = %v", tc.expression, errNext)
+		}
+		if !got.Equal(tc.want) {
+			t.Fatalf("next(%q) = %s, want %s", tc.expression, got, tc.want)
+		}
+	}
+}
+
+func TestParseCronScheduleRejectsInvalidExpressions(t *testing.T) {
+	for _, expression := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "a * * * *", "0 0 30 2 *"} {
+		if _, errParse := parseCronSchedule(expression); errParse == nil {
+			t.Fatalf("parseCronSchedule(%q) succeeded", expression)
+		}
+	}
+}
+
+func TestNewPluginTimerValidatesRequest(t *testing.T) {
+	for _, req := range []pluginapi.HostTimerScheduleRequest{
+		{Interval: "1m"},
+		{Name: "sync"},
+		{Name: "sync", Interval: "1m", Cron: "* * * * *"},
+		{Name: "sync", Interval: "10ms"},
+		{Name: "sync", Interval: "1m", Method: "plugin.register"},
+	} {
+		if _, errTimer := newPluginTimer("alpha", req); errTimer == nil {
+			t.Fatalf("newPluginTimer(%#v) succeeded", req)
+		}
+	}
+	timer, errTimer := newPluginTimer("alpha", pluginapi.HostTimerScheduleRequest{Name: "sync", Interval: "1m"})
+	if errTimer != nil {
+		t.Fatalf("newPluginTimer() error = %v", errTimer)
+	}
+	if timer.method != pluginabi.MethodTimerFire {
+		t.Fatalf("method = %q, want %q", timer.method, pluginabi.MethodTimerFire)
+	}
+}
+
+func TestHostTimerCallbacksFireAndCancel(t *testing.T) {
+	host := New()
+	t.Cleanup(func() { host.timers.retain(nil) })
+	client := &timerRecordingClient{}
+	host.loaded["alpha"] = &loadedPlugin{id: "alpha", client: client}
+	ctx := withHostCallbackPluginID(context.Background(), "alpha")
+
+	rawResp, errCall := host.callFromPlugin(ctx, pluginabi.MethodHostTimerSchedule, []byte(`{"name":"sync","interval":"1s","payload":{"n":1}}`))
+	if errCall != nil {
+		t.Fatalf("schedule error = %v", errCall)
+	}
+	scheduled, errDecode := decodeRPCEnvelope[pluginapi.HostTimerScheduleResponse](rawResp)
+	if errDecode != nil || scheduled.Name != "sync" || scheduled.NextRun.IsZero() {
+		t.Fatalf("schedule response = %#v, %v", scheduled, errDecode)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for client.firedCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if client.firedCount() == 0 {
+		t.Fatal("timer did not fire")
+	}
+	client.mu.Lock()
+	fired := client.fired[0]
+	client.mu.Unlock()
+	if fired.Name != "sync" || string(fired.Payload) != `{"n":1}` || fired.ScheduledAt.IsZero() {
+		t.Fatalf("fired = %#v, want name, payload and slot", fired)
+	}
+
+	rawResp, errCall = host.callFromPlugin(ctx, pluginabi.MethodHostTimerCancel, []byte(`{"name":"sync"}`))
+	if errCall != nil {
+		t.Fatalf("cancel error = %v", errCall)
+	}
+	canceled, errDecode := decodeRPCEnvelope[pluginapi.HostTimerCancelResponse](rawResp)
+	if errDecode != nil || !canceled.Canceled {
+		t.Fatalf("cancel response = %#v, %v", canceled, errDecode)
+	}
+	if len(host.timers.byPlugin["alpha"]) != 0 {
+		t.Fatal("canceled timer is still registered")
+	}
+}
+
+func TestPluginTimerRegistryRetainStopsInactivePlugins(t *testing.T) {
+	host := New()
+	for _, pluginID := range []string{"alpha", "beta"} {
+		timer, errTimer := newPluginTimer(pluginID, pluginapi.HostTimerScheduleRequest{Name: "sync", Interval: "1h"})
+		if errTimer != nil {
+			t.Fatalf("newPluginTimer() error = %v", errTimer)
+		}
+		if _, errSchedule := host.schedulePluginTimer(timer); errSchedule != nil {
+			t.Fatalf("schedulePluginTimer() error = %v", errSchedule)
+		}
+	}
+	beta := host.timers.byPlugin["beta"]["sync"]
+
+	host.timers.retain(map[string]struct{}{"alpha": {}})
+	if _, ok := host.timers.byPlugin["alpha"]; !ok {
+		t.Fatal("active plugin timer was removed")
+	}
+	if _, ok := host.timers.byPlugin["beta"]; ok || !beta.stopped {
+		t.Fatal("inactive plugin timer was not stopped")
+	}
+	host.timers.retain(nil)
+}
@@ -0,0 +1,182 @@
+package pluginhost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/events"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginabi"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultRetiredPluginGrace lets requests that read the snapshot just before
+	// an upgrade swapped it reach the retired instance before it starts draining.
+	defaultRetiredPluginGrace = 2 * time.Second
+	pluginHealthTimeout       = 10 * time.Second
+)
+
+// rejectedPluginUpgrade remembers a plugin file that failed its upgrade so later
+// config reloads keep the running version instead of retrying the same file.
+type rejectedPluginUpgrade struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func statRejectedPluginUpgrade(path string) rejectedPluginUpgrade {
+	rejected := rejectedPluginUpgrade{path: cleanPluginPath(path)}
+	if info, errStat := os.Stat(path); errStat == nil {
+		rejected.size = info.Size()
+		rejected.modTime = info.ModTime()
+	}
+	return rejected
+}
+
+// upgradeRejectedLocked reports whether file is the unchanged file of an
+// upgrade that was already rolled back. Reinstalling it clears the mark.
+func (h *Host) upgradeRejectedLocked(file pluginFile) bool {
+	rejected, ok := h.rejectedUpgrades[file.ID]
+	if !ok {
+		return false
+	}
+	current := statRejectedPluginUpgrade(file.Path)
+	if rejected.path == current.path && rejected.size == current.size && rejected.modTime.Equal(current.modTime) {
+		return true
+	}
+	delete(h.rejectedUpgrades, file.ID)
+	return false
+}
+
+// verifyPluginUpgrade decides whether a freshly loaded version may replace the
+// running one: it must register and pass the optional plugin.health probe.
+func verifyPluginUpgrade(ctx context.Context, result pluginLoadResult) error {
+	if !result.initialized || result.loaded == nil {
+		return fmt.Errorf("plugin registration failed")
+	}
+	return checkPluginHealth(ctx, result.loaded.client)
+}
+
+func checkPluginHealth(ctx context.Context, client pluginClient) (errHealth error) {
+	if client == nil {
+		return fmt.Errorf("plugin client is unavailable")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, pluginHealthTimeout)
+	defer cancel()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			errHealth = fmt.Errorf("plugin health check panic: %v", recovered)
+		}
+	}()
+	raw, errCall := client.Call(ctx, pluginabi.MethodPluginHealth, []byte(`{}`))
+	if errCall != nil {
+		return fmt.Errorf("plugin health check: %w", errCall)
+	}
+	var envelope pluginabi.Envelope
+	if errUnmarshal := json.Unmarshal(raw, &envelope); errUnmarshal != nil {
+		return fmt.Errorf("decode plugin health check: %w", errUnmarshal)
+	}
+	if envelope.OK {
+		return nil
+	}
+	if envelope.Error == nil {
+		return fmt.Errorf("plugin health check failed")
+	}
+	switch strings.TrimSpace(envelope.Error.Code) {
+	case "unknown_method", "not_implemented":
+		return nil
+	}
+	message := strings.TrimSpace(envelope.Error.Message)
+	if message == "" {
+		message = strings.TrimSpace(envelope.Error.Code)
+	}
+	return fmt.Errorf("plugin health check failed: %s", message)
+}
+
+// rollbackPluginUpgrade keeps active serving after candidate failed to upgrade
+// it. fusedBefore restores the fuse state a panicking candidate may have set.
+func (h *Host) rollbackPluginUpgrade(active *loadedPlugin, candidate pluginFile, fusedBefore bool, reason error) {
+	if h == nil || active == nil {
+		return
+	}
+	h.mu.Lock()
+	h.rejectedUpgrades[candidate.ID] = statRejectedPluginUpgrade(candidate.Path)
+	if !fusedBefore {
+		delete(h.fused, candidate.ID)
+	}
+	h.mu.Unlock()
+
+	fields := pluginLogFields(active.id, active.name, active.version, active.path)
+	if version := strings.TrimSpace(candidate.Version); version != "" {
+		fields["failed_version"] = version
+	}
+	if path := strings.TrimSpace(candidate.Path); path != "" {
+		fields["failed_path"] = path
+	}
+	log.WithFields(fields).Warnf("pluginhost: plugin upgrade rolled back: %v", reason)
+	publishPluginRollbackEvent(active.id, active.version, active.path, candidate.Version, candidate.Path, reason.Error())
+}
+
+// drainRetiredPlugin unloads an instance replaced by an upgrade once the calls
+// and streams it is still serving have finished.
+func (h *Host) drainRetiredPlugin(lp *loadedPlugin, grace time.Duration) {
+	if h == nil || lp == nil {
+		return
+	}
+	if grace > 0 {
+		time.Sleep(grace)
+	}
+	shutdownPluginClient(context.Background(), lp.client)
+
+	h.mu.Lock()
+	removed := h.removeRetiredPluginLocked(lp)
+	h.mu.Unlock()
+	if !removed {
+		// UnloadPlugin or ShutdownAll already took this instance.
+		return
+	}
+	log.WithFields(pluginLogFields(lp.id, lp.name, lp.version, lp.path)).Info("pluginhost: retired plugin unloaded")
+	publishPluginEvent(events.TypePluginUnloaded, lp.id, lp.name, lp.version, lp.path, true)
+}
+
+func (h *Host) removeRetiredPluginLocked(lp *loadedPlugin) bool {
+	retired := h.retired[lp.id]
+	for index, candidate := range retired {
+		if candidate != lp {
+			continue
+		}
+		remaining := append(retired[:index:index], retired[index+1:]...)
+		if len(remaining) == 0 {
+			delete(h.retired, lp.id)
+		} else {
+			h.retired[lp.id] = remaining
+		}
+		return true
+	}
+	return false
+}
+
+// PluginRegisteredVersion returns the version of a plugin active in the current
+// runtime snapshot. It differs from the installed version after a rollback.
+func (h *Host) PluginRegisteredVersion(id string) (string, bool) {
+	if h == nil {
+		return "", false
+	}
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return "", false
+	}
+	for _, record := range h.activeRecords() {
+		if record.id == id {
+			return strings.TrimSpace(record.version), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,169 @@
+package pluginhost
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginabi"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
+)
+
+// versionedSymbolLoader hands out a separate test client per plugin version so
+// upgrades run two instances side by side.
+type versionedSymbolLoader struct {
+	openCalls int
+	lookups   map[string]*testSymbolLookup
+}
+
+func (l *versionedSymbolLoader) Open(file pluginFile, host *Host) (pluginClient, error) {
+	l.openCalls++
+	lookup := l.lookups[file.Version]
+	if lookup == nil {
+		return nil, fmt.Errorf("missing test plugin for %s", file.Path)
+	}
+	return lookup, nil
+}
+
+func applyPinnedPluginVersion(t *testing.T, h *Host, pluginsDir, version string) {
+	t.Helper()
+	h.ApplyConfig(context.Background(), &config.Config{
+		Plugins: config.PluginsConfig{
+			Enabled: true,
+			Dir:     pluginsDir,
+			Configs: map[string]config.PluginInstanceConfig{
+				"alpha": enabledPluginConfigWithStoreVersion(t, version),
+			},
+		},
+	})
+}
+
+func waitForHostCondition(t *testing.T, h *Host, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		h.mu.Lock()
+		done := condition()
+		h.mu.Unlock()
+		if done {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestHostApplyConfigRollsBackFailedUpgrade(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		prepare func(*testSymbolLookup)
+	}{
+		{
+			name: "health check",
+			prepare: func(lookup *testSymbolLookup) {
+				lookup.healthError = &pluginabi.Error{Code: "unhealthy", Message: "upstream unreachable"}
+			},
+		},
+		{
+			name: "registration",
+			prepare: func(lookup *testSymbolLookup) {
+				lookup.registerOverride = func([]byte) pluginapi.Plugin { return pluginapi.Plugin{} }
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			active := newTestSymbolLookup(&testPlugin{
+				registerResult:    validTestPlugin("alpha"),
+				reconfigureResult: validTestPlugin("alpha"),
+			})
+			candidate := newTestSymbolLookup(&testPlugin{registerResult: validTestPlugin("alpha")})
+			tc.prepare(candidate)
+			loader := &versionedSymbolLoader{lookups: map[string]*testSymbolLookup{"1.0.4": active, "1.0.5": candidate}}
+			h := NewForTest(loader)
+			t.Cleanup(h.ShutdownAll)
+			pluginsDir, paths := makeVersionedPluginDir(t, "alpha", "1.0.4")
+
+			applyPinnedPluginVersion(t, h, pluginsDir, "1.0.4")
+			paths["1.0.5"] = writeVersionedPluginFile(t, pluginsDir, "alpha", "1.0.5")
+			applyPinnedPluginVersion(t, h, pluginsDir, "1.0.5")
+
+			if !h.pluginIdentityCurrent("alpha", paths["1.0.4"], "1.0.4") {
+				t.Fatal("failed upgrade did not keep 1.0.4 active")
+			}
+			if version, ok := h.PluginRegisteredVersion("alpha"); !ok || version != "1.0.4" {
+				t.Fatalf("PluginRegisteredVersion(alpha) = %q, %v, want 1.0.4", version, ok)
+			}
+			waitForHostCondition(t, h, "candidate cleanup", func() bool {
+				_, loading := h.loading["alpha"]
+				return !loading
+			})
+			if candidate.shutdownCalls != 1 || active.shutdownCalls != 0 {
+				t.Fatalf("shutdown calls = candidate %d active %d, want 1/0", candidate.shutdownCalls, active.shutdownCalls)
+			}
+			if h.isPluginFused("alpha") {
+				t.Fatal("rolled back plugin is fused")
+			}
+
+			applyPinnedPluginVersion(t, h, pluginsDir, "1.0.5")
+			if loader.openCalls != 2 {
+				t.Fatalf("Open calls = %d, want the rejected file not to be retried", loader.openCalls)
+			}
+			if !h.pluginIdentityCurrent("alpha", paths["1.0.4"], "1.0.4") {
+				t.Fatal("1.0.4 is no longer active after retrying the rejected upgrade")
+			}
+		})
+	}
+}
+
+func TestHostApplyConfigDrainsRetiredPluginAfterLeases(t *testing.T) {
+	previous := newTestSymbolLookup(&testPlugin{registerResult: validTestPlugin("alpha")})
+	next := newTestSymbolLookup(&testPlugin{registerResult: validTestPlugin("alpha")})
+	loader := &versionedSymbolLoader{lookups: map[string]*testSymbolLookup{"1.0.4": previous, "1.0.5": next}}
+	h := NewForTest(loader)
+	h.retiredPluginGrace = 0
+	t.Cleanup(h.ShutdownAll)
+	pluginsDir, paths := makeVersionedPluginDir(t, "alpha", "1.0.4")
+
+	applyPinnedPluginVersion(t, h, pluginsDir, "1.0.4")
+	h.mu.Lock()
+	previousClient := h.loaded["alpha"].client
+	h.mu.Unlock()
+	// An in-flight stream on the old version holds a lease until it ends.
+	release := holdPluginClient(previousClient)
+
+	paths["1.0.5"] = writeVersionedPluginFile(t, pluginsDir, "alpha", "1.0.5")
+	applyPinnedPluginVersion(t, h, pluginsDir, "1.0.5")
+	if !h.pluginIdentityCurrent("alpha", paths["1.0.5"], "1.0.5") {
+		t.Fatal("upgrade did not route to 1.0.5")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	h.mu.Lock()
+	retired := len(h.retired["alpha"])
+	h.mu.Unlock()
+	if retired != 1 || previous.shutdownCalls != 0 {
+		t.Fatalf("retired = %d, shutdown calls = %d, want the old instance kept while leased", retired, previous.shutdownCalls)
+	}
+
+	release()
+	waitForHostCondition(t, h, "retired plugin drain", func() bool {
+		return len(h.retired["alpha"]) == 0
+	})
+	if previous.shutdownCalls != 1 || next.shutdownCalls != 0 {
+		t.Fatalf("shutdown calls = previous %d next %d, want 1/0", previous.shutdownCalls, next.shutdownCalls)
+	}
+}
+
+func TestCheckPluginHealthTreatsUnknownMethodAsHealthy(t *testing.T) {
+	lookup := newTestSymbolLookup(&testPlugin{})
+	lookup.healthError = &pluginabi.Error{Code: "unknown_method", Message: "unknown method"}
+	if errHealth := checkPluginHealth(context.Background(), lookup); errHealth != nil {
+		t.Fatalf("checkPluginHealth(unknown_method) error = %v", errHealth)
+	}
+	lookup.healthError = &pluginabi.Error{Code: "unhealthy", Message: "database offline"}
+	if errHealth := checkPluginHealth(context.Background(), lookup); errHealth == nil {
+		t.Fatal("checkPluginHealth(unhealthy) succeeded")
+	}
+}
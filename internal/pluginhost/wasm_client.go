@@ -0,0 +1,596 @@
+package pluginhost
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginabi"
+	log "github.com/sirupsen/logrus"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// wasmPluginExtension marks WASI modules that the host runs in a sandboxed
+// WebAssembly runtime. One module serves every GOOS/GOARCH.
+const wasmPluginExtension = ".wasm"
+
+const (
+	defaultWASMMemoryLimitBytes = 64 << 20
+	// defaultWASMMaxGuestCalls is the number of guest function calls one plugin
+	// call may make. It does not count instructions; the call timeout is what
+	// bounds CPU time.
+	defaultWASMMaxGuestCalls = 100_000_000
+	defaultWASMCallTimeout   = 30 * time.Second
+	maxWASMInstances         = 64
+	wasmPageSize             = 64 << 10
+	// wasmShutdownGrace bounds waiting for busy instances and plugin.shutdown.
+	wasmShutdownGrace = 5 * time.Second
+)
+
+// wasmHostCalls are the host callbacks a WASM plugin can be granted. Callbacks
+// that re-enter plugins or hold streams open are not available in the sandbox.
+var wasmHostCalls = []string{
+	pluginabi.MethodHostLog,
+	pluginabi.MethodHostHTTPDo,
+	pluginabi.MethodHostAuthList,
+	pluginabi.MethodHostAuthGet,
+	pluginabi.MethodHostAuthGetRuntime,
+	pluginabi.MethodHostAuthSave,
+	pluginabi.MethodHostKVGet,
+	pluginabi.MethodHostKVSet,
+	pluginabi.MethodHostKVDelete,
+	pluginabi.MethodHostKVList,
+	pluginabi.MethodHostTimerSchedule,
+	pluginabi.MethodHostTimerCancel,
+}
+
+// wasmOptions configures a WASM plugin from the plugins.configs.<id>.wasm block.
+type wasmOptions struct {
+	MemoryLimitBytes uint64
+	// MaxGuestCalls caps guest function calls per plugin call; zero disables
+	// the cap. Loops that make no calls are not counted and stop at CallTimeout.
+	MaxGuestCalls int64
+	CallTimeout   time.Duration
+	Instances     int
+	Capabilities  []string
+}
+
+type wasmOptionsYAML struct {
+	MemoryLimitMB int      `yaml:"memory-limit-mb"`
+	MaxGuestCalls int64    `yaml:"max-guest-calls"`
+	CallTimeout   string   `yaml:"call-timeout"`
+	Instances     int      `yaml:"instances"`
+	Capabilities  []string `yaml:"capabilities"`
+}
+
+func defaultWASMOptions() wasmOptions {
+	return wasmOptions{
+		MemoryLimitBytes: defaultWASMMemoryLimitBytes,
+		MaxGuestCalls:    defaultWASMMaxGuestCalls,
+		CallTimeout:      defaultWASMCallTimeout,
+		Instances:        1,
+		Capabilities:     []string{pluginabi.MethodHostLog},
+	}
+}
+
+// pluginWASMOptions reads the host-owned wasm block of a plugin config.
+// Invalid values fall back to the defaults with a warning.
+func pluginWASMOptions(id string, item config.PluginInstanceConfig) wasmOptions {
+	opts := defaultWASMOptions()
+	node := yamlMappingValue(&item.Raw, "wasm")
+	if node == nil || node.Kind == 0 {
+		return opts
+	}
+	var raw wasmOptionsYAML
+	if errDecode := node.Decode(&raw); errDecode != nil {
+		log.Warnf("pluginhost: invalid wasm config for plugin %s: %v", id, errDecode)
+		return opts
+	}
+	if raw.MemoryLimitMB > 0 {
+		opts.MemoryLimitBytes = uint64(raw.MemoryLimitMB) << 20
+	}
+	switch {
+	case raw.MaxGuestCalls > 0:
+		opts.MaxGuestCalls = raw.MaxGuestCalls
+	case raw.MaxGuestCalls < 0:
+		opts.MaxGuestCalls = 0
+	}
+	if timeout, ok := parseProcessDuration(id, "wasm call-timeout", raw.CallTimeout); ok {
+		opts.CallTimeout = timeout
+	}
+	if raw.Instances > 0 {
+		opts.Instances = min(raw.Instances, maxWASMInstances)
+	}
+	if raw.Capabilities != nil {
+		opts.Capabilities = opts.Capabilities[:0]
+		for _, capability := range raw.Capabilities {
+			capability = strings.TrimSpace(capability)
+			if !wasmCapabilityKnown(capability) {
+				log.Warnf("pluginhost: unknown wasm capability %q for plugin %s", capability, id)
+				continue
+			}
+			opts.Capabilities = append(opts.Capabilities, capability)
+		}
+	}
+	return opts
+}
+
+func wasmCapabilityKnown(capability string) bool {
+	for _, method := range wasmHostCalls {
+		if wasmCapabilityMatches(capability, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// wasmCapabilityMatches reports whether capability grants method. A trailing
+// ".*" grants every callback under that prefix, as in host.auth.*.
+func wasmCapabilityMatches(capability, method string) bool {
+	if prefix, ok := strings.CutSuffix(capability, "*"); ok {
+		return strings.HasPrefix(method, prefix)
+	}
+	return capability == method
+}
+
+// allowsHostCall reports whether the plugin was granted the host callback method.
+func (o wasmOptions) allowsHostCall(method string) bool {
+	known := false
+	for _, candidate := range wasmHostCalls {
+		if candidate == method {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return false
+	}
+	for _, capability := range o.Capabilities {
+		if wasmCapabilityMatches(capability, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWASMPluginFile(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), wasmPluginExtension)
+}
+
+// wasmOptionsFor returns the WASM settings configured for plugin id.
+func (h *Host) wasmOptionsFor(id string) wasmOptions {
+	item, ok := h.pluginInstanceConfig(id)
+	if !ok {
+		return defaultWASMOptions()
+	}
+	return pluginWASMOptions(id, item)
+}
+
+type wasmPluginLoader struct{}
+
+func (wasmPluginLoader) Open(file pluginFile, host *Host) (pluginClient, error) {
+	return startWASMPluginClient(file, host, host.wasmOptionsFor(file.ID))
+}
+
+// wasmPluginClient runs a WASI plugin module in a pure-Go WebAssembly runtime.
+// Calls are served by a small pool of module instances. An instance that traps,
+// runs out of guest calls or memory, or exceeds its call timeout is discarded
+// and replaced by a fresh instance registered with the last accepted configuration.
+type wasmPluginClient struct {
+	id   string
+	path string
+	host *Host
+	opts wasmOptions
+
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	stderr   *pluginStderrWriter
+	// slots holds one entry per instance; nil entries are instantiated on demand.
+	slots chan *wasmInstance
+
+	mu             sync.Mutex
+	register       []byte
+	registerMethod string
+	generation     uint64
+	crashes        int
+	closed         bool
+	stop           chan struct{}
+}
+
+type wasmInstance struct {
+	module api.Module
+	alloc  api.Function
+	free   api.Function
+	entry  api.Function
+	// generation is the configuration generation this instance was registered with.
+	generation uint64
+	// pending holds the response of the last host_call until host_result copies it.
+	pending []byte
+	broken  bool
+}
+
+type wasmInstanceContextKey struct{}
+
+type wasmCallBudgetContextKey struct{}
+
+// wasmCallBudget counts down guest function calls during one plugin call and
+// cancels the call, which terminates the instance, once it runs out. It is
+// charged through a function listener on every guest call, so it stops
+// call-heavy runaways early; a loop without calls runs until the call timeout.
+type wasmCallBudget struct {
+	remaining atomic.Int64
+	exhausted atomic.Bool
+	cancel    context.CancelFunc
+}
+
+type wasmCallBudgetListenerFactory struct{}
+
+func (wasmCallBudgetListenerFactory) NewFunctionListener(api.FunctionDefinition) experimental.FunctionListener {
+	return wasmCallBudgetListener{}
+}
+
+type wasmCallBudgetListener struct{}
+
+func (wasmCallBudgetListener) Before(ctx context.Context, _ api.Module, _ api.FunctionDefinition, _ []uint64, _ experimental.StackIterator) {
+	budget, _ := ctx.Value(wasmCallBudgetContextKey{}).(*wasmCallBudget)
+	if budget == nil {
+		return
+	}
+	if budget.remaining.Add(-1) < 0 && budget.exhausted.CompareAndSwap(false, true) {
+		budget.cancel()
+	}
+}
+
+func (wasmCallBudgetListener) After(context.Context, api.Module, api.FunctionDefinition, []uint64) {}
+
+func (wasmCallBudgetListener) Abort(context.Context, api.Module, api.FunctionDefinition, error) {}
+
+func startWASMPluginClient(file pluginFile, host *Host, opts wasmOptions) (*wasmPluginClient, error) {
+	binary, errRead := os.ReadFile(file.Path)
+	if errRead != nil {
+		return nil, fmt.Errorf("read wasm plugin %s: %w", file.Path, errRead)
+	}
+	client := &wasmPluginClient{
+		id:     file.ID,
+		path:   file.Path,
+		host:   host,
+		opts:   opts,
+		stderr: &pluginStderrWriter{id: file.ID},
+		slots:  make(chan *wasmInstance, max(opts.Instances, 1)),
+		stop:   make(chan struct{}),
+	}
+	pages := min(max(opts.MemoryLimitBytes/wasmPageSize, 1), 1<<16)
+	ctx := context.Background()
+	client.runtime = wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(pages)).
+		WithCloseOnContextDone(true))
+	if _, errWASI := wasi_snapshot_preview1.Instantiate(ctx, client.runtime); errWASI != nil {
+		_ = client.runtime.Close(ctx)
+		return nil, fmt.Errorf("instantiate wasi: %w", errWASI)
+	}
+	_, errHost := client.runtime.NewHostModuleBuilder(pluginabi.WASMHostModule).
+		NewFunctionBuilder().WithFunc(client.hostCall).Export(pluginabi.WASMHostCall).
+		NewFunctionBuilder().WithFunc(client.hostResult).Export(pluginabi.WASMHostResult).
+		Instantiate(ctx)
+	if errHost != nil {
+		_ = client.runtime.Close(ctx)
+		return nil, fmt.Errorf("instantiate wasm host module: %w", errHost)
+	}
+	compileCtx := ctx
+	if opts.MaxGuestCalls > 0 {
+		compileCtx = experimental.WithFunctionListenerFactory(ctx, wasmCallBudgetListenerFactory{})
+	}
+	compiled, errCompile := client.runtime.CompileModule(compileCtx, binary)
+	if errCompile != nil {
+		_ = client.runtime.Close(ctx)
+		return nil, fmt.Errorf("compile wasm plugin %s: %w", file.Path, errCompile)
+	}
+	client.compiled = compiled
+
+	// Instantiate one instance up front so a broken module fails the load.
+	first, errInstantiate := client.instantiate()
+	if errInstantiate != nil {
+		_ = client.runtime.Close(ctx)
+		return nil, errInstantiate
+	}
+	client.slots <- first
+	for range cap(client.slots) - 1 {
+		client.slots <- nil
+	}
+	return client, nil
+}
+
+func (c *wasmPluginClient) Call(ctx context.Context, method string, request []byte) ([]byte, error) {
+	if c == nil {
+		return nil, fmt.Errorf("plugin client is closed")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if len(request) > 0 && !json.Valid(request) {
+		return nil, fmt.Errorf("%s payload is not valid JSON", method)
+	}
+	inst, errAcquire := c.acquire(ctx)
+	if errAcquire != nil {
+		return nil, errAcquire
+	}
+	defer c.release(inst)
+
+	response, errCall := c.invoke(ctx, inst, method, request)
+	if errCall != nil {
+		return nil, errCall
+	}
+	if (method == pluginabi.MethodPluginRegister || method == pluginabi.MethodPluginReconfigure) && !isPluginErrorEnvelope(response) {
+		c.mu.Lock()
+		c.register = append([]byte(nil), request...)
+		c.registerMethod = method
+		c.generation++
+		inst.generation = c.generation
+		c.mu.Unlock()
+	}
+	return response, nil
+}
+
+func (c *wasmPluginClient) Shutdown() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	close(c.stop)
+	c.mu.Unlock()
+
+	grace := time.NewTimer(wasmShutdownGrace)
+	defer grace.Stop()
+collect:
+	for range cap(c.slots) {
+		select {
+		case inst := <-c.slots:
+			if inst == nil || inst.broken {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), wasmShutdownGrace)
+			_, _ = c.invoke(ctx, inst, pluginabi.MethodPluginShutdown, nil)
+			cancel()
+		case <-grace.C:
+			break collect
+		}
+	}
+	if errClose := c.runtime.Close(context.Background()); errClose != nil {
+		log.WithFields(pluginLogFields(c.id, "", "", c.path)).Debugf("pluginhost: close wasm runtime: %v", errClose)
+	}
+}
+
+// acquire takes an idle instance, creating it when needed, and brings it up to
+// date with the latest plugin configuration.
+func (c *wasmPluginClient) acquire(ctx context.Context) (*wasmInstance, error) {
+	var inst *wasmInstance
+	select {
+	case inst = <-c.slots:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.stop:
+		return nil, fmt.Errorf("plugin client is closed")
+	}
+	c.mu.Lock()
+	closed := c.closed
+	register, registerMethod, generation := c.register, c.registerMethod, c.generation
+	c.mu.Unlock()
+	if closed {
+		c.release(inst)
+		return nil, fmt.Errorf("plugin client is closed")
+	}
+	if inst == nil {
+		var errInstantiate error
+		inst, errInstantiate = c.instantiate()
+		if errInstantiate != nil {
+			c.slots <- nil
+			return nil, errInstantiate
+		}
+	}
+	if register == nil || inst.generation == generation {
+		return inst, nil
+	}
+	// Fresh instances are registered; live ones missed a reconfigure.
+	method := registerMethod
+	if inst.generation == 0 {
+		method = pluginabi.MethodPluginRegister
+	}
+	response, errRegister := c.invoke(ctx, inst, method, register)
+	if errRegister == nil && isPluginErrorEnvelope(response) {
+		errRegister = fmt.Errorf("plugin rejected its current configuration")
+	}
+	if errRegister != nil {
+		inst.broken = true
+		c.release(inst)
+		return nil, fmt.Errorf("configure wasm plugin instance: %w", errRegister)
+	}
+	inst.generation = generation
+	return inst, nil
+}
+
+func (c *wasmPluginClient) release(inst *wasmInstance) {
+	if inst != nil && inst.broken {
+		_ = inst.module.Close(context.Background())
+		inst = nil
+	}
+	c.slots <- inst
+}
+
+func (c *wasmPluginClient) instantiate() (*wasmInstance, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.opts.CallTimeout)
+	defer cancel()
+	module, errInstantiate := c.runtime.InstantiateModule(ctx, c.compiled, wazero.NewModuleConfig().
+		WithName("").
+		WithStartFunctions(pluginabi.WASMExportInitialize).
+		WithStdout(c.stderr).
+		WithStderr(c.stderr).
+		WithEnv(pluginabi.EnvPluginID, c.id).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithRandSource(rand.Reader))
+	if errInstantiate != nil {
+		return nil, fmt.Errorf("instantiate wasm plugin %s: %w", c.path, errInstantiate)
+	}
+	inst := &wasmInstance{
+		module: module,
+		alloc:  module.ExportedFunction(pluginabi.WASMExportAlloc),
+		free:   module.ExportedFunction(pluginabi.WASMExportFree),
+		entry:  module.ExportedFunction(pluginabi.WASMExportCall),
+	}
+	if inst.alloc == nil || inst.entry == nil || module.Memory() == nil {
+		_ = module.Close(ctx)
+		return nil, fmt.Errorf("wasm plugin %s must export memory, %s and %s", c.path, pluginabi.WASMExportAlloc, pluginabi.WASMExportCall)
+	}
+	return inst, nil
+}
+
+// invoke runs one plugin call under the guest call and time limits. Any failure
+// inside the module marks the instance broken so it is replaced.
+func (c *wasmPluginClient) invoke(ctx context.Context, inst *wasmInstance, method string, request []byte) ([]byte, error) {
+	callCtx, cancel := context.WithTimeout(ctx, c.opts.CallTimeout)
+	defer cancel()
+	var budget *wasmCallBudget
+	if c.opts.MaxGuestCalls > 0 {
+		budget = &wasmCallBudget{cancel: cancel}
+		budget.remaining.Store(c.opts.MaxGuestCalls)
+		callCtx = context.WithValue(callCtx, wasmCallBudgetContextKey{}, budget)
+	}
+	callCtx = context.WithValue(callCtx, wasmInstanceContextKey{}, inst)
+
+	response, errCall := inst.call(callCtx, method, request)
+	if errCall == nil {
+		return response, nil
+	}
+	inst.broken = true
+	var reason string
+	switch {
+	case budget != nil && budget.exhausted.Load():
+		reason = fmt.Sprintf("limit of %d guest calls exhausted", c.opts.MaxGuestCalls)
+	case ctx.Err() != nil:
+		return nil, fmt.Errorf("plugin call %s: %w", method, ctx.Err())
+	case errors.Is(callCtx.Err(), context.DeadlineExceeded):
+		reason = fmt.Sprintf("call exceeded %s", c.opts.CallTimeout)
+	default:
+		reason = errCall.Error()
+	}
+	c.mu.Lock()
+	c.crashes++
+	crashes := c.crashes
+	c.mu.Unlock()
+	log.WithFields(pluginLogFields(c.id, "", "", c.path)).Warnf("pluginhost: wasm plugin instance stopped during %s (%s), replacing it", method, reason)
+	publishPluginCrashEvent(c.id, c.path, reason, crashes, 0)
+	return nil, fmt.Errorf("wasm plugin %s failed during %s: %s", c.id, method, reason)
+}
+
+// call copies method and request into guest memory and runs cliproxy_call.
+func (i *wasmInstance) call(ctx context.Context, method string, request []byte) ([]byte, error) {
+	methodPtr, errMethod := i.write(ctx, []byte(method))
+	if errMethod != nil {
+		return nil, errMethod
+	}
+	requestPtr, errRequest := i.write(ctx, request)
+	if errRequest != nil {
+		return nil, errRequest
+	}
+	results, errCall := i.entry.Call(ctx, uint64(methodPtr), uint64(len(method)), uint64(requestPtr), uint64(len(request)))
+	if errCall != nil {
+		return nil, errCall
+	}
+	i.release(ctx, methodPtr, uint32(len(method)))
+	i.release(ctx, requestPtr, uint32(len(request)))
+	if len(results) != 1 {
+		return nil, fmt.Errorf("%s returned %d values", pluginabi.WASMExportCall, len(results))
+	}
+	ptr, size := pluginabi.UnpackWASMResult(results[0])
+	if size == 0 {
+		return nil, nil
+	}
+	raw, ok := i.module.Memory().Read(ptr, size)
+	if !ok {
+		return nil, fmt.Errorf("%s returned a buffer outside guest memory", method)
+	}
+	response := append([]byte(nil), raw...)
+	i.release(ctx, ptr, size)
+	if !json.Valid(response) {
+		return nil, fmt.Errorf("%s returned invalid JSON", method)
+	}
+	return response, nil
+}
+
+func (i *wasmInstance) write(ctx context.Context, data []byte) (uint32, error) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+	results, errAlloc := i.alloc.Call(ctx, uint64(len(data)))
+	if errAlloc != nil {
+		return 0, fmt.Errorf("%s: %w", pluginabi.WASMExportAlloc, errAlloc)
+	}
+	ptr := uint32(results[0])
+	if !i.module.Memory().Write(ptr, data) {
+		return 0, fmt.Errorf("%s returned a buffer outside guest memory", pluginabi.WASMExportAlloc)
+	}
+	return ptr, nil
+}
+
+func (i *wasmInstance) release(ctx context.Context, ptr, size uint32) {
+	if i.free == nil || size == 0 {
+		return
+	}
+	_, _ = i.free.Call(ctx, uint64(ptr), uint64(size))
+}
+
+// hostCall implements the host_call import. The response stays on the host
+// until the guest copies it with host_result.
+func (c *wasmPluginClient) hostCall(ctx context.Context, module api.Module, methodPtr, methodLen, payloadPtr, payloadLen uint32) int32 {
+	inst, _ := ctx.Value(wasmInstanceContextKey{}).(*wasmInstance)
+	if inst == nil {
+		return -1
+	}
+	method, okMethod := module.Memory().Read(methodPtr, methodLen)
+	payload, okPayload := module.Memory().Read(payloadPtr, payloadLen)
+	if !okMethod || !okPayload {
+		return -1
+	}
+	inst.pending = c.handleHostCall(ctx, string(method), append([]byte(nil), payload...))
+	return int32(len(inst.pending))
+}
+
+// hostResult implements the host_result import.
+func (c *wasmPluginClient) hostResult(ctx context.Context, module api.Module, ptr uint32) {
+	inst, _ := ctx.Value(wasmInstanceContextKey{}).(*wasmInstance)
+	if inst == nil {
+		return
+	}
+	module.Memory().Write(ptr, inst.pending)
+	inst.pending = nil
+}
+
+func (c *wasmPluginClient) handleHostCall(ctx context.Context, method string, payload []byte) []byte {
+	if !c.opts.allowsHostCall(method) {
+		return marshalRPCError("capability_denied", fmt.Sprintf("wasm plugin %s is not granted %s", c.id, method))
+	}
+	if c.host == nil {
+		return marshalRPCError("host_unavailable", "plugin host is unavailable")
+	}
+	response, errCall := c.host.callFromPlugin(withHostCallbackPluginID(ctx, c.id), method, payload)
+	if errCall != nil {
+		return marshalRPCError("host_call_failed", errCall.Error())
+	}
+	return response
+}
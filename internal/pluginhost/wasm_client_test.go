@@ -0,0 +1,219 @@
+package pluginhost
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginabi"
+	"gopkg.in/yaml.v3"
+)
+
+// buildWASMGuest compiles testdata/wasmguest into a WASI reactor module.
+func buildWASMGuest(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("building the wasm test plugin is slow")
+	}
+	goTool, errLook := exec.LookPath("go")
+	if errLook != nil {
+		t.Skip("go toolchain not available")
+	}
+	output := filepath.Join(t.TempDir(), "wasmguest.wasm")
+	cmd := exec.Command(goTool, "build", "-buildmode=c-shared", "-o", output, "./testdata/wasmguest")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm", "CGO_ENABLED=0")
+	if out, errBuild := cmd.CombinedOutput(); errBuild != nil {
+		t.Fatalf("build wasm guest: %v\n%s", errBuild, out)
+	}
+	return output
+}
+
+type wasmGuestState struct {
+	Registered string `json:"registered"`
+	Calls      int    `json:"calls"`
+	PluginID   string `json:"plugin_id"`
+}
+
+func wasmGuestStateOf(t *testing.T, client *wasmPluginClient) wasmGuestState {
+	t.Helper()
+	raw, errCall := client.Call(context.Background(), "test.state", nil)
+	if errCall != nil {
+		t.Fatalf("test.state error = %v", errCall)
+	}
+	var state wasmGuestState
+	if errUnmarshal := json.Unmarshal(raw, &state); errUnmarshal != nil {
+		t.Fatalf("decode state %s: %v", raw, errUnmarshal)
+	}
+	return state
+}
+
+func TestWASMPluginClient(t *testing.T) {
+	path := buildWASMGuest(t)
+	start := func(t *testing.T, opts wasmOptions) *wasmPluginClient {
+		t.Helper()
+		client, errStart := startWASMPluginClient(pluginFile{ID: "wasm-test", Path: path}, New(), opts)
+		if errStart != nil {
+			t.Fatalf("startWASMPluginClient() error = %v", errStart)
+		}
+		t.Cleanup(client.Shutdown)
+		return client
+	}
+	ctx := context.Background()
+
+	t.Run("calls and capabilities", func(t *testing.T) {
+		client := start(t, defaultWASMOptions())
+		if raw, errCall := client.Call(ctx, "test.echo", []byte(`{"hello":"wasm"}`)); errCall != nil || string(raw) != `{"hello":"wasm"}` {
+			t.Fatalf("test.echo = %s, %v", raw, errCall)
+		}
+		if state := wasmGuestStateOf(t, client); state.PluginID != "wasm-test" {
+			t.Fatalf("state = %+v, want the plugin id in the environment", state)
+		}
+		raw, errCall := client.Call(ctx, "test.host_call", []byte(`{"method":"host.log","payload":{"level":"debug","message":"from wasm plugin"}}`))
+		if errCall != nil || !strings.Contains(string(raw), `"ok":true`) {
+			t.Fatalf("granted host.log = %s, %v", raw, errCall)
+		}
+		raw, errCall = client.Call(ctx, "test.host_call", []byte(`{"method":"host.http.do","payload":{"method":"GET","url":"http://127.0.0.1:1/"}}`))
+		if errCall != nil || !strings.Contains(string(raw), "capability_denied") {
+			t.Fatalf("ungranted host.http.do = %s, %v", raw, errCall)
+		}
+		raw, errCall = client.Call(ctx, "test.panic", nil)
+		if errCall != nil || !strings.Contains(string(raw), "plugin_panic") {
+			t.Fatalf("test.panic = %s, %v, want an error envelope", raw, errCall)
+		}
+	})
+
+	t.Run("guest call limit replaces the instance", func(t *testing.T) {
+		opts := defaultWASMOptions()
+		opts.MaxGuestCalls = 100_000
+		client := start(t, opts)
+		if _, errRegister := client.Call(ctx, pluginabi.MethodPluginRegister, []byte(`{"config_yaml":"ZW5hYmxlZDogdHJ1ZQo="}`)); errRegister != nil {
+			t.Fatalf("register error = %v", errRegister)
+		}
+		wasmGuestStateOf(t, client)
+		if _, errSpin := client.Call(ctx, "test.spin", nil); errSpin == nil || !strings.Contains(errSpin.Error(), "guest calls exhausted") {
+			t.Fatalf("test.spin error = %v, want the guest call limit", errSpin)
+		}
+		state := wasmGuestStateOf(t, client)
+		if state.Calls != 2 || state.Registered != `{"config_yaml":"ZW5hYmxlZDogdHJ1ZQo="}` {
+			t.Fatalf("state after the guest call limit = %+v, want a fresh instance registered with the previous config", state)
+		}
+	})
+
+	t.Run("call-free loop is bounded by the timeout, not the guest call limit", func(t *testing.T) {
+		opts := defaultWASMOptions()
+		opts.MaxGuestCalls = 100_000
+		opts.CallTimeout = 500 * time.Millisecond
+		client := start(t, opts)
+		started := time.Now()
+		_, errBusy := client.Call(ctx, "test.busy", nil)
+		if errBusy == nil || strings.Contains(errBusy.Error(), "guest calls exhausted") || !strings.Contains(errBusy.Error(), "exceeded") {
+			t.Fatalf("test.busy error = %v, want the call timeout", errBusy)
+		}
+		if elapsed := time.Since(started); elapsed < opts.CallTimeout {
+			t.Fatalf("test.busy stopped after %s, before the call timeout", elapsed)
+		}
+		if raw, errCall := client.Call(ctx, "test.echo", []byte(`{}`)); errCall != nil || string(raw) != `{}` {
+			t.Fatalf("test.echo after timeout = %s, %v", raw, errCall)
+		}
+	})
+
+	t.Run("memory limit and timeout", func(t *testing.T) {
+		opts := defaultWASMOptions()
+		opts.MaxGuestCalls = 0
+		opts.CallTimeout = 500 * time.Millisecond
+		client := start(t, opts)
+		if _, errHoard := client.Call(ctx, "test.hoard", nil); errHoard == nil {
+			t.Fatal("test.hoard succeeded past the memory limit")
+		}
+		if _, errSpin := client.Call(ctx, "test.spin", nil); errSpin == nil || !strings.Contains(errSpin.Error(), "exceeded") {
+			t.Fatalf("test.spin error = %v, want the call timeout", errSpin)
+		}
+		if raw, errCall := client.Call(ctx, "test.echo", []byte(`{}`)); errCall != nil || string(raw) != `{}` {
+			t.Fatalf("test.echo after failures = %s, %v", raw, errCall)
+		}
+	})
+
+	t.Run("instances share configuration", func(t *testing.T) {
+		opts := defaultWASMOptions()
+		opts.Instances = 2
+		client := start(t, opts)
+		if _, errRegister := client.Call(ctx, pluginabi.MethodPluginRegister, []byte(`{"v":1}`)); errRegister != nil {
+			t.Fatalf("register error = %v", errRegister)
+		}
+		if _, errReconfigure := client.Call(ctx, pluginabi.MethodPluginReconfigure, []byte(`{"v":2}`)); errReconfigure != nil {
+			t.Fatalf("reconfigure error = %v", errReconfigure)
+		}
+		// Idle instances are used in turn, so both are checked.
+		for range opts.Instances {
+			if state := wasmGuestStateOf(t, client); state.Registered != `{"v":2}` {
+				t.Fatalf("instance registered with %q, want the latest config", state.Registered)
+			}
+		}
+	})
+
+	client := start(t, defaultWASMOptions())
+	client.Shutdown()
+	if _, errCall := client.Call(ctx, "test.echo", []byte(`{}`)); errCall == nil {
+		t.Fatal("call after Shutdown succeeded")
+	}
+}
+
+func TestPluginWASMOptionsFromConfig(t *testing.T) {
+	var item config.PluginInstanceConfig
+	if errUnmarshal := yaml.Unmarshal([]byte(`
+enabled: true
+wasm:
+  memory-limit-mb: 32
+  max-guest-calls: -1
+  call-timeout: 5s
+  instances: 500
+  capabilities: ["host.http.do", "host.auth.*", "host.model.execute"]
+`), &item); errUnmarshal != nil {
+		t.Fatalf("unmarshal: %v", errUnmarshal)
+	}
+	opts := pluginWASMOptions("sample", item)
+	if opts.MemoryLimitBytes != 32<<20 || opts.MaxGuestCalls != 0 || opts.CallTimeout != 5*time.Second || opts.Instances != maxWASMInstances {
+		t.Fatalf("opts = %+v", opts)
+	}
+	for method, want := range map[string]bool{
+		pluginabi.MethodHostHTTPDo:          true,
+		pluginabi.MethodHostAuthSave:        true,
+		pluginabi.MethodHostLog:             false,
+		pluginabi.MethodHostModelExecute:    false,
+		pluginabi.MethodHostHTTPDoStream:    false,
+		pluginabi.MethodHostStreamEmit:      false,
+		pluginabi.MethodHostAuthGetRuntime:  true,
+		pluginabi.MethodHostModelStreamRead: false,
+	} {
+		if got := opts.allowsHostCall(method); got != want {
+			t.Fatalf("allowsHostCall(%s) = %v, want %v", method, got, want)
+		}
+	}
+
+	defaults := pluginWASMOptions("sample", config.PluginInstanceConfig{})
+	if !defaults.allowsHostCall(pluginabi.MethodHostLog) || defaults.allowsHostCall(pluginabi.MethodHostHTTPDo) || defaults.MaxGuestCalls != defaultWASMMaxGuestCalls {
+		t.Fatalf("default opts = %+v", defaults)
+	}
+}
+
+func TestSelectPluginFilesIncludesWASMPlugins(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"beta-v1.0.0.wasm", "beta-v0.9.0.wasm"} {
+		if errWrite := os.WriteFile(filepath.Join(root, name), []byte("\x00asm"), 0o644); errWrite != nil {
+			t.Fatalf("write %s: %v", name, errWrite)
+		}
+	}
+	files, errSelect := selectPluginFiles(root)
+	if errSelect != nil {
+		t.Fatalf("selectPluginFiles() error = %v", errSelect)
+	}
+	if len(files) != 1 || files[0].ID != "beta" || files[0].Version != "1.0.0" || !isWASMPluginFile(files[0].Path) {
+		t.Fatalf("files = %+v, want the newest beta wasm plugin", files)
+	}
+}
@@ -16,7 +16,13 @@ func SelectArtifact(plan InstallPlan, goos string, goarch string) (Artifact, err
 		return Artifact{}, fmt.Errorf("install type %q is not direct", plan.Type)
 	}
 	for _, artifact := range plan.Artifacts {
-		if artifact.GOOS == goos && artifact.GOARCH == goarch {
+		if artifact.Type == "" && artifact.GOOS == goos && artifact.GOARCH == goarch {
+			return artifact, nil
+		}
+	}
+	// A portable WASM build serves platforms without a native artifact.
+	for _, artifact := range plan.Artifacts {
+		if artifact.Type == ArtifactTypeWASM {
 			return artifact, nil
 		}
 	}
@@ -54,3 +60,18 @@ func VerifyArtifactChecksum(artifact Artifact, data []byte) error {
 	}
 	return nil
 }
+
+func (c Client) verifyDirectSignature(ctx context.Context, artifact Artifact, data []byte) (SignatureVerification, error) {
+	if c.Trust == nil {
+		return SignatureVerification{}, nil
+	}
+	var signatureData []byte
+	if signatureURL := strings.TrimSpace(artifact.SignatureURL); signatureURL != "" {
+		var errDownload error
+		signatureData, errDownload = c.get(ctx, signatureURL, "application/octet-stream", RequestKindArtifact, maxSignatureFileSize)
+		if errDownload != nil {
+			return SignatureVerification{}, fmt.Errorf("download artifact signature: %w", errDownload)
+		}
+	}
+	return c.verifyArtifactSignature("artifact", data, signatureData)
+}
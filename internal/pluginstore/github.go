@@ -28,6 +28,9 @@ type Client struct {
 	Auth                  []AuthConfig
 	ResolvedAuth          []ResolvedAuthConfig
 	ResolvedAuthExpiresAt time.Time
+	// Trust enforces publisher signatures on installed artifacts. Nil skips
+	// signature checks.
+	Trust *TrustPolicy
 }
 
 type Release struct {
@@ -324,6 +327,32 @@ func SelectReleaseAssets(release Release, id, version, goos, goarch string) (Rel
 	return archiveAsset, checksumAsset, nil
 }
 
+// SelectSignatureAsset returns the detached signature published for asset.
+func SelectSignatureAsset(release Release, asset ReleaseAsset) (ReleaseAsset, bool) {
+	signatureName := strings.TrimSpace(asset.Name) + SignatureExtension
+	for _, candidate := range release.Assets {
+		if strings.TrimSpace(candidate.Name) == signatureName {
+			return candidate, true
+		}
+	}
+	return ReleaseAsset{}, false
+}
+
+func (c Client) verifyReleaseSignature(ctx context.Context, release Release, asset ReleaseAsset, data []byte) (SignatureVerification, error) {
+	if c.Trust == nil {
+		return SignatureVerification{}, nil
+	}
+	var signatureData []byte
+	if signatureAsset, okSignature := SelectSignatureAsset(release, asset); okSignature {
+		var errDownload error
+		signatureData, errDownload = c.DownloadAsset(ctx, signatureAsset)
+		if errDownload != nil {
+			return SignatureVerification{}, fmt.Errorf("download %s: %w", signatureAsset.Name, errDownload)
+		}
+	}
+	return c.verifyArtifactSignature(asset.Name, data, signatureData)
+}
+
 func ArchiveName(id, version, goos, goarch string) string {
 	return fmt.Sprintf(
 		"%s_%s_%s_%s.zip",
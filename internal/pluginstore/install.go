@@ -42,6 +42,10 @@ type InstallResult struct {
 	Path        string `json:"path"`
 	Overwritten bool   `json:"overwritten"`
 	Skipped     bool   `json:"skipped"`
+	// Signature is the SignatureStatus* outcome when the client enforces trust.
+	Signature   string `json:"signature,omitempty"`
+	Signer      string `json:"signer,omitempty"`
+	SignerKeyID string `json:"signer_key_id,omitempty"`
 }
 
 func (c Client) Install(ctx context.Context, plugin Plugin, options InstallOptions) (InstallResult, error) {
@@ -114,9 +118,16 @@ func (c Client) InstallVersion(ctx context.Context, plugin Plugin, releaseTag st
 }
 
 func (c Client) installRelease(ctx context.Context, plugin Plugin, release Release, version string, options InstallOptions) (InstallResult, error) {
+	install := InstallArchive
 	archiveAsset, checksumAsset, errAssets := SelectReleaseAssets(release, plugin.ID, plugin.Version, options.GOOS, options.GOARCH)
 	if errAssets != nil {
-		return InstallResult{}, errAssets
+		// A portable WASM build serves platforms without a native archive.
+		var errWASM error
+		archiveAsset, checksumAsset, errWASM = SelectReleaseAssets(release, plugin.ID, plugin.Version, WASMGOOS, WASMGOARCH)
+		if errWASM != nil {
+			return InstallResult{}, errAssets
+		}
+		install = InstallWASM
 	}
 	archiveData, errArchive := c.DownloadAsset(ctx, archiveAsset)
 	if errArchive != nil {
@@ -133,11 +144,16 @@ func (c Client) installRelease(ctx context.Context, plugin Plugin, release Relea
 	if errVerify := VerifyChecksum(archiveAsset.Name, archiveData, checksums); errVerify != nil {
 		return InstallResult{}, errVerify
 	}
+	verification, errSignature := c.verifyReleaseSignature(ctx, release, archiveAsset, archiveData)
+	if errSignature != nil {
+		return InstallResult{}, errSignature
+	}
 	plugin.Version = version
-	result, errInstall := InstallArchive(archiveData, plugin, options)
+	result, errInstall := install(archiveData, plugin, options)
 	if errInstall != nil {
 		return InstallResult{}, errInstall
 	}
+	applySignatureVerification(&result, verification)
 	result.InstallType = InstallTypeGitHubRelease
 	result.ReleaseTag = strings.TrimSpace(release.TagName)
 	return result, nil
@@ -169,10 +185,19 @@ func (c Client) InstallDirect(ctx context.Context, plugin Plugin, plan InstallPl
 	if errVerify := VerifyArtifactChecksum(artifact, archiveData); errVerify != nil {
 		return InstallResult{}, errVerify
 	}
-	result, errInstall := InstallArchive(archiveData, plugin, options)
+	verification, errSignature := c.verifyDirectSignature(ctx, artifact, archiveData)
+	if errSignature != nil {
+		return InstallResult{}, errSignature
+	}
+	install := InstallArchive
+	if artifact.Type == ArtifactTypeWASM {
+		install = InstallWASM
+	}
+	result, errInstall := install(archiveData, plugin, options)
 	if errInstall != nil {
 		return InstallResult{}, errInstall
 	}
+	applySignatureVerification(&result, verification)
 	result.InstallType = InstallTypeDirect
 	return result, nil
 }
@@ -242,26 +267,57 @@ func directPluginVersion(plugin Plugin, id string, version string) (Plugin, erro
 
 func InstallArchive(archiveData []byte, plugin Plugin, options InstallOptions) (InstallResult, error) {
 	options = normalizeInstallOptions(options)
+	return installArchive(archiveData, plugin, options, pluginExtension(options.GOOS))
+}
+
+// InstallWASM installs a WASM plugin module delivered either as a bare .wasm
+// file or as a zip archive containing it. WASM modules run on every platform,
+// so the module is installed where the host for options.GOOS/GOARCH loads it.
+func InstallWASM(data []byte, plugin Plugin, options InstallOptions) (InstallResult, error) {
+	options = normalizeInstallOptions(options)
+	// Modules are read into memory when loaded, so they never lock the file.
+	options.PluginLoaded = nil
+	if !bytes.HasPrefix(data, wasmMagic) {
+		return installArchive(data, plugin, options, WASMExtension)
+	}
+	id, version, errPlugin := installPluginIdentity(plugin)
+	if errPlugin != nil {
+		return InstallResult{}, errPlugin
+	}
+	return installLibrary(data, 0o644, id, version, options, WASMExtension)
+}
+
+func installPluginIdentity(plugin Plugin) (string, string, error) {
 	id := strings.TrimSpace(plugin.ID)
 	if !validPluginID(id) {
-		return InstallResult{}, fmt.Errorf("invalid plugin id %q", plugin.ID)
+		return "", "", fmt.Errorf("invalid plugin id %q", plugin.ID)
 	}
 	version := normalizeVersion(plugin.Version)
 	if !validPluginVersion(version) {
-		return InstallResult{}, fmt.Errorf("invalid plugin version %q", plugin.Version)
+		return "", "", fmt.Errorf("invalid plugin version %q", plugin.Version)
+	}
+	return id, version, nil
+}
+
+func installArchive(archiveData []byte, plugin Plugin, options InstallOptions, extension string) (InstallResult, error) {
+	id, version, errPlugin := installPluginIdentity(plugin)
+	if errPlugin != nil {
+		return InstallResult{}, errPlugin
 	}
-	plugin.Version = version
 	reader, errZip := zip.NewReader(bytes.NewReader(archiveData), int64(len(archiveData)))
 	if errZip != nil {
 		return InstallResult{}, fmt.Errorf("open zip: %w", errZip)
 	}
 
-	libraryData, mode, errLibrary := readTargetLibrary(reader, id, version, options.GOOS)
+	libraryData, mode, errLibrary := readTargetLibrary(reader, id, version, extension)
 	if errLibrary != nil {
 		return InstallResult{}, errLibrary
 	}
+	return installLibrary(libraryData, mode, id, version, options, extension)
+}
 
-	targetPath, errTarget := installTargetPath(options, id, version)
+func installLibrary(libraryData []byte, mode os.FileMode, id string, version string, options InstallOptions, extension string) (InstallResult, error) {
+	targetPath, errTarget := installTargetPath(options, id, version, extension)
 	if errTarget != nil {
 		return InstallResult{}, errTarget
 	}
@@ -279,7 +335,7 @@ func InstallArchive(archiveData []byte, plugin Plugin, options InstallOptions) (
 		if bytes.Equal(existingData, libraryData) {
 			return InstallResult{
 				ID:          id,
-				Version:     strings.TrimSpace(plugin.Version),
+				Version:     version,
 				Path:        targetPath,
 				Overwritten: true,
 				Skipped:     true,
@@ -301,23 +357,23 @@ func InstallArchive(archiveData []byte, plugin Plugin, options InstallOptions) (
 	}
 	return InstallResult{
 		ID:          id,
-		Version:     strings.TrimSpace(plugin.Version),
+		Version:     version,
 		Path:        targetPath,
 		Overwritten: overwritten,
 	}, nil
 }
 
-func installTargetPath(options InstallOptions, id string, version string) (string, error) {
+func installTargetPath(options InstallOptions, id string, version string, extension string) (string, error) {
 	version = normalizeVersion(version)
 	if !validPluginVersion(version) {
 		return "", fmt.Errorf("invalid plugin version %q", version)
 	}
-	return filepath.Join(options.PluginsDir, options.GOOS, options.GOARCH, versionedPluginFileName(id, version, options.GOOS)), nil
+	return filepath.Join(options.PluginsDir, options.GOOS, options.GOARCH, versionedPluginFileName(id, version, extension)), nil
 }
 
-func readTargetLibrary(reader *zip.Reader, id string, version string, goos string) ([]byte, os.FileMode, error) {
-	targetName := strings.TrimSpace(id) + pluginExtension(goos)
-	versionedTargetName := versionedPluginFileName(id, version, goos)
+func readTargetLibrary(reader *zip.Reader, id string, version string, extension string) ([]byte, os.FileMode, error) {
+	targetName := strings.TrimSpace(id) + extension
+	versionedTargetName := versionedPluginFileName(id, version, extension)
 	var target *zip.File
 	for _, file := range reader.File {
 		cleanedName, errClean := cleanZipName(file.Name)
@@ -330,7 +386,7 @@ func readTargetLibrary(reader *zip.Reader, id string, version string, goos strin
 		if !regularZipFile(file) {
 			return nil, 0, fmt.Errorf("zip entry %s is not a regular file", file.Name)
 		}
-		if !hasDynamicLibraryExtension(cleanedName) {
+		if !pluginLibraryCandidate(cleanedName, extension) {
 			continue
 		}
 		if cleanedName != targetName && cleanedName != versionedTargetName {
@@ -368,8 +424,8 @@ func readTargetLibrary(reader *zip.Reader, id string, version string, goos strin
 	return data, mode, nil
 }
 
-func versionedPluginFileName(id string, version string, goos string) string {
-	return strings.TrimSpace(id) + "-v" + normalizeVersion(version) + pluginExtension(goos)
+func versionedPluginFileName(id string, version string, extension string) string {
+	return strings.TrimSpace(id) + "-v" + normalizeVersion(version) + extension
 }
 
 func cleanZipName(name string) (string, error) {
@@ -399,6 +455,15 @@ func hasDynamicLibraryExtension(name string) bool {
 	return strings.HasSuffix(lowerName, ".dylib") || strings.HasSuffix(lowerName, ".so") || strings.HasSuffix(lowerName, ".dll")
 }
 
+// pluginLibraryCandidate reports whether an archive entry competes for the
+// plugin file with the given extension.
+func pluginLibraryCandidate(name string, extension string) bool {
+	if extension == WASMExtension {
+		return strings.HasSuffix(strings.ToLower(name), WASMExtension)
+	}
+	return hasDynamicLibraryExtension(name)
+}
+
 type pluginFileInfo struct {
 	ID      string
 	Path    string
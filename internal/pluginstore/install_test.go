@@ -812,3 +812,86 @@ func testPlugin() Plugin {
 		Repository:  "https://github.com/author-name/cliproxy-sample-provider-plugin",
 	}
 }
+
+func TestInstallDirectFallsBackToWASMArtifact(t *testing.T) {
+	t.Parallel()
+
+	module := []byte("\x00asm\x01\x00\x00\x00")
+	checksum := sha256.Sum256(module)
+	client := Client{HTTPClient: mapHTTPDoer{
+		"https://downloads.example/sample-provider.wasm": module,
+	}}
+	plugin := testPlugin()
+	plugin.Version = "0.5.0"
+	plugin.Install = InstallPlan{
+		Type: InstallTypeDirect,
+		Artifacts: []Artifact{{
+			Type:   ArtifactTypeWASM,
+			URL:    "https://downloads.example/sample-provider.wasm",
+			SHA256: hex.EncodeToString(checksum[:]),
+		}},
+	}
+	if platforms := PluginPlatforms(plugin); len(platforms) != 1 || platforms[0] != (Platform{GOOS: WASMGOOS, GOARCH: WASMGOARCH}) {
+		t.Fatalf("PluginPlatforms() = %v, want the wasm platform", platforms)
+	}
+	root := t.TempDir()
+	result, errInstall := client.Install(context.Background(), plugin, InstallOptions{
+		PluginsDir: root,
+		GOOS:       "windows",
+		GOARCH:     "arm64",
+		// WASM modules are never locked by the running host.
+		PluginLoaded: func() bool { return true },
+	})
+	if errInstall != nil {
+		t.Fatalf("Install() error = %v", errInstall)
+	}
+	wantPath := filepath.Join(root, "windows", "arm64", "sample-provider-v0.5.0.wasm")
+	if result.Path != wantPath {
+		t.Fatalf("Path = %q, want %q", result.Path, wantPath)
+	}
+	data, errRead := os.ReadFile(wantPath)
+	if errRead != nil || !bytes.Equal(data, module) {
+		t.Fatalf("installed data = %q, %v", data, errRead)
+	}
+}
+
+func TestInstallWASMReadsModuleFromArchive(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	result, errInstall := InstallWASM(makeZip(t, map[string]string{
+		"sample-provider.so":   "native-library",
+		"sample-provider.wasm": "\x00asm-module",
+	}), testPlugin(), InstallOptions{PluginsDir: root, GOOS: "linux", GOARCH: "amd64"})
+	if errInstall != nil {
+		t.Fatalf("InstallWASM() error = %v", errInstall)
+	}
+	data, errRead := os.ReadFile(result.Path)
+	if errRead != nil || string(data) != "\x00asm-module" || filepath.Ext(result.Path) != WASMExtension {
+		t.Fatalf("installed %s = %q, %v", result.Path, data, errRead)
+	}
+
+	if _, errInstall = InstallWASM(makeZip(t, map[string]string{"other.wasm": "x"}), testPlugin(), InstallOptions{PluginsDir: root}); errInstall == nil {
+		t.Fatal("InstallWASM() accepted an archive without the plugin module")
+	}
+}
+
+func TestSelectArtifactPrefersNativeOverWASM(t *testing.T) {
+	t.Parallel()
+
+	plan := InstallPlan{Type: InstallTypeDirect, Artifacts: []Artifact{
+		{Type: "WASM", URL: "https://downloads.example/p.wasm"},
+		{GOOS: "linux", GOARCH: "x86_64", URL: "https://downloads.example/p.zip"},
+	}}
+	native, errSelect := SelectArtifact(plan, "linux", "amd64")
+	if errSelect != nil || native.Type != "" {
+		t.Fatalf("SelectArtifact(linux/amd64) = %+v, %v, want the native artifact", native, errSelect)
+	}
+	portable, errSelect := SelectArtifact(plan, "darwin", "arm64")
+	if errSelect != nil || portable.Type != ArtifactTypeWASM || portable.GOOS != WASMGOOS {
+		t.Fatalf("SelectArtifact(darwin/arm64) = %+v, %v, want the wasm artifact", portable, errSelect)
+	}
+	if errValidate := ValidateArtifact(Artifact{Type: "jar", URL: "https://downloads.example/p.jar", SHA256: strings.Repeat("0", 64)}); errValidate == nil {
+		t.Fatal("ValidateArtifact() accepted an unknown artifact type")
+	}
+}
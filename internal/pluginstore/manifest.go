@@ -23,6 +23,11 @@ type Manifest struct {
 	SourceName    string      `yaml:"source-name,omitempty" json:"source_name,omitempty"`
 	SourceURL     string      `yaml:"source-url,omitempty" json:"source_url,omitempty"`
 	Install       InstallPlan `yaml:"install,omitempty" json:"install,omitempty"`
+	// Signature, Signer, and SignerKeyID record the publisher check made when
+	// the plugin was installed.
+	Signature   string `yaml:"signature,omitempty" json:"signature,omitempty"`
+	Signer      string `yaml:"signer,omitempty" json:"signer,omitempty"`
+	SignerKeyID string `yaml:"signer-key-id,omitempty" json:"signer_key_id,omitempty"`
 }
 
 func ManifestFromRelease(source Source, plugin Plugin, release Release) (Manifest, error) {
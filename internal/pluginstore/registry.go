@@ -63,13 +63,31 @@ type InstallPlan struct {
 }
 
 type Artifact struct {
+	// Type is ArtifactTypeNative (the default) or ArtifactTypeWASM.
+	Type   string `yaml:"type,omitempty" json:"type,omitempty"`
 	GOOS   string `yaml:"goos,omitempty" json:"goos,omitempty"`
 	GOARCH string `yaml:"goarch,omitempty" json:"goarch,omitempty"`
 	URL    string `yaml:"url,omitempty" json:"url,omitempty"`
 	SHA256 string `yaml:"sha256,omitempty" json:"sha256,omitempty"`
 	Size   int64  `yaml:"size,omitempty" json:"size,omitempty"`
+	// SignatureURL points at a detached minisign signature of the artifact.
+	SignatureURL string `yaml:"signature-url,omitempty" json:"signature_url,omitempty"`
 }
 
+const (
+	// ArtifactTypeNative is a dynamic library built for one GOOS/GOARCH.
+	ArtifactTypeNative = "native"
+	// ArtifactTypeWASM is a WASI module that runs on every platform. Its
+	// platform is always WASMGOOS/WASMGOARCH.
+	ArtifactTypeWASM = "wasm"
+
+	WASMGOOS      = "wasip1"
+	WASMGOARCH    = "wasm"
+	WASMExtension = ".wasm"
+)
+
+var wasmMagic = []byte("\x00asm")
+
 type Platform struct {
 	GOOS   string `json:"goos"`
 	GOARCH string `json:"goarch"`
@@ -269,11 +287,7 @@ func PluginInstallType(plugin Plugin) string {
 func NormalizeInstallPlan(plan InstallPlan) InstallPlan {
 	plan.Type = strings.ToLower(strings.TrimSpace(plan.Type))
 	for index := range plan.Artifacts {
-		artifact := &plan.Artifacts[index]
-		artifact.GOOS = normalizeGOOS(artifact.GOOS)
-		artifact.GOARCH = normalizeGOARCH(artifact.GOARCH)
-		artifact.URL = strings.TrimSpace(artifact.URL)
-		artifact.SHA256 = strings.ToLower(strings.TrimSpace(artifact.SHA256))
+		plan.Artifacts[index] = normalizeArtifact(plan.Artifacts[index])
 	}
 	return plan
 }
@@ -300,11 +314,28 @@ func ValidateInstallPlan(plan InstallPlan) error {
 	return nil
 }
 
-func ValidateArtifact(artifact Artifact) error {
+func normalizeArtifact(artifact Artifact) Artifact {
+	artifact.Type = strings.ToLower(strings.TrimSpace(artifact.Type))
+	if artifact.Type == ArtifactTypeNative {
+		artifact.Type = ""
+	}
 	artifact.GOOS = normalizeGOOS(artifact.GOOS)
 	artifact.GOARCH = normalizeGOARCH(artifact.GOARCH)
+	if artifact.Type == ArtifactTypeWASM {
+		artifact.GOOS = WASMGOOS
+		artifact.GOARCH = WASMGOARCH
+	}
 	artifact.URL = strings.TrimSpace(artifact.URL)
 	artifact.SHA256 = strings.ToLower(strings.TrimSpace(artifact.SHA256))
+	artifact.SignatureURL = strings.TrimSpace(artifact.SignatureURL)
+	return artifact
+}
+
+func ValidateArtifact(artifact Artifact) error {
+	artifact = normalizeArtifact(artifact)
+	if artifact.Type != "" && artifact.Type != ArtifactTypeWASM {
+		return fmt.Errorf("unsupported artifact type %q", artifact.Type)
+	}
 	if artifact.GOOS == "" {
 		return fmt.Errorf("missing goos")
 	}
@@ -336,6 +367,15 @@ func ValidateArtifact(artifact Artifact) error {
 	if artifact.Size < 0 {
 		return fmt.Errorf("invalid size")
 	}
+	if artifact.SignatureURL != "" {
+		signatureURL, errSignatureURL := url.Parse(artifact.SignatureURL)
+		if errSignatureURL != nil || signatureURL.Host == "" || (signatureURL.Scheme != "https" && signatureURL.Scheme != "http") {
+			return fmt.Errorf("invalid signature url")
+		}
+		if hasSensitiveQueryParameter(signatureURL) {
+			return fmt.Errorf("signature url contains sensitive query parameter")
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,276 @@
+package pluginstore
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// Artifacts are signed with minisign (https://jedisct1.github.io/minisign/):
+// detached ed25519 signatures published next to the artifact as
+// <artifact>.minisig and checked against publisher keys trusted per source.
+const (
+	SignatureExtension = ".minisig"
+
+	SignatureStatusVerified  = "verified"
+	SignatureStatusUnsigned  = "unsigned"
+	SignatureStatusUntrusted = "untrusted"
+
+	// TrustSourceAny matches every store source without a more specific entry.
+	TrustSourceAny = "*"
+)
+
+const (
+	minisignKeyIDSize        = 8
+	minisignUntrustedPrefix  = "untrusted comment:"
+	minisignTrustedPrefix    = "trusted comment:"
+	minisignAlgorithmLegacy  = "Ed"
+	minisignAlgorithmHashed  = "ED"
+	minisignPublicKeyLength  = 2 + minisignKeyIDSize + ed25519.PublicKeySize
+	minisignSignatureLength  = 2 + minisignKeyIDSize + ed25519.SignatureSize
+	minisignGlobalSigLength  = ed25519.SignatureSize
+	maxSignatureFileSize     = 64 << 10
+	minisignPublicKeyComment = "minisign public key"
+)
+
+// TrustConfig lists the publisher keys trusted for one store source.
+type TrustConfig struct {
+	// Source is a store source ID, a registry URL, or TrustSourceAny.
+	Source     string         `yaml:"source" json:"source"`
+	Publishers []PublisherKey `yaml:"publishers,omitempty" json:"publishers,omitempty"`
+	// AllowUnsigned installs artifacts that publish no signature.
+	AllowUnsigned bool `yaml:"allow-unsigned,omitempty" json:"allow_unsigned,omitempty"`
+	// AllowUntrusted installs artifacts signed by a key not listed in Publishers.
+	// Such signatures cannot be checked and are reported as untrusted.
+	AllowUntrusted bool `yaml:"allow-untrusted,omitempty" json:"allow_untrusted,omitempty"`
+}
+
+// PublisherKey is a named minisign public key.
+type PublisherKey struct {
+	Name string `yaml:"name" json:"name"`
+	// Key is the base64 minisign public key line, or a whole .pub file.
+	Key string `yaml:"key" json:"key"`
+}
+
+// TrustPolicy decides whether a signed or unsigned artifact may be installed.
+type TrustPolicy struct {
+	Publishers     []PublisherKey
+	AllowUnsigned  bool
+	AllowUntrusted bool
+}
+
+// SignatureVerification describes the outcome of a successful trust check.
+type SignatureVerification struct {
+	Status string
+	Signer string
+	KeyID  string
+}
+
+// MinisignPublicKey is a decoded minisign ed25519 public key.
+type MinisignPublicKey struct {
+	KeyID [minisignKeyIDSize]byte
+	Key   ed25519.PublicKey
+}
+
+// MinisignSignature is a decoded minisign signature file.
+type MinisignSignature struct {
+	Algorithm       string
+	KeyID           [minisignKeyIDSize]byte
+	Signature       []byte
+	TrustedComment  string
+	GlobalSignature []byte
+}
+
+func NormalizeTrustConfigs(trust []TrustConfig) []TrustConfig {
+	if len(trust) == 0 {
+		return nil
+	}
+	out := make([]TrustConfig, 0, len(trust))
+	for _, item := range trust {
+		item.Source = strings.TrimSpace(item.Source)
+		if item.Source == "" {
+			continue
+		}
+		publishers := make([]PublisherKey, 0, len(item.Publishers))
+		for _, publisher := range item.Publishers {
+			publisher.Name = strings.TrimSpace(publisher.Name)
+			publisher.Key = strings.TrimSpace(publisher.Key)
+			if publisher.Key == "" {
+				continue
+			}
+			publishers = append(publishers, publisher)
+		}
+		item.Publishers = publishers
+		out = append(out, item)
+	}
+	return out
+}
+
+// TrustPolicyForSource returns the policy configured for source. An entry
+// naming the source ID or registry URL wins over TrustSourceAny; without a
+// matching entry unsigned and untrusted artifacts are refused.
+func TrustPolicyForSource(trust []TrustConfig, source Source) TrustPolicy {
+	var fallback *TrustConfig
+	for index := range trust {
+		item := &trust[index]
+		match := strings.TrimSpace(item.Source)
+		switch {
+		case match == TrustSourceAny:
+			if fallback == nil {
+				fallback = item
+			}
+			continue
+		case match != strings.TrimSpace(source.ID) && match != strings.TrimSpace(source.URL):
+			continue
+		}
+		return trustPolicyFromConfig(*item)
+	}
+	if fallback != nil {
+		return trustPolicyFromConfig(*fallback)
+	}
+	return TrustPolicy{}
+}
+
+func trustPolicyFromConfig(item TrustConfig) TrustPolicy {
+	return TrustPolicy{
+		Publishers:     append([]PublisherKey(nil), item.Publishers...),
+		AllowUnsigned:  item.AllowUnsigned,
+		AllowUntrusted: item.AllowUntrusted,
+	}
+}
+
+// Verify checks signatureData, the contents of a .minisig file, against data.
+// A nil signatureData means the artifact is unsigned. Signatures that do not
+// verify are always refused.
+func (p TrustPolicy) Verify(name string, data []byte, signatureData []byte) (SignatureVerification, error) {
+	if signatureData == nil {
+		if !p.AllowUnsigned {
+			return SignatureVerification{}, fmt.Errorf("%s is not signed", name)
+		}
+		return SignatureVerification{Status: SignatureStatusUnsigned}, nil
+	}
+	signature, errParse := ParseMinisignSignature(signatureData)
+	if errParse != nil {
+		return SignatureVerification{}, fmt.Errorf("%s signature: %w", name, errParse)
+	}
+	keyID := MinisignKeyIDString(signature.KeyID)
+	for _, publisher := range p.Publishers {
+		key, errKey := ParseMinisignPublicKey(publisher.Key)
+		if errKey != nil {
+			return SignatureVerification{}, fmt.Errorf("trusted publisher %q: %w", publisher.Name, errKey)
+		}
+		if key.KeyID != signature.KeyID {
+			continue
+		}
+		if errVerify := VerifyMinisign(key, data, signature); errVerify != nil {
+			return SignatureVerification{}, fmt.Errorf("%s signature: %w", name, errVerify)
+		}
+		signer := publisher.Name
+		if signer == "" {
+			signer = keyID
+		}
+		return SignatureVerification{Status: SignatureStatusVerified, Signer: signer, KeyID: keyID}, nil
+	}
+	if !p.AllowUntrusted {
+		return SignatureVerification{}, fmt.Errorf("%s is signed by untrusted key %s", name, keyID)
+	}
+	return SignatureVerification{Status: SignatureStatusUntrusted, KeyID: keyID}, nil
+}
+
+// ParseMinisignPublicKey decodes a minisign public key given either as the
+// base64 key line or as the contents of a .pub file.
+func ParseMinisignPublicKey(value string) (MinisignPublicKey, error) {
+	line := ""
+	for _, rawLine := range strings.Split(value, "\n") {
+		rawLine = strings.TrimSpace(rawLine)
+		if rawLine == "" || strings.HasPrefix(rawLine, minisignUntrustedPrefix) {
+			continue
+		}
+		line = rawLine
+		break
+	}
+	raw, errDecode := base64.StdEncoding.DecodeString(line)
+	if errDecode != nil || len(raw) != minisignPublicKeyLength || string(raw[:2]) != minisignAlgorithmLegacy {
+		return MinisignPublicKey{}, fmt.Errorf("invalid %s", minisignPublicKeyComment)
+	}
+	var key MinisignPublicKey
+	key.KeyID = [minisignKeyIDSize]byte(raw[2 : 2+minisignKeyIDSize])
+	key.Key = ed25519.PublicKey(append([]byte(nil), raw[2+minisignKeyIDSize:]...))
+	return key, nil
+}
+
+// ParseMinisignSignature decodes the four-line minisign signature format.
+func ParseMinisignSignature(data []byte) (MinisignSignature, error) {
+	if len(data) > maxSignatureFileSize {
+		return MinisignSignature{}, fmt.Errorf("signature file is too large")
+	}
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[0], minisignUntrustedPrefix) || !strings.HasPrefix(lines[2], minisignTrustedPrefix) {
+		return MinisignSignature{}, fmt.Errorf("malformed minisign signature")
+	}
+	raw, errDecode := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if errDecode != nil || len(raw) != minisignSignatureLength {
+		return MinisignSignature{}, fmt.Errorf("malformed minisign signature")
+	}
+	algorithm := string(raw[:2])
+	if algorithm != minisignAlgorithmLegacy && algorithm != minisignAlgorithmHashed {
+		return MinisignSignature{}, fmt.Errorf("unsupported signature algorithm %q", algorithm)
+	}
+	globalSignature, errGlobal := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if errGlobal != nil || len(globalSignature) != minisignGlobalSigLength {
+		return MinisignSignature{}, fmt.Errorf("malformed minisign global signature")
+	}
+	return MinisignSignature{
+		Algorithm:       algorithm,
+		KeyID:           [minisignKeyIDSize]byte(raw[2 : 2+minisignKeyIDSize]),
+		Signature:       append([]byte(nil), raw[2+minisignKeyIDSize:]...),
+		TrustedComment:  strings.TrimPrefix(lines[2], minisignTrustedPrefix+" "),
+		GlobalSignature: globalSignature,
+	}, nil
+}
+
+// VerifyMinisign checks both the data signature and the global signature that
+// binds the trusted comment to it.
+func VerifyMinisign(key MinisignPublicKey, data []byte, signature MinisignSignature) error {
+	if key.KeyID != signature.KeyID {
+		return fmt.Errorf("signature key %s does not match %s", MinisignKeyIDString(signature.KeyID), MinisignKeyIDString(key.KeyID))
+	}
+	message := data
+	if signature.Algorithm == minisignAlgorithmHashed {
+		digest := blake2b.Sum512(data)
+		message = digest[:]
+	}
+	if !ed25519.Verify(key.Key, message, signature.Signature) {
+		return fmt.Errorf("signature verification failed")
+	}
+	var global bytes.Buffer
+	global.Write(signature.Signature)
+	global.WriteString(signature.TrustedComment)
+	if !ed25519.Verify(key.Key, global.Bytes(), signature.GlobalSignature) {
+		return fmt.Errorf("trusted comment verification failed")
+	}
+	return nil
+}
+
+// MinisignKeyIDString formats a key ID the way the minisign tool prints it.
+func MinisignKeyIDString(keyID [minisignKeyIDSize]byte) string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(keyID[:]))
+}
+
+func (c Client) verifyArtifactSignature(name string, data []byte, signatureData []byte) (SignatureVerification, error) {
+	if c.Trust == nil {
+		return SignatureVerification{}, nil
+	}
+	return c.Trust.Verify(name, data, signatureData)
+}
+
+func applySignatureVerification(result *InstallResult, verification SignatureVerification) {
+	result.Signature = verification.Status
+	result.Signer = verification.Signer
+	result.SignerKeyID = verification.KeyID
+}
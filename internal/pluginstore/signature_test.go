@@ -0,0 +1,237 @@
+package pluginstore
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+type testMinisignKey struct {
+	keyID   [minisignKeyIDSize]byte
+	private ed25519.PrivateKey
+	public  string
+}
+
+func newTestMinisignKey(t *testing.T, seed byte) testMinisignKey {
+	t.Helper()
+	seedBytes := make([]byte, ed25519.SeedSize)
+	for index := range seedBytes {
+		seedBytes[index] = seed
+	}
+	private := ed25519.NewKeyFromSeed(seedBytes)
+	key := testMinisignKey{private: private}
+	for index := range key.keyID {
+		key.keyID[index] = seed + byte(index)
+	}
+	raw := append([]byte(minisignAlgorithmLegacy), key.keyID[:]...)
+	raw = append(raw, private.Public().(ed25519.PublicKey)...)
+	key.public = base64.StdEncoding.EncodeToString(raw)
+	return key
+}
+
+// sign produces a minisign signature file; hashed selects the prehashed "ED" form.
+func (k testMinisignKey) sign(data []byte, trustedComment string, hashed bool) []byte {
+	algorithm := minisignAlgorithmLegacy
+	message := data
+	if hashed {
+		algorithm = minisignAlgorithmHashed
+		digest := blake2b.Sum512(data)
+		message = digest[:]
+	}
+	signature := ed25519.Sign(k.private, message)
+	raw := append([]byte(algorithm), k.keyID[:]...)
+	raw = append(raw, signature...)
+	global := ed25519.Sign(k.private, append(append([]byte(nil), signature...), trustedComment...))
+	return []byte("untrusted comment: signature from minisign secret key\n" +
+		base64.StdEncoding.EncodeToString(raw) + "\n" +
+		"trusted comment: " + trustedComment + "\n" +
+		base64.StdEncoding.EncodeToString(global) + "\n")
+}
+
+func TestVerifyMinisign(t *testing.T) {
+	t.Parallel()
+
+	key := newTestMinisignKey(t, 7)
+	publicKey, errKey := ParseMinisignPublicKey("untrusted comment: minisign public key\n" + key.public + "\n")
+	if errKey != nil {
+		t.Fatalf("ParseMinisignPublicKey() error = %v", errKey)
+	}
+	data := []byte("plugin archive")
+	for _, hashed := range []bool{false, true} {
+		signature, errParse := ParseMinisignSignature(key.sign(data, "timestamp:1 file:plugin.zip", hashed))
+		if errParse != nil {
+			t.Fatalf("ParseMinisignSignature(hashed=%v) error = %v", hashed, errParse)
+		}
+		if errVerify := VerifyMinisign(publicKey, data, signature); errVerify != nil {
+			t.Fatalf("VerifyMinisign(hashed=%v) error = %v", hashed, errVerify)
+		}
+		if errVerify := VerifyMinisign(publicKey, []byte("tampered archive"), signature); errVerify == nil {
+			t.Fatalf("VerifyMinisign(hashed=%v) accepted tampered data", hashed)
+		}
+		signature.TrustedComment = "timestamp:2 file:other.zip"
+		if errVerify := VerifyMinisign(publicKey, data, signature); errVerify == nil {
+			t.Fatalf("VerifyMinisign(hashed=%v) accepted a tampered trusted comment", hashed)
+		}
+	}
+	if _, errParse := ParseMinisignSignature([]byte("not a signature")); errParse == nil {
+		t.Fatal("ParseMinisignSignature() accepted malformed input")
+	}
+	if _, errKey := ParseMinisignPublicKey("RWQ="); errKey == nil {
+		t.Fatal("ParseMinisignPublicKey() accepted a short key")
+	}
+}
+
+func TestTrustPolicyVerify(t *testing.T) {
+	t.Parallel()
+
+	trusted := newTestMinisignKey(t, 1)
+	stranger := newTestMinisignKey(t, 2)
+	data := []byte("plugin archive")
+	policy := TrustPolicy{Publishers: []PublisherKey{{Name: "Example Publisher", Key: trusted.public}}}
+
+	verification, errVerify := policy.Verify("plugin.zip", data, trusted.sign(data, "release", true))
+	if errVerify != nil {
+		t.Fatalf("Verify(trusted) error = %v", errVerify)
+	}
+	if verification.Status != SignatureStatusVerified || verification.Signer != "Example Publisher" || verification.KeyID != MinisignKeyIDString(trusted.keyID) {
+		t.Fatalf("Verify(trusted) = %#v", verification)
+	}
+	if _, errVerify = policy.Verify("plugin.zip", data, nil); errVerify == nil || !strings.Contains(errVerify.Error(), "not signed") {
+		t.Fatalf("Verify(unsigned) error = %v, want refusal", errVerify)
+	}
+	if _, errVerify = policy.Verify("plugin.zip", data, stranger.sign(data, "release", true)); errVerify == nil || !strings.Contains(errVerify.Error(), "untrusted key") {
+		t.Fatalf("Verify(untrusted) error = %v, want refusal", errVerify)
+	}
+
+	permissive := policy
+	permissive.AllowUnsigned = true
+	permissive.AllowUntrusted = true
+	if verification, errVerify = permissive.Verify("plugin.zip", data, nil); errVerify != nil || verification.Status != SignatureStatusUnsigned {
+		t.Fatalf("Verify(unsigned, allowed) = %#v, %v", verification, errVerify)
+	}
+	if verification, errVerify = permissive.Verify("plugin.zip", data, stranger.sign(data, "release", true)); errVerify != nil || verification.Status != SignatureStatusUntrusted || verification.Signer != "" {
+		t.Fatalf("Verify(untrusted, allowed) = %#v, %v", verification, errVerify)
+	}
+	// A trusted key whose signature does not match is refused regardless of the allow flags.
+	if _, errVerify = permissive.Verify("plugin.zip", []byte("tampered"), trusted.sign(data, "release", true)); errVerify == nil {
+		t.Fatal("Verify(bad signature, allowed) succeeded")
+	}
+}
+
+func TestTrustPolicyForSource(t *testing.T) {
+	t.Parallel()
+
+	trust := NormalizeTrustConfigs([]TrustConfig{
+		{Source: " * ", AllowUnsigned: true},
+		{Source: "https://community.example/registry.json", AllowUntrusted: true, Publishers: []PublisherKey{{Name: " Community ", Key: " key "}, {Name: "empty"}}},
+		{Source: ""},
+	})
+	if len(trust) != 2 || len(trust[1].Publishers) != 1 || trust[1].Publishers[0].Name != "Community" {
+		t.Fatalf("NormalizeTrustConfigs() = %#v", trust)
+	}
+	community := TrustPolicyForSource(trust, Source{ID: "community", URL: "https://community.example/registry.json"})
+	if community.AllowUnsigned || !community.AllowUntrusted || len(community.Publishers) != 1 {
+		t.Fatalf("community policy = %#v, want the exact source entry", community)
+	}
+	if official := TrustPolicyForSource(trust, DefaultSource()); !official.AllowUnsigned || official.AllowUntrusted {
+		t.Fatalf("official policy = %#v, want the wildcard entry", official)
+	}
+	if strict := TrustPolicyForSource(nil, DefaultSource()); strict.AllowUnsigned || strict.AllowUntrusted || len(strict.Publishers) != 0 {
+		t.Fatalf("policy without config = %#v, want refusal of unsigned artifacts", strict)
+	}
+}
+
+func TestInstallVerifiesReleaseSignature(t *testing.T) {
+	t.Parallel()
+
+	key := newTestMinisignKey(t, 3)
+	archiveData := makeZip(t, map[string]string{"sample-provider.so": "library-data"})
+	archiveName := "sample-provider_0.2.0_linux_amd64.zip"
+	checksum := sha256.Sum256(archiveData)
+	release := `{
+		"tag_name": "v0.2.0",
+		"assets": [
+			{"name": "` + archiveName + `", "browser_download_url": "https://downloads.example/` + archiveName + `"},
+			{"name": "` + archiveName + `.minisig", "browser_download_url": "https://downloads.example/` + archiveName + `.minisig"},
+			{"name": "checksums.txt", "browser_download_url": "https://downloads.example/checksums.txt"}
+		]
+	}`
+	responses := mapHTTPDoer{
+		"https://api.github.com/repos/author-name/cliproxy-sample-provider-plugin/releases/latest": []byte(release),
+		"https://downloads.example/" + archiveName:                                                 archiveData,
+		"https://downloads.example/" + archiveName + ".minisig":                                    key.sign(archiveData, "sample-provider 0.2.0", false),
+		"https://downloads.example/checksums.txt":                                                  []byte(hex.EncodeToString(checksum[:]) + "  " + archiveName + "\n"),
+	}
+	options := func(root string) InstallOptions {
+		return InstallOptions{PluginsDir: root, GOOS: "linux", GOARCH: "amd64"}
+	}
+
+	root := t.TempDir()
+	client := Client{HTTPClient: responses, Trust: &TrustPolicy{Publishers: []PublisherKey{{Name: "Author", Key: key.public}}}}
+	result, errInstall := client.Install(context.Background(), testPlugin(), options(root))
+	if errInstall != nil {
+		t.Fatalf("Install() error = %v", errInstall)
+	}
+	if result.Signature != SignatureStatusVerified || result.Signer != "Author" || result.SignerKeyID != MinisignKeyIDString(key.keyID) {
+		t.Fatalf("result = %#v, want a verified signature from Author", result)
+	}
+
+	other := newTestMinisignKey(t, 4)
+	root = t.TempDir()
+	client.Trust = &TrustPolicy{Publishers: []PublisherKey{{Name: "Other", Key: other.public}}}
+	if _, errInstall = client.Install(context.Background(), testPlugin(), options(root)); errInstall == nil || !strings.Contains(errInstall.Error(), "untrusted key") {
+		t.Fatalf("Install(untrusted) error = %v, want refusal", errInstall)
+	}
+	if _, errStat := os.Stat(filepath.Join(root, "linux", "amd64", "sample-provider-v0.2.0.so")); !errors.Is(errStat, os.ErrNotExist) {
+		t.Fatalf("refused install wrote the plugin: %v", errStat)
+	}
+}
+
+func TestInstallDirectVerifiesSignatureURL(t *testing.T) {
+	t.Parallel()
+
+	key := newTestMinisignKey(t, 5)
+	archiveData := makeZip(t, map[string]string{"sample-provider.so": "library-data"})
+	checksum := sha256.Sum256(archiveData)
+	artifactURL := "https://downloads.example/sample-provider_0.4.0_linux_amd64.zip"
+	plugin := testPlugin()
+	plugin.Version = "0.4.0"
+	plugin.Install = InstallPlan{
+		Type: InstallTypeDirect,
+		Artifacts: []Artifact{{
+			GOOS:         "linux",
+			GOARCH:       "amd64",
+			URL:          artifactURL,
+			SHA256:       hex.EncodeToString(checksum[:]),
+			SignatureURL: artifactURL + SignatureExtension,
+		}},
+	}
+	client := Client{
+		HTTPClient: mapHTTPDoer{
+			artifactURL:                      archiveData,
+			artifactURL + SignatureExtension: key.sign(archiveData, "sample-provider 0.4.0", true),
+		},
+		Trust: &TrustPolicy{Publishers: []PublisherKey{{Key: key.public}}},
+	}
+	result, errInstall := client.Install(context.Background(), plugin, InstallOptions{PluginsDir: t.TempDir(), GOOS: "linux", GOARCH: "amd64"})
+	if errInstall != nil {
+		t.Fatalf("Install() error = %v", errInstall)
+	}
+	if result.Signature != SignatureStatusVerified || result.Signer != MinisignKeyIDString(key.keyID) {
+		t.Fatalf("result = %#v, want the key id as signer for an unnamed publisher", result)
+	}
+
+	plugin.Install.Artifacts[0].SignatureURL = ""
+	if _, errInstall = client.Install(context.Background(), plugin, InstallOptions{PluginsDir: t.TempDir(), GOOS: "linux", GOARCH: "amd64"}); errInstall == nil || !strings.Contains(errInstall.Error(), "not signed") {
+		t.Fatalf("Install(unsigned) error = %v, want refusal", errInstall)
+	}
+}
@@ -116,6 +116,7 @@ func (p *usageQueuePlugin) HandleUsage(ctx context.Context, record coreusage.Rec
 		ReasoningEffort:     reasoningEffort,
 		ServiceTier:         serviceTier,
 		ResponseServiceTier: responseServiceTier,
+		Redactions:          record.Redactions,
 	})
 	if err != nil {
 		return
@@ -138,6 +139,7 @@ type queuedUsageDetail struct {
 	ReasoningEffort     string                   `json:"reasoning_effort"`
 	ServiceTier         string                   `json:"service_tier"`
 	ResponseServiceTier string                   `json:"response_service_tier,omitempty"`
+	Redactions          map[string]int64         `json:"redactions,omitempty"`
 }
 
 type requestDetail struct {
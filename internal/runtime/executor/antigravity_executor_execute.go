@@ -224,7 +224,7 @@ attemptLoop:
 					// Report the upstream failure rather than the cleanup failure.
 					logAntigravityReasoningReplayDegraded(replayScope, "invalidate", errClear)
 				}
-				err = newAntigravityStatusErr(httpResp.StatusCode, bodyBytes)
+				err = newAntigravityStatusErr(httpResp.StatusCode, bodyBytes).withHeaders(httpResp.Header)
 				return resp, err
 			}
 
@@ -466,7 +466,7 @@ attemptLoop:
 					// Report the upstream failure rather than the cleanup failure.
 					logAntigravityReasoningReplayDegraded(replayScope, "invalidate", errClear)
 				}
-				err = newAntigravityStatusErr(httpResp.StatusCode, bodyBytes)
+				err = newAntigravityStatusErr(httpResp.StatusCode, bodyBytes).withHeaders(httpResp.Header)
 				return resp, err
 			}
 
@@ -232,7 +232,7 @@ attemptLoop:
 					// Report the upstream failure rather than the cleanup failure.
 					logAntigravityReasoningReplayDegraded(replayScope, "invalidate", errClear)
 				}
-				err = newAntigravityStatusErr(httpResp.StatusCode, bodyBytes)
+				err = newAntigravityStatusErr(httpResp.StatusCode, bodyBytes).withHeaders(httpResp.Header)
 				return nil, err
 			}
 
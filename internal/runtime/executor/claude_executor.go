@@ -18,6 +18,10 @@ import (
 	"github.com/tidwall/sjson"
 )
 
+func init() {
+	cliproxyauth.RegisterRateLimitHeaderParser("claude", helps.ClaudeRateLimitWindows)
+}
+
 // ClaudeExecutor is a stateless executor for Anthropic Claude over the messages API.
 // If api_key is unavailable on auth, it falls back to legacy via ClientAdapter.
 type ClaudeExecutor struct {
@@ -249,3 +253,10 @@ func (e *ClaudeExecutor) HttpRequest(ctx context.Context, auth *cliproxyauth.Aut
 	httpClient := helps.NewUtlsHTTPClient(ctx, e.cfg, auth, 0)
 	return httpClient.Do(httpReq)
 }
+
+// CheckHealth verifies Claude OAuth credentials through the usage endpoint,
+// which spends no tokens. API-key credentials fall back to a model request probe.
+func (e *ClaudeExecutor) CheckHealth(ctx context.Context, auth *cliproxyauth.Auth) error {
+	_, err := cliproxyauth.FetchQuotaUsageWindows(ctx, e, auth)
+	return err
+}
@@ -84,6 +84,11 @@ func TestClaudeExecutor_HonorsAnthropicRateLimitHeaders_Execute(t *testing.T) {
 	if second := rap.RetryAfter(); second == nil || *second != *retryAfter {
 		t.Fatalf("RetryAfter changed across calls: %v vs %v", *second, *retryAfter)
 	}
+
+	var hp interface{ Headers() http.Header }
+	if !errors.As(err, &hp) || hp.Headers().Get("Anthropic-Ratelimit-Unified-7d-Status") != "rejected" {
+		t.Fatalf("expected error %T to carry the upstream rate-limit headers", err)
+	}
 }
 
 func TestClaudeExecutor_HonorsAnthropicRateLimitHeaders_ExecuteStream(t *testing.T) {
@@ -84,6 +84,13 @@ func (e *CodexExecutor) HttpRequest(ctx context.Context, auth *cliproxyauth.Auth
 	return httpClient.Do(httpReq)
 }
 
+// CheckHealth verifies Codex OAuth credentials through the usage endpoint, which
+// spends no tokens. API-key credentials fall back to a model request probe.
+func (e *CodexExecutor) CheckHealth(ctx context.Context, auth *cliproxyauth.Auth) error {
+	_, err := cliproxyauth.FetchQuotaUsageWindows(ctx, e, auth)
+	return err
+}
+
 type codexIdentityConfuseState struct {
 	enabled                bool
 	authID                 string
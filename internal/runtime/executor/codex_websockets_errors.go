@@ -14,18 +14,6 @@ import (
 	"github.com/tidwall/sjson"
 )
 
-type statusErrWithHeaders struct {
-	statusErr
-	headers http.Header
-}
-
-func (e statusErrWithHeaders) Headers() http.Header {
-	if e.headers == nil {
-		return nil
-	}
-	return e.headers.Clone()
-}
-
 func parseCodexWebsocketError(payload []byte) (error, bool) {
 	if len(payload) == 0 {
 		return nil, false
@@ -42,18 +30,14 @@ func parseCodexWebsocketError(payload []byte) (error, bool) {
 	}
 
 	out := buildCodexWebsocketErrorPayload(payload, status)
-	headers := parseCodexWebsocketErrorHeaders(payload)
-	statusError := statusErr{code: status, msg: string(out)}
+	statusError := statusErr{code: status, msg: string(out), headers: parseCodexWebsocketErrorHeaders(payload)}
 	if retryAfter := parseCodexRetryAfter(status, out, time.Now()); retryAfter != nil {
 		statusError.retryAfter = retryAfter
 	} else if isCodexWebsocketConnectionLimitError(payload) {
 		retryAfter := time.Duration(0)
 		statusError.retryAfter = &retryAfter
 	}
-	return statusErrWithHeaders{
-		statusErr: statusError,
-		headers:   headers,
-	}, true
+	return statusError, true
 }
 
 func clearCodexReasoningReplayOnWebsocketError(ctx context.Context, scope codexReasoningReplayScope, payload []byte) error {
@@ -2,12 +2,14 @@ package helps
 
 import (
 	cryptorand "crypto/rand"
+	"math"
 	"math/big"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -173,6 +175,66 @@ func parseClaudeRateLimitResetWithFuzz(headers http.Header, now time.Time, minFu
 	return &effectiveDuration
 }
 
+// ClaudeRateLimitWindows normalizes Anthropic per-key and unified subscription
+// rate-limit headers into quota windows. The 7d_oi window is model-scoped, so it
+// is left to the per-model 429 handling.
+func ClaudeRateLimitWindows(headers http.Header, now time.Time) []cliproxyauth.QuotaWindow {
+	if headers == nil {
+		return nil
+	}
+	var windows []cliproxyauth.QuotaWindow
+	for _, kind := range []string{"requests", "tokens", "input-tokens", "output-tokens"} {
+		prefix := "Anthropic-Ratelimit-" + kind + "-"
+		remaining, ok := parseClaudeRateLimitFloat(getHeaderCaseInsensitive(headers, prefix+"Remaining"))
+		if !ok {
+			continue
+		}
+		window := cliproxyauth.QuotaWindow{Name: "anthropic-" + kind, Remaining: int64(remaining), RemainingFraction: 1}
+		if limit, okLimit := parseClaudeRateLimitFloat(getHeaderCaseInsensitive(headers, prefix+"Limit")); okLimit && limit > 0 {
+			window.Limit = int64(limit)
+			window.RemainingFraction = clampClaudeRateLimitFraction(remaining / limit)
+		} else if remaining <= 0 {
+			window.RemainingFraction = 0
+		}
+		if resetAt, okReset := parseUnixOrTimestamp(getHeaderCaseInsensitive(headers, prefix+"Reset")); okReset && resetAt.After(now) {
+			window.ResetAt = resetAt
+		}
+		windows = append(windows, window)
+	}
+	for _, span := range []string{"5h", "7d"} {
+		prefix := "Anthropic-Ratelimit-Unified-" + span + "-"
+		utilization, ok := parseClaudeRateLimitFloat(getHeaderCaseInsensitive(headers, prefix+"Utilization"))
+		if !ok {
+			continue
+		}
+		window := cliproxyauth.QuotaWindow{Name: "unified-" + span, RemainingFraction: clampClaudeRateLimitFraction(1 - utilization)}
+		if strings.EqualFold(strings.TrimSpace(getHeaderCaseInsensitive(headers, prefix+"Status")), "rejected") {
+			window.RemainingFraction = 0
+		}
+		if resetAt, okReset := parseUnixOrTimestamp(getHeaderCaseInsensitive(headers, prefix+"Reset")); okReset && resetAt.After(now) {
+			window.ResetAt = resetAt
+		}
+		windows = append(windows, window)
+	}
+	return windows
+}
+
+func parseClaudeRateLimitFloat(raw string) (float64, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, false
+	}
+	return value, true
+}
+
+func clampClaudeRateLimitFraction(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
+
 func containsString(list []string, target string) bool {
 	for _, item := range list {
 		if item == target {
@@ -191,3 +191,36 @@ func TestParseClaudeRateLimitReset_AllCases(t *testing.T) {
 		}
 	})
 }
+
+func TestClaudeRateLimitWindows(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	h := make(http.Header)
+	h.Set("Anthropic-Ratelimit-Tokens-Limit", "1000")
+	h.Set("Anthropic-Ratelimit-Tokens-Remaining", "0")
+	h.Set("Anthropic-Ratelimit-Tokens-Reset", "2026-10-18T12:01:00Z")
+	h.Set("Anthropic-Ratelimit-Unified-5h-Utilization", "0.4")
+	h.Set("Anthropic-Ratelimit-Unified-5h-Reset", "1792325600")
+	h.Set("Anthropic-Ratelimit-Unified-7d-Utilization", "0.2")
+	h.Set("Anthropic-Ratelimit-Unified-7d-Status", "rejected")
+	h.Set("Anthropic-Ratelimit-Unified-7d_oi-Utilization", "1")
+
+	windows := ClaudeRateLimitWindows(h, now)
+	if len(windows) != 3 {
+		t.Fatalf("windows = %+v, want 3 entries", windows)
+	}
+	tokens := windows[0]
+	if tokens.Name != "anthropic-tokens" || tokens.Limit != 1000 || tokens.Remaining != 0 || tokens.RemainingFraction != 0 || !tokens.ResetAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("tokens window = %+v", tokens)
+	}
+	fiveHour := windows[1]
+	if fiveHour.Name != "unified-5h" || fiveHour.RemainingFraction < 0.599 || fiveHour.RemainingFraction > 0.601 || !fiveHour.ResetAt.Equal(time.Unix(1792325600, 0)) {
+		t.Fatalf("5h window = %+v", fiveHour)
+	}
+	if sevenDay := windows[2]; sevenDay.Name != "unified-7d" || sevenDay.RemainingFraction != 0 {
+		t.Fatalf("rejected 7d window = %+v, want no headroom", sevenDay)
+	}
+
+	if got := ClaudeRateLimitWindows(http.Header{"Retry-After": []string{"30"}}, now); len(got) != 0 {
+		t.Fatalf("unrelated headers produced windows %+v", got)
+	}
+}
@@ -556,7 +556,7 @@ func (e *XAIWebsocketsExecutor) ExecuteStream(ctx context.Context, auth *cliprox
 			if sess != nil {
 				sess.reqMu.Unlock()
 			}
-			return nil, xaiStatusErr(respHS.StatusCode, bodyErr)
+			return nil, xaiStatusErr(respHS.StatusCode, bodyErr).withHeaders(respHS.Header)
 		}
 		helps.RecordAPIWebsocketError(ctx, e.cfg, "dial", errDial)
 		if sess != nil {
@@ -610,7 +610,7 @@ func (e *XAIWebsocketsExecutor) ExecuteStream(ctx context.Context, auth *cliprox
 				sess.clearActive(conn, readCh)
 				sess.reqMu.Unlock()
 				if respHSRetry != nil && respHSRetry.StatusCode > 0 {
-					return nil, xaiStatusErr(respHSRetry.StatusCode, bodyErrRetry)
+					return nil, xaiStatusErr(respHSRetry.StatusCode, bodyErrRetry).withHeaders(respHSRetry.Header)
 				}
 				return nil, errDialRetry
 			}
@@ -980,7 +980,7 @@ func buildXAIWebsocketWarmupCompletedPayload(createdPayload []byte) []byte {
 
 func parseXAIWebsocketError(payload []byte) (error, bool) {
 	if wsErr, ok := parseCodexWebsocketError(payload); ok {
-		if statusError, okStatus := wsErr.(statusErrWithHeaders); okStatus {
+		if statusError, okStatus := wsErr.(statusErr); okStatus {
 			xaiError := xaiStatusErr(statusError.code, payload)
 			// Apply normalized status (e.g. 403 bad-credentials -> 401) and any
 			// provider-specific retry hint while preserving websocket headers.
@@ -22,6 +22,7 @@ import (
 	"github.com/go-git/go-git/v6/plumbing/transport"
 	"github.com/go-git/go-git/v6/plumbing/transport/http"
 	"github.com/go-git/go-git/v6/storage/filesystem/dotgit"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/envelope"
 	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
 )
 
@@ -412,7 +413,7 @@ func (s *GitTokenStore) Save(_ context.Context, auth *cliproxyauth.Auth) (string
 		if setter, ok := auth.Storage.(interface{ SetMetadata(map[string]any) }); ok {
 			setter.SetMetadata(auth.Metadata)
 		}
-		if err = auth.Storage.SaveTokenToFile(path); err != nil {
+		if err = envelope.SaveToken(path, auth.Storage); err != nil {
 			return "", err
 		}
 	case auth.Metadata != nil:
@@ -423,17 +424,13 @@ func (s *GitTokenStore) Save(_ context.Context, auth *cliproxyauth.Auth) (string
 		}
 		contentsMatch := false
 		if existing, errRead := os.ReadFile(path); errRead == nil {
-			contentsMatch = jsonEqual(existing, raw)
+			contentsMatch = authContentsMatch(path, existing, raw)
 		} else if !os.IsNotExist(errRead) {
 			return "", fmt.Errorf("auth filestore: read existing failed: %w", errRead)
 		}
 		if !contentsMatch {
-			tmp := path + ".tmp"
-			if errWrite := os.WriteFile(tmp, raw, 0o600); errWrite != nil {
-				return "", fmt.Errorf("auth filestore: write temp failed: %w", errWrite)
-			}
-			if errRename := os.Rename(tmp, path); errRename != nil {
-				return "", fmt.Errorf("auth filestore: rename failed: %w", errRename)
+			if errWrite := envelope.WriteFile(path, raw, 0o600); errWrite != nil {
+				return "", fmt.Errorf("auth filestore: write failed: %w", errWrite)
 			}
 		}
 	default:
@@ -642,7 +639,7 @@ func (s *GitTokenStore) resolveDeletePath(id string) (string, error) {
 }
 
 func (s *GitTokenStore) readAuthFile(path, baseDir string) (*cliproxyauth.Auth, error) {
-	data, err := os.ReadFile(path)
+	data, err := envelope.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read file: %w", err)
 	}
@@ -1829,6 +1826,16 @@ func ensureEmptyFile(path string) error {
 	return nil
 }
 
+// authContentsMatch reports whether the auth file at path already holds raw in
+// the form a write would produce, so unchanged records are not rewritten.
+func authContentsMatch(path string, existing, raw []byte) bool {
+	if envelope.NeedsReseal(existing) {
+		return false
+	}
+	opened, errOpen := envelope.Open(envelope.RecordName(path), existing)
+	return errOpen == nil && jsonEqual(opened, raw)
+}
+
 func jsonEqual(a, b []byte) bool {
 	var objA any
 	var objB any
@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/audit"
+)
+
+var _ audit.Store = (*postgresAuditStore)(nil)
+
+type postgresAuditStore struct {
+	store *PostgresStore
+}
+
+// AuditStore returns the PostgreSQL-backed management audit store.
+func (s *PostgresStore) AuditStore() audit.Store {
+	if s == nil || s.auditStore == nil {
+		return nil
+	}
+	return s.auditStore
+}
+
+func (s *postgresAuditStore) Append(ctx context.Context, entry audit.Entry) error {
+	if s == nil || s.store == nil || s.store.db == nil {
+		return fmt.Errorf("postgres audit store: not initialized")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	entry.Normalize()
+	content, errMarshal := json.Marshal(entry)
+	if errMarshal != nil {
+		return fmt.Errorf("postgres audit store: encode entry: %w", errMarshal)
+	}
+	table := s.store.fullTableName(s.store.cfg.AuditTable)
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, created_at, key_name, role, method, path, status, content)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, table)
+	if _, errExec := s.store.db.ExecContext(ctx, query, entry.ID, entry.Timestamp, entry.Key, entry.Role, entry.Method, entry.Path, entry.Status, content); errExec != nil {
+		return fmt.Errorf("postgres audit store: insert entry: %w", errExec)
+	}
+	return nil
+}
+
+func (s *postgresAuditStore) Query(ctx context.Context, filter audit.Filter) (entries []audit.Entry, err error) {
+	if s == nil || s.store == nil || s.store.db == nil {
+		return nil, fmt.Errorf("postgres audit store: not initialized")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	where, args := buildAuditWhere(filter)
+	args = append(args, filter.NormalizedLimit())
+	query := fmt.Sprintf("SELECT content FROM %s%s ORDER BY created_at DESC LIMIT $%d", s.store.fullTableName(s.store.cfg.AuditTable), where, len(args))
+	rows, errQuery := s.store.db.QueryContext(ctx, query, args...)
+	if errQuery != nil {
+		return nil, fmt.Errorf("postgres audit store: query entries: %w", errQuery)
+	}
+	defer func() {
+		if errClose := rows.Close(); errClose != nil {
+			err = errors.Join(err, fmt.Errorf("postgres audit store: close rows: %w", errClose))
+		}
+	}()
+
+	entries = make([]audit.Entry, 0)
+	for rows.Next() {
+		var content []byte
+		if errScan := rows.Scan(&content); errScan != nil {
+			return nil, fmt.Errorf("postgres audit store: scan entry: %w", errScan)
+		}
+		var entry audit.Entry
+		if errUnmarshal := json.Unmarshal(content, &entry); errUnmarshal != nil {
+			return nil, fmt.Errorf("postgres audit store: decode entry: %w", errUnmarshal)
+		}
+		entries = append(entries, entry)
+	}
+	if errRows := rows.Err(); errRows != nil {
+		return nil, fmt.Errorf("postgres audit store: iterate entries: %w", errRows)
+	}
+	return entries, nil
+}
+
+// buildAuditWhere converts a filter into a WHERE clause with positional arguments.
+func buildAuditWhere(filter audit.Filter) (string, []any) {
+	conditions := make([]string, 0, 6)
+	args := make([]any, 0, 6)
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if !filter.Since.IsZero() {
+		add("created_at >= $%d", filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		add("created_at <= $%d", filter.Until.UTC())
+	}
+	if filter.Key != "" {
+		add("key_name = $%d", filter.Key)
+	}
+	if filter.Role != "" {
+		add("LOWER(role) = LOWER($%d)", filter.Role)
+	}
+	if filter.Method != "" {
+		add("method = UPPER($%d)", filter.Method)
+	}
+	if filter.PathPrefix != "" {
+		add("starts_with(path, $%d)", filter.PathPrefix)
+	}
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/envelope"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/misc"
 	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
 	log "github.com/sirupsen/logrus"
@@ -23,6 +24,7 @@ const (
 	defaultConfigTable   = "config_store"
 	defaultAuthTable     = "auth_store"
 	defaultCooldownTable = "cooldown_store"
+	defaultAuditTable    = "audit_log"
 	defaultConfigKey     = "config"
 )
 
@@ -33,6 +35,7 @@ type PostgresStoreConfig struct {
 	ConfigTable   string
 	AuthTable     string
 	CooldownTable string
+	AuditTable    string
 	SpoolDir      string
 }
 
@@ -45,6 +48,7 @@ type PostgresStore struct {
 	configPath    string
 	authDir       string
 	cooldownStore *postgresCooldownStateStore
+	auditStore    *postgresAuditStore
 	mu            sync.Mutex
 }
 
@@ -64,6 +68,9 @@ func NewPostgresStore(ctx context.Context, cfg PostgresStoreConfig) (*PostgresSt
 	if cfg.CooldownTable == "" {
 		cfg.CooldownTable = defaultCooldownTable
 	}
+	if cfg.AuditTable == "" {
+		cfg.AuditTable = defaultAuditTable
+	}
 
 	spoolRoot := strings.TrimSpace(cfg.SpoolDir)
 	if spoolRoot == "" {
@@ -103,6 +110,7 @@ func NewPostgresStore(ctx context.Context, cfg PostgresStoreConfig) (*PostgresSt
 		authDir:    authDir,
 	}
 	store.cooldownStore = &postgresCooldownStateStore{store: store}
+	store.auditStore = &postgresAuditStore{store: store}
 	return store, nil
 }
 
@@ -161,6 +169,21 @@ func (s *PostgresStore) EnsureSchema(ctx context.Context) error {
 	`, cooldownTable)); err != nil {
 		return fmt.Errorf("postgres store: create cooldown table: %w", err)
 	}
+	auditTable := s.fullTableName(s.cfg.AuditTable)
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id TEXT PRIMARY KEY,
+			created_at TIMESTAMPTZ NOT NULL,
+			key_name TEXT NOT NULL DEFAULT '',
+			role TEXT NOT NULL DEFAULT '',
+			method TEXT NOT NULL,
+			path TEXT NOT NULL,
+			status INTEGER NOT NULL DEFAULT 0,
+			content JSONB NOT NULL
+		)
+	`, auditTable)); err != nil {
+		return fmt.Errorf("postgres store: create audit table: %w", err)
+	}
 	return nil
 }
 
@@ -246,7 +269,7 @@ func (s *PostgresStore) Save(ctx context.Context, auth *cliproxyauth.Auth) (stri
 		if setter, ok := auth.Storage.(interface{ SetMetadata(map[string]any) }); ok {
 			setter.SetMetadata(auth.Metadata)
 		}
-		if err = auth.Storage.SaveTokenToFile(path); err != nil {
+		if err = envelope.SaveToken(path, auth.Storage); err != nil {
 			return "", err
 		}
 	case auth.Metadata != nil:
@@ -256,18 +279,14 @@ func (s *PostgresStore) Save(ctx context.Context, auth *cliproxyauth.Auth) (stri
 			return "", fmt.Errorf("postgres store: marshal metadata: %w", errMarshal)
 		}
 		if existing, errRead := os.ReadFile(path); errRead == nil {
-			if jsonEqual(existing, raw) {
+			if authContentsMatch(path, existing, raw) {
 				return path, nil
 			}
 		} else if errRead != nil && !errors.Is(errRead, fs.ErrNotExist) {
 			return "", fmt.Errorf("postgres store: read existing metadata: %w", errRead)
 		}
-		tmp := path + ".tmp"
-		if errWrite := os.WriteFile(tmp, raw, 0o600); errWrite != nil {
-			return "", fmt.Errorf("postgres store: write temp auth file: %w", errWrite)
-		}
-		if errRename := os.Rename(tmp, path); errRename != nil {
-			return "", fmt.Errorf("postgres store: rename auth file: %w", errRename)
+		if errWrite := envelope.WriteFile(path, raw, 0o600); errWrite != nil {
+			return "", fmt.Errorf("postgres store: write auth file: %w", errWrite)
 		}
 	default:
 		return "", fmt.Errorf("postgres store: nothing to persist for %s", auth.ID)
@@ -318,8 +337,13 @@ func (s *PostgresStore) List(ctx context.Context) ([]*cliproxyauth.Auth, error)
 			log.WithError(errPath).Warnf("postgres store: skipping auth %s outside spool", id)
 			continue
 		}
+		content, errOpen := envelope.Open(envelope.RecordName(id), []byte(payload))
+		if errOpen != nil {
+			log.WithError(errOpen).Warnf("postgres store: skipping auth %s that cannot be decrypted", id)
+			continue
+		}
 		metadata := make(map[string]any)
-		if err = json.Unmarshal([]byte(payload), &metadata); err != nil {
+		if err = json.Unmarshal(content, &metadata); err != nil {
 			log.WithError(err).Warnf("postgres store: skipping auth %s with invalid json", id)
 			continue
 		}
@@ -14,10 +14,13 @@ import (
 // Tab identifiers
 const (
 	tabDashboard = iota
+	tabUsage
 	tabConfig
 	tabAuthFiles
 	tabAPIKeys
 	tabOAuth
+	tabRequests
+	tabQuota
 	tabLogs
 )
 
@@ -35,10 +38,13 @@ type App struct {
 	authConnecting bool
 
 	dashboard dashboardModel
+	usage     usageTabModel
 	config    configTabModel
 	auth      authTabModel
 	keys      keysTabModel
 	oauth     oauthTabModel
+	requests  requestsTabModel
+	quota     quotaTabModel
 	logs      logsTabModel
 
 	client *Client
@@ -48,7 +54,7 @@ type App struct {
 	ready  bool
 
 	// Track which tabs have been initialized (fetched data)
-	initialized [6]bool
+	initialized [9]bool
 }
 
 type authConnectMsg struct {
@@ -75,13 +81,16 @@ func NewApp(port int, secretKey string, hook *LogHook) App {
 		authenticated: !authRequired,
 		authInput:     ti,
 		dashboard:     newDashboardModel(client),
+		usage:         newUsageTabModel(client),
 		config:        newConfigTabModel(client),
 		auth:          newAuthTabModel(client),
 		keys:          newKeysTabModel(client),
 		oauth:         newOAuthTabModel(client),
+		requests:      newRequestsTabModel(client),
+		quota:         newQuotaTabModel(client),
 		logs:          newLogsTabModel(client, hook),
 		client:        client,
-		initialized: [6]bool{
+		initialized: [9]bool{
 			tabDashboard: true,
 			tabLogs:      true,
 		},
@@ -89,7 +98,7 @@ func NewApp(port int, secretKey string, hook *LogHook) App {
 
 	app.refreshTabs()
 	if authRequired {
-		app.initialized = [6]bool{}
+		app.initialized = [9]bool{}
 	}
 	app.setAuthInputPrompt()
 	return app
@@ -121,10 +130,13 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		contentW := a.width
 		a.dashboard.SetSize(contentW, contentH)
+		a.usage.SetSize(contentW, contentH)
 		a.config.SetSize(contentW, contentH)
 		a.auth.SetSize(contentW, contentH)
 		a.keys.SetSize(contentW, contentH)
 		a.oauth.SetSize(contentW, contentH)
+		a.requests.SetSize(contentW, contentH)
+		a.quota.SetSize(contentW, contentH)
 		a.logs.SetSize(contentW, contentH)
 		return a, nil
 
@@ -138,7 +150,7 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.authenticated = true
 		a.logsEnabled = a.standalone || isLogsEnabledFromConfig(msg.cfg)
 		a.refreshTabs()
-		a.initialized = [6]bool{}
+		a.initialized = [9]bool{}
 		a.initialized[tabDashboard] = true
 		cmds := []tea.Cmd{a.dashboard.Init()}
 		if a.logsEnabled {
@@ -178,6 +190,11 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, cmdLogs
 
 	case tea.KeyMsg:
+		if a.authenticated && a.activeTab == tabRequests && a.requests.capturingInput() && msg.String() != "ctrl+c" {
+			var cmd tea.Cmd
+			a.requests, cmd = a.requests.Update(msg)
+			return a, cmd
+		}
 		if !a.authenticated {
 			switch msg.String() {
 			case "ctrl+c", "q":
@@ -246,6 +263,8 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch a.activeTab {
 	case tabDashboard:
 		a.dashboard, cmd = a.dashboard.Update(msg)
+	case tabUsage:
+		a.usage, cmd = a.usage.Update(msg)
 	case tabConfig:
 		a.config, cmd = a.config.Update(msg)
 	case tabAuthFiles:
@@ -254,6 +273,10 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.keys, cmd = a.keys.Update(msg)
 	case tabOAuth:
 		a.oauth, cmd = a.oauth.Update(msg)
+	case tabRequests:
+		a.requests, cmd = a.requests.Update(msg)
+	case tabQuota:
+		a.quota, cmd = a.quota.Update(msg)
 	case tabLogs:
 		a.logs, cmd = a.logs.Update(msg)
 	}
@@ -270,6 +293,42 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Keep the usage stream alive once the Usage tab has been opened.
+	if a.activeTab != tabUsage {
+		switch msg.(type) {
+		case usagePollMsg, usageCredentialsMsg:
+			var usageCmd tea.Cmd
+			a.usage, usageCmd = a.usage.Update(msg)
+			if usageCmd != nil {
+				cmd = tea.Batch(cmd, usageCmd)
+			}
+		}
+	}
+
+	// Keep the request log tail alive once the Requests tab has been opened.
+	if a.activeTab != tabRequests {
+		switch msg.(type) {
+		case requestLogsPollMsg, requestLogsTickMsg, requestLogDetailMsg:
+			var requestsCmd tea.Cmd
+			a.requests, requestsCmd = a.requests.Update(msg)
+			if requestsCmd != nil {
+				cmd = tea.Batch(cmd, requestsCmd)
+			}
+		}
+	}
+
+	// Keep quota polling alive once the Quota tab has been opened.
+	if a.activeTab != tabQuota {
+		switch msg.(type) {
+		case quotaTickMsg, quotaWindowsMsg, quotaRefreshMsg:
+			var quotaCmd tea.Cmd
+			a.quota, quotaCmd = a.quota.Update(msg)
+			if quotaCmd != nil {
+				cmd = tea.Batch(cmd, quotaCmd)
+			}
+		}
+	}
+
 	return a, cmd
 }
 
@@ -308,6 +367,8 @@ func (a *App) initTabIfNeeded(_ int) tea.Cmd {
 	switch a.activeTab {
 	case tabDashboard:
 		return a.dashboard.Init()
+	case tabUsage:
+		return a.usage.Init()
 	case tabConfig:
 		return a.config.Init()
 	case tabAuthFiles:
@@ -316,6 +377,10 @@ func (a *App) initTabIfNeeded(_ int) tea.Cmd {
 		return a.keys.Init()
 	case tabOAuth:
 		return a.oauth.Init()
+	case tabRequests:
+		return a.requests.Init()
+	case tabQuota:
+		return a.quota.Init()
 	case tabLogs:
 		if !a.logsEnabled {
 			return nil
@@ -344,6 +409,8 @@ func (a App) View() string {
 	switch a.activeTab {
 	case tabDashboard:
 		sb.WriteString(a.dashboard.View())
+	case tabUsage:
+		sb.WriteString(a.usage.View())
 	case tabConfig:
 		sb.WriteString(a.config.View())
 	case tabAuthFiles:
@@ -352,6 +419,10 @@ func (a App) View() string {
 		sb.WriteString(a.keys.View())
 	case tabOAuth:
 		sb.WriteString(a.oauth.View())
+	case tabRequests:
+		sb.WriteString(a.requests.View())
+	case tabQuota:
+		sb.WriteString(a.quota.View())
 	case tabLogs:
 		if a.logsEnabled {
 			sb.WriteString(a.logs.View())
@@ -503,6 +574,10 @@ func (a App) broadcastToAllTabs(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if cmd != nil {
 		cmds = append(cmds, cmd)
 	}
+	a.usage, cmd = a.usage.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
 	a.config, cmd = a.config.Update(msg)
 	if cmd != nil {
 		cmds = append(cmds, cmd)
@@ -519,6 +594,14 @@ func (a App) broadcastToAllTabs(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if cmd != nil {
 		cmds = append(cmds, cmd)
 	}
+	a.requests, cmd = a.requests.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	a.quota, cmd = a.quota.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
 	a.logs, cmd = a.logs.Update(msg)
 	if cmd != nil {
 		cmds = append(cmds, cmd)
@@ -199,6 +199,9 @@ func (m authTabModel) renderContent() string {
 		if disabled {
 			statusIcon = lipgloss.NewStyle().Foreground(colorMuted).Render("○")
 			statusText = T("status_disabled")
+		} else if getString(f, "status") == "draining" {
+			statusIcon = warningStyle.Render("◐")
+			statusText = T("status_draining")
 		}
 
 		cursor := "  "
@@ -275,6 +278,7 @@ func (m authTabModel) renderDetail(f map[string]any) string {
 		{"Email", "email", false},
 		{"Status", "status", false},
 		{"Status Msg", "status_message", false},
+		{"Draining Until", "draining_until", false},
 		{"File Name", "file_name", false},
 		{"Auth Type", "auth_type", false},
 		{"Prefix", "prefix", true},
@@ -439,6 +443,17 @@ func (m authTabModel) handleNormalInput(msg tea.KeyMsg) (authTabModel, tea.Cmd)
 			}
 		}
 		return m, nil
+	case "n", "N":
+		if m.cursor < len(m.files) {
+			name := getString(m.files[m.cursor], "name")
+			return m, func() tea.Msg {
+				if err := m.client.DrainAuthFile(name); err != nil {
+					return authActionMsg{err: err}
+				}
+				return authActionMsg{action: fmt.Sprintf("%s %s", T("draining"), name)}
+			}
+		}
+		return m, nil
 	case "1":
 		return m, m.startEdit(0) // prefix
 	case "2":
@@ -1,7 +1,10 @@
 package tui
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -172,6 +175,13 @@ func (c *Client) ToggleAuthFile(name string, disabled bool) error {
 	return err
 }
 
+// DrainAuthFile starts draining an auth file with the server's default timeout.
+func (c *Client) DrainAuthFile(name string) error {
+	body, _ := json.Marshal(map[string]any{"name": name, "status": "draining"})
+	_, err := c.patch("/v0/management/auth-files/status", strings.NewReader(string(body)))
+	return err
+}
+
 // PatchAuthFileFields updates editable fields on an auth file.
 func (c *Client) PatchAuthFileFields(name string, fields map[string]any) error {
 	fields["name"] = name
@@ -180,6 +190,75 @@ func (c *Client) PatchAuthFileFields(name string, fields map[string]any) error {
 	return err
 }
 
+// streamEvent is one event read from the management event stream.
+type streamEvent struct {
+	ID   uint64          `json:"id"`
+	Type string          `json:"type"`
+	Time time.Time       `json:"time"`
+	Data json.RawMessage `json:"data"`
+}
+
+// errEventStreamLagged reports that the server closed the stream because the
+// client fell behind. Reconnecting after the last event ID resumes it.
+var errEventStreamLagged = errors.New("event stream lagged")
+
+// StreamEvents follows the management event stream for types, starting after
+// lastEventID (0 replays every retained event), and calls onEvent for each
+// event until ctx is done or the server closes the stream. Unlike the usage
+// queue, reading the stream removes nothing for other consumers.
+func (c *Client) StreamEvents(ctx context.Context, types string, lastEventID uint64, onEvent func(streamEvent)) error {
+	query := url.Values{}
+	query.Set("types", types)
+	query.Set("last_event_id", strconv.FormatUint(lastEventID, 10))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/v0/management/events?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if c.secretKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.secretKey)
+	}
+	// The stream stays open, so it must not inherit the request timeout.
+	streamClient := &http.Client{Transport: c.http.Transport}
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	var eventType string
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			switch eventType {
+			case "stream.lagged":
+				return errEventStreamLagged
+			case "stream.gap", "":
+			default:
+				var event streamEvent
+				if errUnmarshal := json.Unmarshal([]byte(data.String()), &event); errUnmarshal == nil {
+					onEvent(event)
+				}
+			}
+			eventType = ""
+			data.Reset()
+		case strings.HasPrefix(line, "event:"):
+			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data.WriteString(strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		}
+	}
+	return scanner.Err()
+}
+
 // GetLogs fetches log lines from the server.
 func (c *Client) GetLogs(after int64, limit int) ([]string, int64, error) {
 	query := url.Values{}
@@ -234,6 +313,83 @@ func (c *Client) GetLogs(after int64, limit int) ([]string, int64, error) {
 	return lines, latest, nil
 }
 
+// GetRequestLogs fetches request log summaries modified after the given unix
+// millisecond timestamp, newest first, and the latest modification time seen.
+func (c *Client) GetRequestLogs(after int64, limit int) ([]requestLogEntry, int64, bool, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if after > 0 {
+		query.Set("after", strconv.FormatInt(after, 10))
+	}
+	path := "/v0/management/request-logs"
+	if encodedQuery := query.Encode(); encodedQuery != "" {
+		path += "?" + encodedQuery
+	}
+	data, err := c.get(path)
+	if err != nil {
+		return nil, after, false, err
+	}
+	var wrapper struct {
+		Logs    []requestLogEntry `json:"logs"`
+		Latest  int64             `json:"latest"`
+		HasMore bool              `json:"has_more"`
+	}
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return nil, after, false, err
+	}
+	if wrapper.Latest < after {
+		wrapper.Latest = after
+	}
+	return wrapper.Logs, wrapper.Latest, wrapper.HasMore, nil
+}
+
+// GetRequestLogByID downloads the raw request log recorded for a request ID.
+func (c *Client) GetRequestLogByID(id string) (string, error) {
+	data, err := c.get("/v0/management/request-log-by-id/" + url.PathEscape(id))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// GetQuotaWindows fetches per-credential rate-limit window usage.
+// API returns {"credentials": [...]}.
+func (c *Client) GetQuotaWindows() ([]quotaCredential, error) {
+	data, err := c.get("/v0/management/quota-windows")
+	if err != nil {
+		return nil, err
+	}
+	var wrapper struct {
+		Credentials []quotaCredential `json:"credentials"`
+	}
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return nil, err
+	}
+	return wrapper.Credentials, nil
+}
+
+// RefreshQuotaWindows queries provider usage endpoints for every supported
+// credential and returns how many succeeded plus the failures by auth index.
+func (c *Client) RefreshQuotaWindows() (int, map[string]string, error) {
+	data, code, err := c.doRequest("POST", "/v0/management/quota-windows/refresh", strings.NewReader("{}"))
+	if err != nil {
+		return 0, nil, err
+	}
+	if code >= 400 {
+		return 0, nil, fmt.Errorf("HTTP %d: %s", code, strings.TrimSpace(string(data)))
+	}
+	var wrapper struct {
+		Refreshed []string          `json:"refreshed"`
+		Errors    map[string]string `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return 0, nil, err
+	}
+	return len(wrapper.Refreshed), wrapper.Errors, nil
+}
+
 // GetAPIKeys fetches the list of API keys.
 // API returns {"api-keys": [...]}.
 func (c *Client) GetAPIKeys() ([]string, error) {
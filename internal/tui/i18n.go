@@ -50,8 +50,8 @@ var locales = map[string]map[string]string{
 // ──────────────────────────────────────────
 // Tab names
 // ──────────────────────────────────────────
-var zhTabNames = []string{"仪表盘", "配置", "认证文件", "API 密钥", "OAuth", "日志"}
-var enTabNames = []string{"Dashboard", "Config", "Auth Files", "API Keys", "OAuth", "Logs"}
+var zhTabNames = []string{"仪表盘", "用量", "配置", "认证文件", "API 密钥", "OAuth", "请求", "配额", "日志"}
+var enTabNames = []string{"Dashboard", "Usage", "Config", "Auth Files", "API Keys", "OAuth", "Requests", "Quota", "Logs"}
 
 // TabNames returns tab names in the current locale.
 func TabNames() []string {
@@ -136,7 +136,7 @@ var zhStrings = map[string]string{
 	// ── Auth Files ──
 	"auth_title":      "🔑 认证文件",
 	"auth_help1":      " [↑↓/jk] 导航 • [Enter] 展开 • [e] 启用/停用 • [d] 删除 • [r] 刷新",
-	"auth_help2":      " [1] 编辑 prefix • [2] 编辑 proxy_url • [3] 编辑 priority",
+	"auth_help2":      " [1] 编辑 prefix • [2] 编辑 proxy_url • [3] 编辑 priority • [n] 排空",
 	"no_auth_files":   "  无认证文件",
 	"confirm_delete":  "⚠ 删除 %s? [y/n]",
 	"deleted":         "已删除 %s",
@@ -145,6 +145,8 @@ var zhStrings = map[string]string{
 	"updated_field":   "已更新 %s 的 %s",
 	"status_active":   "活跃",
 	"status_disabled": "已停用",
+	"status_draining": "排空中",
+	"draining":        "开始排空",
 
 	// ── API Keys ──
 	"keys_title":         "🔐 API 密钥",
@@ -186,25 +188,62 @@ var zhStrings = map[string]string{
 	"oauth_device_expires": "  设备码将在 %d 秒后过期。",
 
 	// ── Usage ──
-	"usage_title":         "📈 使用统计",
-	"usage_help":          " [r] 刷新 • [↑↓] 滚动",
-	"usage_no_data":       "  使用数据不可用",
-	"usage_total_reqs":    "总请求数",
-	"usage_total_tokens":  "总 Token 数",
-	"usage_success":       "成功",
-	"usage_failure":       "失败",
-	"usage_total_token_l": "总Token",
-	"usage_rpm":           "RPM",
-	"usage_tpm":           "TPM",
-	"usage_req_by_hour":   "请求趋势 (按小时)",
-	"usage_tok_by_hour":   "Token 使用趋势 (按小时)",
-	"usage_req_by_day":    "请求趋势 (按天)",
-	"usage_api_detail":    "API 详细统计",
-	"usage_input":         "输入",
-	"usage_output":        "输出",
-	"usage_cached":        "缓存",
-	"usage_reasoning":     "思考",
-	"usage_time":          "时间",
+	"usage_title":             "📈 用量统计",
+	"usage_help":              " [1-4/w] 时间窗口 • [m] 按模型 • [c] 按凭证 • [Enter] 查看模型凭证 • [Esc] 返回 • [↑↓] 选择 • [r] 刷新凭证名",
+	"usage_no_data":           "  所选时间窗口内暂无用量数据",
+	"usage_queue_hint":        "  数据来自管理事件流中的 request.completed 事件，打开本页时会回放服务器仍保留的最近事件。",
+	"usage_window":            "时间窗口",
+	"usage_total_reqs":        "总请求数",
+	"usage_total_tokens":      "总 Token 数",
+	"usage_error_rate":        "错误率",
+	"usage_rpm":               "RPM",
+	"usage_tpm":               "TPM",
+	"usage_by_model":          "按模型",
+	"usage_by_credential":     "按凭证",
+	"usage_model_credentials": "%s 的凭证",
+	"usage_col_name":          "名称",
+	"usage_col_requests":      "请求",
+	"usage_col_tokens":        "Token",
+	"usage_col_errors":        "错误率",
+	"usage_col_trend":         "趋势",
+
+	// ── Requests ──
+	"requests_title":                    "🔎 请求日志",
+	"requests_help":                     " [/] 过滤 • [e] 仅错误 • [x] 清除过滤 • [a] 实时/暂停 • [Enter] 详情 • [↑↓] 选择 • [r] 刷新",
+	"requests_live":                     "● 实时",
+	"requests_paused":                   "○ 已暂停",
+	"requests_scope_all":                "全部",
+	"requests_scope_errors":             "仅错误",
+	"requests_count":                    "条数",
+	"requests_empty":                    "  暂无请求日志（需开启 request-log，或请求出错时才会记录）",
+	"requests_col_time":                 "时间",
+	"requests_col_status":               "状态",
+	"requests_col_method":               "方法",
+	"requests_col_path":                 "路径",
+	"requests_col_model":                "模型",
+	"requests_col_credential":           "凭证",
+	"requests_col_latency":              "耗时",
+	"requests_detail_title":             "🔎 请求 %s",
+	"requests_detail_help":              " [1-5/←→] 切换面板 • [y] 复制面板 • [Y] 复制完整日志 • [r] 重新加载 • [Esc] 返回 • [↑↓] 滚动",
+	"requests_pane_downstream_request":  "下游请求",
+	"requests_pane_downstream_response": "下游响应",
+	"requests_pane_upstream_request":    "上游请求",
+	"requests_pane_upstream_response":   "上游响应",
+	"requests_pane_websocket":           "WebSocket 时间线",
+	"requests_pane_empty":               "  此面板无记录内容",
+
+	// ── Quota ──
+	"quota_title":           "⏳ 配额窗口",
+	"quota_help":            " [u] 查询提供商用量接口 • [r] 重新加载 • [↑↓] 滚动",
+	"quota_no_data":         "  尚未观测到限流窗口",
+	"quota_never_observed":  "尚未观测到窗口；按 [u] 查询用量接口",
+	"quota_used":            "已用",
+	"quota_resets_in":       "%s 后重置",
+	"quota_burn":            "%.1f%%/小时",
+	"quota_exhausts_in":     "预计 %s 后耗尽",
+	"quota_cooling":         "冷却中，剩余 %s",
+	"quota_refreshed":       "已查询 %d 个用量接口",
+	"quota_refresh_partial": "⚠ 已查询 %d 个用量接口，%d 个失败",
 
 	// ── Logs ──
 	"logs_title":       "📋 日志",
@@ -291,7 +330,7 @@ var enStrings = map[string]string{
 	// ── Auth Files ──
 	"auth_title":      "🔑 Auth Files",
 	"auth_help1":      " [↑↓/jk] Navigate • [Enter] Expand • [e] Enable/Disable • [d] Delete • [r] Refresh",
-	"auth_help2":      " [1] Edit prefix • [2] Edit proxy_url • [3] Edit priority",
+	"auth_help2":      " [1] Edit prefix • [2] Edit proxy_url • [3] Edit priority • [n] Drain",
 	"no_auth_files":   "  No auth files found",
 	"confirm_delete":  "⚠ Delete %s? [y/n]",
 	"deleted":         "Deleted %s",
@@ -300,6 +339,8 @@ var enStrings = map[string]string{
 	"updated_field":   "Updated %s on %s",
 	"status_active":   "active",
 	"status_disabled": "disabled",
+	"status_draining": "draining",
+	"draining":        "Draining",
 
 	// ── API Keys ──
 	"keys_title":         "🔐 API Keys",
@@ -341,25 +382,62 @@ var enStrings = map[string]string{
 	"oauth_device_expires": "  Device code expires in %d seconds.",
 
 	// ── Usage ──
-	"usage_title":         "📈 Usage Statistics",
-	"usage_help":          " [r] Refresh • [↑↓] Scroll",
-	"usage_no_data":       "  Usage data not available",
-	"usage_total_reqs":    "Total Requests",
-	"usage_total_tokens":  "Total Tokens",
-	"usage_success":       "Success",
-	"usage_failure":       "Failed",
-	"usage_total_token_l": "Total Tokens",
-	"usage_rpm":           "RPM",
-	"usage_tpm":           "TPM",
-	"usage_req_by_hour":   "Requests by Hour",
-	"usage_tok_by_hour":   "Token Usage by Hour",
-	"usage_req_by_day":    "Requests by Day",
-	"usage_api_detail":    "API Detail Statistics",
-	"usage_input":         "Input",
-	"usage_output":        "Output",
-	"usage_cached":        "Cached",
-	"usage_reasoning":     "Reasoning",
-	"usage_time":          "Time",
+	"usage_title":             "📈 Usage",
+	"usage_help":              " [1-4/w] Window • [m] By model • [c] By credential • [Enter] Model credentials • [Esc] Back • [↑↓] Select • [r] Reload credential names",
+	"usage_no_data":           "  No usage recorded in the selected window",
+	"usage_queue_hint":        "  Data comes from request.completed events on the management event stream; opening this tab replays the recent events the server still retains.",
+	"usage_window":            "Window",
+	"usage_total_reqs":        "Total Requests",
+	"usage_total_tokens":      "Total Tokens",
+	"usage_error_rate":        "Error Rate",
+	"usage_rpm":               "RPM",
+	"usage_tpm":               "TPM",
+	"usage_by_model":          "By Model",
+	"usage_by_credential":     "By Credential",
+	"usage_model_credentials": "Credentials serving %s",
+	"usage_col_name":          "Name",
+	"usage_col_requests":      "Requests",
+	"usage_col_tokens":        "Tokens",
+	"usage_col_errors":        "Errors",
+	"usage_col_trend":         "Trend",
+
+	// ── Requests ──
+	"requests_title":                    "🔎 Request Logs",
+	"requests_help":                     " [/] Filter • [e] Errors only • [x] Clear filters • [a] Live/pause • [Enter] Details • [↑↓] Select • [r] Refresh",
+	"requests_live":                     "● LIVE",
+	"requests_paused":                   "○ PAUSED",
+	"requests_scope_all":                "All",
+	"requests_scope_errors":             "Errors only",
+	"requests_count":                    "Count",
+	"requests_empty":                    "  No request logs yet (enable request-log, or wait for a failed request)",
+	"requests_col_time":                 "Time",
+	"requests_col_status":               "Status",
+	"requests_col_method":               "Method",
+	"requests_col_path":                 "Path",
+	"requests_col_model":                "Model",
+	"requests_col_credential":           "Credential",
+	"requests_col_latency":              "Latency",
+	"requests_detail_title":             "🔎 Request %s",
+	"requests_detail_help":              " [1-5/←→] Pane • [y] Copy pane • [Y] Copy full log • [r] Reload • [Esc] Back • [↑↓] Scroll",
+	"requests_pane_downstream_request":  "Downstream request",
+	"requests_pane_downstream_response": "Downstream response",
+	"requests_pane_upstream_request":    "Upstream request",
+	"requests_pane_upstream_response":   "Upstream response",
+	"requests_pane_websocket":           "WebSocket timelines",
+	"requests_pane_empty":               "  Nothing recorded for this pane",
+
+	// ── Quota ──
+	"quota_title":           "⏳ Quota Windows",
+	"quota_help":            " [u] Query provider usage endpoints • [r] Reload • [↑↓] Scroll",
+	"quota_no_data":         "  No rate-limit windows observed yet",
+	"quota_never_observed":  "No windows observed yet; press [u] to query the usage endpoint",
+	"quota_used":            "used",
+	"quota_resets_in":       "resets in %s",
+	"quota_burn":            "%.1f%%/h",
+	"quota_exhausts_in":     "runs out in %s",
+	"quota_cooling":         "cooling down for %s",
+	"quota_refreshed":       "Queried %d usage endpoints",
+	"quota_refresh_partial": "⚠ Queried %d usage endpoints, %d failed",
 
 	// ── Logs ──
 	"logs_title":       "📋 Logs",
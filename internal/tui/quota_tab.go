@@ -0,0 +1,272 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	quotaPollInterval = 30 * time.Second
+	quotaBarWidth     = 20
+)
+
+// quotaCredential mirrors one entry of GET /v0/management/quota-windows.
+type quotaCredential struct {
+	AuthIndex     string        `json:"auth_index"`
+	ID            string        `json:"id"`
+	Name          string        `json:"name"`
+	Provider      string        `json:"provider"`
+	Label         string        `json:"label"`
+	Email         string        `json:"email"`
+	StatusMessage string        `json:"status_message"`
+	Unavailable   bool          `json:"unavailable"`
+	NextRetryAt   *time.Time    `json:"next_retry_at"`
+	UsageEndpoint bool          `json:"usage_endpoint"`
+	ObservedAt    *time.Time    `json:"observed_at"`
+	Windows       []quotaWindow `json:"windows"`
+	ExhaustsAt    *time.Time    `json:"exhausts_at"`
+}
+
+type quotaWindow struct {
+	Name              string    `json:"name"`
+	Model             string    `json:"model"`
+	Limit             int64     `json:"limit"`
+	Remaining         int64     `json:"remaining"`
+	RemainingFraction float64   `json:"remaining_fraction"`
+	ResetAt           time.Time `json:"reset_at"`
+	Source            string    `json:"source"`
+	BurnRate          float64   `json:"burn_rate"`
+	ExhaustsAt        time.Time `json:"exhausts_at"`
+}
+
+// quotaTabModel shows per-credential rate-limit window usage with predicted
+// exhaustion for subscription OAuth accounts.
+type quotaTabModel struct {
+	client      *Client
+	viewport    viewport.Model
+	credentials []quotaCredential
+	err         error
+	status      string
+	width       int
+	height      int
+	ready       bool
+	now         func() time.Time
+}
+
+type quotaWindowsMsg struct {
+	credentials []quotaCredential
+	err         error
+}
+
+type quotaTickMsg struct{}
+
+type quotaRefreshMsg struct {
+	refreshed int
+	failures  map[string]string
+	err       error
+}
+
+func newQuotaTabModel(client *Client) quotaTabModel {
+	return quotaTabModel{client: client, now: time.Now}
+}
+
+func (m quotaTabModel) Init() tea.Cmd {
+	return tea.Batch(m.fetchWindows, m.waitForNextPoll())
+}
+
+func (m quotaTabModel) fetchWindows() tea.Msg {
+	credentials, err := m.client.GetQuotaWindows()
+	return quotaWindowsMsg{credentials: credentials, err: err}
+}
+
+func (m quotaTabModel) refreshEndpoints() tea.Msg {
+	refreshed, failures, err := m.client.RefreshQuotaWindows()
+	return quotaRefreshMsg{refreshed: refreshed, failures: failures, err: err}
+}
+
+func (m quotaTabModel) waitForNextPoll() tea.Cmd {
+	return tea.Tick(quotaPollInterval, func(_ time.Time) tea.Msg {
+		return quotaTickMsg{}
+	})
+}
+
+func (m quotaTabModel) Update(msg tea.Msg) (quotaTabModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case localeChangedMsg:
+		m.viewport.SetContent(m.renderContent())
+		return m, nil
+	case quotaTickMsg:
+		return m, tea.Batch(m.fetchWindows, m.waitForNextPoll())
+	case quotaWindowsMsg:
+		if msg.err != nil {
+			m.err = msg.err
+		} else {
+			m.err = nil
+			m.credentials = msg.credentials
+		}
+		m.viewport.SetContent(m.renderContent())
+		return m, nil
+	case quotaRefreshMsg:
+		switch {
+		case msg.err != nil:
+			m.status = errorStyle.Render("✗ " + msg.err.Error())
+		case len(msg.failures) > 0:
+			m.status = warningStyle.Render(fmt.Sprintf(T("quota_refresh_partial"), msg.refreshed, len(msg.failures)))
+		default:
+			m.status = successStyle.Render(fmt.Sprintf("✓ "+T("quota_refreshed"), msg.refreshed))
+		}
+		m.viewport.SetContent(m.renderContent())
+		return m, m.fetchWindows
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "r":
+			m.status = ""
+			return m, m.fetchWindows
+		case "u":
+			m.status = T("loading")
+			m.viewport.SetContent(m.renderContent())
+			return m, m.refreshEndpoints
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m *quotaTabModel) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	if !m.ready {
+		m.viewport = viewport.New(w, h)
+		m.viewport.SetContent(m.renderContent())
+		m.ready = true
+	} else {
+		m.viewport.Width = w
+		m.viewport.Height = h
+	}
+}
+
+func (m quotaTabModel) View() string {
+	if !m.ready {
+		return T("loading")
+	}
+	return m.viewport.View()
+}
+
+func (m quotaTabModel) renderContent() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render(T("quota_title")))
+	sb.WriteString("\n")
+	sb.WriteString(helpStyle.Render(T("quota_help")))
+	sb.WriteString("\n")
+	sb.WriteString(strings.Repeat("─", m.width))
+	sb.WriteString("\n")
+	if m.status != "" {
+		sb.WriteString(m.status)
+		sb.WriteString("\n")
+	}
+	if m.err != nil {
+		sb.WriteString(errorStyle.Render(T("error_prefix") + m.err.Error()))
+		sb.WriteString("\n")
+		return sb.String()
+	}
+	if len(m.credentials) == 0 {
+		sb.WriteString(subtitleStyle.Render(T("quota_no_data")))
+		sb.WriteString("\n")
+		return sb.String()
+	}
+	now := m.now()
+	for _, credential := range m.credentials {
+		sb.WriteString(renderQuotaCredential(credential, now))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// renderQuotaCredential renders one credential header followed by a usage bar
+// per window.
+func renderQuotaCredential(credential quotaCredential, now time.Time) string {
+	var sb strings.Builder
+	name := credential.Email
+	if name == "" {
+		name = credential.Label
+	}
+	if name == "" {
+		name = credential.Name
+	}
+	if name == "" {
+		name = credential.ID
+	}
+	sb.WriteString(labelStyle.Render(fmt.Sprintf("%s · %s", credential.Provider, name)))
+	if credential.Unavailable && credential.NextRetryAt != nil {
+		sb.WriteString(" ")
+		sb.WriteString(warningStyle.Render(fmt.Sprintf(T("quota_cooling"), formatQuotaDuration(credential.NextRetryAt.Sub(now)))))
+	}
+	sb.WriteString("\n")
+	if len(credential.Windows) == 0 {
+		sb.WriteString(helpStyle.Render("    " + T("quota_never_observed")))
+		sb.WriteString("\n")
+		return sb.String()
+	}
+	for _, window := range credential.Windows {
+		used := 1 - window.RemainingFraction
+		name := window.Name
+		if window.Model != "" {
+			name += " · " + window.Model
+		}
+		line := fmt.Sprintf("    %-18s %s %5.1f%% %s", name, quotaBar(used), used*100, T("quota_used"))
+		if !window.ResetAt.IsZero() {
+			line += " • " + fmt.Sprintf(T("quota_resets_in"), formatQuotaDuration(window.ResetAt.Sub(now)))
+		}
+		if window.BurnRate > 0 {
+			line += " • " + fmt.Sprintf(T("quota_burn"), window.BurnRate*100)
+		}
+		switch {
+		case window.RemainingFraction <= 0:
+			sb.WriteString(errorStyle.Render(line))
+		case !window.ExhaustsAt.IsZero():
+			sb.WriteString(warningStyle.Render(line + " • " + fmt.Sprintf(T("quota_exhausts_in"), formatQuotaDuration(window.ExhaustsAt.Sub(now)))))
+		default:
+			sb.WriteString(valueStyle.Render(line))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func quotaBar(used float64) string {
+	if used < 0 {
+		used = 0
+	} else if used > 1 {
+		used = 1
+	}
+	filled := int(used*quotaBarWidth + 0.5)
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", quotaBarWidth-filled) + "]"
+}
+
+func formatQuotaDuration(d time.Duration) string {
+	if d <= 0 {
+		return "0m"
+	}
+	d = d.Round(time.Minute)
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	hours := d / time.Hour
+	minutes := (d - hours*time.Hour) / time.Minute
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh%02dm", hours, minutes)
+	default:
+		if minutes < 1 {
+			minutes = 1
+		}
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatQuotaDuration(t *testing.T) {
+	cases := map[time.Duration]string{
+		-time.Minute:                  "0m",
+		20 * time.Second:              "1m",
+		42 * time.Minute:              "42m",
+		2*time.Hour + 5*time.Minute:   "2h05m",
+		50*time.Hour + 10*time.Minute: "2d2h",
+	}
+	for in, want := range cases {
+		if got := formatQuotaDuration(in); got != want {
+			t.Fatalf("formatQuotaDuration(%s) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRenderQuotaCredentialShowsUsageAndPrediction(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	rendered := renderQuotaCredential(quotaCredential{
+		Provider: "codex",
+		ID:       "codex-1",
+		Email:    "dev@example.com",
+		Windows: []quotaWindow{{
+			Name:              "codex-primary",
+			RemainingFraction: 0.25,
+			ResetAt:           now.Add(3 * time.Hour),
+			BurnRate:          0.5,
+			ExhaustsAt:        now.Add(30 * time.Minute),
+		}},
+	}, now)
+	for _, want := range []string{"dev@example.com", "codex-primary", "75.0%", "3h00m", "30m"} {
+		if !strings.Contains(rendered, want) {
+			t.Fatalf("rendered output missing %q:\n%s", want, rendered)
+		}
+	}
+	if quotaBar(2) != "["+strings.Repeat("█", quotaBarWidth)+"]" {
+		t.Fatalf("quotaBar(2) = %q, want a full bar", quotaBar(2))
+	}
+}
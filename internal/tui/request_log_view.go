@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/logging"
+)
+
+// requestLogEntry mirrors one item returned by the management request-logs listing.
+type requestLogEntry struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	URL        string    `json:"url"`
+	Method     string    `json:"method"`
+	Status     int       `json:"status"`
+	Model      string    `json:"model"`
+	Provider   string    `json:"provider"`
+	Credential string    `json:"credential"`
+	Timestamp  time.Time `json:"timestamp"`
+	LatencyMs  int64     `json:"latency_ms"`
+	Size       int64     `json:"size"`
+	Modified   time.Time `json:"modified"`
+	Error      bool      `json:"error"`
+}
+
+// failed reports whether the request ended with an error status or was written
+// as a forced error log.
+func (e requestLogEntry) failed() bool {
+	return e.Error || e.Status >= 400
+}
+
+// matches reports whether every space-separated term in filter occurs in one of
+// the entry's displayed fields. Matching is case-insensitive.
+func (e requestLogEntry) matches(filter string) bool {
+	filter = strings.ToLower(strings.TrimSpace(filter))
+	if filter == "" {
+		return true
+	}
+	haystack := strings.ToLower(strings.Join([]string{
+		e.ID, e.Method, e.URL, e.Model, e.Provider, e.Credential, strconv.Itoa(e.Status),
+	}, " "))
+	for _, term := range strings.Fields(filter) {
+		if !strings.Contains(haystack, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// Detail panes, in display order.
+const (
+	requestPaneDownstreamRequest = iota
+	requestPaneDownstreamResponse
+	requestPaneUpstreamRequest
+	requestPaneUpstreamResponse
+	requestPaneWebsocket
+	requestPaneCount
+)
+
+// requestLogPane maps a section name to the detail pane that shows it.
+func requestLogPane(name string) int {
+	switch {
+	case name == "REQUEST INFO" || name == "HEADERS" || name == "REQUEST BODY":
+		return requestPaneDownstreamRequest
+	case name == "RESPONSE":
+		return requestPaneDownstreamResponse
+	case strings.HasSuffix(name, "WEBSOCKET TIMELINE"):
+		return requestPaneWebsocket
+	case strings.HasPrefix(name, "API REQUEST"):
+		return requestPaneUpstreamRequest
+	case strings.HasPrefix(name, "API RESPONSE") || name == "API ERROR RESPONSE":
+		return requestPaneUpstreamResponse
+	default:
+		return requestPaneDownstreamRequest
+	}
+}
+
+// buildRequestLogPanes groups sections into detail panes. Each section keeps its
+// heading and JSON payloads are pretty-printed.
+func buildRequestLogPanes(raw string) [requestPaneCount]string {
+	var builders [requestPaneCount]strings.Builder
+	for _, section := range logging.SplitRequestLogSections([]byte(raw)) {
+		sb := &builders[requestLogPane(section.Name)]
+		if sb.Len() > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString("=== " + section.Name + " ===")
+		if body := strings.Trim(strings.Join(section.Lines, "\n"), "\n"); body != "" {
+			sb.WriteString("\n")
+			sb.WriteString(prettyPrintJSONLines(body))
+		}
+	}
+	var panes [requestPaneCount]string
+	for i := range builders {
+		panes[i] = builders[i].String()
+	}
+	return panes
+}
+
+// prettyPrintJSONLines indents lines that hold a JSON object or array, including
+// SSE "data:" lines. Other lines are kept as-is.
+func prettyPrintJSONLines(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		prefix, payload := "", strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(payload, "data:"); ok {
+			prefix, payload = "data: ", strings.TrimSpace(rest)
+		}
+		if payload == "" || (payload[0] != '{' && payload[0] != '[') {
+			continue
+		}
+		var out bytes.Buffer
+		if json.Indent(&out, []byte(payload), "", "  ") != nil {
+			continue
+		}
+		lines[i] = prefix + out.String()
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleTUIRequestLog = `=== REQUEST INFO ===
+URL: /v1/responses
+Method: POST
+
+=== HEADERS ===
+Content-Type: application/json
+
+=== REQUEST BODY ===
+{"model":"gpt-5"}
+
+=== WEBSOCKET TIMELINE ===
+Event: websocket.request
+
+=== API REQUEST ===
+=== API REQUEST 1 ===
+Body:
+{"input":[1,2]}
+
+=== API ERROR RESPONSE ===
+HTTP Status: 429
+quota exceeded
+
+=== API RESPONSE ===
+=== API RESPONSE 1 ===
+data: {"type":"response.completed"}
+
+=== RESPONSE ===
+Status: 200
+`
+
+func TestBuildRequestLogPanesGroupsAndPrettyPrints(t *testing.T) {
+	panes := buildRequestLogPanes(sampleTUIRequestLog)
+	if !strings.Contains(panes[requestPaneDownstreamRequest], "{\n  \"model\": \"gpt-5\"\n}") {
+		t.Fatalf("downstream request not pretty-printed:\n%s", panes[requestPaneDownstreamRequest])
+	}
+	if !strings.Contains(panes[requestPaneUpstreamRequest], "=== API REQUEST 1 ===") {
+		t.Fatalf("upstream request pane = %q", panes[requestPaneUpstreamRequest])
+	}
+	upstream := panes[requestPaneUpstreamResponse]
+	if !strings.Contains(upstream, "quota exceeded") || !strings.Contains(upstream, "data: {\n  \"type\": \"response.completed\"\n}") {
+		t.Fatalf("upstream response pane = %q", upstream)
+	}
+	if !strings.HasPrefix(panes[requestPaneDownstreamResponse], "=== RESPONSE ===\nStatus: 200") {
+		t.Fatalf("downstream response pane = %q", panes[requestPaneDownstreamResponse])
+	}
+	if !strings.Contains(panes[requestPaneWebsocket], "websocket.request") {
+		t.Fatalf("websocket pane = %q", panes[requestPaneWebsocket])
+	}
+}
+
+func TestRequestLogEntryMatches(t *testing.T) {
+	entry := requestLogEntry{ID: "abc", Method: "POST", URL: "/v1/responses", Model: "gpt-5", Provider: "codex", Credential: "user@example.com", Status: 429}
+	for _, filter := range []string{"", "GPT-5", "codex 429", "user@ responses"} {
+		if !entry.matches(filter) {
+			t.Fatalf("matches(%q) = false", filter)
+		}
+	}
+	if entry.matches("claude") {
+		t.Fatal("matches(claude) = true")
+	}
+	if !entry.failed() {
+		t.Fatal("429 entry not reported as failed")
+	}
+}
+
+func TestRequestsTabMergeKeepsSelection(t *testing.T) {
+	m := newRequestsTabModel(nil)
+	m.merge([]requestLogEntry{{Name: "b", ID: "b"}, {Name: "a", ID: "a"}})
+	m.cursor = 1
+	m.merge([]requestLogEntry{{Name: "c", ID: "c"}, {Name: "b", ID: "b", Status: 200}})
+	if len(m.entries) != 3 || m.entries[0].Name != "c" || m.entries[1].Status != 200 {
+		t.Fatalf("entries = %+v", m.entries)
+	}
+	if got := m.visible()[m.cursor].Name; got != "a" {
+		t.Fatalf("selected = %q, want a", got)
+	}
+}
@@ -0,0 +1,553 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	requestsPollInterval = 2 * time.Second
+	requestsPollBatch    = 200
+	requestsMaxEntries   = 1000
+)
+
+// requestsTabModel lists recorded request logs, tails new ones and shows a
+// detail pane for the selected request.
+type requestsTabModel struct {
+	client   *Client
+	viewport viewport.Model
+	width    int
+	height   int
+	ready    bool
+
+	entries []requestLogEntry // newest first
+	after   int64
+	live    bool
+	lastErr error
+	cursor  int
+	status  string
+
+	errorsOnly  bool
+	filter      string
+	filterInput textinput.Model
+	filtering   bool
+
+	detail *requestLogDetail
+}
+
+// requestLogDetail holds the downloaded log shown in the detail pane.
+type requestLogDetail struct {
+	entry   requestLogEntry
+	raw     string
+	panes   [requestPaneCount]string
+	pane    int
+	loading bool
+	err     error
+}
+
+type requestLogsPollMsg struct {
+	entries []requestLogEntry
+	latest  int64
+	hasMore bool
+	err     error
+}
+
+type requestLogsTickMsg struct{}
+
+type requestLogDetailMsg struct {
+	id  string
+	raw string
+	err error
+}
+
+func newRequestsTabModel(client *Client) requestsTabModel {
+	ti := textinput.New()
+	ti.CharLimit = 128
+	ti.Prompt = "  / "
+	return requestsTabModel{
+		client:      client,
+		live:        true,
+		filterInput: ti,
+	}
+}
+
+func (m requestsTabModel) Init() tea.Cmd {
+	return m.fetchRequestLogs
+}
+
+func (m requestsTabModel) fetchRequestLogs() tea.Msg {
+	entries, latest, hasMore, err := m.client.GetRequestLogs(m.after, requestsPollBatch)
+	return requestLogsPollMsg{entries: entries, latest: latest, hasMore: hasMore, err: err}
+}
+
+func (m requestsTabModel) fetchDetail(id string) tea.Cmd {
+	return func() tea.Msg {
+		raw, err := m.client.GetRequestLogByID(id)
+		return requestLogDetailMsg{id: id, raw: raw, err: err}
+	}
+}
+
+func (m requestsTabModel) waitForNextPoll() tea.Cmd {
+	return tea.Tick(requestsPollInterval, func(_ time.Time) tea.Msg {
+		return requestLogsTickMsg{}
+	})
+}
+
+// capturingInput reports whether key presses should bypass global shortcuts.
+func (m requestsTabModel) capturingInput() bool {
+	return m.filtering
+}
+
+func (m requestsTabModel) Update(msg tea.Msg) (requestsTabModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case localeChangedMsg:
+		m.refresh()
+		return m, nil
+	case requestLogsTickMsg:
+		if !m.live {
+			return m, m.waitForNextPoll()
+		}
+		return m, m.fetchRequestLogs
+	case requestLogsPollMsg:
+		if msg.err != nil {
+			m.lastErr = msg.err
+		} else {
+			m.lastErr = nil
+			m.after = msg.latest
+			m.merge(msg.entries)
+		}
+		if m.detail == nil {
+			m.refresh()
+		}
+		if msg.err == nil && msg.hasMore {
+			// More requests landed than one batch holds; drain them now.
+			return m, m.fetchRequestLogs
+		}
+		return m, m.waitForNextPoll()
+	case requestLogDetailMsg:
+		if m.detail == nil || m.detail.entry.ID != msg.id {
+			return m, nil
+		}
+		m.detail.loading = false
+		m.detail.err = msg.err
+		if msg.err == nil {
+			m.detail.raw = msg.raw
+			m.detail.panes = buildRequestLogPanes(msg.raw)
+			m.detail.pane = firstNonEmptyPane(m.detail.panes)
+		}
+		m.refresh()
+		m.viewport.GotoTop()
+		return m, nil
+	case tea.KeyMsg:
+		if m.filtering {
+			return m.handleFilterInput(msg)
+		}
+		if m.detail != nil {
+			return m.handleDetailInput(msg)
+		}
+		return m.handleListInput(msg)
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// merge adds newly listed entries. A file listed again (a streaming log that
+// kept growing) replaces its earlier summary and moves to the top.
+func (m *requestsTabModel) merge(entries []requestLogEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	selected := ""
+	if visible := m.visible(); m.cursor < len(visible) {
+		selected = visible[m.cursor].Name
+	}
+	seen := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		seen[entry.Name] = struct{}{}
+	}
+	merged := make([]requestLogEntry, 0, len(entries)+len(m.entries))
+	merged = append(merged, entries...)
+	for _, entry := range m.entries {
+		if _, ok := seen[entry.Name]; !ok {
+			merged = append(merged, entry)
+		}
+	}
+	if len(merged) > requestsMaxEntries {
+		merged = merged[:requestsMaxEntries]
+	}
+	m.entries = merged
+	if selected == "" || m.cursor == 0 {
+		m.cursor = 0
+		return
+	}
+	for i, entry := range m.visible() {
+		if entry.Name == selected {
+			m.cursor = i
+			return
+		}
+	}
+	m.clampCursor()
+}
+
+// visible returns the entries that pass the current filters.
+func (m requestsTabModel) visible() []requestLogEntry {
+	out := make([]requestLogEntry, 0, len(m.entries))
+	for _, entry := range m.entries {
+		if m.errorsOnly && !entry.failed() {
+			continue
+		}
+		if !entry.matches(m.filter) {
+			continue
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func (m *requestsTabModel) clampCursor() {
+	if count := len(m.visible()); m.cursor >= count {
+		m.cursor = max(0, count-1)
+	}
+}
+
+func (m requestsTabModel) handleFilterInput(msg tea.KeyMsg) (requestsTabModel, tea.Cmd) {
+	switch msg.String() {
+	case "enter", "esc":
+		if msg.String() == "esc" {
+			m.filterInput.SetValue(m.filter)
+		}
+		m.filter = strings.TrimSpace(m.filterInput.Value())
+		m.filtering = false
+		m.filterInput.Blur()
+		m.cursor = 0
+		m.refresh()
+		return m, nil
+	default:
+		var cmd tea.Cmd
+		m.filterInput, cmd = m.filterInput.Update(msg)
+		m.filter = strings.TrimSpace(m.filterInput.Value())
+		m.cursor = 0
+		m.refresh()
+		return m, cmd
+	}
+}
+
+func (m requestsTabModel) handleListInput(msg tea.KeyMsg) (requestsTabModel, tea.Cmd) {
+	switch msg.String() {
+	case "/":
+		m.filtering = true
+		m.filterInput.SetValue(m.filter)
+		m.filterInput.Focus()
+		m.refresh()
+		return m, textinput.Blink
+	case "e":
+		m.errorsOnly = !m.errorsOnly
+		m.cursor = 0
+	case "x":
+		m.filter = ""
+		m.filterInput.SetValue("")
+		m.errorsOnly = false
+		m.cursor = 0
+	case "a", " ":
+		m.live = !m.live
+	case "r":
+		m.status = ""
+		return m, m.fetchRequestLogs
+	case "j", "down":
+		m.cursor++
+		m.clampCursor()
+	case "k", "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "g", "home":
+		m.cursor = 0
+	case "G", "end":
+		m.cursor = max(0, len(m.visible())-1)
+	case "enter":
+		visible := m.visible()
+		if m.cursor >= len(visible) {
+			return m, nil
+		}
+		entry := visible[m.cursor]
+		m.status = ""
+		m.detail = &requestLogDetail{entry: entry, loading: true}
+		m.refresh()
+		m.viewport.GotoTop()
+		return m, m.fetchDetail(entry.ID)
+	default:
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	}
+	m.refresh()
+	return m, nil
+}
+
+func (m requestsTabModel) handleDetailInput(msg tea.KeyMsg) (requestsTabModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "backspace":
+		m.detail = nil
+		m.status = ""
+		m.refresh()
+		return m, nil
+	case "1", "2", "3", "4", "5":
+		m.detail.pane = int(msg.String()[0] - '1')
+	case "right", "l":
+		m.detail.pane = (m.detail.pane + 1) % requestPaneCount
+	case "left", "h":
+		m.detail.pane = (m.detail.pane - 1 + requestPaneCount) % requestPaneCount
+	case "y":
+		m.status = copyToClipboard(m.detail.panes[m.detail.pane])
+		m.refresh()
+		return m, nil
+	case "Y":
+		m.status = copyToClipboard(m.detail.raw)
+		m.refresh()
+		return m, nil
+	case "r":
+		m.detail.loading = true
+		m.refresh()
+		return m, m.fetchDetail(m.detail.entry.ID)
+	default:
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	}
+	m.status = ""
+	m.refresh()
+	m.viewport.GotoTop()
+	return m, nil
+}
+
+func copyToClipboard(text string) string {
+	if text == "" {
+		return ""
+	}
+	if err := clipboard.WriteAll(text); err != nil {
+		return errorStyle.Render(T("copy_failed") + ": " + err.Error())
+	}
+	return successStyle.Render(T("copied"))
+}
+
+func firstNonEmptyPane(panes [requestPaneCount]string) int {
+	for i, pane := range panes {
+		if pane != "" {
+			return i
+		}
+	}
+	return requestPaneDownstreamRequest
+}
+
+func (m *requestsTabModel) refresh() {
+	if m.detail != nil {
+		m.viewport.SetContent(m.renderDetail())
+		return
+	}
+	m.viewport.SetContent(m.renderList())
+	// Keep the selected row visible below the list header.
+	headerLines := requestsListHeaderLines
+	if m.filtering || m.filter != "" {
+		headerLines++
+	}
+	if m.lastErr != nil {
+		headerLines++
+	}
+	line := headerLines + m.cursor
+	if line < m.viewport.YOffset {
+		m.viewport.SetYOffset(max(0, line-headerLines))
+	} else if line >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(line - m.viewport.Height + 1)
+	}
+}
+
+func (m *requestsTabModel) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	m.filterInput.Width = w - 8
+	if !m.ready {
+		m.viewport = viewport.New(w, h)
+		m.ready = true
+		m.refresh()
+	} else {
+		m.viewport.Width = w
+		m.viewport.Height = h
+	}
+}
+
+func (m requestsTabModel) View() string {
+	if !m.ready {
+		return T("loading")
+	}
+	return m.viewport.View()
+}
+
+// requestsListHeaderLines is the number of lines renderList writes before the
+// first row when neither the filter line nor an error is shown.
+const requestsListHeaderLines = 6
+
+func (m requestsTabModel) renderList() string {
+	var sb strings.Builder
+
+	sb.WriteString(titleStyle.Render(T("requests_title")))
+	sb.WriteString("\n")
+	sb.WriteString(helpStyle.Render(T("requests_help")))
+	sb.WriteString("\n")
+	sb.WriteString(strings.Repeat("─", m.width))
+	sb.WriteString("\n")
+
+	liveText := successStyle.Render(T("requests_live"))
+	if !m.live {
+		liveText = warningStyle.Render(T("requests_paused"))
+	}
+	visible := m.visible()
+	scope := T("requests_scope_all")
+	if m.errorsOnly {
+		scope = T("requests_scope_errors")
+	}
+	sb.WriteString(fmt.Sprintf("  %s  %s: %s  %s: %d/%d",
+		liveText, T("logs_filter"), scope, T("requests_count"), len(visible), len(m.entries)))
+	if m.status != "" {
+		sb.WriteString("  " + m.status)
+	}
+	sb.WriteString("\n")
+	if m.filtering {
+		sb.WriteString(m.filterInput.View())
+		sb.WriteString("\n")
+	} else if m.filter != "" {
+		sb.WriteString(helpStyle.Render("  / " + m.filter))
+		sb.WriteString("\n")
+	}
+
+	nameWidth := max(24, m.width-86)
+	header := fmt.Sprintf("  %-8s %-6s %-6s %-*s %-24s %-20s %8s",
+		T("requests_col_time"), T("requests_col_status"), T("requests_col_method"), nameWidth, T("requests_col_path"),
+		T("requests_col_model"), T("requests_col_credential"), T("requests_col_latency"))
+	sb.WriteString(tableHeaderStyle.Render(header))
+	sb.WriteString("\n")
+
+	if m.lastErr != nil {
+		sb.WriteString(errorStyle.Render("  " + T("error_prefix") + m.lastErr.Error()))
+		sb.WriteString("\n")
+	}
+	if len(visible) == 0 {
+		sb.WriteString(subtitleStyle.Render(T("requests_empty")))
+		sb.WriteString("\n")
+		return sb.String()
+	}
+
+	for i, entry := range visible {
+		cursor := "  "
+		rowStyle := lipgloss.NewStyle()
+		if i == m.cursor {
+			cursor = "▸ "
+			rowStyle = rowStyle.Bold(true)
+		}
+		stamp := entry.Timestamp
+		if stamp.IsZero() {
+			stamp = entry.Modified
+		}
+		status := "-"
+		if entry.Status > 0 {
+			status = fmt.Sprintf("%d", entry.Status)
+		}
+		statusText := fmt.Sprintf("%-6s", status)
+		if entry.failed() {
+			statusText = errorStyle.Render(statusText)
+		} else if entry.Status > 0 {
+			statusText = successStyle.Render(statusText)
+		}
+		credential := entry.Credential
+		if entry.Provider != "" {
+			credential = strings.TrimSpace(entry.Provider + " " + credential)
+		}
+		line := fmt.Sprintf("%s%-8s %s %-6s %-*s %-24s %-20s %8s",
+			cursor, stamp.Local().Format("15:04:05"), statusText, entry.Method,
+			nameWidth, fitStringWidth(entry.URL, nameWidth),
+			fitStringWidth(orDash(entry.Model), 24), fitStringWidth(orDash(credential), 20),
+			formatMillis(entry.LatencyMs))
+		sb.WriteString(rowStyle.Render(line))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func (m requestsTabModel) renderDetail() string {
+	var sb strings.Builder
+	detail := m.detail
+	entry := detail.entry
+
+	sb.WriteString(titleStyle.Render(fmt.Sprintf(T("requests_detail_title"), entry.ID)))
+	sb.WriteString("\n")
+	sb.WriteString(helpStyle.Render(T("requests_detail_help")))
+	sb.WriteString("\n")
+	sb.WriteString(strings.Repeat("─", m.width))
+	sb.WriteString("\n")
+
+	sb.WriteString(fmt.Sprintf("  %s %s  %s %d  %s %s  %s %s  %s %s\n",
+		entry.Method, entry.URL,
+		T("requests_col_status"), entry.Status,
+		T("requests_col_model"), orDash(entry.Model),
+		T("requests_col_credential"), orDash(strings.TrimSpace(entry.Provider+" "+entry.Credential)),
+		T("requests_col_latency"), formatMillis(entry.LatencyMs)))
+
+	labels := []string{
+		T("requests_pane_downstream_request"),
+		T("requests_pane_downstream_response"),
+		T("requests_pane_upstream_request"),
+		T("requests_pane_upstream_response"),
+		T("requests_pane_websocket"),
+	}
+	tabs := make([]string, 0, len(labels))
+	for i, label := range labels {
+		text := fmt.Sprintf("[%d] %s", i+1, label)
+		switch {
+		case i == detail.pane:
+			text = tabActiveStyle.Render(text)
+		case detail.panes[i] == "":
+			text = lipgloss.NewStyle().Foreground(colorMuted).Render(text)
+		}
+		tabs = append(tabs, text)
+	}
+	sb.WriteString("  " + strings.Join(tabs, " "))
+	if m.status != "" {
+		sb.WriteString("  " + m.status)
+	}
+	sb.WriteString("\n\n")
+
+	switch {
+	case detail.loading:
+		sb.WriteString(subtitleStyle.Render("  " + T("loading")))
+	case detail.err != nil:
+		sb.WriteString(errorStyle.Render("  " + T("error_prefix") + detail.err.Error()))
+	case detail.panes[detail.pane] == "":
+		sb.WriteString(subtitleStyle.Render(T("requests_pane_empty")))
+	default:
+		for _, line := range strings.Split(detail.panes[detail.pane], "\n") {
+			if strings.HasPrefix(line, "=== ") {
+				sb.WriteString(tableHeaderStyle.Render(line))
+			} else {
+				sb.WriteString(line)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func orDash(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "-"
+	}
+	return value
+}
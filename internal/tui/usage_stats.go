@@ -0,0 +1,212 @@
+package tui
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// usageRetention bounds how long usage samples are kept in memory.
+const usageRetention = 24 * time.Hour
+
+// usageSample is one completed request reduced to the fields the Usage tab needs.
+type usageSample struct {
+	Timestamp    time.Time
+	Model        string
+	Provider     string
+	AuthIndex    string
+	Failed       bool
+	TTFTMs       int64
+	LatencyMs    int64
+	InputTokens  int64
+	OutputTokens int64
+	TotalTokens  int64
+}
+
+// requestCompletionRecord mirrors the data of request.completed management events.
+type requestCompletionRecord struct {
+	Timestamp    time.Time `json:"timestamp"`
+	LatencyMs    int64     `json:"latency_ms"`
+	TTFTMs       int64     `json:"ttft_ms"`
+	AuthIndex    string    `json:"auth_index"`
+	Failed       bool      `json:"failed"`
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model"`
+	Alias        string    `json:"alias"`
+	InputTokens  int64     `json:"input_tokens"`
+	OutputTokens int64     `json:"output_tokens"`
+	TotalTokens  int64     `json:"total_tokens"`
+}
+
+// usageWindow is a selectable time range rendered as a fixed number of buckets.
+type usageWindow struct {
+	label   string
+	span    time.Duration
+	buckets int
+}
+
+var usageWindows = []usageWindow{
+	{label: "5m", span: 5 * time.Minute, buckets: 30},
+	{label: "1h", span: time.Hour, buckets: 30},
+	{label: "6h", span: 6 * time.Hour, buckets: 36},
+	{label: "24h", span: 24 * time.Hour, buckets: 48},
+}
+
+// usageRow aggregates samples for one model or credential.
+type usageRow struct {
+	Name     string
+	Requests int64
+	Errors   int64
+	Tokens   int64
+	TTFTP50  int64
+	TTFTP95  int64
+	Trend    []float64
+}
+
+// ErrorRate returns the failed request share in percent.
+func (r usageRow) ErrorRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Errors) * 100 / float64(r.Requests)
+}
+
+// parseRequestCompletion decodes the data of a request.completed event into a sample.
+func parseRequestCompletion(data []byte) (usageSample, error) {
+	var record requestCompletionRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return usageSample{}, err
+	}
+	model := strings.TrimSpace(record.Alias)
+	if model == "" {
+		model = strings.TrimSpace(record.Model)
+	}
+	total := record.TotalTokens
+	if total == 0 {
+		total = record.InputTokens + record.OutputTokens
+	}
+	return usageSample{
+		Timestamp:    record.Timestamp,
+		Model:        model,
+		Provider:     strings.TrimSpace(record.Provider),
+		AuthIndex:    strings.TrimSpace(record.AuthIndex),
+		Failed:       record.Failed,
+		TTFTMs:       record.TTFTMs,
+		LatencyMs:    record.LatencyMs,
+		InputTokens:  record.InputTokens,
+		OutputTokens: record.OutputTokens,
+		TotalTokens:  total,
+	}, nil
+}
+
+// usageHistory keeps samples ordered by arrival and drops those past usageRetention.
+type usageHistory struct {
+	samples []usageSample
+}
+
+func (h *usageHistory) add(samples []usageSample, now time.Time) {
+	for _, sample := range samples {
+		if sample.Timestamp.IsZero() {
+			sample.Timestamp = now
+		}
+		h.samples = append(h.samples, sample)
+	}
+	cutoff := now.Add(-usageRetention)
+	keep := h.samples[:0]
+	for _, sample := range h.samples {
+		if !sample.Timestamp.Before(cutoff) {
+			keep = append(keep, sample)
+		}
+	}
+	h.samples = keep
+}
+
+// summarize groups samples inside window by key. Rows are sorted by request
+// count, then name. Samples for which key returns "" are skipped.
+func (h *usageHistory) summarize(window usageWindow, now time.Time, key func(usageSample) string) []usageRow {
+	start := now.Add(-window.span)
+	bucketSpan := window.span / time.Duration(window.buckets)
+	rows := make(map[string]*usageRow)
+	ttfts := make(map[string][]int64)
+	for _, sample := range h.samples {
+		if sample.Timestamp.Before(start) || sample.Timestamp.After(now) {
+			continue
+		}
+		name := key(sample)
+		if name == "" {
+			continue
+		}
+		row := rows[name]
+		if row == nil {
+			row = &usageRow{Name: name, Trend: make([]float64, window.buckets)}
+			rows[name] = row
+		}
+		row.Requests++
+		row.Tokens += sample.TotalTokens
+		if sample.Failed {
+			row.Errors++
+		} else if sample.TTFTMs > 0 {
+			ttfts[name] = append(ttfts[name], sample.TTFTMs)
+		}
+		bucket := int(sample.Timestamp.Sub(start) / bucketSpan)
+		if bucket >= window.buckets {
+			bucket = window.buckets - 1
+		}
+		row.Trend[bucket]++
+	}
+
+	out := make([]usageRow, 0, len(rows))
+	for name, row := range rows {
+		values := ttfts[name]
+		sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+		row.TTFTP50 = percentile(values, 50)
+		row.TTFTP95 = percentile(values, 95)
+		out = append(out, *row)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Requests != out[j].Requests {
+			return out[i].Requests > out[j].Requests
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders values scaled to the largest value.
+func sparkline(values []float64) string {
+	maxValue := 0.0
+	for _, v := range values {
+		if v > maxValue {
+			maxValue = v
+		}
+	}
+	var sb strings.Builder
+	for _, v := range values {
+		if maxValue <= 0 || v <= 0 {
+			sb.WriteRune(' ')
+			continue
+		}
+		index := int(v / maxValue * float64(len(sparkBlocks)-1))
+		sb.WriteRune(sparkBlocks[index])
+	}
+	return sb.String()
+}
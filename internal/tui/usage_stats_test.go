@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseRequestCompletionPrefersAliasAndDerivesTotal(t *testing.T) {
+	sample, err := parseRequestCompletion([]byte(`{"timestamp":"2026-01-01T00:00:00Z","model":"gpt-5","alias":"fast","auth_index":"3","provider":"codex","ttft_ms":120,"input_tokens":10,"output_tokens":5}`))
+	if err != nil {
+		t.Fatalf("parseRequestCompletion: %v", err)
+	}
+	if sample.Model != "fast" || sample.TotalTokens != 15 || sample.AuthIndex != "3" || sample.TTFTMs != 120 {
+		t.Fatalf("unexpected sample: %+v", sample)
+	}
+	sample, err = parseRequestCompletion([]byte(`{"timestamp":"2026-01-01T00:00:01Z","model":"claude","failed":true,"total_tokens":42}`))
+	if err != nil || sample.Model != "claude" || !sample.Failed || sample.TotalTokens != 42 {
+		t.Fatalf("unexpected sample: %+v err = %v", sample, err)
+	}
+	if _, err = parseRequestCompletion([]byte(`[]`)); err == nil {
+		t.Fatal("expected error for non-object payload")
+	}
+}
+
+func TestUsageHistorySummarize(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	var history usageHistory
+	history.add([]usageSample{
+		{Timestamp: now.Add(-2 * time.Hour), Model: "old", TotalTokens: 1},
+		{Timestamp: now.Add(-50 * time.Minute), Model: "a", AuthIndex: "1", TTFTMs: 100, TotalTokens: 10},
+		{Timestamp: now.Add(-10 * time.Minute), Model: "a", AuthIndex: "2", TTFTMs: 300, TotalTokens: 20},
+		{Timestamp: now.Add(-time.Minute), Model: "a", AuthIndex: "2", Failed: true, TotalTokens: 5},
+		{Timestamp: now.Add(-time.Minute), Model: "b", AuthIndex: "1", TTFTMs: 50, TotalTokens: 7},
+	}, now)
+
+	window := usageWindow{label: "1h", span: time.Hour, buckets: 6}
+	rows := history.summarize(window, now, func(s usageSample) string { return s.Model })
+	if len(rows) != 2 || rows[0].Name != "a" || rows[1].Name != "b" {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+	a := rows[0]
+	if a.Requests != 3 || a.Errors != 1 || a.Tokens != 35 {
+		t.Fatalf("unexpected totals: %+v", a)
+	}
+	if a.TTFTP50 != 100 || a.TTFTP95 != 300 {
+		t.Fatalf("ttft p50/p95 = %d/%d, want 100/300", a.TTFTP50, a.TTFTP95)
+	}
+	if got := a.ErrorRate(); got < 33.3 || got > 33.4 {
+		t.Fatalf("error rate = %.2f", got)
+	}
+	if a.Trend[1] != 1 || a.Trend[5] != 2 {
+		t.Fatalf("unexpected trend: %v", a.Trend)
+	}
+
+	credentials := history.summarize(window, now, func(s usageSample) string {
+		if s.Model != "a" {
+			return ""
+		}
+		return s.AuthIndex
+	})
+	if len(credentials) != 2 || credentials[0].Name != "2" || credentials[0].Requests != 2 {
+		t.Fatalf("unexpected drill-down rows: %+v", credentials)
+	}
+}
+
+func TestUsageHistoryDropsExpiredSamples(t *testing.T) {
+	now := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	var history usageHistory
+	history.add([]usageSample{
+		{Timestamp: now.Add(-25 * time.Hour), Model: "expired"},
+		{Model: "stamped"},
+	}, now)
+	if len(history.samples) != 1 || history.samples[0].Model != "stamped" || !history.samples[0].Timestamp.Equal(now) {
+		t.Fatalf("unexpected samples: %+v", history.samples)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]float64{0, 1, 2, 4}); got != " ▂▄█" {
+		t.Fatalf("sparkline = %q", got)
+	}
+	if got := sparkline([]float64{0, 0}); got != "  " {
+		t.Fatalf("sparkline of zeros = %q", got)
+	}
+}
+
+func TestStreamEventsReadsRequestCompletions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v0/management/events" || r.URL.Query().Get("types") != usageEventTypes || r.URL.Query().Get("last_event_id") != "7" {
+			t.Errorf("unexpected request %s", r.URL.String())
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: stream.gap\ndata: {\"last_event_id\":7}\n\n")
+		fmt.Fprint(w, "id: 8\nevent: request.completed\ndata: {\"id\":8,\"type\":\"request.completed\",\"data\":{\"model\":\"gpt-5\",\"total_tokens\":3}}\n\n")
+		fmt.Fprint(w, ": ping\n\n")
+		fmt.Fprint(w, "event: stream.lagged\ndata: {\"last_event_id\":8}\n\n")
+	}))
+	defer server.Close()
+	client := &Client{baseURL: server.URL, http: server.Client()}
+
+	var got []streamEvent
+	err := client.StreamEvents(context.Background(), usageEventTypes, 7, func(event streamEvent) {
+		got = append(got, event)
+	})
+	if !errors.Is(err, errEventStreamLagged) {
+		t.Fatalf("StreamEvents() error = %v, want errEventStreamLagged", err)
+	}
+	if len(got) != 1 || got[0].ID != 8 {
+		t.Fatalf("events = %+v, want the single request.completed event", got)
+	}
+	if sample, errParse := parseRequestCompletion(got[0].Data); errParse != nil || sample.Model != "gpt-5" || sample.TotalTokens != 3 {
+		t.Fatalf("sample = %+v err = %v", sample, errParse)
+	}
+}
@@ -0,0 +1,369 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// usageEventTypes selects the management events the Usage tab follows.
+	usageEventTypes       = "request.completed"
+	usageReconnectDelay   = 2 * time.Second
+	usageStreamBatch      = 1000
+	usageStreamBufferSize = 4096
+)
+
+// usage views
+const (
+	usageViewModels = iota
+	usageViewCredentials
+)
+
+// usageTabModel aggregates request.completed management events into per-model
+// and per-credential statistics with sparklines.
+type usageTabModel struct {
+	client   *Client
+	stream   *usageStream
+	viewport viewport.Model
+	history  usageHistory
+	width    int
+	height   int
+	ready    bool
+	lastErr  error
+
+	window int
+	view   int
+	cursor int
+	// drillModel restricts the credential view to one model when set.
+	drillModel string
+
+	// credentialNames maps auth_index to a display name from the auth files list.
+	credentialNames map[string]string
+	now             func() time.Time
+}
+
+type usagePollMsg struct {
+	samples []usageSample
+	err     error
+}
+
+// usageStream follows the management event stream in the background. It is
+// shared by all copies of the tab model and started once.
+type usageStream struct {
+	once sync.Once
+	msgs chan usagePollMsg
+}
+
+// run reads request.completed events, reconnecting after errors and resuming
+// after the last event seen. The first connection replays retained events so
+// the tab shows recent history when it is opened.
+func (s *usageStream) run(client *Client) {
+	var lastID uint64
+	for {
+		err := client.StreamEvents(context.Background(), usageEventTypes, lastID, func(event streamEvent) {
+			lastID = event.ID
+			if sample, errParse := parseRequestCompletion(event.Data); errParse == nil {
+				s.msgs <- usagePollMsg{samples: []usageSample{sample}}
+			}
+		})
+		if err != nil && !errors.Is(err, errEventStreamLagged) {
+			s.msgs <- usagePollMsg{err: err}
+		}
+		time.Sleep(usageReconnectDelay)
+	}
+}
+
+type usageCredentialsMsg struct {
+	names map[string]string
+}
+
+func newUsageTabModel(client *Client) usageTabModel {
+	return usageTabModel{
+		client: client,
+		stream: &usageStream{msgs: make(chan usagePollMsg, usageStreamBufferSize)},
+		window: 1,
+		now:    time.Now,
+	}
+}
+
+func (m usageTabModel) Init() tea.Cmd {
+	started := false
+	m.stream.once.Do(func() {
+		started = true
+		go m.stream.run(m.client)
+	})
+	if !started {
+		return m.fetchCredentials
+	}
+	return tea.Batch(m.waitForUsage, m.fetchCredentials)
+}
+
+// waitForUsage blocks for the next streamed sample and batches whatever else is
+// already buffered.
+func (m usageTabModel) waitForUsage() tea.Msg {
+	msg := <-m.stream.msgs
+	for msg.err == nil && len(msg.samples) < usageStreamBatch {
+		select {
+		case next := <-m.stream.msgs:
+			msg.samples = append(msg.samples, next.samples...)
+			msg.err = next.err
+		default:
+			return msg
+		}
+	}
+	return msg
+}
+
+func (m usageTabModel) fetchCredentials() tea.Msg {
+	files, err := m.client.GetAuthFiles()
+	if err != nil {
+		return usageCredentialsMsg{}
+	}
+	names := make(map[string]string, len(files))
+	for _, file := range files {
+		index := getString(file, "auth_index")
+		if index == "" {
+			continue
+		}
+		name := getString(file, "email")
+		if name == "" {
+			name = getString(file, "name")
+		}
+		if name != "" {
+			names[index] = name
+		}
+	}
+	return usageCredentialsMsg{names: names}
+}
+
+func (m usageTabModel) Update(msg tea.Msg) (usageTabModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case localeChangedMsg:
+		m.viewport.SetContent(m.renderContent())
+		return m, nil
+	case usagePollMsg:
+		m.history.add(msg.samples, m.now())
+		m.lastErr = msg.err
+		m.clampCursor()
+		m.viewport.SetContent(m.renderContent())
+		return m, m.waitForUsage
+	case usageCredentialsMsg:
+		if msg.names != nil {
+			m.credentialNames = msg.names
+		}
+		m.viewport.SetContent(m.renderContent())
+		return m, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "r":
+			return m, m.fetchCredentials
+		case "w":
+			m.window = (m.window + 1) % len(usageWindows)
+		case "1", "2", "3", "4":
+			if index := int(msg.String()[0] - '1'); index < len(usageWindows) {
+				m.window = index
+			}
+		case "m":
+			m.view, m.drillModel, m.cursor = usageViewModels, "", 0
+		case "c":
+			m.view, m.drillModel, m.cursor = usageViewCredentials, "", 0
+		case "enter":
+			if m.view == usageViewModels {
+				rows := m.rows()
+				if m.cursor < len(rows) {
+					m.view, m.drillModel, m.cursor = usageViewCredentials, rows[m.cursor].Name, 0
+				}
+			}
+		case "esc", "backspace":
+			if m.drillModel != "" {
+				m.view, m.drillModel, m.cursor = usageViewModels, "", 0
+			}
+		case "j", "down":
+			m.cursor++
+			m.clampCursor()
+		case "k", "up":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		default:
+			var cmd tea.Cmd
+			m.viewport, cmd = m.viewport.Update(msg)
+			return m, cmd
+		}
+		m.viewport.SetContent(m.renderContent())
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m *usageTabModel) clampCursor() {
+	if rows := len(m.rows()); m.cursor >= rows {
+		m.cursor = max(0, rows-1)
+	}
+}
+
+// rows returns the aggregated rows for the current view and window.
+func (m usageTabModel) rows() []usageRow {
+	window := usageWindows[m.window]
+	if m.view == usageViewModels {
+		return m.history.summarize(window, m.now(), func(s usageSample) string { return s.Model })
+	}
+	return m.history.summarize(window, m.now(), func(s usageSample) string {
+		if m.drillModel != "" && s.Model != m.drillModel {
+			return ""
+		}
+		return m.credentialLabel(s)
+	})
+}
+
+func (m usageTabModel) credentialLabel(sample usageSample) string {
+	name := m.credentialNames[sample.AuthIndex]
+	if name == "" {
+		name = sample.AuthIndex
+	}
+	if name == "" {
+		name = "-"
+	}
+	if sample.Provider != "" {
+		return sample.Provider + " · " + name
+	}
+	return name
+}
+
+func (m *usageTabModel) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	if !m.ready {
+		m.viewport = viewport.New(w, h)
+		m.viewport.SetContent(m.renderContent())
+		m.ready = true
+	} else {
+		m.viewport.Width = w
+		m.viewport.Height = h
+	}
+}
+
+func (m usageTabModel) View() string {
+	if !m.ready {
+		return T("loading")
+	}
+	return m.viewport.View()
+}
+
+func (m usageTabModel) renderContent() string {
+	var sb strings.Builder
+
+	sb.WriteString(titleStyle.Render(T("usage_title")))
+	sb.WriteString("\n")
+	sb.WriteString(helpStyle.Render(T("usage_help")))
+	sb.WriteString("\n")
+	sb.WriteString(strings.Repeat("─", m.width))
+	sb.WriteString("\n")
+
+	if m.lastErr != nil {
+		sb.WriteString(errorStyle.Render(T("error_prefix") + m.lastErr.Error()))
+		sb.WriteString("\n")
+	}
+
+	window := usageWindows[m.window]
+	windowLabels := make([]string, 0, len(usageWindows))
+	for i, w := range usageWindows {
+		label := fmt.Sprintf("[%d] %s", i+1, w.label)
+		if i == m.window {
+			label = tabActiveStyle.Render(label)
+		}
+		windowLabels = append(windowLabels, label)
+	}
+	sb.WriteString(fmt.Sprintf("  %s: %s\n\n", T("usage_window"), strings.Join(windowLabels, " ")))
+
+	// Totals across all models for the window.
+	totals := m.history.summarize(window, m.now(), func(usageSample) string { return "all" })
+	var total usageRow
+	if len(totals) > 0 {
+		total = totals[0]
+	}
+	minutes := window.span.Minutes()
+	sb.WriteString(fmt.Sprintf("  %s %s   %s %s   %s %.1f%%   %s %.1f   %s %.0f\n",
+		labelStyle.Width(0).Render(T("usage_total_reqs")), valueStyle.Render(fmt.Sprintf("%d", total.Requests)),
+		labelStyle.Width(0).Render(T("usage_total_tokens")), valueStyle.Render(formatLargeNumber(total.Tokens)),
+		labelStyle.Width(0).Render(T("usage_error_rate")), total.ErrorRate(),
+		labelStyle.Width(0).Render(T("usage_rpm")), float64(total.Requests)/minutes,
+		labelStyle.Width(0).Render(T("usage_tpm")), float64(total.Tokens)/minutes,
+	))
+	if total.Requests > 0 {
+		sb.WriteString("  " + lipgloss.NewStyle().Foreground(colorInfo).Render(sparkline(total.Trend)) + "\n")
+	}
+	sb.WriteString("\n")
+
+	heading := T("usage_by_model")
+	if m.view == usageViewCredentials {
+		heading = T("usage_by_credential")
+		if m.drillModel != "" {
+			heading = fmt.Sprintf(T("usage_model_credentials"), m.drillModel)
+		}
+	}
+	sb.WriteString(tableHeaderStyle.Render("  " + heading))
+	sb.WriteString("\n")
+
+	rows := m.rows()
+	if len(rows) == 0 {
+		sb.WriteString(subtitleStyle.Render(T("usage_no_data")))
+		sb.WriteString("\n")
+		sb.WriteString(helpStyle.Render(T("usage_queue_hint")))
+		sb.WriteString("\n")
+		return sb.String()
+	}
+
+	nameWidth := 28
+	for _, row := range rows {
+		if w := lipgloss.Width(row.Name); w > nameWidth {
+			nameWidth = minInt(w, 48)
+		}
+	}
+	header := fmt.Sprintf("  %-*s %8s %10s %7s %8s %8s  %s",
+		nameWidth, T("usage_col_name"), T("usage_col_requests"), T("usage_col_tokens"),
+		T("usage_col_errors"), "TTFT p50", "TTFT p95", T("usage_col_trend"))
+	sb.WriteString(subtitleStyle.Render(header))
+	sb.WriteString("\n")
+
+	for i, row := range rows {
+		cursor := "  "
+		rowStyle := lipgloss.NewStyle()
+		if i == m.cursor {
+			cursor = "▸ "
+			rowStyle = rowStyle.Bold(true)
+		}
+		errText := fmt.Sprintf("%6.1f%%", row.ErrorRate())
+		if row.Errors > 0 {
+			errText = errorStyle.Render(errText)
+		}
+		line := fmt.Sprintf("%s%-*s %8d %10s %s %8s %8s  %s",
+			cursor, nameWidth, fitStringWidth(row.Name, nameWidth), row.Requests, formatLargeNumber(row.Tokens),
+			errText, formatMillis(row.TTFTP50), formatMillis(row.TTFTP95),
+			lipgloss.NewStyle().Foreground(colorInfo).Render(sparkline(row.Trend)))
+		sb.WriteString(rowStyle.Render(line))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func formatMillis(ms int64) string {
+	if ms <= 0 {
+		return "-"
+	}
+	if ms < 1000 {
+		return fmt.Sprintf("%dms", ms)
+	}
+	return fmt.Sprintf("%.2fs", float64(ms)/1000)
+}
@@ -6,10 +6,12 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"os"
+	"path/filepath"
 	"reflect"
 	"time"
 
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/events"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/watcher/diff"
 	"gopkg.in/yaml.v3"
@@ -17,6 +19,18 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// configReloadedEvent is the payload of config.reloaded management events.
+type configReloadedEvent struct {
+	File    string   `json:"file,omitempty"`
+	Changes []string `json:"changes"`
+}
+
+// configReloadFailedEvent is the payload of config.reload_failed management events.
+type configReloadFailedEvent struct {
+	File  string `json:"file,omitempty"`
+	Error string `json:"error"`
+}
+
 func (w *Watcher) stopConfigReloadTimer() {
 	w.configReloadMu.Lock()
 	if w.configReloadTimer != nil {
@@ -92,6 +106,7 @@ func (w *Watcher) reloadConfig() bool {
 	newConfig, errLoadConfig := config.LoadConfig(w.configPath)
 	if errLoadConfig != nil {
 		log.Errorf("failed to reload config: %v", errLoadConfig)
+		events.Publish(events.TypeConfigReloadFailed, configReloadFailedEvent{File: filepath.Base(w.configPath), Error: errLoadConfig.Error()})
 		return false
 	}
 
@@ -122,8 +137,9 @@ func (w *Watcher) reloadConfig() bool {
 		log.Debugf("log level updated - debug mode changed from %t to %t", oldConfig.Debug, newConfig.Debug)
 	}
 
+	var details []string
 	if oldConfig != nil {
-		details := diff.BuildConfigChangeDetails(oldConfig, newConfig)
+		details = diff.BuildConfigChangeDetails(oldConfig, newConfig)
 		if len(details) > 0 {
 			log.Info("config changes detected:")
 			for _, d := range details {
@@ -133,6 +149,10 @@ func (w *Watcher) reloadConfig() bool {
 			log.Debugf("no material config field changes detected")
 		}
 	}
+	if details == nil {
+		details = []string{}
+	}
+	events.Publish(events.TypeConfigReloaded, configReloadedEvent{File: filepath.Base(w.configPath), Changes: details})
 
 	authDirChanged := oldConfig == nil || oldConfig.AuthDir != newConfig.AuthDir
 	retryConfigChanged := oldConfig != nil && (oldConfig.RequestRetry != newConfig.RequestRetry || oldConfig.MaxRetryInterval != newConfig.MaxRetryInterval || oldConfig.MaxRetryCredentials != newConfig.MaxRetryCredentials)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -407,6 +408,48 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 			changes = append(changes, "remote-management.secret-key: updated")
 		}
 	}
+	if !managementKeysEqual(oldCfg.RemoteManagement.Keys, newCfg.RemoteManagement.Keys) {
+		if len(oldCfg.RemoteManagement.Keys) != len(newCfg.RemoteManagement.Keys) {
+			changes = append(changes, fmt.Sprintf("remote-management.keys: %d -> %d", len(oldCfg.RemoteManagement.Keys), len(newCfg.RemoteManagement.Keys)))
+		} else {
+			changes = append(changes, "remote-management.keys: updated")
+		}
+	}
+	if oldCfg.RemoteManagement.AuditLog.Enabled != newCfg.RemoteManagement.AuditLog.Enabled {
+		changes = append(changes, fmt.Sprintf("remote-management.audit-log.enabled: %t -> %t", oldCfg.RemoteManagement.AuditLog.Enabled, newCfg.RemoteManagement.AuditLog.Enabled))
+	}
+	if oldCfg.RemoteManagement.AuditLog.Backend != newCfg.RemoteManagement.AuditLog.Backend {
+		changes = append(changes, fmt.Sprintf("remote-management.audit-log.backend: %s -> %s", oldCfg.RemoteManagement.AuditLog.Backend, newCfg.RemoteManagement.AuditLog.Backend))
+	}
+
+	if oldCfg.HealthCheck.Enabled != newCfg.HealthCheck.Enabled {
+		changes = append(changes, fmt.Sprintf("health-check.enabled: %t -> %t", oldCfg.HealthCheck.Enabled, newCfg.HealthCheck.Enabled))
+	}
+	if oldCfg.HealthCheck.IntervalSeconds != newCfg.HealthCheck.IntervalSeconds {
+		changes = append(changes, fmt.Sprintf("health-check.interval-seconds: %d -> %d", oldCfg.HealthCheck.IntervalSeconds, newCfg.HealthCheck.IntervalSeconds))
+	}
+	if oldCfg.HealthCheck.Concurrency != newCfg.HealthCheck.Concurrency {
+		changes = append(changes, fmt.Sprintf("health-check.concurrency: %d -> %d", oldCfg.HealthCheck.Concurrency, newCfg.HealthCheck.Concurrency))
+	}
+	if !reflect.DeepEqual(oldCfg.HealthCheck.Models, newCfg.HealthCheck.Models) {
+		changes = append(changes, "health-check.models: updated")
+	}
+
+	if oldCfg.Notifications.Enabled != newCfg.Notifications.Enabled {
+		changes = append(changes, fmt.Sprintf("notifications.enabled: %t -> %t", oldCfg.Notifications.Enabled, newCfg.Notifications.Enabled))
+	}
+	if !reflect.DeepEqual(oldCfg.Notifications.Targets, newCfg.Notifications.Targets) {
+		changes = append(changes, fmt.Sprintf("notifications.targets: %d -> %d", len(oldCfg.Notifications.Targets), len(newCfg.Notifications.Targets)))
+	}
+	if !reflect.DeepEqual(oldCfg.Notifications.ClientKeyBudgets, newCfg.Notifications.ClientKeyBudgets) {
+		changes = append(changes, fmt.Sprintf("notifications.client-key-budgets: %d -> %d", len(oldCfg.Notifications.ClientKeyBudgets), len(newCfg.Notifications.ClientKeyBudgets)))
+	}
+	if oldCfg.Encryption.Enabled != newCfg.Encryption.Enabled {
+		changes = append(changes, fmt.Sprintf("encryption.enabled: %t -> %t", oldCfg.Encryption.Enabled, newCfg.Encryption.Enabled))
+	}
+	if oldCfg.Encryption.KMS != newCfg.Encryption.KMS || oldCfg.Encryption.KeyFile != newCfg.Encryption.KeyFile || oldCfg.Encryption.KeyEnv != newCfg.Encryption.KeyEnv || !reflect.DeepEqual(oldCfg.Encryption.PreviousKeyFiles, newCfg.Encryption.PreviousKeyFiles) {
+		changes = append(changes, "encryption.key: updated")
+	}
 
 	// OpenAI compatibility providers (summarized)
 	if compat := DiffOpenAICompatibility(oldCfg.OpenAICompatibility, newCfg.OpenAICompatibility); len(compat) > 0 {
@@ -586,3 +629,20 @@ func formatURL(raw string) string {
 	}
 	return scheme + "://" + host
 }
+
+// managementKeysEqual compares named management keys by name, role, routes and
+// key digest. Digests stand in for the secrets, which are never compared or
+// printed directly, and stay stable although plaintext keys are re-hashed on
+// every load.
+func managementKeysEqual(a, b []config.ManagementKey) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Role != b[i].Role || !slices.Equal(a[i].Routes, b[i].Routes) ||
+			a[i].KeyDigest() != b[i].KeyDigest() {
+			return false
+		}
+	}
+	return true
+}
@@ -651,3 +651,31 @@ func TestTrimStrings(t *testing.T) {
 		t.Fatalf("unexpected trimmed strings: %v", out)
 	}
 }
+
+func TestBuildConfigChangeDetails_ManagementKeyMaterial(t *testing.T) {
+	parse := func(key string) *config.Config {
+		t.Helper()
+		cfg, errParse := config.ParseConfigBytes([]byte("remote-management:\n  keys:\n    - name: ops\n      key: " + key + "\n      role: operator\n"))
+		if errParse != nil {
+			t.Fatalf("ParseConfigBytes() error = %v", errParse)
+		}
+		return cfg
+	}
+	oldCfg := parse("plain-ops-key")
+
+	// Plaintext keys get a fresh bcrypt salt on every load.
+	if details := BuildConfigChangeDetails(oldCfg, parse("plain-ops-key")); len(details) != 0 {
+		t.Fatalf("expected no change for a reloaded key, got %v", details)
+	}
+	if details := BuildConfigChangeDetails(oldCfg, oldCfg.CloneForRuntime()); len(details) != 0 {
+		t.Fatalf("expected no change for a cloned config, got %v", details)
+	}
+
+	details := BuildConfigChangeDetails(oldCfg, parse("rotated-ops-key"))
+	expectContains(t, details, "remote-management.keys: updated")
+	for _, detail := range details {
+		if strings.Contains(detail, "ops-key") || strings.Contains(detail, "$2a$") {
+			t.Fatalf("change details leak key material: %v", details)
+		}
+	}
+}
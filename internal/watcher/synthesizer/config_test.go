@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/credentialschedule"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
 )
 
@@ -1268,3 +1269,30 @@ func TestConfigSynthesizer_RequestScopedErrors(t *testing.T) {
 		}
 	}
 }
+
+func TestConfigSynthesizer_CopiesScheduleIntoAttributes(t *testing.T) {
+	schedule := &credentialschedule.Schedule{Timezone: "UTC", Windows: []string{"mon-fri 19:00-07:00"}}
+	auths, errSynthesize := NewConfigSynthesizer().Synthesize(&SynthesisContext{
+		Config:      &config.Config{ClaudeKey: []config.ClaudeKey{{APIKey: "key", Schedule: schedule}}},
+		Now:         time.Now(),
+		IDGenerator: NewStableIDGenerator(),
+	})
+	if errSynthesize != nil {
+		t.Fatalf("Synthesize() error = %v", errSynthesize)
+	}
+	if len(auths) != 1 {
+		t.Fatalf("auth count = %d, want 1", len(auths))
+	}
+	if got := auths[0].Attributes[coreauth.AttributeSchedule]; got != credentialschedule.Encode(schedule) {
+		t.Fatalf("schedule attribute = %q", got)
+	}
+
+	_, errSynthesize = NewConfigSynthesizer().Synthesize(&SynthesisContext{
+		Config:      &config.Config{CodexKey: []config.CodexKey{{APIKey: "key", Schedule: &credentialschedule.Schedule{Windows: []string{"mon 09:00-09:00"}}}}},
+		Now:         time.Now(),
+		IDGenerator: NewStableIDGenerator(),
+	})
+	if errSynthesize == nil || !strings.Contains(errSynthesize.Error(), "codex-api-key[0].schedule") {
+		t.Fatalf("Synthesize() error = %v, want the invalid schedule path", errSynthesize)
+	}
+}
@@ -18,6 +18,9 @@ type Result struct {
 	Provider  string
 	Principal string
 	Metadata  map[string]string
+	// PrincipalLoggable marks principals that are identities rather than secrets,
+	// so they may be written to request logs.
+	PrincipalLoggable bool
 }
 
 var (
@@ -28,6 +28,16 @@ const (
 	// AccessProviderTypeConfigAPIKey is the built-in provider validating inline API keys.
 	AccessProviderTypeConfigAPIKey = "config-api-key"
 
+	// AccessProviderTypeJWT is the built-in provider validating bearer JWTs against a JWKS.
+	AccessProviderTypeJWT = "jwt"
+
+	// AccessProviderTypeClientCert is the built-in provider authenticating TLS client certificates.
+	AccessProviderTypeClientCert = "client-cert"
+
+	// MetadataKeyAllowedModels carries a comma-separated model allow-list in Result.Metadata.
+	// Entries may use '*' wildcards; an absent key places no restriction on the client.
+	MetadataKeyAllowedModels = "allowed-models"
+
 	// DefaultAccessProviderName is applied when no provider name is supplied.
 	DefaultAccessProviderName = "config-inline"
 )
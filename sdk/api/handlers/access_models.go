@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/thinking"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v7/sdk/access"
+)
+
+// validateAccessModel enforces the model allow-list that an access provider
+// attached to the authenticated client. Requests without an allow-list, and
+// internal plugin calls, are not restricted.
+func validateAccessModel(ctx context.Context, modelName string, execOptions modelExecutionOptions) *interfaces.ErrorMessage {
+	if execOptions.InternalSource {
+		return nil
+	}
+	allowed := accessAllowedModels(ctx)
+	if len(allowed) == 0 {
+		return nil
+	}
+	model := strings.TrimSpace(modelName)
+	baseModel := strings.TrimSpace(thinking.ParseSuffix(model).ModelName)
+	for _, pattern := range allowed {
+		if matchAccessModel(pattern, model) || matchAccessModel(pattern, baseModel) {
+			return nil
+		}
+	}
+	return &interfaces.ErrorMessage{
+		StatusCode: http.StatusForbidden,
+		Error:      fmt.Errorf("model %q is not allowed for this client", model),
+	}
+}
+
+func accessAllowedModels(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil {
+		return nil
+	}
+	raw, exists := ginCtx.Get("accessMetadata")
+	if !exists {
+		return nil
+	}
+	metadata, ok := raw.(map[string]string)
+	if !ok {
+		return nil
+	}
+	var allowed []string
+	for _, entry := range strings.Split(metadata[sdkaccess.MetadataKeyAllowedModels], ",") {
+		if trimmed := strings.TrimSpace(entry); trimmed != "" {
+			allowed = append(allowed, trimmed)
+		}
+	}
+	return allowed
+}
+
+// matchAccessModel reports whether model matches pattern, where '*' matches any
+// run of characters. Matching is case-insensitive.
+func matchAccessModel(pattern, model string) bool {
+	pattern = strings.ToLower(pattern)
+	model = strings.ToLower(model)
+	if model == "" {
+		return false
+	}
+	if !strings.Contains(pattern, "*") {
+		return pattern == model
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(model, parts[0]) {
+		return false
+	}
+	model = model[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, segment := range parts[1 : len(parts)-1] {
+		index := strings.Index(model, segment)
+		if index < 0 {
+			return false
+		}
+		model = model[index+len(segment):]
+	}
+	return len(model) >= len(last) && strings.HasSuffix(model, last)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v7/sdk/access"
+)
+
+func accessModelContext(allowed string) context.Context {
+	gin.SetMode(gin.TestMode)
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	if allowed != "" {
+		ginCtx.Set("accessMetadata", map[string]string{sdkaccess.MetadataKeyAllowedModels: allowed})
+	}
+	return context.WithValue(context.Background(), "gin", ginCtx)
+}
+
+func TestValidateAccessModelEnforcesAllowList(t *testing.T) {
+	ctx := accessModelContext("gpt-5*,claude-sonnet-4")
+
+	for _, model := range []string{"gpt-5", "gpt-5.4-mini", "GPT-5-codex(high)", "claude-sonnet-4"} {
+		if errMsg := validateAccessModel(ctx, model, modelExecutionOptions{}); errMsg != nil {
+			t.Fatalf("validateAccessModel(%q) error = %v, want allowed", model, errMsg.Error)
+		}
+	}
+	errMsg := validateAccessModel(ctx, "gemini-2.5-pro", modelExecutionOptions{})
+	if errMsg == nil || errMsg.StatusCode != http.StatusForbidden {
+		t.Fatalf("validateAccessModel(gemini-2.5-pro) = %+v, want 403", errMsg)
+	}
+	if errMsg = validateAccessModel(ctx, "gemini-2.5-pro", modelExecutionOptions{InternalSource: true}); errMsg != nil {
+		t.Fatalf("validateAccessModel(internal) error = %v, want allowed", errMsg.Error)
+	}
+}
+
+func TestValidateAccessModelAllowsClientsWithoutAllowList(t *testing.T) {
+	if errMsg := validateAccessModel(accessModelContext(""), "any-model", modelExecutionOptions{}); errMsg != nil {
+		t.Fatalf("validateAccessModel() error = %v, want allowed", errMsg.Error)
+	}
+	if errMsg := validateAccessModel(context.Background(), "any-model", modelExecutionOptions{}); errMsg != nil {
+		t.Fatalf("validateAccessModel(no gin) error = %v, want allowed", errMsg.Error)
+	}
+}
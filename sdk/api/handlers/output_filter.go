@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/outputfilter"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/usage"
+	"golang.org/x/net/context"
+)
+
+// withOutputFilter prepares ctx to collect output redactions for one client
+// request. Usage records are held until finishOutputFilter so they can carry
+// the final redaction counts.
+func (h *BaseAPIHandler) withOutputFilter(ctx context.Context) context.Context {
+	if outputfilter.FromConfig(h.Cfg) == nil {
+		return ctx
+	}
+	ctx = outputfilter.WithStats(ctx)
+	return coreusage.WithPublishGate(ctx)
+}
+
+// finishOutputFilter records the request's redactions in the request log and
+// releases held usage records.
+func (h *BaseAPIHandler) finishOutputFilter(c *gin.Context, ctx context.Context) {
+	stats := outputfilter.StatsFromContext(ctx)
+	if stats == nil {
+		return
+	}
+	counts := stats.Counts()
+	if c != nil && h.Cfg != nil && h.Cfg.RequestLog {
+		appendAPIResponse(c, stats.LogSection())
+	}
+	coreusage.ReleasePublishGate(ctx, func(record *coreusage.Record) {
+		if len(counts) > 0 {
+			record.Redactions = counts
+		}
+	})
+}
+
+// filterOutputBody redacts a complete response body. It runs where the shared
+// execution paths return, so client handlers and host model callbacks alike
+// receive filtered output.
+func (h *BaseAPIHandler) filterOutputBody(ctx context.Context, body []byte) []byte {
+	filter := outputfilter.FromConfig(h.Cfg)
+	if filter == nil {
+		return body
+	}
+	return filter.ApplyBody(body, outputfilter.StatsFromContext(ctx))
+}
+
+// filterOutputStream redacts translated stream chunks before any consumer sees
+// them, so ForwardStream, first-chunk peeks, the Responses websocket forwarder
+// and ExecuteModelStream all emit filtered output. Chunks are held back by the filter's
+// rolling window and released in order.
+func (h *BaseAPIHandler) filterOutputStream(ctx context.Context, data <-chan []byte) <-chan []byte {
+	filter := outputfilter.FromConfig(h.Cfg)
+	if filter == nil || data == nil {
+		return data
+	}
+	stream := filter.NewStream(outputfilter.StatsFromContext(ctx))
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	out := make(chan []byte)
+	go func() {
+		defer close(out)
+		send := func(chunks [][]byte) bool {
+			for _, chunk := range chunks {
+				if len(chunk) == 0 {
+					continue
+				}
+				select {
+				case out <- chunk:
+				case <-done:
+					return false
+				}
+			}
+			return true
+		}
+		for {
+			select {
+			case chunk, ok := <-data:
+				if !ok {
+					send(stream.Flush())
+					return
+				}
+				if !send(stream.Push(chunk)) {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return out
+}
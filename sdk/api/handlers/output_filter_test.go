@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/outputfilter"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/config"
+)
+
+func outputFilterTestHandler() *BaseAPIHandler {
+	return &BaseAPIHandler{Cfg: &config.SDKConfig{
+		RequestLog: true,
+		OutputFilter: config.OutputFilterConfig{
+			Enabled: true,
+			Window:  8,
+			Rules:   []config.OutputFilterRule{{Name: "secret", Pattern: `secret-[0-9]+`}},
+		},
+	}}
+}
+
+func TestFilterOutputStreamRedactsAcrossChunks(t *testing.T) {
+	h := outputFilterTestHandler()
+	ctx := outputfilter.WithStats(context.Background())
+	data := make(chan []byte, 3)
+	data <- []byte(`{"delta":"value secret-12"}`)
+	data <- []byte(`{"delta":"34 end of text"}`)
+	close(data)
+
+	var joined bytes.Buffer
+	for chunk := range h.filterOutputStream(ctx, data) {
+		joined.Write(chunk)
+	}
+
+	if bytes.Contains(joined.Bytes(), []byte("secret-")) || !bytes.Contains(joined.Bytes(), []byte("[REDACTED]")) {
+		t.Fatalf("stream output = %s, want secret redacted", joined.String())
+	}
+	if got := outputfilter.StatsFromContext(ctx).Total(); got != 1 {
+		t.Fatalf("redactions = %d, want 1", got)
+	}
+}
+
+func TestGetContextWithCancelRecordsRedactionsInRequestLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := outputFilterTestHandler()
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	ctx, cancel := h.GetContextWithCancel(nil, c, context.Background())
+	body := h.filterOutputBody(ctx, []byte(`{"content":"secret-1 and secret-2"}`))
+	cancel()
+
+	if !bytes.Contains(body, []byte(`"[REDACTED] and [REDACTED]"`)) {
+		t.Fatalf("body = %s, want redacted", body)
+	}
+	logged, _ := c.Get("API_RESPONSE")
+	loggedBytes, _ := logged.([]byte)
+	if !bytes.Contains(loggedBytes, []byte("=== OUTPUT REDACTIONS ===\nsecret: 2")) {
+		t.Fatalf("API_RESPONSE = %q, want redaction section", loggedBytes)
+	}
+}
+
+func TestExecuteModelAppliesOutputFilter(t *testing.T) {
+	model := "output-filter-model"
+	executor := &modelExecutionCaptureExecutor{
+		execute: func(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+			return coreexecutor.Response{Payload: []byte(`{"content":"secret-7"}`)}, nil
+		},
+		stream: func(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+			chunks := make(chan coreexecutor.StreamChunk, 2)
+			chunks <- coreexecutor.StreamChunk{Payload: []byte(`{"delta":"value secret-12"}`)}
+			chunks <- coreexecutor.StreamChunk{Payload: []byte(`{"delta":"34 end of text"}`)}
+			close(chunks)
+			return &coreexecutor.StreamResult{Chunks: chunks}, nil
+		},
+	}
+	handler := newModelExecutionHandler(t, model, executor, outputFilterTestHandler().Cfg)
+
+	resp, errMsg := handler.ExecuteModel(context.Background(), ModelExecutionRequest{
+		EntryProtocol: "openai",
+		Model:         model,
+		Body:          []byte(fmt.Sprintf(`{"model":%q}`, model)),
+	})
+	if errMsg != nil {
+		t.Fatalf("ExecuteModel() error = %+v", errMsg)
+	}
+	if !bytes.Contains(resp.Body, []byte(`"[REDACTED]"`)) {
+		t.Fatalf("ExecuteModel() body = %s, want secret redacted", resp.Body)
+	}
+
+	ctx := outputfilter.WithStats(context.Background())
+	stream, errMsg := handler.ExecuteModelStream(ctx, ModelExecutionRequest{
+		EntryProtocol: "openai",
+		Model:         model,
+		Stream:        true,
+		Body:          []byte(fmt.Sprintf(`{"model":%q,"stream":true}`, model)),
+	})
+	if errMsg != nil {
+		t.Fatalf("ExecuteModelStream() error = %+v", errMsg)
+	}
+	var joined bytes.Buffer
+	for chunk := range stream.Chunks {
+		if chunk.Err != nil {
+			t.Fatalf("stream chunk error = %+v", chunk.Err)
+		}
+		joined.Write(chunk.Payload)
+	}
+	if bytes.Contains(joined.Bytes(), []byte("secret-")) || !bytes.Contains(joined.Bytes(), []byte("[REDACTED]")) {
+		t.Fatalf("ExecuteModelStream() output = %s, want secret redacted", joined.String())
+	}
+	if got := outputfilter.StatsFromContext(ctx).Total(); got != 1 {
+		t.Fatalf("stream redactions = %d, want 1", got)
+	}
+}
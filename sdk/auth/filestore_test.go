@@ -1,11 +1,13 @@
 package auth
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/envelope"
 	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
 	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
 )
@@ -146,6 +148,48 @@ func TestFileTokenStoreSaveExistingMetadataSetsFileAttributes(t *testing.T) {
 	}
 }
 
+func TestFileTokenStoreSealsAuthFilesWhenEncryptionEnabled(t *testing.T) {
+	provider, errProvider := envelope.NewLocalKeyProvider(bytes.Repeat([]byte{9}, 32))
+	if errProvider != nil {
+		t.Fatalf("NewLocalKeyProvider() error = %v", errProvider)
+	}
+	envelope.Configure(provider)
+	t.Cleanup(func() { envelope.Configure(nil) })
+
+	baseDir := t.TempDir()
+	fileName := "codex-user.json"
+	path := filepath.Join(baseDir, fileName)
+	// A legacy plaintext file with the same content is rewritten sealed.
+	if errWrite := os.WriteFile(path, []byte(`{"type":"codex","refresh_token":"rt-secret","disabled":false}`), 0o600); errWrite != nil {
+		t.Fatalf("write existing auth file: %v", errWrite)
+	}
+	store := NewFileTokenStore()
+	store.SetBaseDir(baseDir)
+	auth := &cliproxyauth.Auth{
+		ID:       fileName,
+		FileName: fileName,
+		Metadata: map[string]any{"type": "codex", "refresh_token": "rt-secret"},
+	}
+	if _, errSave := store.Save(context.Background(), auth); errSave != nil {
+		t.Fatalf("Save() error = %v", errSave)
+	}
+	persisted, errRead := os.ReadFile(path)
+	if errRead != nil {
+		t.Fatalf("read saved auth file: %v", errRead)
+	}
+	if !envelope.IsSealed(persisted) || bytes.Contains(persisted, []byte("rt-secret")) {
+		t.Fatalf("saved auth file is not sealed: %s", persisted)
+	}
+
+	auths, errList := store.List(context.Background())
+	if errList != nil || len(auths) != 1 {
+		t.Fatalf("List() = %v, %v", auths, errList)
+	}
+	if got := auths[0].Metadata["refresh_token"]; got != "rt-secret" {
+		t.Fatalf("listed refresh_token = %v", got)
+	}
+}
+
 func TestFileTokenStoreNormalizesLegacyCredentialMetadata(t *testing.T) {
 	t.Run("save", func(t *testing.T) {
 		baseDir := t.TempDir()
@@ -4,11 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/envelope"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
 )
 
@@ -77,7 +77,7 @@ func (m *Manager) Login(ctx context.Context, provider string, cfg *config.Config
 			}
 			if targetFile != "" {
 				fullPath := filepath.Join(cfg.AuthDir, targetFile)
-				if raw, errRead := os.ReadFile(fullPath); errRead == nil && len(raw) > 0 {
+				if raw, errRead := envelope.ReadFile(fullPath); errRead == nil && len(raw) > 0 {
 					var existingMap map[string]any
 					if errUnmarshal := json.Unmarshal(raw, &existingMap); errUnmarshal == nil && len(existingMap) > 0 {
 						coreauth.MergeExistingAuthMetadata(record, existingMap)
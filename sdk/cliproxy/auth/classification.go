@@ -19,6 +19,7 @@ const (
 	AttributeConfigIndex      = "config_index"
 	AttributePath             = "path"
 	AttributeRuntimeOnly      = "runtime_only"
+	AttributeSchedule         = "schedule"
 	AttributeSource           = "source"
 	AttributeSourceBackend    = "source_backend"
 	AttributeWeight           = "weight"
@@ -58,6 +58,8 @@ type Result struct {
 	CredentialScope bool
 	// Error describes the failure when Success is false.
 	Error *Error
+	// Headers carries upstream response headers so rate-limit headroom can be tracked.
+	Headers http.Header
 	// Options carries execution request options (headers, metadata, etc.) for result tracking.
 	Options cliproxyexecutor.Options
 }
@@ -158,6 +160,21 @@ type Manager struct {
 	refreshLoop   *authAutoRefreshLoop
 
 	requestPrepareLocks sync.Map
+
+	// cooldownEventDeadlines holds the pending cooldown end per "authID\x00model"
+	// so auth.cooldown.exited is published once per cooldown.
+	cooldownEventDeadlines sync.Map
+	// modelCoolingEventDeadlines holds the reported reset time per
+	// "provider\x00model" so models.cooling is published once per cooldown.
+	modelCoolingEventDeadlines sync.Map
+
+	// health holds credential probe results and the scheduled prober state.
+	health healthCheckState
+	// drain tracks the monitor that disables credentials once drained.
+	drain drainMonitorState
+	// quotaWindows persists observed rate-limit windows across restarts.
+	quotaWindows quotaWindowState
+
 	// refreshLocks serializes credential refresh per auth ID so concurrent
 	// 401 recoveries and auto-refresh workers do not race the same refresh_token.
 	refreshLocks sync.Map
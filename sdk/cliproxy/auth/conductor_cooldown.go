@@ -347,6 +347,7 @@ func (m *Manager) restoreCooldownRecordLocked(record CooldownStateRecord, now ti
 	reason := strings.TrimSpace(record.Reason)
 	model := strings.TrimSpace(record.Model)
 	quota := record.Quota
+	quota.Limits = auth.Quota.Limits
 	if quota.Exceeded && quota.NextRecoverAt.IsZero() {
 		quota.NextRecoverAt = record.NextRetryAfter
 	}
@@ -386,7 +387,7 @@ func clearCooldownStateForAuth(auth *Auth, now time.Time) bool {
 	if auth.Unavailable || !auth.NextRetryAfter.IsZero() || auth.Quota.Exceeded || !auth.Quota.NextRecoverAt.IsZero() {
 		auth.Unavailable = false
 		auth.NextRetryAfter = time.Time{}
-		auth.Quota = QuotaState{}
+		auth.Quota = QuotaState{Limits: auth.Quota.Limits}
 		auth.UpdatedAt = now
 		changed = true
 	}
@@ -482,7 +483,7 @@ func (m *Manager) ResetQuota(ctx context.Context, authID string) (*Auth, []strin
 	if !auth.Disabled && auth.Status != StatusDisabled && !hasModelError(auth, now) {
 		auth.LastError = nil
 		auth.StatusMessage = ""
-		auth.Status = StatusActive
+		auth.Status = activeStatusFor(auth)
 	}
 	auth.UpdatedAt = now
 	if errPersist := m.persist(ctx, auth); errPersist != nil {
@@ -714,10 +715,14 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 	setModelQuota := false
 	var authSnapshot *Auth
 	cooldownStateChanged := false
+	quotaWindowsChanged := false
+	var eventsBefore authEventState
+	var eventsNow time.Time
 
 	m.mu.Lock()
 	if auth, ok := m.auths[result.AuthID]; ok && auth != nil {
 		now := time.Now()
+		eventsBefore, eventsNow = captureAuthEventState(auth, modelKey, now), now
 		var cooldownRecordsBefore []CooldownStateRecord
 		trackCooldownState := m.cooldownStore != nil
 		if trackCooldownState {
@@ -740,7 +745,7 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 				if !hasModelError(auth, now) {
 					auth.LastError = nil
 					auth.StatusMessage = ""
-					auth.Status = StatusActive
+					auth.Status = activeStatusFor(auth)
 				}
 				auth.UpdatedAt = now
 				shouldResumeModel = true
@@ -904,6 +909,10 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 				applyAuthFailureState(auth, result.Error, result.RetryAfter, now, disableCooling)
 			}
 		}
+		if len(result.Headers) > 0 {
+			windows := parseProviderRateLimitHeaders(auth.Provider, modelKey, result.Headers, now)
+			quotaWindowsChanged = applyQuotaWindowsLocked(auth, windows, QuotaSourceHeaders, m.quotaCooldownPolicy(auth), now)
+		}
 
 		_ = m.persist(ctx, auth)
 		authSnapshot = auth.Clone()
@@ -919,6 +928,9 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 	if authSnapshot != nil && cooldownStateChanged {
 		m.persistCooldownStates(context.Background())
 	}
+	if quotaWindowsChanged {
+		m.markQuotaWindowsDirty(ctx)
+	}
 
 	if clearModelQuota && modelKey != "" {
 		registry.GetGlobalRegistry().ClearModelQuotaExceeded(result.AuthID, modelKey)
@@ -934,6 +946,9 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 
 	m.hook.OnResult(ctx, result)
 	m.publishErrorEvent(result, authSnapshot)
+	if authSnapshot != nil {
+		m.publishAuthEvents(eventsBefore, authSnapshot, eventsNow)
+	}
 	m.updateSessionAffinity(result)
 }
 
@@ -1210,7 +1225,7 @@ func clearAggregatedAvailability(auth *Auth) {
 	}
 	auth.Unavailable = false
 	auth.NextRetryAfter = time.Time{}
-	auth.Quota = QuotaState{}
+	auth.Quota = QuotaState{Limits: auth.Quota.Limits}
 }
 
 func hasModelError(auth *Auth, now time.Time) bool {
@@ -1238,7 +1253,7 @@ func clearAuthStateOnSuccess(auth *Auth, now time.Time) {
 		return
 	}
 	auth.Unavailable = false
-	auth.Status = StatusActive
+	auth.Status = activeStatusFor(auth)
 	auth.StatusMessage = ""
 	auth.Quota.Exceeded = false
 	auth.Quota.Reason = ""
@@ -1444,6 +1459,21 @@ func retryAfterFromError(err error) *time.Duration {
 	return &value
 }
 
+// headersFromError returns upstream response headers attached to an executor error.
+func headersFromError(err error) http.Header {
+	if err == nil {
+		return nil
+	}
+	type headersProvider interface {
+		Headers() http.Header
+	}
+	var hp headersProvider
+	if !errors.As(err, &hp) || hp == nil {
+		return nil
+	}
+	return hp.Headers()
+}
+
 func isCredentialScopedError(err error) bool {
 	if err == nil {
 		return false
@@ -1899,6 +1929,7 @@ func applyAuthFailureState(auth *Auth, resultErr *Error, retryAfter *time.Durati
 			Reason:        "cloudflare challenge",
 			NextRecoverAt: next,
 			BackoffLevel:  backoffLevel,
+			Limits:        auth.Quota.Limits,
 		}
 		auth.NextRetryAfter = next
 		return
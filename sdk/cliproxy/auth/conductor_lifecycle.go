@@ -116,6 +116,7 @@ func (m *Manager) Update(ctx context.Context, auth *Auth) (*Auth, error) {
 		m.mu.Unlock()
 		return nil, nil
 	}
+	eventsBefore := captureAuthEventState(existing, "", time.Now())
 	if !auth.indexAssigned && auth.Index == "" {
 		auth.Index = existing.Index
 		auth.indexAssigned = existing.indexAssigned
@@ -136,6 +137,12 @@ func (m *Manager) Update(ctx context.Context, auth *Auth) (*Auth, error) {
 			}
 		}
 	}
+	if !drainChangeFromContext(ctx) {
+		carryOverDrain(existing, auth)
+	}
+	if auth.Disabled || auth.Status == StatusDisabled {
+		auth.DrainingUntil = time.Time{}
+	}
 	now := time.Now()
 	cooldownStateChanged := normalizeModelStates(auth)
 	if m.cooldownDisabledForAuth(auth) || auth.Disabled || auth.Status == StatusDisabled {
@@ -154,6 +161,7 @@ func (m *Manager) Update(ctx context.Context, auth *Auth) (*Auth, error) {
 	m.queueRefreshReschedule(auth.ID)
 	_ = m.persist(ctx, auth)
 	m.hook.OnAuthUpdated(ctx, auth.Clone())
+	m.publishAuthEvents(eventsBefore, authClone, now)
 	if cooldownStateChanged {
 		m.persistCooldownStates(ctx)
 	}
@@ -180,6 +188,7 @@ func (m *Manager) Remove(ctx context.Context, id string) {
 	}
 	provider := strings.TrimSpace(existing.Provider)
 	delete(m.auths, id)
+	m.forgetHealthCheck(id)
 	if m.modelPoolOffsets != nil {
 		delete(m.modelPoolOffsets, id)
 	}
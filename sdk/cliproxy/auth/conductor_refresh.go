@@ -539,8 +539,11 @@ func (m *Manager) refreshAuthForRequest(ctx context.Context, id, failedAccessTok
 	if err != nil {
 		unauthorized := isUnauthorizedError(err)
 		shouldReschedule := false
+		var eventsBefore authEventState
+		var failedSnapshot *Auth
 		m.mu.Lock()
 		if current := m.auths[id]; current != nil {
+			eventsBefore = captureAuthEventState(current, "", now)
 			current.LastError = refreshErrorFromError(err)
 			if unauthorized {
 				current.NextRefreshAfter = time.Time{}
@@ -552,6 +555,7 @@ func (m *Manager) refreshAuthForRequest(ctx context.Context, id, failedAccessTok
 			}
 			m.auths[id] = current
 			shouldReschedule = true
+			failedSnapshot = current.Clone()
 			if m.scheduler != nil {
 				m.scheduler.upsertAuth(current.Clone())
 			}
@@ -560,6 +564,10 @@ func (m *Manager) refreshAuthForRequest(ctx context.Context, id, failedAccessTok
 		if shouldReschedule {
 			m.queueRefreshReschedule(id)
 		}
+		if failedSnapshot != nil {
+			publishRefreshEvent(failedSnapshot, err)
+			m.publishAuthEvents(eventsBefore, failedSnapshot, now)
+		}
 		return nil, err
 	}
 	if updated == nil {
@@ -576,7 +584,7 @@ func (m *Manager) refreshAuthForRequest(ctx context.Context, id, failedAccessTok
 	updated.StatusMessage = ""
 	updated.Unavailable = false
 	if updated.Status == StatusError {
-		updated.Status = StatusActive
+		updated.Status = activeStatusFor(updated)
 	}
 	updated.UpdatedAt = now
 	modelsToResume := clearUnauthorizedModelStates(updated, now)
@@ -591,7 +599,9 @@ func (m *Manager) refreshAuthForRequest(ctx context.Context, id, failedAccessTok
 		log.Debugf("persist refreshed auth %s (%s) failed: %v", auth.Provider, auth.ID, errUpdate)
 	}
 	if saved != nil {
+		publishRefreshEvent(saved, nil)
 		return saved, nil
 	}
+	publishRefreshEvent(updated, nil)
 	return updated.Clone(), nil
 }
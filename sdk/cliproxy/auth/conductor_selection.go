@@ -220,7 +220,7 @@ func (m *Manager) ReconcileRegistryModelStates(ctx context.Context, authID strin
 			if !hasModelError(auth, now) {
 				auth.LastError = nil
 				auth.StatusMessage = ""
-				auth.Status = StatusActive
+				auth.Status = activeStatusFor(auth)
 			}
 			auth.UpdatedAt = now
 			if errPersist := m.persist(ctx, auth); errPersist != nil {
@@ -373,7 +373,8 @@ func (m *Manager) availableAuthsForRouteModelWithPriorityMode(auths []*Auth, pro
 // recovered higher-priority credential.
 func (m *Manager) availableAuthsForSelector(selector Selector, auths []*Auth, provider, routeModel string, now time.Time) (priorityAuths, selectorAuths []*Auth, err error) {
 	if _, sessionAffinity := selector.(*SessionAffinitySelector); !sessionAffinity {
-		priorityAuths, err = m.availableAuthsForRouteModel(auths, provider, routeModel, now)
+		// Draining auths only serve session bindings, which other selectors do not keep.
+		priorityAuths, err = m.availableAuthsForRouteModel(withoutDrainingAuths(auths), provider, routeModel, now)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -387,8 +388,10 @@ func (m *Manager) availableAuthsForSelector(selector Selector, auths []*Auth, pr
 	if err != nil {
 		return nil, nil, err
 	}
+	// Draining auths stay in the selector list so their bound sessions keep
+	// resolving, but are never offered as cold picks.
 	selectorAuths = cloneAuthSlice(selectorAuths)
-	return highestPriorityAuths(selectorAuths), selectorAuths, nil
+	return highestPriorityAuths(withoutDrainingAuths(selectorAuths)), selectorAuths, nil
 }
 
 func selectionArgForSelector(selector Selector, routeModel string) string {
@@ -745,7 +748,7 @@ func retryRoundAvailabilityForAuth(auth *Auth, model string, now time.Time) (boo
 	if !blocked {
 		return true, time.Time{}
 	}
-	if auth == nil || next.IsZero() || reason == blockReasonDisabled {
+	if auth == nil || next.IsZero() || reason == blockReasonDisabled || reason == blockReasonOffSchedule {
 		return false, time.Time{}
 	}
 	if auth.Quota.Exceeded && auth.Quota.Reason == "credential_quota" && auth.Quota.NextRecoverAt.After(now) {
@@ -1781,6 +1784,7 @@ func (m *Manager) warnLogAuthUnavailable(ctx context.Context, providers []string
 		return
 	}
 	now := time.Now()
+	m.publishModelCoolingEvent(err, tried, now)
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	eligibility := authSelectionEligibilityForRequest(ctx, opts)
@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultDrainTimeout bounds a drain started without an explicit deadline.
+const DefaultDrainTimeout = time.Hour
+
+// drainCheckTick is how often the drain monitor looks for drained credentials.
+const drainCheckTick = 10 * time.Second
+
+// Status messages of credentials disabled by the drain monitor.
+const (
+	drainedIdleMessage     = "drained: no bound sessions left"
+	drainedDeadlineMessage = "drained: drain deadline reached"
+)
+
+type drainMonitorState struct {
+	mu      sync.Mutex
+	running bool
+}
+
+type drainChangeContextKey struct{}
+
+// withDrainChange marks an Update that deliberately sets or clears a drain, so
+// the previous drain is not carried over.
+func withDrainChange(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, drainChangeContextKey{}, true)
+}
+
+func drainChangeFromContext(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	changed, _ := ctx.Value(drainChangeContextKey{}).(bool)
+	return changed
+}
+
+// IsDraining reports whether the auth only serves its existing session bindings.
+func (a *Auth) IsDraining() bool {
+	return a != nil && !a.Disabled && a.Status != StatusDisabled && !a.DrainingUntil.IsZero()
+}
+
+// activeStatusFor is the status an auth returns to once it is healthy again.
+func activeStatusFor(auth *Auth) Status {
+	if auth.IsDraining() {
+		return StatusDraining
+	}
+	return StatusActive
+}
+
+// carryOverDrain keeps a running drain across updates that rebuild the auth,
+// such as auth file reloads, which know nothing about runtime drain state.
+func carryOverDrain(existing, auth *Auth) {
+	if !existing.IsDraining() || !auth.DrainingUntil.IsZero() || auth.Disabled || auth.Status == StatusDisabled {
+		return
+	}
+	auth.DrainingUntil = existing.DrainingUntil
+	if auth.Status == StatusActive || auth.Status == "" {
+		auth.Status = StatusDraining
+	}
+}
+
+// withoutDrainingAuths drops draining auths, which take no cold selections.
+func withoutDrainingAuths(auths []*Auth) []*Auth {
+	draining := 0
+	for _, auth := range auths {
+		if auth.IsDraining() {
+			draining++
+		}
+	}
+	if draining == 0 {
+		return auths
+	}
+	filtered := make([]*Auth, 0, len(auths)-draining)
+	for _, auth := range auths {
+		if !auth.IsDraining() {
+			filtered = append(filtered, auth)
+		}
+	}
+	return filtered
+}
+
+// SetDraining starts draining authID until deadline, or ends a running drain
+// when deadline is zero. A draining auth takes no cold selections and no new
+// session bindings, keeps serving the sessions already bound to it, and is
+// disabled once those idle out or the deadline passes.
+func (m *Manager) SetDraining(ctx context.Context, authID string, deadline time.Time) (*Auth, error) {
+	authID = strings.TrimSpace(authID)
+	current, ok := m.GetByID(authID)
+	if !ok || current == nil {
+		return nil, &Error{Code: "auth_not_found", Message: "auth not found", HTTPStatus: http.StatusNotFound}
+	}
+	if deadline.IsZero() {
+		if !current.IsDraining() {
+			return current, nil
+		}
+		current.DrainingUntil = time.Time{}
+		if current.Status == StatusDraining {
+			current.Status = StatusActive
+		}
+	} else {
+		if current.Disabled || current.Status == StatusDisabled {
+			return nil, &Error{Code: "auth_disabled", Message: "cannot drain a disabled auth", HTTPStatus: http.StatusConflict}
+		}
+		current.DrainingUntil = deadline
+		if current.Status == StatusActive || current.Status == "" {
+			current.Status = StatusDraining
+		}
+	}
+	current.UpdatedAt = time.Now()
+	updated, errUpdate := m.Update(withDrainChange(ctx), current)
+	if errUpdate != nil {
+		return nil, errUpdate
+	}
+	if updated == nil {
+		return nil, &Error{Code: "auth_not_found", Message: "auth not found", HTTPStatus: http.StatusNotFound}
+	}
+	if deadline.IsZero() {
+		log.Infof("auth %s: drain cancelled", authID)
+		return updated, nil
+	}
+	log.Infof("auth %s: draining until %s", authID, deadline.Format(time.RFC3339))
+	m.ensureDrainMonitor()
+	return updated, nil
+}
+
+// ensureDrainMonitor starts the drain monitor unless it is already running.
+// The monitor exits on its own once no credential is draining.
+func (m *Manager) ensureDrainMonitor() {
+	m.drain.mu.Lock()
+	defer m.drain.mu.Unlock()
+	if m.drain.running {
+		return
+	}
+	m.drain.running = true
+	go func() {
+		ticker := time.NewTicker(drainCheckTick)
+		defer ticker.Stop()
+		for range ticker.C {
+			m.finishDrains(context.Background(), time.Now())
+			m.drain.mu.Lock()
+			if m.drainingCount() == 0 {
+				m.drain.running = false
+				m.drain.mu.Unlock()
+				return
+			}
+			m.drain.mu.Unlock()
+		}
+	}()
+}
+
+func (m *Manager) drainingCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	count := 0
+	for _, auth := range m.auths {
+		if auth.IsDraining() {
+			count++
+		}
+	}
+	return count
+}
+
+// finishDrains disables every draining credential that has no bound session
+// left or whose deadline has passed, and returns the IDs it disabled.
+func (m *Manager) finishDrains(ctx context.Context, now time.Time) []string {
+	cache, _ := m.sessionAffinityCache()
+	type drained struct {
+		auth    *Auth
+		message string
+	}
+	var due []drained
+	m.mu.RLock()
+	for _, auth := range m.auths {
+		if !auth.IsDraining() {
+			continue
+		}
+		switch {
+		case !now.Before(auth.DrainingUntil):
+			due = append(due, drained{auth: auth.Clone(), message: drainedDeadlineMessage})
+		case cache.authBindingCount(auth.ID, now) == 0:
+			due = append(due, drained{auth: auth.Clone(), message: drainedIdleMessage})
+		}
+	}
+	m.mu.RUnlock()
+
+	finished := make([]string, 0, len(due))
+	for _, item := range due {
+		auth := item.auth
+		auth.Disabled = true
+		auth.Status = StatusDisabled
+		auth.StatusMessage = item.message
+		auth.DrainingUntil = time.Time{}
+		auth.UpdatedAt = now
+		if auth.Metadata == nil {
+			auth.Metadata = make(map[string]any)
+		}
+		auth.Metadata["disabled"] = true
+		if _, errUpdate := m.Update(withDrainChange(ctx), auth); errUpdate != nil {
+			log.Warnf("auth %s: failed to disable drained auth: %v", auth.ID, errUpdate)
+			continue
+		}
+		if cache != nil {
+			cache.InvalidateAuth(auth.ID)
+		}
+		log.Infof("auth %s: %s", auth.ID, item.message)
+		finished = append(finished, auth.ID)
+	}
+	return finished
+}
+
+// authBindingCount returns how many live bindings point at authID.
+func (c *SessionCache) authBindingCount(authID string, now time.Time) int {
+	if c == nil {
+		return 0
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	groups := make(map[string]struct{})
+	for _, entry := range c.entries {
+		if entry.authID == authID && now.Before(entry.expiresAt) {
+			groups[sessionGroupKey(entry)] = struct{}{}
+		}
+	}
+	return len(groups)
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+)
+
+func sessionPickOptions(sessionID string) cliproxyexecutor.Options {
+	return cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.ExecutionSessionMetadataKey: sessionID}}
+}
+
+func TestDrainingAuthTakesNoColdSelections(t *testing.T) {
+	const provider, model = "drain-provider", "drain-model"
+	m := NewManager(nil, &RoundRobinSelector{}, nil)
+	m.RegisterExecutor(&healthProbeExecutor{provider: provider})
+	registerExplainAuth(t, m, &Auth{ID: "drain-a", Provider: provider, Status: StatusActive}, model)
+	registerExplainAuth(t, m, &Auth{ID: "drain-b", Provider: provider, Status: StatusActive}, model)
+
+	drained, errDrain := m.SetDraining(context.Background(), "drain-a", time.Now().Add(time.Hour))
+	if errDrain != nil || drained.Status != StatusDraining {
+		t.Fatalf("SetDraining() = %+v, %v", drained, errDrain)
+	}
+	for i := 0; i < 4; i++ {
+		picked, _, errPick := m.pickNext(context.Background(), provider, model, cliproxyexecutor.Options{}, nil)
+		if errPick != nil || picked.ID != "drain-b" {
+			t.Fatalf("pick %d = %v (%v), want drain-b", i, picked, errPick)
+		}
+	}
+	if _, _, errPick := m.pickNext(context.Background(), provider, model, cliproxyexecutor.Options{}, map[string]struct{}{"drain-b": {}}); errPick == nil {
+		t.Fatal("expected no pick when only the draining auth remains")
+	}
+
+	explanation, _ := m.ExplainRouting(context.Background(), RoutingExplainRequest{Providers: []string{provider}, Model: model})
+	if candidate := explainCandidate(t, explanation, "drain-a"); !slices.Contains(candidate.Reasons, RoutingReasonDraining) || candidate.DrainingUntil == nil {
+		t.Fatalf("draining candidate = %+v", candidate)
+	}
+
+	if _, errCancel := m.SetDraining(context.Background(), "drain-a", time.Time{}); errCancel != nil {
+		t.Fatalf("cancel drain error = %v", errCancel)
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < 4; i++ {
+		picked, _, _ := m.pickNext(context.Background(), provider, model, cliproxyexecutor.Options{}, nil)
+		if picked != nil {
+			seen[picked.ID] = true
+		}
+	}
+	if !seen["drain-a"] {
+		t.Fatalf("picks after cancelling the drain = %v, want drain-a back in rotation", seen)
+	}
+}
+
+func TestDrainingAuthKeepsBoundSessions(t *testing.T) {
+	const provider, model = "drain-session-provider", "drain-session-model"
+	selector := NewSessionAffinitySelector(&RoundRobinSelector{})
+	t.Cleanup(selector.Stop)
+	m := NewManager(nil, selector, nil)
+	m.RegisterExecutor(&healthProbeExecutor{provider: provider})
+	registerExplainAuth(t, m, &Auth{ID: "drain-bound", Provider: provider, Status: StatusActive}, model)
+	registerExplainAuth(t, m, &Auth{ID: "drain-other", Provider: provider, Status: StatusActive}, model)
+
+	bound, _, errPick := m.pickNext(context.Background(), provider, model, sessionPickOptions("drain-session-1"), nil)
+	if errPick != nil {
+		t.Fatalf("first pick error = %v", errPick)
+	}
+	other := "drain-other"
+	if bound.ID == other {
+		other = "drain-bound"
+	}
+	if _, errDrain := m.SetDraining(context.Background(), bound.ID, time.Now().Add(time.Hour)); errDrain != nil {
+		t.Fatalf("SetDraining() error = %v", errDrain)
+	}
+
+	again, _, errPick := m.pickNext(context.Background(), provider, model, sessionPickOptions("drain-session-1"), nil)
+	if errPick != nil || again.ID != bound.ID {
+		t.Fatalf("bound session picked %v (%v), want draining %s", again, errPick, bound.ID)
+	}
+	for i := 0; i < 4; i++ {
+		fresh, _, errFresh := m.pickNext(context.Background(), provider, model, sessionPickOptions("drain-new-session-"+string(rune('a'+i))), nil)
+		if errFresh != nil || fresh.ID != other {
+			t.Fatalf("new session picked %v (%v), want %s", fresh, errFresh, other)
+		}
+	}
+
+	var authErr *Error
+	if _, errRebind := m.RebindSession("drain-new-session-a", "", bound.ID); !errors.As(errRebind, &authErr) || authErr.HTTPStatus != http.StatusConflict {
+		t.Fatalf("RebindSession() onto a draining auth error = %v, want 409", errRebind)
+	}
+	if finished := m.finishDrains(context.Background(), time.Now()); len(finished) != 0 {
+		t.Fatalf("finishDrains() = %v while a session is still bound", finished)
+	}
+
+	selector.cache.InvalidateAuth(bound.ID)
+	if finished := m.finishDrains(context.Background(), time.Now()); !slices.Equal(finished, []string{bound.ID}) {
+		t.Fatalf("finishDrains() = %v, want %s once its sessions idled out", finished, bound.ID)
+	}
+	if current, _ := m.GetByID(bound.ID); !current.Disabled || current.Status != StatusDisabled || current.StatusMessage != drainedIdleMessage {
+		t.Fatalf("drained auth = %+v, want disabled", current)
+	}
+}
+
+func TestDrainDeadlineDisablesAndDropsBindings(t *testing.T) {
+	const provider = "drain-deadline-provider"
+	selector := NewSessionAffinitySelector(&RoundRobinSelector{})
+	t.Cleanup(selector.Stop)
+	m := NewManager(nil, selector, nil)
+	registerExplainAuth(t, m, &Auth{ID: "drain-deadline", Provider: provider, Status: StatusActive}, "")
+	selector.cache.Set("mixed::header:abc::drain-model", "drain-deadline")
+
+	deadline := time.Now().Add(time.Minute)
+	if _, errDrain := m.SetDraining(context.Background(), "drain-deadline", deadline); errDrain != nil {
+		t.Fatalf("SetDraining() error = %v", errDrain)
+	}
+	if finished := m.finishDrains(context.Background(), time.Now()); len(finished) != 0 {
+		t.Fatalf("finishDrains() before the deadline = %v", finished)
+	}
+	if finished := m.finishDrains(context.Background(), deadline.Add(time.Second)); len(finished) != 1 {
+		t.Fatalf("finishDrains() after the deadline = %v", finished)
+	}
+	if current, _ := m.GetByID("drain-deadline"); current.StatusMessage != drainedDeadlineMessage || current.Metadata["disabled"] != true {
+		t.Fatalf("drained auth = %+v", current)
+	}
+	if bindings := selector.cache.Bindings(); len(bindings) != 0 {
+		t.Fatalf("bindings after the drain deadline = %+v", bindings)
+	}
+}
+
+func TestDrainSurvivesAuthReloadAndRejectsDisabled(t *testing.T) {
+	m := NewManager(nil, &RoundRobinSelector{}, nil)
+	registerExplainAuth(t, m, &Auth{ID: "drain-reload", Provider: "drain-reload-provider", Status: StatusActive}, "")
+	registerExplainAuth(t, m, &Auth{ID: "drain-off", Provider: "drain-reload-provider", Status: StatusDisabled, Disabled: true}, "")
+
+	deadline := time.Now().Add(time.Hour)
+	if _, errDrain := m.SetDraining(context.Background(), "drain-reload", deadline); errDrain != nil {
+		t.Fatalf("SetDraining() error = %v", errDrain)
+	}
+	reloaded, errUpdate := m.Update(context.Background(), &Auth{ID: "drain-reload", Provider: "drain-reload-provider", Status: StatusActive})
+	if errUpdate != nil || reloaded.Status != StatusDraining || !reloaded.DrainingUntil.Equal(deadline) {
+		t.Fatalf("reloaded auth = %+v, %v; want the drain kept", reloaded, errUpdate)
+	}
+
+	var authErr *Error
+	if _, errDrain := m.SetDraining(context.Background(), "drain-off", deadline); !errors.As(errDrain, &authErr) || authErr.HTTPStatus != http.StatusConflict {
+		t.Fatalf("SetDraining() on a disabled auth error = %v, want 409", errDrain)
+	}
+	if off, _ := m.GetByID("drain-off"); off != nil {
+		raw, errMarshal := json.Marshal(off)
+		if errMarshal != nil || strings.Contains(string(raw), "draining_until") {
+			t.Fatalf("auth without a drain serialized %s, %v", raw, errMarshal)
+		}
+	}
+}
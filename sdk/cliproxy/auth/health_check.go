@@ -0,0 +1,440 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/events"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v7/sdk/translator"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/sjson"
+)
+
+// Health-check probe methods.
+const (
+	HealthCheckMethodChecker = "checker"
+	HealthCheckMethodRequest = "request"
+)
+
+// healthCheckTick is how often the scheduler looks for credentials that are due.
+const healthCheckTick = 30 * time.Second
+
+// HealthChecker is implemented by executors that can verify a credential through
+// a cheap provider endpoint, such as a quota or profile lookup, instead of a
+// model request. Errors are classified like execution errors; returning
+// ErrHealthCheckUnsupported falls back to the model request probe.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context, auth *Auth) error
+}
+
+// ErrHealthCheckUnsupported is returned by a HealthChecker that cannot verify
+// a particular credential, e.g. an API key with no usage endpoint.
+var ErrHealthCheckUnsupported = errors.New("health check not supported for credential")
+
+// HealthCheckResult is the outcome of one credential probe.
+type HealthCheckResult struct {
+	AuthID string `json:"auth_id"`
+	// Model is empty for probes that do not target a model.
+	Model   string `json:"model,omitempty"`
+	Method  string `json:"method,omitempty"`
+	Healthy bool   `json:"healthy"`
+	// Skipped marks credentials that were not probed, e.g. disabled ones.
+	Skipped    bool      `json:"skipped,omitempty"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Message    string    `json:"message,omitempty"`
+	LatencyMs  int64     `json:"latency_ms"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// HealthCheckStats summarizes prober activity since start.
+type HealthCheckStats struct {
+	Enabled   bool      `json:"enabled"`
+	LastRun   time.Time `json:"last_run"`
+	Probes    uint64    `json:"probes"`
+	Healthy   uint64    `json:"healthy"`
+	Unhealthy uint64    `json:"unhealthy"`
+	Skipped   uint64    `json:"skipped"`
+}
+
+// healthEvent is the payload of auth.health management events.
+type healthEvent struct {
+	authEventIdentity
+	Model      string `json:"model,omitempty"`
+	Method     string `json:"method,omitempty"`
+	Healthy    bool   `json:"healthy"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Message    string `json:"message,omitempty"`
+	LatencyMs  int64  `json:"latency_ms"`
+}
+
+type healthCheckState struct {
+	mu       sync.Mutex
+	results  map[string][]HealthCheckResult
+	inFlight map[string]struct{}
+	cancel   context.CancelFunc
+	stats    HealthCheckStats
+}
+
+func (m *Manager) healthCheckConfig() internalconfig.HealthCheckConfig {
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil {
+		cfg = &internalconfig.Config{}
+	}
+	hc := cfg.HealthCheck
+	if hc.IntervalSeconds <= 0 {
+		hc.IntervalSeconds = internalconfig.DefaultHealthCheckIntervalSeconds
+	}
+	if hc.TimeoutSeconds <= 0 {
+		hc.TimeoutSeconds = internalconfig.DefaultHealthCheckTimeoutSeconds
+	}
+	if hc.Concurrency <= 0 {
+		hc.Concurrency = internalconfig.DefaultHealthCheckConcurrency
+	}
+	return hc
+}
+
+// StartHealthCheck launches the scheduled prober. It reads the health-check
+// config on every pass, so enabling or tuning it takes effect without a restart.
+func (m *Manager) StartHealthCheck(parent context.Context) {
+	if m == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(parent)
+	m.health.mu.Lock()
+	cancelPrev := m.health.cancel
+	m.health.cancel = cancel
+	m.health.mu.Unlock()
+	if cancelPrev != nil {
+		cancelPrev()
+	}
+	go func() {
+		ticker := time.NewTicker(healthCheckTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if m.healthCheckConfig().Enabled {
+					m.runScheduledHealthChecks(ctx, time.Now())
+				}
+			}
+		}
+	}()
+}
+
+// StopHealthCheck stops the scheduled prober, if running.
+func (m *Manager) StopHealthCheck() {
+	if m == nil {
+		return
+	}
+	m.health.mu.Lock()
+	cancel := m.health.cancel
+	m.health.cancel = nil
+	m.health.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// runScheduledHealthChecks probes every credential whose last check is older
+// than the configured interval. Disabled, cooling-down, off-schedule and
+// draining credentials are left alone: routing already avoids them, and a
+// probe would only spend quota on a credential that is not taking new work.
+func (m *Manager) runScheduledHealthChecks(ctx context.Context, now time.Time) {
+	hc := m.healthCheckConfig()
+	interval := time.Duration(hc.IntervalSeconds) * time.Second
+
+	var due []string
+	for _, auth := range m.List() {
+		if auth == nil || auth.Disabled || auth.Status == StatusDisabled {
+			continue
+		}
+		if until, _ := authCooldownUntil(auth, "", now); !until.IsZero() {
+			continue
+		}
+		if state, ok := AuthScheduleState(auth, now); ok && !state.Active {
+			continue
+		}
+		if auth.IsDraining() {
+			continue
+		}
+		if last := m.lastHealthCheck(auth.ID); !last.IsZero() && now.Sub(last) < interval {
+			continue
+		}
+		due = append(due, auth.ID)
+	}
+	m.health.mu.Lock()
+	m.health.stats.LastRun = now
+	m.health.mu.Unlock()
+	m.CheckAuthsHealth(ctx, due, nil)
+}
+
+// CheckAuthsHealth probes the given credentials with the configured concurrency
+// and returns the results keyed by auth ID. Credentials that cannot be probed
+// are reported through a skipped result.
+func (m *Manager) CheckAuthsHealth(ctx context.Context, authIDs []string, models []string) map[string][]HealthCheckResult {
+	out := make(map[string][]HealthCheckResult, len(authIDs))
+	if m == nil || len(authIDs) == 0 {
+		return out
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, m.healthCheckConfig().Concurrency)
+	for _, id := range authIDs {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return out
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results, errCheck := m.CheckAuthHealth(ctx, id, models)
+			if errCheck != nil {
+				results = []HealthCheckResult{{AuthID: id, Skipped: true, Message: errCheck.Error(), CheckedAt: time.Now()}}
+			}
+			mu.Lock()
+			out[id] = results
+			mu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+	return out
+}
+
+// CheckAuthHealth probes one credential through its executor and records the
+// outcome through MarkResult, so failures drive the usual cooldown and status
+// handling. Executors implementing HealthChecker are asked directly; others, and
+// checkers reporting ErrHealthCheckUnsupported, get a minimal chat request for
+// each model. models overrides the configured list.
+func (m *Manager) CheckAuthHealth(ctx context.Context, authID string, models []string) ([]HealthCheckResult, error) {
+	if m == nil {
+		return nil, errors.New("auth manager is nil")
+	}
+	auth, ok := m.GetByID(authID)
+	if !ok || auth == nil {
+		return nil, &Error{Code: "auth_not_found", Message: "auth not found", HTTPStatus: http.StatusNotFound}
+	}
+	if !m.beginHealthCheck(auth.ID) {
+		return nil, &Error{Code: "health_check_in_progress", Message: "health check already running", HTTPStatus: http.StatusConflict}
+	}
+	defer m.endHealthCheck(auth.ID)
+
+	now := time.Now()
+	if auth.Disabled || auth.Status == StatusDisabled {
+		results := []HealthCheckResult{{AuthID: auth.ID, Skipped: true, Message: "credential disabled", CheckedAt: now}}
+		m.storeHealthResults(auth, results)
+		return results, nil
+	}
+	executor := m.executorFor(executorKeyFromAuth(auth))
+	if executor == nil {
+		return nil, &Error{Code: "executor_not_found", Message: "executor not registered", HTTPStatus: http.StatusServiceUnavailable}
+	}
+
+	hc := m.healthCheckConfig()
+	timeout := time.Duration(hc.TimeoutSeconds) * time.Second
+	var results []HealthCheckResult
+	if checker, okChecker := executor.(HealthChecker); okChecker {
+		if result, checked := m.probeWithChecker(ctx, checker, auth, timeout); checked {
+			results = append(results, result)
+		}
+	}
+	if len(results) == 0 {
+		if len(models) == 0 {
+			models = healthCheckModels(hc, auth)
+		}
+		if len(models) == 0 {
+			results = append(results, HealthCheckResult{AuthID: auth.ID, Method: HealthCheckMethodRequest, Skipped: true, Message: "no models registered for credential", CheckedAt: now})
+		}
+		for _, model := range models {
+			results = append(results, m.probeWithRequest(ctx, auth, model, timeout))
+		}
+	}
+	m.storeHealthResults(auth, results)
+	return results, nil
+}
+
+// probeWithChecker asks the executor to verify the credential. checked is false
+// when the checker does not support the credential.
+func (m *Manager) probeWithChecker(ctx context.Context, checker HealthChecker, auth *Auth, timeout time.Duration) (result HealthCheckResult, checked bool) {
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	errCheck := checker.CheckHealth(probeCtx, auth.Clone())
+	if errors.Is(errCheck, ErrHealthCheckUnsupported) {
+		return HealthCheckResult{}, false
+	}
+	result = healthResultFromError(auth.ID, "", HealthCheckMethodChecker, errCheck, start)
+	m.MarkResult(ctx, Result{AuthID: auth.ID, Provider: auth.Provider, Success: errCheck == nil, Error: resultErrorFromError(errCheck)})
+	return result, true
+}
+
+// probeWithRequest sends a one-token chat completion pinned to the credential.
+// It goes through Execute, which records the outcome with MarkResult.
+func (m *Manager) probeWithRequest(ctx context.Context, auth *Auth, model string, timeout time.Duration) HealthCheckResult {
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	payload := []byte(`{"messages":[{"role":"user","content":"ping"}],"max_tokens":1,"stream":false}`)
+	payload, _ = sjson.SetBytes(payload, "model", model)
+	providers := util.GetProviderName(model)
+	if len(providers) == 0 {
+		providers = []string{executorKeyFromAuth(auth)}
+	}
+	req := cliproxyexecutor.Request{Model: model, Payload: payload}
+	opts := cliproxyexecutor.Options{
+		OriginalRequest: payload,
+		SourceFormat:    sdktranslator.FromString("openai"),
+		ResponseFormat:  sdktranslator.FromString("openai"),
+		Metadata: map[string]any{
+			cliproxyexecutor.PinnedAuthMetadataKey:     auth.ID,
+			cliproxyexecutor.RequestedModelMetadataKey: model,
+		},
+	}
+	start := time.Now()
+	_, errExec := m.Execute(probeCtx, providers, req, opts)
+	return healthResultFromError(auth.ID, model, HealthCheckMethodRequest, errExec, start)
+}
+
+func healthResultFromError(authID, model, method string, err error, start time.Time) HealthCheckResult {
+	result := HealthCheckResult{
+		AuthID:    authID,
+		Model:     model,
+		Method:    method,
+		Healthy:   err == nil,
+		LatencyMs: time.Since(start).Milliseconds(),
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		result.StatusCode = statusCodeFromError(err)
+		result.Message = err.Error()
+	}
+	return result
+}
+
+// healthCheckModels picks the models to probe: the configured list for the
+// provider, or else the first model registered for the credential.
+func healthCheckModels(hc internalconfig.HealthCheckConfig, auth *Auth) []string {
+	provider := strings.ToLower(strings.TrimSpace(auth.Provider))
+	if models := hc.Models[provider]; len(models) > 0 {
+		return append([]string(nil), models...)
+	}
+	if key := strings.ToLower(executorKeyFromAuth(auth)); key != provider {
+		if models := hc.Models[key]; len(models) > 0 {
+			return append([]string(nil), models...)
+		}
+	}
+	for _, model := range registry.GetGlobalRegistry().GetModelsForClient(auth.ID) {
+		if model != nil && strings.TrimSpace(model.ID) != "" {
+			return []string{model.ID}
+		}
+	}
+	return nil
+}
+
+func (m *Manager) beginHealthCheck(authID string) bool {
+	m.health.mu.Lock()
+	defer m.health.mu.Unlock()
+	if m.health.inFlight == nil {
+		m.health.inFlight = make(map[string]struct{})
+	}
+	if _, running := m.health.inFlight[authID]; running {
+		return false
+	}
+	m.health.inFlight[authID] = struct{}{}
+	return true
+}
+
+func (m *Manager) endHealthCheck(authID string) {
+	m.health.mu.Lock()
+	delete(m.health.inFlight, authID)
+	m.health.mu.Unlock()
+}
+
+func (m *Manager) storeHealthResults(auth *Auth, results []HealthCheckResult) {
+	m.health.mu.Lock()
+	if m.health.results == nil {
+		m.health.results = make(map[string][]HealthCheckResult)
+	}
+	m.health.results[auth.ID] = append([]HealthCheckResult(nil), results...)
+	for _, result := range results {
+		m.health.stats.Probes++
+		switch {
+		case result.Skipped:
+			m.health.stats.Skipped++
+		case result.Healthy:
+			m.health.stats.Healthy++
+		default:
+			m.health.stats.Unhealthy++
+		}
+	}
+	m.health.mu.Unlock()
+
+	identity := authEventIdentityOf(auth)
+	for _, result := range results {
+		if !result.Healthy && !result.Skipped {
+			log.Warnf("health check failed for %s (%s) model=%s: %s", auth.ID, auth.Provider, result.Model, result.Message)
+		}
+		events.Publish(events.TypeAuthHealth, healthEvent{
+			authEventIdentity: identity,
+			Model:             result.Model,
+			Method:            result.Method,
+			Healthy:           result.Healthy,
+			Skipped:           result.Skipped,
+			StatusCode:        result.StatusCode,
+			Message:           result.Message,
+			LatencyMs:         result.LatencyMs,
+		})
+	}
+}
+
+func (m *Manager) lastHealthCheck(authID string) time.Time {
+	m.health.mu.Lock()
+	defer m.health.mu.Unlock()
+	var last time.Time
+	for _, result := range m.health.results[authID] {
+		if result.CheckedAt.After(last) {
+			last = result.CheckedAt
+		}
+	}
+	return last
+}
+
+// HealthCheckResults returns the latest probe results for a credential.
+func (m *Manager) HealthCheckResults(authID string) []HealthCheckResult {
+	if m == nil {
+		return nil
+	}
+	m.health.mu.Lock()
+	defer m.health.mu.Unlock()
+	return append([]HealthCheckResult(nil), m.health.results[authID]...)
+}
+
+// HealthCheckStats returns prober counters and whether scheduling is enabled.
+func (m *Manager) HealthCheckStats() HealthCheckStats {
+	if m == nil {
+		return HealthCheckStats{}
+	}
+	m.health.mu.Lock()
+	stats := m.health.stats
+	m.health.mu.Unlock()
+	stats.Enabled = m.healthCheckConfig().Enabled
+	return stats
+}
+
+func (m *Manager) forgetHealthCheck(authID string) {
+	m.health.mu.Lock()
+	delete(m.health.results, authID)
+	m.health.mu.Unlock()
+}
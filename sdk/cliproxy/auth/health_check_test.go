@@ -0,0 +1,234 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+)
+
+type healthProbeExecutor struct {
+	provider string
+	failFor  map[string]error
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (e *healthProbeExecutor) Identifier() string { return e.provider }
+
+func (e *healthProbeExecutor) Execute(_ context.Context, auth *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.mu.Lock()
+	e.calls = append(e.calls, auth.ID+"/"+req.Model)
+	e.mu.Unlock()
+	if err := e.failFor[auth.ID]; err != nil {
+		return cliproxyexecutor.Response{}, err
+	}
+	return cliproxyexecutor.Response{Payload: []byte(`{"choices":[]}`)}, nil
+}
+
+func (e *healthProbeExecutor) ExecuteStream(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
+	return nil, errors.New("stream not supported")
+}
+
+func (e *healthProbeExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) {
+	return auth, nil
+}
+
+func (e *healthProbeExecutor) CountTokens(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, errors.New("not supported")
+}
+
+func (e *healthProbeExecutor) HttpRequest(context.Context, *Auth, *http.Request) (*http.Response, error) {
+	return nil, errors.New("not supported")
+}
+
+type healthCheckerExecutor struct {
+	healthProbeExecutor
+	checkErr error
+}
+
+func (e *healthCheckerExecutor) CheckHealth(context.Context, *Auth) error {
+	return e.checkErr
+}
+
+// usageEndpointExecutor answers usage endpoint requests with a fixed status.
+type usageEndpointExecutor struct {
+	healthProbeExecutor
+	status int
+	urls   []string
+}
+
+func (e *usageEndpointExecutor) HttpRequest(_ context.Context, _ *Auth, req *http.Request) (*http.Response, error) {
+	e.mu.Lock()
+	e.urls = append(e.urls, req.URL.String())
+	e.mu.Unlock()
+	return &http.Response{StatusCode: e.status, Body: io.NopCloser(strings.NewReader(`{"error":"revoked"}`))}, nil
+}
+
+func (e *usageEndpointExecutor) CheckHealth(ctx context.Context, auth *Auth) error {
+	_, err := FetchQuotaUsageWindows(ctx, e, auth)
+	return err
+}
+
+func registerHealthProbeAuths(t *testing.T, m *Manager, provider, model string, ids ...string) {
+	t.Helper()
+	auths := make([]*Auth, 0, len(ids))
+	for _, id := range ids {
+		auths = append(auths, &Auth{ID: id, Provider: provider, Status: StatusActive})
+	}
+	registerHealthProbeAuthEntries(t, m, model, auths...)
+}
+
+func registerHealthProbeAuthEntries(t *testing.T, m *Manager, model string, auths ...*Auth) {
+	t.Helper()
+	for _, auth := range auths {
+		if _, errRegister := m.Register(context.Background(), auth); errRegister != nil {
+			t.Fatalf("Register(%s) error = %v", auth.ID, errRegister)
+		}
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: model}})
+	}
+	t.Cleanup(func() {
+		for _, auth := range auths {
+			registry.GetGlobalRegistry().UnregisterClient(auth.ID)
+		}
+	})
+}
+
+func TestManagerCheckAuthHealthProbesPinnedCredential(t *testing.T) {
+	executor := &healthProbeExecutor{
+		provider: "health-probe-provider",
+		failFor: map[string]error{
+			"health-bad": &Error{HTTPStatus: http.StatusUnauthorized, Message: "token revoked"},
+		},
+	}
+	m := NewManager(nil, nil, nil)
+	m.SetConfig(&internalconfig.Config{})
+	m.RegisterExecutor(executor)
+	const model = "health-probe-model"
+	registerHealthProbeAuths(t, m, executor.provider, model, "health-good", "health-bad")
+
+	results := m.CheckAuthsHealth(context.Background(), []string{"health-good", "health-bad"}, nil)
+
+	good := results["health-good"]
+	if len(good) != 1 || !good[0].Healthy || good[0].Model != model || good[0].Method != HealthCheckMethodRequest {
+		t.Fatalf("good results = %+v, want one healthy request probe", good)
+	}
+	bad := results["health-bad"]
+	if len(bad) != 1 || bad[0].Healthy || bad[0].StatusCode != http.StatusUnauthorized {
+		t.Fatalf("bad results = %+v, want one 401 probe", bad)
+	}
+	for _, call := range executor.calls {
+		if call != "health-good/"+model && call != "health-bad/"+model {
+			t.Fatalf("executor calls = %v, want probes pinned to each credential", executor.calls)
+		}
+	}
+
+	updated, _ := m.GetByID("health-bad")
+	if state := updated.ModelStates[model]; state == nil || !state.Unavailable {
+		t.Fatalf("bad credential model state = %+v, want cooldown from the failed probe", state)
+	}
+	if got := m.HealthCheckResults("health-bad"); len(got) != 1 || got[0].Healthy {
+		t.Fatalf("HealthCheckResults() = %+v, want stored failure", got)
+	}
+	stats := m.HealthCheckStats()
+	if stats.Probes != 2 || stats.Healthy != 1 || stats.Unhealthy != 1 {
+		t.Fatalf("stats = %+v, want 2 probes with 1 healthy and 1 unhealthy", stats)
+	}
+}
+
+func TestManagerCheckAuthHealthPrefersHealthChecker(t *testing.T) {
+	executor := &healthCheckerExecutor{
+		healthProbeExecutor: healthProbeExecutor{provider: "health-checker-provider"},
+		checkErr:            &Error{HTTPStatus: http.StatusForbidden, Message: "account suspended"},
+	}
+	m := NewManager(nil, nil, nil)
+	m.SetConfig(&internalconfig.Config{})
+	m.RegisterExecutor(executor)
+	registerHealthProbeAuths(t, m, executor.provider, "health-checker-model", "health-checked")
+
+	results, errCheck := m.CheckAuthHealth(context.Background(), "health-checked", nil)
+	if errCheck != nil {
+		t.Fatalf("CheckAuthHealth() error = %v", errCheck)
+	}
+	if len(results) != 1 || results[0].Method != HealthCheckMethodChecker || results[0].Healthy || results[0].StatusCode != http.StatusForbidden {
+		t.Fatalf("results = %+v, want one failed checker probe", results)
+	}
+	if len(executor.calls) != 0 {
+		t.Fatalf("executor calls = %v, want no model request", executor.calls)
+	}
+	updated, _ := m.GetByID("health-checked")
+	if updated.Status != StatusError {
+		t.Fatalf("status = %s, want error after failed check", updated.Status)
+	}
+}
+
+func TestManagerCheckAuthHealthSkipsDisabledCredential(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	if _, errRegister := m.Register(context.Background(), &Auth{ID: "health-disabled", Provider: "x", Disabled: true, Status: StatusDisabled}); errRegister != nil {
+		t.Fatalf("Register() error = %v", errRegister)
+	}
+	results, errCheck := m.CheckAuthHealth(context.Background(), "health-disabled", nil)
+	if errCheck != nil || len(results) != 1 || !results[0].Skipped {
+		t.Fatalf("results = %+v err = %v, want skipped", results, errCheck)
+	}
+	if _, errMissing := m.CheckAuthHealth(context.Background(), "missing", nil); errMissing == nil {
+		t.Fatal("CheckAuthHealth(missing) error = nil, want not found")
+	}
+}
+
+func TestManagerCheckAuthHealthUsesUsageEndpoint(t *testing.T) {
+	executor := &usageEndpointExecutor{healthProbeExecutor: healthProbeExecutor{provider: "codex"}, status: http.StatusUnauthorized}
+	m := NewManager(nil, nil, nil)
+	m.SetConfig(&internalconfig.Config{})
+	m.RegisterExecutor(executor)
+	registerHealthProbeAuthEntries(t, m, "health-usage-model",
+		&Auth{ID: "health-usage-oauth", Provider: "codex", Status: StatusActive, Attributes: map[string]string{AttributeAuthKind: AuthKindOAuth}},
+		&Auth{ID: "health-usage-key", Provider: "codex", Status: StatusActive, Attributes: map[string]string{AttributeAPIKey: "sk-test"}},
+	)
+
+	oauth, errCheck := m.CheckAuthHealth(context.Background(), "health-usage-oauth", nil)
+	if errCheck != nil || len(oauth) != 1 || oauth[0].Method != HealthCheckMethodChecker || oauth[0].StatusCode != http.StatusUnauthorized {
+		t.Fatalf("oauth results = %+v err = %v, want a failed usage endpoint check", oauth, errCheck)
+	}
+	if len(executor.urls) != 1 || executor.urls[0] != quotaUsageEndpoints["codex"].url {
+		t.Fatalf("usage requests = %v, want the codex usage endpoint", executor.urls)
+	}
+
+	apiKey, errCheck := m.CheckAuthHealth(context.Background(), "health-usage-key", nil)
+	if errCheck != nil || len(apiKey) != 1 || apiKey[0].Method != HealthCheckMethodRequest || !apiKey[0].Healthy {
+		t.Fatalf("api key results = %+v err = %v, want the request probe fallback", apiKey, errCheck)
+	}
+}
+
+func TestRunScheduledHealthChecksSkipsOffScheduleAndDrainingCredentials(t *testing.T) {
+	executor := &healthProbeExecutor{provider: "health-scheduled-provider"}
+	m := NewManager(nil, nil, nil)
+	m.SetConfig(&internalconfig.Config{})
+	m.RegisterExecutor(executor)
+	const model = "health-scheduled-model"
+	registerHealthProbeAuthEntries(t, m, model,
+		&Auth{ID: "health-active", Provider: executor.provider, Status: StatusActive},
+		&Auth{ID: "health-off-schedule", Provider: executor.provider, Status: StatusActive, Attributes: map[string]string{AttributeSchedule: scheduleAround(2*time.Hour, 3*time.Hour)}},
+		&Auth{ID: "health-draining", Provider: executor.provider, Status: StatusDraining, DrainingUntil: time.Now().Add(time.Hour)},
+	)
+
+	m.runScheduledHealthChecks(context.Background(), time.Now())
+
+	if len(executor.calls) != 1 || executor.calls[0] != "health-active/"+model {
+		t.Fatalf("executor calls = %v, want only the active credential probed", executor.calls)
+	}
+	for _, id := range []string{"health-off-schedule", "health-draining"} {
+		if got := m.HealthCheckResults(id); len(got) != 0 {
+			t.Fatalf("HealthCheckResults(%s) = %+v, want no probe", id, got)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/events"
+)
+
+// Cooldown exit reasons reported on auth.cooldown.exited events.
+const (
+	cooldownExitRecovered = "recovered"
+	cooldownExitExpired   = "expired"
+)
+
+type authEventIdentity struct {
+	AuthID    string `json:"auth_id"`
+	AuthIndex string `json:"auth_index,omitempty"`
+	Provider  string `json:"provider,omitempty"`
+	Label     string `json:"label,omitempty"`
+	File      string `json:"file,omitempty"`
+}
+
+type authStatusEvent struct {
+	authEventIdentity
+	Status         Status `json:"status"`
+	PreviousStatus Status `json:"previous_status,omitempty"`
+	StatusMessage  string `json:"status_message,omitempty"`
+	Disabled       bool   `json:"disabled"`
+	Unavailable    bool   `json:"unavailable"`
+}
+
+type cooldownEvent struct {
+	authEventIdentity
+	// Model is empty when the cooldown covers the whole credential.
+	Model  string     `json:"model,omitempty"`
+	Reason string     `json:"reason,omitempty"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+type refreshEvent struct {
+	authEventIdentity
+	Success          bool       `json:"success"`
+	Error            string     `json:"error,omitempty"`
+	NextRefreshAfter *time.Time `json:"next_refresh_after,omitempty"`
+}
+
+type modelCoolingEvent struct {
+	Model    string    `json:"model"`
+	Provider string    `json:"provider,omitempty"`
+	Until    time.Time `json:"until"`
+}
+
+// authEventState is the part of an auth that management events are derived
+// from, captured before a change so the change can be reported afterwards.
+type authEventState struct {
+	status        Status
+	statusMessage string
+	disabled      bool
+	unavailable   bool
+	model         string
+	authUntil     time.Time
+	modelUntil    time.Time
+}
+
+func captureAuthEventState(auth *Auth, model string, now time.Time) authEventState {
+	if auth == nil {
+		return authEventState{model: model}
+	}
+	state := authEventState{
+		status:        auth.Status,
+		statusMessage: auth.StatusMessage,
+		disabled:      auth.Disabled,
+		unavailable:   auth.Unavailable,
+		model:         model,
+	}
+	state.authUntil, _ = authCooldownUntil(auth, "", now)
+	if model != "" {
+		state.modelUntil, _ = authCooldownUntil(auth, model, now)
+	}
+	return state
+}
+
+// authCooldownUntil returns when the cooldown of auth (model == "") or of one
+// of its models ends, or the zero time when it is not cooling down.
+func authCooldownUntil(auth *Auth, model string, now time.Time) (time.Time, string) {
+	if auth == nil {
+		return time.Time{}, ""
+	}
+	if model == "" {
+		if auth.Unavailable && auth.NextRetryAfter.After(now) {
+			return auth.NextRetryAfter, firstNonEmpty(auth.Quota.Reason, auth.StatusMessage)
+		}
+		return time.Time{}, ""
+	}
+	state := auth.ModelStates[model]
+	if state == nil || !state.Unavailable || !state.NextRetryAfter.After(now) {
+		return time.Time{}, ""
+	}
+	return state.NextRetryAfter, firstNonEmpty(state.Quota.Reason, state.StatusMessage)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			return trimmed
+		}
+	}
+	return ""
+}
+
+func authEventIdentityOf(auth *Auth) authEventIdentity {
+	identity := authEventIdentity{
+		AuthID:    auth.ID,
+		AuthIndex: auth.Index,
+		Provider:  auth.Provider,
+		Label:     auth.Label,
+	}
+	if auth.FileName != "" {
+		identity.File = filepath.Base(auth.FileName)
+	}
+	return identity
+}
+
+// publishAuthEvents reports the status and cooldown changes between before and
+// the auth snapshot taken after the change.
+func (m *Manager) publishAuthEvents(before authEventState, after *Auth, now time.Time) {
+	if m == nil || after == nil {
+		return
+	}
+	identity := authEventIdentityOf(after)
+	if before.status != after.Status || before.disabled != after.Disabled || before.unavailable != after.Unavailable || before.statusMessage != after.StatusMessage {
+		events.Publish(events.TypeAuthStatus, authStatusEvent{
+			authEventIdentity: identity,
+			Status:            after.Status,
+			PreviousStatus:    before.status,
+			StatusMessage:     after.StatusMessage,
+			Disabled:          after.Disabled,
+			Unavailable:       after.Unavailable,
+		})
+	}
+	m.publishCooldownTransition(identity, "", before.authUntil, after, now)
+	if before.model != "" {
+		m.publishCooldownTransition(identity, before.model, before.modelUntil, after, now)
+	}
+}
+
+func (m *Manager) publishCooldownTransition(identity authEventIdentity, model string, beforeUntil time.Time, after *Auth, now time.Time) {
+	afterUntil, reason := authCooldownUntil(after, model, now)
+	key := identity.AuthID + "\x00" + model
+	switch {
+	case beforeUntil.IsZero() && !afterUntil.IsZero():
+		until := afterUntil
+		events.Publish(events.TypeCooldownEntered, cooldownEvent{authEventIdentity: identity, Model: model, Reason: reason, Until: &until})
+		m.scheduleCooldownExitEvent(key, identity, model, afterUntil)
+	case !beforeUntil.IsZero() && afterUntil.IsZero():
+		if _, tracked := m.cooldownEventDeadlines.LoadAndDelete(key); tracked {
+			events.Publish(events.TypeCooldownExited, cooldownEvent{authEventIdentity: identity, Model: model, Reason: cooldownExitRecovered})
+		}
+	case !afterUntil.IsZero() && !afterUntil.Equal(beforeUntil):
+		m.scheduleCooldownExitEvent(key, identity, model, afterUntil)
+	}
+}
+
+// scheduleCooldownExitEvent publishes auth.cooldown.exited when the cooldown
+// ends on its own. A later deadline for the same key supersedes the timer.
+func (m *Manager) scheduleCooldownExitEvent(key string, identity authEventIdentity, model string, until time.Time) {
+	m.cooldownEventDeadlines.Store(key, until)
+	time.AfterFunc(time.Until(until), func() {
+		if !m.cooldownEventDeadlines.CompareAndDelete(key, until) {
+			return
+		}
+		events.Publish(events.TypeCooldownExited, cooldownEvent{authEventIdentity: identity, Model: model, Reason: cooldownExitExpired})
+	})
+}
+
+func publishRefreshEvent(auth *Auth, errRefresh error) {
+	if auth == nil {
+		return
+	}
+	event := refreshEvent{authEventIdentity: authEventIdentityOf(auth), Success: errRefresh == nil}
+	if errRefresh != nil {
+		event.Error = errRefresh.Error()
+	}
+	if !auth.NextRefreshAfter.IsZero() {
+		next := auth.NextRefreshAfter
+		event.NextRefreshAfter = &next
+	}
+	events.Publish(events.TypeAuthRefresh, event)
+}
+
+// publishModelCoolingEvent reports a selection that found every credential for
+// a model cooling down. Retries that already excluded credentials are skipped
+// because the excluded credentials may still serve the model.
+func (m *Manager) publishModelCoolingEvent(err error, tried map[string]struct{}, now time.Time) {
+	var cooldownErr *modelCooldownError
+	if m == nil || len(tried) > 0 || !errors.As(err, &cooldownErr) || cooldownErr == nil {
+		return
+	}
+	key := cooldownErr.provider + "\x00" + cooldownErr.model
+	if previous, loaded := m.modelCoolingEventDeadlines.Load(key); loaded {
+		if previousUntil, ok := previous.(time.Time); ok && now.Before(previousUntil) {
+			return
+		}
+	}
+	until := now.Add(cooldownErr.resetIn)
+	m.modelCoolingEventDeadlines.Store(key, until)
+	events.Publish(events.TypeModelsCooling, modelCoolingEvent{Model: cooldownErr.model, Provider: cooldownErr.provider, Until: until.UTC()})
+}
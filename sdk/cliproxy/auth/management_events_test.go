@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/events"
+)
+
+func TestManagerMarkResultPublishesCooldownEvents(t *testing.T) {
+	previousGlobal := quotaCooldownDisabled.Load()
+	quotaCooldownDisabled.Store(false)
+	t.Cleanup(func() { quotaCooldownDisabled.Store(previousGlobal) })
+
+	manager := NewManager(nil, nil, nil)
+	manager.SetConfig(&internalconfig.Config{})
+	auth := &Auth{ID: "cooldown-events", Provider: "claude", Status: StatusActive}
+	if _, errRegister := manager.Register(context.Background(), auth); errRegister != nil {
+		t.Fatalf("Register() error = %v", errRegister)
+	}
+
+	sub, _, _ := events.Default().Subscribe(events.ParseFilter("auth"), 0, false)
+	defer sub.Close()
+
+	const model = "test-model"
+	retryAfter := time.Minute
+	manager.MarkResult(context.Background(), Result{
+		AuthID:     auth.ID,
+		Provider:   auth.Provider,
+		Model:      model,
+		RetryAfter: &retryAfter,
+		Error:      &Error{HTTPStatus: http.StatusTooManyRequests, Message: "rate limited"},
+	})
+	manager.MarkResult(context.Background(), Result{AuthID: auth.ID, Provider: auth.Provider, Model: model, Success: true})
+
+	// With its only model cooling down the credential as a whole cools down
+	// too, so both scopes report their transitions.
+	var got []string
+	entered := map[string]cooldownEvent{}
+	exited := map[string]cooldownEvent{}
+	timeout := time.After(2 * time.Second)
+	for len(exited) < 2 {
+		select {
+		case event := <-sub.C:
+			var payload cooldownEvent
+			_ = json.Unmarshal(event.Data, &payload)
+			if payload.AuthID != auth.ID {
+				continue
+			}
+			got = append(got, event.Type)
+			switch event.Type {
+			case events.TypeCooldownEntered:
+				entered[payload.Model] = payload
+			case events.TypeCooldownExited:
+				exited[payload.Model] = payload
+			}
+		case <-timeout:
+			t.Fatalf("events = %v, want cooldown transitions for model and credential", got)
+		}
+	}
+
+	if got[0] != events.TypeAuthStatus {
+		t.Fatalf("events = %v, want auth.status first", got)
+	}
+	if modelEntered := entered[model]; modelEntered.Reason != "quota" || modelEntered.Until == nil {
+		t.Fatalf("entered = %+v, want quota cooldown for %s", entered, model)
+	}
+	if _, ok := entered[""]; !ok {
+		t.Fatalf("entered = %+v, want credential cooldown", entered)
+	}
+	for scope, event := range exited {
+		if event.Reason != cooldownExitRecovered {
+			t.Fatalf("exited[%q] = %+v, want recovered", scope, event)
+		}
+	}
+}
+
+func TestScheduleCooldownExitEventPublishesOnExpiry(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	sub, _, _ := events.Default().Subscribe(events.ParseFilter(events.TypeCooldownExited), 0, false)
+	defer sub.Close()
+
+	identity := authEventIdentity{AuthID: "cooldown-expiry"}
+	key := identity.AuthID + "\x00"
+	manager.scheduleCooldownExitEvent(key, identity, "", time.Now().Add(time.Hour))
+	manager.scheduleCooldownExitEvent(key, identity, "", time.Now().Add(10*time.Millisecond))
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-sub.C:
+			var exited cooldownEvent
+			_ = json.Unmarshal(event.Data, &exited)
+			if exited.AuthID != identity.AuthID {
+				continue
+			}
+			if exited.Reason != cooldownExitExpired {
+				t.Fatalf("exited = %+v, want expired", exited)
+			}
+			if _, pending := manager.cooldownEventDeadlines.Load(key); pending {
+				t.Fatal("deadline still tracked after expiry")
+			}
+			return
+		case <-timeout:
+			t.Fatal("timed out waiting for cooldown exit event")
+		}
+	}
+}
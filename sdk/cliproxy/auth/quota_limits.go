@@ -0,0 +1,432 @@
+package auth
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+const (
+	// quotaUnknownResetHorizon is assumed for windows that report no reset time.
+	quotaUnknownResetHorizon = time.Hour
+	// quotaCycleTolerance is how far reset times may drift within one window period.
+	quotaCycleTolerance = time.Minute
+	// quotaBurnRateMinSample is the shortest baseline span used to estimate a burn rate.
+	quotaBurnRateMinSample = 5 * time.Minute
+	// defaultQuotaExhaustionLead cools a credential down this long before a
+	// window is predicted to run out.
+	defaultQuotaExhaustionLead = time.Minute
+)
+
+// Quota window sources.
+const (
+	QuotaSourceHeaders       = "headers"
+	QuotaSourceUsageEndpoint = "usage-endpoint"
+)
+
+// RateLimitHeaderParser converts provider-specific rate-limit response headers
+// into quota windows.
+type RateLimitHeaderParser func(headers http.Header, now time.Time) []QuotaWindow
+
+var (
+	rateLimitHeaderParsersMu sync.RWMutex
+	rateLimitHeaderParsers   = make(map[string]RateLimitHeaderParser)
+)
+
+// RegisterRateLimitHeaderParser installs the header parser for a provider whose
+// rate-limit headers are not covered by ParseRateLimitHeaders.
+func RegisterRateLimitHeaderParser(provider string, parser RateLimitHeaderParser) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if provider == "" || parser == nil {
+		return
+	}
+	rateLimitHeaderParsersMu.Lock()
+	rateLimitHeaderParsers[provider] = parser
+	rateLimitHeaderParsersMu.Unlock()
+}
+
+// parseProviderRateLimitHeaders combines the generic header windows with the
+// windows reported by the provider's registered parser. The OpenAI-style
+// counted windows are per-model limits and are tagged with model; Codex usage
+// windows and registered parsers report account-wide windows.
+func parseProviderRateLimitHeaders(provider, model string, headers http.Header, now time.Time) []QuotaWindow {
+	windows := parseCountedRateLimitHeaders(headers, now)
+	if modelKey := canonicalModelKey(model); modelKey != "" {
+		for i := range windows {
+			windows[i].Model = modelKey
+		}
+	}
+	windows = append(windows, parseCodexRateLimitHeaders(headers, now)...)
+	rateLimitHeaderParsersMu.RLock()
+	parser := rateLimitHeaderParsers[strings.ToLower(strings.TrimSpace(provider))]
+	rateLimitHeaderParsersMu.RUnlock()
+	if parser != nil && len(headers) > 0 {
+		windows = append(windows, parser(headers, now)...)
+	}
+	return windows
+}
+
+// ParseRateLimitHeaders normalizes upstream rate-limit response headers into
+// quota windows. It understands the OpenAI-style x-ratelimit-* family and Codex
+// usage windows; other providers plug in through RegisterRateLimitHeaderParser.
+func ParseRateLimitHeaders(headers http.Header, now time.Time) []QuotaWindow {
+	return append(parseCountedRateLimitHeaders(headers, now), parseCodexRateLimitHeaders(headers, now)...)
+}
+
+// parseCountedRateLimitHeaders reads the OpenAI-style x-ratelimit-* family.
+func parseCountedRateLimitHeaders(headers http.Header, now time.Time) []QuotaWindow {
+	if len(headers) == 0 {
+		return nil
+	}
+	var windows []QuotaWindow
+	for _, kind := range []string{"requests", "tokens"} {
+		if window, ok := countedQuotaWindow(kind,
+			headers.Get("X-Ratelimit-Limit-"+kind),
+			headers.Get("X-Ratelimit-Remaining-"+kind),
+			headers.Get("X-Ratelimit-Reset-"+kind), now); ok {
+			windows = append(windows, window)
+		}
+	}
+	if window, ok := countedQuotaWindow("default", headers.Get("X-Ratelimit-Limit"), headers.Get("X-Ratelimit-Remaining"), headers.Get("X-Ratelimit-Reset"), now); ok {
+		windows = append(windows, window)
+	}
+	return windows
+}
+
+// parseCodexRateLimitHeaders reads the Codex account usage windows.
+func parseCodexRateLimitHeaders(headers http.Header, now time.Time) []QuotaWindow {
+	if len(headers) == 0 {
+		return nil
+	}
+	var windows []QuotaWindow
+	for _, slot := range []string{"primary", "secondary"} {
+		prefix := "X-Codex-" + slot + "-"
+		usedPercent, ok := parseQuotaFloat(headers.Get(prefix + "Used-Percent"))
+		if !ok {
+			continue
+		}
+		window := QuotaWindow{Name: "codex-" + slot, RemainingFraction: clampQuotaFraction(1 - usedPercent/100)}
+		if seconds, okSeconds := parseQuotaFloat(headers.Get(prefix + "Reset-After-Seconds")); okSeconds && seconds > 0 {
+			window.ResetAt = now.Add(time.Duration(seconds * float64(time.Second)))
+		} else {
+			window.ResetAt = parseQuotaResetTime(headers.Get(prefix+"Reset-At"), now)
+		}
+		windows = append(windows, window)
+	}
+	return windows
+}
+
+func countedQuotaWindow(name, rawLimit, rawRemaining, rawReset string, now time.Time) (QuotaWindow, bool) {
+	remaining, okRemaining := parseQuotaFloat(rawRemaining)
+	if !okRemaining {
+		return QuotaWindow{}, false
+	}
+	window := QuotaWindow{Name: name, Remaining: int64(remaining), RemainingFraction: 1}
+	if limit, okLimit := parseQuotaFloat(rawLimit); okLimit && limit > 0 {
+		window.Limit = int64(limit)
+		window.RemainingFraction = clampQuotaFraction(remaining / limit)
+	} else if remaining <= 0 {
+		window.RemainingFraction = 0
+	}
+	window.ResetAt = parseQuotaResetTime(rawReset, now)
+	return window, true
+}
+
+func parseQuotaFloat(raw string) (float64, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, false
+	}
+	value, errParse := strconv.ParseFloat(raw, 64)
+	if errParse != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, false
+	}
+	return value, true
+}
+
+// parseQuotaResetTime accepts Go durations ("6m0s"), delta seconds, Unix
+// timestamps and RFC 3339 times.
+func parseQuotaResetTime(raw string, now time.Time) time.Time {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}
+	}
+	if seconds, ok := parseQuotaFloat(raw); ok {
+		if seconds <= 0 {
+			return time.Time{}
+		}
+		// Values this large are Unix timestamps rather than delays.
+		if seconds > 1e9 {
+			return time.Unix(0, int64(seconds*float64(time.Second)))
+		}
+		return now.Add(time.Duration(seconds * float64(time.Second)))
+	}
+	if duration, errParse := time.ParseDuration(raw); errParse == nil && duration > 0 {
+		return now.Add(duration)
+	}
+	if at, errParse := time.Parse(time.RFC3339, raw); errParse == nil {
+		return at
+	}
+	if at, errParse := http.ParseTime(raw); errParse == nil {
+		return at
+	}
+	return time.Time{}
+}
+
+func clampQuotaFraction(value float64) float64 {
+	switch {
+	case value < 0:
+		return 0
+	case value > 1:
+		return 1
+	default:
+		return value
+	}
+}
+
+// quotaHeadroom scores how much reported capacity an auth may spend per hour
+// on model before its tightest window resets. Only account-wide windows and
+// the windows of model count. Windows that already reset count as full, and
+// auths that never reported limits score +Inf so they are tried first.
+func quotaHeadroom(auth *Auth, model string, now time.Time) float64 {
+	if auth == nil || len(auth.Quota.Limits.Windows) == 0 {
+		return math.Inf(1)
+	}
+	modelKey := canonicalModelKey(model)
+	score := math.Inf(1)
+	for _, window := range auth.Quota.Limits.Windows {
+		if window.Model != "" && window.Model != modelKey {
+			continue
+		}
+		fraction := window.RemainingFraction
+		until := quotaUnknownResetHorizon
+		if !window.ResetAt.IsZero() {
+			if !window.ResetAt.After(now) {
+				continue
+			}
+			until = max(window.ResetAt.Sub(now), time.Minute)
+		}
+		score = min(score, fraction/until.Hours())
+	}
+	return score
+}
+
+// exhaustedQuotaWindow returns the window whose remaining share fell to the
+// reserve, or that is predicted to run out within the lead time, preferring the
+// one that resets last.
+func exhaustedQuotaWindow(windows []QuotaWindow, policy quotaCooldownPolicy, now time.Time) (QuotaWindow, bool) {
+	var exhausted QuotaWindow
+	found := false
+	for _, window := range windows {
+		if window.ResetAt.IsZero() || !window.ResetAt.After(now) {
+			continue
+		}
+		spent := window.RemainingFraction <= policy.reserve || (window.Limit > 0 && window.Remaining <= 0)
+		predicted := policy.lead > 0 && !window.ExhaustsAt.IsZero() && !window.ExhaustsAt.After(now.Add(policy.lead))
+		if !spent && !predicted {
+			continue
+		}
+		if !found || window.ResetAt.After(exhausted.ResetAt) {
+			exhausted = window
+			found = true
+		}
+	}
+	return exhausted, found
+}
+
+// mergeQuotaWindows folds newly observed windows into the previous snapshot.
+// Windows keep their baseline sample while they stay in the same reset cycle so
+// the burn rate covers the whole cycle; windows that were not re-observed are
+// kept until they reset.
+func mergeQuotaWindows(previous, observed []QuotaWindow, source string, now time.Time) []QuotaWindow {
+	merged := make([]QuotaWindow, 0, len(previous)+len(observed))
+	seen := make(map[string]struct{}, len(observed))
+	key := func(window QuotaWindow) string { return window.Model + "\x00" + window.Name }
+	for _, window := range observed {
+		window.Source = source
+		window.ObservedAt = now
+		window.BaselineFraction, window.BaselineAt = window.RemainingFraction, now
+		window.BurnRate, window.ExhaustsAt = 0, time.Time{}
+		for _, prior := range previous {
+			if key(prior) != key(window) || prior.BaselineAt.IsZero() {
+				continue
+			}
+			if sameQuotaCycle(prior, window) && window.RemainingFraction <= prior.BaselineFraction {
+				window.BaselineFraction, window.BaselineAt = prior.BaselineFraction, prior.BaselineAt
+			}
+		}
+		predictQuotaExhaustion(&window, now)
+		merged = append(merged, window)
+		seen[key(window)] = struct{}{}
+	}
+	for _, prior := range previous {
+		if _, ok := seen[key(prior)]; ok {
+			continue
+		}
+		if !prior.ResetAt.IsZero() && !prior.ResetAt.After(now) {
+			continue
+		}
+		merged = append(merged, prior)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Model != merged[j].Model {
+			return merged[i].Model < merged[j].Model
+		}
+		return merged[i].Name < merged[j].Name
+	})
+	return merged
+}
+
+// sameQuotaCycle reports whether two samples describe the same window period.
+// Reset times drift slightly when upstreams report relative delays.
+func sameQuotaCycle(a, b QuotaWindow) bool {
+	if a.ResetAt.IsZero() || b.ResetAt.IsZero() {
+		return a.ResetAt.IsZero() && b.ResetAt.IsZero()
+	}
+	diff := a.ResetAt.Sub(b.ResetAt)
+	return diff > -quotaCycleTolerance && diff < quotaCycleTolerance
+}
+
+// predictQuotaExhaustion estimates the burn rate from the cycle baseline and
+// sets ExhaustsAt when the window is expected to run out before it resets.
+func predictQuotaExhaustion(window *QuotaWindow, now time.Time) {
+	elapsed := now.Sub(window.BaselineAt)
+	spent := window.BaselineFraction - window.RemainingFraction
+	if elapsed < quotaBurnRateMinSample || spent <= 0 {
+		return
+	}
+	window.BurnRate = spent / elapsed.Hours()
+	exhaustsAt := now.Add(time.Duration(window.RemainingFraction / window.BurnRate * float64(time.Hour)))
+	if window.ResetAt.IsZero() || exhaustsAt.Before(window.ResetAt) {
+		window.ExhaustsAt = exhaustsAt
+	}
+}
+
+// quotaCooldownPolicy holds the settings that turn reported headroom into cooldowns.
+type quotaCooldownPolicy struct {
+	reserve  float64
+	lead     time.Duration
+	disabled bool
+}
+
+func (m *Manager) quotaCooldownPolicy(auth *Auth) quotaCooldownPolicy {
+	policy := quotaCooldownPolicy{lead: defaultQuotaExhaustionLead, disabled: m.cooldownDisabledForAuth(auth)}
+	if cfg, ok := m.runtimeConfig.Load().(*internalconfig.Config); ok && cfg != nil {
+		policy.reserve = quotaReserveFraction(cfg.Routing.QuotaReservePercent)
+		policy.lead = quotaExhaustionLead(cfg.Routing.QuotaExhaustionLead)
+	}
+	return policy
+}
+
+// applyQuotaWindowsLocked records reported rate-limit windows and cools the
+// auth down before the upstream starts answering 429 when a window is spent or
+// about to be. Account-wide windows cool the whole credential; per-model
+// windows only cool their model. It reports whether the stored windows changed.
+func applyQuotaWindowsLocked(auth *Auth, windows []QuotaWindow, source string, policy quotaCooldownPolicy, now time.Time) bool {
+	if !recordQuotaWindowsLocked(auth, windows, source, now) {
+		return false
+	}
+	if policy.disabled {
+		return true
+	}
+	accountWindows := make([]QuotaWindow, 0, len(auth.Quota.Limits.Windows))
+	modelWindows := make(map[string][]QuotaWindow)
+	for _, window := range auth.Quota.Limits.Windows {
+		if window.Model == "" {
+			accountWindows = append(accountWindows, window)
+		} else {
+			modelWindows[window.Model] = append(modelWindows[window.Model], window)
+		}
+	}
+	for model, windowsForModel := range modelWindows {
+		if window, exhausted := exhaustedQuotaWindow(windowsForModel, policy, now); exhausted {
+			coolModelForQuotaWindowLocked(auth, model, window, policy, now)
+		}
+	}
+	window, exhausted := exhaustedQuotaWindow(accountWindows, policy, now)
+	if !exhausted {
+		return true
+	}
+	next := window.ResetAt
+	if auth.Quota.Exceeded && auth.Quota.NextRecoverAt.After(next) {
+		next = auth.Quota.NextRecoverAt
+	}
+	auth.Unavailable = true
+	auth.Quota.Exceeded = true
+	auth.Quota.Reason = "credential_quota"
+	auth.Quota.NextRecoverAt = next
+	auth.NextRetryAfter = next
+	auth.StatusMessage = quotaWindowStatusMessage(window, policy)
+	auth.UpdatedAt = now
+	return true
+}
+
+// coolModelForQuotaWindowLocked parks one model of the auth until a per-model
+// window resets, leaving the credential's other models available.
+func coolModelForQuotaWindowLocked(auth *Auth, model string, window QuotaWindow, policy quotaCooldownPolicy, now time.Time) {
+	state := ensureModelState(auth, model)
+	if state == nil {
+		return
+	}
+	next := window.ResetAt
+	if state.Quota.Exceeded && state.Quota.NextRecoverAt.After(next) {
+		next = state.Quota.NextRecoverAt
+	}
+	state.Unavailable = true
+	state.Status = StatusError
+	state.StatusMessage = quotaWindowStatusMessage(window, policy)
+	state.NextRetryAfter = next
+	state.Quota = QuotaState{Exceeded: true, Reason: "quota", NextRecoverAt: next, BackoffLevel: state.Quota.BackoffLevel}
+	state.UpdatedAt = now
+	updateAggregatedAvailability(auth, now)
+	auth.UpdatedAt = now
+}
+
+func quotaWindowStatusMessage(window QuotaWindow, policy quotaCooldownPolicy) string {
+	if window.RemainingFraction <= policy.reserve || (window.Limit > 0 && window.Remaining <= 0) {
+		return fmt.Sprintf("rate-limit window %s exhausted", window.Name)
+	}
+	return fmt.Sprintf("rate-limit window %s predicted to run out", window.Name)
+}
+
+// recordQuotaWindowsLocked merges reported rate-limit windows into the auth
+// without changing its availability. It reports whether anything was recorded.
+func recordQuotaWindowsLocked(auth *Auth, windows []QuotaWindow, source string, now time.Time) bool {
+	if auth == nil || len(windows) == 0 {
+		return false
+	}
+	auth.Quota.Limits = QuotaLimits{Windows: mergeQuotaWindows(auth.Quota.Limits.Windows, windows, source, now), ObservedAt: now}
+	return true
+}
+
+// quotaReserveFraction converts routing.quota-reserve-percent to a fraction.
+func quotaReserveFraction(percent int) float64 {
+	if percent <= 0 {
+		return 0
+	}
+	return float64(min(percent, 99)) / 100
+}
+
+// quotaExhaustionLead parses routing.quota-exhaustion-lead. Empty or invalid
+// values use the default; zero or negative values disable predictive cooldowns.
+func quotaExhaustionLead(raw string) time.Duration {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return defaultQuotaExhaustionLead
+	}
+	if raw == "0" {
+		return 0
+	}
+	lead, errParse := time.ParseDuration(raw)
+	if errParse != nil {
+		return defaultQuotaExhaustionLead
+	}
+	return max(lead, 0)
+}
@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+)
+
+func quotaHeaders(pairs ...string) http.Header {
+	headers := make(http.Header)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		headers.Set(pairs[i], pairs[i+1])
+	}
+	return headers
+}
+
+func TestParseRateLimitHeaders(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	windows := ParseRateLimitHeaders(quotaHeaders(
+		"X-Ratelimit-Limit-Requests", "100",
+		"X-Ratelimit-Remaining-Requests", "25",
+		"X-Ratelimit-Reset-Requests", "6m0s",
+		"Anthropic-Ratelimit-Unified-5h-Utilization", "0.4",
+		"X-Codex-Primary-Used-Percent", "90",
+		"X-Codex-Primary-Reset-After-Seconds", "120",
+	), now)
+
+	want := map[string]QuotaWindow{
+		"requests":      {Name: "requests", Limit: 100, Remaining: 25, RemainingFraction: 0.25, ResetAt: now.Add(6 * time.Minute)},
+		"codex-primary": {Name: "codex-primary", RemainingFraction: 0.1, ResetAt: now.Add(2 * time.Minute)},
+	}
+	if len(windows) != len(want) {
+		t.Fatalf("windows = %+v, want %d entries", windows, len(want))
+	}
+	for _, window := range windows {
+		expected, ok := want[window.Name]
+		if !ok {
+			t.Fatalf("unexpected window %+v", window)
+		}
+		if window.Limit != expected.Limit || window.Remaining != expected.Remaining || !window.ResetAt.Equal(expected.ResetAt) {
+			t.Fatalf("window %s = %+v, want %+v", window.Name, window, expected)
+		}
+		if diff := window.RemainingFraction - expected.RemainingFraction; diff > 1e-9 || diff < -1e-9 {
+			t.Fatalf("window %s fraction = %v, want %v", window.Name, window.RemainingFraction, expected.RemainingFraction)
+		}
+	}
+
+	if windows := ParseRateLimitHeaders(quotaHeaders("Retry-After", "30"), now); len(windows) != 0 {
+		t.Fatalf("unrelated headers produced windows %+v", windows)
+	}
+}
+
+func TestParseProviderRateLimitHeadersUsesRegisteredParser(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	RegisterRateLimitHeaderParser("quota-test", func(headers http.Header, now time.Time) []QuotaWindow {
+		if headers.Get("X-Test-Remaining") == "" {
+			return nil
+		}
+		return []QuotaWindow{{Name: "test", RemainingFraction: 0.5, ResetAt: now.Add(time.Hour)}}
+	})
+	headers := quotaHeaders("X-Ratelimit-Remaining", "10", "X-Test-Remaining", "1")
+
+	windows := parseProviderRateLimitHeaders("Quota-Test", "", headers, now)
+	if len(windows) != 2 || windows[0].Name != "default" || windows[1].Name != "test" {
+		t.Fatalf("windows = %+v, want the generic and registered windows", windows)
+	}
+	if windows := parseProviderRateLimitHeaders("codex", "", headers, now); len(windows) != 1 {
+		t.Fatalf("windows for an unregistered provider = %+v, want only the generic window", windows)
+	}
+}
+
+func TestQuotaAwareSelectorPrefersHeadroomRelativeToReset(t *testing.T) {
+	now := time.Now()
+	limited := func(id string, fraction float64, resetIn time.Duration) *Auth {
+		return &Auth{ID: id, Provider: "codex", Status: StatusActive, Quota: QuotaState{Limits: QuotaLimits{Windows: []QuotaWindow{
+			{Name: "codex-primary", RemainingFraction: fraction, ResetAt: now.Add(resetIn)},
+		}}}}
+	}
+	// Half of a five-hour window is tighter than a fifth of a window that resets in ten minutes.
+	slow := limited("slow-reset", 0.5, 5*time.Hour)
+	fast := limited("fast-reset", 0.2, 10*time.Minute)
+
+	selector := &QuotaAwareSelector{}
+	for i := 0; i < 3; i++ {
+		picked, errPick := selector.Pick(context.Background(), "codex", "", cliproxyexecutor.Options{}, []*Auth{slow, fast})
+		if errPick != nil || picked.ID != "fast-reset" {
+			t.Fatalf("pick %d = %v (%v), want fast-reset", i, picked, errPick)
+		}
+	}
+
+	unknownA := &Auth{ID: "unknown-a", Provider: "codex", Status: StatusActive}
+	unknownB := &Auth{ID: "unknown-b", Provider: "codex", Status: StatusActive}
+	seen := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		picked, errPick := selector.Pick(context.Background(), "codex", "", cliproxyexecutor.Options{}, []*Auth{fast, unknownA, unknownB})
+		if errPick != nil {
+			t.Fatalf("pick %d error = %v", i, errPick)
+		}
+		seen[picked.ID] = true
+	}
+	if len(seen) != 2 || !seen["unknown-a"] || !seen["unknown-b"] {
+		t.Fatalf("picked %v, want rotation between auths without reported limits", seen)
+	}
+}
+
+func TestMarkResultPreemptivelyCoolsDownSpentWindow(t *testing.T) {
+	const provider = "quota-provider"
+	m := NewManager(nil, &QuotaAwareSelector{}, nil)
+	m.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{QuotaReservePercent: 10}})
+	m.RegisterExecutor(&healthProbeExecutor{provider: provider})
+	registerExplainAuth(t, m, &Auth{ID: "quota-spent", Provider: provider, Status: StatusActive}, "")
+	registerExplainAuth(t, m, &Auth{ID: "quota-fresh", Provider: provider, Status: StatusActive}, "")
+
+	m.MarkResult(context.Background(), Result{AuthID: "quota-fresh", Provider: provider, Success: true, Headers: quotaHeaders(
+		"X-Ratelimit-Limit-Requests", "100",
+		"X-Ratelimit-Remaining-Requests", "80",
+		"X-Ratelimit-Reset-Requests", "60",
+	)})
+	m.MarkResult(context.Background(), Result{AuthID: "quota-spent", Provider: provider, Success: true, Headers: quotaHeaders(
+		"X-Codex-Primary-Used-Percent", "95",
+		"X-Codex-Primary-Reset-After-Seconds", "600",
+	)})
+
+	spent, _ := m.GetByID("quota-spent")
+	if !spent.Unavailable || spent.Quota.Reason != "credential_quota" || time.Until(spent.NextRetryAfter) < 9*time.Minute {
+		t.Fatalf("spent auth = unavailable %v, quota %+v, next retry %s", spent.Unavailable, spent.Quota, spent.NextRetryAfter)
+	}
+	fresh, _ := m.GetByID("quota-fresh")
+	if fresh.Unavailable || len(fresh.Quota.Limits.Windows) != 1 {
+		t.Fatalf("fresh auth = unavailable %v, limits %+v", fresh.Unavailable, fresh.Quota.Limits)
+	}
+
+	for i := 0; i < 3; i++ {
+		picked, _, errPick := m.pickNext(context.Background(), provider, "", cliproxyexecutor.Options{}, nil)
+		if errPick != nil || picked.ID != "quota-fresh" {
+			t.Fatalf("pick %d = %v (%v), want quota-fresh", i, picked, errPick)
+		}
+	}
+
+	// A later success without headers keeps both the cooldown and the observed limits.
+	m.MarkResult(context.Background(), Result{AuthID: "quota-spent", Provider: provider, Success: true})
+	spent, _ = m.GetByID("quota-spent")
+	if !spent.Unavailable || len(spent.Quota.Limits.Windows) != 1 {
+		t.Fatalf("after headerless success: unavailable %v, limits %+v", spent.Unavailable, spent.Quota.Limits)
+	}
+	m.MarkResult(context.Background(), Result{AuthID: "quota-fresh", Provider: provider, Success: true})
+	fresh, _ = m.GetByID("quota-fresh")
+	if len(fresh.Quota.Limits.Windows) != 1 {
+		t.Fatalf("fresh limits dropped after success reset: %+v", fresh.Quota.Limits)
+	}
+}
+
+func TestMarkResultCoolsDownOnlyTheModelOfASpentCountedWindow(t *testing.T) {
+	const provider = "quota-model-provider"
+	m := NewManager(nil, &QuotaAwareSelector{}, nil)
+	m.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{QuotaReservePercent: 10}})
+	m.RegisterExecutor(&healthProbeExecutor{provider: provider})
+	registerExplainAuth(t, m, &Auth{ID: "multi-model", Provider: provider, Status: StatusActive}, "")
+
+	m.MarkResult(context.Background(), Result{AuthID: "multi-model", Provider: provider, Model: "model-a", Success: true, Headers: quotaHeaders(
+		"X-Ratelimit-Limit-Requests", "100",
+		"X-Ratelimit-Remaining-Requests", "0",
+		"X-Ratelimit-Reset-Requests", "10m",
+	)})
+	m.MarkResult(context.Background(), Result{AuthID: "multi-model", Provider: provider, Model: "model-b", Success: true, Headers: quotaHeaders(
+		"X-Ratelimit-Limit-Requests", "100",
+		"X-Ratelimit-Remaining-Requests", "90",
+		"X-Ratelimit-Reset-Requests", "10m",
+	)})
+
+	auth, _ := m.GetByID("multi-model")
+	if auth.Quota.Reason == "credential_quota" {
+		t.Fatalf("per-model window cooled the whole credential: %+v", auth.Quota)
+	}
+	if len(auth.Quota.Limits.Windows) != 2 {
+		t.Fatalf("windows = %+v, want one requests window per model", auth.Quota.Limits.Windows)
+	}
+	stateA := auth.ModelStates["model-a"]
+	if stateA == nil || !stateA.Unavailable || !stateA.Quota.Exceeded || time.Until(stateA.NextRetryAfter) < 9*time.Minute {
+		t.Fatalf("model-a state = %+v, want a quota cooldown until the window resets", stateA)
+	}
+	if blocked, _, _ := isAuthBlockedForModel(auth, "model-b", time.Now()); blocked {
+		t.Fatalf("model-b blocked by model-a's window; states %+v", auth.ModelStates)
+	}
+	if blocked, _, _ := isAuthBlockedForModel(auth, "model-a", time.Now()); !blocked {
+		t.Fatal("model-a not blocked by its spent window")
+	}
+}
@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// quotaUsageEndpointMaxBody caps how much of a usage endpoint response is read.
+const quotaUsageEndpointMaxBody = 1 << 20
+
+// quotaUsageEndpoint describes a provider API that reports subscription window usage.
+type quotaUsageEndpoint struct {
+	url     string
+	headers func(auth *Auth) http.Header
+	parse   func(body []byte, now time.Time) []QuotaWindow
+}
+
+// quotaUsageEndpoints lists the OAuth providers whose usage windows can be queried
+// directly. Window names match the ones parsed from response headers so both
+// sources update the same entries.
+var quotaUsageEndpoints = map[string]quotaUsageEndpoint{
+	"codex": {
+		url: "https://chatgpt.com/backend-api/wham/usage",
+		headers: func(auth *Auth) http.Header {
+			headers := http.Header{"Accept": {"application/json"}}
+			if accountID, ok := auth.Metadata["account_id"].(string); ok && strings.TrimSpace(accountID) != "" {
+				headers.Set("Chatgpt-Account-Id", accountID)
+			}
+			return headers
+		},
+		parse: parseCodexUsageWindows,
+	},
+	"claude": {
+		url: "https://api.anthropic.com/api/oauth/usage",
+		headers: func(*Auth) http.Header {
+			return http.Header{"Accept": {"application/json"}, "Anthropic-Beta": {"oauth-2025-04-20"}}
+		},
+		parse: parseClaudeUsageWindows,
+	},
+	"kimi": {
+		url: "https://api.kimi.com/coding/v1/usages",
+		headers: func(*Auth) http.Header {
+			return http.Header{"Accept": {"application/json"}}
+		},
+		parse: parseKimiUsageWindows,
+	},
+}
+
+// SupportsQuotaUsageEndpoint reports whether RefreshQuotaWindows can query the
+// provider usage endpoint for the auth.
+func SupportsQuotaUsageEndpoint(auth *Auth) bool {
+	if auth == nil || auth.AuthKind() == AuthKindAPIKey {
+		return false
+	}
+	_, ok := quotaUsageEndpoints[strings.ToLower(strings.TrimSpace(auth.Provider))]
+	return ok
+}
+
+// RefreshQuotaWindows queries the provider usage endpoint for an OAuth auth and
+// records the reported windows.
+func (m *Manager) RefreshQuotaWindows(ctx context.Context, authID string) ([]QuotaWindow, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	auth, ok := m.GetByID(authID)
+	if !ok || auth == nil {
+		return nil, &Error{Code: "auth_not_found", Message: "auth not found: " + authID}
+	}
+	if !SupportsQuotaUsageEndpoint(auth) {
+		return nil, &Error{Code: "not_supported", Message: "no usage endpoint for provider: " + auth.Provider}
+	}
+	executor := m.executorFor(executorKeyFromAuth(auth))
+	if executor == nil {
+		return nil, &Error{Code: "provider_not_found", Message: "executor not registered for provider: " + auth.Provider}
+	}
+	windows, errFetch := FetchQuotaUsageWindows(ctx, executor, auth)
+	if errFetch != nil {
+		return nil, errFetch
+	}
+	m.ObserveQuotaWindows(ctx, auth.ID, QuotaSourceUsageEndpoint, windows)
+	return windows, nil
+}
+
+// FetchQuotaUsageWindows queries the provider usage endpoint for auth through
+// executor, which injects the credentials, and returns the reported windows
+// without recording them. It returns ErrHealthCheckUnsupported when the auth
+// has no usage endpoint. Executors use it to implement HealthChecker.
+func FetchQuotaUsageWindows(ctx context.Context, executor ProviderExecutor, auth *Auth) ([]QuotaWindow, error) {
+	if !SupportsQuotaUsageEndpoint(auth) || executor == nil {
+		return nil, ErrHealthCheckUnsupported
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	endpoint := quotaUsageEndpoints[strings.ToLower(strings.TrimSpace(auth.Provider))]
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.url, nil)
+	if errReq != nil {
+		return nil, errReq
+	}
+	req.Header = endpoint.headers(auth)
+	resp, errDo := executor.HttpRequest(ctx, auth, req)
+	if errDo != nil {
+		return nil, errDo
+	}
+	defer func() {
+		if errClose := resp.Body.Close(); errClose != nil {
+			logEntryWithRequestID(ctx).Debugf("close usage endpoint response: %v", errClose)
+		}
+	}()
+	body, errRead := io.ReadAll(io.LimitReader(resp.Body, quotaUsageEndpointMaxBody))
+	if errRead != nil {
+		return nil, fmt.Errorf("read usage endpoint response: %w", errRead)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &Error{Code: "usage_endpoint_failed", HTTPStatus: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+	}
+	return endpoint.parse(body, time.Now()), nil
+}
+
+func parseCodexUsageWindows(body []byte, now time.Time) []QuotaWindow {
+	var windows []QuotaWindow
+	for _, slot := range []string{"primary", "secondary"} {
+		result := gjson.GetBytes(body, "rate_limit."+slot+"_window")
+		if !result.IsObject() || !result.Get("used_percent").Exists() {
+			continue
+		}
+		window := QuotaWindow{Name: "codex-" + slot, RemainingFraction: clampQuotaFraction(1 - result.Get("used_percent").Float()/100)}
+		if seconds := result.Get("reset_after_seconds").Float(); seconds > 0 {
+			window.ResetAt = now.Add(time.Duration(seconds * float64(time.Second)))
+		} else if resetAt := result.Get("reset_at").Int(); resetAt > 0 {
+			window.ResetAt = time.Unix(resetAt, 0)
+		}
+		windows = append(windows, window)
+	}
+	return windows
+}
+
+func parseClaudeUsageWindows(body []byte, now time.Time) []QuotaWindow {
+	var windows []QuotaWindow
+	for key, span := range map[string]string{"five_hour": "5h", "seven_day": "7d"} {
+		result := gjson.GetBytes(body, key)
+		if !result.IsObject() || !result.Get("utilization").Exists() {
+			continue
+		}
+		window := QuotaWindow{Name: "unified-" + span, RemainingFraction: clampQuotaFraction(1 - result.Get("utilization").Float()/100)}
+		window.ResetAt = parseQuotaResetTime(result.Get("resets_at").String(), now)
+		windows = append(windows, window)
+	}
+	return windows
+}
+
+func parseKimiUsageWindows(body []byte, now time.Time) []QuotaWindow {
+	var windows []QuotaWindow
+	if usage := gjson.GetBytes(body, "usage"); usage.IsObject() {
+		if window, ok := countedQuotaWindow("kimi-total", usage.Get("limit").String(), usage.Get("remaining").String(), usage.Get("resetTime").String(), now); ok {
+			windows = append(windows, window)
+		}
+	}
+	for _, limit := range gjson.GetBytes(body, "limits").Array() {
+		detail := limit.Get("detail")
+		name := "kimi-" + kimiWindowSpan(limit.Get("window.duration").Int(), limit.Get("window.timeUnit").String())
+		if window, ok := countedQuotaWindow(name, detail.Get("limit").String(), detail.Get("remaining").String(), detail.Get("resetTime").String(), now); ok {
+			windows = append(windows, window)
+		}
+	}
+	return windows
+}
+
+// kimiWindowSpan renders a Kimi limit window as a compact span such as "5h" or "7d".
+func kimiWindowSpan(duration int64, unit string) string {
+	var span time.Duration
+	switch strings.ToUpper(strings.TrimSpace(unit)) {
+	case "TIME_UNIT_SECOND":
+		span = time.Duration(duration) * time.Second
+	case "TIME_UNIT_HOUR":
+		span = time.Duration(duration) * time.Hour
+	case "TIME_UNIT_DAY":
+		span = time.Duration(duration) * 24 * time.Hour
+	default:
+		span = time.Duration(duration) * time.Minute
+	}
+	switch {
+	case span <= 0:
+		return "window"
+	case span%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", span/(24*time.Hour))
+	case span%time.Hour == 0:
+		return fmt.Sprintf("%dh", span/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", span/time.Minute)
+	}
+}
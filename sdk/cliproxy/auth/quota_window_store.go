@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// QuotaWindowFileName is the file that holds persisted quota windows inside the auth directory.
+const QuotaWindowFileName = "quota-windows.state"
+
+// quotaWindowSaveInterval bounds how often observed windows are written to the store.
+const quotaWindowSaveInterval = 30 * time.Second
+
+// QuotaWindowStore persists observed quota windows, keyed by auth ID, so usage
+// history and exhaustion predictions survive restarts.
+type QuotaWindowStore interface {
+	Load(context.Context) (map[string]QuotaLimits, error)
+	Save(context.Context, map[string]QuotaLimits) error
+}
+
+// quotaWindowState tracks the store and its pending writes.
+type quotaWindowState struct {
+	saveMu  sync.Mutex
+	store   atomic.Pointer[QuotaWindowStore]
+	dirty   atomic.Bool
+	savedAt atomic.Int64
+}
+
+type quotaWindowFile struct {
+	Version   int                    `json:"version"`
+	UpdatedAt time.Time              `json:"updated_at"`
+	Auths     map[string]QuotaLimits `json:"auths"`
+}
+
+// FileQuotaWindowStore stores quota windows in a single JSON file.
+type FileQuotaWindowStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileQuotaWindowStore creates a file-backed quota window store at path.
+func NewFileQuotaWindowStore(path string) *FileQuotaWindowStore {
+	return &FileQuotaWindowStore{path: strings.TrimSpace(path)}
+}
+
+// Load reads persisted windows. A missing file is treated as empty state.
+func (s *FileQuotaWindowStore) Load(ctx context.Context) (map[string]QuotaLimits, error) {
+	if s == nil || s.path == "" {
+		return nil, nil
+	}
+	if ctx != nil {
+		if errCtx := ctx.Err(); errCtx != nil {
+			return nil, errCtx
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, errRead := os.ReadFile(s.path)
+	if errRead != nil {
+		if errors.Is(errRead, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read quota windows %s: %w", s.path, errRead)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, nil
+	}
+	var envelope quotaWindowFile
+	if errUnmarshal := json.Unmarshal(data, &envelope); errUnmarshal != nil {
+		return nil, fmt.Errorf("parse quota windows %s: %w", s.path, errUnmarshal)
+	}
+	return envelope.Auths, nil
+}
+
+// Save atomically replaces the persisted windows.
+func (s *FileQuotaWindowStore) Save(ctx context.Context, windows map[string]QuotaLimits) error {
+	if s == nil || s.path == "" {
+		return nil
+	}
+	if ctx != nil {
+		if errCtx := ctx.Err(); errCtx != nil {
+			return errCtx
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(windows) == 0 {
+		if errRemove := os.Remove(s.path); errRemove != nil && !errors.Is(errRemove, os.ErrNotExist) {
+			return fmt.Errorf("remove quota windows: %w", errRemove)
+		}
+		return nil
+	}
+	data, errMarshal := json.MarshalIndent(quotaWindowFile{Version: 1, UpdatedAt: time.Now().UTC(), Auths: windows}, "", "  ")
+	if errMarshal != nil {
+		return fmt.Errorf("marshal quota windows: %w", errMarshal)
+	}
+	data = append(data, '\n')
+	dir := filepath.Dir(s.path)
+	if errMkdir := os.MkdirAll(dir, 0o700); errMkdir != nil {
+		return fmt.Errorf("create quota windows directory: %w", errMkdir)
+	}
+	tmpFile, errCreate := os.CreateTemp(dir, filepath.Base(s.path)+".*.tmp")
+	if errCreate != nil {
+		return fmt.Errorf("create quota windows temp file: %w", errCreate)
+	}
+	tmp := tmpFile.Name()
+	if _, errWrite := tmpFile.Write(data); errWrite != nil {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("write quota windows temp file: %w", errWrite)
+	}
+	if errClose := tmpFile.Close(); errClose != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("close quota windows temp file: %w", errClose)
+	}
+	if errRename := os.Rename(tmp, s.path); errRename != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("replace quota windows file: %w", errRename)
+	}
+	return nil
+}
+
+// SetQuotaWindowStore sets the store used to persist observed quota windows.
+// A nil store disables persistence.
+func (m *Manager) SetQuotaWindowStore(store QuotaWindowStore) {
+	if m == nil {
+		return
+	}
+	if store == nil {
+		m.quotaWindows.store.Store(nil)
+		return
+	}
+	m.quotaWindows.store.Store(&store)
+}
+
+func (m *Manager) quotaWindowStore() QuotaWindowStore {
+	if m == nil {
+		return nil
+	}
+	if store := m.quotaWindows.store.Load(); store != nil {
+		return *store
+	}
+	return nil
+}
+
+// RestoreQuotaWindows loads persisted windows into registered auths. Windows
+// that already reset are dropped; auths that already observed newer windows
+// keep them.
+func (m *Manager) RestoreQuotaWindows(ctx context.Context) error {
+	store := m.quotaWindowStore()
+	if store == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	persisted, errLoad := store.Load(ctx)
+	if errLoad != nil {
+		return errLoad
+	}
+	now := time.Now()
+	snapshots := make([]*Auth, 0, len(persisted))
+	m.mu.Lock()
+	for authID, limits := range persisted {
+		auth := m.auths[authID]
+		if auth == nil || !auth.Quota.Limits.ObservedAt.Before(limits.ObservedAt) {
+			continue
+		}
+		windows := mergeQuotaWindows(limits.Windows, nil, "", now)
+		if len(windows) == 0 {
+			continue
+		}
+		auth.Quota.Limits = QuotaLimits{Windows: windows, ObservedAt: limits.ObservedAt}
+		snapshots = append(snapshots, auth.Clone())
+	}
+	m.mu.Unlock()
+	if m.scheduler != nil {
+		for _, snapshot := range snapshots {
+			m.scheduler.upsertAuth(snapshot)
+		}
+	}
+	return nil
+}
+
+// ObserveQuotaWindows records windows reported for an auth outside of request
+// execution, such as from provider usage endpoints. It only updates the
+// dashboard and predictions; cooldowns come from MarkResult alone.
+func (m *Manager) ObserveQuotaWindows(ctx context.Context, authID, source string, windows []QuotaWindow) {
+	if m == nil || len(windows) == 0 {
+		return
+	}
+	var snapshot *Auth
+	m.mu.Lock()
+	if auth := m.auths[strings.TrimSpace(authID)]; auth != nil {
+		if recordQuotaWindowsLocked(auth, windows, source, time.Now()) {
+			snapshot = auth.Clone()
+		}
+	}
+	m.mu.Unlock()
+	if snapshot == nil {
+		return
+	}
+	if m.scheduler != nil {
+		m.scheduler.upsertAuth(snapshot)
+	}
+	m.markQuotaWindowsDirty(ctx)
+}
+
+// FlushQuotaWindows writes pending quota windows regardless of the save interval.
+func (m *Manager) FlushQuotaWindows(ctx context.Context) error {
+	return m.saveQuotaWindows(ctx, true)
+}
+
+func (m *Manager) markQuotaWindowsDirty(ctx context.Context) {
+	if m == nil || m.quotaWindowStore() == nil {
+		return
+	}
+	m.quotaWindows.dirty.Store(true)
+	if errSave := m.saveQuotaWindows(ctx, false); errSave != nil {
+		log.Warnf("failed to persist quota windows: %v", errSave)
+	}
+}
+
+func (m *Manager) saveQuotaWindows(ctx context.Context, force bool) error {
+	store := m.quotaWindowStore()
+	if store == nil || !m.quotaWindows.dirty.Load() {
+		return nil
+	}
+	now := time.Now()
+	if !force && now.Sub(time.Unix(0, m.quotaWindows.savedAt.Load())) < quotaWindowSaveInterval {
+		return nil
+	}
+	m.quotaWindows.saveMu.Lock()
+	defer m.quotaWindows.saveMu.Unlock()
+	if !m.quotaWindows.dirty.Swap(false) {
+		return nil
+	}
+	m.quotaWindows.savedAt.Store(now.UnixNano())
+	windows := make(map[string]QuotaLimits)
+	m.mu.RLock()
+	for id, auth := range m.auths {
+		if auth != nil && len(auth.Quota.Limits.Windows) > 0 {
+			windows[id] = auth.Quota.Limits
+		}
+	}
+	m.mu.RUnlock()
+	if ctx == nil || ctx.Err() != nil {
+		ctx = context.Background()
+	}
+	if errSave := store.Save(ctx, windows); errSave != nil {
+		m.quotaWindows.dirty.Store(true)
+		return errSave
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+func TestMergeQuotaWindowsTracksBurnRateWithinCycle(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	resetAt := now.Add(4 * time.Hour)
+	previous := []QuotaWindow{{
+		Name:              "codex-primary",
+		RemainingFraction: 0.7,
+		ResetAt:           resetAt,
+		BaselineFraction:  0.9,
+		BaselineAt:        now.Add(-time.Hour),
+	}}
+
+	merged := mergeQuotaWindows(previous, []QuotaWindow{{Name: "codex-primary", RemainingFraction: 0.5, ResetAt: resetAt.Add(20 * time.Second)}}, QuotaSourceHeaders, now)
+	if len(merged) != 1 {
+		t.Fatalf("merged = %+v, want one window", merged)
+	}
+	window := merged[0]
+	if window.BaselineFraction != 0.9 || !window.BaselineAt.Equal(now.Add(-time.Hour)) || window.Source != QuotaSourceHeaders {
+		t.Fatalf("window = %+v, want the cycle baseline kept", window)
+	}
+	if diff := window.BurnRate - 0.4; diff > 1e-9 || diff < -1e-9 {
+		t.Fatalf("burn rate = %v, want 0.4", window.BurnRate)
+	}
+	if want := now.Add(75 * time.Minute); !window.ExhaustsAt.Equal(want) {
+		t.Fatalf("exhausts at = %s, want %s", window.ExhaustsAt, want)
+	}
+
+	// A new reset cycle starts a fresh baseline without a prediction.
+	merged = mergeQuotaWindows(merged, []QuotaWindow{{Name: "codex-primary", RemainingFraction: 1, ResetAt: now.Add(5 * time.Hour)}}, QuotaSourceUsageEndpoint, now)
+	if window := merged[0]; window.BaselineFraction != 1 || !window.BaselineAt.Equal(now) || window.BurnRate != 0 || !window.ExhaustsAt.IsZero() {
+		t.Fatalf("window after reset = %+v, want a fresh baseline", window)
+	}
+
+	// Windows that were not re-observed are kept until they reset.
+	merged = mergeQuotaWindows([]QuotaWindow{
+		{Name: "stale", ResetAt: now.Add(-time.Minute)},
+		{Name: "weekly", ResetAt: now.Add(48 * time.Hour)},
+	}, []QuotaWindow{{Name: "codex-primary", RemainingFraction: 1, ResetAt: now.Add(time.Hour)}}, QuotaSourceHeaders, now)
+	if len(merged) != 2 || merged[0].Name != "codex-primary" || merged[1].Name != "weekly" {
+		t.Fatalf("merged = %+v, want codex-primary and weekly", merged)
+	}
+}
+
+func TestObserveQuotaWindowsPredictsWithoutCooldown(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{QuotaExhaustionLead: "10m"}})
+	now := time.Now()
+	resetAt := now.Add(2 * time.Hour).Truncate(time.Second)
+	registerExplainAuth(t, m, &Auth{ID: "burning", Provider: "codex", Status: StatusActive, Quota: QuotaState{Limits: QuotaLimits{
+		ObservedAt: now.Add(-time.Hour),
+		Windows: []QuotaWindow{{
+			Name:              "codex-primary",
+			RemainingFraction: 1,
+			ResetAt:           resetAt,
+			BaselineFraction:  1,
+			BaselineAt:        now.Add(-time.Hour),
+		}},
+	}}}, "")
+
+	// 90% spent in the last hour leaves about seven minutes of headroom.
+	m.ObserveQuotaWindows(context.Background(), "burning", QuotaSourceUsageEndpoint, []QuotaWindow{{Name: "codex-primary", RemainingFraction: 0.1, ResetAt: resetAt}})
+	auth, _ := m.GetByID("burning")
+	if auth.Unavailable || auth.Quota.Limits.Windows[0].ExhaustsAt.IsZero() {
+		t.Fatalf("auth = unavailable %v, windows %+v, want a prediction without a cooldown", auth.Unavailable, auth.Quota.Limits.Windows)
+	}
+
+	// The next request reporting the same window cools the auth down.
+	m.MarkResult(context.Background(), Result{AuthID: "burning", Provider: "codex", Success: true, Headers: quotaHeaders(
+		"X-Codex-Primary-Used-Percent", "90",
+		"X-Codex-Primary-Reset-At", strconv.FormatInt(resetAt.Unix(), 10),
+	)})
+	auth, _ = m.GetByID("burning")
+	if !auth.Unavailable || auth.Quota.Reason != "credential_quota" || !auth.NextRetryAfter.Equal(resetAt) {
+		t.Fatalf("auth = unavailable %v, quota %+v, next retry %s", auth.Unavailable, auth.Quota, auth.NextRetryAfter)
+	}
+	if !strings.Contains(auth.StatusMessage, "predicted") {
+		t.Fatalf("status message = %q, want a predicted exhaustion", auth.StatusMessage)
+	}
+}
+
+func TestQuotaWindowsPersistAcrossManagers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), QuotaWindowFileName)
+	resetAt := time.Now().Add(3 * time.Hour).Truncate(time.Second)
+
+	first := NewManager(nil, nil, nil)
+	first.SetQuotaWindowStore(NewFileQuotaWindowStore(path))
+	registerExplainAuth(t, first, &Auth{ID: "persisted", Provider: "codex", Status: StatusActive}, "")
+	first.ObserveQuotaWindows(context.Background(), "persisted", QuotaSourceUsageEndpoint, []QuotaWindow{{Name: "codex-secondary", RemainingFraction: 0.6, ResetAt: resetAt}})
+	if errFlush := first.FlushQuotaWindows(context.Background()); errFlush != nil {
+		t.Fatalf("FlushQuotaWindows() error = %v", errFlush)
+	}
+
+	second := NewManager(nil, nil, nil)
+	second.SetQuotaWindowStore(NewFileQuotaWindowStore(path))
+	registerExplainAuth(t, second, &Auth{ID: "persisted", Provider: "codex", Status: StatusActive}, "")
+	if errRestore := second.RestoreQuotaWindows(context.Background()); errRestore != nil {
+		t.Fatalf("RestoreQuotaWindows() error = %v", errRestore)
+	}
+	auth, _ := second.GetByID("persisted")
+	windows := auth.Quota.Limits.Windows
+	if len(windows) != 1 || windows[0].Name != "codex-secondary" || windows[0].RemainingFraction != 0.6 || !windows[0].ResetAt.Equal(resetAt) || windows[0].Source != QuotaSourceUsageEndpoint {
+		t.Fatalf("restored windows = %+v", windows)
+	}
+
+	expired := NewFileQuotaWindowStore(path)
+	if errSave := expired.Save(context.Background(), map[string]QuotaLimits{"persisted": {
+		ObservedAt: time.Now(),
+		Windows:    []QuotaWindow{{Name: "codex-primary", RemainingFraction: 0, ResetAt: time.Now().Add(-time.Minute)}},
+	}}); errSave != nil {
+		t.Fatalf("Save() error = %v", errSave)
+	}
+	third := NewManager(nil, nil, nil)
+	third.SetQuotaWindowStore(expired)
+	registerExplainAuth(t, third, &Auth{ID: "persisted", Provider: "codex", Status: StatusActive}, "")
+	if errRestore := third.RestoreQuotaWindows(context.Background()); errRestore != nil {
+		t.Fatalf("RestoreQuotaWindows() error = %v", errRestore)
+	}
+	if auth, _ := third.GetByID("persisted"); len(auth.Quota.Limits.Windows) != 0 {
+		t.Fatalf("restored windows = %+v, want windows that already reset dropped", auth.Quota.Limits.Windows)
+	}
+}
+
+func TestParseUsageEndpointWindows(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	codex := parseCodexUsageWindows([]byte(`{"rate_limit":{"primary_window":{"used_percent":40,"reset_after_seconds":3600},"secondary_window":{"used_percent":10,"reset_at":1792800000}}}`), now)
+	if len(codex) != 2 || codex[0].Name != "codex-primary" || codex[0].RemainingFraction != 0.6 || !codex[0].ResetAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("codex windows = %+v", codex)
+	}
+	if codex[1].Name != "codex-secondary" || !codex[1].ResetAt.Equal(time.Unix(1792800000, 0)) {
+		t.Fatalf("codex secondary = %+v", codex[1])
+	}
+
+	claude := parseClaudeUsageWindows([]byte(`{"five_hour":{"utilization":75,"resets_at":"2026-10-18T14:00:00Z"},"seven_day":null}`), now)
+	if len(claude) != 1 || claude[0].Name != "unified-5h" || claude[0].RemainingFraction != 0.25 || !claude[0].ResetAt.Equal(now.Add(2*time.Hour)) {
+		t.Fatalf("claude windows = %+v", claude)
+	}
+
+	kimi := parseKimiUsageWindows([]byte(`{"usage":{"limit":"100","remaining":"40","resetTime":"2026-10-25T12:00:00Z"},"limits":[{"window":{"duration":300,"timeUnit":"TIME_UNIT_MINUTE"},"detail":{"limit":"20","remaining":"5","resetTime":"2026-10-18T15:00:00Z"}}]}`), now)
+	if len(kimi) != 2 || kimi[0].Name != "kimi-total" || kimi[0].Remaining != 40 || kimi[1].Name != "kimi-5h" || kimi[1].RemainingFraction != 0.25 {
+		t.Fatalf("kimi windows = %+v", kimi)
+	}
+}
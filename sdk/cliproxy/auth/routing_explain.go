@@ -0,0 +1,395 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+)
+
+// Routing explain reasons. Filter reasons mark a candidate ineligible; tier
+// reasons explain why an eligible candidate would not be picked this time.
+const (
+	RoutingReasonExecutorMissing    = "executor_missing"
+	RoutingReasonDisabled           = "disabled"
+	RoutingReasonIneligible         = "ineligible"
+	RoutingReasonPrefixMismatch     = "prefix_mismatch"
+	RoutingReasonExcludedModel      = "excluded_model"
+	RoutingReasonModelUnsupported   = "model_unsupported"
+	RoutingReasonRetryRound         = "retry_round"
+	RoutingReasonCooldown           = "cooldown"
+	RoutingReasonUnavailable        = "unavailable"
+	RoutingReasonZeroWeight         = "zero_weight"
+	RoutingReasonDraining           = "draining"
+	RoutingReasonOffSchedule        = "off_schedule"
+	RoutingReasonLowerPriority      = "lower_priority"
+	RoutingReasonWebsocketPreferred = "websocket_preferred"
+)
+
+// RoutingExplainRequest describes one request to resolve without executing it.
+type RoutingExplainRequest struct {
+	// Providers are the provider keys the requested model resolves to.
+	Providers []string
+	Model     string
+	Headers   http.Header
+	// SessionID is treated like an execution session ID for session affinity.
+	SessionID string
+	// CallerScope is the hashed client key, as real requests carry it.
+	CallerScope string
+	// RetryRound explains a later retry round; credentials whose request-retry
+	// limit is below it are skipped.
+	RetryRound int
+	// Websocket marks the downstream connection as a websocket.
+	Websocket bool
+}
+
+// RoutingCandidate reports how one credential fares for an explained request.
+type RoutingCandidate struct {
+	AuthID      string     `json:"auth_id"`
+	AuthIndex   string     `json:"auth_index,omitempty"`
+	Provider    string     `json:"provider"`
+	Label       string     `json:"label,omitempty"`
+	File        string     `json:"file,omitempty"`
+	Priority    int        `json:"priority"`
+	Weight      int64      `json:"weight"`
+	Websocket   bool       `json:"websocket,omitempty"`
+	Eligible    bool       `json:"eligible"`
+	Reasons     []string   `json:"reasons,omitempty"`
+	NextRetryAt *time.Time `json:"next_retry_at,omitempty"`
+	// DrainingUntil is set while the credential drains; it then serves only
+	// the sessions already bound to it.
+	DrainingUntil *time.Time `json:"draining_until,omitempty"`
+	Selected      bool       `json:"selected,omitempty"`
+}
+
+// RoutingPriorityTier lists the eligible credentials sharing one priority.
+type RoutingPriorityTier struct {
+	Priority int      `json:"priority"`
+	AuthIDs  []string `json:"auth_ids"`
+}
+
+// RoutingSessionBinding is the session-affinity state seen by an explained request.
+type RoutingSessionBinding struct {
+	SessionID  string `json:"session_id,omitempty"`
+	FallbackID string `json:"fallback_session_id,omitempty"`
+	AuthID     string `json:"auth_id,omitempty"`
+	Bound      bool   `json:"bound"`
+	// Usable reports whether the bound credential is still eligible, in which
+	// case it is picked regardless of priority. A draining credential stays
+	// usable for the sessions bound to it.
+	Usable bool `json:"usable"`
+}
+
+// RoutingExplanation is the result of a routing dry run.
+type RoutingExplanation struct {
+	Model      string                 `json:"model"`
+	Providers  []string               `json:"providers"`
+	Selector   string                 `json:"selector"`
+	RetryRound int                    `json:"retry_round,omitempty"`
+	Candidates []RoutingCandidate     `json:"candidates"`
+	Tiers      []RoutingPriorityTier  `json:"priority_tiers"`
+	Session    *RoutingSessionBinding `json:"session_affinity,omitempty"`
+	Selected   *RoutingCandidate      `json:"selected,omitempty"`
+	// Error is the error the request would fail with when nothing is picked.
+	Error string `json:"error,omitempty"`
+	// Note qualifies the predicted pick, or explains why none was predicted.
+	Note string `json:"note,omitempty"`
+}
+
+// ExplainRouting runs credential selection for req without executing anything
+// and without advancing rotation or session-affinity state.
+func (m *Manager) ExplainRouting(ctx context.Context, req RoutingExplainRequest) (*RoutingExplanation, error) {
+	if m == nil {
+		return nil, &Error{Code: "auth_not_found", Message: "no auth available"}
+	}
+	if m.HomeEnabled() {
+		return nil, &Error{Code: "home_unavailable", Message: "routing explain is unavailable while Home is enabled", HTTPStatus: http.StatusServiceUnavailable}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	model := strings.TrimSpace(req.Model)
+	providers := m.normalizeProviders(req.Providers)
+	if len(providers) == 0 {
+		return nil, &Error{Code: "provider_not_found", Message: "no provider supplied", HTTPStatus: http.StatusBadRequest}
+	}
+	if req.Websocket {
+		ctx = cliproxyexecutor.WithDownstreamWebsocket(ctx)
+	}
+	opts := cliproxyexecutor.Options{Headers: req.Headers.Clone()}
+	opts.EnsureMetadata()
+	if sessionID := strings.TrimSpace(req.SessionID); sessionID != "" {
+		opts.Metadata[cliproxyexecutor.ExecutionSessionMetadataKey] = sessionID
+	}
+	if callerScope := strings.TrimSpace(req.CallerScope); callerScope != "" {
+		opts.Metadata[cliproxyexecutor.CallerScopeMetadataKey] = callerScope
+	}
+	opts.Metadata[cliproxyexecutor.SessionAffinityProviderMetadataKey] = "mixed"
+	opts.Metadata[cliproxyexecutor.SessionAffinityModelMetadataKey] = model
+
+	m.mu.RLock()
+	selector := m.selector
+	m.mu.RUnlock()
+	strategy := selectorStrategy(selector)
+	affinity, _ := selector.(*SessionAffinitySelector)
+	if affinity != nil {
+		strategy = selectorStrategy(affinity.fallback)
+	}
+
+	explanation := &RoutingExplanation{
+		Model:      model,
+		Providers:  providers,
+		Selector:   routingSelectorName(selector, m.hasPluginScheduler()),
+		RetryRound: req.RetryRound,
+	}
+	defaultRequestRetry, _, _ := m.retrySettings()
+	tried := m.requestRetryRoundExclusions(req.RetryRound, defaultRequestRetry)
+	eligibility := authSelectionEligibilityForRequest(ctx, opts)
+	explanation.Candidates = m.routingCandidates(providers, model, eligibility, tried, strategy == schedulerStrategyWeightedRoundRobin, time.Now())
+	explanation.Tiers = routingPriorityTiers(explanation.Candidates)
+
+	eligibleProviders := make([]string, 0, len(providers))
+	for _, providerKey := range providers {
+		if _, ok := m.Executor(providerKey); ok {
+			eligibleProviders = append(eligibleProviders, providerKey)
+		}
+	}
+
+	if affinity != nil {
+		explanation.Session = affinity.explainBinding(opts, model, explanation.Candidates)
+	}
+	switch {
+	case m.hasPluginScheduler():
+		explanation.Note = "a plugin scheduler picks the credential at request time"
+		return explanation, nil
+	case explanation.Session != nil && explanation.Session.Usable:
+		explanation.markSelected(explanation.Session.AuthID)
+		return explanation, nil
+	case strategy == schedulerStrategyCustom:
+		explanation.Note = "a custom selector picks the credential at request time"
+		return explanation, nil
+	case len(eligibleProviders) == 0:
+		explanation.Error = (&Error{Code: "auth_not_found", Message: "no auth available"}).Error()
+		return explanation, nil
+	}
+
+	picked, _, errPick := m.scheduler.peekMixed(ctx, eligibleProviders, model, opts, tried, strategy)
+	if errPick != nil {
+		explanation.Error = errPick.Error()
+		return explanation, nil
+	}
+	if picked == nil {
+		explanation.Error = (&Error{Code: "auth_not_found", Message: "selector returned no auth"}).Error()
+		return explanation, nil
+	}
+	explanation.markSelected(picked.ID)
+	if affinity != nil {
+		explanation.Note = "predicted from scheduler rotation; the session-affinity fallback keeps its own rotation"
+	}
+	wsPreferred := len(eligibleProviders) == 1 && req.Websocket && providerPrefersWebsocketTransport(eligibleProviders[0])
+	explanation.markTierReasons(wsPreferred)
+	return explanation, nil
+}
+
+// routingCandidates reports every credential of providers with the reasons
+// the scheduler would pass over it, derived from the scheduler's own filters
+// and shard state.
+func (m *Manager) routingCandidates(providers []string, model string, eligibility authSelectionEligibility, tried map[string]struct{}, weighted bool, now time.Time) []RoutingCandidate {
+	providerSet := make(map[string]struct{}, len(providers))
+	for _, providerKey := range providers {
+		providerSet[providerKey] = struct{}{}
+	}
+	verdicts := m.scheduler.explainMixed(providers, model, eligibility, tried, weighted, now)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	candidates := make([]RoutingCandidate, 0)
+	for _, auth := range m.auths {
+		if auth == nil {
+			continue
+		}
+		providerKey := executorKeyFromAuth(auth)
+		if _, ok := providerSet[providerKey]; !ok {
+			continue
+		}
+		candidate := RoutingCandidate{
+			AuthID:    auth.ID,
+			AuthIndex: auth.Index,
+			Provider:  providerKey,
+			Label:     auth.Label,
+			Priority:  authPriority(auth),
+			Weight:    authWeight(auth),
+			Websocket: authWebsocketsEnabled(auth),
+		}
+		if auth.FileName != "" {
+			candidate.File = filepath.Base(auth.FileName)
+		}
+		var reasons []string
+		if _, ok := m.executors[providerKey]; !ok {
+			reasons = append(reasons, RoutingReasonExecutorMissing)
+		}
+		verdict, scheduled := verdicts[auth.ID]
+		switch {
+		case !scheduled:
+			// The scheduler only drops disabled credentials.
+			reasons = append(reasons, RoutingReasonDisabled)
+		case slices.Contains(verdict.reasons, RoutingReasonModelUnsupported):
+			reasons = append(reasons, routingUnsupportedReason(auth, model))
+		default:
+			reasons = append(reasons, verdict.reasons...)
+			if !verdict.nextRetryAt.IsZero() {
+				next := verdict.nextRetryAt
+				candidate.NextRetryAt = &next
+			}
+		}
+		if auth.IsDraining() {
+			drainingUntil := auth.DrainingUntil
+			candidate.DrainingUntil = &drainingUntil
+		}
+		candidate.Reasons = reasons
+		candidate.Eligible = len(reasons) == 0
+		candidates = append(candidates, candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Priority != candidates[j].Priority {
+			return candidates[i].Priority > candidates[j].Priority
+		}
+		return candidates[i].AuthID < candidates[j].AuthID
+	})
+	return candidates
+}
+
+// routingUnsupportedReason tells a model prefix mismatch or an excluded model
+// apart from a model the credential simply does not serve.
+func routingUnsupportedReason(auth *Auth, model string) string {
+	baseModel := canonicalModelKey(model)
+	if prefix, _, found := strings.Cut(baseModel, "/"); found {
+		if !strings.EqualFold(prefix, strings.TrimSpace(auth.Prefix)) {
+			return RoutingReasonPrefixMismatch
+		}
+		baseModel = baseModel[len(prefix)+1:]
+	}
+	if auth.Attributes != nil {
+		for _, pattern := range strings.Split(auth.Attributes["excluded_models"], ",") {
+			pattern = strings.ToLower(strings.TrimSpace(pattern))
+			if pattern == "" {
+				continue
+			}
+			if matched, _ := path.Match(pattern, strings.ToLower(baseModel)); matched {
+				return RoutingReasonExcludedModel
+			}
+		}
+	}
+	return RoutingReasonModelUnsupported
+}
+
+func routingPriorityTiers(candidates []RoutingCandidate) []RoutingPriorityTier {
+	tiers := make([]RoutingPriorityTier, 0)
+	for _, candidate := range candidates {
+		if !candidate.Eligible {
+			continue
+		}
+		if n := len(tiers); n > 0 && tiers[n-1].Priority == candidate.Priority {
+			tiers[n-1].AuthIDs = append(tiers[n-1].AuthIDs, candidate.AuthID)
+			continue
+		}
+		tiers = append(tiers, RoutingPriorityTier{Priority: candidate.Priority, AuthIDs: []string{candidate.AuthID}})
+	}
+	return tiers
+}
+
+func routingSelectorName(selector Selector, pluginScheduler bool) string {
+	if pluginScheduler {
+		return "plugin"
+	}
+	switch s := selector.(type) {
+	case *SessionAffinitySelector:
+		return "session-affinity/" + routingSelectorName(s.fallback, false)
+	case *FillFirstSelector:
+		return "fill-first"
+	case *WeightedRoundRobinSelector:
+		return "weighted-round-robin"
+	case *QuotaAwareSelector:
+		return "quota-aware"
+	case nil, *RoundRobinSelector:
+		return "round-robin"
+	default:
+		return "custom"
+	}
+}
+
+func (e *RoutingExplanation) markSelected(authID string) {
+	for i := range e.Candidates {
+		if e.Candidates[i].AuthID == authID {
+			e.Candidates[i].Selected = true
+			selected := e.Candidates[i]
+			e.Selected = &selected
+			return
+		}
+	}
+}
+
+// markTierReasons notes why eligible candidates lost to the selected one.
+func (e *RoutingExplanation) markTierReasons(wsPreferred bool) {
+	if e.Selected == nil {
+		return
+	}
+	wsPreferred = wsPreferred && e.Selected.Websocket
+	for i := range e.Candidates {
+		candidate := &e.Candidates[i]
+		if !candidate.Eligible || candidate.Selected {
+			continue
+		}
+		switch {
+		case wsPreferred && !candidate.Websocket:
+			candidate.Reasons = append(candidate.Reasons, RoutingReasonWebsocketPreferred)
+		case candidate.Priority < e.Selected.Priority:
+			candidate.Reasons = append(candidate.Reasons, RoutingReasonLowerPriority)
+		}
+	}
+}
+
+// explainBinding looks up the binding a request would hit without refreshing,
+// expiring or creating it.
+func (s *SessionAffinitySelector) explainBinding(opts cliproxyexecutor.Options, model string, candidates []RoutingCandidate) *RoutingSessionBinding {
+	primaryID, fallbackID := extractSessionIDs(opts.Headers, opts.OriginalRequest, opts.Metadata)
+	if primaryID == "" {
+		return nil
+	}
+	binding := &RoutingSessionBinding{SessionID: primaryID}
+	if fallbackID != primaryID {
+		binding.FallbackID = fallbackID
+	}
+	modelKey := canonicalModelKey(model)
+	keys := []string{"mixed::" + primaryID + "::" + modelKey}
+	if binding.FallbackID != "" {
+		keys = append(keys, "mixed::"+binding.FallbackID+"::"+modelKey)
+	}
+	// Like Pick, an unusable primary binding is replaced rather than falling
+	// through to the fallback binding.
+	for _, key := range keys {
+		authID, ok := s.cache.peek(key)
+		if !ok {
+			continue
+		}
+		binding.AuthID, binding.Bound = authID, true
+		for _, candidate := range candidates {
+			if candidate.AuthID == authID && (candidate.Eligible || onlyDraining(candidate.Reasons)) {
+				binding.Usable = true
+				break
+			}
+		}
+		return binding
+	}
+	return binding
+}
+
+func onlyDraining(reasons []string) bool {
+	return len(reasons) == 1 && reasons[0] == RoutingReasonDraining
+}
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+)
+
+func registerExplainAuth(t *testing.T, m *Manager, auth *Auth, model string) {
+	t.Helper()
+	if _, errRegister := m.Register(context.Background(), auth); errRegister != nil {
+		t.Fatalf("Register(%s) error = %v", auth.ID, errRegister)
+	}
+	if model != "" {
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: model}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+	}
+	m.RefreshSchedulerEntry(auth.ID)
+}
+
+func explainCandidate(t *testing.T, explanation *RoutingExplanation, authID string) RoutingCandidate {
+	t.Helper()
+	for _, candidate := range explanation.Candidates {
+		if candidate.AuthID == authID {
+			return candidate
+		}
+	}
+	t.Fatalf("candidate %s missing from %+v", authID, explanation.Candidates)
+	return RoutingCandidate{}
+}
+
+func TestManagerExplainRoutingReportsReasonsWithoutAdvancingRotation(t *testing.T) {
+	const provider, model = "explain-provider", "explain-model"
+	m := NewManager(nil, &RoundRobinSelector{}, nil)
+	m.RegisterExecutor(&healthProbeExecutor{provider: provider})
+
+	registerExplainAuth(t, m, &Auth{ID: "explain-a", Provider: provider, Status: StatusActive, Attributes: map[string]string{"priority": "10"}}, model)
+	registerExplainAuth(t, m, &Auth{ID: "explain-b", Provider: provider, Status: StatusActive, Attributes: map[string]string{"priority": "10"}}, model)
+	registerExplainAuth(t, m, &Auth{ID: "explain-low", Provider: provider, Status: StatusActive}, model)
+	registerExplainAuth(t, m, &Auth{ID: "explain-off", Provider: provider, Status: StatusDisabled, Disabled: true}, model)
+	registerExplainAuth(t, m, &Auth{ID: "explain-other", Provider: provider, Status: StatusActive}, "other-model")
+	registerExplainAuth(t, m, &Auth{
+		ID:       "explain-cool",
+		Provider: provider,
+		Status:   StatusActive,
+		ModelStates: map[string]*ModelState{model: {
+			Status:         StatusError,
+			Unavailable:    true,
+			NextRetryAfter: time.Now().Add(time.Hour),
+			Quota:          QuotaState{Exceeded: true, NextRecoverAt: time.Now().Add(time.Hour)},
+		}},
+	}, model)
+
+	req := RoutingExplainRequest{Providers: []string{provider}, Model: model}
+	first, errExplain := m.ExplainRouting(context.Background(), req)
+	if errExplain != nil {
+		t.Fatalf("ExplainRouting() error = %v", errExplain)
+	}
+	if first.Selected == nil || first.Error != "" {
+		t.Fatalf("expected a pick, got selected=%v error=%q", first.Selected, first.Error)
+	}
+	if first.Selector != "round-robin" {
+		t.Fatalf("Selector = %q, want round-robin", first.Selector)
+	}
+	wantReasons := map[string]string{
+		"explain-low":   RoutingReasonLowerPriority,
+		"explain-off":   RoutingReasonDisabled,
+		"explain-other": RoutingReasonModelUnsupported,
+		"explain-cool":  RoutingReasonCooldown,
+	}
+	for authID, reason := range wantReasons {
+		if candidate := explainCandidate(t, first, authID); !slices.Contains(candidate.Reasons, reason) {
+			t.Fatalf("%s reasons = %v, want %s", authID, candidate.Reasons, reason)
+		}
+	}
+	if cooling := explainCandidate(t, first, "explain-cool"); cooling.Eligible || cooling.NextRetryAt == nil {
+		t.Fatalf("cooling candidate = %+v, want ineligible with next_retry_at", cooling)
+	}
+	if len(first.Tiers) != 2 || first.Tiers[0].Priority != 10 || !slices.Equal(first.Tiers[0].AuthIDs, []string{"explain-a", "explain-b"}) {
+		t.Fatalf("Tiers = %+v", first.Tiers)
+	}
+
+	second, _ := m.ExplainRouting(context.Background(), req)
+	if second.Selected == nil || second.Selected.AuthID != first.Selected.AuthID {
+		t.Fatalf("second explain picked %v, want %s", second.Selected, first.Selected.AuthID)
+	}
+	picked, _, errPick := m.scheduler.pickMixed(context.Background(), []string{provider}, model, cliproxyexecutor.Options{}, nil)
+	if errPick != nil || picked.ID != first.Selected.AuthID {
+		t.Fatalf("real pick = %v (%v), want %s", picked, errPick, first.Selected.AuthID)
+	}
+	third, _ := m.ExplainRouting(context.Background(), req)
+	if third.Selected == nil || third.Selected.AuthID == first.Selected.AuthID {
+		t.Fatalf("explain after a real pick still selected %v", third.Selected)
+	}
+}
+
+func TestManagerExplainRoutingRetryRoundAndEmptyPool(t *testing.T) {
+	const provider, model = "explain-retry-provider", "explain-retry-model"
+	m := NewManager(nil, &RoundRobinSelector{}, nil)
+	m.RegisterExecutor(&healthProbeExecutor{provider: provider})
+	m.SetRetryConfig(0, 0, 0)
+	registerExplainAuth(t, m, &Auth{ID: "explain-retry", Provider: provider, Status: StatusActive}, model)
+
+	explanation, errExplain := m.ExplainRouting(context.Background(), RoutingExplainRequest{Providers: []string{provider}, Model: model, RetryRound: 1})
+	if errExplain != nil {
+		t.Fatalf("ExplainRouting() error = %v", errExplain)
+	}
+	if explanation.Selected != nil || explanation.Error == "" {
+		t.Fatalf("expected no pick and an error, got selected=%v error=%q", explanation.Selected, explanation.Error)
+	}
+	if candidate := explainCandidate(t, explanation, "explain-retry"); !slices.Contains(candidate.Reasons, RoutingReasonRetryRound) {
+		t.Fatalf("reasons = %v, want %s", candidate.Reasons, RoutingReasonRetryRound)
+	}
+}
+
+func TestManagerExplainRoutingReportsSessionBinding(t *testing.T) {
+	const provider, model = "explain-session-provider", "explain-session-model"
+	selector := NewSessionAffinitySelector(&RoundRobinSelector{})
+	m := NewManager(nil, selector, nil)
+	t.Cleanup(selector.Stop)
+	m.RegisterExecutor(&healthProbeExecutor{provider: provider})
+	registerExplainAuth(t, m, &Auth{ID: "explain-high", Provider: provider, Status: StatusActive, Attributes: map[string]string{"priority": "10"}}, model)
+	registerExplainAuth(t, m, &Auth{ID: "explain-bound", Provider: provider, Status: StatusActive}, model)
+
+	req := RoutingExplainRequest{Providers: []string{provider}, Model: model, SessionID: "explain-session"}
+	primaryID, _ := extractSessionIDs(nil, nil, map[string]any{cliproxyexecutor.ExecutionSessionMetadataKey: req.SessionID})
+	selector.cache.Set("mixed::"+primaryID+"::"+model, "explain-bound")
+
+	explanation, errExplain := m.ExplainRouting(context.Background(), req)
+	if errExplain != nil {
+		t.Fatalf("ExplainRouting() error = %v", errExplain)
+	}
+	if explanation.Session == nil || !explanation.Session.Bound || !explanation.Session.Usable || explanation.Session.AuthID != "explain-bound" {
+		t.Fatalf("Session = %+v", explanation.Session)
+	}
+	if explanation.Selected == nil || explanation.Selected.AuthID != "explain-bound" {
+		t.Fatalf("Selected = %+v, want the bound credential", explanation.Selected)
+	}
+}
+
+func TestManagerExplainRoutingLeavesSchedulerAndSessionsUntouched(t *testing.T) {
+	const provider, model = "explain-readonly-provider", "explain-readonly-model"
+	selector := NewSessionAffinitySelector(&RoundRobinSelector{})
+	m := NewManager(nil, selector, nil)
+	t.Cleanup(selector.Stop)
+	m.RegisterExecutor(&healthProbeExecutor{provider: provider})
+	registerExplainAuth(t, m, &Auth{ID: "explain-readonly", Provider: provider, Status: StatusActive}, model)
+
+	req := RoutingExplainRequest{Providers: []string{provider}, Model: model, SessionID: "explain-expired"}
+	primaryID, _ := extractSessionIDs(nil, nil, map[string]any{cliproxyexecutor.ExecutionSessionMetadataKey: req.SessionID})
+	key := "mixed::" + primaryID + "::" + model
+	selector.cache.mu.Lock()
+	selector.cache.entries[key] = sessionEntry{authID: "explain-readonly", expiresAt: time.Now().Add(-time.Second), aliases: []string{key}}
+	selector.cache.mu.Unlock()
+
+	explanation, errExplain := m.ExplainRouting(context.Background(), req)
+	if errExplain != nil || explanation.Selected == nil {
+		t.Fatalf("ExplainRouting() = %+v, %v", explanation, errExplain)
+	}
+	if explanation.Session == nil || explanation.Session.Bound {
+		t.Fatalf("Session = %+v, want the expired binding ignored", explanation.Session)
+	}
+	selector.cache.mu.RLock()
+	_, kept := selector.cache.entries[key]
+	selector.cache.mu.RUnlock()
+	if !kept {
+		t.Fatal("explain removed the expired binding")
+	}
+	m.scheduler.mu.Lock()
+	_, built := m.scheduler.providers[provider].modelShards[canonicalModelKey(model)]
+	m.scheduler.mu.Unlock()
+	if built {
+		t.Fatal("explain built a model shard on the live scheduler")
+	}
+}
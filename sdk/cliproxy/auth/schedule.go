@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/credentialschedule"
+)
+
+// compiledSchedules caches compiled availability schedules by their encoded form
+// so selection does not re-parse them on every pick.
+var compiledSchedules sync.Map
+
+// ApplyAuthScheduleMetadata validates and applies a source metadata schedule.
+func ApplyAuthScheduleMetadata(auth *Auth, metadata map[string]any) error {
+	if auth == nil || metadata == nil {
+		return nil
+	}
+	rawSchedule, ok := metadata[AttributeSchedule]
+	if !ok {
+		return nil
+	}
+	schedule, errParse := credentialschedule.ParseValue(rawSchedule)
+	if errParse != nil {
+		return fmt.Errorf("invalid metadata schedule: %w", errParse)
+	}
+	encoded := credentialschedule.Encode(schedule)
+	if encoded == "" {
+		return nil
+	}
+	if auth.Attributes == nil {
+		auth.Attributes = make(map[string]string)
+	}
+	auth.Attributes[AttributeSchedule] = encoded
+	return nil
+}
+
+// AuthSchedule returns the availability schedule configured for the auth, if any.
+func AuthSchedule(auth *Auth) *credentialschedule.Schedule {
+	raw := authScheduleSource(auth)
+	if raw == "" {
+		return nil
+	}
+	schedule, errParse := credentialschedule.ParseString(raw)
+	if errParse != nil {
+		return nil
+	}
+	return schedule
+}
+
+// AuthScheduleState evaluates the auth schedule at now. It reports false when
+// the auth has no schedule.
+func AuthScheduleState(auth *Auth, now time.Time) (credentialschedule.State, bool) {
+	raw := authScheduleSource(auth)
+	if raw == "" {
+		return credentialschedule.State{Active: true}, false
+	}
+	compiled, ok := compileAuthSchedule(raw)
+	if !ok {
+		// Fail closed: a credential with an unreadable schedule must not be used
+		// outside the hours its owner intended.
+		return credentialschedule.State{}, true
+	}
+	return compiled.At(now), true
+}
+
+// authScheduleSource returns the encoded schedule from attributes, falling back
+// to raw auth-file metadata.
+func authScheduleSource(auth *Auth) string {
+	if auth == nil {
+		return ""
+	}
+	if raw := strings.TrimSpace(auth.Attributes[AttributeSchedule]); raw != "" {
+		return raw
+	}
+	rawSchedule, ok := auth.Metadata[AttributeSchedule]
+	if !ok || rawSchedule == nil {
+		return ""
+	}
+	schedule, errParse := credentialschedule.ParseValue(rawSchedule)
+	if errParse != nil {
+		return "invalid"
+	}
+	return credentialschedule.Encode(schedule)
+}
+
+type cachedSchedule struct {
+	compiled *credentialschedule.Compiled
+	valid    bool
+}
+
+func compileAuthSchedule(raw string) (*credentialschedule.Compiled, bool) {
+	if cached, ok := compiledSchedules.Load(raw); ok {
+		entry := cached.(cachedSchedule)
+		return entry.compiled, entry.valid
+	}
+	entry := cachedSchedule{}
+	if schedule, errParse := credentialschedule.ParseString(raw); errParse == nil {
+		entry.compiled, errParse = credentialschedule.Compile(schedule)
+		entry.valid = errParse == nil
+	}
+	compiledSchedules.Store(raw, entry)
+	return entry.compiled, entry.valid
+}
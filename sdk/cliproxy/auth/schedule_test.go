@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/credentialschedule"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+)
+
+// scheduleAround encodes a daily UTC window spanning the given offsets from now.
+func scheduleAround(from, to time.Duration) string {
+	now := time.Now().UTC()
+	start, end := now.Add(from), now.Add(to)
+	return credentialschedule.Encode(&credentialschedule.Schedule{
+		Timezone: "UTC",
+		Windows:  []string{fmt.Sprintf("daily %02d:%02d-%02d:%02d", start.Hour(), start.Minute(), end.Hour(), end.Minute())},
+	})
+}
+
+func TestOffScheduleAuthIsSkippedAndExplained(t *testing.T) {
+	const provider, model = "schedule-provider", "schedule-model"
+	m := NewManager(nil, &RoundRobinSelector{}, nil)
+	m.RegisterExecutor(&healthProbeExecutor{provider: provider})
+	registerExplainAuth(t, m, &Auth{ID: "schedule-off", Provider: provider, Status: StatusActive, Attributes: map[string]string{AttributeSchedule: scheduleAround(2*time.Hour, 3*time.Hour)}}, model)
+	registerExplainAuth(t, m, &Auth{ID: "schedule-on", Provider: provider, Status: StatusActive, Attributes: map[string]string{AttributeSchedule: scheduleAround(-time.Hour, time.Hour)}}, model)
+
+	for i := 0; i < 4; i++ {
+		picked, _, errPick := m.pickNext(context.Background(), provider, model, cliproxyexecutor.Options{}, nil)
+		if errPick != nil || picked.ID != "schedule-on" {
+			t.Fatalf("pick %d = %v (%v), want schedule-on", i, picked, errPick)
+		}
+	}
+	if _, _, errPick := m.pickNext(context.Background(), provider, model, cliproxyexecutor.Options{}, map[string]struct{}{"schedule-on": {}}); errPick == nil {
+		t.Fatal("expected no pick when only the off-schedule auth remains")
+	}
+
+	explanation, _ := m.ExplainRouting(context.Background(), RoutingExplainRequest{Providers: []string{provider}, Model: model})
+	candidate := explainCandidate(t, explanation, "schedule-off")
+	if !slices.Contains(candidate.Reasons, RoutingReasonOffSchedule) || candidate.NextRetryAt == nil {
+		t.Fatalf("off-schedule candidate = %+v", candidate)
+	}
+	if until := time.Until(*candidate.NextRetryAt); until <= time.Hour || until > 2*time.Hour {
+		t.Fatalf("off-schedule next retry in %s, want the window start", until)
+	}
+}
+
+func TestSchedulePriorityOverrideChangesTier(t *testing.T) {
+	const provider = "schedule-priority-provider"
+	now := time.Now().UTC()
+	start, end := now.Add(-time.Hour), now.Add(time.Hour)
+	override := credentialschedule.Encode(&credentialschedule.Schedule{
+		Timezone: "UTC",
+		Priorities: []credentialschedule.PriorityWindow{{
+			Windows:  []string{fmt.Sprintf("%02d:%02d-%02d:%02d", start.Hour(), start.Minute(), end.Hour(), end.Minute())},
+			Priority: 10,
+		}},
+	})
+	m := NewManager(nil, &RoundRobinSelector{}, nil)
+	m.RegisterExecutor(&healthProbeExecutor{provider: provider})
+	registerExplainAuth(t, m, &Auth{ID: "schedule-night", Provider: provider, Status: StatusActive, Attributes: map[string]string{AttributeSchedule: override}}, "")
+	registerExplainAuth(t, m, &Auth{ID: "schedule-day", Provider: provider, Status: StatusActive, Attributes: map[string]string{"priority": "5"}}, "")
+
+	for i := 0; i < 3; i++ {
+		picked, _, errPick := m.pickNext(context.Background(), provider, "", cliproxyexecutor.Options{}, nil)
+		if errPick != nil || picked.ID != "schedule-night" {
+			t.Fatalf("pick %d = %v (%v), want the overridden schedule-night", i, picked, errPick)
+		}
+	}
+}
+
+func TestSchedulerReevaluatesAtWindowBoundary(t *testing.T) {
+	// 2026-10-20 is a Tuesday; the window opens on Monday 2026-10-26.
+	tuesday := time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC)
+	monday := time.Date(2026, 10, 26, 1, 0, 0, 0, time.UTC)
+	schedule := credentialschedule.Encode(&credentialschedule.Schedule{Timezone: "UTC", Windows: []string{"mon 00:00-24:00"}})
+
+	scheduler := newAuthScheduler(&RoundRobinSelector{})
+	scheduler.upsertAuthLocked(&Auth{ID: "monday-only", Provider: "gemini", Attributes: map[string]string{AttributeSchedule: schedule}}, tuesday)
+	predicate := scheduledAuthPredicate(authSelectionEligibility{}, nil, "", false)
+
+	shard := scheduler.providers["gemini"].ensureModelLocked("", tuesday)
+	if picked := shard.pickReadyLocked(false, schedulerStrategyRoundRobin, predicate); picked != nil {
+		t.Fatalf("Tuesday pick = %s, want none", picked.ID)
+	}
+	if entry := shard.entries["monday-only"]; entry.state != scheduledStateBlocked || !entry.nextRetryAt.Equal(time.Date(2026, 10, 26, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Tuesday entry = %+v, want blocked until Monday", entry)
+	}
+
+	shard = scheduler.providers["gemini"].ensureModelLocked("", monday)
+	if picked := shard.pickReadyLocked(false, schedulerStrategyRoundRobin, predicate); picked == nil || picked.ID != "monday-only" {
+		t.Fatalf("Monday pick = %v, want monday-only", picked)
+	}
+	if next := scheduler.providers["gemini"].nextScheduleTransition; !next.Equal(time.Date(2026, 10, 27, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("next schedule transition = %s, want Tuesday 00:00", next)
+	}
+}
+
+func TestApplyAuthScheduleMetadataRejectsInvalidSchedules(t *testing.T) {
+	auth := &Auth{ID: "schedule-meta"}
+	if errApply := ApplyAuthScheduleMetadata(auth, map[string]any{AttributeSchedule: map[string]any{"windows": []any{"someday 10:00-11:00"}}}); errApply == nil {
+		t.Fatal("expected an invalid schedule to be rejected")
+	}
+	if errApply := ApplyAuthScheduleMetadata(auth, map[string]any{AttributeSchedule: map[string]any{"timezone": "UTC", "windows": []any{"sat,sun 00:00-24:00"}}}); errApply != nil {
+		t.Fatalf("ApplyAuthScheduleMetadata() error = %v", errApply)
+	}
+	if schedule := AuthSchedule(auth); schedule == nil || schedule.Timezone != "UTC" {
+		t.Fatalf("AuthSchedule() = %+v", schedule)
+	}
+	if state, ok := AuthScheduleState(&Auth{Attributes: map[string]string{AttributeSchedule: "not json"}}, time.Now()); !ok || state.Active {
+		t.Fatalf("unreadable schedule state = %+v, %v; want inactive", state, ok)
+	}
+}
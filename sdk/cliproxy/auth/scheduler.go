@@ -30,6 +30,9 @@ const (
 	scheduledStateCooldown
 	scheduledStateBlocked
 	scheduledStateDisabled
+	// scheduledStateDraining auths only serve existing session bindings, which
+	// the session-affinity selector resolves outside the scheduler.
+	scheduledStateDraining
 )
 
 // authScheduler keeps the incremental provider/model scheduling state used by Manager.
@@ -47,6 +50,9 @@ type providerScheduler struct {
 	providerKey string
 	auths       map[string]*scheduledAuthMeta
 	modelShards map[string]*modelScheduler
+	// nextScheduleTransition is the earliest availability-schedule boundary
+	// among the provider auths; zero when none of them is scheduled.
+	nextScheduleTransition time.Time
 }
 
 // scheduledAuthMeta stores the immutable scheduling fields derived from an auth snapshot.
@@ -57,6 +63,9 @@ type scheduledAuthMeta struct {
 	weight            int64
 	websocketEnabled  bool
 	supportedModelSet map[string]struct{}
+	// scheduleTransition is when the auth schedule next changes availability
+	// or priority, at which point the metadata must be rebuilt.
+	scheduleTransition time.Time
 }
 
 // modelScheduler tracks ready and blocked auths for one provider/model combination.
@@ -103,20 +112,24 @@ type readyBucketCursorState struct {
 }
 
 func snapshotReadyViewCursors(view readyView) readyViewCursorState {
-	state := readyViewCursorState{cursor: view.cursor}
-	if len(view.weightedState.current) > 0 {
-		state.weightedState.current = make(map[string]int64, len(view.weightedState.current))
-		for authID, current := range view.weightedState.current {
-			state.weightedState.current[authID] = current
+	return readyViewCursorState{cursor: view.cursor, weightedState: cloneSmoothWeightedState(view.weightedState)}
+}
+
+func cloneSmoothWeightedState(state smoothWeightedState) smoothWeightedState {
+	var clone smoothWeightedState
+	if len(state.current) > 0 {
+		clone.current = make(map[string]int64, len(state.current))
+		for authID, current := range state.current {
+			clone.current[authID] = current
 		}
 	}
-	if len(view.weightedState.weights) > 0 {
-		state.weightedState.weights = make(map[string]int64, len(view.weightedState.weights))
-		for authID, weight := range view.weightedState.weights {
-			state.weightedState.weights[authID] = weight
+	if len(state.weights) > 0 {
+		clone.weights = make(map[string]int64, len(state.weights))
+		for authID, weight := range state.weights {
+			clone.weights[authID] = weight
 		}
 	}
-	return state
+	return clone
 }
 
 func restoreReadyViewCursors(view *readyView, state readyViewCursorState) {
@@ -232,14 +245,18 @@ func (s *authScheduler) pickSingleWithStrategy(ctx context.Context, provider, mo
 	if s == nil {
 		return nil, &Error{Code: "auth_not_found", Message: "no auth available"}
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pickSingleLocked(ctx, provider, model, opts, tried, strategy)
+}
+
+func (s *authScheduler) pickSingleLocked(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, tried map[string]struct{}, strategy schedulerStrategy) (*Auth, error) {
 	providerKey := strings.ToLower(strings.TrimSpace(provider))
 	modelKey := canonicalModelKey(model)
 	pinnedAuthID := pinnedAuthIDFromMetadata(opts.Metadata)
 	eligibility := authSelectionEligibilityForRequest(ctx, opts)
 	preferWebsocket := cliproxyexecutor.DownstreamWebsocket(ctx) && providerPrefersWebsocketTransport(providerKey) && pinnedAuthID == ""
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
 	if strategy == schedulerStrategyCurrent {
 		strategy = s.strategy
 	}
@@ -276,6 +293,113 @@ func (s *authScheduler) pickMixedWithStrategy(ctx context.Context, providers []s
 	if s == nil {
 		return nil, "", &Error{Code: "auth_not_found", Message: "no auth available"}
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pickMixedLocked(ctx, providers, model, opts, tried, strategy)
+}
+
+// peekMixed resolves a mixed-provider pick exactly like pickMixedWithStrategy
+// on a copy of the scheduling state, so rotation cursors, cooldown promotion
+// and lazily built shards of the live scheduler are left untouched.
+func (s *authScheduler) peekMixed(ctx context.Context, providers []string, model string, opts cliproxyexecutor.Options, tried map[string]struct{}, strategy schedulerStrategy) (*Auth, string, error) {
+	if s == nil {
+		return nil, "", &Error{Code: "auth_not_found", Message: "no auth available"}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	preview := s.previewLocked(normalizeProviderKeys(providers), canonicalModelKey(model))
+	return preview.pickMixedLocked(ctx, providers, model, opts, tried, strategy)
+}
+
+// previewLocked copies the state a pick over providers and modelKey reads and
+// writes. Auth snapshots and their metadata are immutable and stay shared.
+func (s *authScheduler) previewLocked(providers []string, modelKey string) *authScheduler {
+	preview := &authScheduler{
+		strategy:            s.strategy,
+		providers:           make(map[string]*providerScheduler, len(providers)),
+		authProviders:       make(map[string]string, len(s.authProviders)),
+		mixedCursors:        make(map[string]int, len(s.mixedCursors)),
+		mixedWeightedStates: make(map[string]*smoothWeightedState, len(s.mixedWeightedStates)),
+	}
+	for authID, providerKey := range s.authProviders {
+		preview.authProviders[authID] = providerKey
+	}
+	for key, cursor := range s.mixedCursors {
+		preview.mixedCursors[key] = cursor
+	}
+	for key, state := range s.mixedWeightedStates {
+		if state != nil {
+			cloned := cloneSmoothWeightedState(*state)
+			preview.mixedWeightedStates[key] = &cloned
+		}
+	}
+	for _, providerKey := range providers {
+		if providerState := s.providers[providerKey]; providerState != nil {
+			preview.providers[providerKey] = providerState.cloneLocked(modelKey)
+		}
+	}
+	return preview
+}
+
+// cloneLocked copies the provider with only the modelKey shard, which is the
+// only shard a pick for that model touches.
+func (p *providerScheduler) cloneLocked(modelKey string) *providerScheduler {
+	clone := &providerScheduler{
+		providerKey:            p.providerKey,
+		auths:                  make(map[string]*scheduledAuthMeta, len(p.auths)),
+		modelShards:            make(map[string]*modelScheduler, 1),
+		nextScheduleTransition: p.nextScheduleTransition,
+	}
+	for authID, meta := range p.auths {
+		clone.auths[authID] = meta
+	}
+	if shard := p.modelShards[modelKey]; shard != nil {
+		clone.modelShards[modelKey] = shard.cloneLocked()
+	}
+	return clone
+}
+
+// cloneLocked copies the shard entries, ready views and cooldown queue.
+func (m *modelScheduler) cloneLocked() *modelScheduler {
+	clone := &modelScheduler{
+		modelKey:        m.modelKey,
+		entries:         make(map[string]*scheduledAuth, len(m.entries)),
+		priorityOrder:   append([]int(nil), m.priorityOrder...),
+		readyByPriority: make(map[int]*readyBucket, len(m.readyByPriority)),
+		blocked:         make(cooldownQueue, 0, len(m.blocked)),
+	}
+	copies := make(map[*scheduledAuth]*scheduledAuth, len(m.entries))
+	for authID, entry := range m.entries {
+		if entry == nil {
+			continue
+		}
+		copied := *entry
+		clone.entries[authID] = &copied
+		copies[entry] = &copied
+	}
+	cloneView := func(view readyView) readyView {
+		out := readyView{
+			flat:          make([]*scheduledAuth, 0, len(view.flat)),
+			cursor:        view.cursor,
+			weightedState: cloneSmoothWeightedState(view.weightedState),
+		}
+		for _, entry := range view.flat {
+			out.flat = append(out.flat, copies[entry])
+		}
+		return out
+	}
+	for priority, bucket := range m.readyByPriority {
+		if bucket != nil {
+			clone.readyByPriority[priority] = &readyBucket{all: cloneView(bucket.all), ws: cloneView(bucket.ws)}
+		}
+	}
+	for _, entry := range m.blocked {
+		clone.blocked = append(clone.blocked, copies[entry])
+	}
+	return clone
+}
+
+func (s *authScheduler) pickMixedLocked(ctx context.Context, providers []string, model string, opts cliproxyexecutor.Options, tried map[string]struct{}, strategy schedulerStrategy) (*Auth, string, error) {
 	normalized := normalizeProviderKeys(providers)
 	if len(normalized) == 0 {
 		return nil, "", &Error{Code: "provider_not_found", Message: "no provider supplied"}
@@ -284,7 +408,7 @@ func (s *authScheduler) pickMixedWithStrategy(ctx context.Context, providers []s
 		// When a single provider is eligible, reuse pickSingle so provider-specific preferences
 		// (for example Codex websocket transport) are applied consistently.
 		providerKey := normalized[0]
-		picked, errPick := s.pickSingleWithStrategy(ctx, providerKey, model, opts, tried, strategy)
+		picked, errPick := s.pickSingleLocked(ctx, providerKey, model, opts, tried, strategy)
 		if errPick != nil {
 			return nil, "", errPick
 		}
@@ -297,8 +421,6 @@ func (s *authScheduler) pickMixedWithStrategy(ctx context.Context, providers []s
 	eligibility := authSelectionEligibilityForRequest(ctx, opts)
 	modelKey := canonicalModelKey(model)
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
 	if strategy == schedulerStrategyCurrent {
 		strategy = s.strategy
 	}
@@ -488,20 +610,52 @@ func (s *authScheduler) mixedUnavailableErrorLocked(providers []string, model st
 	return &Error{Code: "auth_unavailable", Message: "no auth available"}
 }
 
+// scheduledAuthFilter is one request-level check applied before scheduler
+// state advances; reason is the routing-explain reason it reports.
+type scheduledAuthFilter struct {
+	reason  string
+	rejects func(*scheduledAuth) bool
+}
+
+// scheduledAuthFilters returns the request-level checks in the order selection applies them.
+func scheduledAuthFilters(eligibility authSelectionEligibility, tried map[string]struct{}, pinnedAuthID string, requirePositiveWeight bool) []scheduledAuthFilter {
+	filters := []scheduledAuthFilter{{
+		reason:  RoutingReasonIneligible,
+		rejects: func(entry *scheduledAuth) bool { return !eligibility.allows(entry.auth) },
+	}}
+	if requirePositiveWeight {
+		filters = append(filters, scheduledAuthFilter{
+			reason:  RoutingReasonZeroWeight,
+			rejects: func(entry *scheduledAuth) bool { return entry.meta == nil || entry.meta.weight <= 0 },
+		})
+	}
+	if pinnedAuthID != "" {
+		filters = append(filters, scheduledAuthFilter{
+			reason:  RoutingReasonIneligible,
+			rejects: func(entry *scheduledAuth) bool { return entry.auth.ID != pinnedAuthID },
+		})
+	}
+	if len(tried) > 0 {
+		filters = append(filters, scheduledAuthFilter{
+			reason: RoutingReasonRetryRound,
+			rejects: func(entry *scheduledAuth) bool {
+				_, ok := tried[entry.auth.ID]
+				return ok
+			},
+		})
+	}
+	return filters
+}
+
 // scheduledAuthPredicate filters request-ineligible auths before scheduler state advances.
 func scheduledAuthPredicate(eligibility authSelectionEligibility, tried map[string]struct{}, pinnedAuthID string, requirePositiveWeight bool) func(*scheduledAuth) bool {
+	filters := scheduledAuthFilters(eligibility, tried, pinnedAuthID, requirePositiveWeight)
 	return func(entry *scheduledAuth) bool {
-		if entry == nil || entry.auth == nil || !eligibility.allows(entry.auth) {
-			return false
-		}
-		if requirePositiveWeight && (entry.meta == nil || entry.meta.weight <= 0) {
-			return false
-		}
-		if pinnedAuthID != "" && entry.auth.ID != pinnedAuthID {
+		if entry == nil || entry.auth == nil {
 			return false
 		}
-		if len(tried) > 0 {
-			if _, ok := tried[entry.auth.ID]; ok {
+		for _, filter := range filters {
+			if filter.rejects(entry) {
 				return false
 			}
 		}
@@ -509,7 +663,65 @@ func scheduledAuthPredicate(eligibility authSelectionEligibility, tried map[stri
 	}
 }
 
-// normalizeProviderKeys lowercases, trims, and de-duplicates provider keys while preserving order.
+// scheduledAuthVerdict is how one auth fares in a model shard for a request.
+type scheduledAuthVerdict struct {
+	reasons     []string
+	nextRetryAt time.Time
+}
+
+// explainMixed classifies every scheduled auth of providers for model with the
+// filters and shard states pickMixedLocked selects by, on a copy of the
+// scheduling state. Auths the scheduler does not track are absent.
+func (s *authScheduler) explainMixed(providers []string, model string, eligibility authSelectionEligibility, tried map[string]struct{}, requirePositiveWeight bool, now time.Time) map[string]scheduledAuthVerdict {
+	if s == nil {
+		return nil
+	}
+	normalized := normalizeProviderKeys(providers)
+	modelKey := canonicalModelKey(model)
+	filters := scheduledAuthFilters(eligibility, tried, "", requirePositiveWeight)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	preview := s.previewLocked(normalized, modelKey)
+	verdicts := make(map[string]scheduledAuthVerdict)
+	for _, providerKey := range normalized {
+		providerState := preview.providers[providerKey]
+		if providerState == nil {
+			continue
+		}
+		shard := providerState.ensureModelLocked(modelKey, now)
+		for authID := range providerState.auths {
+			entry := shard.entries[authID]
+			if entry == nil || entry.auth == nil {
+				verdicts[authID] = scheduledAuthVerdict{reasons: []string{RoutingReasonModelUnsupported}}
+				continue
+			}
+			var verdict scheduledAuthVerdict
+			for _, filter := range filters {
+				if filter.rejects(entry) {
+					verdict.reasons = append(verdict.reasons, filter.reason)
+				}
+			}
+			switch entry.state {
+			case scheduledStateCooldown:
+				verdict.reasons = append(verdict.reasons, RoutingReasonCooldown)
+			case scheduledStateDisabled:
+				verdict.reasons = append(verdict.reasons, RoutingReasonDisabled)
+			case scheduledStateDraining:
+				verdict.reasons = append(verdict.reasons, RoutingReasonDraining)
+			case scheduledStateBlocked:
+				if scheduleState, ok := AuthScheduleState(entry.auth, now); ok && !scheduleState.Active {
+					verdict.reasons = append(verdict.reasons, RoutingReasonOffSchedule)
+				} else {
+					verdict.reasons = append(verdict.reasons, RoutingReasonUnavailable)
+				}
+			}
+			verdict.nextRetryAt = entry.nextRetryAt
+			verdicts[authID] = verdict
+		}
+	}
+	return verdicts
+}
+
 func normalizeProviderKeys(providers []string) []string {
 	seen := make(map[string]struct{}, len(providers))
 	out := make([]string, 0, len(providers))
@@ -553,7 +765,7 @@ func (s *authScheduler) upsertAuthLocked(auth *Auth, now time.Time) {
 			previousState.removeAuthLocked(authID)
 		}
 	}
-	meta := buildScheduledAuthMeta(auth)
+	meta := buildScheduledAuthMeta(auth, now)
 	s.authProviders[authID] = providerKey
 	s.ensureProviderLocked(providerKey).upsertAuthLocked(meta, now)
 }
@@ -589,15 +801,17 @@ func (s *authScheduler) ensureProviderLocked(providerKey string) *providerSchedu
 }
 
 // buildScheduledAuthMeta extracts the scheduling metadata needed for shard bookkeeping.
-func buildScheduledAuthMeta(auth *Auth) *scheduledAuthMeta {
+func buildScheduledAuthMeta(auth *Auth, now time.Time) *scheduledAuthMeta {
 	providerKey := executorKeyFromAuth(auth)
+	scheduleState, _ := AuthScheduleState(auth, now)
 	return &scheduledAuthMeta{
-		auth:              auth,
-		providerKey:       providerKey,
-		priority:          authPriority(auth),
-		weight:            authWeight(auth),
-		websocketEnabled:  authWebsocketsEnabled(auth),
-		supportedModelSet: supportedModelSetForAuth(auth.ID),
+		auth:               auth,
+		providerKey:        providerKey,
+		priority:           authPriorityAt(auth, now),
+		weight:             authWeight(auth),
+		websocketEnabled:   authWebsocketsEnabled(auth),
+		supportedModelSet:  supportedModelSetForAuth(auth.ID),
+		scheduleTransition: scheduleState.NextTransition,
 	}
 }
 
@@ -631,6 +845,9 @@ func (p *providerScheduler) upsertAuthLocked(meta *scheduledAuthMeta, now time.T
 		return
 	}
 	p.auths[meta.auth.ID] = meta
+	if !meta.scheduleTransition.IsZero() && (p.nextScheduleTransition.IsZero() || meta.scheduleTransition.Before(p.nextScheduleTransition)) {
+		p.nextScheduleTransition = meta.scheduleTransition
+	}
 	for modelKey, shard := range p.modelShards {
 		if shard == nil {
 			continue
@@ -656,11 +873,34 @@ func (p *providerScheduler) removeAuthLocked(authID string) {
 	}
 }
 
+// refreshSchedulesLocked rebuilds the metadata of auths whose availability
+// schedule crossed a window boundary, moving them in or out of the ready
+// buckets and applying time-of-day priority overrides.
+func (p *providerScheduler) refreshSchedulesLocked(now time.Time) {
+	if p == nil || p.nextScheduleTransition.IsZero() || p.nextScheduleTransition.After(now) {
+		return
+	}
+	p.nextScheduleTransition = time.Time{}
+	for _, meta := range p.auths {
+		if meta == nil || meta.scheduleTransition.IsZero() {
+			continue
+		}
+		if meta.scheduleTransition.After(now) {
+			if p.nextScheduleTransition.IsZero() || meta.scheduleTransition.Before(p.nextScheduleTransition) {
+				p.nextScheduleTransition = meta.scheduleTransition
+			}
+			continue
+		}
+		p.upsertAuthLocked(buildScheduledAuthMeta(meta.auth, now), now)
+	}
+}
+
 // ensureModelLocked returns the shard for modelKey, building it lazily from provider auths.
 func (p *providerScheduler) ensureModelLocked(modelKey string, now time.Time) *modelScheduler {
 	if p == nil {
 		return nil
 	}
+	p.refreshSchedulesLocked(now)
 	modelKey = canonicalModelKey(modelKey)
 	if shard, ok := p.modelShards[modelKey]; ok && shard != nil {
 		shard.promoteExpiredLocked(now)
@@ -715,25 +955,29 @@ func (m *modelScheduler) upsertEntryLocked(meta *scheduledAuthMeta, now time.Tim
 
 	entry.meta = meta
 	entry.auth = meta.auth
-	entry.nextRetryAt = time.Time{}
-	blocked, reason, next := isAuthBlockedForModel(meta.auth, m.modelKey, now)
+	entry.state, entry.nextRetryAt = scheduledStateForAuth(meta.auth, m.modelKey, now)
+
+	if ok && previousState == entry.state && previousNextRetryAt.Equal(entry.nextRetryAt) && previousPriority == meta.priority && previousWebsocketEnabled == meta.websocketEnabled {
+		return
+	}
+	m.rebuildIndexesLocked()
+}
+
+// scheduledStateForAuth classifies an auth for one model shard.
+func scheduledStateForAuth(auth *Auth, modelKey string, now time.Time) (scheduledState, time.Time) {
+	blocked, reason, next := isAuthBlockedForModel(auth, modelKey, now)
 	switch {
+	case !blocked && auth.IsDraining():
+		return scheduledStateDraining, time.Time{}
 	case !blocked:
-		entry.state = scheduledStateReady
+		return scheduledStateReady, time.Time{}
 	case reason == blockReasonCooldown:
-		entry.state = scheduledStateCooldown
-		entry.nextRetryAt = next
+		return scheduledStateCooldown, next
 	case reason == blockReasonDisabled:
-		entry.state = scheduledStateDisabled
+		return scheduledStateDisabled, time.Time{}
 	default:
-		entry.state = scheduledStateBlocked
-		entry.nextRetryAt = next
+		return scheduledStateBlocked, next
 	}
-
-	if ok && previousState == entry.state && previousNextRetryAt.Equal(entry.nextRetryAt) && previousPriority == meta.priority && previousWebsocketEnabled == meta.websocketEnabled {
-		return
-	}
-	m.rebuildIndexesLocked()
 }
 
 // removeEntryLocked deletes one auth entry and rebuilds the shard indexes if needed.
@@ -761,21 +1005,7 @@ func (m *modelScheduler) promoteExpiredLocked(now time.Time) {
 		if entry.nextRetryAt.IsZero() || entry.nextRetryAt.After(now) {
 			continue
 		}
-		blocked, reason, next := isAuthBlockedForModel(entry.auth, m.modelKey, now)
-		switch {
-		case !blocked:
-			entry.state = scheduledStateReady
-			entry.nextRetryAt = time.Time{}
-		case reason == blockReasonCooldown:
-			entry.state = scheduledStateCooldown
-			entry.nextRetryAt = next
-		case reason == blockReasonDisabled:
-			entry.state = scheduledStateDisabled
-			entry.nextRetryAt = time.Time{}
-		default:
-			entry.state = scheduledStateBlocked
-			entry.nextRetryAt = next
-		}
+		entry.state, entry.nextRetryAt = scheduledStateForAuth(entry.auth, m.modelKey, now)
 		changed = true
 	}
 	if changed {
@@ -65,12 +65,19 @@ func weightedSelectorStateModel(ctx context.Context, availabilityModel string) s
 // rolling-window subscription caps (e.g. chat message limits).
 type FillFirstSelector struct{}
 
+// QuotaAwareSelector prefers the credential with the most reported rate-limit
+// headroom relative to its reset time, rotating among equally scored ones.
+type QuotaAwareSelector struct {
+	ties RoundRobinSelector
+}
+
 type blockReason int
 
 const (
 	blockReasonNone blockReason = iota
 	blockReasonCooldown
 	blockReasonDisabled
+	blockReasonOffSchedule
 	blockReasonOther
 )
 
@@ -144,7 +151,18 @@ func (e *modelCooldownError) Headers() http.Header {
 }
 
 func authPriority(auth *Auth) int {
-	if auth == nil || auth.Attributes == nil {
+	return authPriorityAt(auth, time.Now())
+}
+
+// authPriorityAt returns the auth priority at now, honoring schedule overrides.
+func authPriorityAt(auth *Auth, now time.Time) int {
+	if auth == nil {
+		return 0
+	}
+	if state, ok := AuthScheduleState(auth, now); ok && state.Priority != nil {
+		return *state.Priority
+	}
+	if auth.Attributes == nil {
 		return 0
 	}
 	raw := strings.TrimSpace(auth.Attributes["priority"])
@@ -534,6 +552,30 @@ func (s *FillFirstSelector) Pick(ctx context.Context, provider, model string, op
 	return available[0], nil
 }
 
+// Pick selects the available auth whose tightest rate-limit window leaves the
+// most capacity per hour until it resets. Auths without reported limits rank first.
+func (s *QuotaAwareSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	now := time.Now()
+	available, err := getAvailableAuths(auths, provider, model, now)
+	if err != nil {
+		return nil, err
+	}
+	available = preferCodexWebsocketAuths(ctx, provider, available)
+	best := make([]*Auth, 0, len(available))
+	bestScore := math.Inf(-1)
+	for _, candidate := range available {
+		score := quotaHeadroom(candidate, model, now)
+		switch {
+		case score > bestScore:
+			best = append(best[:0], candidate)
+			bestScore = score
+		case score == bestScore:
+			best = append(best, candidate)
+		}
+	}
+	return s.ties.Pick(ctx, provider, model, opts, best)
+}
+
 func isAuthBlockedForModel(auth *Auth, model string, now time.Time) (bool, blockReason, time.Time) {
 	if auth == nil {
 		return true, blockReasonOther, time.Time{}
@@ -541,6 +583,9 @@ func isAuthBlockedForModel(auth *Auth, model string, now time.Time) (bool, block
 	if auth.Disabled || auth.Status == StatusDisabled {
 		return true, blockReasonDisabled, time.Time{}
 	}
+	if state, ok := AuthScheduleState(auth, now); ok && !state.Active {
+		return true, blockReasonOffSchedule, state.NextTransition
+	}
 	if auth.Quota.Exceeded && auth.Quota.Reason == "credential_quota" && auth.Quota.NextRecoverAt.After(now) {
 		return true, blockReasonCooldown, auth.Quota.NextRecoverAt
 	}
@@ -668,7 +713,7 @@ func (s *SessionAffinitySelector) Pick(ctx context.Context, provider, model stri
 		availabilityCandidates = positiveWeightAuths(auths)
 	}
 	if primaryID == "" {
-		fallbackAuths, errAvailable := getAvailableAuths(availabilityCandidates, provider, model, now)
+		fallbackAuths, errAvailable := getAvailableAuths(withoutDrainingAuths(availabilityCandidates), provider, model, now)
 		if errAvailable != nil {
 			return nil, errAvailable
 		}
@@ -678,11 +723,12 @@ func (s *SessionAffinitySelector) Pick(ctx context.Context, provider, model stri
 
 	// A single availability pass serves both lookups: the bound credential is validated against
 	// every priority tier, while the fallback selector keeps seeing only the highest tier.
+	// Draining credentials still serve their bindings but never take new ones.
 	available, err := getAvailableAuthsAcrossPriorities(availabilityCandidates, provider, model, now)
 	if err != nil {
 		return nil, err
 	}
-	fallbackAuths := highestPriorityAuths(available)
+	fallbackAuths := highestPriorityAuths(withoutDrainingAuths(available))
 
 	modelKey := canonicalModelKey(model)
 	cacheKey := provider + "::" + primaryID + "::" + modelKey
@@ -1815,6 +1815,29 @@ func TestSessionAffinitySelector_CrossProviderIsolation(t *testing.T) {
 	}
 }
 
+func TestSessionCacheGetDoesNotRefresh(t *testing.T) {
+	t.Parallel()
+
+	cache := NewSessionCache(time.Minute)
+	defer cache.Stop()
+
+	cache.Set("session1", "auth1")
+	cache.mu.RLock()
+	before := cache.entries["session1"].expiresAt
+	cache.mu.RUnlock()
+
+	time.Sleep(5 * time.Millisecond)
+	if got, ok := cache.Get("session1"); !ok || got != "auth1" {
+		t.Fatalf("Get() = %q, %v, want auth1, true", got, ok)
+	}
+	cache.mu.RLock()
+	after := cache.entries["session1"].expiresAt
+	cache.mu.RUnlock()
+	if !after.Equal(before) {
+		t.Fatalf("Get() changed expiry from %v to %v", before, after)
+	}
+}
+
 func TestSessionCache_GetAndRefresh(t *testing.T) {
 	t.Parallel()
 
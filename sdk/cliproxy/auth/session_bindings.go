@@ -0,0 +1,241 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SessionBinding describes one session-affinity binding. Session identifiers
+// are reported as hashes because they are often derived from user content.
+type SessionBinding struct {
+	// SessionHash identifies the session the binding was created for.
+	SessionHash string `json:"session_hash"`
+	// Namespace is the provider the binding applies to, or "mixed".
+	Namespace  string    `json:"namespace"`
+	Model      string    `json:"model"`
+	AuthID     string    `json:"auth_id"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	TTLSeconds int64     `json:"ttl_seconds"`
+	// Aliases lists the hashes of every session identifier sharing the binding.
+	Aliases []string `json:"aliases"`
+
+	keys []string
+}
+
+// SessionBindingHash returns the hash a session identifier is reported under.
+func SessionBindingHash(sessionID string) string {
+	sum := sha256.Sum256([]byte(sessionID))
+	return hex.EncodeToString(sum[:8])
+}
+
+// splitSessionCacheKey splits a "namespace::session::model" cache key.
+func splitSessionCacheKey(key string) (namespace, sessionID, model string) {
+	namespace, rest, ok := strings.Cut(key, "::")
+	if !ok {
+		return "", key, ""
+	}
+	index := strings.LastIndex(rest, "::")
+	if index < 0 {
+		return namespace, rest, ""
+	}
+	return namespace, rest[:index], rest[index+2:]
+}
+
+func sessionGroupKey(entry sessionEntry) string {
+	if len(entry.aliases) == 0 {
+		return entry.authID
+	}
+	return entry.authID + "\x00" + entry.aliases[0] + "\x00" + entry.expiresAt.String()
+}
+
+// matchesSession reports whether a session identifier matches query, given as
+// the identifier itself, its hash, or the raw client value without the
+// source prefix (for example "abc" for "header:abc").
+func matchesSession(sessionID, query string) bool {
+	if sessionID == query || SessionBindingHash(sessionID) == query {
+		return true
+	}
+	_, raw, found := strings.Cut(sessionID, ":")
+	return found && raw == query
+}
+
+// Bindings returns the live bindings, one per alias group, ordered by expiry.
+func (c *SessionCache) Bindings() []SessionBinding {
+	if c == nil {
+		return nil
+	}
+	now := time.Now()
+	c.mu.RLock()
+	seen := make(map[string]struct{}, len(c.entries))
+	bindings := make([]SessionBinding, 0, len(c.entries))
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			continue
+		}
+		groupKey := sessionGroupKey(entry)
+		if _, ok := seen[groupKey]; ok {
+			continue
+		}
+		seen[groupKey] = struct{}{}
+		keys := entry.aliases
+		if len(keys) == 0 {
+			keys = []string{key}
+		}
+		bindings = append(bindings, newSessionBinding(entry, keys, now))
+	}
+	c.mu.RUnlock()
+	sort.Slice(bindings, func(i, j int) bool {
+		if !bindings[i].ExpiresAt.Equal(bindings[j].ExpiresAt) {
+			return bindings[i].ExpiresAt.After(bindings[j].ExpiresAt)
+		}
+		return bindings[i].SessionHash < bindings[j].SessionHash
+	})
+	return bindings
+}
+
+func newSessionBinding(entry sessionEntry, keys []string, now time.Time) SessionBinding {
+	namespace, sessionID, model := splitSessionCacheKey(keys[0])
+	binding := SessionBinding{
+		SessionHash: SessionBindingHash(sessionID),
+		Namespace:   namespace,
+		Model:       model,
+		AuthID:      entry.authID,
+		ExpiresAt:   entry.expiresAt,
+		TTLSeconds:  int64(entry.expiresAt.Sub(now).Seconds()),
+		Aliases:     make([]string, 0, len(keys)),
+		keys:        append([]string(nil), keys...),
+	}
+	for _, key := range keys {
+		_, aliasSession, _ := splitSessionCacheKey(key)
+		binding.Aliases = append(binding.Aliases, SessionBindingHash(aliasSession))
+	}
+	return binding
+}
+
+// Lookup returns the live bindings whose session matches query, optionally
+// limited to one model.
+func (c *SessionCache) Lookup(query, model string) []SessionBinding {
+	query = strings.TrimSpace(query)
+	if c == nil || query == "" {
+		return nil
+	}
+	modelKey := canonicalModelKey(model)
+	matches := make([]SessionBinding, 0)
+	for _, binding := range c.Bindings() {
+		if modelKey != "" && canonicalModelKey(binding.Model) != modelKey {
+			continue
+		}
+		for _, key := range binding.keys {
+			if _, sessionID, _ := splitSessionCacheKey(key); matchesSession(sessionID, query) {
+				matches = append(matches, binding)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// Rebind moves the bindings matching query to authID, keeping their aliases.
+// When nothing matches and model is set, a new mixed-provider binding is
+// created for query, which must then be the full session identifier.
+func (c *SessionCache) Rebind(query, model, authID string) []SessionBinding {
+	query = strings.TrimSpace(query)
+	if c == nil || query == "" || authID == "" {
+		return nil
+	}
+	matches := c.Lookup(query, model)
+	if len(matches) == 0 {
+		modelKey := canonicalModelKey(model)
+		if modelKey == "" {
+			return nil
+		}
+		matches = []SessionBinding{{keys: []string{"mixed::" + query + "::" + modelKey}}}
+	}
+	for _, binding := range matches {
+		c.SetAliases(authID, binding.keys...)
+	}
+	rebound := make([]SessionBinding, 0, len(matches))
+	for _, binding := range matches {
+		_, sessionID, bindingModel := splitSessionCacheKey(binding.keys[0])
+		rebound = append(rebound, c.Lookup(sessionID, bindingModel)...)
+	}
+	return rebound
+}
+
+// SessionBindingsAvailable reports why the session-affinity bindings of this
+// node cannot be managed, or nil when they can. While Home is enabled, Home
+// selects credentials and the local bindings are never consulted.
+func (m *Manager) SessionBindingsAvailable() error {
+	_, errCache := m.sessionAffinityCache()
+	return errCache
+}
+
+func (m *Manager) sessionAffinityCache() (*SessionCache, error) {
+	if m == nil {
+		return nil, &Error{Code: "session_affinity_disabled", Message: "session affinity is disabled", HTTPStatus: http.StatusConflict}
+	}
+	if m.HomeEnabled() {
+		return nil, &Error{Code: "home_unavailable", Message: "session bindings are unavailable while Home is enabled", HTTPStatus: http.StatusServiceUnavailable}
+	}
+	m.mu.RLock()
+	selector, _ := m.selector.(*SessionAffinitySelector)
+	m.mu.RUnlock()
+	if selector == nil || selector.cache == nil {
+		return nil, &Error{Code: "session_affinity_disabled", Message: "session affinity is disabled", HTTPStatus: http.StatusConflict}
+	}
+	return selector.cache, nil
+}
+
+// SessionBindings lists the live session-affinity bindings of this node.
+func (m *Manager) SessionBindings() ([]SessionBinding, error) {
+	cache, errCache := m.sessionAffinityCache()
+	if errCache != nil {
+		return nil, errCache
+	}
+	return cache.Bindings(), nil
+}
+
+// LookupSessionBindings returns the bindings of one session. session may be
+// the session identifier, its hash, or the raw client value.
+func (m *Manager) LookupSessionBindings(session, model string) ([]SessionBinding, error) {
+	cache, errCache := m.sessionAffinityCache()
+	if errCache != nil {
+		return nil, errCache
+	}
+	return cache.Lookup(session, model), nil
+}
+
+// RebindSession forces a session onto authID until the binding next expires
+// or fails over.
+func (m *Manager) RebindSession(session, model, authID string) ([]SessionBinding, error) {
+	cache, errCache := m.sessionAffinityCache()
+	if errCache != nil {
+		return nil, errCache
+	}
+	auth, ok := m.GetByID(authID)
+	if !ok {
+		return nil, &Error{Code: "auth_not_found", Message: "auth not found", HTTPStatus: http.StatusNotFound}
+	}
+	if auth.IsDraining() {
+		return nil, &Error{Code: "auth_draining", Message: "auth is draining and takes no new bindings", HTTPStatus: http.StatusConflict}
+	}
+	rebound := cache.Rebind(session, model, authID)
+	if len(rebound) == 0 {
+		return nil, &Error{Code: "session_not_found", Message: "no binding for session; pass model to create one", HTTPStatus: http.StatusNotFound}
+	}
+	return rebound, nil
+}
+
+// InvalidateSessionBindings drops every binding of authID and reports how
+// many were removed.
+func (m *Manager) InvalidateSessionBindings(authID string) (int, error) {
+	cache, errCache := m.sessionAffinityCache()
+	if errCache != nil {
+		return 0, errCache
+	}
+	return cache.InvalidateAuthCount(authID), nil
+}
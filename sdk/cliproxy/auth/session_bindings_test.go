@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSessionCacheBindingsGroupAliases(t *testing.T) {
+	cache := NewSessionCache(time.Minute)
+	defer cache.Stop()
+	cache.SetAliases("auth-a", "claude::header:abc::claude-test", "claude::conv:conversation-1::claude-test")
+	cache.Set("mixed::header:other::gpt-test", "auth-b")
+
+	bindings := cache.Bindings()
+	if len(bindings) != 2 {
+		t.Fatalf("Bindings() = %+v, want two alias groups", bindings)
+	}
+	var grouped SessionBinding
+	for _, binding := range bindings {
+		if binding.AuthID == "auth-a" {
+			grouped = binding
+		}
+	}
+	if grouped.Namespace != "claude" || grouped.Model != "claude-test" || len(grouped.Aliases) != 2 {
+		t.Fatalf("grouped binding = %+v", grouped)
+	}
+	if grouped.SessionHash != SessionBindingHash("header:abc") || grouped.TTLSeconds <= 0 {
+		t.Fatalf("grouped binding = %+v, want hash of header:abc and a positive TTL", grouped)
+	}
+}
+
+func TestSessionCacheLookupMatchesHashAndRawValue(t *testing.T) {
+	cache := NewSessionCache(time.Minute)
+	defer cache.Stop()
+	cache.Set("mixed::header:abc::gpt-test", "auth-a")
+
+	for _, query := range []string{"header:abc", "abc", SessionBindingHash("header:abc")} {
+		if matches := cache.Lookup(query, ""); len(matches) != 1 || matches[0].AuthID != "auth-a" {
+			t.Fatalf("Lookup(%q) = %+v, want the auth-a binding", query, matches)
+		}
+	}
+	if matches := cache.Lookup("abc", "other-model"); len(matches) != 0 {
+		t.Fatalf("Lookup with another model = %+v, want none", matches)
+	}
+}
+
+func TestSessionCacheRebindKeepsAliases(t *testing.T) {
+	cache := NewSessionCache(time.Minute)
+	defer cache.Stop()
+	cache.SetAliases("auth-a", "claude::header:abc::claude-test", "claude::conv:conversation-1::claude-test")
+
+	rebound := cache.Rebind("abc", "", "auth-b")
+	if len(rebound) != 1 || rebound[0].AuthID != "auth-b" || len(rebound[0].Aliases) != 2 {
+		t.Fatalf("Rebind() = %+v, want one auth-b binding with both aliases", rebound)
+	}
+	if authID, ok := cache.Get("claude::conv:conversation-1::claude-test"); !ok || authID != "auth-b" {
+		t.Fatalf("alias bound to %q (%v), want auth-b", authID, ok)
+	}
+
+	if created := cache.Rebind("header:new", "", "auth-b"); len(created) != 0 {
+		t.Fatalf("Rebind() without model created %+v", created)
+	}
+	created := cache.Rebind("header:new", "gpt-test", "auth-b")
+	if len(created) != 1 || created[0].Namespace != "mixed" || created[0].Model != "gpt-test" {
+		t.Fatalf("Rebind() with model = %+v, want a new mixed binding", created)
+	}
+}
+
+func TestSessionCacheInvalidateAuthCountsAliasGroups(t *testing.T) {
+	cache := NewSessionCache(time.Minute)
+	defer cache.Stop()
+	cache.SetAliases("auth-a", "claude::header:abc::claude-test", "claude::conv:conversation-1::claude-test")
+	cache.Set("mixed::header:other::gpt-test", "auth-a")
+	cache.Set("mixed::header:kept::gpt-test", "auth-b")
+
+	if removed := cache.InvalidateAuthCount("auth-a"); removed != 2 {
+		t.Fatalf("InvalidateAuthCount() = %d, want 2", removed)
+	}
+	if bindings := cache.Bindings(); len(bindings) != 1 || bindings[0].AuthID != "auth-b" {
+		t.Fatalf("Bindings() after invalidation = %+v", bindings)
+	}
+}
+
+func TestManagerSessionBindingsRequireSessionAffinity(t *testing.T) {
+	m := NewManager(nil, &RoundRobinSelector{}, nil)
+	_, errBindings := m.SessionBindings()
+	var authErr *Error
+	if !errors.As(errBindings, &authErr) || authErr.HTTPStatus != http.StatusConflict {
+		t.Fatalf("SessionBindings() error = %v, want 409", errBindings)
+	}
+}
+
+func TestManagerRebindSessionValidatesAuth(t *testing.T) {
+	selector := NewSessionAffinitySelector(&RoundRobinSelector{})
+	t.Cleanup(selector.Stop)
+	m := NewManager(nil, selector, nil)
+	registerExplainAuth(t, m, &Auth{ID: "rebind-target", Provider: "rebind-provider", Status: StatusActive}, "")
+	selector.cache.Set("mixed::header:abc::gpt-test", "rebind-source")
+
+	var authErr *Error
+	if _, errRebind := m.RebindSession("abc", "", "missing"); !errors.As(errRebind, &authErr) || authErr.HTTPStatus != http.StatusNotFound {
+		t.Fatalf("RebindSession() to unknown auth error = %v, want 404", errRebind)
+	}
+	rebound, errRebind := m.RebindSession("abc", "", "rebind-target")
+	if errRebind != nil || len(rebound) != 1 || rebound[0].AuthID != "rebind-target" {
+		t.Fatalf("RebindSession() = %+v, %v", rebound, errRebind)
+	}
+	if removed, _ := m.InvalidateSessionBindings("rebind-target"); removed != 1 {
+		t.Fatalf("InvalidateSessionBindings() = %d, want 1", removed)
+	}
+}
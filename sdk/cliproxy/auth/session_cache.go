@@ -70,6 +70,21 @@ func (c *SessionCache) Get(sessionID string) (string, bool) {
 	return "", false
 }
 
+// peek returns the auth ID bound to a live session without refreshing or
+// expiring anything.
+func (c *SessionCache) peek(sessionID string) (string, bool) {
+	if c == nil || sessionID == "" {
+		return "", false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[sessionID]
+	if !ok || !time.Now().Before(entry.expiresAt) {
+		return "", false
+	}
+	return entry.authID, true
+}
+
 // GetAndRefresh retrieves the auth ID bound to a session and refreshes the TTL
 // for every identifier known to represent the same logical session.
 func (c *SessionCache) GetAndRefresh(sessionID string) (string, bool) {
@@ -306,16 +321,25 @@ func (c *SessionCache) Invalidate(sessionID string) {
 // InvalidateAuth removes all sessions bound to a specific auth ID.
 // Used when an auth becomes unavailable.
 func (c *SessionCache) InvalidateAuth(authID string) {
+	c.InvalidateAuthCount(authID)
+}
+
+// InvalidateAuthCount is InvalidateAuth that also reports how many bindings,
+// counted once per alias group, were removed.
+func (c *SessionCache) InvalidateAuthCount(authID string) int {
 	if authID == "" {
-		return
+		return 0
 	}
+	removed := make(map[string]struct{})
 	c.mu.Lock()
 	for sid, entry := range c.entries {
 		if entry.authID == authID {
 			delete(c.entries, sid)
+			removed[sessionGroupKey(entry)] = struct{}{}
 		}
 	}
 	c.mu.Unlock()
+	return len(removed)
 }
 
 // Stop terminates the background cleanup goroutine.
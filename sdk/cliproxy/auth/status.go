@@ -16,4 +16,7 @@ const (
 	StatusError Status = "error"
 	// StatusDisabled marks the auth as intentionally disabled.
 	StatusDisabled Status = "disabled"
+	// StatusDraining marks an auth that only serves its existing session
+	// bindings and is disabled once they idle out or its drain deadline passes.
+	StatusDraining Status = "draining"
 )
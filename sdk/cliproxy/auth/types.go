@@ -67,6 +67,9 @@ type Auth struct {
 	Disabled bool `json:"disabled"`
 	// Unavailable flags transient provider unavailability (e.g. quota exceeded).
 	Unavailable bool `json:"unavailable"`
+	// DrainingUntil is the drain deadline while the auth is draining. Draining
+	// is runtime state and is not written to the backing auth file.
+	DrainingUntil time.Time `json:"draining_until,omitzero"`
 	// ProxyURL overrides the global proxy setting for this auth if provided.
 	ProxyURL string `json:"proxy_url,omitempty"`
 	// Attributes stores provider specific metadata needed by executors (immutable configuration).
@@ -174,6 +177,45 @@ type QuotaState struct {
 	NextRecoverAt time.Time `json:"next_recover_at"`
 	// BackoffLevel stores the progressive cooldown exponent used for rate limits.
 	BackoffLevel int `json:"backoff_level,omitempty"`
+	// Limits is the rate-limit headroom the upstream last reported for the credential.
+	Limits QuotaLimits `json:"limits,omitempty"`
+}
+
+// QuotaLimits is a normalized snapshot of upstream rate-limit windows.
+type QuotaLimits struct {
+	// Windows lists every rate-limit window the upstream reported.
+	Windows []QuotaWindow `json:"windows,omitempty"`
+	// ObservedAt is when any window was last reported.
+	ObservedAt time.Time `json:"observed_at,omitempty"`
+}
+
+// QuotaWindow is the remaining capacity of one upstream rate-limit window.
+type QuotaWindow struct {
+	// Name identifies the window, e.g. "requests", "tokens", "unified-5h" or "codex-primary".
+	Name string `json:"name"`
+	// Model is the model a per-model window applies to; empty for windows that
+	// cover the whole account.
+	Model string `json:"model,omitempty"`
+	// Limit and Remaining are absolute counts when the upstream reports them.
+	Limit     int64 `json:"limit,omitempty"`
+	Remaining int64 `json:"remaining,omitempty"`
+	// RemainingFraction is the unused share of the window, from 0 to 1.
+	RemainingFraction float64 `json:"remaining_fraction"`
+	// ResetAt is when the window refills; zero when unknown.
+	ResetAt time.Time `json:"reset_at,omitempty"`
+	// Source records where the window was reported: response headers or a provider usage endpoint.
+	Source string `json:"source,omitempty"`
+	// ObservedAt is when the window was last reported.
+	ObservedAt time.Time `json:"observed_at,omitempty"`
+	// BaselineFraction and BaselineAt are the first sample of the current reset
+	// cycle, from which BurnRate is estimated.
+	BaselineFraction float64   `json:"baseline_fraction,omitempty"`
+	BaselineAt       time.Time `json:"baseline_at,omitempty"`
+	// BurnRate is the estimated share of the window spent per hour.
+	BurnRate float64 `json:"burn_rate,omitempty"`
+	// ExhaustsAt predicts when the window runs out at the current burn rate.
+	// It is zero when the window is expected to last until ResetAt.
+	ExhaustsAt time.Time `json:"exhausts_at,omitempty"`
 }
 
 // ModelState captures the execution state for a specific model under an auth entry.
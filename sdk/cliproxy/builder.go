@@ -7,7 +7,9 @@ import (
 	"context"
 	"fmt"
 
+	clientcertaccess "github.com/router-for-me/CLIProxyAPI/v7/internal/access/client_cert_access"
 	configaccess "github.com/router-for-me/CLIProxyAPI/v7/internal/access/config_access"
+	jwtaccess "github.com/router-for-me/CLIProxyAPI/v7/internal/access/jwt_access"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/api"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/pluginhost"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/watcher"
@@ -197,6 +199,12 @@ func (b *Builder) Build() (*Service, error) {
 	if errValidate := b.cfg.ValidateCredentialWeights(); errValidate != nil {
 		return nil, fmt.Errorf("cliproxy: validate credential weights: %w", errValidate)
 	}
+	if errValidate := b.cfg.ValidateCredentialSchedules(); errValidate != nil {
+		return nil, fmt.Errorf("cliproxy: validate credential schedules: %w", errValidate)
+	}
+	if errEncryption := b.cfg.ApplyEncryption(); errEncryption != nil {
+		return nil, fmt.Errorf("cliproxy: %w", errEncryption)
+	}
 	b.cfg.NormalizePluginsConfig()
 	if errResolvePluginsDir := b.cfg.ResolvePluginsDir(); errResolvePluginsDir != nil && b.cfg.Plugins.Enabled {
 		return nil, fmt.Errorf("cliproxy: %w", errResolvePluginsDir)
@@ -228,6 +236,8 @@ func (b *Builder) Build() (*Service, error) {
 	}
 
 	configaccess.Register(&b.cfg.SDKConfig)
+	jwtaccess.Register(&b.cfg.SDKConfig)
+	clientcertaccess.Register(&b.cfg.SDKConfig)
 	pluginHost := b.pluginHost
 	if pluginHost == nil {
 		pluginHost = pluginhost.New()
@@ -2,6 +2,7 @@ package cliproxy
 
 import (
 	"context"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -398,6 +399,26 @@ func (s *Service) resolveCooldownStateStore(cfg *config.Config) coreauth.Cooldow
 	return coreauth.NewFileCooldownStateStoreWithAuthDir(authDir, authDir)
 }
 
+// configureQuotaWindowStore persists observed quota windows next to the auth files.
+func (s *Service) configureQuotaWindowStore(cfg *config.Config) {
+	if s == nil || s.coreManager == nil {
+		return
+	}
+	if cfg == nil || cfg.Home.Enabled {
+		s.coreManager.SetQuotaWindowStore(nil)
+		return
+	}
+	authDir, errResolve := resolveCooldownStateAuthDir(cfg)
+	if errResolve != nil || authDir == "" {
+		if errResolve != nil {
+			log.Warnf("failed to resolve quota window directory: %v", errResolve)
+		}
+		s.coreManager.SetQuotaWindowStore(nil)
+		return
+	}
+	s.coreManager.SetQuotaWindowStore(coreauth.NewFileQuotaWindowStore(filepath.Join(authDir, coreauth.QuotaWindowFileName)))
+}
+
 func resolveCooldownStateAuthDir(cfg *config.Config) (string, error) {
 	if cfg == nil {
 		return "", nil
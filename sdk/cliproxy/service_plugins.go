@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/events"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/pluginhost"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v7/sdk/access"
@@ -353,5 +354,12 @@ func (s *Service) registerModelRefreshCallback() {
 		if refreshed > 0 {
 			log.Infof("re-registered models for %d auth(s) due to model catalog changes: %v", refreshed, changedProviders)
 		}
+		events.Publish(events.TypeModelsRefreshed, modelsRefreshedEvent{Providers: changedProviders, ReregisteredAuths: refreshed})
 	})
 }
+
+// modelsRefreshedEvent is the payload of models.refreshed management events.
+type modelsRefreshedEvent struct {
+	Providers         []string `json:"providers"`
+	ReregisteredAuths int      `json:"reregistered_auths"`
+}
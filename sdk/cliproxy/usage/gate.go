@@ -0,0 +1,86 @@
+package usage
+
+import (
+	"context"
+	"sync"
+)
+
+type publishGateContextKey struct{}
+
+type heldRecord struct {
+	manager *Manager
+	ctx     context.Context
+	record  Record
+}
+
+type publishGate struct {
+	mu       sync.Mutex
+	released bool
+	finalize func(*Record)
+	held     []heldRecord
+}
+
+// WithPublishGate returns ctx with a gate that holds usage records published
+// under it until ReleasePublishGate is called. Handlers use it to attach data
+// that is only known once the client response is complete, such as output
+// redactions, to records emitted earlier by executors.
+func WithPublishGate(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if publishGateFromContext(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, publishGateContextKey{}, &publishGate{})
+}
+
+// ReleasePublishGate applies finalize to every held record and publishes them.
+// Records published under ctx afterwards are finalized and published directly.
+// Calling it more than once has no further effect.
+func ReleasePublishGate(ctx context.Context, finalize func(*Record)) {
+	gate := publishGateFromContext(ctx)
+	if gate == nil {
+		return
+	}
+	gate.mu.Lock()
+	if gate.released {
+		gate.mu.Unlock()
+		return
+	}
+	gate.released = true
+	gate.finalize = finalize
+	held := gate.held
+	gate.held = nil
+	gate.mu.Unlock()
+
+	for _, item := range held {
+		if finalize != nil {
+			finalize(&item.record)
+		}
+		// Mask the gate so the record is not finalized a second time.
+		item.manager.Publish(context.WithValue(item.ctx, publishGateContextKey{}, (*publishGate)(nil)), item.record)
+	}
+}
+
+func publishGateFromContext(ctx context.Context) *publishGate {
+	if ctx == nil {
+		return nil
+	}
+	gate, _ := ctx.Value(publishGateContextKey{}).(*publishGate)
+	return gate
+}
+
+// hold queues record while the gate is open. After release it finalizes the
+// record in place and reports false so the caller publishes it immediately.
+func (g *publishGate) hold(m *Manager, ctx context.Context, record *Record) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.released {
+		if g.finalize != nil {
+			g.finalize(record)
+		}
+		return false
+	}
+	g.held = append(g.held, heldRecord{manager: m, ctx: ctx, record: *record})
+	return true
+}
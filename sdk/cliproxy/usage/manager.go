@@ -53,6 +53,8 @@ type Record struct {
 	Detail      Detail
 	// ResponseHeaders stores a snapshot of upstream response headers for usage sinks.
 	ResponseHeaders http.Header
+	// Redactions counts output filter matches per rule for the client response.
+	Redactions map[string]int64
 }
 
 // Failure holds HTTP failure metadata for an upstream request attempt.
@@ -313,6 +315,9 @@ func (m *Manager) Publish(ctx context.Context, record Record) {
 	if m == nil {
 		return
 	}
+	if gate := publishGateFromContext(ctx); gate != nil && gate.hold(m, ctx, &record) {
+		return
+	}
 	// ensure worker is running even if Start was not called explicitly
 	m.Start(context.Background())
 	m.mu.Lock()
@@ -3,6 +3,7 @@ package usage
 import (
 	"context"
 	"testing"
+	"time"
 )
 
 func TestGenerateEnabledDefaultsNilToTrue(t *testing.T) {
@@ -50,3 +51,48 @@ func TestRecordOmittedGenerateIsEnabled(t *testing.T) {
 		t.Fatalf("GenerateEnabled(omitted) = false, want true")
 	}
 }
+
+type recordingPlugin struct {
+	records chan Record
+}
+
+func (p *recordingPlugin) HandleUsage(_ context.Context, record Record) {
+	p.records <- record
+}
+
+func TestPublishGateHoldsRecordsUntilRelease(t *testing.T) {
+	manager := NewManager(0)
+	plugin := &recordingPlugin{records: make(chan Record, 2)}
+	manager.Register(plugin)
+	defer manager.Stop()
+
+	ctx := WithPublishGate(context.Background())
+	manager.Publish(ctx, Record{Model: "held"})
+	select {
+	case record := <-plugin.records:
+		t.Fatalf("record %q delivered before release", record.Model)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	ReleasePublishGate(ctx, func(record *Record) {
+		record.Redactions = map[string]int64{"api-key": 2}
+	})
+	select {
+	case record := <-plugin.records:
+		if record.Redactions["api-key"] != 2 {
+			t.Fatalf("Redactions = %v, want finalized counts", record.Redactions)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("held record not delivered after release")
+	}
+
+	manager.Publish(ctx, Record{Model: "late"})
+	select {
+	case record := <-plugin.records:
+		if record.Model != "late" || record.Redactions["api-key"] != 2 {
+			t.Fatalf("late record = %+v, want finalized and delivered", record)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("late record not delivered")
+	}
+}
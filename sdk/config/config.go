@@ -11,9 +11,15 @@ type SDKConfig = internalconfig.SDKConfig
 type Config = internalconfig.Config
 
 type StreamingConfig = internalconfig.StreamingConfig
+type OutputFilterConfig = internalconfig.OutputFilterConfig
+type OutputFilterRule = internalconfig.OutputFilterRule
+type JWTAuthConfig = internalconfig.JWTAuthConfig
+type ClientCertAuthConfig = internalconfig.ClientCertAuthConfig
+type ClientCertIdentity = internalconfig.ClientCertIdentity
 type ClaudeCodeConfig = internalconfig.ClaudeCodeConfig
 type TLSConfig = internalconfig.TLSConfig
 type RemoteManagement = internalconfig.RemoteManagement
+type ManagementKey = internalconfig.ManagementKey
 type OAuthModelAlias = internalconfig.OAuthModelAlias
 type PayloadConfig = internalconfig.PayloadConfig
 type PayloadRule = internalconfig.PayloadRule
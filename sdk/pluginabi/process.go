@@ -0,0 +1,350 @@
+package pluginabi
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Out-of-process plugins are executables that exchange the same method set as
+// native plugins over length-prefixed JSON frames. The host sets these
+// environment variables before starting the plugin process.
+const (
+	// EnvPluginID carries the plugin ID assigned by the host.
+	EnvPluginID = "CLIPROXY_PLUGIN_ID"
+	// EnvProcessTransport selects ProcessTransportStdio or ProcessTransportUnix.
+	EnvProcessTransport = "CLIPROXY_PLUGIN_TRANSPORT"
+	// EnvProcessSocket is the Unix socket path the plugin dials for ProcessTransportUnix.
+	EnvProcessSocket = "CLIPROXY_PLUGIN_SOCKET"
+
+	// ProcessTransportStdio exchanges frames over the plugin's stdin and stdout.
+	// Plugins must write logs to stderr when using it.
+	ProcessTransportStdio = "stdio"
+	// ProcessTransportUnix exchanges frames over a Unix socket created by the host.
+	ProcessTransportUnix = "unix"
+)
+
+// Frame kinds exchanged on a process connection.
+const (
+	// FrameCall invokes Method with Payload on the peer.
+	FrameCall = "call"
+	// FrameReply answers the call with the same ID.
+	FrameReply = "reply"
+	// FrameCancel cancels the in-flight call with the same ID.
+	FrameCancel = "cancel"
+)
+
+// MaxFrameSize bounds a single frame so a corrupt length prefix cannot exhaust memory.
+const MaxFrameSize = 64 << 20
+
+// ErrConnClosed is returned for calls on a closed process connection.
+var ErrConnClosed = errors.New("pluginabi: process connection closed")
+
+// Frame is one message on a process connection. IDs are scoped to the side
+// that sent the call, so both peers may issue calls concurrently.
+type Frame struct {
+	ID      uint64          `json:"id"`
+	Kind    string          `json:"kind"`
+	Method  string          `json:"method,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	// Error reports a transport-level failure; method failures travel as Envelope payloads.
+	Error string `json:"error,omitempty"`
+}
+
+// WriteFrame writes frame as a 4-byte big-endian length followed by its JSON encoding.
+func WriteFrame(w io.Writer, frame Frame) error {
+	raw, errMarshal := json.Marshal(frame)
+	if errMarshal != nil {
+		return fmt.Errorf("marshal frame: %w", errMarshal)
+	}
+	if len(raw) > MaxFrameSize {
+		return fmt.Errorf("frame of %d bytes exceeds limit", len(raw))
+	}
+	buf := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(raw)), uint32(len(raw)))
+	_, errWrite := w.Write(append(buf, raw...))
+	return errWrite
+}
+
+// ReadFrame reads one frame written by WriteFrame.
+func ReadFrame(r io.Reader) (Frame, error) {
+	var header [4]byte
+	if _, errRead := io.ReadFull(r, header[:]); errRead != nil {
+		return Frame{}, errRead
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > MaxFrameSize {
+		return Frame{}, fmt.Errorf("frame of %d bytes exceeds limit", size)
+	}
+	raw := make([]byte, size)
+	if _, errRead := io.ReadFull(r, raw); errRead != nil {
+		return Frame{}, errRead
+	}
+	var frame Frame
+	if errUnmarshal := json.Unmarshal(raw, &frame); errUnmarshal != nil {
+		return Frame{}, fmt.Errorf("parse frame: %w", errUnmarshal)
+	}
+	return frame, nil
+}
+
+// Handler serves calls received from the peer. The returned bytes must be JSON,
+// normally an Envelope. A returned error is reported to the caller as a
+// transport failure.
+type Handler func(ctx context.Context, method string, payload []byte) ([]byte, error)
+
+type callResult struct {
+	payload []byte
+	err     error
+}
+
+// Conn multiplexes calls in both directions over a single frame stream.
+type Conn struct {
+	r      io.Reader
+	w      io.Writer
+	closer io.Closer
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan callResult
+	serving map[uint64]context.CancelFunc
+	err     error
+	done    chan struct{}
+}
+
+// NewConn creates a connection over r and w. closer, when set, is closed by Close.
+func NewConn(r io.Reader, w io.Writer, closer io.Closer) *Conn {
+	return &Conn{
+		r:       bufio.NewReader(r),
+		w:       w,
+		closer:  closer,
+		pending: make(map[uint64]chan callResult),
+		serving: make(map[uint64]context.CancelFunc),
+		done:    make(chan struct{}),
+	}
+}
+
+// DialHost connects a plugin process to its host using the transport selected
+// by the host through the process environment.
+func DialHost() (*Conn, error) {
+	transport := strings.TrimSpace(os.Getenv(EnvProcessTransport))
+	switch transport {
+	case "", ProcessTransportStdio:
+		return NewConn(os.Stdin, os.Stdout, nil), nil
+	case ProcessTransportUnix:
+		socket := strings.TrimSpace(os.Getenv(EnvProcessSocket))
+		if socket == "" {
+			return nil, fmt.Errorf("%s is not set", EnvProcessSocket)
+		}
+		conn, errDial := net.Dial("unix", socket)
+		if errDial != nil {
+			return nil, fmt.Errorf("dial host socket: %w", errDial)
+		}
+		return NewConn(conn, conn, conn), nil
+	default:
+		return nil, fmt.Errorf("unsupported plugin transport %q", transport)
+	}
+}
+
+// Serve reads frames until the stream ends or ctx is canceled, dispatching
+// incoming calls to handler concurrently. It returns nil when the peer closes
+// the stream cleanly.
+func (c *Conn) Serve(ctx context.Context, handler Handler) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	stop := context.AfterFunc(ctx, func() { c.shutdown(ctx.Err()) })
+	defer stop()
+	for {
+		frame, errRead := ReadFrame(c.r)
+		if errRead != nil {
+			if errors.Is(errRead, io.EOF) || errors.Is(errRead, net.ErrClosed) {
+				errRead = nil
+			}
+			c.shutdown(errRead)
+			return errRead
+		}
+		switch frame.Kind {
+		case FrameReply:
+			c.mu.Lock()
+			result := c.pending[frame.ID]
+			delete(c.pending, frame.ID)
+			c.mu.Unlock()
+			if result == nil {
+				continue
+			}
+			if frame.Error != "" {
+				result <- callResult{err: errors.New(frame.Error)}
+			} else {
+				result <- callResult{payload: frame.Payload}
+			}
+		case FrameCancel:
+			c.mu.Lock()
+			cancel := c.serving[frame.ID]
+			c.mu.Unlock()
+			if cancel != nil {
+				cancel()
+			}
+		case FrameCall:
+			callCtx, cancel := context.WithCancel(ctx)
+			c.mu.Lock()
+			c.serving[frame.ID] = cancel
+			c.mu.Unlock()
+			go c.serveCall(callCtx, cancel, handler, frame)
+		}
+	}
+}
+
+func (c *Conn) serveCall(ctx context.Context, cancel context.CancelFunc, handler Handler, frame Frame) {
+	defer func() {
+		cancel()
+		c.mu.Lock()
+		delete(c.serving, frame.ID)
+		c.mu.Unlock()
+	}()
+	reply := Frame{ID: frame.ID, Kind: FrameReply}
+	func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				reply.Error = fmt.Sprintf("panic in %s: %v", frame.Method, recovered)
+			}
+		}()
+		if handler == nil {
+			reply.Error = "no handler for " + frame.Method
+			return
+		}
+		payload, errHandle := handler(ctx, frame.Method, frame.Payload)
+		if errHandle != nil {
+			reply.Error = errHandle.Error()
+			return
+		}
+		if len(payload) > 0 {
+			if !json.Valid(payload) {
+				reply.Error = frame.Method + " returned invalid JSON"
+				return
+			}
+			reply.Payload = payload
+		}
+	}()
+	if errWrite := c.write(reply); errWrite != nil {
+		c.shutdown(errWrite)
+	}
+}
+
+// Call invokes method on the peer and waits for its reply. Canceling ctx sends
+// a cancel frame and returns ctx.Err().
+func (c *Conn) Call(ctx context.Context, method string, payload []byte) ([]byte, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if len(payload) > 0 && !json.Valid(payload) {
+		return nil, fmt.Errorf("%s payload is not valid JSON", method)
+	}
+	result := make(chan callResult, 1)
+	c.mu.Lock()
+	if c.err != nil || c.isDone() {
+		errConn := c.closedErrLocked()
+		c.mu.Unlock()
+		return nil, errConn
+	}
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = result
+	c.mu.Unlock()
+
+	if errWrite := c.write(Frame{ID: id, Kind: FrameCall, Method: method, Payload: payload}); errWrite != nil {
+		c.dropPending(id)
+		c.shutdown(errWrite)
+		return nil, errWrite
+	}
+	select {
+	case res := <-result:
+		return res.payload, res.err
+	case <-ctx.Done():
+		if c.dropPending(id) {
+			_ = c.write(Frame{ID: id, Kind: FrameCancel})
+		}
+		return nil, ctx.Err()
+	case <-c.done:
+		select {
+		case res := <-result:
+			return res.payload, res.err
+		default:
+		}
+		c.mu.Lock()
+		errConn := c.closedErrLocked()
+		c.mu.Unlock()
+		return nil, errConn
+	}
+}
+
+// Close closes the underlying stream and fails pending calls.
+func (c *Conn) Close() error {
+	c.shutdown(nil)
+	if c.closer != nil {
+		return c.closer.Close()
+	}
+	return nil
+}
+
+// Done is closed once the connection stops serving.
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err reports why the connection stopped, or nil after a clean close.
+func (c *Conn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *Conn) write(frame Frame) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return WriteFrame(c.w, frame)
+}
+
+func (c *Conn) dropPending(id uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.pending[id]
+	delete(c.pending, id)
+	return ok
+}
+
+func (c *Conn) shutdown(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isDone() {
+		return
+	}
+	c.err = err
+	for _, cancel := range c.serving {
+		cancel()
+	}
+	close(c.done)
+}
+
+func (c *Conn) isDone() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Conn) closedErrLocked() error {
+	if c.err != nil {
+		return fmt.Errorf("%w: %v", ErrConnClosed, c.err)
+	}
+	return ErrConnClosed
+}
@@ -0,0 +1,94 @@
+package pluginabi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestFrameRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	frame := Frame{ID: 7, Kind: FrameCall, Method: MethodPluginRegister, Payload: []byte(`{"config_yaml":"ZW5hYmxlZDogdHJ1ZQ=="}`)}
+	if errWrite := WriteFrame(&buf, frame); errWrite != nil {
+		t.Fatalf("WriteFrame() error = %v", errWrite)
+	}
+	decoded, errRead := ReadFrame(&buf)
+	if errRead != nil {
+		t.Fatalf("ReadFrame() error = %v", errRead)
+	}
+	if decoded.ID != frame.ID || decoded.Kind != frame.Kind || decoded.Method != frame.Method || string(decoded.Payload) != string(frame.Payload) {
+		t.Fatalf("decoded frame = %+v, want %+v", decoded, frame)
+	}
+
+	if _, errRead = ReadFrame(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff})); errRead == nil {
+		t.Fatal("ReadFrame() accepted an oversized length prefix")
+	}
+}
+
+func TestConnCallsInBothDirections(t *testing.T) {
+	hostSide, pluginSide := net.Pipe()
+	host := NewConn(hostSide, hostSide, hostSide)
+	plugin := NewConn(pluginSide, pluginSide, pluginSide)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		_ = host.Serve(ctx, func(_ context.Context, method string, payload []byte) ([]byte, error) {
+			if method != MethodHostLog {
+				return nil, errors.New("unexpected host method " + method)
+			}
+			return []byte(`{"ok":true}`), nil
+		})
+	}()
+	blocked := make(chan struct{})
+	go func() {
+		_ = plugin.Serve(ctx, func(callCtx context.Context, method string, payload []byte) ([]byte, error) {
+			switch method {
+			case "test.callback":
+				// Plugins call back into the host while serving a host call.
+				return plugin.Call(callCtx, MethodHostLog, []byte(`{"message":"hi"}`))
+			case "test.block":
+				close(blocked)
+				<-callCtx.Done()
+				return nil, callCtx.Err()
+			default:
+				return nil, errors.New("unknown method " + method)
+			}
+		})
+	}()
+
+	response, errCall := host.Call(ctx, "test.callback", nil)
+	if errCall != nil || string(response) != `{"ok":true}` {
+		t.Fatalf("Call(test.callback) = %s, %v", response, errCall)
+	}
+	if _, errCall = host.Call(ctx, "test.missing", nil); errCall == nil || errCall.Error() != "unknown method test.missing" {
+		t.Fatalf("Call(test.missing) error = %v, want handler error", errCall)
+	}
+
+	callCtx, callCancel := context.WithCancel(ctx)
+	result := make(chan error, 1)
+	go func() {
+		_, errBlock := host.Call(callCtx, "test.block", nil)
+		result <- errBlock
+	}()
+	<-blocked
+	callCancel()
+	if errBlock := <-result; !errors.Is(errBlock, context.Canceled) {
+		t.Fatalf("canceled call error = %v", errBlock)
+	}
+
+	if errClose := plugin.Close(); errClose != nil {
+		t.Fatalf("Close() error = %v", errClose)
+	}
+	select {
+	case <-host.Done():
+	case <-time.After(time.Second):
+		t.Fatal("host connection did not notice the closed peer")
+	}
+	if _, errCall = host.Call(ctx, "test.callback", nil); !errors.Is(errCall, ErrConnClosed) {
+		t.Fatalf("Call() after close error = %v, want ErrConnClosed", errCall)
+	}
+}
@@ -10,16 +10,28 @@ const (
 	// Version 3 omits OriginalRequest/RequestBody on payload stream chunks
 	// (ChunkIndex >= 0); those fields remain on StreamChunkHeaderInitIndex only.
 	// Plugins that still need per-chunk request bodies should keep schema_version < 3.
-	SchemaVersion uint32 = 3
+	// Version 4 adds the host.kv.* storage and host.timer.* scheduling callbacks.
+	// Version 5 adds the event_subscriber capability and event.handle.
+	SchemaVersion uint32 = 5
 	// SchemaVersionStreamChunkOmitRequestBody is the first schema version that omits
 	// request bodies on payload stream-chunk interceptor calls.
 	SchemaVersionStreamChunkOmitRequestBody uint32 = 3
+	// SchemaVersionHostState is the first schema version with host.kv.* and
+	// host.timer.* callbacks. The host rejects them from plugins registered
+	// with an older schema version.
+	SchemaVersionHostState uint32 = 4
+	// SchemaVersionEvents is the first schema version with host event
+	// subscriptions. Older plugins declaring event_subscriber receive no events.
+	SchemaVersionEvents uint32 = 5
 )
 
 const (
 	MethodPluginRegister    = "plugin.register"
 	MethodPluginReconfigure = "plugin.reconfigure"
 	MethodPluginShutdown    = "plugin.shutdown"
+	// MethodPluginHealth is probed after an upgrade registers. Plugins that do
+	// not implement it answer unknown_method and are treated as healthy.
+	MethodPluginHealth = "plugin.health"
 
 	MethodModelRegister = "model.register"
 	MethodModelStatic   = "model.static"
@@ -62,6 +74,8 @@ const (
 
 	MethodUsageHandle = "usage.handle"
 
+	MethodEventHandle = "event.handle"
+
 	MethodCommandLineRegister = "command_line.register"
 	MethodCommandLineExecute  = "command_line.execute"
 
@@ -83,6 +97,16 @@ const (
 	MethodHostAuthGet            = "host.auth.get"
 	MethodHostAuthGetRuntime     = "host.auth.get_runtime"
 	MethodHostAuthSave           = "host.auth.save"
+	MethodHostKVGet              = "host.kv.get"
+	MethodHostKVSet              = "host.kv.set"
+	MethodHostKVDelete           = "host.kv.delete"
+	MethodHostKVList             = "host.kv.list"
+	MethodHostTimerSchedule      = "host.timer.schedule"
+	MethodHostTimerCancel        = "host.timer.cancel"
+
+	// MethodTimerFire is the default plugin method a host.timer.schedule timer
+	// invokes. Timers may name any other method under the "timer." prefix.
+	MethodTimerFire = "timer.fire"
 )
 
 type Envelope struct {
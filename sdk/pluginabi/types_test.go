@@ -27,12 +27,18 @@ func TestEnvelopeRoundTrip(t *testing.T) {
 }
 
 func TestMethodNamesAreStable(t *testing.T) {
-	if SchemaVersion != 3 {
-		t.Fatalf("SchemaVersion = %d, want 3", SchemaVersion)
+	if SchemaVersion != 5 {
+		t.Fatalf("SchemaVersion = %d, want 5", SchemaVersion)
 	}
 	if SchemaVersionStreamChunkOmitRequestBody != 3 {
 		t.Fatalf("SchemaVersionStreamChunkOmitRequestBody = %d, want 3", SchemaVersionStreamChunkOmitRequestBody)
 	}
+	if SchemaVersionHostState != 4 {
+		t.Fatalf("SchemaVersionHostState = %d, want 4", SchemaVersionHostState)
+	}
+	if SchemaVersionEvents != 5 {
+		t.Fatalf("SchemaVersionEvents = %d, want 5", SchemaVersionEvents)
+	}
 	if MethodPluginRegister != "plugin.register" {
 		t.Fatalf("MethodPluginRegister = %q", MethodPluginRegister)
 	}
@@ -84,6 +90,20 @@ func TestMethodNamesAreStable(t *testing.T) {
 	if MethodExecutorExecuteStream != "executor.execute_stream" {
 		t.Fatalf("MethodExecutorExecuteStream = %q", MethodExecutorExecuteStream)
 	}
+	for got, want := range map[string]string{
+		MethodHostKVGet:         "host.kv.get",
+		MethodHostKVSet:         "host.kv.set",
+		MethodHostKVDelete:      "host.kv.delete",
+		MethodHostKVList:        "host.kv.list",
+		MethodHostTimerSchedule: "host.timer.schedule",
+		MethodHostTimerCancel:   "host.timer.cancel",
+		MethodTimerFire:         "timer.fire",
+		MethodEventHandle:       "event.handle",
+	} {
+		if got != want {
+			t.Fatalf("method = %q, want %q", got, want)
+		}
+	}
 }
 
 func TestSchedulerPickMethodName(t *testing.T) {
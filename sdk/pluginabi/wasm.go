@@ -0,0 +1,39 @@
+package pluginabi
+
+// WASM plugins are WASI (wasip1) reactor modules that exchange the same JSON
+// method set as native plugins through linear memory. The guest exports an
+// allocator and a single call entry point; the host exposes host callbacks
+// through the WASMHostModule import module.
+const (
+	// WASMHostModule is the import module name that provides host functions.
+	WASMHostModule = "cliproxy"
+	// WASMHostCall is imported as host_call(method_ptr, method_len, payload_ptr, payload_len) -> len.
+	// It runs a host callback and keeps the JSON response on the host until the
+	// guest copies it with WASMHostResult. A negative length means no response.
+	WASMHostCall = "host_call"
+	// WASMHostResult is imported as host_result(ptr). It copies the response of
+	// the last host_call into guest memory at ptr.
+	WASMHostResult = "host_result"
+
+	// WASMExportAlloc is exported by the guest as cliproxy_alloc(size) -> ptr.
+	WASMExportAlloc = "cliproxy_alloc"
+	// WASMExportFree is exported by the guest as cliproxy_free(ptr, size).
+	WASMExportFree = "cliproxy_free"
+	// WASMExportCall is exported by the guest as
+	// cliproxy_call(method_ptr, method_len, payload_ptr, payload_len) -> packed result.
+	// The result is a guest buffer packed with PackWASMResult; the host releases
+	// it with cliproxy_free after reading.
+	WASMExportCall = "cliproxy_call"
+	// WASMExportInitialize is the WASI reactor initializer run once per instance.
+	WASMExportInitialize = "_initialize"
+)
+
+// PackWASMResult packs a guest buffer location into the i64 returned by cliproxy_call.
+func PackWASMResult(ptr, size uint32) uint64 {
+	return uint64(ptr)<<32 | uint64(size)
+}
+
+// UnpackWASMResult splits a value produced by PackWASMResult.
+func UnpackWASMResult(packed uint64) (ptr, size uint32) {
+	return uint32(packed >> 32), uint32(packed)
+}
@@ -0,0 +1,119 @@
+//go:build wasip1
+
+package pluginabi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// Guest side of the WASM plugin ABI. A plugin built with
+//
+//	GOOS=wasip1 GOARCH=wasm go build -buildmode=c-shared -o my-plugin.wasm
+//
+// imports this package and calls ServeWASM from an init function, because a
+// reactor module never runs main.
+
+var (
+	wasmHandler Handler
+	// wasmBuffers keeps buffers handed to the host alive until it frees them.
+	wasmBuffers = make(map[uint32][]byte)
+)
+
+// ServeWASM installs handler as the plugin entry point of a WASM module.
+func ServeWASM(handler Handler) {
+	wasmHandler = handler
+}
+
+// CallHost invokes a host callback from a WASM plugin and returns its JSON
+// response envelope. Only callbacks granted to the plugin by the host
+// configuration succeed; others return an error envelope.
+func CallHost(_ context.Context, method string, payload []byte) ([]byte, error) {
+	if len(payload) > 0 && !json.Valid(payload) {
+		return nil, fmt.Errorf("%s payload is not valid JSON", method)
+	}
+	methodBytes := []byte(method)
+	size := wasmHostCall(wasmPointer(methodBytes), uint32(len(methodBytes)), wasmPointer(payload), uint32(len(payload)))
+	if size < 0 {
+		return nil, errors.New("host did not answer " + method)
+	}
+	response := make([]byte, size)
+	if size > 0 {
+		wasmHostResult(wasmPointer(response))
+	}
+	return response, nil
+}
+
+//go:wasmimport cliproxy host_call
+func wasmHostCall(methodPtr, methodLen, payloadPtr, payloadLen uint32) int32
+
+//go:wasmimport cliproxy host_result
+func wasmHostResult(ptr uint32)
+
+//go:wasmexport cliproxy_alloc
+func wasmAlloc(size uint32) uint32 {
+	buf := make([]byte, max(size, 1))
+	ptr := wasmPointer(buf)
+	wasmBuffers[ptr] = buf
+	return ptr
+}
+
+//go:wasmexport cliproxy_free
+func wasmFree(ptr, _ uint32) {
+	delete(wasmBuffers, ptr)
+}
+
+//go:wasmexport cliproxy_call
+func wasmCall(methodPtr, methodLen, payloadPtr, payloadLen uint32) uint64 {
+	method := string(wasmBytes(methodPtr, methodLen))
+	payload := append([]byte(nil), wasmBytes(payloadPtr, payloadLen)...)
+	response := wasmServe(method, payload)
+	if len(response) == 0 {
+		return 0
+	}
+	ptr := wasmPointer(response)
+	wasmBuffers[ptr] = response
+	return PackWASMResult(ptr, uint32(len(response)))
+}
+
+func wasmServe(method string, payload []byte) (response []byte) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			response = wasmError("plugin_panic", fmt.Sprintf("panic in %s: %v", method, recovered))
+		}
+	}()
+	if wasmHandler == nil {
+		return wasmError("not_implemented", "no handler for "+method)
+	}
+	response, errHandle := wasmHandler(context.Background(), method, payload)
+	if errHandle != nil {
+		return wasmError("plugin_error", errHandle.Error())
+	}
+	if len(response) > 0 && !json.Valid(response) {
+		return wasmError("invalid_response", method+" returned invalid JSON")
+	}
+	return response
+}
+
+func wasmError(code, message string) []byte {
+	raw, _ := json.Marshal(Envelope{Error: &Error{Code: code, Message: message}})
+	return raw
+}
+
+func wasmPointer(buf []byte) uint32 {
+	if len(buf) == 0 {
+		return 0
+	}
+	return uint32(uintptr(unsafe.Pointer(unsafe.SliceData(buf))))
+}
+
+func wasmBytes(ptr, size uint32) []byte {
+	if size == 0 {
+		return nil
+	}
+	// Guest pointers are offsets into linear memory, which starts at address zero.
+	return unsafe.Slice((*byte)(unsafe.Add(nil, ptr)), size)
+}
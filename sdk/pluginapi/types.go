@@ -116,6 +116,15 @@ type Capabilities struct {
 	ThinkingApplier ThinkingApplier
 	// UsagePlugin receives completed usage records.
 	UsagePlugin UsagePlugin
+	// EventSubscriber asynchronously receives host events whose type matches EventTopics.
+	EventSubscriber EventSubscriber
+	// EventTopics filters the events delivered to EventSubscriber. A topic matches its exact
+	// event type and every type below it, so "auth" matches "auth.cooldown.entered".
+	// Empty subscribes to every event.
+	EventTopics []string
+	// EventQueueSize bounds the events waiting for EventSubscriber. Events that arrive while
+	// the queue is full are dropped and counted. Zero uses the host default.
+	EventQueueSize int
 	// CommandLinePlugin declares and handles plugin-owned command-line flags.
 	CommandLinePlugin CommandLinePlugin
 	// ManagementAPI declares plugin-owned diagnostic Management API and resource routes.
@@ -772,6 +781,100 @@ type HostAuthSaveResponse struct {
 	Path string `json:"path"`
 }
 
+// HostKVGetRequest reads one key from the calling plugin's key-value namespace.
+type HostKVGetRequest struct {
+	// Key is the key to read.
+	Key string `json:"key"`
+}
+
+// HostKVGetResponse returns a stored value.
+type HostKVGetResponse struct {
+	// Value is the stored value.
+	Value []byte `json:"value,omitempty"`
+	// Found reports whether the key exists and has not expired.
+	Found bool `json:"found"`
+}
+
+// HostKVSetRequest writes one key in the calling plugin's key-value namespace.
+type HostKVSetRequest struct {
+	// Key is the key to write.
+	Key string `json:"key"`
+	// Value is the value to store.
+	Value []byte `json:"value"`
+	// TTLSeconds expires the key after the given number of seconds. Zero keeps it.
+	TTLSeconds int64 `json:"ttl_seconds,omitempty"`
+}
+
+// HostKVDeleteRequest removes one key from the calling plugin's key-value namespace.
+type HostKVDeleteRequest struct {
+	// Key is the key to remove.
+	Key string `json:"key"`
+}
+
+// HostKVDeleteResponse reports whether a key was removed.
+type HostKVDeleteResponse struct {
+	// Deleted reports whether the key existed.
+	Deleted bool `json:"deleted"`
+}
+
+// HostKVListRequest lists keys in the calling plugin's key-value namespace.
+type HostKVListRequest struct {
+	// Prefix limits the listing to keys starting with it.
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// HostKVListResponse returns matching keys in sorted order.
+type HostKVListResponse struct {
+	// Keys contains the matching keys.
+	Keys []string `json:"keys"`
+}
+
+// HostTimerScheduleRequest asks the host to invoke a plugin method periodically.
+// Exactly one of Interval and Cron must be set.
+type HostTimerScheduleRequest struct {
+	// Name identifies the timer within the plugin. Scheduling an existing name replaces it.
+	Name string `json:"name"`
+	// Method is the plugin method to invoke. It defaults to timer.fire and must
+	// start with "timer.".
+	Method string `json:"method,omitempty"`
+	// Interval is a Go duration such as "30s" or "5m".
+	Interval string `json:"interval,omitempty"`
+	// Cron is a five-field cron expression (minute hour day-of-month month day-of-week) in UTC.
+	Cron string `json:"cron,omitempty"`
+	// Payload is passed back unchanged in TimerFireRequest.
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// HostTimerScheduleResponse reports the next run of a scheduled timer.
+type HostTimerScheduleResponse struct {
+	// Name identifies the timer within the plugin.
+	Name string `json:"name"`
+	// NextRun is when the timer fires next.
+	NextRun time.Time `json:"next_run"`
+}
+
+// HostTimerCancelRequest stops a timer scheduled by the calling plugin.
+type HostTimerCancelRequest struct {
+	// Name identifies the timer within the plugin.
+	Name string `json:"name"`
+}
+
+// HostTimerCancelResponse reports whether a timer was stopped.
+type HostTimerCancelResponse struct {
+	// Canceled reports whether the timer existed.
+	Canceled bool `json:"canceled"`
+}
+
+// TimerFireRequest is sent to the plugin method named by a timer when it fires.
+type TimerFireRequest struct {
+	// Name identifies the timer within the plugin.
+	Name string `json:"name"`
+	// ScheduledAt is the time the run was due.
+	ScheduledAt time.Time `json:"scheduled_at"`
+	// Payload is the payload given to host.timer.schedule.
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
 // HTTPRequest describes an upstream HTTP request issued through the host.
 type HTTPRequest struct {
 	// Method is the HTTP method.
@@ -1164,6 +1267,55 @@ type UsagePlugin interface {
 	HandleUsage(context.Context, UsageRecord)
 }
 
+// Host event types delivered to EventSubscriber plugins.
+const (
+	// HostEventAuthStatus reports auth status, disabled, and unavailable changes.
+	HostEventAuthStatus = "auth.status"
+	// HostEventAuthCooldownEntered reports an auth or auth model entering cooldown.
+	HostEventAuthCooldownEntered = "auth.cooldown.entered"
+	// HostEventAuthCooldownExited reports an auth or auth model leaving cooldown.
+	HostEventAuthCooldownExited = "auth.cooldown.exited"
+	// HostEventAuthRefresh reports a credential refresh attempt and its outcome.
+	HostEventAuthRefresh = "auth.refresh"
+	// HostEventAuthHealth reports a credential health check result.
+	HostEventAuthHealth = "auth.health"
+	// HostEventConfigReloaded reports a config file reload and the changed settings.
+	HostEventConfigReloaded = "config.reloaded"
+	// HostEventModelsRefreshed reports model catalog changes found by the background refresh.
+	HostEventModelsRefreshed = "models.refreshed"
+	// HostEventPluginLoaded reports a plugin load or hot reload.
+	HostEventPluginLoaded = "plugin.loaded"
+	// HostEventPluginUnloaded reports a plugin unload.
+	HostEventPluginUnloaded = "plugin.unloaded"
+	// HostEventPluginCrashed reports an out-of-process plugin crash.
+	HostEventPluginCrashed = "plugin.crashed"
+	// HostEventPluginRolledBack reports a plugin upgrade that was rolled back.
+	HostEventPluginRolledBack = "plugin.rolled_back"
+	// HostEventRequestCompleted summarizes one finished upstream request.
+	HostEventRequestCompleted = "request.completed"
+)
+
+// EventSubscriber receives host events from a bounded per-plugin queue.
+// Events are delivered one at a time in publish order; a slow subscriber only
+// delays its own queue.
+type EventSubscriber interface {
+	HandleEvent(context.Context, HostEvent) error
+}
+
+// HostEvent is one host event delivered to an EventSubscriber.
+type HostEvent struct {
+	// ID increases with every event published by the host process.
+	ID uint64
+	// Type is the event type, one of the HostEvent* constants.
+	Type string
+	// Time is when the host published the event.
+	Time time.Time
+	// Data is the JSON payload of the event.
+	Data json.RawMessage
+	// Dropped counts matching events dropped since the previous delivery because the queue was full.
+	Dropped uint64
+}
+
 // CommandLinePlugin declares and handles plugin-owned command-line flags.
 type CommandLinePlugin interface {
 	RegisterCommandLine(context.Context, CommandLineRegistrationRequest) (CommandLineRegistrationResponse, error)
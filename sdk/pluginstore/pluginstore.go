@@ -21,6 +21,18 @@ const (
 	InstallTypeGitHubRelease = internalpluginstore.InstallTypeGitHubRelease
 	InstallTypeDirect        = internalpluginstore.InstallTypeDirect
 
+	ArtifactTypeNative = internalpluginstore.ArtifactTypeNative
+	ArtifactTypeWASM   = internalpluginstore.ArtifactTypeWASM
+	WASMGOOS           = internalpluginstore.WASMGOOS
+	WASMGOARCH         = internalpluginstore.WASMGOARCH
+	WASMExtension      = internalpluginstore.WASMExtension
+
+	SignatureExtension       = internalpluginstore.SignatureExtension
+	SignatureStatusVerified  = internalpluginstore.SignatureStatusVerified
+	SignatureStatusUnsigned  = internalpluginstore.SignatureStatusUnsigned
+	SignatureStatusUntrusted = internalpluginstore.SignatureStatusUntrusted
+	TrustSourceAny           = internalpluginstore.TrustSourceAny
+
 	RequestKindRegistry = internalpluginstore.RequestKindRegistry
 	RequestKindMetadata = internalpluginstore.RequestKindMetadata
 	RequestKindArtifact = internalpluginstore.RequestKindArtifact
@@ -49,6 +61,9 @@ type Manifest = internalpluginstore.Manifest
 type AuthConfig = internalpluginstore.AuthConfig
 type Secret = internalpluginstore.Secret
 type ResolvedAuthConfig = internalpluginstore.ResolvedAuthConfig
+type TrustConfig = internalpluginstore.TrustConfig
+type PublisherKey = internalpluginstore.PublisherKey
+type TrustPolicy = internalpluginstore.TrustPolicy
 type PluginSyncRequest = internalpluginstore.PluginSyncRequest
 type PluginSyncItem = internalpluginstore.PluginSyncItem
 type PluginSyncResponse = internalpluginstore.PluginSyncResponse
@@ -100,6 +115,14 @@ func (c *Client) ClearAuth() {
 	c.inner.ResolvedAuthExpiresAt = time.Time{}
 }
 
+// SetTrust makes installs verify publisher signatures against policy.
+func (c *Client) SetTrust(policy TrustPolicy) {
+	if c == nil {
+		return
+	}
+	c.inner.Trust = &policy
+}
+
 func DefaultSource() Source {
 	return internalpluginstore.DefaultSource()
 }
@@ -140,6 +163,14 @@ func NormalizeAuthConfigs(auth []AuthConfig) []AuthConfig {
 	return internalpluginstore.NormalizeAuthConfigs(auth)
 }
 
+func NormalizeTrustConfigs(trust []TrustConfig) []TrustConfig {
+	return internalpluginstore.NormalizeTrustConfigs(trust)
+}
+
+func TrustPolicyForSource(trust []TrustConfig, source Source) TrustPolicy {
+	return internalpluginstore.TrustPolicyForSource(trust, source)
+}
+
 func ClearResolvedAuthConfigs(auth []ResolvedAuthConfig) {
 	internalpluginstore.ClearResolvedAuthConfigs(auth)
 }